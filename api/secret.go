@@ -314,6 +314,10 @@ type SecretAuth struct {
 	LeaseDuration int  `json:"lease_duration"`
 	Renewable     bool `json:"renewable"`
 
+	// SuggestedRenewAt is the time at which the token should be renewed. It
+	// is nil when renewing would not extend the token.
+	SuggestedRenewAt *time.Time `json:"suggested_renew_at,omitempty"`
+
 	MFARequirement *MFARequirement `json:"mfa_requirement"`
 }
 
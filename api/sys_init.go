@@ -62,6 +62,9 @@ type InitRequest struct {
 	RecoveryThreshold int      `json:"recovery_threshold"`
 	RecoveryPGPKeys   []string `json:"recovery_pgp_keys"`
 	RootTokenPGPKey   string   `json:"root_token_pgp_key"`
+
+	ShareCredentials     []string `json:"share_credentials,omitempty"`
+	ShareCredentialsRPID string   `json:"share_credentials_rp_id,omitempty"`
 }
 
 type InitStatusResponse struct {
@@ -249,6 +249,7 @@ type MountInput struct {
 	Local                 bool              `json:"local"`
 	SealWrap              bool              `json:"seal_wrap" mapstructure:"seal_wrap"`
 	ExternalEntropyAccess bool              `json:"external_entropy_access" mapstructure:"external_entropy_access"`
+	DedicatedStorageKey   bool              `json:"dedicated_storage_key,omitempty" mapstructure:"dedicated_storage_key"`
 	Options               map[string]string `json:"options"`
 
 	// Deprecated: Newer server responses should be returning this information in the
@@ -271,6 +272,8 @@ type MountConfigInput struct {
 	AllowedManagedKeys        []string                `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	PluginVersion             string                  `json:"plugin_version,omitempty"`
 	UserLockoutConfig         *UserLockoutConfigInput `json:"user_lockout_config,omitempty"`
+	RequestTimeout            string                  `json:"request_timeout,omitempty" mapstructure:"request_timeout"`
+	AliasCaseInsensitive      *bool                   `json:"alias_case_insensitive,omitempty" mapstructure:"alias_case_insensitive"`
 	// Deprecated: This field will always be blank for newer server responses.
 	PluginName string `json:"plugin_name,omitempty" mapstructure:"plugin_name"`
 }
@@ -285,6 +288,7 @@ type MountOutput struct {
 	Local                 bool              `json:"local"`
 	SealWrap              bool              `json:"seal_wrap" mapstructure:"seal_wrap"`
 	ExternalEntropyAccess bool              `json:"external_entropy_access" mapstructure:"external_entropy_access"`
+	DedicatedStorageKey   bool              `json:"dedicated_storage_key" mapstructure:"dedicated_storage_key"`
 	PluginVersion         string            `json:"plugin_version" mapstructure:"plugin_version"`
 	RunningVersion        string            `json:"running_plugin_version" mapstructure:"running_plugin_version"`
 	RunningSha256         string            `json:"running_sha256" mapstructure:"running_sha256"`
@@ -303,6 +307,8 @@ type MountConfigOutput struct {
 	TokenType                 string                   `json:"token_type,omitempty" mapstructure:"token_type"`
 	AllowedManagedKeys        []string                 `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfigOutput `json:"user_lockout_config,omitempty"`
+	RequestTimeout            int                      `json:"request_timeout,omitempty" mapstructure:"request_timeout"`
+	AliasCaseInsensitive      bool                     `json:"alias_case_insensitive,omitempty" mapstructure:"alias_case_insensitive"`
 	// Deprecated: This field will always be blank for newer server responses.
 	PluginName string `json:"plugin_name,omitempty" mapstructure:"plugin_name"`
 }
@@ -270,6 +270,10 @@ type ReloadPluginInput struct {
 
 	// Scope is the scope of the plugin reload
 	Scope string `json:"scope"`
+
+	// DrainTimeout, if set, reloads the plugin backends gracefully, waiting
+	// up to this duration for in-flight requests to complete
+	DrainTimeout string `json:"drain_timeout,omitempty"`
 }
 
 // ReloadPlugin wraps ReloadPluginWithContext using context.Background.
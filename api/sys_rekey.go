@@ -436,6 +436,9 @@ type RekeyInitRequest struct {
 	PGPKeys             []string `json:"pgp_keys"`
 	Backup              bool
 	RequireVerification bool `json:"require_verification"`
+
+	ShareCredentials     []string `json:"share_credentials,omitempty"`
+	ShareCredentialsRPID string   `json:"share_credentials_rp_id,omitempty"`
 }
 
 type RekeyStatusResponse struct {
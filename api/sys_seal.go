@@ -111,10 +111,22 @@ type SealStatusResponse struct {
 	RecoverySeal bool     `json:"recovery_seal"`
 	StorageType  string   `json:"storage_type,omitempty"`
 	Warnings     []string `json:"warnings,omitempty"`
+
+	AttestationChallenge string `json:"attestation_challenge,omitempty"`
 }
 
 type UnsealOpts struct {
 	Key     string `json:"key"`
 	Reset   bool   `json:"reset"`
 	Migrate bool   `json:"migrate"`
+
+	Attestation *UnsealAttestation `json:"attestation,omitempty"`
+}
+
+// UnsealAttestation is the WebAuthn assertion of the hardware token bound to
+// an unseal key share, with each field base64url encoded.
+type UnsealAttestation struct {
+	AuthenticatorData string `json:"authenticator_data"`
+	ClientDataJSON    string `json:"client_data_json"`
+	Signature         string `json:"signature"`
 }
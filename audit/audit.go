@@ -45,6 +45,14 @@ type Backend interface {
 	Invalidate(context.Context)
 }
 
+// Closer may be implemented by backends which run background routines or
+// buffer entries, to release them when the backend is disabled or OpenBao is
+// sealed. Close should write out any buffered entries which can still be
+// delivered before returning.
+type Closer interface {
+	Close(context.Context) error
+}
+
 // BackendConfig contains configuration parameters used in the factory func to
 // instantiate audit backends
 type BackendConfig struct {
@@ -162,6 +162,10 @@ func (f *AuditFormatter) FormatRequest(ctx context.Context, w io.Writer, config
 		reqEntry.Request.WrapTTL = int(req.WrapInfo.TTL / time.Second)
 	}
 
+	if !config.Raw {
+		reqEntry.HMACEpoch = salt.Epoch()
+	}
+
 	if !config.OmitTime {
 		reqEntry.Time = time.Now().UTC().Format(time.RFC3339Nano)
 	}
@@ -384,6 +388,10 @@ func (f *AuditFormatter) FormatResponse(ctx context.Context, w io.Writer, config
 		respEntry.Request.WrapTTL = int(req.WrapInfo.TTL / time.Second)
 	}
 
+	if !config.Raw {
+		respEntry.HMACEpoch = salt.Epoch()
+	}
+
 	if !config.OmitTime {
 		respEntry.Time = time.Now().UTC().Format(time.RFC3339Nano)
 	}
@@ -399,6 +407,10 @@ type AuditRequestEntry struct {
 	Request       *AuditRequest `json:"request,omitempty"`
 	Error         string        `json:"error,omitempty"`
 	ForwardedFrom string        `json:"forwarded_from,omitempty"` // Populated in Enterprise when a request is forwarded
+
+	// HMACEpoch is the epoch of the salt used to HMAC the entry, so that
+	// values can be correlated across rotations of the salt.
+	HMACEpoch int `json:"hmac_epoch,omitempty"`
 }
 
 // AuditResponseEntry is the structure of a response audit log entry in Audit.
@@ -410,6 +422,10 @@ type AuditResponseEntry struct {
 	Response  *AuditResponse `json:"response,omitempty"`
 	Error     string         `json:"error,omitempty"`
 	Forwarded bool           `json:"forwarded,omitempty"`
+
+	// HMACEpoch is the epoch of the salt used to HMAC the entry, so that
+	// values can be correlated across rotations of the salt.
+	HMACEpoch int `json:"hmac_epoch,omitempty"`
 }
 
 type AuditRequest struct {
@@ -6,14 +6,19 @@ package socket
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/jefferai/jsonx"
 	"github.com/openbao/openbao/audit"
 	"github.com/openbao/openbao/sdk/v2/helper/salt"
 	"github.com/openbao/openbao/sdk/v2/logical"
@@ -85,6 +90,45 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 		elideListResponses = value
 	}
 
+	bufferSize := 0
+	if bufferSizeRaw, ok := conf.Config["buffer_size"]; ok {
+		value, err := strconv.Atoi(bufferSizeRaw)
+		if err != nil {
+			return nil, err
+		}
+		if value < 0 {
+			return nil, fmt.Errorf("buffer_size must not be negative")
+		}
+		bufferSize = value
+	}
+
+	overflowBehavior, ok := conf.Config["overflow_behavior"]
+	if !ok {
+		overflowBehavior = OverflowBlock
+	}
+	switch overflowBehavior {
+	case OverflowBlock, OverflowDrop, OverflowFail:
+	default:
+		return nil, fmt.Errorf("unknown overflow behavior %q", overflowBehavior)
+	}
+
+	minBackoff, maxBackoff := 100*time.Millisecond, 30*time.Second
+	if raw, ok := conf.Config["reconnect_min_backoff"]; ok {
+		minBackoff, err = parseutil.ParseDurationSecond(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := conf.Config["reconnect_max_backoff"]; ok {
+		maxBackoff, err = parseutil.ParseDurationSecond(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if minBackoff <= 0 || maxBackoff < minBackoff {
+		return nil, fmt.Errorf("reconnect_min_backoff must be positive and not greater than reconnect_max_backoff")
+	}
+
 	b := &Backend{
 		saltConfig: conf.SaltConfig,
 		saltView:   conf.SaltView,
@@ -97,6 +141,19 @@ func Factory(ctx context.Context, conf *audit.BackendConfig) (audit.Backend, err
 		writeDuration: writeDuration,
 		address:       address,
 		socketType:    socketType,
+		format:        format,
+		prefix:        conf.Config["prefix"],
+
+		overflowBehavior: overflowBehavior,
+		minBackoff:       minBackoff,
+		maxBackoff:       maxBackoff,
+	}
+
+	if bufferSize > 0 {
+		b.entries = make(chan []byte, bufferSize)
+		b.stopCh = make(chan struct{})
+		b.flushCh = make(chan struct{})
+		b.doneCh = make(chan struct{})
 	}
 
 	switch format {
@@ -125,16 +182,67 @@ type Backend struct {
 	writeDuration time.Duration
 	address       string
 	socketType    string
+	format        string
+	prefix        string
 
+	// The lock protects the connection. Buffered entries are written by a
+	// single routine which holds it only while writing.
 	sync.Mutex
 
+	// entries buffers formatted entries when buffering is enabled. It is nil
+	// otherwise and entries are written out synchronously.
+	entries          chan []byte
+	overflowBehavior string
+	minBackoff       time.Duration
+	maxBackoff       time.Duration
+	dropped          atomic.Uint64
+
+	bufferLock sync.RWMutex
+	closed     bool
+	startOnce  sync.Once
+	running    bool
+	stopOnce   sync.Once
+	stopCh     chan struct{}
+	flushCh    chan struct{}
+	doneCh     chan struct{}
+	lost       int
+
 	saltMutex  sync.RWMutex
 	salt       *salt.Salt
 	saltConfig *salt.Config
 	saltView   logical.Storage
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend = (*Backend)(nil)
+	_ audit.Closer  = (*Backend)(nil)
+)
+
+// The overflow behaviors decide what happens to an entry when the buffer of
+// the device is full.
+const (
+	// OverflowBlock waits for room in the buffer.
+	OverflowBlock = "block"
+
+	// OverflowDrop drops the entry and reports it with a marker record once
+	// entries can be written out again.
+	OverflowDrop = "drop"
+
+	// OverflowFail fails the request which is being audited.
+	OverflowFail = "fail"
+)
+
+var (
+	errBufferFull    = errors.New("audit socket buffer is full")
+	errBackendClosed = errors.New("audit socket device is closed")
+)
+
+// droppedRecord is the marker record written out after entries were dropped.
+type droppedRecord struct {
+	Time    string `json:"time"`
+	Type    string `json:"type"`
+	Dropped uint64 `json:"dropped"`
+}
 
 func (b *Backend) GetHash(ctx context.Context, data string) (string, error) {
 	salt, err := b.Salt(ctx)
@@ -150,70 +258,256 @@ func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 		return err
 	}
 
-	b.Lock()
-	defer b.Unlock()
+	return b.log(ctx, buf.Bytes())
+}
 
-	err := b.write(ctx, buf.Bytes())
-	if err != nil {
-		rErr := b.reconnect(ctx)
-		if rErr != nil {
-			err = multierror.Append(err, rErr)
-		} else {
-			// Try once more after reconnecting
-			err = b.write(ctx, buf.Bytes())
-		}
+func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatResponse(ctx, &buf, b.formatConfig, in); err != nil {
+		return err
 	}
 
-	return err
+	return b.log(ctx, buf.Bytes())
 }
 
-func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
 	var buf bytes.Buffer
-	if err := b.formatter.FormatResponse(ctx, &buf, b.formatConfig, in); err != nil {
+	temporaryFormatter := audit.NewTemporaryFormatter(config["format"], config["prefix"])
+	if err := temporaryFormatter.FormatRequest(ctx, &buf, b.formatConfig, in); err != nil {
 		return err
 	}
 
+	// The test message is always written synchronously, so that a device
+	// which cannot reach its socket is not enabled.
 	b.Lock()
 	defer b.Unlock()
 
-	err := b.write(ctx, buf.Bytes())
-	if err != nil {
-		rErr := b.reconnect(ctx)
-		if rErr != nil {
-			err = multierror.Append(err, rErr)
-		} else {
-			// Try once more after reconnecting
-			err = b.write(ctx, buf.Bytes())
+	return b.send(ctx, buf.Bytes())
+}
+
+// log writes out the formatted entry, or buffers it when buffering is
+// enabled. When the buffer is full, the overflow behavior of the device
+// decides whether to wait for room, drop the entry or fail.
+func (b *Backend) log(ctx context.Context, entry []byte) error {
+	if b.entries == nil {
+		b.Lock()
+		defer b.Unlock()
+
+		return b.send(ctx, entry)
+	}
+
+	b.bufferLock.RLock()
+	defer b.bufferLock.RUnlock()
+
+	if b.closed {
+		return errBackendClosed
+	}
+	b.startOnce.Do(func() {
+		b.running = true
+		go b.run()
+	})
+
+	select {
+	case b.entries <- entry:
+		return nil
+	default:
+	}
+
+	switch b.overflowBehavior {
+	case OverflowDrop:
+		b.dropped.Add(1)
+		metrics.IncrCounter([]string{"audit", "socket", "dropped"}, 1)
+		return nil
+	case OverflowFail:
+		metrics.IncrCounter([]string{"audit", "socket", "overflow"}, 1)
+		return errBufferFull
+	}
+
+	select {
+	case b.entries <- entry:
+		return nil
+	case <-b.stopCh:
+		return errBackendClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run writes out the buffered entries until the backend is closed, at which
+// point the entries still in the buffer are flushed.
+func (b *Backend) run() {
+	defer close(b.doneCh)
+
+	for {
+		select {
+		case entry := <-b.entries:
+			if !b.deliver(entry) {
+				b.abandon()
+				return
+			}
+		case <-b.flushCh:
+			for {
+				select {
+				case entry := <-b.entries:
+					if !b.deliver(entry) {
+						b.abandon()
+						return
+					}
+				default:
+					// Report the entries dropped since the last one
+					// which was written out as well.
+					b.Lock()
+					b.sendDropped()
+					b.Unlock()
+					return
+				}
+			}
 		}
 	}
+}
 
-	return err
+// deliver writes out a buffered entry, reconnecting with exponential backoff
+// for as long as the backend is not closed. Once it is closed, a single
+// attempt is made. It returns false if the entry could not be delivered.
+func (b *Backend) deliver(entry []byte) bool {
+	backoff := b.minBackoff
+	for {
+		b.Lock()
+		err := b.sendBuffered(entry)
+		b.Unlock()
+		if err == nil {
+			return true
+		}
+
+		select {
+		case <-b.stopCh:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, b.maxBackoff)
+	}
 }
 
-func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
-	var buf bytes.Buffer
-	temporaryFormatter := audit.NewTemporaryFormatter(config["format"], config["prefix"])
-	if err := temporaryFormatter.FormatRequest(ctx, &buf, b.formatConfig, in); err != nil {
+// abandon counts the entry which failed to be delivered during the flush
+// along with the entries which remain in the buffer as lost.
+func (b *Backend) abandon() {
+	b.lost = 1 + len(b.entries)
+	for len(b.entries) > 0 {
+		<-b.entries
+	}
+}
+
+// sendBuffered writes out a buffered entry, preceded by a marker record if
+// entries were dropped since the last entry which was written out. The lock
+// must be held.
+func (b *Backend) sendBuffered(entry []byte) error {
+	if err := b.sendDropped(); err != nil {
 		return err
 	}
 
-	b.Lock()
-	defer b.Unlock()
+	return b.send(context.Background(), entry)
+}
+
+// sendDropped writes out the marker record reporting the entries dropped
+// since the last entry which was written out, if any. The lock must be held.
+func (b *Backend) sendDropped() error {
+	dropped := b.dropped.Load()
+	if dropped == 0 {
+		return nil
+	}
 
-	err := b.write(ctx, buf.Bytes())
+	marker, err := b.droppedMarker(dropped)
+	if err != nil {
+		return err
+	}
+	if err := b.send(context.Background(), marker); err != nil {
+		return err
+	}
+	b.dropped.Add(^(dropped - 1))
+
+	return nil
+}
+
+// droppedMarker formats the record which reports the number of entries that
+// were dropped because the buffer was full.
+func (b *Backend) droppedMarker(dropped uint64) ([]byte, error) {
+	record, err := json.Marshal(&droppedRecord{
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Type:    "dropped",
+		Dropped: dropped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.format == "jsonx" {
+		record, err = jsonx.EncodeJSONBytes(record)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		record = append(record, '\n')
+	}
+
+	return append([]byte(b.prefix), record...), nil
+}
+
+// send writes the entry to the socket, reconnecting once if the write fails.
+// The lock must be held.
+func (b *Backend) send(ctx context.Context, buf []byte) error {
+	err := b.write(ctx, buf)
 	if err != nil {
 		rErr := b.reconnect(ctx)
 		if rErr != nil {
 			err = multierror.Append(err, rErr)
 		} else {
 			// Try once more after reconnecting
-			err = b.write(ctx, buf.Bytes())
+			err = b.write(ctx, buf)
 		}
 	}
 
 	return err
 }
 
+// Close stops accepting entries and flushes the buffered entries which can
+// still be written out. It returns an error if any of them could not be.
+func (b *Backend) Close(_ context.Context) error {
+	if b.entries == nil {
+		return nil
+	}
+
+	// Release the writers waiting for room in the buffer first, so that they
+	// give up the read lock.
+	b.stopOnce.Do(func() {
+		close(b.stopCh)
+	})
+
+	b.bufferLock.Lock()
+	if b.closed {
+		b.bufferLock.Unlock()
+		return nil
+	}
+	b.closed = true
+	running := b.running
+	b.bufferLock.Unlock()
+
+	if running {
+		close(b.flushCh)
+		<-b.doneCh
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	if b.connection != nil {
+		b.connection.Close()
+		b.connection = nil
+	}
+
+	if b.lost > 0 {
+		return fmt.Errorf("failed to flush %d buffered audit entries", b.lost)
+	}
+	return nil
+}
+
 func (b *Backend) write(ctx context.Context, buf []byte) error {
 	if b.connection == nil {
 		if err := b.reconnect(ctx); err != nil {
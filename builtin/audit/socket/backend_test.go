@@ -0,0 +1,187 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package socket
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/audit"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/helper/salt"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// unusedAddress returns a local address on which nothing listens.
+func unusedAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return address
+}
+
+// readRecords accepts a single connection on the listener and decodes the
+// records written to it until the connection is closed.
+func readRecords(t *testing.T, ln net.Listener) <-chan []map[string]interface{} {
+	t.Helper()
+	ch := make(chan []map[string]interface{}, 1)
+	go func() {
+		var records []map[string]interface{}
+		defer func() {
+			ch <- records
+		}()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var record map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+				records = append(records, record)
+			}
+		}
+	}()
+	return ch
+}
+
+func testBackend(t *testing.T, config map[string]string) *Backend {
+	t.Helper()
+	config["reconnect_min_backoff"] = "10ms"
+	config["reconnect_max_backoff"] = "50ms"
+	b, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config:     config,
+	})
+	require.NoError(t, err)
+	return b.(*Backend)
+}
+
+func logRequest(b *Backend, ctx context.Context) error {
+	return b.LogRequest(namespace.RootContext(ctx), &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "secret/foo",
+		},
+	})
+}
+
+func TestAuditSocket_Factory(t *testing.T) {
+	for _, config := range []map[string]string{
+		{"buffer_size": "-1"},
+		{"overflow_behavior": "ignore"},
+		{"reconnect_min_backoff": "0s"},
+		{"reconnect_min_backoff": "1m", "reconnect_max_backoff": "1s"},
+	} {
+		config["address"] = "127.0.0.1:0"
+		_, err := Factory(context.Background(), &audit.BackendConfig{
+			SaltConfig: &salt.Config{},
+			SaltView:   &logical.InmemStorage{},
+			Config:     config,
+		})
+		require.Error(t, err, "config: %v", config)
+	}
+}
+
+func TestAuditSocket_DropMarker(t *testing.T) {
+	address := unusedAddress(t)
+	b := testBackend(t, map[string]string{
+		"address":           address,
+		"buffer_size":       "2",
+		"overflow_behavior": OverflowDrop,
+	})
+
+	// Nothing listens yet, so the buffer fills up and entries get dropped
+	// without failing the requests.
+	const total = 6
+	for i := 0; i < total; i++ {
+		require.NoError(t, logRequest(b, context.Background()))
+	}
+	dropped := b.dropped.Load()
+	require.NotZero(t, dropped)
+
+	ln, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer ln.Close()
+	records := readRecords(t, ln)
+
+	require.NoError(t, b.Close(context.Background()))
+	received := <-records
+	require.Len(t, received, total-int(dropped)+1)
+	require.Equal(t, "dropped", received[0]["type"])
+	require.EqualValues(t, dropped, received[0]["dropped"])
+	for _, record := range received[1:] {
+		require.Equal(t, "request", record["type"])
+	}
+}
+
+func TestAuditSocket_OverflowFail(t *testing.T) {
+	b := testBackend(t, map[string]string{
+		"address":           unusedAddress(t),
+		"buffer_size":       "1",
+		"overflow_behavior": OverflowFail,
+	})
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = logRequest(b, context.Background())
+	}
+	require.ErrorIs(t, err, errBufferFull)
+
+	// The buffered entries cannot be flushed to an unreachable socket.
+	require.Error(t, b.Close(context.Background()))
+	require.ErrorIs(t, logRequest(b, context.Background()), errBackendClosed)
+}
+
+func TestAuditSocket_OverflowBlock(t *testing.T) {
+	b := testBackend(t, map[string]string{
+		"address":     unusedAddress(t),
+		"buffer_size": "1",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = logRequest(b, ctx)
+	}
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Closing the device releases the requests waiting for room.
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- logRequest(b, context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.Error(t, b.Close(context.Background()))
+	require.ErrorIs(t, <-blocked, errBackendClosed)
+}
+
+func TestAuditSocket_CloseFlushes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	records := readRecords(t, ln)
+
+	b := testBackend(t, map[string]string{
+		"address":     ln.Addr().String(),
+		"buffer_size": "100",
+	})
+	for i := 0; i < 50; i++ {
+		require.NoError(t, logRequest(b, context.Background()))
+	}
+	require.NoError(t, b.Close(context.Background()))
+	require.Len(t, <-records, 50)
+}
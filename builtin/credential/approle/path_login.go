@@ -151,6 +151,23 @@ func (b *backend) pathLoginUpdate(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("invalid role or secret ID"), nil
 	}
 
+	// Check the role's CIDRs before looking up the secret ID, so that
+	// requests from unauthorized addresses learn nothing about its validity
+	if len(role.SecretIDBoundCIDRs) != 0 {
+		if req.Connection == nil || req.Connection.RemoteAddr == "" {
+			return nil, fmt.Errorf("failed to get connection information")
+		}
+		belongs, err := cidrutil.IPBelongsToCIDRBlocksSlice(req.Connection.RemoteAddr, role.SecretIDBoundCIDRs)
+		if err != nil || !belongs {
+			return logical.ErrorResponse(
+				fmt.Errorf(
+					"source address %q unauthorized by CIDR restrictions on the role: %w",
+					req.Connection.RemoteAddr,
+					err,
+				).Error()), nil
+		}
+	}
+
 	metadata := make(map[string]string)
 	var entry *secretIDStorageEntry
 	if role.BindSecretID {
@@ -228,31 +245,8 @@ func (b *backend) pathLoginUpdate(ctx context.Context, req *logical.Request, dat
 			// expired.
 			//
 
-			// Ensure that the CIDRs on the secret ID are still a subset of that of
-			// role's
-			err = verifyCIDRRoleSecretIDSubset(entry.CIDRList, role.SecretIDBoundCIDRs)
-			if err != nil {
-				return nil, err
-			}
-
-			// If CIDR restrictions are present on the secret ID, check if the
-			// source IP complies to it
-			if len(entry.CIDRList) != 0 {
-				if req.Connection == nil || req.Connection.RemoteAddr == "" {
-					return nil, fmt.Errorf("failed to get connection information")
-				}
-
-				belongs, err := cidrutil.IPBelongsToCIDRBlocksSlice(req.Connection.RemoteAddr, entry.CIDRList)
-				if err != nil {
-					return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
-				}
-
-				if !belongs {
-					return logical.ErrorResponse(fmt.Errorf(
-						"source address %q unauthorized through CIDR restrictions on the secret ID",
-						req.Connection.RemoteAddr,
-					).Error()), nil
-				}
+			if resp, err := checkSecretIDCIDRs(req, role, entry); resp != nil || err != nil {
+				return resp, err
 			}
 		default:
 			//
@@ -274,6 +268,12 @@ func (b *backend) pathLoginUpdate(ctx context.Context, req *logical.Request, dat
 				return logical.ErrorResponse(fmt.Sprintf("invalid secret_id %q", secretID)), nil
 			}
 
+			// Check the CIDRs before counting the use, so that requests from
+			// unauthorized addresses cannot exhaust the secret ID
+			if resp, err := checkSecretIDCIDRs(req, role, entry); resp != nil || err != nil {
+				return resp, err
+			}
+
 			// If there exists a single use left, delete the SecretID entry from
 			// the storage but do not fail the validation request. Subsequent
 			// requests to use the same SecretID will fail.
@@ -302,51 +302,11 @@ func (b *backend) pathLoginUpdate(ctx context.Context, req *logical.Request, dat
 					return nil, err
 				}
 			}
-
-			// Ensure that the CIDRs on the secret ID are still a subset of that of
-			// role's
-			err = verifyCIDRRoleSecretIDSubset(entry.CIDRList, role.SecretIDBoundCIDRs)
-			if err != nil {
-				return nil, err
-			}
-
-			// If CIDR restrictions are present on the secret ID, check if the
-			// source IP complies to it
-			if len(entry.CIDRList) != 0 {
-				if req.Connection == nil || req.Connection.RemoteAddr == "" {
-					return nil, fmt.Errorf("failed to get connection information")
-				}
-
-				belongs, err := cidrutil.IPBelongsToCIDRBlocksSlice(req.Connection.RemoteAddr, entry.CIDRList)
-				if err != nil || !belongs {
-					return logical.ErrorResponse(
-						fmt.Errorf(
-							"source address %q unauthorized by CIDR restrictions on the secret ID: %w",
-							req.Connection.RemoteAddr,
-							err,
-						).Error()), nil
-				}
-			}
 		}
 
 		metadata = entry.Metadata
 	}
 
-	if len(role.SecretIDBoundCIDRs) != 0 {
-		if req.Connection == nil || req.Connection.RemoteAddr == "" {
-			return nil, fmt.Errorf("failed to get connection information")
-		}
-		belongs, err := cidrutil.IPBelongsToCIDRBlocksSlice(req.Connection.RemoteAddr, role.SecretIDBoundCIDRs)
-		if err != nil || !belongs {
-			return logical.ErrorResponse(
-				fmt.Errorf(
-					"source address %q unauthorized by CIDR restrictions on the role: %w",
-					req.Connection.RemoteAddr,
-					err,
-				).Error()), nil
-		}
-	}
-
 	// Parse the CIDRs we should be binding the token to.
 	tokenBoundCIDRs := role.TokenBoundCIDRs
 	if entry != nil && len(entry.TokenBoundCIDRs) > 0 {
@@ -426,3 +386,34 @@ constraint (enabled by default) on the App role requires the
 'role_id' is fetched using the 'role/<role_name>/role_id'
 endpoint and 'secret_id' is fetched using the 'role/<role_name>/secret_id'
 endpoint.`
+
+// checkSecretIDCIDRs ensures that the CIDRs on the secret ID are still a
+// subset of the role's and, if the secret ID has any, that the request comes
+// from one of them. A non-nil response or error is to be returned to the
+// caller.
+func checkSecretIDCIDRs(req *logical.Request, role *roleStorageEntry, entry *secretIDStorageEntry) (*logical.Response, error) {
+	if err := verifyCIDRRoleSecretIDSubset(entry.CIDRList, role.SecretIDBoundCIDRs); err != nil {
+		return nil, err
+	}
+
+	if len(entry.CIDRList) == 0 {
+		return nil, nil
+	}
+
+	if req.Connection == nil || req.Connection.RemoteAddr == "" {
+		return nil, fmt.Errorf("failed to get connection information")
+	}
+
+	belongs, err := cidrutil.IPBelongsToCIDRBlocksSlice(req.Connection.RemoteAddr, entry.CIDRList)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if !belongs {
+		return logical.ErrorResponse(fmt.Sprintf(
+			"source address %q unauthorized by CIDR restrictions on the secret ID",
+			req.Connection.RemoteAddr,
+		)), nil
+	}
+
+	return nil, nil
+}
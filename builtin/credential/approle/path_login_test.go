@@ -5,6 +5,7 @@ package approle
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -110,6 +111,106 @@ func TestAppRole_BoundCIDRLogin(t *testing.T) {
 	}
 }
 
+func TestAppRole_SecretIDCIDRLogin(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+
+	b.requestNoErr(t, &logical.Request{
+		Path:      "role/testrole",
+		Operation: logical.CreateOperation,
+		Data: map[string]interface{}{
+			"bind_secret_id": true,
+		},
+		Storage: s,
+	})
+
+	resp := b.requestNoErr(t, &logical.Request{
+		Path:      "role/testrole/role-id",
+		Operation: logical.ReadOperation,
+		Storage:   s,
+	})
+	roleID := resp.Data["role_id"]
+
+	resp = b.requestNoErr(t, &logical.Request{
+		Path:      "role/testrole/secret-id",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"cidr_list": []string{"10.1.0.0/16"},
+			"num_uses":  2,
+			"metadata":  `{"workload": "web"}`,
+		},
+		Storage: s,
+	})
+	secretID := resp.Data["secret_id"]
+
+	numUses := func() interface{} {
+		t.Helper()
+		resp := b.requestNoErr(t, &logical.Request{
+			Path:      "role/testrole/secret-id/lookup",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"secret_id": secretID,
+			},
+			Storage: s,
+		})
+		return resp.Data["secret_id_num_uses"]
+	}
+
+	loginReq := &logical.Request{
+		Path:      "login",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"role_id":   roleID,
+			"secret_id": secretID,
+		},
+		Storage:    s,
+		Connection: &logical.Connection{RemoteAddr: "127.0.0.1"},
+	}
+
+	// Logins from outside the secret ID's CIDRs fail without using it up
+	resp, err := b.HandleRequest(context.Background(), loginReq)
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected login from an unauthorized address to fail, err: %v resp: %#v", err, resp)
+	}
+	if uses := numUses(); uses != 2 {
+		t.Fatalf("expected the failed login not to use the secret ID, uses left: %v", uses)
+	}
+
+	loginReq.Connection.RemoteAddr = "10.1.2.3"
+	resp = b.requestNoErr(t, loginReq)
+	if resp.Auth == nil {
+		t.Fatal("expected login to succeed")
+	}
+	if resp.Auth.Metadata["workload"] != "web" {
+		t.Fatalf("expected secret ID metadata in auth, got: %#v", resp.Auth.Metadata)
+	}
+	if uses := numUses(); uses != 1 {
+		t.Fatalf("expected one use left, got: %v", uses)
+	}
+
+	// Oversized metadata is rejected
+	tooMany := make([]string, 0, maxSecretIDMetadataKeys+1)
+	for i := 0; i <= maxSecretIDMetadataKeys; i++ {
+		tooMany = append(tooMany, fmt.Sprintf(`"key%d": "value"`, i))
+	}
+	for _, metadata := range []string{
+		"{" + strings.Join(tooMany, ",") + "}",
+		fmt.Sprintf(`{"%s": "value"}`, strings.Repeat("k", maxSecretIDMetadataKeyLength+1)),
+		fmt.Sprintf(`{"key": "%s"}`, strings.Repeat("v", maxSecretIDMetadataValueLength+1)),
+	} {
+		resp, err = b.HandleRequest(context.Background(), &logical.Request{
+			Path:      "role/testrole/secret-id",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"metadata": metadata,
+			},
+			Storage: s,
+		})
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("expected oversized metadata to be rejected, err: %v resp: %#v", err, resp)
+		}
+	}
+}
+
 func TestAppRole_RoleLogin(t *testing.T) {
 	var resp *logical.Response
 	var err error
@@ -939,7 +939,8 @@ to 0, meaning no expiration.`,
 				"metadata": {
 					Type: framework.TypeString,
 					Description: `Metadata to be tied to the SecretID. This should be a JSON
-formatted string containing the metadata in key value pairs.`,
+formatted string containing the metadata in key value pairs. At most 64 keys are
+accepted, with keys of up to 128 bytes and values of up to 512 bytes.`,
 				},
 				"cidr_list": {
 					Type: framework.TypeCommaStringSlice,
@@ -1257,7 +1258,8 @@ Overrides secret_id_ttl role option when supplied. May not be longer than role's
 				"metadata": {
 					Type: framework.TypeString,
 					Description: `Metadata to be tied to the SecretID. This should be a JSON
-formatted string containing metadata in key value pairs.`,
+formatted string containing metadata in key value pairs. At most 64 keys are
+accepted, with keys of up to 128 bytes and values of up to 512 bytes.`,
 				},
 				"cidr_list": {
 					Type: framework.TypeCommaStringSlice,
@@ -3140,6 +3142,9 @@ func (b *backend) handleRoleSecretIDCommon(ctx context.Context, req *logical.Req
 	if err = strutil.ParseArbitraryKeyValues(data.Get("metadata").(string), secretIDStorage.Metadata, ","); err != nil {
 		return logical.ErrorResponse(fmt.Sprintf("failed to parse metadata: %v", err)), nil
 	}
+	if err = validateSecretIDMetadata(secretIDStorage.Metadata); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("invalid metadata: %v", err)), nil
+	}
 
 	if secretIDStorage, err = b.registerSecretIDEntry(ctx, req.Storage, role.name, secretID, role.HMACKey, role.SecretIDPrefix, secretIDStorage); err != nil {
 		return nil, fmt.Errorf("failed to store secret_id: %w", err)
@@ -104,6 +104,31 @@ func verifyCIDRRoleSecretIDSubset(secretIDCIDRs []string, roleBoundCIDRList []st
 	return nil
 }
 
+const (
+	maxSecretIDMetadataKeys        = 64
+	maxSecretIDMetadataKeyLength   = 128
+	maxSecretIDMetadataValueLength = 512
+)
+
+// validateSecretIDMetadata bounds the metadata attached to a SecretID, which
+// is copied into the auth response of every login made with it.
+func validateSecretIDMetadata(metadata map[string]string) error {
+	if len(metadata) > maxSecretIDMetadataKeys {
+		return fmt.Errorf("metadata cannot have more than %d keys", maxSecretIDMetadataKeys)
+	}
+
+	for key, value := range metadata {
+		if len(key) > maxSecretIDMetadataKeyLength {
+			return fmt.Errorf("metadata key %q is longer than %d bytes", key[:maxSecretIDMetadataKeyLength], maxSecretIDMetadataKeyLength)
+		}
+		if len(value) > maxSecretIDMetadataValueLength {
+			return fmt.Errorf("value of metadata key %q is longer than %d bytes", key, maxSecretIDMetadataValueLength)
+		}
+	}
+
+	return nil
+}
+
 const maxHmacInputLength = 4096
 
 // Creates a SHA256 HMAC of the given 'value' using the given 'key' and returns
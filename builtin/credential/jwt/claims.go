@@ -182,6 +182,40 @@ func matchFound(expVals, actVals []interface{}, useGlobs bool) (bool, error) {
 	return false, nil
 }
 
+// groupAliasNames converts a groups claim to the names of group aliases. If a
+// delimiter is given, a string claim is split on it, so that space-delimited
+// and comma-delimited claims are supported. If a template is given, each name
+// is made by replacing groupTemplatePlaceholder in it with the group. Empty
+// groups are skipped.
+func groupAliasNames(raw interface{}, delimiter, template string) ([]string, error) {
+	groups, ok := normalizeList(raw)
+	if !ok {
+		return nil, errors.New("claim could not be converted to string list")
+	}
+	if str, ok := raw.(string); ok && delimiter != "" {
+		groups = nil
+		for _, group := range strings.Split(str, delimiter) {
+			groups = append(groups, strings.TrimSpace(group))
+		}
+	}
+
+	var names []string
+	for _, groupRaw := range groups {
+		group, ok := groupRaw.(string)
+		if !ok {
+			return nil, fmt.Errorf("value %v in groups claim could not be parsed as string", groupRaw)
+		}
+		if group == "" {
+			continue
+		}
+		if template != "" {
+			group = strings.ReplaceAll(template, groupTemplatePlaceholder, group)
+		}
+		names = append(names, group)
+	}
+	return names, nil
+}
+
 // normalizeList takes a string, bool, json.Number or list and returns a list. This is useful when
 // providers are expected to return a list (typically of strings) but reduce it
 // to a string type when the list count is 1.
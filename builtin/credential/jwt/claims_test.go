@@ -664,3 +664,33 @@ func Test_normalizeList(t *testing.T) {
 		}
 	}
 }
+
+func Test_groupAliasNames(t *testing.T) {
+	tests := []struct {
+		raw       interface{}
+		delimiter string
+		template  string
+		names     []string
+		err       bool
+	}{
+		{raw: []interface{}{"a", "", "b"}, names: []string{"a", "b"}},
+		{raw: "a", names: []string{"a"}},
+		{raw: "a b", names: []string{"a b"}},
+		{raw: "a  b ", delimiter: " ", names: []string{"a", "b"}},
+		{raw: "a, b", delimiter: ",", names: []string{"a", "b"}},
+		{raw: []interface{}{"a b"}, delimiter: " ", names: []string{"a b"}},
+		{raw: []interface{}{"a", "b"}, template: "oidc-{{group}}", names: []string{"oidc-a", "oidc-b"}},
+		{raw: []interface{}{}, names: nil},
+		{raw: []interface{}{"a", 42}, err: true},
+		{raw: 42, err: true},
+	}
+	for _, tt := range tests {
+		names, err := groupAliasNames(tt.raw, tt.delimiter, tt.template)
+		if (err != nil) != tt.err {
+			t.Errorf("groupAliasNames(%v) got err = %v, want err %v", tt.raw, err, tt.err)
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("groupAliasNames(%v) got names = %v, want %v", tt.raw, names, tt.names)
+		}
+	}
+}
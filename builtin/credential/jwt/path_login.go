@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/cap/jwt"
 	"github.com/hashicorp/errwrap"
@@ -267,19 +268,11 @@ func (b *jwtAuthBackend) createIdentity(ctx context.Context, allClaims map[strin
 		return nil, nil, fmt.Errorf("failed to fetch groups: %s", err)
 	}
 
-	groups, ok := normalizeList(groupsClaimRaw)
-
-	if !ok {
-		return nil, nil, fmt.Errorf("%q claim could not be converted to string list", role.GroupsClaim)
+	groups, err := groupAliasNames(groupsClaimRaw, role.GroupsClaimDelimiter, role.GroupsAliasTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %q claim: %w", role.GroupsClaim, err)
 	}
-	for _, groupRaw := range groups {
-		group, ok := groupRaw.(string)
-		if !ok {
-			return nil, nil, fmt.Errorf("value %v in groups claim could not be parsed as string", groupRaw)
-		}
-		if group == "" {
-			continue
-		}
+	for _, group := range groups {
 		groupAliases = append(groupAliases, &logical.Alias{
 			Name: group,
 		})
@@ -318,6 +311,11 @@ func (b *jwtAuthBackend) fetchGroups(ctx context.Context, pConfig CustomProvider
 	}
 	groupsClaimRaw := getClaim(b.Logger(), allClaims, role.GroupsClaim)
 
+	// Nested claims are often left out entirely for users without groups,
+	// so a JSON pointer which does not resolve means there are none
+	if groupsClaimRaw == nil && strings.HasPrefix(role.GroupsClaim, "/") {
+		return []interface{}{}, nil
+	}
 	if groupsClaimRaw == nil {
 		return nil, fmt.Errorf("%q claim not found in token", role.GroupsClaim)
 	}
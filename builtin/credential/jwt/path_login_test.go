@@ -1329,6 +1329,91 @@ func TestLogin_NestedGroups(t *testing.T) {
 	}
 }
 
+func TestLogin_GroupsClaimTransform(t *testing.T) {
+	b, storage := getBackend(t)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+			Connection: &logical.Connection{
+				RemoteAddr: "127.0.0.1",
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err:%s resp:%#v\n", err, resp)
+		}
+		return resp
+	}
+
+	request(logical.UpdateOperation, configPath, map[string]interface{}{
+		"bound_issuer":           "https://team-vault.auth0.com/",
+		"jwt_validation_pubkeys": ecdsaPubKey,
+		"jwt_supported_algs":     string(jwt.ES256),
+	})
+	request(logical.CreateOperation, "role/plugin-test", map[string]interface{}{
+		"role_type":              "jwt",
+		"bound_audiences":        "https://vault.plugin.auth.jwt.test",
+		"user_claim":             "https://vault/user",
+		"groups_claim":           "/resource_access/myclient/roles",
+		"groups_claim_delimiter": " ",
+		"groups_alias_template":  "keycloak-{{group}}",
+		"policies":               "test",
+	})
+
+	cl := sqjwt.Claims{
+		Subject:   "r3qXcK2bix9eFECzsU3Sbmh0K16fatW6@clients",
+		Issuer:    "https://team-vault.auth0.com/",
+		NotBefore: sqjwt.NewNumericDate(time.Now().Add(-5 * time.Second)),
+		Audience:  sqjwt.Audience{"https://vault.plugin.auth.jwt.test"},
+	}
+	login := func(privateCl interface{}) *logical.Auth {
+		t.Helper()
+		jwtData, _ := getTestJWT(t, ecdsaPrivKey, cl, privateCl)
+		return request(logical.UpdateOperation, "login", map[string]interface{}{
+			"role": "plugin-test",
+			"jwt":  jwtData,
+		}).Auth
+	}
+
+	auth := login(map[string]interface{}{
+		"https://vault/user": "jeff",
+		"resource_access": map[string]interface{}{
+			"myclient": map[string]interface{}{
+				"roles": "admin  reader",
+			},
+		},
+	})
+	if len(auth.GroupAliases) != 2 || auth.GroupAliases[0].Name != "keycloak-admin" || auth.GroupAliases[1].Name != "keycloak-reader" {
+		t.Fatalf("unexpected group aliases: %#v", auth.GroupAliases)
+	}
+
+	// Users missing from the client's resource access have no groups
+	auth = login(map[string]interface{}{
+		"https://vault/user": "jeff",
+		"resource_access":    map[string]interface{}{},
+	})
+	if len(auth.GroupAliases) != 0 {
+		t.Fatalf("expected no group aliases, got: %#v", auth.GroupAliases)
+	}
+
+	// Templates must use the group
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "role/plugin-test",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"groups_alias_template": "keycloak",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected a template without the group to be rejected, err:%v resp:%#v", err, resp)
+	}
+}
+
 func TestLogin_OIDC_StringGroupClaim(t *testing.T) {
 	cfg := testConfig{
 		oidc:          true,
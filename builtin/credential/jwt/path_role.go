@@ -27,6 +27,9 @@ const (
 	boundClaimsTypeGlob   = "glob"
 	callbackModeDirect    = "direct"
 	callbackModeClient    = "client"
+
+	// groupTemplatePlaceholder is replaced by the group in groups_alias_template
+	groupTemplatePlaceholder = "{{group}}"
 )
 
 func pathRoleList(b *jwtAuthBackend) *framework.Path {
@@ -163,8 +166,20 @@ Defaults to 60 (1 minute) if set to 0 and can be disabled if set to -1.`,
 for referencing claims.`,
 			},
 			"groups_claim": {
-				Type:        framework.TypeString,
-				Description: `The claim to use for the Identity group alias names`,
+				Type: framework.TypeString,
+				Description: `The claim to use for the Identity group alias names. May be a JSON
+pointer, such as "/resource_access/myclient/roles", in which case a claim which
+cannot be found results in no groups.`,
+			},
+			"groups_claim_delimiter": {
+				Type: framework.TypeString,
+				Description: `If set, a groups claim which is a string is split on this delimiter,
+for example " " for space-delimited groups.`,
+			},
+			"groups_alias_template": {
+				Type: framework.TypeString,
+				Description: `If set, each group alias name is made by replacing "{{group}}" in
+this template with the group, for example "oidc-{{group}}".`,
 			},
 			"oidc_scopes": {
 				Type:        framework.TypeCommaStringSlice,
@@ -246,6 +261,8 @@ type jwtRole struct {
 	Oauth2Metadata       []string               `json:"oauth2_metadata"`
 	UserClaim            string                 `json:"user_claim"`
 	GroupsClaim          string                 `json:"groups_claim"`
+	GroupsClaimDelimiter string                 `json:"groups_claim_delimiter"`
+	GroupsAliasTemplate  string                 `json:"groups_alias_template"`
 	OIDCScopes           []string               `json:"oidc_scopes"`
 	AllowedRedirectURIs  []string               `json:"allowed_redirect_uris"`
 	CallbackMode         string                 `json:"callback_mode"`
@@ -364,6 +381,8 @@ func (b *jwtAuthBackend) pathRoleRead(ctx context.Context, req *logical.Request,
 		"user_claim":              role.UserClaim,
 		"user_claim_json_pointer": role.UserClaimJSONPointer,
 		"groups_claim":            role.GroupsClaim,
+		"groups_claim_delimiter":  role.GroupsClaimDelimiter,
+		"groups_alias_template":   role.GroupsAliasTemplate,
 		"allowed_redirect_uris":   role.AllowedRedirectURIs,
 		"callback_mode":           role.CallbackMode,
 		"oidc_scopes":             role.OIDCScopes,
@@ -596,6 +615,17 @@ func (b *jwtAuthBackend) pathRoleCreateUpdate(ctx context.Context, req *logical.
 		role.GroupsClaim = groupsClaim.(string)
 	}
 
+	if groupsClaimDelimiter, ok := data.GetOk("groups_claim_delimiter"); ok {
+		role.GroupsClaimDelimiter = groupsClaimDelimiter.(string)
+	}
+
+	if groupsAliasTemplate, ok := data.GetOk("groups_alias_template"); ok {
+		role.GroupsAliasTemplate = groupsAliasTemplate.(string)
+	}
+	if role.GroupsAliasTemplate != "" && !strings.Contains(role.GroupsAliasTemplate, groupTemplatePlaceholder) {
+		return logical.ErrorResponse("groups_alias_template must contain %q", groupTemplatePlaceholder), nil
+	}
+
 	if oidcScopes, ok := data.GetOk("oidc_scopes"); ok {
 		role.OIDCScopes = oidcScopes.([]string)
 	}
@@ -778,6 +778,8 @@ func TestPath_Read(t *testing.T) {
 		"user_claim":              "user",
 		"user_claim_json_pointer": false,
 		"groups_claim":            "groups",
+		"groups_claim_delimiter":  "",
+		"groups_alias_template":   "",
 		"token_policies":          []string{"test"},
 		"policies":                []string{"test"},
 		"token_period":            int64(3),
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	operationPrefixWebAuthn = "webauthn"
+	userPrefix              = "user/"
+	credentialPrefix        = "credential/"
+	challengePrefix         = "challenge/"
+)
+
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func Backend() *backend {
+	b := &backend{
+		userLocks:      locksutil.CreateLocks(),
+		challengeLocks: locksutil.CreateLocks(),
+	}
+	b.Backend = &framework.Backend{
+		Help: backendHelp,
+
+		PathsSpecial: &logical.Paths{
+			Unauthenticated: []string{
+				"login",
+				"login/begin",
+			},
+		},
+
+		Paths: []*framework.Path{
+			pathConfig(b),
+			pathUsers(b),
+			pathUsersList(b),
+			pathUserCredentials(b),
+			pathRegisterBegin(b),
+			pathRegisterFinish(b),
+			pathLoginBegin(b),
+			pathLogin(b),
+		},
+
+		// Register a periodic function that deletes expired challenges
+		PeriodicFunc: b.tidyChallenges,
+		AuthRenew:    b.pathLoginRenew,
+		BackendType:  logical.TypeCredential,
+	}
+
+	return b
+}
+
+type backend struct {
+	*framework.Backend
+
+	// Locks serializing changes to users, in particular updates of the
+	// signature counters of their credentials on login, indexed by
+	// username.
+	userLocks []*locksutil.LockEntry
+
+	// Locks making the consumption of challenges atomic, indexed by the
+	// storage key of the challenge.
+	challengeLocks []*locksutil.LockEntry
+}
+
+const backendHelp = `
+The "webauthn" credential provider allows authentication using
+WebAuthn/FIDO2 authenticators, such as security keys and platform
+authenticators.
+
+The relying party is configured using the "config" endpoint. Users
+are created using the "users/" endpoints, and credentials are
+registered for them with the "users/<username>/register/" endpoints.
+Authentication is then done by requesting a challenge from
+"login/begin" and supplying the authenticator assertion for it to
+"login".
+`
@@ -0,0 +1,390 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://login.example.com"
+)
+
+// cborPair and cborMap allow encoding CBOR maps with their keys in a given
+// order, as authenticators do.
+type cborPair struct {
+	key   interface{}
+	value interface{}
+}
+
+type cborMap []cborPair
+
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg < 1<<8:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg < 1<<16:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg < 1<<32:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+}
+
+func cborEncode(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case cborMap:
+		out := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			out = append(out, cborEncode(t, pair.key)...)
+			out = append(out, cborEncode(t, pair.value)...)
+		}
+		return out
+	}
+	t.Fatalf("cannot encode %T", v)
+	return nil
+}
+
+// testAuthenticator is a software authenticator holding a single ES256
+// credential.
+type testAuthenticator struct {
+	t         *testing.T
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+	rpID      string
+	origin    string
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return &testAuthenticator{
+		t:      t,
+		key:    key,
+		id:     id,
+		rpID:   testRPID,
+		origin: testOrigin,
+	}
+}
+
+func (a *testAuthenticator) clientData(ceremony, challenge string) []byte {
+	raw, err := json.Marshal(map[string]interface{}{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return raw
+}
+
+func (a *testAuthenticator) authData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, cborEncode(a.t, cborMap{
+			{webauthnutil.COSEKeyKty, webauthnutil.COSEKtyEC2},
+			{webauthnutil.COSEKeyAlg, int(webauthnutil.COSEAlgES256)},
+			{webauthnutil.COSEKeyCrv, webauthnutil.COSECrvP256},
+			{webauthnutil.COSEKeyX, a.key.X.FillBytes(make([]byte, 32))},
+			{webauthnutil.COSEKeyY, a.key.Y.FillBytes(make([]byte, 32))},
+		})...)
+	}
+	return data
+}
+
+// create answers a registration challenge.
+func (a *testAuthenticator) create(challenge string) map[string]interface{} {
+	authData := a.authData(webauthnutil.FlagUserPresent|webauthnutil.FlagUserVerified|webauthnutil.FlagAttestedCredential, true)
+	return map[string]interface{}{
+		"client_data_json": webauthnutil.EncodeBase64(a.clientData(webauthnutil.ClientDataTypeCreate, challenge)),
+		"attestation_object": webauthnutil.EncodeBase64(cborEncode(a.t, cborMap{
+			{"fmt", "none"},
+			{"attStmt", cborMap{}},
+			{"authData", authData},
+		})),
+	}
+}
+
+// get answers a login challenge, bumping the signature counter.
+func (a *testAuthenticator) get(challenge string) map[string]interface{} {
+	a.signCount++
+	clientData := a.clientData(webauthnutil.ClientDataTypeGet, challenge)
+	authData := a.authData(webauthnutil.FlagUserPresent|webauthnutil.FlagUserVerified, false)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return map[string]interface{}{
+		"credential_id":      webauthnutil.EncodeBase64(a.id),
+		"client_data_json":   webauthnutil.EncodeBase64(clientData),
+		"authenticator_data": webauthnutil.EncodeBase64(authData),
+		"signature":          webauthnutil.EncodeBase64(sig),
+	}
+}
+
+func testBackend(t *testing.T) (*backend, logical.Storage) {
+	storage := &logical.InmemStorage{}
+	config := logical.TestBackendConfig()
+	config.StorageView = storage
+
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.(*backend), storage
+}
+
+func testRequest(t *testing.T, b *backend, s logical.Storage, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+	t.Helper()
+	return b.HandleRequest(context.Background(), &logical.Request{
+		Operation: op,
+		Path:      path,
+		Storage:   s,
+		Data:      data,
+		Connection: &logical.Connection{
+			RemoteAddr: "127.0.0.1",
+		},
+	})
+}
+
+func mustRequest(t *testing.T, b *backend, s logical.Storage, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := testRequest(t, b, s, op, path, data)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("%s %s failed: resp: %#v, err: %v", op, path, resp, err)
+	}
+	return resp
+}
+
+func expectFailure(t *testing.T, resp *logical.Response, err error) {
+	t.Helper()
+	if err == nil && (resp == nil || !resp.IsError()) {
+		t.Fatalf("expected failure, got resp: %#v", resp)
+	}
+}
+
+// testSetup configures the backend with a user holding a registered
+// credential.
+func testSetup(t *testing.T, residentKey string) (*backend, logical.Storage, *testAuthenticator) {
+	b, s := testBackend(t)
+	mustRequest(t, b, s, logical.UpdateOperation, "config", map[string]interface{}{
+		"rp_id":             testRPID,
+		"rp_origins":        testOrigin,
+		"user_verification": requirementRequired,
+		"resident_key":      residentKey,
+	})
+	mustRequest(t, b, s, logical.CreateOperation, "users/alice", map[string]interface{}{
+		"token_policies": "dev",
+	})
+
+	auth := newTestAuthenticator(t)
+	resp := mustRequest(t, b, s, logical.UpdateOperation, "users/alice/register/begin", nil)
+	if rk := resp.Data["authenticator_selection"].(map[string]interface{})["resident_key"]; rk != residentKey {
+		t.Fatalf("expected resident key requirement %q, got %v", residentKey, rk)
+	}
+	mustRequest(t, b, s, logical.UpdateOperation, "users/alice/register/finish", auth.create(resp.Data["challenge"].(string)))
+
+	return b, s, auth
+}
+
+func beginLogin(t *testing.T, b *backend, s logical.Storage, username string) string {
+	t.Helper()
+	data := map[string]interface{}{}
+	if username != "" {
+		data["username"] = username
+	}
+	resp := mustRequest(t, b, s, logical.UpdateOperation, "login/begin", data)
+	return resp.Data["challenge"].(string)
+}
+
+func TestBackend_RegisterAndLogin(t *testing.T) {
+	b, s, auth := testSetup(t, requirementDiscouraged)
+
+	resp := mustRequest(t, b, s, logical.ReadOperation, "users/alice", nil)
+	creds := resp.Data["credentials"].([]map[string]interface{})
+	if len(creds) != 1 || creds[0]["id"] != webauthnutil.EncodeBase64(auth.id) || creds[0]["discoverable"] != false {
+		t.Fatalf("unexpected credentials: %#v", creds)
+	}
+
+	resp = mustRequest(t, b, s, logical.UpdateOperation, "login/begin", map[string]interface{}{
+		"username": "alice",
+	})
+	allowed := resp.Data["allow_credentials"].([]map[string]interface{})
+	if len(allowed) != 1 || allowed[0]["id"] != webauthnutil.EncodeBase64(auth.id) {
+		t.Fatalf("unexpected allowed credentials: %#v", allowed)
+	}
+
+	resp = mustRequest(t, b, s, logical.UpdateOperation, "login", auth.get(resp.Data["challenge"].(string)))
+	if resp.Auth == nil || resp.Auth.Alias.Name != "alice" || len(resp.Auth.Policies) != 1 || resp.Auth.Policies[0] != "dev" {
+		t.Fatalf("unexpected auth: %#v", resp.Auth)
+	}
+
+	resp = mustRequest(t, b, s, logical.AliasLookaheadOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	if resp.Auth.Alias.Name != "alice" {
+		t.Fatalf("unexpected alias: %#v", resp.Auth.Alias)
+	}
+
+	// Registering the same credential twice is refused
+	resp = mustRequest(t, b, s, logical.UpdateOperation, "users/alice/register/begin", nil)
+	excluded := resp.Data["exclude_credentials"].([]map[string]interface{})
+	if len(excluded) != 1 {
+		t.Fatalf("unexpected excluded credentials: %#v", excluded)
+	}
+	resp, err := testRequest(t, b, s, logical.UpdateOperation, "users/alice/register/finish", auth.create(resp.Data["challenge"].(string)))
+	expectFailure(t, resp, err)
+
+	// Deleted credentials can no longer be used
+	mustRequest(t, b, s, logical.DeleteOperation, "users/alice/credentials/"+webauthnutil.EncodeBase64(auth.id), nil)
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	expectFailure(t, resp, err)
+}
+
+func TestBackend_DiscoverableLogin(t *testing.T) {
+	b, s, auth := testSetup(t, requirementRequired)
+
+	resp := mustRequest(t, b, s, logical.UpdateOperation, "login/begin", nil)
+	if allowed := resp.Data["allow_credentials"].([]map[string]interface{}); len(allowed) != 0 {
+		t.Fatalf("unexpected allowed credentials: %#v", allowed)
+	}
+
+	// The user handle tells which user the credential belongs to
+	data := auth.get(resp.Data["challenge"].(string))
+	resp, err := testRequest(t, b, s, logical.UpdateOperation, "login", data)
+	expectFailure(t, resp, err)
+
+	user, err := b.user(context.Background(), s, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = auth.get(beginLogin(t, b, s, ""))
+	data["user_handle"] = webauthnutil.EncodeBase64(user.UserHandle)
+	resp = mustRequest(t, b, s, logical.UpdateOperation, "login", data)
+	if resp.Auth == nil || resp.Auth.Alias.Name != "alice" {
+		t.Fatalf("unexpected auth: %#v", resp.Auth)
+	}
+
+	data = auth.get(beginLogin(t, b, s, ""))
+	data["user_handle"] = webauthnutil.EncodeBase64([]byte("someone else"))
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", data)
+	expectFailure(t, resp, err)
+
+	// Challenges bound to a user cannot be answered for another
+	mustRequest(t, b, s, logical.CreateOperation, "users/bob", nil)
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "bob")))
+	expectFailure(t, resp, err)
+}
+
+func TestBackend_Challenges(t *testing.T) {
+	b, s, auth := testSetup(t, requirementPreferred)
+	ctx := context.Background()
+
+	// Challenges are single-use
+	data := auth.get(beginLogin(t, b, s, "alice"))
+	mustRequest(t, b, s, logical.UpdateOperation, "login", data)
+	resp, err := testRequest(t, b, s, logical.UpdateOperation, "login", data)
+	expectFailure(t, resp, err)
+
+	// Challenges are tied to their ceremony
+	resp = mustRequest(t, b, s, logical.UpdateOperation, "users/alice/register/begin", nil)
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(resp.Data["challenge"].(string)))
+	expectFailure(t, resp, err)
+
+	// Expired challenges are refused, and tidied
+	challenge, err := b.newChallenge(ctx, s, ceremonyAuthentication, "alice", -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(webauthnutil.EncodeBase64(challenge)))
+	expectFailure(t, resp, err)
+
+	if _, err := b.newChallenge(ctx, s, ceremonyAuthentication, "alice", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	live := beginLogin(t, b, s, "alice")
+	if err := b.tidyChallenges(ctx, &logical.Request{Storage: s}); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := s.List(ctx, challengePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected only the live challenge to remain, got %d", len(keys))
+	}
+	mustRequest(t, b, s, logical.UpdateOperation, "login", auth.get(live))
+
+	// Responses from other origins or relying parties are refused
+	auth.origin = "https://evil.example.org"
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	expectFailure(t, resp, err)
+	auth.origin = testOrigin
+	auth.rpID = "evil.example.org"
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	expectFailure(t, resp, err)
+}
+
+func TestBackend_SignCountRegression(t *testing.T) {
+	b, s, auth := testSetup(t, requirementDiscouraged)
+
+	auth.signCount = 10
+	mustRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+
+	// A clone of the authenticator lags behind the original
+	clone := *auth
+	clone.signCount = 5
+	resp, err := testRequest(t, b, s, logical.UpdateOperation, "login", clone.get(beginLogin(t, b, s, "alice")))
+	expectFailure(t, resp, err)
+
+	// Replaying the last counter is refused as well
+	auth.signCount--
+	resp, err = testRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	expectFailure(t, resp, err)
+
+	mustRequest(t, b, s, logical.UpdateOperation, "login", auth.get(beginLogin(t, b, s, "alice")))
+	resp = mustRequest(t, b, s, logical.ReadOperation, "users/alice/credentials/"+webauthnutil.EncodeBase64(auth.id), nil)
+	if count := resp.Data["sign_count"]; count != uint32(12) {
+		t.Fatalf("expected sign count 12, got %v", count)
+	}
+}
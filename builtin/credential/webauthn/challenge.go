@@ -0,0 +1,142 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// Ceremonies challenges are issued for.
+const (
+	ceremonyRegistration   = "registration"
+	ceremonyAuthentication = "authentication"
+)
+
+// challengeLen is the length of the random challenges issued, well above the
+// 16 bytes required by the specification.
+const challengeLen = 32
+
+var errInvalidChallenge = errors.New("unknown, expired or already used challenge")
+
+// challengeEntry tracks an outstanding challenge. Challenges are stored by
+// their hash and deleted as soon as they are answered, so that each may be
+// used at most once.
+type challengeEntry struct {
+	Ceremony string `json:"ceremony"`
+
+	// Username is the user the challenge was issued for, if any. It is
+	// unset for logins with discoverable credentials.
+	Username string `json:"username"`
+
+	Expiration time.Time `json:"expiration"`
+}
+
+func challengeKey(challenge []byte) string {
+	sum := sha256.Sum256(challenge)
+	return challengePrefix + hex.EncodeToString(sum[:])
+}
+
+// newChallenge generates and stores a challenge for the ceremony.
+func (b *backend) newChallenge(ctx context.Context, s logical.Storage, ceremony, username string, ttl time.Duration) ([]byte, error) {
+	challenge := make([]byte, challengeLen)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	entry, err := logical.StorageEntryJSON(challengeKey(challenge), &challengeEntry{
+		Ceremony:   ceremony,
+		Username:   username,
+		Expiration: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// consumeChallenge looks up and deletes the challenge sent back by a client,
+// failing with errInvalidChallenge unless it is outstanding and was issued
+// for the ceremony.
+func (b *backend) consumeChallenge(ctx context.Context, s logical.Storage, encoded, ceremony string) (*challengeEntry, error) {
+	challenge, err := webauthnutil.DecodeBase64(encoded)
+	if err != nil || len(challenge) != challengeLen {
+		return nil, errInvalidChallenge
+	}
+
+	key := challengeKey(challenge)
+	lock := locksutil.LockForKey(b.challengeLocks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, errInvalidChallenge
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	var result challengeEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	if result.Ceremony != ceremony || time.Now().After(result.Expiration) {
+		return nil, errInvalidChallenge
+	}
+	return &result, nil
+}
+
+// tidyChallenges deletes the challenges which expired without being
+// answered.
+func (b *backend) tidyChallenges(ctx context.Context, req *logical.Request) error {
+	keys, err := req.Storage.List(ctx, challengePrefix)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		if err := b.tidyChallenge(ctx, req.Storage, challengePrefix+key, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *backend) tidyChallenge(ctx context.Context, s logical.Storage, key string, now time.Time) error {
+	lock := locksutil.LockForKey(b.challengeLocks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := s.Get(ctx, key)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	var challenge challengeEntry
+	if err := entry.DecodeJSON(&challenge); err != nil {
+		b.Logger().Warn("deleting undecodable challenge", "key", key, "error", err)
+		return s.Delete(ctx, key)
+	}
+	if now.After(challenge.Expiration) {
+		return s.Delete(ctx, key)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// User verification and resident key requirements, as named by the WebAuthn
+// specification.
+const (
+	requirementRequired    = "required"
+	requirementPreferred   = "preferred"
+	requirementDiscouraged = "discouraged"
+)
+
+const defaultChallengeTTL = 2 * time.Minute
+
+func pathConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			Action:          "Configure",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"rp_id": {
+				Type:        framework.TypeString,
+				Description: "Relying party ID credentials are scoped to; the effective domain of the origins, such as \"example.com\".",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Relying Party ID",
+				},
+			},
+			"rp_name": {
+				Type:        framework.TypeString,
+				Description: "Human-readable relying party name shown by authenticators. Defaults to the relying party ID.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Relying Party Name",
+				},
+			},
+			"rp_origins": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Origins allowed to perform ceremonies, such as \"https://login.example.com\". Defaults to https://<rp_id>.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Relying Party Origins",
+				},
+			},
+			"user_verification": {
+				Type:          framework.TypeString,
+				Default:       requirementPreferred,
+				AllowedValues: []interface{}{requirementRequired, requirementPreferred, requirementDiscouraged},
+				Description:   "Whether authenticators must verify the user, for instance with a PIN or biometrics. Only \"required\" is enforced.",
+			},
+			"resident_key": {
+				Type:          framework.TypeString,
+				Default:       requirementPreferred,
+				AllowedValues: []interface{}{requirementRequired, requirementPreferred, requirementDiscouraged},
+				Description:   "Whether credentials should be discoverable, allowing users to log in without a username.",
+			},
+			"challenge_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Default:     int(defaultChallengeTTL.Seconds()),
+				Description: "Duration for which challenges may be answered.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Challenge TTL",
+					Value: int(defaultChallengeTTL.Seconds()),
+				},
+			},
+		},
+
+		ExistenceCheck: b.configExistenceCheck,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "configuration",
+				},
+			},
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.pathConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "configure",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "configure",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigHelpSyn,
+		HelpDescription: pathConfigHelpDesc,
+	}
+}
+
+// ConfigEntry is the relying party configuration of the mount.
+type ConfigEntry struct {
+	RPID             string        `json:"rp_id"`
+	RPName           string        `json:"rp_name"`
+	RPOrigins        []string      `json:"rp_origins"`
+	UserVerification string        `json:"user_verification"`
+	ResidentKey      string        `json:"resident_key"`
+	ChallengeTTL     time.Duration `json:"challenge_ttl"`
+}
+
+func (b *backend) configExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return false, err
+	}
+	return config != nil, nil
+}
+
+func (b *backend) config(ctx context.Context, s logical.Storage) (*ConfigEntry, error) {
+	entry, err := s.Get(ctx, "config")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config ConfigEntry
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"rp_id":             config.RPID,
+			"rp_name":           config.RPName,
+			"rp_origins":        config.RPOrigins,
+			"user_verification": config.UserVerification,
+			"resident_key":      config.ResidentKey,
+			"challenge_ttl":     int64(config.ChallengeTTL.Seconds()),
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	// Defaults apply to fields left unset when configuring for the first
+	// time
+	isNew := config == nil
+	if isNew {
+		config = &ConfigEntry{}
+	}
+
+	if rpID, ok := d.GetOk("rp_id"); ok {
+		config.RPID = strings.ToLower(rpID.(string))
+	}
+	if config.RPID == "" {
+		return logical.ErrorResponse("missing rp_id"), nil
+	}
+	if strings.Contains(config.RPID, "/") || strings.Contains(config.RPID, ":") {
+		return logical.ErrorResponse("rp_id must be a domain, not a URL"), nil
+	}
+
+	if rpName, ok := d.GetOk("rp_name"); ok {
+		config.RPName = rpName.(string)
+	}
+
+	if origins, ok := d.GetOk("rp_origins"); ok {
+		config.RPOrigins = origins.([]string)
+	}
+	for _, origin := range config.RPOrigins {
+		if err := validateOrigin(origin, config.RPID); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	if uv, ok := d.GetOk("user_verification"); ok || isNew {
+		if !ok {
+			uv = d.Get("user_verification")
+		}
+		config.UserVerification = uv.(string)
+	}
+
+	if rk, ok := d.GetOk("resident_key"); ok || isNew {
+		if !ok {
+			rk = d.Get("resident_key")
+		}
+		config.ResidentKey = rk.(string)
+	}
+
+	if ttl, ok := d.GetOk("challenge_ttl"); ok || isNew {
+		if !ok {
+			ttl = d.Get("challenge_ttl")
+		}
+		config.ChallengeTTL = time.Duration(ttl.(int)) * time.Second
+	}
+	if config.ChallengeTTL <= 0 {
+		return logical.ErrorResponse("challenge_ttl must be positive"), nil
+	}
+
+	entry, err := logical.StorageEntryJSON("config", config)
+	if err != nil {
+		return nil, err
+	}
+	return nil, req.Storage.Put(ctx, entry)
+}
+
+// validateOrigin checks the origin is one credentials scoped to the relying
+// party ID may be used from: the ID itself or one of its subdomains.
+func validateOrigin(origin, rpID string) error {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return fmt.Errorf("invalid origin %q", origin)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != rpID && !strings.HasSuffix(host, "."+rpID) {
+		return fmt.Errorf("origin %q is not within the relying party ID %q", origin, rpID)
+	}
+	return nil
+}
+
+// origins returns the origins ceremonies may be performed from.
+func (c *ConfigEntry) origins() []string {
+	if len(c.RPOrigins) > 0 {
+		return c.RPOrigins
+	}
+	return []string{"https://" + c.RPID}
+}
+
+func (c *ConfigEntry) rpName() string {
+	if c.RPName != "" {
+		return c.RPName
+	}
+	return c.RPID
+}
+
+const pathConfigHelpSyn = `
+Configure the WebAuthn relying party.
+`
+
+const pathConfigHelpDesc = `
+This endpoint configures the relying party credentials are registered
+with and asserted to: its ID, which authenticators scope credentials
+to, and the origins from which ceremonies may be performed. It also
+sets the requirements passed on to authenticators and the duration for
+which challenges may be answered.
+`
@@ -0,0 +1,348 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/cidrutil"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/helper/policyutil"
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+func pathLoginBegin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "login/begin",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationVerb:   "begin",
+			OperationSuffix: "login",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the user logging in. Omit to log in with a discoverable credential.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathLoginBegin,
+		},
+
+		HelpSynopsis:    pathLoginBeginSyn,
+		HelpDescription: pathLoginBeginDesc,
+	}
+}
+
+func pathLogin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "login$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationVerb:   "login",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"credential_id": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded ID of the credential used.",
+			},
+			"client_data_json": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded client data JSON of the authenticator response.",
+			},
+			"authenticator_data": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded authenticator data of the authenticator response.",
+			},
+			"signature": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded signature of the authenticator response.",
+			},
+			"user_handle": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded user handle of the authenticator response. Required when logging in with a discoverable credential.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation:         b.pathLogin,
+			logical.AliasLookaheadOperation: b.pathLoginAliasLookahead,
+		},
+
+		HelpSynopsis:    pathLoginSyn,
+		HelpDescription: pathLoginDesc,
+	}
+}
+
+func (b *backend) pathLoginBegin(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse(errNotConfigured.Error()), logical.ErrInvalidRequest
+	}
+
+	// Unknown users are issued a challenge all the same, so that they
+	// cannot be told apart from users without credentials
+	username := strings.ToLower(d.Get("username").(string))
+	var creds []*Credential
+	if username != "" {
+		user, err := b.user(ctx, req.Storage, username)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			creds = user.Credentials
+		}
+	}
+
+	challenge, err := b.newChallenge(ctx, req.Storage, ceremonyAuthentication, username, config.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"challenge":         webauthnutil.EncodeBase64(challenge),
+			"timeout":           config.ChallengeTTL.Milliseconds(),
+			"rp_id":             config.RPID,
+			"user_verification": config.UserVerification,
+			"allow_credentials": credentialDescriptors(creds),
+		},
+	}, nil
+}
+
+func (b *backend) pathLoginAliasLookahead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	id, err := webauthnutil.DecodeBase64(d.Get("credential_id").(string))
+	if err != nil || len(id) == 0 {
+		return nil, fmt.Errorf("missing or invalid credential_id")
+	}
+
+	username, err := b.credentialUser(ctx, req.Storage, id)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" {
+		return nil, fmt.Errorf("unknown credential")
+	}
+
+	return &logical.Response{
+		Auth: &logical.Auth{
+			Alias: &logical.Alias{
+				Name: username,
+			},
+		},
+	}, nil
+}
+
+func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse(errNotConfigured.Error()), logical.ErrInvalidRequest
+	}
+
+	id, err := webauthnutil.DecodeBase64(d.Get("credential_id").(string))
+	if err != nil || len(id) == 0 {
+		return logical.ErrorResponse("missing or invalid credential_id"), logical.ErrInvalidRequest
+	}
+	rawClientData, err := webauthnutil.DecodeBase64(d.Get("client_data_json").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid client_data_json: %s", err), logical.ErrInvalidRequest
+	}
+	rawAuthData, err := webauthnutil.DecodeBase64(d.Get("authenticator_data").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid authenticator_data: %s", err), logical.ErrInvalidRequest
+	}
+	signature, err := webauthnutil.DecodeBase64(d.Get("signature").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid signature: %s", err), logical.ErrInvalidRequest
+	}
+	var userHandle []byte
+	if raw, ok := d.GetOk("user_handle"); ok && raw.(string) != "" {
+		userHandle, err = webauthnutil.DecodeBase64(raw.(string))
+		if err != nil {
+			return logical.ErrorResponse("invalid user_handle: %s", err), logical.ErrInvalidRequest
+		}
+	}
+
+	clientData, err := webauthnutil.ParseClientData(rawClientData, webauthnutil.ClientDataTypeGet)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	// The challenge is consumed before anything else is verified, so that
+	// it cannot be reused whatever the outcome
+	challenge, err := b.consumeChallenge(ctx, req.Storage, clientData.Challenge, ceremonyAuthentication)
+	if err != nil {
+		if errors.Is(err, errInvalidChallenge) {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		return nil, err
+	}
+	if err := config.verifyClientData(clientData); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	username, err := b.credentialUser(ctx, req.Storage, id)
+	if err != nil {
+		return nil, err
+	}
+	if username == "" {
+		return logical.ErrorResponse("invalid credential"), nil
+	}
+	if challenge.Username != "" && challenge.Username != username {
+		return logical.ErrorResponse("invalid credential"), logical.ErrInvalidCredentials
+	}
+	// Discoverable credentials are selected by the authenticator, which
+	// must then tell which user they belong to
+	if challenge.Username == "" && userHandle == nil {
+		return logical.ErrorResponse("missing user_handle"), logical.ErrInvalidRequest
+	}
+
+	lock := locksutil.LockForKey(b.userLocks, username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return logical.ErrorResponse("invalid credential"), nil
+	}
+	cred := user.credential(id)
+	if cred == nil {
+		return logical.ErrorResponse("invalid credential"), nil
+	}
+	if userHandle != nil && subtle.ConstantTimeCompare(userHandle, user.UserHandle) != 1 {
+		return logical.ErrorResponse("user handle mismatch"), logical.ErrInvalidCredentials
+	}
+
+	pubKey, err := webauthnutil.ParseCOSEKey(cred.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored public key: %w", err)
+	}
+	authData, err := webauthnutil.VerifyAssertion(&webauthnutil.Assertion{
+		AuthenticatorData: rawAuthData,
+		ClientDataJSON:    rawClientData,
+		Signature:         signature,
+	}, pubKey, config.RPID, config.UserVerification == requirementRequired)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidCredentials
+	}
+
+	// Authenticators supporting signature counters increase them with each
+	// assertion, so that a counter going backwards reveals that the
+	// credential was cloned and is being used from two authenticators
+	if (authData.SignCount != 0 || cred.SignCount != 0) && authData.SignCount <= cred.SignCount {
+		b.Logger().Warn("signature counter did not increase, the authenticator may have been cloned",
+			"username", username, "credential_id", webauthnutil.EncodeBase64(cred.ID),
+			"stored_sign_count", cred.SignCount, "sign_count", authData.SignCount)
+		return logical.ErrorResponse("signature counter did not increase, the authenticator may have been cloned"), logical.ErrInvalidCredentials
+	}
+
+	cred.SignCount = authData.SignCount
+	cred.LastUsedTime = time.Now().UTC()
+	if err := b.setUser(ctx, req.Storage, username, user); err != nil {
+		return nil, err
+	}
+
+	// Check for a CIDR match.
+	if len(user.TokenBoundCIDRs) > 0 {
+		if req.Connection == nil {
+			b.Logger().Warn("token bound CIDRs found but no connection information available for validation")
+			return nil, logical.ErrPermissionDenied
+		}
+		if !cidrutil.RemoteAddrIsOk(req.Connection.RemoteAddr, user.TokenBoundCIDRs) {
+			return nil, logical.ErrPermissionDenied
+		}
+	}
+
+	auth := &logical.Auth{
+		Metadata: map[string]string{
+			"username":      username,
+			"credential_id": webauthnutil.EncodeBase64(cred.ID),
+		},
+		DisplayName: username,
+		Alias: &logical.Alias{
+			Name: username,
+		},
+	}
+	if err := user.PopulateTokenAuth(auth, req); err != nil {
+		return nil, fmt.Errorf("failed to populate auth information: %w", err)
+	}
+
+	return &logical.Response{
+		Auth: auth,
+	}, nil
+}
+
+func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	// Get the user
+	user, err := b.user(ctx, req.Storage, req.Auth.Metadata["username"])
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		// User no longer exists, do not renew
+		return nil, nil
+	}
+
+	// Neither do credentials which were deleted
+	id, err := webauthnutil.DecodeBase64(req.Auth.Metadata["credential_id"])
+	if err != nil || user.credential(id) == nil {
+		return nil, fmt.Errorf("credential no longer exists, not renewing")
+	}
+
+	if !policyutil.EquivalentPolicies(user.TokenPolicies, req.Auth.TokenPolicies) {
+		return nil, fmt.Errorf("policies have changed, not renewing")
+	}
+
+	resp := &logical.Response{Auth: req.Auth}
+	resp.Auth.Period = user.TokenPeriod
+	resp.Auth.TTL = user.TokenTTL
+	resp.Auth.MaxTTL = user.TokenMaxTTL
+	return resp, nil
+}
+
+const pathLoginBeginSyn = `
+Begin logging in with a WebAuthn credential.
+`
+
+const pathLoginBeginDesc = `
+This endpoint returns the options to pass to navigator.credentials.get(),
+including a single-use challenge to answer through the "login" endpoint.
+
+When a username is given, the credentials registered for the user are
+listed for the authenticator to choose from. Otherwise, the list is empty
+and the authenticator picks one of its discoverable credentials.
+`
+
+const pathLoginSyn = `
+Log in with a WebAuthn credential.
+`
+
+const pathLoginDesc = `
+This endpoint authenticates using the assertion returned by an
+authenticator for a challenge issued by "login/begin". The signature is
+verified against the public key registered for the credential, and the
+signature counter of the credential must increase unless the
+authenticator does not implement one.
+`
@@ -0,0 +1,296 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+var errNotConfigured = errors.New("WebAuthn is not configured")
+
+func pathRegisterBegin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "users/" + framework.GenericNameRegex("username") + "/register/begin",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationVerb:   "begin",
+			OperationSuffix: "registration",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the user to register a credential for.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRegisterBegin,
+		},
+
+		HelpSynopsis:    pathRegisterHelpSyn,
+		HelpDescription: pathRegisterHelpDesc,
+	}
+}
+
+func pathRegisterFinish(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "users/" + framework.GenericNameRegex("username") + "/register/finish",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationVerb:   "finish",
+			OperationSuffix: "registration",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the user to register a credential for.",
+			},
+			"client_data_json": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded client data JSON of the authenticator response.",
+			},
+			"attestation_object": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded attestation object of the authenticator response.",
+			},
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Optional name describing the credential.",
+			},
+			"discoverable": {
+				Type:        framework.TypeBool,
+				Description: "Whether the authenticator created a discoverable credential, as reported by the credProps extension. Assumed when resident keys are required.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRegisterFinish,
+		},
+
+		HelpSynopsis:    pathRegisterHelpSyn,
+		HelpDescription: pathRegisterHelpDesc,
+	}
+}
+
+func (b *backend) pathRegisterBegin(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse(errNotConfigured.Error()), logical.ErrInvalidRequest
+	}
+
+	username := strings.ToLower(d.Get("username").(string))
+	user, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return logical.ErrorResponse("unknown user %q", username), logical.ErrInvalidRequest
+	}
+
+	challenge, err := b.newChallenge(ctx, req.Storage, ceremonyRegistration, username, config.ChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]map[string]interface{}, 0, len(webauthnutil.SupportedCOSEAlgorithms))
+	for _, alg := range webauthnutil.SupportedCOSEAlgorithms {
+		params = append(params, map[string]interface{}{
+			"type": "public-key",
+			"alg":  alg,
+		})
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"challenge": webauthnutil.EncodeBase64(challenge),
+			"timeout":   config.ChallengeTTL.Milliseconds(),
+			"rp": map[string]interface{}{
+				"id":   config.RPID,
+				"name": config.rpName(),
+			},
+			"user": map[string]interface{}{
+				"id":           webauthnutil.EncodeBase64(user.UserHandle),
+				"name":         username,
+				"display_name": user.displayName(username),
+			},
+			"pub_key_cred_params": params,
+			"exclude_credentials": credentialDescriptors(user.Credentials),
+			"authenticator_selection": map[string]interface{}{
+				"resident_key":         config.ResidentKey,
+				"require_resident_key": config.ResidentKey == requirementRequired,
+				"user_verification":    config.UserVerification,
+			},
+			"attestation": "none",
+		},
+	}, nil
+}
+
+func (b *backend) pathRegisterFinish(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return logical.ErrorResponse(errNotConfigured.Error()), logical.ErrInvalidRequest
+	}
+
+	username := strings.ToLower(d.Get("username").(string))
+
+	rawClientData, err := webauthnutil.DecodeBase64(d.Get("client_data_json").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid client_data_json: %s", err), logical.ErrInvalidRequest
+	}
+	clientData, err := webauthnutil.ParseClientData(rawClientData, webauthnutil.ClientDataTypeCreate)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	// The challenge is consumed before anything else is verified, so that
+	// it cannot be reused whatever the outcome
+	challenge, err := b.consumeChallenge(ctx, req.Storage, clientData.Challenge, ceremonyRegistration)
+	if err != nil {
+		if errors.Is(err, errInvalidChallenge) {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		return nil, err
+	}
+	if challenge.Username != username {
+		return logical.ErrorResponse("challenge was issued for another user"), logical.ErrInvalidRequest
+	}
+	if err := config.verifyClientData(clientData); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	rawAttestation, err := webauthnutil.DecodeBase64(d.Get("attestation_object").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid attestation_object: %s", err), logical.ErrInvalidRequest
+	}
+	rawAuthData, err := webauthnutil.ParseAttestationObject(rawAttestation)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	authData, err := webauthnutil.ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if err := authData.Verify(config.RPID, config.UserVerification == requirementRequired); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if authData.CredentialID == nil {
+		return logical.ErrorResponse("authenticator data is missing the attested credential"), logical.ErrInvalidRequest
+	}
+	pubKey, err := webauthnutil.ParseCOSEKey(authData.PublicKey)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	lock := locksutil.LockForKey(b.userLocks, username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return logical.ErrorResponse("unknown user %q", username), logical.ErrInvalidRequest
+	}
+
+	owner, err := b.credentialUser(ctx, req.Storage, authData.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" {
+		return logical.ErrorResponse("credential is already registered"), logical.ErrInvalidRequest
+	}
+
+	cred := &Credential{
+		ID:           authData.CredentialID,
+		Name:         d.Get("name").(string),
+		PublicKey:    authData.PublicKey,
+		Algorithm:    pubKey.Algorithm(),
+		SignCount:    authData.SignCount,
+		Discoverable: d.Get("discoverable").(bool) || config.ResidentKey == requirementRequired,
+		CreationTime: time.Now().UTC(),
+	}
+
+	index, err := logical.StorageEntryJSON(credentialKey(cred.ID), &credentialIndexEntry{
+		Username: username,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, index); err != nil {
+		return nil, err
+	}
+
+	user.Credentials = append(user.Credentials, cred)
+	if err := b.setUser(ctx, req.Storage, username, user); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: cred.toResponseData(),
+	}, nil
+}
+
+// verifyClientData checks the ceremony was performed from one of the
+// configured origins.
+func (c *ConfigEntry) verifyClientData(data *webauthnutil.ClientData) error {
+	if data.CrossOrigin {
+		return errors.New("cross-origin ceremonies are not allowed")
+	}
+	for _, origin := range c.origins() {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", data.Origin)
+}
+
+// credentialDescriptors lists the credentials in the form passed on to
+// clients to select or exclude them.
+func credentialDescriptors(creds []*Credential) []map[string]interface{} {
+	descriptors := make([]map[string]interface{}, 0, len(creds))
+	for _, cred := range creds {
+		descriptors = append(descriptors, map[string]interface{}{
+			"type": "public-key",
+			"id":   webauthnutil.EncodeBase64(cred.ID),
+		})
+	}
+	return descriptors
+}
+
+const pathRegisterHelpSyn = `
+Register a WebAuthn credential for a user.
+`
+
+const pathRegisterHelpDesc = `
+Registering a credential is a two-step ceremony. The "begin" endpoint
+returns the options to pass to navigator.credentials.create(),
+including a single-use challenge. The "finish" endpoint then takes the
+client data JSON and attestation object of the authenticator response,
+verifies them against the challenge and relying party configuration,
+and stores the public key of the credential for the user.
+
+Attestation statements are not verified: authenticators are trusted on
+the basis of the caller being authorized to register credentials for
+the user.
+`
@@ -0,0 +1,430 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthn
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/helper/tokenutil"
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// userHandleLen is the length of the random user handles identifying users
+// to authenticators, which must not carry personal information.
+const userHandleLen = 32
+
+func pathUsersList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "users/?",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationSuffix: "users",
+			Navigation:      true,
+			ItemType:        "User",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"after": {
+				Type:        framework.TypeString,
+				Description: `Optional entry to list begin listing after, not required to exist.`,
+			},
+			"limit": {
+				Type:        framework.TypeInt,
+				Description: `Optional number of entries to return; defaults to all entries.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathUserList,
+		},
+
+		HelpSynopsis:    pathUserHelpSyn,
+		HelpDescription: pathUserHelpDesc,
+	}
+}
+
+func pathUsers(b *backend) *framework.Path {
+	p := &framework.Path{
+		Pattern: "users/" + framework.GenericNameRegex("username"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationSuffix: "user",
+			Action:          "Create",
+			ItemType:        "User",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username for this user.",
+			},
+
+			"display_name": {
+				Type:        framework.TypeString,
+				Description: "Human-readable name shown by authenticators. Defaults to the username.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.DeleteOperation: b.pathUserDelete,
+			logical.ReadOperation:   b.pathUserRead,
+			logical.UpdateOperation: b.pathUserWrite,
+			logical.CreateOperation: b.pathUserWrite,
+		},
+
+		ExistenceCheck: b.userExistenceCheck,
+
+		HelpSynopsis:    pathUserHelpSyn,
+		HelpDescription: pathUserHelpDesc,
+	}
+
+	tokenutil.AddTokenFields(p.Fields)
+	return p
+}
+
+func pathUserCredentials(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "users/" + framework.GenericNameRegex("username") + "/credentials/(?P<credential_id>[A-Za-z0-9_-]+)",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixWebAuthn,
+			OperationSuffix: "user-credential",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the user.",
+			},
+			"credential_id": {
+				Type:        framework.TypeString,
+				Description: "Base64url-encoded ID of the credential.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathUserCredentialRead,
+			logical.DeleteOperation: b.pathUserCredentialDelete,
+		},
+
+		HelpSynopsis:    pathUserCredentialsHelpSyn,
+		HelpDescription: pathUserCredentialsHelpDesc,
+	}
+}
+
+// UserEntry is a user along with the credentials registered for it.
+type UserEntry struct {
+	tokenutil.TokenParams
+
+	DisplayName string `json:"display_name"`
+
+	// UserHandle identifies the user to authenticators, and is returned by
+	// them when logging in with a discoverable credential.
+	UserHandle []byte `json:"user_handle"`
+
+	Credentials []*Credential `json:"credentials"`
+}
+
+// Credential is a public key credential registered for a user.
+type Credential struct {
+	ID        []byte `json:"id"`
+	Name      string `json:"name"`
+	PublicKey []byte `json:"public_key"`
+	Algorithm int64  `json:"algorithm"`
+
+	// SignCount is the last signature counter reported by the
+	// authenticator, which must increase with each login if it is
+	// supported at all.
+	SignCount uint32 `json:"sign_count"`
+
+	Discoverable bool      `json:"discoverable"`
+	CreationTime time.Time `json:"creation_time"`
+	LastUsedTime time.Time `json:"last_used_time"`
+}
+
+// credentialIndexEntry maps a credential ID to the user it is registered
+// for, so that discoverable credentials can be looked up without a
+// username and that credentials are only registered once.
+type credentialIndexEntry struct {
+	Username string `json:"username"`
+}
+
+func credentialKey(id []byte) string {
+	sum := sha256.Sum256(id)
+	return credentialPrefix + hex.EncodeToString(sum[:])
+}
+
+func (u *UserEntry) credential(id []byte) *Credential {
+	for _, cred := range u.Credentials {
+		if bytes.Equal(cred.ID, id) {
+			return cred
+		}
+	}
+	return nil
+}
+
+func (u *UserEntry) displayName(username string) string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return username
+}
+
+func (c *Credential) toResponseData() map[string]interface{} {
+	data := map[string]interface{}{
+		"id":            webauthnutil.EncodeBase64(c.ID),
+		"name":          c.Name,
+		"algorithm":     c.Algorithm,
+		"sign_count":    c.SignCount,
+		"discoverable":  c.Discoverable,
+		"creation_time": c.CreationTime.Format(time.RFC3339),
+	}
+	if !c.LastUsedTime.IsZero() {
+		data["last_used_time"] = c.LastUsedTime.Format(time.RFC3339)
+	}
+	return data
+}
+
+func (b *backend) userExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	userEntry, err := b.user(ctx, req.Storage, d.Get("username").(string))
+	if err != nil {
+		return false, err
+	}
+
+	return userEntry != nil, nil
+}
+
+func (b *backend) user(ctx context.Context, s logical.Storage, username string) (*UserEntry, error) {
+	if username == "" {
+		return nil, fmt.Errorf("missing username")
+	}
+
+	entry, err := s.Get(ctx, userPrefix+strings.ToLower(username))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result UserEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) setUser(ctx context.Context, s logical.Storage, username string, userEntry *UserEntry) error {
+	entry, err := logical.StorageEntryJSON(userPrefix+username, userEntry)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(ctx, entry)
+}
+
+// credentialUser returns the name of the user the credential is registered
+// for, if any.
+func (b *backend) credentialUser(ctx context.Context, s logical.Storage, id []byte) (string, error) {
+	entry, err := s.Get(ctx, credentialKey(id))
+	if err != nil || entry == nil {
+		return "", err
+	}
+
+	var index credentialIndexEntry
+	if err := entry.DecodeJSON(&index); err != nil {
+		return "", err
+	}
+	return index.Username, nil
+}
+
+func (b *backend) pathUserList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	after := data.Get("after").(string)
+	limit := data.Get("limit").(int)
+	if limit <= 0 {
+		limit = -1
+	}
+
+	users, err := req.Storage.ListPage(ctx, userPrefix, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(users), nil
+}
+
+func (b *backend) pathUserDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	username := strings.ToLower(d.Get("username").(string))
+
+	lock := locksutil.LockForKey(b.userLocks, username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	for _, cred := range user.Credentials {
+		if err := req.Storage.Delete(ctx, credentialKey(cred.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, req.Storage.Delete(ctx, userPrefix+username)
+}
+
+func (b *backend) pathUserRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	user, err := b.user(ctx, req.Storage, strings.ToLower(d.Get("username").(string)))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	creds := make([]map[string]interface{}, 0, len(user.Credentials))
+	for _, cred := range user.Credentials {
+		creds = append(creds, cred.toResponseData())
+	}
+
+	data := map[string]interface{}{
+		"display_name": user.DisplayName,
+		"user_handle":  webauthnutil.EncodeBase64(user.UserHandle),
+		"credentials":  creds,
+	}
+	user.PopulateTokenData(data)
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (b *backend) pathUserWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	username := strings.ToLower(d.Get("username").(string))
+
+	lock := locksutil.LockForKey(b.userLocks, username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	userEntry, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	// Due to existence check, user will only be nil if it's a create operation
+	if userEntry == nil {
+		userEntry = &UserEntry{
+			UserHandle: make([]byte, userHandleLen),
+		}
+		if _, err := rand.Read(userEntry.UserHandle); err != nil {
+			return nil, fmt.Errorf("failed to generate user handle: %w", err)
+		}
+	}
+
+	if err := userEntry.ParseTokenFields(req, d); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	if displayName, ok := d.GetOk("display_name"); ok {
+		userEntry.DisplayName = displayName.(string)
+	}
+
+	return nil, b.setUser(ctx, req.Storage, username, userEntry)
+}
+
+func (b *backend) pathUserCredentialRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	id, err := webauthnutil.DecodeBase64(d.Get("credential_id").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid credential_id"), logical.ErrInvalidRequest
+	}
+
+	user, err := b.user(ctx, req.Storage, strings.ToLower(d.Get("username").(string)))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	cred := user.credential(id)
+	if cred == nil {
+		return nil, nil
+	}
+	return &logical.Response{
+		Data: cred.toResponseData(),
+	}, nil
+}
+
+func (b *backend) pathUserCredentialDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	id, err := webauthnutil.DecodeBase64(d.Get("credential_id").(string))
+	if err != nil {
+		return logical.ErrorResponse("invalid credential_id"), logical.ErrInvalidRequest
+	}
+	username := strings.ToLower(d.Get("username").(string))
+
+	lock := locksutil.LockForKey(b.userLocks, username)
+	lock.Lock()
+	defer lock.Unlock()
+
+	user, err := b.user(ctx, req.Storage, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.credential(id) == nil {
+		return nil, nil
+	}
+
+	creds := make([]*Credential, 0, len(user.Credentials)-1)
+	for _, cred := range user.Credentials {
+		if !bytes.Equal(cred.ID, id) {
+			creds = append(creds, cred)
+		}
+	}
+	user.Credentials = creds
+
+	if err := b.setUser(ctx, req.Storage, username, user); err != nil {
+		return nil, err
+	}
+	return nil, req.Storage.Delete(ctx, credentialKey(id))
+}
+
+const pathUserHelpSyn = `
+Manage users allowed to authenticate.
+`
+
+const pathUserHelpDesc = `
+This endpoint allows you to create, read, update, and delete users
+that are allowed to authenticate, along with the token parameters of
+their logins. Credentials are registered for users through the
+"users/<username>/register/" endpoints, and listed when reading them.
+
+Deleting a user deletes its credentials, but will not revoke auth for
+prior authenticated users with that name. The next renew will cause the
+lease to expire.
+`
+
+const pathUserCredentialsHelpSyn = `
+Read or delete a credential registered for a user.
+`
+
+const pathUserCredentialsHelpDesc = `
+This endpoint reads or deletes a credential registered for a user, by
+its base64url-encoded ID. Once deleted, the credential can no longer be
+used to log in.
+`
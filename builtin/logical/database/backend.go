@@ -24,6 +24,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/openbao/openbao/sdk/v2/queue"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -41,6 +42,10 @@ type dbPluginInstance struct {
 	id     string
 	name   string
 	closed bool
+
+	// validationLimiter limits the rate at which users are validated when
+	// renewing their leases.
+	validationLimiter *rate.Limiter
 }
 
 func (dbi *dbPluginInstance) Close() error {
@@ -329,6 +334,48 @@ func (b *databaseBackend) GetConnection(ctx context.Context, s logical.Storage,
 	return b.GetConnectionWithConfig(ctx, name, config)
 }
 
+// GetConnectionRLocked returns the named connection with its read lock held.
+// Rotating the root credential drains the operations holding the read lock
+// and then closes the connection, so a connection found to be closed once the
+// lock is acquired is replaced by one using the current configuration.
+func (b *databaseBackend) GetConnectionRLocked(ctx context.Context, s logical.Storage, name string) (*dbPluginInstance, error) {
+	return b.getConnectionLocked(ctx, s, name, false)
+}
+
+// GetConnectionLocked is like GetConnectionRLocked, but holds the write lock.
+func (b *databaseBackend) GetConnectionLocked(ctx context.Context, s logical.Storage, name string) (*dbPluginInstance, error) {
+	return b.getConnectionLocked(ctx, s, name, true)
+}
+
+func (b *databaseBackend) getConnectionLocked(ctx context.Context, s logical.Storage, name string, write bool) (*dbPluginInstance, error) {
+	for {
+		dbi, err := b.GetConnection(ctx, s, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if write {
+			dbi.Lock()
+		} else {
+			dbi.RLock()
+		}
+		if !dbi.closed {
+			return dbi, nil
+		}
+		if write {
+			dbi.Unlock()
+		} else {
+			dbi.RUnlock()
+		}
+
+		// The closed connection may not have been removed yet
+		b.connPopIfEqual(name, dbi.id)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
 func (b *databaseBackend) GetConnectionWithConfig(ctx context.Context, name string, config *DatabaseConfig) (*dbPluginInstance, error) {
 	dbi := b.connGet(name)
 	if dbi != nil {
@@ -356,9 +403,10 @@ func (b *databaseBackend) GetConnectionWithConfig(ctx context.Context, name stri
 	}
 
 	dbi = &dbPluginInstance{
-		database: dbw,
-		id:       id,
-		name:     name,
+		database:          dbw,
+		id:                id,
+		name:              name,
+		validationLimiter: newRenewValidationLimiter(config),
 	}
 	oldConn := b.connPut(name, dbi)
 	if oldConn != nil {
@@ -48,6 +48,17 @@ func (m *mockNewDatabase) Close() error {
 	return args.Error(0)
 }
 
+var _ v5.UserValidator = &mockValidatingDatabase{}
+
+type mockValidatingDatabase struct {
+	mockNewDatabase
+}
+
+func (m *mockValidatingDatabase) ValidateUser(ctx context.Context, req v5.ValidateUserRequest) (v5.ValidateUserResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(v5.ValidateUserResponse), args.Error(1)
+}
+
 var _ v4.Database = &mockLegacyDatabase{}
 
 type mockLegacyDatabase struct {
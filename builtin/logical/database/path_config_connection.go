@@ -40,6 +40,10 @@ type DatabaseConfig struct {
 	RootCredentialsRotateStatements []string `json:"root_credentials_rotate_statements" structs:"root_credentials_rotate_statements" mapstructure:"root_credentials_rotate_statements"`
 
 	PasswordPolicy string `json:"password_policy" structs:"password_policy" mapstructure:"password_policy"`
+
+	// RenewValidationRateLimit is the maximum number of users validated per
+	// second when renewing the leases of roles with validate_on_renew set.
+	RenewValidationRateLimit int `json:"renew_validation_rate_limit" structs:"renew_validation_rate_limit" mapstructure:"renew_validation_rate_limit"`
 }
 
 func (c *DatabaseConfig) SupportsCredentialType(credentialType v5.CredentialType) bool {
@@ -161,6 +165,13 @@ func pathConfigurePluginConnection(b *databaseBackend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: `Password policy to use when generating passwords.`,
 			},
+			"renew_validation_rate_limit": {
+				Type:    framework.TypeInt,
+				Default: defaultRenewValidationRateLimit,
+				Description: `Maximum number of users validated per second when
+				renewing the leases of roles with validate_on_renew set. Renewals
+				above this rate are performed without validating the user.`,
+			},
 		},
 
 		ExistenceCheck: b.connectionExistenceCheck(),
@@ -413,6 +424,15 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 			config.PasswordPolicy = passwordPolicyRaw.(string)
 		}
 
+		if rateLimitRaw, ok := data.GetOk("renew_validation_rate_limit"); ok {
+			config.RenewValidationRateLimit = rateLimitRaw.(int)
+		} else if req.Operation == logical.CreateOperation {
+			config.RenewValidationRateLimit = data.Get("renew_validation_rate_limit").(int)
+		}
+		if config.RenewValidationRateLimit < 0 {
+			return logical.ErrorResponse("renew_validation_rate_limit must not be negative"), nil
+		}
+
 		// Remove these entries from the data before we store it keyed under
 		// ConnectionDetails.
 		delete(data.Raw, "name")
@@ -422,6 +442,7 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 		delete(data.Raw, "verify_connection")
 		delete(data.Raw, "root_rotation_statements")
 		delete(data.Raw, "password_policy")
+		delete(data.Raw, "renew_validation_rate_limit")
 
 		id, err := uuid.GenerateUUID()
 		if err != nil {
@@ -463,9 +484,10 @@ func (b *databaseBackend) connectionWriteHandler() framework.OperationFunc {
 
 		// Close and remove the old connection
 		oldConn := b.connPut(name, &dbPluginInstance{
-			database: dbw,
-			name:     name,
-			id:       id,
+			database:          dbw,
+			name:              name,
+			id:                id,
+			validationLimiter: newRenewValidationLimiter(config),
 		})
 		if oldConn != nil {
 			oldConn.Close()
@@ -97,12 +97,10 @@ func (b *databaseBackend) pathCredsCreateRead() framework.OperationFunc {
 		}
 
 		// Get the Database object
-		dbi, err := b.GetConnection(ctx, req.Storage, role.DBName)
+		dbi, err := b.GetConnectionRLocked(ctx, req.Storage, role.DBName)
 		if err != nil {
 			return nil, err
 		}
-
-		dbi.RLock()
 		defer dbi.RUnlock()
 
 		ttl, _, err := framework.CalculateTTL(b.System(), 0, role.DefaultTTL, 0, role.MaxTTL, 0, time.Time{})
@@ -201,6 +201,13 @@ func dynamicFields() map[string]*framework.FieldSchema {
 	type will support this functionality. See the plugin's API page for
 	more information on support and formatting for this parameter.`,
 		},
+		"validate_on_renew": {
+			Type: framework.TypeBool,
+			Description: `Whether to check that the user still exists within
+	the database before renewing its lease. Leases of users which no longer
+	exist are revoked. Not every plugin type will support this
+	functionality.`,
+		},
 	}
 	return fields
 }
@@ -351,6 +358,7 @@ func (b *databaseBackend) pathRoleRead(ctx context.Context, req *logical.Request
 		"default_ttl":           role.DefaultTTL.Seconds(),
 		"max_ttl":               role.MaxTTL.Seconds(),
 		"credential_type":       role.CredentialType.String(),
+		"validate_on_renew":     role.ValidateOnRenew,
 	}
 	if len(role.CredentialConfig) > 0 {
 		data["credential_config"] = role.CredentialConfig
@@ -495,6 +503,10 @@ func (b *databaseBackend) pathRoleCreateUpdate(ctx context.Context, req *logical
 		}
 	}
 
+	if validateOnRenewRaw, ok := data.GetOk("validate_on_renew"); ok {
+		role.ValidateOnRenew = validateOnRenewRaw.(bool)
+	}
+
 	// Store it
 	entry, err := logical.StorageEntryJSON(databaseRolePath+name, role)
 	if err != nil {
@@ -670,6 +682,7 @@ type roleEntry struct {
 	CredentialType   v5.CredentialType      `json:"credential_type"`
 	CredentialConfig map[string]interface{} `json:"credential_config"`
 	StaticAccount    *staticAccount         `json:"static_account" mapstructure:"static_account"`
+	ValidateOnRenew  bool                   `json:"validate_on_renew"`
 }
 
 // setCredentialType sets the credential type for the role given its string form.
@@ -839,6 +852,12 @@ The "renew_statements" parameter customizes the statement string used to renew a
 user.
 The "rollback_statements' parameter customizes the statement string used to
 rollback a change if needed.
+
+The "validate_on_renew" parameter makes renewals check that the user still
+exists within the database first. If it does not, e.g. as it was dropped outside
+of OpenBao, the lease is revoked instead of renewed. These checks are rate
+limited per connection, see the "renew_validation_rate_limit" parameter of the
+connection configuration.
 `
 
 const pathStaticRoleHelpDesc = `
@@ -81,28 +81,13 @@ func (b *databaseBackend) pathRotateRootCredentialsUpdate() framework.OperationF
 			return logical.ErrorResponse(respErrEmptyName), nil
 		}
 
-		config, err := b.DatabaseConfig(ctx, req.Storage, name)
-		if err != nil {
-			return nil, err
-		}
-
-		rootUsername, ok := config.ConnectionDetails["username"].(string)
-		if !ok || rootUsername == "" {
-			return nil, fmt.Errorf("unable to rotate root credentials: no username in configuration")
-		}
-
-		rootPassword, ok := config.ConnectionDetails["password"].(string)
-		if !ok || rootPassword == "" {
-			return nil, fmt.Errorf("unable to rotate root credentials: no password in configuration")
-		}
-
-		dbi, err := b.GetConnection(ctx, req.Storage, name)
+		// Take the write lock on the instance, which waits for in-flight
+		// operations on it to finish. New operations wait for the rotation
+		// and then use a new connection with the rotated credential.
+		dbi, err := b.GetConnectionLocked(ctx, req.Storage, name)
 		if err != nil {
 			return nil, err
 		}
-
-		// Take the write lock on the instance
-		dbi.Lock()
 		defer func() {
 			dbi.Unlock()
 			// Even on error, still remove the connection
@@ -116,6 +101,23 @@ func (b *databaseBackend) pathRotateRootCredentialsUpdate() framework.OperationF
 			}
 		}()
 
+		// Read the configuration under the lock, so that concurrent
+		// rotations each start from the credential stored by the last
+		config, err := b.DatabaseConfig(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+
+		rootUsername, ok := config.ConnectionDetails["username"].(string)
+		if !ok || rootUsername == "" {
+			return nil, fmt.Errorf("unable to rotate root credentials: no username in configuration")
+		}
+
+		rootPassword, ok := config.ConnectionDetails["password"].(string)
+		if !ok || rootPassword == "" {
+			return nil, fmt.Errorf("unable to rotate root credentials: no password in configuration")
+		}
+
 		generator, err := newPasswordGenerator(nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to construct credential generator: %s", err)
@@ -357,12 +357,10 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	}
 
 	// Get the Database object
-	dbi, err := b.GetConnection(ctx, s, input.Role.DBName)
+	dbi, err := b.GetConnectionRLocked(ctx, s, input.Role.DBName)
 	if err != nil {
 		return output, err
 	}
-
-	dbi.RLock()
 	defer dbi.RUnlock()
 
 	updateReq := v5.UpdateUserRequest{
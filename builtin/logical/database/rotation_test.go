@@ -1274,6 +1274,53 @@ func requireWALs(t *testing.T, storage logical.Storage, expectedCount int) []str
 	return wals
 }
 
+func TestGetConnectionRLocked_RetriesClosedConnection(t *testing.T) {
+	ctx := context.Background()
+	b, storage, _ := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	// Hold the write lock as a root credential rotation would
+	old := b.connGet("mockv5")
+	old.Lock()
+
+	type result struct {
+		dbi *dbPluginInstance
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		dbi, err := b.GetConnectionRLocked(ctx, storage, "mockv5")
+		done <- result{dbi, err}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the operation to wait for the rotation")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The rotation closes the connection, and it is replaced before the
+	// operation acquires the lock
+	replacement := &dbPluginInstance{
+		database: old.database,
+		id:       "bar-id",
+		name:     "mockV5",
+	}
+	old.closed = true
+	b.connPut("mockv5", replacement)
+	old.Unlock()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if res.dbi != replacement {
+		t.Fatalf("expected the replacement connection, got %q", res.dbi.id)
+	}
+	res.dbi.RUnlock()
+}
+
 func getBackend(t *testing.T) (*databaseBackend, logical.Storage, *mockNewDatabase) {
 	t.Helper()
 	config := logical.TestBackendConfig()
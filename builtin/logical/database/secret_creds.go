@@ -5,6 +5,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,9 +13,26 @@ import (
 	v5 "github.com/openbao/openbao/sdk/v2/database/dbplugin/v5"
 	"github.com/openbao/openbao/sdk/v2/framework"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"golang.org/x/time/rate"
 )
 
-const SecretCredsType = "creds"
+const (
+	SecretCredsType = "creds"
+
+	// defaultRenewValidationRateLimit is the default maximum number of users
+	// validated per second and per connection when renewing leases.
+	defaultRenewValidationRateLimit = 10
+)
+
+// newRenewValidationLimiter returns the limiter for the user validations of
+// the given connection.
+func newRenewValidationLimiter(config *DatabaseConfig) *rate.Limiter {
+	limit := config.RenewValidationRateLimit
+	if limit <= 0 {
+		limit = defaultRenewValidationRateLimit
+	}
+	return rate.NewLimiter(rate.Limit(limit), limit)
+}
 
 func secretCreds(b *databaseBackend) *framework.Secret {
 	return &framework.Secret{
@@ -49,14 +67,23 @@ func (b *databaseBackend) secretCredsRenew() framework.OperationFunc {
 		}
 
 		// Get the Database object
-		dbi, err := b.GetConnection(ctx, req.Storage, role.DBName)
+		dbi, err := b.GetConnectionRLocked(ctx, req.Storage, role.DBName)
 		if err != nil {
 			return nil, err
 		}
-
-		dbi.RLock()
 		defer dbi.RUnlock()
 
+		var warnings []string
+		if role.ValidateOnRenew {
+			warning, err := b.validateUserOnRenew(ctx, dbi, username)
+			if err != nil {
+				return nil, err
+			}
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+		}
+
 		// Make sure we increase the VALID UNTIL endpoint for this user.
 		ttl, _, err := framework.CalculateTTL(b.System(), req.Secret.Increment, role.DefaultTTL, 0, role.MaxTTL, 0, req.Secret.IssueTime)
 		if err != nil {
@@ -83,13 +110,40 @@ func (b *databaseBackend) secretCredsRenew() framework.OperationFunc {
 				return nil, err
 			}
 		}
-		resp := &logical.Response{Secret: req.Secret}
+		resp := &logical.Response{Secret: req.Secret, Warnings: warnings}
 		resp.Secret.TTL = role.DefaultTTL
 		resp.Secret.MaxTTL = role.MaxTTL
 		return resp, nil
 	}
 }
 
+// validateUserOnRenew checks that the user of a lease being renewed still
+// exists within the database. It returns logical.ErrLeaseInvalidated if it
+// does not, so that the lease gets revoked. The checks are rate limited per
+// connection; renewals above the rate are allowed without validation so that
+// mass renewals do not put extra load on the database.
+func (b *databaseBackend) validateUserOnRenew(ctx context.Context, dbi *dbPluginInstance, username string) (string, error) {
+	if dbi.validationLimiter != nil && !dbi.validationLimiter.Allow() {
+		b.Logger().Debug("renew validation rate limit exceeded, renewing without validating the user", "name", dbi.name, "username", username)
+		return "", nil
+	}
+
+	resp, err := dbi.database.ValidateUser(ctx, v5.ValidateUserRequest{
+		Username: username,
+	})
+	switch {
+	case errors.Is(err, v5.ErrValidateUserUnsupported):
+		return fmt.Sprintf("validate_on_renew is set, but the database plugin of connection %q does not support validating users", dbi.name), nil
+	case err != nil:
+		b.CloseIfShutdown(dbi, err)
+		return "", fmt.Errorf("failed to validate user: %w", err)
+	case !resp.Exists:
+		b.Logger().Warn("user no longer exists within the database, revoking its lease", "name", dbi.name, "username", username)
+		return "", logical.ErrLeaseInvalidated
+	}
+	return "", nil
+}
+
 func (b *databaseBackend) secretCredsRevoke() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 		// Get the username from the internal data
@@ -143,12 +197,10 @@ func (b *databaseBackend) secretCredsRevoke() framework.OperationFunc {
 		}
 
 		// Get our connection
-		dbi, err := b.GetConnection(ctx, req.Storage, dbName)
+		dbi, err := b.GetConnectionRLocked(ctx, req.Storage, dbName)
 		if err != nil {
 			return nil, err
 		}
-
-		dbi.RLock()
 		defer dbi.RUnlock()
 
 		deleteReq := v5.DeleteUserRequest{
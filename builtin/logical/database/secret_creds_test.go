@@ -0,0 +1,83 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v5 "github.com/openbao/openbao/sdk/v2/database/dbplugin/v5"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUserOnRenew(t *testing.T) {
+	b, _, _ := getBackend(t)
+	defer b.Cleanup(context.Background())
+	ctx := context.Background()
+
+	newInstance := func(db v5.Database, limit int) *dbPluginInstance {
+		return &dbPluginInstance{
+			database:          databaseVersionWrapper{v5: db},
+			id:                "foo-id",
+			name:              "mockv5",
+			validationLimiter: newRenewValidationLimiter(&DatabaseConfig{RenewValidationRateLimit: limit}),
+		}
+	}
+
+	t.Run("user exists", func(t *testing.T) {
+		db := &mockValidatingDatabase{}
+		db.On("ValidateUser", mock.Anything, v5.ValidateUserRequest{Username: "bob"}).
+			Return(v5.ValidateUserResponse{Exists: true}, nil)
+
+		warning, err := b.validateUserOnRenew(ctx, newInstance(db, 0), "bob")
+		require.NoError(t, err)
+		require.Empty(t, warning)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("user removed", func(t *testing.T) {
+		db := &mockValidatingDatabase{}
+		db.On("ValidateUser", mock.Anything, v5.ValidateUserRequest{Username: "bob"}).
+			Return(v5.ValidateUserResponse{Exists: false}, nil)
+
+		_, err := b.validateUserOnRenew(ctx, newInstance(db, 0), "bob")
+		require.ErrorIs(t, err, logical.ErrLeaseInvalidated)
+	})
+
+	t.Run("plugin error", func(t *testing.T) {
+		db := &mockValidatingDatabase{}
+		db.On("ValidateUser", mock.Anything, mock.Anything).
+			Return(v5.ValidateUserResponse{}, errors.New("connection refused"))
+
+		_, err := b.validateUserOnRenew(ctx, newInstance(db, 0), "bob")
+		require.ErrorContains(t, err, "connection refused")
+		require.NotErrorIs(t, err, logical.ErrLeaseInvalidated)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		warning, err := b.validateUserOnRenew(ctx, newInstance(&mockNewDatabase{}, 0), "bob")
+		require.NoError(t, err)
+		require.Contains(t, warning, "does not support validating users")
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		db := &mockValidatingDatabase{}
+		db.On("ValidateUser", mock.Anything, mock.Anything).
+			Return(v5.ValidateUserResponse{Exists: false}, nil)
+
+		dbi := newInstance(db, 1)
+		dbi.validationLimiter.SetLimit(0)
+		_, err := b.validateUserOnRenew(ctx, dbi, "bob")
+		require.ErrorIs(t, err, logical.ErrLeaseInvalidated)
+
+		// The burst is consumed, renewals are allowed without validation
+		warning, err := b.validateUserOnRenew(ctx, dbi, "bob")
+		require.NoError(t, err)
+		require.Empty(t, warning)
+		db.AssertNumberOfCalls(t, "ValidateUser", 1)
+	})
+}
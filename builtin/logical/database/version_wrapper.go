@@ -212,6 +212,23 @@ func (d databaseVersionWrapper) DeleteUser(ctx context.Context, req v5.DeleteUse
 	return v5.DeleteUserResponse{}, err
 }
 
+// ValidateUser checks whether the user exists in the underlying database.
+// Returns v5.ErrValidateUserUnsupported for v4 databases and v5 databases
+// which do not support it.
+func (d databaseVersionWrapper) ValidateUser(ctx context.Context, req v5.ValidateUserRequest) (v5.ValidateUserResponse, error) {
+	if !d.isV5() && !d.isV4() {
+		return v5.ValidateUserResponse{}, fmt.Errorf("no underlying database specified")
+	}
+
+	// v5 Database
+	if d.isV5() {
+		return v5.ValidateUser(ctx, d.v5, req)
+	}
+
+	// v4 Database
+	return v5.ValidateUserResponse{}, v5.ErrValidateUserUnsupported
+}
+
 // Type of the underlying database. Errors if the wrapper does not contain an underlying database.
 func (d databaseVersionWrapper) Type() (string, error) {
 	if !d.isV5() && !d.isV4() {
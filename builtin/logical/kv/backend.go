@@ -61,6 +61,10 @@ type versionedKVBackend struct {
 	// upgrading its data.
 	upgrading *uint32
 
+	// sweeping is an atomic value denoting if the retention sweeper is
+	// running.
+	sweeping *uint32
+
 	// globalConfig is a cached value for fast lookup
 	globalConfig     *Configuration
 	globalConfigLock *sync.RWMutex
@@ -99,6 +103,7 @@ func VersionedKVFactory(ctx context.Context, conf *logical.BackendConfig) (logic
 
 	b := &versionedKVBackend{
 		upgrading:         new(uint32),
+		sweeping:          new(uint32),
 		globalConfigLock:  new(sync.RWMutex),
 		upgradeCancelFunc: upgradeCancelFunc,
 	}
@@ -111,8 +116,9 @@ func VersionedKVFactory(ctx context.Context, conf *logical.BackendConfig) (logic
 		BackendType:    logical.TypeLogical,
 		RunningVersion: ReportedVersion,
 
-		Help:       backendHelp,
-		Invalidate: b.Invalidate,
+		Help:         backendHelp,
+		Invalidate:   b.Invalidate,
+		PeriodicFunc: b.periodicFunc,
 
 		PathsSpecial: &logical.Paths{
 			SealWrapStorage: []string{
@@ -327,9 +333,10 @@ func (b *versionedKVBackend) config(ctx context.Context, s logical.Storage) (*Co
 	if b.globalConfig != nil {
 		defer b.globalConfigLock.RUnlock()
 		return &Configuration{
-			CasRequired:        b.globalConfig.CasRequired,
-			MaxVersions:        b.globalConfig.MaxVersions,
-			DeleteVersionAfter: b.globalConfig.DeleteVersionAfter,
+			CasRequired:             b.globalConfig.CasRequired,
+			MaxVersions:             b.globalConfig.MaxVersions,
+			DeleteVersionAfter:      b.globalConfig.DeleteVersionAfter,
+			DeletedVersionRetention: b.globalConfig.DeletedVersionRetention,
 		}, nil
 	}
 
@@ -340,9 +347,10 @@ func (b *versionedKVBackend) config(ctx context.Context, s logical.Storage) (*Co
 	// Verify this hasn't already changed
 	if b.globalConfig != nil {
 		return &Configuration{
-			CasRequired:        b.globalConfig.CasRequired,
-			MaxVersions:        b.globalConfig.MaxVersions,
-			DeleteVersionAfter: b.globalConfig.DeleteVersionAfter,
+			CasRequired:             b.globalConfig.CasRequired,
+			MaxVersions:             b.globalConfig.MaxVersions,
+			DeleteVersionAfter:      b.globalConfig.DeleteVersionAfter,
+			DeletedVersionRetention: b.globalConfig.DeletedVersionRetention,
 		}, nil
 	}
 
@@ -32,6 +32,14 @@ If set, the length of time before a version is deleted. A negative duration
 disables the use of delete_version_after on all keys. A zero duration
 clears the current setting. Accepts a Go duration format string.`,
 			},
+			"deleted_version_retention": {
+				Type: framework.TypeDurationSecond,
+				Description: `
+If set, the length of time a version is kept after it is deleted, after
+which it is permanently removed. Destroyed versions are also removed. A
+zero duration clears the current setting. Accepts a Go duration format
+string.`,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.UpdateOperation: &framework.PathOperation{
@@ -73,6 +81,7 @@ func (b *versionedKVBackend) pathConfigRead() framework.OperationFunc {
 			}
 		}
 		rdata["delete_version_after"] = deleteVersionAfter.String()
+		rdata["deleted_version_retention"] = deletedVersionRetention(config).String()
 
 		return &logical.Response{
 			Data: rdata,
@@ -86,9 +95,10 @@ func (b *versionedKVBackend) pathConfigWrite() framework.OperationFunc {
 		maxRaw, mOk := data.GetOk("max_versions")
 		casRaw, cOk := data.GetOk("cas_required")
 		dvaRaw, dvaOk := data.GetOk("delete_version_after")
+		dvrRaw, dvrOk := data.GetOk("deleted_version_retention")
 
 		// Fast path validation
-		if !mOk && !cOk && !dvaOk {
+		if !mOk && !cOk && !dvaOk && !dvrOk {
 			return nil, nil
 		}
 
@@ -116,6 +126,14 @@ func (b *versionedKVBackend) pathConfigWrite() framework.OperationFunc {
 			}
 		}
 
+		if dvrOk {
+			if dvr := dvrRaw.(int); dvr == 0 {
+				config.DeletedVersionRetention = nil
+			} else {
+				config.DeletedVersionRetention = ptypes.DurationProto(time.Duration(dvr) * time.Second)
+			}
+		}
+
 		bytes, err := proto.Marshal(config)
 		if err != nil {
 			return nil, err
@@ -154,5 +172,10 @@ key-value store. This parameter accetps:
 	  version is deleted. A negative duration disables the use of
 	  delete_version_after on all keys. A zero duration clears the current
 	  setting. Accepts a Go duration format string.
+
+	* deleted_version_retention (duration) - If set, the length of time a
+	  version is kept after it is deleted, after which it is permanently
+	  removed along with any destroyed versions. A zero duration clears the
+	  current setting. Accepts a Go duration format string.
 `
 )
@@ -371,7 +371,12 @@ func dataPatchPreprocessor() framework.PatchPreprocessorFunc {
 			return nil, errors.New("no data provided")
 		}
 
-		return data.(map[string]interface{}), nil
+		patch, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("data must be a JSON object")
+		}
+
+		return patch, nil
 	}
 }
 
@@ -381,8 +386,9 @@ func dataPatchPreprocessor() framework.PatchPreprocessorFunc {
 // on either the secret or the engine's config. In order for a patch to be
 // successful, cas must be set to the current version of the secret. The contents
 // of the data map under the "data" key will be applied as a partial update to
-// the existing entry via a JSON merge patch to the existing entry using the
-// framework.HandlePatchOperation abstraction.
+// the existing entry via a JSON merge patch (RFC 7396) to the existing entry
+// using the framework.HandlePatchOperation abstraction, so that keys set to
+// null are removed.
 func (b *versionedKVBackend) pathDataPatch() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 		key := data.Get("path").(string)
@@ -446,11 +452,13 @@ func (b *versionedKVBackend) pathDataPatch() framework.OperationFunc {
 			}
 
 			if deletionTime.Before(time.Now()) {
+				notFoundResp.AddWarning(fmt.Sprintf("cannot patch secret: its current version %d is deleted; undelete it or write a new version", currentVersion))
 				return logical.RespondWithStatusCode(notFoundResp, req, http.StatusNotFound)
 			}
 		}
 
 		if versionMetadata.Destroyed {
+			notFoundResp.AddWarning(fmt.Sprintf("cannot patch secret: its current version %d is destroyed; write a new version", currentVersion))
 			return logical.RespondWithStatusCode(notFoundResp, req, http.StatusNotFound)
 		}
 
@@ -480,7 +488,7 @@ func (b *versionedKVBackend) pathDataPatch() framework.OperationFunc {
 
 		patchedBytes, err := framework.HandlePatchOperation(data, versionData, dataPatchPreprocessor())
 		if err != nil {
-			return nil, err
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
 		}
 
 		newVersion := &Version{
@@ -665,8 +673,9 @@ data.
 A patch operation must be performed on an existing secret. The secret must neither
 be deleted nor destroyed. Like a write operation, patch operations accept an
 options object and data object. The options object is used to pass some options to
-the patch command and the data object is used to perform a partial update on the
-current version of the secret and store the encrypted result in the storage backend. 
+the patch command and the data object is applied to the current version of the
+secret as a JSON merge patch (RFC 7396), in which keys set to null are removed,
+and the encrypted result is stored as a new version in the storage backend.
 
 A read operation will return the latest version for a key unless the "version"
 parameter is set, then it returns the version at that number.
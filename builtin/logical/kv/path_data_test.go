@@ -1043,6 +1043,11 @@ func TestVersionedKV_Patch_CurrentVersionDeleted(t *testing.T) {
 		respData["deletion_time"] == "" {
 		t.Fatalf("Expected 404 status code for deleted version: resp:%#v\n", resp)
 	}
+
+	warnings, ok := respBody["warnings"].([]interface{})
+	if !ok || len(warnings) != 1 || !strings.Contains(warnings[0].(string), "is deleted") {
+		t.Fatalf("Expected a warning explaining the version is deleted: resp:%#v\n", resp)
+	}
 }
 
 func TestVersionedKV_Patch_CurrentVersionDestroyed(t *testing.T) {
@@ -1158,4 +1163,104 @@ func TestVersionedKV_Patch_CurrentVersionDestroyed(t *testing.T) {
 		(respData["destroyed"] == nil || !respData["destroyed"].(bool)) {
 		t.Fatalf("Expected 404 status code for destroyed version: resp:%#v\n", resp)
 	}
+
+	warnings, ok := respBody["warnings"].([]interface{})
+	if !ok || len(warnings) != 1 || !strings.Contains(warnings[0].(string), "is destroyed") {
+		t.Fatalf("Expected a warning explaining the version is destroyed: resp:%#v\n", resp)
+	}
+}
+
+func TestVersionedKV_Patch_MergePatchNulls(t *testing.T) {
+	b, storage := getBackend(t)
+
+	req := &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "data/foo",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"data": map[string]interface{}{
+				"bar":  "baz",
+				"keep": "me",
+				"quux": map[string]interface{}{
+					"a": "1",
+					"b": "2",
+				},
+			},
+		},
+	}
+
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("CreateOperation request failed - err:%s resp:%#v\n", err, resp)
+	}
+
+	req = &logical.Request{
+		Operation: logical.PatchOperation,
+		Path:      "data/foo",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"data": map[string]interface{}{
+				"bar": nil,
+				"new": "value",
+				"quux": map[string]interface{}{
+					"a": nil,
+				},
+			},
+			"options": map[string]interface{}{
+				"cas": 1,
+			},
+		},
+	}
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("PatchOperation request failed - err:%s resp:%#v\n", err, resp)
+	}
+
+	if resp.Data["version"] != uint64(2) {
+		t.Fatalf("expected version to be 2, resp: %#v", resp)
+	}
+
+	// A second patch against the same version fails the check-and-set
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected stale check-and-set to fail, resp: %#v", resp)
+	}
+
+	req = &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "data/foo",
+		Storage:   storage,
+	}
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("ReadOperation request failed - err:%s resp:%#v\n", err, resp)
+	}
+
+	expectedData := map[string]interface{}{
+		"keep": "me",
+		"new":  "value",
+		"quux": map[string]interface{}{
+			"b": "2",
+		},
+	}
+
+	if diff := deep.Equal(resp.Data["data"], expectedData); len(diff) > 0 {
+		t.Fatalf("secret data mismatch, diff: %#v\n", diff)
+	}
+
+	// The previous version is left intact
+	req.Data = map[string]interface{}{
+		"version": 1,
+	}
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("ReadOperation request failed - err:%s resp:%#v\n", err, resp)
+	}
+
+	if resp.Data["data"].(map[string]interface{})["bar"] != "baz" {
+		t.Fatalf("expected version 1 to be unchanged, resp: %#v", resp)
+	}
 }
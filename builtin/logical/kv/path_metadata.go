@@ -47,6 +47,15 @@ The length of time before a version is deleted. If not set, the backend's
 configured delete_version_after is used. Cannot be greater than the
 backend's delete_version_after. A zero duration clears the current setting.
 A negative duration will cause an error.
+`,
+			},
+			"deleted_version_retention": {
+				Type: framework.TypeDurationSecond,
+				Description: `
+The length of time a version is kept after it is deleted, after which it is
+permanently removed. Destroyed versions are also removed. If both this and
+the backend's deleted_version_retention are set, the shorter applies. A zero
+duration clears the current setting.
 `,
 			},
 			"custom_metadata": {
@@ -156,15 +165,16 @@ func (b *versionedKVBackend) pathMetadataRead() framework.OperationFunc {
 
 		return &logical.Response{
 			Data: map[string]interface{}{
-				"versions":             versions,
-				"current_version":      meta.CurrentVersion,
-				"oldest_version":       meta.OldestVersion,
-				"created_time":         ptypesTimestampToString(meta.CreatedTime),
-				"updated_time":         ptypesTimestampToString(meta.UpdatedTime),
-				"max_versions":         meta.MaxVersions,
-				"cas_required":         meta.CasRequired,
-				"delete_version_after": deleteVersionAfter.String(),
-				"custom_metadata":      meta.CustomMetadata,
+				"versions":                  versions,
+				"current_version":           meta.CurrentVersion,
+				"oldest_version":            meta.OldestVersion,
+				"created_time":              ptypesTimestampToString(meta.CreatedTime),
+				"updated_time":              ptypesTimestampToString(meta.UpdatedTime),
+				"max_versions":              meta.MaxVersions,
+				"cas_required":              meta.CasRequired,
+				"delete_version_after":      deleteVersionAfter.String(),
+				"custom_metadata":           meta.CustomMetadata,
+				"deleted_version_retention": deletedVersionRetention(meta).String(),
 			},
 		}, nil
 	}
@@ -269,10 +279,11 @@ func (b *versionedKVBackend) pathMetadataWrite() framework.OperationFunc {
 		maxRaw, mOk := data.GetOk("max_versions")
 		casRaw, cOk := data.GetOk("cas_required")
 		deleteVersionAfterRaw, dvaOk := data.GetOk("delete_version_after")
+		deletedVersionRetentionRaw, dvrOk := data.GetOk("deleted_version_retention")
 		customMetadataRaw, cmOk := data.GetOk("custom_metadata")
 
 		// Fast path validation
-		if !mOk && !cOk && !dvaOk && !cmOk {
+		if !mOk && !cOk && !dvaOk && !dvrOk && !cmOk {
 			return nil, nil
 		}
 
@@ -329,6 +340,9 @@ func (b *versionedKVBackend) pathMetadataWrite() framework.OperationFunc {
 		if dvaOk {
 			meta.DeleteVersionAfter = ptypes.DurationProto(time.Duration(deleteVersionAfterRaw.(int)) * time.Second)
 		}
+		if dvrOk {
+			meta.DeletedVersionRetention = ptypes.DurationProto(time.Duration(deletedVersionRetentionRaw.(int)) * time.Second)
+		}
 		if cmOk {
 			meta.CustomMetadata = customMetadataMap
 		}
@@ -344,12 +358,12 @@ func (b *versionedKVBackend) pathMetadataWrite() framework.OperationFunc {
 // and ensuring appropriate handling of data types not supported directly by FieldType.
 func metadataPatchPreprocessor() framework.PatchPreprocessorFunc {
 	return func(input map[string]interface{}) (map[string]interface{}, error) {
-		patchableKeys := []string{"max_versions", "cas_required", "delete_version_after", "custom_metadata"}
+		patchableKeys := []string{"max_versions", "cas_required", "delete_version_after", "deleted_version_retention", "custom_metadata"}
 		patchData := map[string]interface{}{}
 
 		for _, k := range patchableKeys {
 			if v, ok := input[k]; ok {
-				if k == "delete_version_after" {
+				if k == "delete_version_after" || k == "deleted_version_retention" {
 					patchData[k] = ptypes.DurationProto(time.Duration(v.(int)) * time.Second)
 				} else {
 					patchData[k] = v
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"reflect"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
@@ -31,6 +32,10 @@ func pathSubkeys(b *versionedKVBackend) *framework.Path {
 				Type:        framework.TypeInt,
 				Description: "Specifies which version to retrieve. If not provided, the current version will be used.",
 			},
+			"field_metadata": {
+				Type:        framework.TypeBool,
+				Description: "If true, the type and length of every leaf value are returned as well.",
+			},
 		},
 		Callbacks: map[logical.Operation]framework.OperationFunc{
 			logical.ReadOperation: b.upgradeCheck(b.pathSubkeysRead()),
@@ -84,6 +89,52 @@ func removeValues(input map[string]interface{}, maxDepth int) {
 	walk(input, 1)
 }
 
+// fieldMetadata walks the provided secret data in the same way as
+// removeValues and returns a structure with the same nesting, in which every
+// leaf is replaced by a description of its value: its JSON type and, for
+// strings, arrays and objects, its length. The input is left untouched and no
+// value is ever copied to the result.
+func fieldMetadata(input map[string]interface{}, maxDepth int) map[string]interface{} {
+	var walk func(map[string]interface{}, int) map[string]interface{}
+
+	walk = func(in map[string]interface{}, depth int) map[string]interface{} {
+		out := make(map[string]interface{}, len(in))
+		for k, v := range in {
+			if m, ok := v.(map[string]interface{}); ok {
+				if currentDepth := depth + 1; (maxDepth == 0 || currentDepth <= maxDepth) && len(m) > 0 {
+					out[k] = walk(m, currentDepth)
+					continue
+				}
+			}
+			out[k] = describeValue(v)
+		}
+		return out
+	}
+
+	return walk(input, 1)
+}
+
+// describeValue returns the type and, where applicable, the length of a
+// JSON decoded value.
+func describeValue(v interface{}) map[string]interface{} {
+	switch t := v.(type) {
+	case nil:
+		return map[string]interface{}{"type": "null"}
+	case bool:
+		return map[string]interface{}{"type": "bool"}
+	case float64, json.Number:
+		return map[string]interface{}{"type": "number"}
+	case string:
+		return map[string]interface{}{"type": "string", "length": utf8.RuneCountInString(t)}
+	case []interface{}:
+		return map[string]interface{}{"type": "array", "length": len(t)}
+	case map[string]interface{}:
+		return map[string]interface{}{"type": "object", "length": len(t)}
+	default:
+		return map[string]interface{}{"type": "unknown"}
+	}
+}
+
 // pathSubkeysRead handles ReadOperation requests for a specified path. Subkeys
 // that exist within the entry specified by the provided path will be retrieved.
 // This is done by stripping the secret data by replacing all underlying values of
@@ -92,6 +143,7 @@ func (b *versionedKVBackend) pathSubkeysRead() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 		key := data.Get("path").(string)
 		depth := data.Get("depth").(int)
+		withFieldMetadata := data.Get("field_metadata").(bool)
 
 		lock := locksutil.LockForKey(b.locks, key)
 		lock.RLock()
@@ -158,14 +210,22 @@ func (b *versionedKVBackend) pathSubkeysRead() framework.OperationFunc {
 			return nil, errors.New("could not find version data")
 		}
 
+		// Decoding errors may quote the secret data, so they are not
+		// returned to the client
 		version := &Version{}
 		if err := proto.Unmarshal(raw.Value, version); err != nil {
-			return nil, err
+			b.Logger().Error("failed to decode version", "path", key, "version", versionNum)
+			return nil, errors.New("could not decode version data")
 		}
 
 		versionData := map[string]interface{}{}
 		if err := json.Unmarshal(version.Data, &versionData); err != nil {
-			return nil, err
+			b.Logger().Error("failed to decode version data", "path", key, "version", versionNum)
+			return nil, errors.New("could not decode version data")
+		}
+
+		if withFieldMetadata {
+			resp.Data["field_metadata"] = fieldMetadata(versionData, depth)
 		}
 
 		removeValues(versionData, depth)
@@ -189,5 +249,10 @@ The "depth" parameter specifies the deepest nesting level to provide in the outp
 The default value 0 will not impose any limit. If non-zero, keys that reside at the
 specified depth value will be artificially treated as leaves and will thus be null
 even if further underlying subkeys exist.
+
+If the "field_metadata" parameter is true, the response also contains a
+"field_metadata" structure with the same keys as "subkeys", in which every leaf
+describes the type of the value it replaces and, for strings, arrays and
+objects, its length. Values themselves are never returned.
 `
 )
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
+	"github.com/golang/protobuf/proto"
 	"github.com/openbao/openbao/sdk/v2/logical"
 )
 
@@ -560,3 +562,174 @@ func TestVersionedKV_Subkeys_VersionDestroyed(t *testing.T) {
 		t.Fatalf("expected version to be destroyed, resp: %#v\n", resp)
 	}
 }
+
+// TestVersionedKV_Subkeys_FieldMetadata verifies that the type and length of
+// each leaf value are returned when field_metadata is set, honoring the depth
+// param, and that no value is ever included in the response
+func TestVersionedKV_Subkeys_FieldMetadata(t *testing.T) {
+	b, storage := getBackend(t)
+
+	req := &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "data/foo",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"data": map[string]interface{}{
+				"password": "hunter2",
+				"unicode":  "héllo",
+				"port":     5432,
+				"enabled":  true,
+				"nothing":  nil,
+				"hosts":    []string{"a", "b", "c"},
+				"empty":    map[string]interface{}{},
+				"nested": map[string]interface{}{
+					"token": map[string]interface{}{
+						"value": "s3cr3t",
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("data CreateOperation request failed, err: %v, resp %#v", err, resp)
+	}
+
+	expected := map[string]interface{}{
+		"password": map[string]interface{}{"type": "string", "length": 7},
+		"unicode":  map[string]interface{}{"type": "string", "length": 5},
+		"port":     map[string]interface{}{"type": "number"},
+		"enabled":  map[string]interface{}{"type": "bool"},
+		"nothing":  map[string]interface{}{"type": "null"},
+		"hosts":    map[string]interface{}{"type": "array", "length": 3},
+		"empty":    map[string]interface{}{"type": "object", "length": 0},
+		"nested": map[string]interface{}{
+			"token": map[string]interface{}{
+				"value": map[string]interface{}{"type": "string", "length": 6},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		depth    int
+		expected map[string]interface{}
+	}{
+		"no_limit": {
+			depth:    0,
+			expected: expected,
+		},
+		"depth": {
+			depth: 2,
+			expected: func() map[string]interface{} {
+				m := make(map[string]interface{}, len(expected))
+				for k, v := range expected {
+					m[k] = v
+				}
+				m["nested"] = map[string]interface{}{
+					"token": map[string]interface{}{"type": "object", "length": 1},
+				}
+				return m
+			}(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "subkeys/foo",
+				Storage:   storage,
+				Data: map[string]interface{}{
+					"depth":          tc.depth,
+					"field_metadata": true,
+				},
+			}
+
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err != nil || resp == nil || resp.IsError() {
+				t.Fatalf("subkeys ReadOperation request failed, err: %v, resp %#v", err, resp)
+			}
+
+			if diff := deep.Equal(resp.Data["field_metadata"], tc.expected); len(diff) > 0 {
+				t.Fatalf("resp and expected field metadata mismatch, diff: %#v", diff)
+			}
+
+			encoded, err := json.Marshal(resp.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range []string{"hunter2", "héllo", "5432", "s3cr3t"} {
+				if strings.Contains(string(encoded), secret) {
+					t.Fatalf("response leaks value %q: %s", secret, encoded)
+				}
+			}
+		})
+	}
+
+	// Field metadata is only returned when requested
+	req = &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "subkeys/foo",
+		Storage:   storage,
+	}
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("subkeys ReadOperation request failed, err: %v, resp %#v", err, resp)
+	}
+	if _, ok := resp.Data["field_metadata"]; ok {
+		t.Fatalf("unexpected field metadata in resp %#v", resp)
+	}
+}
+
+// TestVersionedKV_Subkeys_CorruptData verifies that decoding errors do not
+// leak the stored secret data
+func TestVersionedKV_Subkeys_CorruptData(t *testing.T) {
+	b, storage := getBackend(t)
+
+	req := &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "data/foo",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"data": map[string]interface{}{
+				"foo": "bar",
+			},
+		},
+	}
+
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("data CreateOperation request failed, err: %v, resp %#v", err, resp)
+	}
+
+	versionKey, err := b.(*versionedKVBackend).getVersionKey(context.Background(), "foo", 1, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := proto.Marshal(&Version{Data: []byte(`{"foo": s3cr3t}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Put(context.Background(), &logical.StorageEntry{Key: versionKey, Value: raw}); err != nil {
+		t.Fatal(err)
+	}
+
+	req = &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "subkeys/foo",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"field_metadata": true,
+		},
+	}
+
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err == nil {
+		t.Fatalf("expected error, resp %#v", resp)
+	}
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Fatalf("error leaks value: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/hashicorp/go-multierror"
+	"github.com/openbao/openbao/sdk/v2/helper/consts"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	// retentionSweepPath is the location where the progress of the retention
+	// sweeper is stored.
+	retentionSweepPath string = "retention-sweep"
+
+	// retentionSweepBatchSize is the number of keys checked by each run of
+	// the retention sweeper.
+	retentionSweepBatchSize = 256
+)
+
+type deletedVersionRetentionGetter interface {
+	GetDeletedVersionRetention() *duration.Duration
+}
+
+func deletedVersionRetention(v deletedVersionRetentionGetter) time.Duration {
+	if v.GetDeletedVersionRetention() == nil {
+		return time.Duration(0)
+	}
+	dvr, err := ptypes.Duration(v.GetDeletedVersionRetention())
+	if err != nil {
+		return time.Duration(0)
+	}
+	return dvr
+}
+
+// retentionPeriod returns the minimum non-zero deleted version retention of
+// the mount and the key, or zero if neither is set.
+func retentionPeriod(config *Configuration, meta *KeyMetadata) time.Duration {
+	mount, key := deletedVersionRetention(config), deletedVersionRetention(meta)
+	switch {
+	case mount == 0:
+		return key
+	case key == 0 || mount < key:
+		return mount
+	}
+	return key
+}
+
+// ExpiredVersions returns, in ascending order, the versions of the key which
+// are due to be removed at the given time: those which have fallen out of
+// the max versions window and, if a retention period is given, those which
+// were deleted longer ago than it or have been destroyed.
+func (k *KeyMetadata) ExpiredVersions(configMaxVersions uint32, retention time.Duration, now time.Time) []uint64 {
+	var maxVersions uint32
+	switch {
+	case max(k.MaxVersions, configMaxVersions) > 0:
+		maxVersions = max(k.MaxVersions, configMaxVersions)
+	default:
+		maxVersions = defaultMaxVersions
+	}
+
+	var expired []uint64
+	for version, vm := range k.Versions {
+		switch {
+		case k.CurrentVersion-version >= uint64(maxVersions):
+			expired = append(expired, version)
+		case retention <= 0:
+		case vm.Destroyed:
+			expired = append(expired, version)
+		case vm.DeletionTime != nil:
+			deletionTime, err := ptypes.Timestamp(vm.DeletionTime)
+			if err == nil && deletionTime.Add(retention).Before(now) {
+				expired = append(expired, version)
+			}
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+	return expired
+}
+
+// periodicFunc runs the retention sweeper. Runs which start while a previous
+// one is still in progress are skipped.
+func (b *versionedKVBackend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	if atomic.LoadUint32(b.upgrading) == 1 {
+		return nil
+	}
+
+	// Only sweep where the storage is writable and owned by this mount
+	if b.System().ReplicationState().HasState(consts.ReplicationDRSecondary|consts.ReplicationPerformanceStandby) ||
+		(!b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary)) {
+		return nil
+	}
+
+	if !atomic.CompareAndSwapUint32(b.sweeping, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreUint32(b.sweeping, 0)
+
+	return b.sweepRetention(ctx, req.Storage, time.Now())
+}
+
+// sweepRetention permanently removes the expired versions of the next batch
+// of keys, resuming after the last key checked by the previous call. Each key
+// is locked only while its versions are being removed, and progress is
+// stored after every batch, so that a pass over a large mount can be spread
+// over many calls and survives restarts.
+func (b *versionedKVBackend) sweepRetention(ctx context.Context, s logical.Storage, now time.Time) error {
+	info := &RetentionSweepInfo{}
+	raw, err := s.Get(ctx, path.Join(b.storagePrefix, retentionSweepPath))
+	if err != nil {
+		return err
+	}
+	if raw != nil {
+		if err := proto.Unmarshal(raw.Value, info); err != nil {
+			return fmt.Errorf("failed to decode retention sweep info: %w", err)
+		}
+	}
+
+	config, err := b.config(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	wrapper, err := b.getKeyEncryptor(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	keys, err := collectSweepKeys(ctx, wrapper.Wrap(s), "", info.LastKey, retentionSweepBatchSize, nil)
+	if err != nil {
+		return err
+	}
+
+	var errs *multierror.Error
+	for _, key := range keys {
+		if err := b.sweepKey(ctx, s, config, key, now); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to remove expired versions of %q: %w", key, err))
+		}
+	}
+
+	// A short batch means the pass is complete, so the next one restarts
+	info.LastKey = ""
+	if len(keys) == retentionSweepBatchSize {
+		info.LastKey = keys[len(keys)-1]
+	}
+
+	buf, err := proto.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, &logical.StorageEntry{
+		Key:   path.Join(b.storagePrefix, retentionSweepPath),
+		Value: buf,
+	}); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// sweepKey removes the expired versions of the key.
+func (b *versionedKVBackend) sweepKey(ctx context.Context, s logical.Storage, config *Configuration, key string, now time.Time) error {
+	lock := locksutil.LockForKey(b.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	meta, err := b.getKeyMetadata(ctx, s, key)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return nil
+	}
+
+	expired := meta.ExpiredVersions(config.MaxVersions, retentionPeriod(config, meta), now)
+	if len(expired) == 0 {
+		return nil
+	}
+
+	for _, version := range expired {
+		delete(meta.Versions, version)
+	}
+	for meta.OldestVersion < meta.CurrentVersion && meta.Versions[meta.OldestVersion] == nil {
+		meta.OldestVersion++
+	}
+
+	// As when writing, the metadata is updated before the version data is
+	// removed, so that a failure can only leave unreferenced data behind
+	if err := b.writeKeyMetadata(ctx, s, meta); err != nil {
+		return err
+	}
+
+	for _, version := range expired {
+		versionKey, err := b.getVersionKey(ctx, key, version, s)
+		if err != nil {
+			return err
+		}
+		if err := s.Delete(ctx, versionKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectSweepKeys appends to keys, in lexical order, the keys under the
+// prefix which sort after the given key, until limit keys have been
+// collected.
+func collectSweepKeys(ctx context.Context, s logical.Storage, prefix, after string, limit int, keys []string) ([]string, error) {
+	names, err := s.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if len(keys) >= limit {
+			break
+		}
+
+		key := prefix + name
+		if strings.HasSuffix(name, "/") {
+			// Skip folders which hold nothing after the given key
+			if key < after && !strings.HasPrefix(after, key) {
+				continue
+			}
+			keys, err = collectSweepKeys(ctx, s, key, after, limit, keys)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if key > after {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,194 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+func TestRetentionPeriodCalc(t *testing.T) {
+	dm, ds := 6*time.Hour, 3*time.Hour
+	tests := []struct {
+		mount, meta time.Duration
+		want        time.Duration
+	}{
+		{0, 0, 0},
+		{0, ds, ds},
+		{ds, 0, ds},
+		{dm, ds, ds},
+		{ds, dm, ds},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("mount=%v,meta=%v", tt.mount, tt.meta), func(t *testing.T) {
+			t.Parallel()
+			config, meta := &Configuration{}, &KeyMetadata{}
+			if tt.mount != 0 {
+				config.DeletedVersionRetention = ptypes.DurationProto(tt.mount)
+			}
+			if tt.meta != 0 {
+				meta.DeletedVersionRetention = ptypes.DurationProto(tt.meta)
+			}
+			if got := retentionPeriod(config, meta); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyMetadata_ExpiredVersions(t *testing.T) {
+	now := time.Date(2019, time.March, 25, 1, 0, 0, 0, time.UTC)
+	ts := func(d time.Duration) *VersionMetadata {
+		dt, _ := ptypes.TimestampProto(now.Add(d))
+		return &VersionMetadata{DeletionTime: dt}
+	}
+
+	meta := &KeyMetadata{
+		CurrentVersion: 5,
+		Versions: map[uint64]*VersionMetadata{
+			1: {},
+			2: ts(-3 * time.Hour),
+			3: {Destroyed: true},
+			4: ts(-30 * time.Minute),
+			5: ts(time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		maxVersions uint32
+		retention   time.Duration
+		want        []uint64
+	}{
+		{"none", 0, 0, nil},
+		{"max versions", 3, 0, []uint64{1, 2}},
+		{"retention", 0, time.Hour, []uint64{2, 3}},
+		{"both", 4, 15 * time.Minute, []uint64{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := meta.ExpiredVersions(tt.maxVersions, tt.retention, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionedKV_SweepRetention(t *testing.T) {
+	ctx := context.Background()
+	b, storage := getBackend(t)
+	kvb := b.(*versionedKVBackend)
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   storage,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("%s request to %s failed - err:%s resp:%#v\n", op, path, err, resp)
+		}
+		return resp
+	}
+
+	for i := 0; i < 3; i++ {
+		request(logical.CreateOperation, "data/foo", map[string]interface{}{
+			"data": map[string]interface{}{"bar": i},
+		})
+	}
+	request(logical.CreateOperation, "data/other", map[string]interface{}{
+		"data": map[string]interface{}{"bar": "baz"},
+	})
+	request(logical.CreateOperation, "delete/foo", map[string]interface{}{"versions": []int{1}})
+	request(logical.CreateOperation, "destroy/foo", map[string]interface{}{"versions": []int{2}})
+	request(logical.CreateOperation, "config", map[string]interface{}{"deleted_version_retention": "1h"})
+
+	if got := request(logical.ReadOperation, "config", nil).Data["deleted_version_retention"]; got != time.Hour.String() {
+		t.Fatalf("expected deleted_version_retention to be %s, got %v", time.Hour, got)
+	}
+
+	versionKey, err := kvb.getVersionKey(ctx, "foo", 1, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing has been deleted for long enough yet
+	if err := kvb.sweepRetention(ctx, storage, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	versions := request(logical.ReadOperation, "metadata/foo", nil).Data["versions"].(map[string]interface{})
+	if len(versions) != 2 {
+		t.Fatalf("expected only the destroyed version to be removed, got %#v", versions)
+	}
+	if raw, err := storage.Get(ctx, versionKey); err != nil || raw == nil {
+		t.Fatalf("expected version 1 to be kept, err: %v", err)
+	}
+
+	if err := kvb.sweepRetention(ctx, storage, time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	resp := request(logical.ReadOperation, "metadata/foo", nil)
+	versions = resp.Data["versions"].(map[string]interface{})
+	if _, ok := versions["3"]; !ok || len(versions) != 1 {
+		t.Fatalf("expected only version 3 to be kept, got %#v", versions)
+	}
+	if resp.Data["oldest_version"] != uint64(3) || resp.Data["current_version"] != uint64(3) {
+		t.Fatalf("unexpected version bounds, resp: %#v", resp)
+	}
+	if raw, err := storage.Get(ctx, versionKey); err != nil || raw != nil {
+		t.Fatalf("expected version 1 to be removed from storage, err: %v", err)
+	}
+
+	// Keys without deleted versions are untouched
+	versions = request(logical.ReadOperation, "metadata/other", nil).Data["versions"].(map[string]interface{})
+	if len(versions) != 1 {
+		t.Fatalf("expected other to be untouched, got %#v", versions)
+	}
+
+	// New writes continue the version sequence
+	resp = request(logical.CreateOperation, "data/foo", map[string]interface{}{
+		"data":    map[string]interface{}{"bar": "qux"},
+		"options": map[string]interface{}{"cas": 3},
+	})
+	if resp.Data["version"] != uint64(4) {
+		t.Fatalf("expected version to be 4, resp: %#v", resp)
+	}
+}
+
+func TestCollectSweepKeys(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	for _, key := range []string{"a", "a/b", "a/c/d", "a-b", "b", "c/d"} {
+		if err := storage.Put(ctx, &logical.StorageEntry{Key: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var all []string
+	after := ""
+	for {
+		keys, err := collectSweepKeys(ctx, storage, "", after, 2, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, keys...)
+		if len(keys) < 2 {
+			break
+		}
+		after = keys[len(keys)-1]
+	}
+
+	want := []string{"a", "a-b", "a/b", "a/c/d", "b", "c/d"}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("got %v, want %v", all, want)
+	}
+}
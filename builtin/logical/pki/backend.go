@@ -90,6 +90,9 @@ func Backend(conf *logical.BackendConfig) *backend {
 				"issuer/+/crl/delta/der",
 				"issuer/+/crl/delta/pem",
 				"issuer/+/crl/delta",
+				"issuer/+/crl/partition/+/der",
+				"issuer/+/crl/partition/+/pem",
+				"issuer/+/crl/partition/+",
 				"issuer/+/pem",
 				"issuer/+/der",
 				"issuer/+/json",
@@ -119,6 +122,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 				legacyCertBundlePath,
 				legacyCertBundleBackupPath,
 				keyPrefix,
+				ocspResponderPrefix,
 			},
 		},
 
@@ -153,6 +157,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathGetIssuer(&b),
 			pathGetUnauthedIssuer(&b),
 			pathGetIssuerCRL(&b),
+			pathGetIssuerCRLPartition(&b),
 			pathImportIssuer(&b),
 			pathIssuerIssue(&b),
 			pathIssuerSign(&b),
@@ -166,6 +171,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathConfigIssuers(&b),
 			pathReplaceRoot(&b),
 			pathRevokeIssuer(&b),
+			pathOcspResponder(&b),
 
 			// Key APIs
 			pathListKeys(&b),
@@ -260,6 +266,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 	cannotRebuildCRLs := conf.System.ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
 		conf.System.ReplicationState().HasState(consts.ReplicationDRSecondary)
 	b.crlBuilder = newCRLBuilder(!cannotRebuildCRLs)
+	b.ocspCache = newOcspResponseCache()
 
 	// Delay the first tidy until after we've started up.
 	b.lastTidy = time.Now()
@@ -302,6 +309,7 @@ type backend struct {
 
 	pkiStorageVersion atomic.Value
 	crlBuilder        *crlBuilder
+	ocspCache         *ocspResponseCache
 
 	// Write lock around issuers and keys.
 	issuersLock sync.RWMutex
@@ -487,6 +495,7 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 			b.crlBuilder.requestRebuildIfActiveNode(b)
 		}()
 	case strings.HasPrefix(key, issuerPrefix):
+		b.ocspCache.purge()
 		if !b.useLegacyBundleCaStorage() {
 			// See note in updateDefaultIssuerId about why this is necessary.
 			// We do this ahead of CRL rebuilding just so we know that things
@@ -503,6 +512,11 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 	case key == "config/crl":
 		// We may need to reload our OCSP status flag
 		b.crlBuilder.markConfigDirty()
+		b.ocspCache.purge()
+	case strings.HasPrefix(key, revokedPath), strings.HasPrefix(key, ocspResponderPrefix):
+		// Cached OCSP responses may no longer reflect the status of a
+		// certificate or the responder signing them.
+		b.ocspCache.purge()
 	case key == storageAcmeConfig:
 		b.acmeState.markConfigDirty()
 	case key == storageIssuerConfig:
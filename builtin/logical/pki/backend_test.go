@@ -7047,9 +7047,13 @@ func TestProperAuthing(t *testing.T) {
 		"issuer/default/crl/delta":               shouldBeUnauthedReadList,
 		"issuer/default/crl/delta/der":           shouldBeUnauthedReadList,
 		"issuer/default/crl/delta/pem":           shouldBeUnauthedReadList,
+		"issuer/default/crl/partition/0":         shouldBeUnauthedReadList,
+		"issuer/default/crl/partition/0/der":     shouldBeUnauthedReadList,
+		"issuer/default/crl/partition/0/pem":     shouldBeUnauthedReadList,
 		"issuer/default/issue/test":              shouldBeAuthed,
 		"issuer/default/resign-crls":             shouldBeAuthed,
 		"issuer/default/revoke":                  shouldBeAuthed,
+		"issuer/default/ocsp-responder":          shouldBeAuthed,
 		"issuer/default/sign-intermediate":       shouldBeAuthed,
 		"issuer/default/sign-revocation-list":    shouldBeAuthed,
 		"issuer/default/sign-self-issued":        shouldBeAuthed,
@@ -7154,6 +7158,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "{issuer_ref}") {
 			raw_path = strings.ReplaceAll(raw_path, "{issuer_ref}", "default")
 		}
+		if strings.Contains(raw_path, "{partition}") {
+			raw_path = strings.ReplaceAll(raw_path, "{partition}", "0")
+		}
 		if strings.Contains(raw_path, "{key_ref}") {
 			raw_path = strings.ReplaceAll(raw_path, "{key_ref}", "default")
 		}
@@ -4,6 +4,7 @@
 package pki
 
 import (
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -40,6 +41,9 @@ type inputBundle struct {
 	role    *roleEntry
 	req     *logical.Request
 	apiData *framework.FieldData
+
+	// When set, precertificates are submitted to the issuer's CT logs.
+	ctSubmitter *ctSubmitter
 }
 
 var (
@@ -1532,6 +1536,20 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 	// This will have been read in from the getGlobalAIAURLs function
 	creation.Params.URLs = caSign.URLs
 
+	// Partitioned CRL distribution points depend on the expiry of the
+	// certificate.
+	if caSign.URLs != nil && crlPartitionDistributionPoints(caSign.URLs.CRLDistributionPoints, 0) != nil {
+		config, err := b.crlBuilder.getConfigWithUpdate(b.makeStorageContext(context.Background(), data.req.Storage))
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to fetch CRL configuration: %w", err)
+		}
+		creation.Params.URLs = withCRLPartition(caSign.URLs, config.Partitions, notAfter)
+	}
+
+	if data.ctSubmitter != nil {
+		creation.Params.PrecertificateHook = data.ctSubmitter.submit
+	}
+
 	// If the max path length in the role is not nil, it was specified at
 	// generation time with the max_path_length parameter; otherwise derive it
 	// from the signing certificate
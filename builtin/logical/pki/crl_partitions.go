@@ -0,0 +1,231 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/openbao/openbao/sdk/v2/helper/certutil"
+	"github.com/openbao/openbao/sdk/v2/helper/errutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	// crlPartitionTemplate is replaced in CRL distribution points with the
+	// index of the CRL partition a certificate belongs to.
+	crlPartitionTemplate = "{{crl_partition}}"
+
+	crlPartitionPathSuffix = "-partition-"
+
+	maxCRLPartitions = 1024
+)
+
+// crlPartitionInfo tracks the last written version of a CRL partition, so
+// that partitions whose entries did not change are not rewritten.
+type crlPartitionInfo struct {
+	Digest     string    `json:"digest"`
+	NextUpdate time.Time `json:"next_update"`
+}
+
+func crlPartitionPath(crlPath string, index int) string {
+	return crlPath + crlPartitionPathSuffix + strconv.Itoa(index)
+}
+
+// crlPartitionForExpiry assigns a certificate to a CRL partition. Only the
+// day on which the certificate expires is used, so the assignment is known
+// at issuance and stays the same across CRL rebuilds.
+func crlPartitionForExpiry(notAfter time.Time, partitions int) int {
+	index := (notAfter.Unix() / 86400) % int64(partitions)
+	if index < 0 {
+		index += int64(partitions)
+	}
+	return int(index)
+}
+
+// templateCRLPartition templates the distribution points containing the
+// CRL partition placeholder with the given partition.
+func templateCRLPartition(urls []string, partition int) []string {
+	templated := make([]string, len(urls))
+	for index, uri := range urls {
+		templated[index] = strings.ReplaceAll(uri, crlPartitionTemplate, strconv.Itoa(partition))
+	}
+	return templated
+}
+
+// crlPartitionDistributionPoints returns the distribution points of the
+// given partition, or nil when none are partitioned.
+func crlPartitionDistributionPoints(urls []string, partition int) []string {
+	var partitioned []string
+	for _, uri := range urls {
+		if strings.Contains(uri, crlPartitionTemplate) {
+			partitioned = append(partitioned, uri)
+		}
+	}
+	if len(partitioned) == 0 {
+		return nil
+	}
+	return templateCRLPartition(partitioned, partition)
+}
+
+// withCRLPartition returns the URLs to place on a certificate expiring at
+// notAfter. When partitioning is disabled, partitioned distribution points
+// are dropped as no such CRLs exist.
+func withCRLPartition(urls *certutil.URLEntries, partitions int, notAfter time.Time) *certutil.URLEntries {
+	if urls == nil || crlPartitionDistributionPoints(urls.CRLDistributionPoints, 0) == nil {
+		return urls
+	}
+
+	result := *urls
+	result.CRLDistributionPoints = nil
+	for _, uri := range urls.CRLDistributionPoints {
+		if strings.Contains(uri, crlPartitionTemplate) {
+			if partitions == 0 {
+				continue
+			}
+			uri = templateCRLPartition([]string{uri}, crlPartitionForExpiry(notAfter, partitions))[0]
+		}
+		result.CRLDistributionPoints = append(result.CRLDistributionPoints, uri)
+	}
+	return &result
+}
+
+func crlPartitionDigest(revoked []pkix.RevokedCertificate, urls []string) string {
+	hash := sha256.New()
+	for _, uri := range urls {
+		fmt.Fprintf(hash, "url:%s\n", uri)
+	}
+	for _, entry := range revoked {
+		fmt.Fprintf(hash, "%s:%d\n", entry.SerialNumber.Text(16), entry.RevocationTime.UnixNano())
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Builds the partitions of a complete CRL, writing only those which changed
+// since they were last built or which are due to expire before the next
+// rebuild. Returns the earliest expiry of the partitions, if any.
+func buildCRLPartitions(sc *storageContext, crlInfo *crlConfig, internalCRLConfig *internalCRLConfigEntry, thisIssuerId issuerID, revoked []pkix.RevokedCertificate, notAfterMap map[string]time.Time, identifier crlID, crlNumber int64) (*time.Time, []string, error) {
+	if thisIssuerId == legacyBundleShimID {
+		return nil, nil, nil
+	}
+
+	crlPath := "crls/" + identifier.String()
+	previous := internalCRLConfig.PartitionMap[identifier]
+	partitions := crlInfo.Partitions
+	if crlInfo.Disable {
+		partitions = 0
+	}
+
+	var signingBundle *certutil.CAInfoBundle
+	var warnings []string
+	if partitions > 0 {
+		var caErr error
+		signingBundle, caErr = sc.fetchCAInfoByIssuerId(thisIssuerId, CRLSigningUsage)
+		if caErr != nil {
+			switch caErr.(type) {
+			case errutil.UserError:
+				return nil, nil, errutil.UserError{Err: fmt.Sprintf("could not fetch the CA certificate: %s", caErr)}
+			default:
+				return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error fetching CA certificate: %s", caErr)}
+			}
+		}
+
+		if crlPartitionDistributionPoints(signingBundle.URLs.CRLDistributionPoints, 0) == nil {
+			warnings = append(warnings, fmt.Sprintf("not building CRL partitions for issuer (%v) as none of its CRL distribution points contain %v", thisIssuerId, crlPartitionTemplate))
+			partitions = 0
+		}
+	}
+
+	// Remove partitions which are no longer in use.
+	for index := partitions; index < len(previous); index++ {
+		if err := sc.Storage.Delete(sc.Context, crlPartitionPath(crlPath, index)); err != nil {
+			return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error removing CRL partition %d: %s", index, err)}
+		}
+	}
+	if partitions == 0 {
+		delete(internalCRLConfig.PartitionMap, identifier)
+		return nil, warnings, nil
+	}
+
+	crlLifetime, err := parseutil.ParseDurationSecond(crlInfo.Expiry)
+	if err != nil {
+		return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error parsing CRL duration of %s", crlInfo.Expiry)}
+	}
+	gracePeriod, err := parseutil.ParseDurationSecond(crlInfo.AutoRebuildGracePeriod)
+	if err != nil {
+		return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error parsing CRL auto-rebuild grace period of %s", crlInfo.AutoRebuildGracePeriod)}
+	}
+
+	buckets := make([][]pkix.RevokedCertificate, partitions)
+	for _, entry := range revoked {
+		index := crlPartitionForExpiry(notAfterMap[serialFromBigInt(entry.SerialNumber)], partitions)
+		buckets[index] = append(buckets[index], entry)
+	}
+
+	// Unchanged partitions are kept only when they remain valid until well
+	// after the next rebuild of the complete CRL.
+	now := time.Now()
+	nextUpdate := now.Add(crlLifetime)
+	refreshBefore := now.Add(max(crlLifetime/2, gracePeriod))
+	earliest := nextUpdate
+
+	infos := make([]crlPartitionInfo, partitions)
+	for index, bucket := range buckets {
+		sort.Slice(bucket, func(i, j int) bool {
+			return bucket[i].SerialNumber.Cmp(bucket[j].SerialNumber) < 0
+		})
+
+		urls := crlPartitionDistributionPoints(signingBundle.URLs.CRLDistributionPoints, index)
+		digest := crlPartitionDigest(bucket, urls)
+		if index < len(previous) && previous[index].Digest == digest && previous[index].NextUpdate.After(refreshBefore) {
+			infos[index] = previous[index]
+			if previous[index].NextUpdate.Before(earliest) {
+				earliest = previous[index].NextUpdate
+			}
+			continue
+		}
+
+		idp, err := certutil.CreateIssuingDistributionPointExt(urls)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not create issuing distribution point extension: %w", err)
+		}
+
+		revocationListTemplate := &x509.RevocationList{
+			RevokedCertificates: bucket,
+			Number:              big.NewInt(crlNumber),
+			ThisUpdate:          now,
+			NextUpdate:          nextUpdate,
+			SignatureAlgorithm:  signingBundle.RevocationSigAlg,
+			ExtraExtensions:     []pkix.Extension{idp},
+		}
+
+		crlBytes, err := x509.CreateRevocationList(rand.Reader, revocationListTemplate, signingBundle.Certificate, signingBundle.PrivateKey)
+		if err != nil {
+			return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error creating CRL partition %d: %s", index, err)}
+		}
+
+		err = sc.Storage.Put(sc.Context, &logical.StorageEntry{
+			Key:   crlPartitionPath(crlPath, index),
+			Value: crlBytes,
+		})
+		if err != nil {
+			return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error storing CRL partition %d: %s", index, err)}
+		}
+
+		infos[index] = crlPartitionInfo{Digest: digest, NextUpdate: nextUpdate}
+	}
+
+	internalCRLConfig.PartitionMap[identifier] = infos
+	return &earliest, warnings, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/certutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRLPartitions(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"not_after":   time.Now().AddDate(0, 0, 30).UTC().Format(time.RFC3339),
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	issuerId := resp.Data["issuer_id"].(issuerID)
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allow_any_name": true,
+	})
+	require.NoError(t, err)
+
+	fullCDP := "http://localhost/v1/pki/issuer/{{issuer_id}}/crl/der"
+	partitionCDP := "http://localhost/v1/pki/issuer/{{issuer_id}}/crl/partition/{{crl_partition}}/der"
+	_, err = CBWrite(b, s, "config/urls", map[string]interface{}{
+		"crl_distribution_points": []string{partitionCDP},
+	})
+	require.Error(t, err, "partition template requires templating")
+	_, err = CBWrite(b, s, "config/urls", map[string]interface{}{
+		"enable_templating":       true,
+		"crl_distribution_points": []string{fullCDP, partitionCDP},
+	})
+	require.NoError(t, err)
+
+	fullURL := fmt.Sprintf("http://localhost/v1/pki/issuer/%v/crl/der", issuerId)
+	partitionURL := func(index int) string {
+		return fmt.Sprintf("http://localhost/v1/pki/issuer/%v/crl/partition/%d/der", issuerId, index)
+	}
+	issue := func(days int) *x509.Certificate {
+		resp, err := CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": "leaf.example.com",
+			"not_after":   time.Now().AddDate(0, 0, days).UTC().Format(time.RFC3339),
+		})
+		requireSuccessNonNilResponse(t, resp, err)
+		return parseCert(t, resp.Data["certificate"].(string))
+	}
+	fetchPartition := func(index int) []byte {
+		resp, err := CBRead(b, s, "issuer/default/crl/partition/"+strconv.Itoa(index)+"/der")
+		requireSuccessNonNilResponse(t, resp, err)
+		if resp.Data[logical.HTTPStatusCode] == 204 {
+			return nil
+		}
+		return resp.Data[logical.HTTPRawBody].([]byte)
+	}
+
+	// Without partitioning, certificates only point to the complete CRL.
+	cert := issue(1)
+	require.Equal(t, []string{fullURL}, cert.CRLDistributionPoints)
+
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"partitions": maxCRLPartitions + 1,
+	})
+	require.Error(t, err)
+	resp, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"partitions": 4,
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, 4, resp.Data["partitions"])
+
+	var certs []*x509.Certificate
+	for days := 1; days <= 8; days++ {
+		cert := issue(days)
+		partition := crlPartitionForExpiry(cert.NotAfter, 4)
+		require.Equal(t, []string{fullURL, partitionURL(partition)}, cert.CRLDistributionPoints)
+		certs = append(certs, cert)
+	}
+
+	revoke := func(cert *x509.Certificate) {
+		_, err := CBWrite(b, s, "revoke", map[string]interface{}{
+			"serial_number": serialFromCert(cert),
+		})
+		require.NoError(t, err)
+	}
+	requirePartitions := func(count int, revoked []*x509.Certificate) [][]byte {
+		t.Helper()
+		var partitions [][]byte
+		for index := 0; index < count; index++ {
+			der := fetchPartition(index)
+			require.NotNil(t, der, "partition %d", index)
+			crl, err := x509.ParseRevocationList(der)
+			require.NoError(t, err)
+
+			var idp bool
+			for _, ext := range crl.Extensions {
+				if ext.Id.Equal(certutil.IssuingDistributionPointOID) {
+					idp = ext.Critical && bytes.Contains(ext.Value, []byte(partitionURL(index)))
+				}
+			}
+			require.True(t, idp, "partition %d lacks its issuing distribution point", index)
+
+			var expected []string
+			for _, cert := range revoked {
+				if crlPartitionForExpiry(cert.NotAfter, count) == index {
+					expected = append(expected, serialFromCert(cert))
+				}
+			}
+			var serials []string
+			for _, entry := range crl.RevokedCertificateEntries {
+				serials = append(serials, serialFromBigInt(entry.SerialNumber))
+			}
+			require.ElementsMatch(t, expected, serials, "partition %d", index)
+
+			partitions = append(partitions, der)
+		}
+		require.Nil(t, fetchPartition(count))
+		return partitions
+	}
+
+	revoke(certs[0])
+	revoke(certs[1])
+	before := requirePartitions(4, certs[:2])
+
+	// Only the partition containing a newly revoked certificate changes.
+	revoke(certs[2])
+	after := requirePartitions(4, certs[:3])
+	changed := crlPartitionForExpiry(certs[2].NotAfter, 4)
+	for index := range after {
+		if index == changed {
+			require.NotEqual(t, before[index], after[index])
+		} else {
+			require.Equal(t, before[index], after[index], "partition %d was rewritten", index)
+		}
+	}
+
+	// Rotating the complete CRL leaves unchanged partitions as they are.
+	resp, err = CBRead(b, s, "crl/rotate")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, after, requirePartitions(4, certs[:3]))
+
+	// Repartitioning removes the partitions which are no longer in use.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"partitions": 2,
+	})
+	require.NoError(t, err)
+	requirePartitions(2, certs[:3])
+	require.Nil(t, fetchPartition(3))
+
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"partitions": 0,
+	})
+	require.NoError(t, err)
+	require.Nil(t, fetchPartition(0))
+	require.Equal(t, []string{fullURL}, issue(1).CRLDistributionPoints)
+}
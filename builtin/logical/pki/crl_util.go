@@ -139,6 +139,10 @@ func (cb *crlBuilder) reloadConfigIfRequired(sc *storageContext) error {
 			cb.config = defaultCrlConfig
 		}
 
+		// Cached OCSP responses may have been built from a previous
+		// configuration.
+		sc.Backend.ocspCache.purge()
+
 		// Updated the config; unset dirty.
 		cb.dirty.Store(false)
 		if !cb.haveInitializedConfig {
@@ -612,6 +616,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		return nil, fmt.Errorf("error saving revoked certificate to new location: %w", err)
 	}
 	sc.Backend.ifCountEnabledIncrementTotalRevokedCertificatesCount(certsCounted, revEntry.Key)
+	sc.Backend.ocspCache.purge()
 
 	// From here on out, the certificate has been revoked locally. Any other
 	// persistence issues might still err, but any other failure messages
@@ -929,13 +934,17 @@ func buildAnyLocalCRLs(
 	var unassignedCerts []pkix.RevokedCertificate
 	var revokedCertsMap map[issuerID][]pkix.RevokedCertificate
 
+	// The expiry of each revoked certificate, by serial, used to assign
+	// it to a CRL partition.
+	notAfterMap := make(map[string]time.Time)
+
 	// If the CRL is disabled do not bother reading in all the revoked certificates.
 	if !globalCRLConfig.Disable {
 		// Next, we load and parse all revoked certificates. We need to assign
 		// these certificates to an issuer. Some certificates will not be
 		// assignable (if they were issued by a since-deleted issuer), so we need
 		// a separate pool for those.
-		unassignedCerts, revokedCertsMap, err = getLocalRevokedCertEntries(sc, issuerIDCertMap, isDelta)
+		unassignedCerts, revokedCertsMap, err = getLocalRevokedCertEntries(sc, issuerIDCertMap, notAfterMap, isDelta)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error building CRLs: unable to get revoked certificate entries: %w", err)
 		}
@@ -949,7 +958,7 @@ func buildAnyLocalCRLs(
 			// complete CRL with the issuer on it. There's no reason to
 			// duplicate this serial number on the delta, hence the above
 			// guard for isDelta.
-			if err := augmentWithRevokedIssuers(issuerIDEntryMap, issuerIDCertMap, revokedCertsMap); err != nil {
+			if err := augmentWithRevokedIssuers(issuerIDEntryMap, issuerIDCertMap, revokedCertsMap, notAfterMap); err != nil {
 				return nil, nil, fmt.Errorf("error building CRLs: unable to parse revoked issuers: %w", err)
 			}
 		}
@@ -964,7 +973,7 @@ func buildAnyLocalCRLs(
 
 	rebuildWarnings, err := buildAnyCRLsWithCerts(sc, issuersConfig, globalCRLConfig, internalCRLConfig,
 		issuers, issuerIDEntryMap, keySubjectIssuersMap,
-		unassignedCerts, revokedCertsMap,
+		unassignedCerts, revokedCertsMap, notAfterMap,
 		forceNew, isDelta)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error building CRLs: %w", err)
@@ -1016,6 +1025,7 @@ func buildAnyCRLsWithCerts(
 	keySubjectIssuersMap map[keyID]map[string][]issuerID,
 	unassignedCerts []pkix.RevokedCertificate,
 	revokedCertsMap map[issuerID][]pkix.RevokedCertificate,
+	notAfterMap map[string]time.Time,
 	forceNew bool,
 	isDelta bool,
 ) ([]string, error) {
@@ -1142,6 +1152,20 @@ func buildAnyCRLsWithCerts(
 				return nil, fmt.Errorf("error building CRLs: unable to build CRL for issuer (%v): %w", representative, err)
 			}
 
+			// Complete CRLs are also sharded into partitions, if enabled, so
+			// rebuild those (or clean them up) as well. The earliest expiry
+			// is kept so that auto-rebuilding refreshes all of them in time.
+			if !isDelta {
+				partitionNextUpdate, partitionWarnings, err := buildCRLPartitions(sc, globalCRLConfig, internalCRLConfig, representative, revokedCerts, notAfterMap, crlIdentifier, crlNumber)
+				if err != nil {
+					return nil, fmt.Errorf("error building CRLs: unable to build CRL partitions for issuer (%v): %w", representative, err)
+				}
+				warnings = append(warnings, partitionWarnings...)
+				if partitionNextUpdate != nil && partitionNextUpdate.Before(*nextUpdate) {
+					nextUpdate = partitionNextUpdate
+				}
+			}
+
 			internalCRLConfig.CRLExpirationMap[crlIdentifier] = *nextUpdate
 			if !isDelta {
 				internalCRLConfig.LastCompleteNumberMap[crlIdentifier] = crlNumber
@@ -1222,7 +1246,7 @@ func associateRevokedCertWithIsssuer(revInfo *revocationInfo, revokedCert *x509.
 	return false
 }
 
-func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID]*x509.Certificate, isDelta bool) ([]pkix.RevokedCertificate, map[issuerID][]pkix.RevokedCertificate, error) {
+func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID]*x509.Certificate, notAfterMap map[string]time.Time, isDelta bool) ([]pkix.RevokedCertificate, map[issuerID][]pkix.RevokedCertificate, error) {
 	var unassignedCerts []pkix.RevokedCertificate
 	revokedCertsMap := make(map[issuerID][]pkix.RevokedCertificate)
 
@@ -1313,6 +1337,7 @@ func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID
 		} else {
 			newRevCert.RevocationTime = time.Unix(revInfo.RevocationTime, 0).UTC()
 		}
+		notAfterMap[serialFromCert(revokedCert)] = revokedCert.NotAfter
 
 		// If we have a CertificateIssuer field on the revocation entry,
 		// prefer it to manually checking each issuer signature, assuming it
@@ -1351,7 +1376,7 @@ func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID
 	return unassignedCerts, revokedCertsMap, nil
 }
 
-func augmentWithRevokedIssuers(issuerIDEntryMap map[issuerID]*issuerEntry, issuerIDCertMap map[issuerID]*x509.Certificate, revokedCertsMap map[issuerID][]pkix.RevokedCertificate) error {
+func augmentWithRevokedIssuers(issuerIDEntryMap map[issuerID]*issuerEntry, issuerIDCertMap map[issuerID]*x509.Certificate, revokedCertsMap map[issuerID][]pkix.RevokedCertificate, notAfterMap map[string]time.Time) error {
 	// When setup our maps with the legacy CA bundle, we only have a
 	// single entry here. This entry is never revoked, so the outer loop
 	// will exit quickly.
@@ -1365,6 +1390,7 @@ func augmentWithRevokedIssuers(issuerIDEntryMap map[issuerID]*issuerEntry, issue
 			SerialNumber:   ourCert.SerialNumber,
 			RevocationTime: ourIssuer.RevocationTimeUTC,
 		}
+		notAfterMap[serialFromCert(ourCert)] = ourCert.NotAfter
 
 		for otherIssuerID := range issuerIDEntryMap {
 			if otherIssuerID == ourIssuerID {
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/certutil"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// ctSubmissionMode controls how failures to submit a precertificate to an
+// issuer's CT logs are handled.
+type ctSubmissionMode string
+
+const (
+	// ctSubmissionRequire fails issuance unless every log returns an SCT.
+	ctSubmissionRequire ctSubmissionMode = "require"
+
+	// ctSubmissionBestEffort issues the certificate with the SCTs of the
+	// logs which could be reached, warning about the others.
+	ctSubmissionBestEffort ctSubmissionMode = "best-effort"
+)
+
+// ctSubmissionTimeout bounds each request to a CT log.
+const ctSubmissionTimeout = 10 * time.Second
+
+func parseCTSubmissionMode(raw string) (ctSubmissionMode, error) {
+	switch mode := ctSubmissionMode(raw); mode {
+	case ctSubmissionRequire, ctSubmissionBestEffort:
+		return mode, nil
+	case "":
+		return ctSubmissionRequire, nil
+	default:
+		return "", fmt.Errorf("unknown value for field `ct_submission_mode`: %q; possible values are %q and %q", raw, ctSubmissionRequire, ctSubmissionBestEffort)
+	}
+}
+
+// signedCertificateTimestamp is the response of a CT log to add-pre-chain,
+// per RFC 6962 Section 4.1.
+type signedCertificateTimestamp struct {
+	SCTVersion uint8  `json:"sct_version"`
+	ID         []byte `json:"id"`
+	Timestamp  uint64 `json:"timestamp"`
+	Extensions []byte `json:"extensions"`
+	Signature  []byte `json:"signature"`
+}
+
+// ctSubmitter submits the precertificates of an issuer to its CT logs and
+// builds the SCT list extension to embed in the final certificate.
+type ctSubmitter struct {
+	ctx    context.Context
+	logger hclog.Logger
+	client *http.Client
+	logs   []string
+	mode   ctSubmissionMode
+
+	// The issuer's certificate followed by the rest of its chain.
+	chain []*certutil.CertBlock
+
+	// Warnings about logs skipped in best-effort mode.
+	warnings []string
+}
+
+// newCTSubmitter returns the CT submitter of the issuer, or nil when the
+// issuer has no CT logs configured.
+func (sc *storageContext) newCTSubmitter(issuerId issuerID, caSign *certutil.CAInfoBundle) (*ctSubmitter, error) {
+	// Legacy bundles predate issuer configuration.
+	if issuerId == legacyBundleShimID {
+		return nil, nil
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, err
+	}
+	if len(issuer.CTLogURLs) == 0 {
+		return nil, nil
+	}
+
+	return &ctSubmitter{
+		ctx:    sc.Context,
+		logger: sc.Backend.Logger(),
+		client: &http.Client{Timeout: ctSubmissionTimeout},
+		logs:   issuer.CTLogURLs,
+		mode:   issuer.CTSubmissionMode,
+		chain:  caSign.GetFullChain(),
+	}, nil
+}
+
+// submit is a certutil.CreationParameters.PrecertificateHook; it submits the
+// precertificate to each log and returns the SCT list extension.
+func (s *ctSubmitter) submit(precert *x509.Certificate) ([]pkix.Extension, error) {
+	var scts []*signedCertificateTimestamp
+	for _, log := range s.logs {
+		sct, err := s.submitToLog(log, precert)
+		if err != nil {
+			if s.mode != ctSubmissionBestEffort {
+				return nil, fmt.Errorf("failed to submit precertificate to CT log %v: %w", log, err)
+			}
+
+			s.logger.Warn("failed to submit precertificate to CT log", "log", log, "serial_number", certutil.GetHexFormatted(precert.SerialNumber.Bytes(), ":"), "error", err)
+			s.warnings = append(s.warnings, fmt.Sprintf("certificate was not submitted to CT log %v: %v", log, err))
+			continue
+		}
+		scts = append(scts, sct)
+	}
+
+	if len(scts) == 0 {
+		return nil, nil
+	}
+
+	value, err := marshalSCTList(scts)
+	if err != nil {
+		return nil, err
+	}
+	return []pkix.Extension{{Id: certutil.CTSCTListOID, Value: value}}, nil
+}
+
+func (s *ctSubmitter) submitToLog(log string, precert *x509.Certificate) (*signedCertificateTimestamp, error) {
+	chain := [][]byte{precert.Raw}
+	for _, block := range s.chain {
+		chain = append(chain, block.Bytes)
+	}
+	body, err := json.Marshal(map[string]interface{}{"chain": chain})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, strings.TrimSuffix(log, "/")+"/ct/v1/add-pre-chain", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var sct signedCertificateTimestamp
+	if err := json.Unmarshal(respBody, &sct); err != nil {
+		return nil, fmt.Errorf("unable to decode response: %w", err)
+	}
+	switch {
+	case sct.SCTVersion != 0:
+		return nil, fmt.Errorf("unsupported SCT version %d", sct.SCTVersion)
+	case len(sct.ID) != 32:
+		return nil, fmt.Errorf("invalid log id of length %d", len(sct.ID))
+	case len(sct.Signature) == 0:
+		return nil, fmt.Errorf("missing SCT signature")
+	}
+
+	return &sct, nil
+}
+
+// marshalSCTList encodes the SCTs as the value of the RFC 6962 SCT list
+// extension: an OCTET STRING holding the TLS encoded
+// SignedCertificateTimestampList. The signatures returned by logs are
+// already TLS encoded.
+func marshalSCTList(scts []*signedCertificateTimestamp) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(list *cryptobyte.Builder) {
+		for _, sct := range scts {
+			list.AddUint16LengthPrefixed(func(entry *cryptobyte.Builder) {
+				entry.AddUint8(sct.SCTVersion)
+				entry.AddBytes(sct.ID)
+				entry.AddUint64(sct.Timestamp)
+				entry.AddUint16LengthPrefixed(func(extensions *cryptobyte.Builder) {
+					extensions.AddBytes(sct.Extensions)
+				})
+				entry.AddBytes(sct.Signature)
+			})
+		}
+	})
+
+	list, err := b.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode SCT list: %w", err)
+	}
+	return asn1.Marshal(list)
+}
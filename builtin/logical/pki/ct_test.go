@@ -0,0 +1,195 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openbao/openbao/sdk/v2/helper/certutil"
+	"github.com/openbao/openbao/sdk/v2/helper/testhelpers/schema"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+func TestPki_CTSubmission(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "48h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allow_any_name": true,
+		"no_store":       true,
+		"ttl":            "1h",
+	})
+	require.NoError(t, err)
+
+	logID := bytes.Repeat([]byte{0x42}, 32)
+	signature := []byte{0x04, 0x03, 0x00, 0x02, 0xaa, 0xbb}
+	var submitted []*x509.Certificate
+	goodLog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/log/ct/v1/add-pre-chain" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Chain [][]byte `json:"chain"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Chain) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		precert, err := x509.ParseCertificate(req.Chain[0])
+		if err != nil || !bytes.Equal(req.Chain[1], rootCert.Raw) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		submitted = append(submitted, precert)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sct_version": 0,
+			"id":          logID,
+			"timestamp":   1700000000000,
+			"extensions":  "",
+			"signature":   signature,
+		})
+	}))
+	defer goodLog.Close()
+	badLog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer badLog.Close()
+
+	// Invalid configuration is rejected
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_submission_mode": "sometimes",
+	})
+	require.ErrorContains(t, err, "ct_submission_mode")
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_log_urls": "not a url",
+	})
+	require.ErrorContains(t, err, "ct_log_urls")
+
+	resp, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_log_urls": []string{goodLog.URL + "/log/"},
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/default"), logical.PatchOperation), resp, true)
+	require.Equal(t, []string{goodLog.URL + "/log/"}, resp.Data["ct_log_urls"])
+	require.Equal(t, "require", resp.Data["ct_submission_mode"])
+
+	issue := func() (*logical.Response, error) {
+		return CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": "leaf.example.com",
+		})
+	}
+	requireSCTs := func(cert *x509.Certificate, count int) {
+		t.Helper()
+		var value []byte
+		for _, ext := range cert.Extensions {
+			if ext.Id.Equal(certutil.CTSCTListOID) {
+				_, err := asn1.Unmarshal(ext.Value, &value)
+				require.NoError(t, err)
+			}
+			require.False(t, ext.Id.Equal(certutil.CTPoisonOID), "final certificate must not be poisoned")
+		}
+		if count == 0 {
+			require.Nil(t, value)
+			return
+		}
+
+		list := cryptobyte.String(value)
+		var entries cryptobyte.String
+		require.True(t, list.ReadUint16LengthPrefixed(&entries) && list.Empty())
+		for i := 0; i < count; i++ {
+			var entry, id, extensions cryptobyte.String
+			var version uint8
+			var timestamp uint64
+			require.True(t, entries.ReadUint16LengthPrefixed(&entry))
+			require.True(t, entry.ReadUint8(&version) && entry.ReadBytes((*[]byte)(&id), 32) && entry.ReadUint64(&timestamp))
+			require.True(t, entry.ReadUint16LengthPrefixed(&extensions) && extensions.Empty())
+			require.Equal(t, uint8(0), version)
+			require.Equal(t, logID, []byte(id))
+			require.Equal(t, uint64(1700000000000), timestamp)
+			require.Equal(t, signature, []byte(entry))
+		}
+		require.True(t, entries.Empty())
+	}
+
+	resp, err = issue()
+	requireSuccessNonNilResponse(t, resp, err)
+	leaf := parseCert(t, resp.Data["certificate"].(string))
+	requireSignedBy(t, leaf, rootCert)
+	requireSCTs(leaf, 1)
+
+	require.Len(t, submitted, 1)
+	precert := submitted[0]
+	requireSignedBy(t, precert, rootCert)
+	require.Equal(t, leaf.SerialNumber, precert.SerialNumber)
+	require.Equal(t, leaf.RawSubject, precert.RawSubject)
+	require.Equal(t, leaf.NotAfter, precert.NotAfter)
+	poison := precert.Extensions[len(precert.Extensions)-1]
+	require.True(t, poison.Id.Equal(certutil.CTPoisonOID) && poison.Critical)
+
+	// Signing a CSR is also submitted
+	_, csrPem := generateTestCsr(t, certutil.ECPrivateKey, 256)
+	resp, err = CBWrite(b, s, "sign-verbatim", map[string]interface{}{
+		"csr": csrPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	requireSCTs(parseCert(t, resp.Data["certificate"].(string)), 1)
+	require.Len(t, submitted, 2)
+
+	// An unavailable log fails issuance when submission is required
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_log_urls": []string{goodLog.URL + "/log", badLog.URL},
+	})
+	require.NoError(t, err)
+	resp, err = issue()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), badLog.URL)
+
+	// ... but only warns in best-effort mode
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_submission_mode": "best-effort",
+	})
+	require.NoError(t, err)
+	resp, err = issue()
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Len(t, resp.Warnings, 1)
+	require.Contains(t, resp.Warnings[0], badLog.URL)
+	requireSCTs(parseCert(t, resp.Data["certificate"].(string)), 1)
+
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_log_urls": []string{badLog.URL},
+	})
+	require.NoError(t, err)
+	resp, err = issue()
+	requireSuccessNonNilResponse(t, resp, err)
+	requireSCTs(parseCert(t, resp.Data["certificate"].(string)), 0)
+
+	// Removing the logs disables submission
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"ct_log_urls": []string{},
+	})
+	require.NoError(t, err)
+	submissions := len(submitted)
+	resp, err = issue()
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Empty(t, resp.Warnings)
+	requireSCTs(parseCert(t, resp.Data["certificate"].(string)), 0)
+	require.Len(t, submitted, submissions)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/crypto/ocsp"
+)
+
+// maxOcspCacheEntries bounds the number of signed OCSP responses kept in
+// memory by each mount; the least recently used are evicted first.
+const maxOcspCacheEntries = 16384
+
+type ocspCacheEntry struct {
+	response []byte
+	expires  time.Time
+}
+
+// ocspResponseCache holds signed OCSP responses, so that repeated queries
+// for the same certificate neither hit storage nor sign a new response.
+// It is local to each node and purged whenever anything which could change
+// a response does: revocations, issuers, OCSP responders or the CRL
+// configuration.
+type ocspResponseCache struct {
+	// Responses built from state read before a purge must not be added
+	// after it, so purges bump the generation, checked when adding.
+	lock  sync.Mutex
+	gen   uint64
+	cache *lru.Cache
+}
+
+func newOcspResponseCache() *ocspResponseCache {
+	// lru.New only fails on a non-positive size.
+	cache, _ := lru.New(maxOcspCacheEntries)
+	return &ocspResponseCache{cache: cache}
+}
+
+// ocspCacheKey identifies the certificate a request is about. Requests
+// hashing the issuer differently get different responses, so the hash is
+// part of the key.
+func ocspCacheKey(req *ocsp.Request) string {
+	return fmt.Sprintf("%d/%x/%x/%s", req.HashAlgorithm, req.IssuerNameHash, req.IssuerKeyHash, req.SerialNumber.Text(16))
+}
+
+func (c *ocspResponseCache) get(key string) ([]byte, bool) {
+	raw, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	entry := raw.(*ocspCacheEntry)
+	if time.Now().After(entry.expires) {
+		c.cache.Remove(key)
+		return nil, false
+	}
+
+	return entry.response, true
+}
+
+// generation returns the current generation of the cache, to be given to
+// put along with responses built afterwards.
+func (c *ocspResponseCache) generation() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.gen
+}
+
+func (c *ocspResponseCache) put(key string, response []byte, ttl time.Duration, gen uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	c.cache.Add(key, &ocspCacheEntry{
+		response: response,
+		expires:  time.Now().Add(ttl),
+	})
+}
+
+func (c *ocspResponseCache) purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gen++
+	c.cache.Purge()
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// maxOcspNonceLength is the largest nonce echoed back to clients; RFC 8954
+// allows responders to ignore longer ones, which otherwise let clients
+// defeat caching with arbitrarily large requests.
+const maxOcspNonceLength = 32
+
+var (
+	oidOcspNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+	oidOcspBasic = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+
+	// Signature algorithms x/crypto/ocsp may sign responses with, mapped
+	// to the hash signed over. Ed25519 signs the message itself.
+	ocspSignatureHashes = map[string]crypto.Hash{
+		"1.2.840.113549.1.1.5":  crypto.SHA1,
+		"1.2.840.113549.1.1.11": crypto.SHA256,
+		"1.2.840.113549.1.1.12": crypto.SHA384,
+		"1.2.840.113549.1.1.13": crypto.SHA512,
+		"1.2.840.10045.4.1":     crypto.SHA1,
+		"1.2.840.10045.4.3.2":   crypto.SHA256,
+		"1.2.840.10045.4.3.3":   crypto.SHA384,
+		"1.2.840.10045.4.3.4":   crypto.SHA512,
+		"1.3.101.112":           crypto.Hash(0),
+	}
+)
+
+// x/crypto/ocsp neither exposes the extensions of requests nor lets callers
+// set the extensions of responses (only those of the single response within
+// them), so the structures from RFC 6960 needed to handle nonces are
+// defined here.
+type ocspRequestASN1 struct {
+	TBSRequest ocspTBSRequest
+}
+
+type ocspTBSRequest struct {
+	Version           int           `asn1:"explicit,tag:0,default:0,optional"`
+	RequestorName     asn1.RawValue `asn1:"explicit,tag:1,optional"`
+	RequestList       []asn1.RawValue
+	RequestExtensions []pkix.Extension `asn1:"explicit,tag:2,optional"`
+}
+
+type ocspResponseASN1 struct {
+	Status   asn1.Enumerated
+	Response ocspResponseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type ocspBasicResponse struct {
+	TBSResponseData    ocspResponseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type ocspResponseData struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         asn1.RawValue
+	Responses          asn1.RawValue
+	ResponseExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+// getOcspNonce returns the nonce extension of a DER encoded OCSP request,
+// if it has one that should be echoed back in the response.
+func getOcspNonce(derReq []byte) (*pkix.Extension, error) {
+	var req ocspRequestASN1
+	if _, err := asn1.Unmarshal(derReq, &req); err != nil {
+		return nil, err
+	}
+
+	for _, ext := range req.TBSRequest.RequestExtensions {
+		if !ext.Id.Equal(oidOcspNonce) {
+			continue
+		}
+
+		// The nonce is itself an OCTET STRING, though some clients send
+		// it bare.
+		nonce := ext.Value
+		var inner []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &inner); err == nil && len(rest) == 0 {
+			nonce = inner
+		}
+		if len(nonce) == 0 || len(nonce) > maxOcspNonceLength {
+			return nil, nil
+		}
+
+		return &pkix.Extension{Id: oidOcspNonce, Value: ext.Value}, nil
+	}
+
+	return nil, nil
+}
+
+// addOcspResponseExtensions adds the given extensions to a signed OCSP
+// response, signing it again with the same algorithm and key.
+func addOcspResponseExtensions(derResp []byte, exts []pkix.Extension, signer crypto.Signer) ([]byte, error) {
+	var resp ocspResponseASN1
+	if _, err := asn1.Unmarshal(derResp, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Response.ResponseType.Equal(oidOcspBasic) {
+		return nil, errors.New("unexpected OCSP response type")
+	}
+
+	var basicResp ocspBasicResponse
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basicResp); err != nil {
+		return nil, err
+	}
+
+	hashFunc, ok := ocspSignatureHashes[basicResp.SignatureAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported OCSP signature algorithm: %v", basicResp.SignatureAlgorithm.Algorithm)
+	}
+
+	basicResp.TBSResponseData.Raw = nil
+	basicResp.TBSResponseData.ResponseExtensions = append(basicResp.TBSResponseData.ResponseExtensions, exts...)
+	tbsResponseData, err := asn1.Marshal(basicResp.TBSResponseData)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := tbsResponseData
+	if hashFunc != crypto.Hash(0) {
+		h := hashFunc.New()
+		h.Write(tbsResponseData)
+		signed = h.Sum(nil)
+	}
+
+	signature, err := signer.Sign(rand.Reader, signed, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	basicResp.TBSResponseData.Raw = tbsResponseData
+	basicResp.Signature = asn1.BitString{
+		Bytes:     signature,
+		BitLength: 8 * len(signature),
+	}
+
+	resp.Response.Response, err = asn1.Marshal(basicResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(resp)
+}
@@ -27,6 +27,9 @@ type crlConfig struct {
 	OcspExpiry             string `json:"ocsp_expiry"`
 	EnableDelta            bool   `json:"enable_delta"`
 	DeltaRebuildInterval   string `json:"delta_rebuild_interval"`
+	Partitions             int    `json:"partitions"`
+	OcspCacheTTL           string `json:"ocsp_cache_ttl"`
+	OcspUnknownStatus      string `json:"ocsp_unknown_status"`
 }
 
 // Implicit default values for the config if it does not exist.
@@ -40,6 +43,9 @@ var defaultCrlConfig = crlConfig{
 	AutoRebuildGracePeriod: "12h",
 	EnableDelta:            false,
 	DeltaRebuildInterval:   "15m",
+	Partitions:             0,
+	OcspCacheTTL:           "0s",
+	OcspUnknownStatus:      ocspUnknownStatusGood,
 }
 
 func pathConfigCRL(b *backend) *framework.Path {
@@ -89,6 +95,20 @@ the NextUpdate field); defaults to 12 hours`,
 				Description: `The time between delta CRL rebuilds if a new revocation has occurred. Must be shorter than the CRL expiry. Defaults to 15m.`,
 				Default:     "15m",
 			},
+			"partitions": {
+				Type:        framework.TypeInt,
+				Description: `The number of partitions to shard each issuer's complete CRL into, in addition to the complete CRL. Certificates are assigned to partitions by expiry and point to theirs through CRL distribution points containing the {{crl_partition}} template. Defaults to 0, disabling partitioning.`,
+			},
+			"ocsp_cache_ttl": {
+				Type:        framework.TypeString,
+				Description: `The amount of time a signed OCSP response is cached and served again to identical requests, without looking up the certificate. Requests containing a nonce are never cached. Must be shorter than ocsp_expiry. Defaults to 0s, disabling the cache.`,
+				Default:     "0s",
+			},
+			"ocsp_unknown_status": {
+				Type:        framework.TypeString,
+				Description: `The status returned by OCSP for serial numbers of the matching issuer which this mount has no record of issuing: good, unknown or revoked. Defaults to good.`,
+				Default:     ocspUnknownStatusGood,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -143,6 +163,21 @@ the NextUpdate field); defaults to 12 hours`,
 								Description: `The time between delta CRL rebuilds if a new revocation has occurred. Must be shorter than the CRL expiry. Defaults to 15m.`,
 								Required:    true,
 							},
+							"partitions": {
+								Type:        framework.TypeInt,
+								Description: `The number of partitions to shard each issuer's complete CRL into, in addition to the complete CRL. Certificates are assigned to partitions by expiry and point to theirs through CRL distribution points containing the {{crl_partition}} template. Defaults to 0, disabling partitioning.`,
+								Required:    true,
+							},
+							"ocsp_cache_ttl": {
+								Type:        framework.TypeString,
+								Description: `The amount of time a signed OCSP response is cached and served again to identical requests, without looking up the certificate. Requests containing a nonce are never cached. Must be shorter than ocsp_expiry. Defaults to 0s, disabling the cache.`,
+								Required:    true,
+							},
+							"ocsp_unknown_status": {
+								Type:        framework.TypeString,
+								Description: `The status returned by OCSP for serial numbers of the matching issuer which this mount has no record of issuing: good, unknown or revoked. Defaults to good.`,
+								Required:    true,
+							},
 						},
 					}},
 				},
@@ -195,6 +230,20 @@ the NextUpdate field); defaults to 12 hours`,
 								Description: `The time between delta CRL rebuilds if a new revocation has occurred. Must be shorter than the CRL expiry. Defaults to 15m.`,
 								Default:     "15m",
 							},
+							"partitions": {
+								Type:        framework.TypeInt,
+								Description: `The number of partitions to shard each issuer's complete CRL into, in addition to the complete CRL. Certificates are assigned to partitions by expiry and point to theirs through CRL distribution points containing the {{crl_partition}} template. Defaults to 0, disabling partitioning.`,
+							},
+							"ocsp_cache_ttl": {
+								Type:        framework.TypeString,
+								Description: `The amount of time a signed OCSP response is cached and served again to identical requests, without looking up the certificate. Requests containing a nonce are never cached. Must be shorter than ocsp_expiry. Defaults to 0s, disabling the cache.`,
+								Default:     "0s",
+							},
+							"ocsp_unknown_status": {
+								Type:        framework.TypeString,
+								Description: `The status returned by OCSP for serial numbers of the matching issuer which this mount has no record of issuing: good, unknown or revoked. Defaults to good.`,
+								Default:     ocspUnknownStatusGood,
+							},
 						},
 					}},
 				},
@@ -282,6 +331,37 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		config.DeltaRebuildInterval = deltaRebuildInterval
 	}
 
+	oldPartitions := config.Partitions
+	if partitionsRaw, ok := d.GetOk("partitions"); ok {
+		partitions := partitionsRaw.(int)
+		if partitions < 0 || partitions > maxCRLPartitions {
+			return logical.ErrorResponse(fmt.Sprintf("partitions must be between 0 and %d, got: %d", maxCRLPartitions, partitions)), nil
+		}
+		config.Partitions = partitions
+	}
+
+	if cacheTTLRaw, ok := d.GetOk("ocsp_cache_ttl"); ok {
+		cacheTTL := cacheTTLRaw.(string)
+		duration, err := parseutil.ParseDurationSecond(cacheTTL)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("given ocsp_cache_ttl could not be decoded: %s", err)), nil
+		}
+		if duration < 0 {
+			return logical.ErrorResponse(fmt.Sprintf("ocsp_cache_ttl must be greater than or equal to 0 got: %s", duration)), nil
+		}
+		config.OcspCacheTTL = cacheTTL
+	}
+
+	if unknownStatusRaw, ok := d.GetOk("ocsp_unknown_status"); ok {
+		unknownStatus := unknownStatusRaw.(string)
+		switch unknownStatus {
+		case ocspUnknownStatusGood, ocspUnknownStatusUnknown, ocspUnknownStatusRevoked:
+		default:
+			return logical.ErrorResponse(fmt.Sprintf("ocsp_unknown_status must be one of %q, %q or %q, got: %q", ocspUnknownStatusGood, ocspUnknownStatusUnknown, ocspUnknownStatusRevoked, unknownStatus)), nil
+		}
+		config.OcspUnknownStatus = unknownStatus
+	}
+
 	expiry, _ := parseutil.ParseDurationSecond(config.Expiry)
 	if config.AutoRebuild {
 		gracePeriod, _ := parseutil.ParseDurationSecond(config.AutoRebuildGracePeriod)
@@ -297,6 +377,12 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		}
 	}
 
+	ocspExpiry, _ := parseutil.ParseDurationSecond(config.OcspExpiry)
+	ocspCacheTTL, _ := parseutil.ParseDurationSecond(config.OcspCacheTTL)
+	if ocspExpiry > 0 && ocspCacheTTL >= ocspExpiry {
+		return logical.ErrorResponse(fmt.Sprintf("OCSP cache TTL (%v) must be strictly shorter than OCSP expiry (%v)", config.OcspCacheTTL, config.OcspExpiry)), nil
+	}
+
 	if !config.AutoRebuild {
 		if config.EnableDelta {
 			return logical.ErrorResponse("Delta CRLs cannot be enabled when auto rebuilding is disabled as the complete CRL is always regenerated!"), nil
@@ -320,10 +406,11 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 	// Note this only affects/happens on the main cluster node, if you need to
 	// notify something based on a configuration change on all server types
 	// have a look at crlBuilder::reloadConfigIfRequired
-	if oldDisable != config.Disable || (oldAutoRebuild && !config.AutoRebuild) || (oldEnableDelta != config.EnableDelta) {
+	if oldDisable != config.Disable || (oldAutoRebuild && !config.AutoRebuild) || (oldEnableDelta != config.EnableDelta) || (oldPartitions != config.Partitions) {
 		// It wasn't disabled but now it is (or equivalently, we were set to
 		// auto-rebuild and we aren't now or equivalently, we changed our
-		// mind about delta CRLs and need a new complete one), rotate the CRLs.
+		// mind about delta CRLs and need a new complete one, or the CRLs
+		// need to be repartitioned), rotate the CRLs.
 		warnings, crlErr := b.crlBuilder.rebuild(sc, true)
 		if crlErr != nil {
 			switch crlErr.(type) {
@@ -352,6 +439,9 @@ func genResponseFromCrlConfig(config *crlConfig) *logical.Response {
 			"auto_rebuild_grace_period": config.AutoRebuildGracePeriod,
 			"enable_delta":              config.EnableDelta,
 			"delta_rebuild_interval":    config.DeltaRebuildInterval,
+			"partitions":                config.Partitions,
+			"ocsp_cache_ttl":            config.OcspCacheTTL,
+			"ocsp_unknown_status":       config.OcspUnknownStatus,
 		},
 	}
 }
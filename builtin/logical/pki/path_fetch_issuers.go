@@ -228,6 +228,20 @@ and using '{{cluster_aia_path}}' requires /config/cluster's 'aia_path' member
 to be set on all PR secondary clusters.`,
 		Default: false,
 	}
+	fields["ct_log_urls"] = &framework.FieldSchema{
+		Type: framework.TypeCommaStringSlice,
+		Description: `Comma-separated list of Certificate Transparency log
+URLs. When set, the precertificate of each leaf certificate issued by this
+issuer is submitted to every log and the returned SCTs are embedded in the
+certificate. See also RFC 6962.`,
+	}
+	fields["ct_submission_mode"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `How to handle CT logs which fail to return an SCT:
+"require" to fail issuance, or "best-effort" to issue the certificate with
+the SCTs of the remaining logs and return a warning.`,
+		Default: string(ctSubmissionRequire),
+	}
 
 	updateIssuerSchema := map[int][]framework.Response{
 		http.StatusOK: {{
@@ -316,6 +330,16 @@ to be set on all PR secondary clusters.`,
 					Description: `Whether or not templating is enabled for AIA fields`,
 					Required:    false,
 				},
+				"ct_log_urls": {
+					Type:        framework.TypeStringSlice,
+					Description: `CT Log URLs`,
+					Required:    false,
+				},
+				"ct_submission_mode": {
+					Type:        framework.TypeString,
+					Description: `CT Submission Mode`,
+					Required:    false,
+				},
 			},
 		}},
 	}
@@ -478,6 +502,15 @@ func respondReadIssuer(issuer *issuerEntry) (*logical.Response, error) {
 		"crl_distribution_points":        []string{},
 		"delta_crl_distribution_points":  []string{},
 		"ocsp_servers":                   []string{},
+		"ct_log_urls":                    []string{},
+		"ct_submission_mode":             string(ctSubmissionRequire),
+	}
+
+	if len(issuer.CTLogURLs) > 0 {
+		data["ct_log_urls"] = issuer.CTLogURLs
+	}
+	if issuer.CTSubmissionMode != "" {
+		data["ct_submission_mode"] = string(issuer.CTSubmissionMode)
 	}
 
 	if issuer.Revoked {
@@ -605,6 +638,16 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse(fmt.Sprintf("invalid URL found in Authority Information Access (AIA) parameter ocsp_servers: %s", badURL)), nil
 	}
 
+	// Certificate Transparency changes
+	ctLogURLs := data.Get("ct_log_urls").([]string)
+	if badURL := validateURLs(ctLogURLs); badURL != "" {
+		return logical.ErrorResponse(fmt.Sprintf("invalid URL found in parameter ct_log_urls: %s", badURL)), nil
+	}
+	ctMode, err := parseCTSubmissionMode(data.Get("ct_submission_mode").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	modified := false
 
 	var oldName string
@@ -648,6 +691,15 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		modified = true
 	}
 
+	if isStringArrayDifferent(ctLogURLs, issuer.CTLogURLs) {
+		issuer.CTLogURLs = ctLogURLs
+		modified = true
+	}
+	if ctMode != issuer.CTSubmissionMode {
+		issuer.CTSubmissionMode = ctMode
+		modified = true
+	}
+
 	if issuer.AIAURIs == nil && (len(issuerCertificates) > 0 || len(crlDistributionPoints) > 0 || len(ocspServers) > 0) {
 		issuer.AIAURIs = &aiaConfigEntry{}
 	}
@@ -893,6 +945,28 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
+	// Certificate Transparency changes
+	if rawCTLogURLs, ok := data.GetOk("ct_log_urls"); ok {
+		ctLogURLs := rawCTLogURLs.([]string)
+		if badURL := validateURLs(ctLogURLs); badURL != "" {
+			return logical.ErrorResponse(fmt.Sprintf("invalid URL found in parameter ct_log_urls: %s", badURL)), nil
+		}
+		if isStringArrayDifferent(ctLogURLs, issuer.CTLogURLs) {
+			issuer.CTLogURLs = ctLogURLs
+			modified = true
+		}
+	}
+	if rawCTMode, ok := data.GetOk("ct_submission_mode"); ok {
+		ctMode, err := parseCTSubmissionMode(rawCTMode.(string))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		if ctMode != issuer.CTSubmissionMode {
+			issuer.CTSubmissionMode = ctMode
+			modified = true
+		}
+	}
+
 	// AIA access changes.
 	if issuer.AIAURIs == nil {
 		issuer.AIAURIs = &aiaConfigEntry{}
@@ -1211,6 +1285,22 @@ func pathGetIssuerCRL(b *backend) *framework.Path {
 	return buildPathGetIssuerCRL(b, pattern, displayAttrs)
 }
 
+func pathGetIssuerCRLPartition(b *backend) *framework.Path {
+	pattern := "issuer/" + framework.GenericNameRegex(issuerRefParam) + "/crl/partition/" + `(?P<partition>\d+)(/pem|/der)?`
+
+	displayAttrs := &framework.DisplayAttributes{
+		OperationPrefix: operationPrefixPKIIssuer,
+		OperationSuffix: "crl-partition|crl-partition-pem|crl-partition-der",
+	}
+
+	path := buildPathGetIssuerCRL(b, pattern, displayAttrs)
+	path.Fields["partition"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: `Index of the CRL partition to fetch.`,
+	}
+	return path
+}
+
 func buildPathGetIssuerCRL(b *backend, pattern string, displayAttrs *framework.DisplayAttributes) *framework.Path {
 	fields := map[string]*framework.FieldSchema{}
 	fields = addIssuerRefNameFields(fields)
@@ -1296,6 +1386,8 @@ func (b *backend) pathGetIssuerCRL(ctx context.Context, req *logical.Request, da
 
 	if strings.Contains(req.Path, "delta") {
 		crlPath += deltaCRLPathSuffix
+	} else if _, ok := data.Schema["partition"]; ok {
+		crlPath = crlPartitionPath(crlPath, data.Get("partition").(int))
 	}
 
 	crlEntry, err := req.Storage.Get(ctx, crlPath)
@@ -1365,5 +1457,8 @@ or its assigned name value.
  - /issuer/:ref/crl is JSON encoded and contains a PEM CRL,
  - /issuer/:ref/crl/pem contains the PEM-encoded CRL,
  - /issuer/:ref/crl/DER contains the raw DER-encoded (binary) CRL.
+
+When CRL partitioning is enabled, /issuer/:ref/crl/partition/:partition
+returns a single partition of the complete CRL in the same formats.
 `
 )
@@ -421,7 +421,7 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 
 	var caErr error
 	sc := b.makeStorageContext(ctx, req.Storage)
-	signingBundle, caErr := sc.fetchCAInfo(issuerName, IssuanceUsage)
+	signingBundle, issuerId, caErr := sc.fetchCAInfoWithIssuer(issuerName, IssuanceUsage)
 	if caErr != nil {
 		switch caErr.(type) {
 		case errutil.UserError:
@@ -433,13 +433,18 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	ctSubmitter, err := sc.newCTSubmitter(issuerId, signingBundle)
+	if err != nil {
+		return nil, err
+	}
+
 	input := &inputBundle{
-		req:     req,
-		apiData: data,
-		role:    role,
+		req:         req,
+		apiData:     data,
+		role:        role,
+		ctSubmitter: ctSubmitter,
 	}
 	var parsedBundle *certutil.ParsedCertBundle
-	var err error
 	var warnings []string
 	if useCSR {
 		parsedBundle, warnings, err = signCert(b, input, signingBundle, false, useCSRValues)
@@ -456,6 +461,9 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 			return nil, fmt.Errorf("error signing/generating certificate: %w", err)
 		}
 	}
+	if ctSubmitter != nil {
+		warnings = append(warnings, ctSubmitter.warnings...)
+	}
 
 	signingCB, err := signingBundle.ToCertBundle()
 	if err != nil {
@@ -602,6 +602,16 @@ func pathRevokeIssuer(b *backend) *framework.Path {
 								Description: `Specifies the URL values for the OCSP Servers field`,
 								Required:    true,
 							},
+							"ct_log_urls": {
+								Type:        framework.TypeStringSlice,
+								Description: `Specifies the Certificate Transparency logs leaf certificates are submitted to`,
+								Required:    true,
+							},
+							"ct_submission_mode": {
+								Type:        framework.TypeString,
+								Description: `Specifies how CT log failures are handled`,
+								Required:    true,
+							},
 							"revocation_time": {
 								Type:        framework.TypeInt64,
 								Description: `Time of revocation`,
@@ -732,6 +742,7 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 			if err != nil {
 				return nil, fmt.Errorf("error saving revoked issuer to new location: %w", err)
 			}
+			b.ocspCache.purge()
 		}
 	}
 
@@ -32,11 +32,22 @@ const (
 	maximumRequestSize      = 2048 // A normal simple request is 87 bytes, so give us some buffer
 )
 
+const (
+	ocspUnknownStatusGood    = "good"
+	ocspUnknownStatusUnknown = "unknown"
+	ocspUnknownStatusRevoked = "revoked"
+)
+
 type ocspRespInfo struct {
 	serialNumber      *big.Int
 	ocspStatus        int
 	revocationTimeUTC *time.Time
+	revocationReason  int
 	issuerID          issuerID
+
+	// Whether the serial number was never issued by this mount and is
+	// reported as revoked, per RFC 6960 section 2.2.
+	notIssued bool
 }
 
 // These response variables should not be mutated, instead treat them as constants
@@ -66,6 +77,10 @@ var (
 	ErrMissingOcspUsage = errors.New("issuer entry did not have the OCSPSigning usage")
 	ErrIssuerHasNoKey   = errors.New("issuer has no key")
 	ErrUnknownIssuer    = errors.New("unknown issuer")
+
+	// id-pkix-ocsp-extended-revoke, signaling that the responder reports
+	// serials it never issued as revoked.
+	oidOcspExtendedRevoke = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 9}
 )
 
 func buildPathOcspGet(b *backend) *framework.Path {
@@ -145,7 +160,27 @@ func (b *backend) ocspHandler(ctx context.Context, request *logical.Request, dat
 		return OcspMalformedResponse, nil
 	}
 
-	ocspStatus, err := getOcspStatus(sc, ocspReq)
+	nonce, err := getOcspNonce(derReq)
+	if err != nil {
+		return OcspMalformedResponse, nil
+	}
+
+	// Responses to requests with a nonce are unique to them, so they are
+	// neither served from nor added to the cache.
+	cacheTTL, err := parseutil.ParseDurationSecond(cfg.OcspCacheTTL)
+	if err != nil {
+		return logAndReturnInternalError(b, err), nil
+	}
+	useCache := cacheTTL > 0 && nonce == nil
+	cacheKey := ocspCacheKey(ocspReq)
+	cacheGeneration := b.ocspCache.generation()
+	if useCache {
+		if byteResp, ok := b.ocspCache.get(cacheKey); ok {
+			return ocspSuccessResponse(byteResp), nil
+		}
+	}
+
+	ocspStatus, err := getOcspStatus(cfg, sc, ocspReq)
 	if err != nil {
 		return logAndReturnInternalError(b, err), nil
 	}
@@ -156,7 +191,7 @@ func (b *backend) ocspHandler(ctx context.Context, request *logical.Request, dat
 			// Since we were not able to find a matching issuer for the incoming request
 			// generate an Unknown OCSP response. This might turn into an Unauthorized if
 			// we find out that we don't have a default issuer or it's missing the proper Usage flags
-			return generateUnknownResponse(cfg, sc, ocspReq), nil
+			return generateUnknownResponse(cfg, sc, ocspReq, nonce), nil
 		}
 		if errors.Is(err, ErrMissingOcspUsage) {
 			// If we did find a matching issuer but aren't allowed to sign, the spec says
@@ -168,21 +203,34 @@ func (b *backend) ocspHandler(ctx context.Context, request *logical.Request, dat
 		return logAndReturnInternalError(b, err), nil
 	}
 
-	byteResp, err := genResponse(cfg, caBundle, ocspStatus, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	responder, err := sc.fetchOcspResponderBundle(issuer.ID)
 	if err != nil {
 		return logAndReturnInternalError(b, err), nil
 	}
 
+	byteResp, err := genResponse(cfg, caBundle, responder, ocspStatus, ocspReq.HashAlgorithm, issuer.RevocationSigAlg, nonce)
+	if err != nil {
+		return logAndReturnInternalError(b, err), nil
+	}
+
+	if useCache {
+		b.ocspCache.put(cacheKey, byteResp, cacheTTL, cacheGeneration)
+	}
+
+	return ocspSuccessResponse(byteResp), nil
+}
+
+func ocspSuccessResponse(byteResp []byte) *logical.Response {
 	return &logical.Response{
 		Data: map[string]interface{}{
 			logical.HTTPContentType: ocspResponseContentType,
 			logical.HTTPStatusCode:  http.StatusOK,
 			logical.HTTPRawBody:     byteResp,
 		},
-	}, nil
+	}
 }
 
-func generateUnknownResponse(cfg *crlConfig, sc *storageContext, ocspReq *ocsp.Request) *logical.Response {
+func generateUnknownResponse(cfg *crlConfig, sc *storageContext, ocspReq *ocsp.Request, nonce *pkix.Extension) *logical.Response {
 	// Generate an Unknown OCSP response, signing with the default issuer from the mount as we did
 	// not match the request's issuer. If no default issuer can be used, return with Unauthorized as there
 	// isn't much else we can do at this point.
@@ -216,18 +264,14 @@ func generateUnknownResponse(cfg *crlConfig, sc *storageContext, ocspReq *ocsp.R
 		ocspStatus:   ocsp.Unknown,
 	}
 
-	byteResp, err := genResponse(cfg, caBundle, info, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	// The request does not concern any issuer of this mount, so the
+	// default issuer signs it itself rather than any delegated responder.
+	byteResp, err := genResponse(cfg, caBundle, nil, info, ocspReq.HashAlgorithm, issuer.RevocationSigAlg, nonce)
 	if err != nil {
 		return logAndReturnInternalError(sc.Backend, err)
 	}
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			logical.HTTPContentType: ocspResponseContentType,
-			logical.HTTPStatusCode:  http.StatusOK,
-			logical.HTTPRawBody:     byteResp,
-		},
-	}
+	return ocspSuccessResponse(byteResp)
 }
 
 func fetchDerEncodedRequest(request *logical.Request, data *framework.FieldData) ([]byte, error) {
@@ -280,7 +324,7 @@ func logAndReturnInternalError(b *backend, err error) *logical.Response {
 	return OcspInternalErrorResponse
 }
 
-func getOcspStatus(sc *storageContext, ocspReq *ocsp.Request) (*ocspRespInfo, error) {
+func getOcspStatus(cfg *crlConfig, sc *storageContext, ocspReq *ocsp.Request) (*ocspRespInfo, error) {
 	revEntryRaw, err := fetchCertBySerialBigInt(sc, revokedPath, ocspReq.SerialNumber)
 	if err != nil {
 		return nil, err
@@ -300,6 +344,36 @@ func getOcspStatus(sc *storageContext, ocspReq *ocsp.Request) (*ocspRespInfo, er
 		info.ocspStatus = ocsp.Revoked
 		info.revocationTimeUTC = &revEntry.RevocationTimeUTC
 		info.issuerID = revEntry.CertificateIssuer // This might be empty if the CRL hasn't been rebuilt
+		return &info, nil
+	}
+
+	if cfg.OcspUnknownStatus == ocspUnknownStatusGood {
+		// Historically, any serial which was not revoked was reported as
+		// good, so there is no need to look up whether it was issued.
+		return &info, nil
+	}
+
+	// Note that certificates issued by roles with no_store set are not
+	// recorded, and so are reported with this status too.
+	certEntry, err := fetchCertBySerialBigInt(sc, "certs/", ocspReq.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if certEntry != nil {
+		return &info, nil
+	}
+
+	switch cfg.OcspUnknownStatus {
+	case ocspUnknownStatusUnknown:
+		info.ocspStatus = ocsp.Unknown
+	case ocspUnknownStatusRevoked:
+		// RFC 6960 section 2.2 has responders report serials they never
+		// issued as revoked on hold since the epoch.
+		epoch := time.Unix(0, 0).UTC()
+		info.ocspStatus = ocsp.Revoked
+		info.revocationTimeUTC = &epoch
+		info.revocationReason = ocsp.CertificateHold
+		info.notIssued = true
 	}
 
 	return &info, nil
@@ -420,7 +494,7 @@ func doesRequestMatchIssuer(parsedBundle *certutil.ParsedCertBundle, req *ocsp.R
 	return bytes.Equal(req.IssuerKeyHash, issuerKeyHash) && bytes.Equal(req.IssuerNameHash, issuerNameHash), nil
 }
 
-func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, info *ocspRespInfo, reqHash crypto.Hash, revSigAlg x509.SignatureAlgorithm) ([]byte, error) {
+func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, responder *certutil.ParsedCertBundle, info *ocspRespInfo, reqHash crypto.Hash, revSigAlg x509.SignatureAlgorithm, nonce *pkix.Extension) ([]byte, error) {
 	curTime := time.Now()
 	duration, err := parseutil.ParseDurationSecond(cfg.OcspExpiry)
 	if err != nil {
@@ -467,10 +541,39 @@ func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, info *ocsp
 
 	if info.ocspStatus == ocsp.Revoked {
 		template.RevokedAt = *info.revocationTimeUTC
-		template.RevocationReason = ocsp.Unspecified
+		template.RevocationReason = info.revocationReason
+	}
+
+	// A delegated responder signs in place of the issuer, and has to be
+	// included in the response for clients to verify it. The issuer's
+	// preferred signature algorithm may not suit the responder's key, so
+	// the default for the key is used instead.
+	responderCert := caBundle.Certificate
+	signer := caBundle.PrivateKey
+	if responder != nil {
+		responderCert = responder.Certificate
+		signer = responder.PrivateKey
+		template.Certificate = responder.Certificate
+		template.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+	}
+
+	byteResp, err := ocsp.CreateResponse(caBundle.Certificate, responderCert, template, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	var exts []pkix.Extension
+	if info.notIssued {
+		exts = append(exts, pkix.Extension{Id: oidOcspExtendedRevoke, Value: asn1.NullBytes})
+	}
+	if nonce != nil {
+		exts = append(exts, *nonce)
+	}
+	if len(exts) == 0 {
+		return byteResp, nil
 	}
 
-	return ocsp.CreateResponse(caBundle.Certificate, caBundle.Certificate, template, caBundle.PrivateKey)
+	return addOcspResponseExtensions(byteResp, exts, signer)
 }
 
 const pathOcspHelpSyn = `
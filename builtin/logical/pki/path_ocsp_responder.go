@@ -0,0 +1,383 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package pki
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/certutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const ocspResponderPrefix = "config/ocsp-responder/"
+
+// id-pkix-ocsp-nocheck, telling clients not to check the revocation status
+// of the responder certificate itself, per RFC 6960 section 4.2.2.2.1.
+var oidOcspNoCheck = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+
+// ocspResponderEntry is a delegated OCSP responder of an issuer, signing
+// OCSP responses about its certificates in its place.
+type ocspResponderEntry struct {
+	IssuerID    issuerID `json:"issuer_id"`
+	Certificate string   `json:"certificate"`
+	PrivateKey  string   `json:"private_key"`
+}
+
+func pathOcspResponder(b *backend) *framework.Path {
+	fields := addIssuerRefField(map[string]*framework.FieldSchema{})
+	fields["key_type"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Default:     "ec",
+		Description: `The type of key to generate for the responder; either "rsa" or "ec". Defaults to "ec".`,
+		AllowedValues: []interface{}{
+			"rsa",
+			"ec",
+		},
+	}
+	fields["key_bits"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Default:     0,
+		Description: `The number of bits to use for the responder key; defaults to 2048 for RSA and 256 for EC keys.`,
+	}
+	fields["ttl"] = &framework.FieldSchema{
+		Type:        framework.TypeDurationSecond,
+		Default:     "720h",
+		Description: `The lifetime of the responder certificate, capped at the expiry of the issuer. Defaults to 720h.`,
+	}
+
+	responseFields := map[string]*framework.FieldSchema{
+		"issuer_id": {
+			Type:        framework.TypeString,
+			Description: `ID of the issuer`,
+			Required:    true,
+		},
+		"certificate": {
+			Type:        framework.TypeString,
+			Description: `The responder certificate`,
+			Required:    true,
+		},
+		"serial_number": {
+			Type:        framework.TypeString,
+			Description: `The serial number of the responder certificate`,
+			Required:    true,
+		},
+		"expiration": {
+			Type:        framework.TypeInt64,
+			Description: `The expiration of the responder certificate`,
+			Required:    true,
+		},
+	}
+
+	return &framework.Path{
+		Pattern: "issuer/" + framework.GenericNameRegex(issuerRefParam) + "/ocsp-responder",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKIIssuer,
+			OperationSuffix: "ocsp-responder",
+		},
+
+		Fields: fields,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathReadOcspResponder,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "read",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      responseFields,
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathWriteOcspResponder,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "generate",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      responseFields,
+					}},
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathDeleteOcspResponder,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "delete",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathOcspResponderHelpSyn,
+		HelpDescription: pathOcspResponderHelpDesc,
+	}
+}
+
+func (b *backend) pathReadOcspResponder(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot read OCSP responders until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	id, err := sc.resolveIssuerReference(getIssuerRef(data))
+	if err != nil {
+		return nil, err
+	}
+
+	responder, err := sc.fetchOcspResponder(id)
+	if err != nil {
+		return nil, err
+	}
+	if responder == nil {
+		return nil, nil
+	}
+
+	return respondReadOcspResponder(responder)
+}
+
+func (b *backend) pathWriteOcspResponder(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Hold the issuers lock so the issuer cannot be removed while its
+	// responder is generated.
+	b.issuersLock.RLock()
+	defer b.issuersLock.RUnlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot generate OCSP responders until migration has completed"), nil
+	}
+
+	keyType := data.Get("key_type").(string)
+	keyBits := data.Get("key_bits").(int)
+	ttl := time.Duration(data.Get("ttl").(int)) * time.Second
+	if keyType != "rsa" && keyType != "ec" {
+		return logical.ErrorResponse(fmt.Sprintf("unsupported key_type for OCSP responders: %q", keyType)), nil
+	}
+	if ttl <= 0 {
+		return logical.ErrorResponse("ttl must be greater than 0"), nil
+	}
+	keyBits, err := certutil.DefaultOrValueKeyBits(keyType, keyBits)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if err := certutil.ValidateKeyTypeLength(keyType, keyBits); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	id, err := sc.resolveIssuerReference(getIssuerRef(data))
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, bundle, err := sc.fetchCertBundleByIssuerId(id, true)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.KeyID == "" {
+		return logical.ErrorResponse("cannot generate an OCSP responder for an issuer without a key"), nil
+	}
+	if err := issuer.EnsureUsage(OCSPSigningUsage); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("cannot generate an OCSP responder for this issuer: %v", err)), nil
+	}
+
+	caBundle, err := bundle.ToParsedCertBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	responder, err := generateOcspResponder(caBundle, keyType, keyBits, ttl)
+	if err != nil {
+		return nil, err
+	}
+	responder.IssuerID = issuer.ID
+
+	if err := sc.writeOcspResponder(responder); err != nil {
+		return nil, err
+	}
+
+	return respondReadOcspResponder(responder)
+}
+
+func (b *backend) pathDeleteOcspResponder(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot delete OCSP responders until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	id, err := sc.resolveIssuerReference(getIssuerRef(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, sc.deleteOcspResponder(id)
+}
+
+func respondReadOcspResponder(responder *ocspResponderEntry) (*logical.Response, error) {
+	cert, err := parseCertificateFromBytes([]byte(responder.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse OCSP responder certificate: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"issuer_id":     responder.IssuerID,
+			"certificate":   responder.Certificate,
+			"serial_number": serialFromCert(cert),
+			"expiration":    cert.NotAfter.Unix(),
+		},
+	}, nil
+}
+
+// generateOcspResponder creates a key pair and a certificate signed by the
+// issuer for it to sign OCSP responses in its place.
+func generateOcspResponder(caBundle *certutil.ParsedCertBundle, keyType string, keyBits int, ttl time.Duration) (*ocspResponderEntry, error) {
+	keyBundle, err := certutil.CreateKeyBundle(keyType, keyBits, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate OCSP responder key: %w", err)
+	}
+
+	serialNumber, err := certutil.GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// Like issued certificates, the responder is backdated to allow for
+	// clock skew and does not outlive its issuer.
+	now := time.Now()
+	notAfter := now.Add(ttl)
+	if notAfter.After(caBundle.Certificate.NotAfter) {
+		notAfter = caBundle.Certificate.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: fmt.Sprintf("OCSP Responder for %s", caBundle.Certificate.Subject.CommonName),
+		},
+		NotBefore:   now.Add(-30 * time.Second),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidOcspNoCheck, Value: asn1.NullBytes},
+		},
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, caBundle.Certificate, keyBundle.PrivateKey.Public(), caBundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign OCSP responder certificate: %w", err)
+	}
+
+	privateKey, err := keyBundle.ToPrivateKeyPemString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ocspResponderEntry{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})),
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+func (sc *storageContext) fetchOcspResponder(id issuerID) (*ocspResponderEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, ocspResponderPrefix+id.String())
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var responder ocspResponderEntry
+	if err := entry.DecodeJSON(&responder); err != nil {
+		return nil, err
+	}
+
+	return &responder, nil
+}
+
+// fetchOcspResponderBundle returns the delegated OCSP responder of an issuer
+// ready to sign responses, or nil if the issuer has none which is valid.
+func (sc *storageContext) fetchOcspResponderBundle(id issuerID) (*certutil.ParsedCertBundle, error) {
+	responder, err := sc.fetchOcspResponder(id)
+	if err != nil || responder == nil {
+		return nil, err
+	}
+
+	bundle, err := certutil.ParsePEMBundle(responder.Certificate + "\n" + responder.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse OCSP responder of issuer %v: %w", id, err)
+	}
+
+	// An expired responder would make clients reject every response, so the
+	// issuer signs them itself until the responder is renewed.
+	now := time.Now()
+	if now.Before(bundle.Certificate.NotBefore) || now.After(bundle.Certificate.NotAfter) {
+		sc.Backend.Logger().Debug("OCSP responder is not valid, signing with its issuer instead", "issuer_id", id)
+		return nil, nil
+	}
+
+	return bundle, nil
+}
+
+func (sc *storageContext) writeOcspResponder(responder *ocspResponderEntry) error {
+	entry, err := logical.StorageEntryJSON(ocspResponderPrefix+responder.IssuerID.String(), responder)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.Storage.Put(sc.Context, entry); err != nil {
+		return err
+	}
+
+	sc.Backend.ocspCache.purge()
+	return nil
+}
+
+func (sc *storageContext) deleteOcspResponder(id issuerID) error {
+	if err := sc.Storage.Delete(sc.Context, ocspResponderPrefix+id.String()); err != nil {
+		return err
+	}
+
+	sc.Backend.ocspCache.purge()
+	return nil
+}
+
+const pathOcspResponderHelpSyn = `
+Manage the delegated OCSP responder of an issuer.
+`
+
+const pathOcspResponderHelpDesc = `
+This endpoint allows generating, reading and removing a delegated OCSP
+responder for an issuer. When one is present and valid, OCSP responses
+about certificates of the issuer are signed by the responder rather than
+by the issuer itself, and include the responder certificate.
+
+Writing to this endpoint generates a new key pair and a certificate
+signed by the issuer with the OCSP signing extended key usage, replacing
+any existing responder. The private key of the responder is never
+returned.
+`
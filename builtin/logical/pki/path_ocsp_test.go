@@ -8,9 +8,12 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
@@ -740,3 +743,243 @@ func sendOcspPostRequest(b *backend, s logical.Storage, ocspRequest []byte) (*lo
 
 	return resp, err
 }
+
+// TestOcsp_UnknownSerialStatus makes sure serial numbers of a known issuer
+// which the mount never issued are reported with the configured status.
+func TestOcsp_UnknownSerialStatus(t *testing.T) {
+	t.Parallel()
+
+	b, s, testEnv := setupOcspEnv(t, "ec")
+	unknownCert := &x509.Certificate{SerialNumber: big.NewInt(4242)}
+
+	for _, status := range []string{ocspUnknownStatusGood, ocspUnknownStatusUnknown, ocspUnknownStatusRevoked} {
+		resp, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+			"ocsp_unknown_status": status,
+		})
+		requireSuccessNonNilResponse(t, resp, err, "config/crl")
+		require.Equal(t, status, resp.Data["ocsp_unknown_status"])
+
+		// Issued certificates are not affected.
+		ocspResp, _ := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, nil)
+		require.Equal(t, ocsp.Good, ocspResp.Status)
+
+		ocspResp, basicResp := sendAndParseOcspRequest(t, b, s, unknownCert, testEnv.issuer1, nil)
+		require.Equal(t, unknownCert.SerialNumber, ocspResp.SerialNumber)
+		requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer1)
+		switch status {
+		case ocspUnknownStatusGood:
+			require.Equal(t, ocsp.Good, ocspResp.Status)
+			require.Empty(t, basicResp.TBSResponseData.ResponseExtensions)
+		case ocspUnknownStatusUnknown:
+			require.Equal(t, ocsp.Unknown, ocspResp.Status)
+			require.Empty(t, basicResp.TBSResponseData.ResponseExtensions)
+		case ocspUnknownStatusRevoked:
+			require.Equal(t, ocsp.Revoked, ocspResp.Status)
+			require.Equal(t, ocsp.CertificateHold, ocspResp.RevocationReason)
+			require.Equal(t, time.Unix(0, 0).UTC(), ocspResp.RevokedAt)
+			require.Len(t, basicResp.TBSResponseData.ResponseExtensions, 1)
+			require.True(t, basicResp.TBSResponseData.ResponseExtensions[0].Id.Equal(oidOcspExtendedRevoke))
+		}
+	}
+
+	_, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_unknown_status": "maybe",
+	})
+	require.Error(t, err)
+}
+
+// TestOcsp_Nonce makes sure nonces of requests are echoed in signed
+// responses, and that such responses are never cached.
+func TestOcsp_Nonce(t *testing.T) {
+	t.Parallel()
+
+	for _, keyType := range []string{"rsa", "ec"} {
+		t.Run(keyType, func(t *testing.T) {
+			t.Parallel()
+
+			b, s, testEnv := setupOcspEnv(t, keyType)
+			resp, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+				"ocsp_cache_ttl": "1h",
+			})
+			requireSuccessNonNilResponse(t, resp, err, "config/crl")
+
+			for i := 0; i < 2; i++ {
+				nonce := []byte(fmt.Sprintf("0123456789abcdef-nonce-%d", i))
+				ocspResp, basicResp := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, nonce)
+				require.Equal(t, ocsp.Good, ocspResp.Status)
+				requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer1)
+
+				require.Len(t, basicResp.TBSResponseData.ResponseExtensions, 1)
+				ext := basicResp.TBSResponseData.ResponseExtensions[0]
+				require.True(t, ext.Id.Equal(oidOcspNonce))
+				var echoed []byte
+				_, err := asn1.Unmarshal(ext.Value, &echoed)
+				require.NoError(t, err)
+				require.Equal(t, nonce, echoed)
+			}
+			require.Zero(t, b.ocspCache.cache.Len())
+
+			// Nonces longer than allowed are ignored, and the response
+			// cached as if there was none.
+			_, basicResp := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, bytes.Repeat([]byte("a"), maxOcspNonceLength+1))
+			require.Empty(t, basicResp.TBSResponseData.ResponseExtensions)
+			require.Equal(t, 1, b.ocspCache.cache.Len())
+		})
+	}
+}
+
+// TestOcsp_ResponseCache makes sure responses are cached when enabled, and
+// that the cache is purged when the status of a certificate changes.
+func TestOcsp_ResponseCache(t *testing.T) {
+	t.Parallel()
+
+	b, s, testEnv := setupOcspEnv(t, "ec")
+
+	// The cache is disabled by default.
+	ocspReq := generateRequest(t, crypto.SHA256, testEnv.leafCertIssuer1, testEnv.issuer1)
+	_, err := sendOcspPostRequest(b, s, ocspReq)
+	require.NoError(t, err)
+	require.Zero(t, b.ocspCache.cache.Len())
+
+	// The cache must expire before responses do.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_cache_ttl": "12h",
+	})
+	require.Error(t, err)
+
+	resp, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_cache_ttl": "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/crl")
+	require.Equal(t, "1h", resp.Data["ocsp_cache_ttl"])
+
+	resp, err = sendOcspPostRequest(b, s, ocspReq)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp request")
+	firstResp := resp.Data["http_raw_body"].([]byte)
+	require.Equal(t, 1, b.ocspCache.cache.Len())
+
+	resp, err = sendOcspGetRequest(b, s, ocspReq)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp request")
+	require.Equal(t, firstResp, resp.Data["http_raw_body"].([]byte))
+
+	// Requests hashing the issuer differently are cached separately.
+	ocspResp, _ := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, nil)
+	require.Equal(t, ocsp.Good, ocspResp.Status)
+	require.Equal(t, 2, b.ocspCache.cache.Len())
+
+	// Revoking the certificate purges the cache.
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(testEnv.leafCertIssuer1),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+	require.Zero(t, b.ocspCache.cache.Len())
+
+	resp, err = sendOcspPostRequest(b, s, ocspReq)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp request")
+	ocspResp, err = ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), testEnv.issuer1)
+	require.NoError(t, err)
+	require.Equal(t, ocsp.Revoked, ocspResp.Status)
+
+	// So does any change of the configuration.
+	require.Equal(t, 1, b.ocspCache.cache.Len())
+	resp, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_unknown_status": ocspUnknownStatusUnknown,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/crl")
+	require.Zero(t, b.ocspCache.cache.Len())
+}
+
+// TestOcsp_DelegatedResponder makes sure responses are signed by the
+// delegated responder of an issuer when it has one.
+func TestOcsp_DelegatedResponder(t *testing.T) {
+	t.Parallel()
+
+	b, s, testEnv := setupOcspEnv(t, "rsa")
+
+	resp, err := CBRead(b, s, "issuer/"+testEnv.issuerId1.String()+"/ocsp-responder")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	_, err = CBWrite(b, s, "issuer/"+testEnv.issuerId1.String()+"/ocsp-responder", map[string]interface{}{
+		"key_type": "ed25519",
+	})
+	require.Error(t, err)
+
+	resp, err = CBWrite(b, s, "issuer/"+testEnv.issuerId1.String()+"/ocsp-responder", map[string]interface{}{
+		"ttl": "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "ocsp-responder")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/default/ocsp-responder"), logical.UpdateOperation), resp, true)
+	require.NotContains(t, resp.Data, "private_key")
+	responderCert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}, responderCert.ExtKeyUsage)
+	require.NoError(t, responderCert.CheckSignatureFrom(testEnv.issuer1))
+	require.Equal(t, serialFromCert(responderCert), resp.Data["serial_number"])
+
+	resp, err = CBRead(b, s, "issuer/"+testEnv.issuerId1.String()+"/ocsp-responder")
+	requireSuccessNonNilResponse(t, resp, err, "ocsp-responder")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/default/ocsp-responder"), logical.ReadOperation), resp, true)
+	require.Equal(t, serialFromCert(responderCert), resp.Data["serial_number"])
+
+	// Responses about the issuer's certificates are signed by the responder,
+	// which is included in them, even with a nonce.
+	for _, nonce := range [][]byte{nil, []byte("0123456789abcdef")} {
+		ocspResp, _ := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, nonce)
+		require.Equal(t, ocsp.Good, ocspResp.Status)
+		require.NotNil(t, ocspResp.Certificate)
+		require.Equal(t, responderCert.Raw, ocspResp.Certificate.Raw)
+		requireOcspResponseSignedBy(t, ocspResp, responderCert)
+	}
+
+	// Other issuers still sign their own responses.
+	ocspResp, _ := sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer2, testEnv.issuer2, nil)
+	require.Nil(t, ocspResp.Certificate)
+	requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer2)
+
+	resp, err = CBDelete(b, s, "issuer/"+testEnv.issuerId1.String()+"/ocsp-responder")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	ocspResp, _ = sendAndParseOcspRequest(t, b, s, testEnv.leafCertIssuer1, testEnv.issuer1, nil)
+	require.Nil(t, ocspResp.Certificate)
+	requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer1)
+}
+
+// sendAndParseOcspRequest sends an OCSP request about cert, with the nonce
+// if any, and returns the parsed response along with its raw structure.
+func sendAndParseOcspRequest(t *testing.T, b *backend, s logical.Storage, cert, issuer *x509.Certificate, nonce []byte) (*ocsp.Response, *ocspBasicResponse) {
+	t.Helper()
+
+	ocspReq := generateRequest(t, crypto.SHA1, cert, issuer)
+	if nonce != nil {
+		var req ocspRequestASN1
+		_, err := asn1.Unmarshal(ocspReq, &req)
+		require.NoError(t, err)
+
+		nonceValue, err := asn1.Marshal(nonce)
+		require.NoError(t, err)
+		req.TBSRequest.RequestExtensions = append(req.TBSRequest.RequestExtensions, pkix.Extension{
+			Id:    oidOcspNonce,
+			Value: nonceValue,
+		})
+		ocspReq, err = asn1.Marshal(req)
+		require.NoError(t, err)
+	}
+
+	resp, err := sendOcspPostRequest(b, s, ocspReq)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp request")
+	require.Equal(t, http.StatusOK, resp.Data["http_status_code"])
+	respDer := resp.Data["http_raw_body"].([]byte)
+
+	ocspResp, err := ocsp.ParseResponse(respDer, issuer)
+	require.NoError(t, err)
+
+	var rawResp ocspResponseASN1
+	_, err = asn1.Unmarshal(respDer, &rawResp)
+	require.NoError(t, err)
+	var basicResp ocspBasicResponse
+	_, err = asn1.Unmarshal(rawResp.Response.Response, &basicResp)
+	require.NoError(t, err)
+
+	return ocspResp, &basicResp
+}
@@ -1018,6 +1018,9 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 	b.tidyStatusLock.RUnlock()
 
 	if rebuildCRL {
+		// Tidied certificates are no longer reported as revoked by OCSP.
+		b.ocspCache.purge()
+
 		// Expired certificates isn't generally an important
 		// reason to trigger a CRL rebuild for. Check if
 		// automatic CRL rebuilds have been enabled and defer
@@ -165,6 +165,8 @@ type issuerEntry struct {
 	RevocationTime       int64                     `json:"revocation_time"`
 	RevocationTimeUTC    time.Time                 `json:"revocation_time_utc"`
 	AIAURIs              *aiaConfigEntry           `json:"aia_uris,omitempty"`
+	CTLogURLs            []string                  `json:"ct_log_urls,omitempty"`
+	CTSubmissionMode     ctSubmissionMode          `json:"ct_submission_mode,omitempty"`
 	LastModified         time.Time                 `json:"last_modified"`
 	Version              uint                      `json:"version"`
 }
@@ -176,6 +178,10 @@ type internalCRLConfigEntry struct {
 	CRLExpirationMap      map[crlID]time.Time `json:"crl_expiration_map"`
 	LastModified          time.Time           `json:"last_modified"`
 	DeltaLastModified     time.Time           `json:"delta_last_modified"`
+
+	// PartitionMap holds the state of each partition of a CRL, indexed by
+	// partition, when CRL partitioning is enabled.
+	PartitionMap map[crlID][]crlPartitionInfo `json:"partition_map,omitempty"`
 }
 
 type keyConfigEntry struct {
@@ -247,7 +253,13 @@ func (c *aiaConfigEntry) toURLEntries(sc *storageContext, issuer issuerID) (*cer
 				templated[index] = uri
 			}
 
-			if uri := validateURLs(templated); uri != "" {
+			// The CRL partition is only known when issuing a certificate,
+			// so validate distribution points against the first partition.
+			validated := templated
+			if name == "crl_distribution_points" {
+				validated = templateCRLPartition(templated, 0)
+			}
+			if uri := validateURLs(validated); uri != "" {
 				return nil, fmt.Errorf("error validating templated %v; invalid URI: %v", name, uri)
 			}
 
@@ -728,7 +740,14 @@ func (sc *storageContext) writeIssuer(issuer *issuerEntry) error {
 		return err
 	}
 
-	return sc.Storage.Put(sc.Context, json)
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return err
+	}
+
+	// The usage or revocation of the issuer may have changed, which OCSP
+	// responses depend on.
+	sc.Backend.ocspCache.purge()
+	return nil
 }
 
 func (sc *storageContext) deleteIssuer(id issuerID) (bool, error) {
@@ -749,7 +768,11 @@ func (sc *storageContext) deleteIssuer(id issuerID) (bool, error) {
 		}
 	}
 
-	return wasDefault, sc.Storage.Delete(sc.Context, issuerPrefix+id.String())
+	if err := sc.Storage.Delete(sc.Context, issuerPrefix+id.String()); err != nil {
+		return wasDefault, err
+	}
+
+	return wasDefault, sc.deleteOcspResponder(id)
 }
 
 func (sc *storageContext) importIssuer(certValue string, issuerName string) (*issuerEntry, bool, error) {
@@ -941,6 +964,11 @@ func (sc *storageContext) _cleanupInternalCRLMapping(mapping *internalCRLConfigE
 			toRemove[id] = true
 		}
 	}
+	for id := range mapping.PartitionMap {
+		if !presentMap[id] {
+			toRemove[id] = true
+		}
+	}
 
 	// Depending on which path we're writing this config to, we need to
 	// remove CRLs from the relevant folder too.
@@ -961,6 +989,12 @@ func (sc *storageContext) _cleanupInternalCRLMapping(mapping *internalCRLConfigE
 		if err := sc.Storage.Delete(sc.Context, deltaCRLPath); err != nil {
 			return fmt.Errorf("failed to delete unreferenced delta CRL %v: %w", id, err)
 		}
+		for index := range mapping.PartitionMap[id] {
+			if err := sc.Storage.Delete(sc.Context, crlPartitionPath(crlPath, index)); err != nil {
+				return fmt.Errorf("failed to delete unreferenced CRL partition %v of %v: %w", index, id, err)
+			}
+		}
+		delete(mapping.PartitionMap, id)
 	}
 
 	// Lastly, some CRLs could've been partially removed from the map but
@@ -1047,6 +1081,10 @@ func (sc *storageContext) _getInternalCRLConfig(path string) (*internalCRLConfig
 		mapping.CRLExpirationMap = make(map[crlID]time.Time)
 	}
 
+	if len(mapping.PartitionMap) == 0 {
+		mapping.PartitionMap = make(map[crlID][]crlPartitionInfo)
+	}
+
 	return mapping, nil
 }
 
@@ -1371,6 +1409,15 @@ func (sc *storageContext) getRevocationConfig() (*crlConfig, error) {
 		result.Expiry = defaultCrlConfig.Expiry
 	}
 
+	// Configurations written before OCSP response caching and the status of
+	// unknown serials were configurable keep their previous behavior.
+	if result.OcspCacheTTL == "" {
+		result.OcspCacheTTL = defaultCrlConfig.OcspCacheTTL
+	}
+	if result.OcspUnknownStatus == "" {
+		result.OcspUnknownStatus = defaultCrlConfig.OcspUnknownStatus
+	}
+
 	return &result, nil
 }
 
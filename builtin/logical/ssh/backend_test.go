@@ -351,6 +351,132 @@ func TestBackend_AllowedDomainsTemplate(t *testing.T) {
 	)
 }
 
+func TestBackend_DefaultHostsTemplate(t *testing.T) {
+	testDefaultHostsTemplate := "{{ identity.entity.metadata.ssh_host }}.example.com"
+	testAllowedPrincipalsTemplate(
+		t, testDefaultHostsTemplate,
+		"host1.example.com",
+		map[string]string{
+			"ssh_host": "host1",
+		},
+		map[string]interface{}{
+			"key_type":                testCaKeyType,
+			"algorithm_signer":        "rsa-sha2-256",
+			"allow_host_certificates": true,
+			"allow_subdomains":        true,
+			"allowed_domains":         "example.com",
+			"default_hosts":           testDefaultHostsTemplate,
+			"default_hosts_template":  true,
+		},
+		map[string]interface{}{
+			"cert_type":  "host",
+			"public_key": testCAPublicKey,
+		},
+	)
+}
+
+func TestBackend_DefaultHostsTemplateOutsideAllowedDomains(t *testing.T) {
+	cluster, userpassToken := getSshCaTestCluster(t, testUserName)
+	defer cluster.Cleanup()
+	client := cluster.Cores[0].Client
+
+	tokenLookupResponse, err := client.Logical().Write("/auth/token/lookup", map[string]interface{}{
+		"token": userpassToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entityID := tokenLookupResponse.Data["entity_id"].(string)
+
+	_, err = client.Logical().Write("ssh/roles/my-role", map[string]interface{}{
+		"key_type":                testCaKeyType,
+		"algorithm_signer":        "rsa-sha2-256",
+		"allow_host_certificates": true,
+		"allow_subdomains":        true,
+		"allowed_domains":         "example.com",
+		"default_hosts":           "{{identity.entity.metadata.ssh_host}}",
+		"default_hosts_template":  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userClient, err := client.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	userClient.SetToken(userpassToken)
+
+	for _, host := range []string{
+		// Hosts outside of the allowed domains
+		"host1.example.org",
+		"example.com.evil.org",
+		// Metadata listing several hosts
+		"host1.example.com,host1.example.org",
+		// Wildcards outside of the leftmost label
+		"host*.example.com",
+	} {
+		_, err = client.Logical().Write("/identity/entity/id/"+entityID, map[string]interface{}{
+			"metadata": map[string]string{
+				"ssh_host": host,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = userClient.Logical().Write("ssh/sign/my-role", map[string]interface{}{
+			"cert_type":  "host",
+			"public_key": testCAPublicKey,
+		})
+		if err == nil {
+			t.Fatalf("expected signing with default host %q to fail", host)
+		}
+	}
+}
+
+func TestValidateValidPrincipalForHosts(t *testing.T) {
+	role := &sshRole{
+		AllowBareDomains: true,
+		AllowSubdomains:  true,
+	}
+	validate := validateValidPrincipalForHosts(role)
+	allowed := []string{"example.com", "bücher.example"}
+
+	for principal, expected := range map[string]bool{
+		"example.com":                true,
+		"host.example.com":           true,
+		"HOST.Example.COM":           true,
+		"*.example.com":              true,
+		"*.host.example.com":         true,
+		"*":                          false,
+		"*.com":                      false,
+		"host*.example.com":          false,
+		"host.*.example.com":         false,
+		"badexample.com":             false,
+		"example.com.evil.org":       false,
+		"bücher.example":             true,
+		"host.bücher.example":        true,
+		"host.xn--bcher-kva.example": true,
+		"host.bucher.example":        false,
+	} {
+		if actual := validate(allowed, principal); actual != expected {
+			t.Errorf("expected principal %q to be allowed: %v, got: %v", principal, expected, actual)
+		}
+	}
+
+	// Bare wildcards require subdomains to be allowed.
+	role.AllowSubdomains = false
+	if validate(allowed, "*.example.com") {
+		t.Error("expected wildcards to require allow_subdomains")
+	}
+
+	normalized, err := normalizeHostPrincipal("*.Bücher.example")
+	if err != nil || normalized != "*.xn--bcher-kva.example" {
+		t.Errorf("unexpected normalized principal %q: %v", normalized, err)
+	}
+}
+
 func TestBackend_AllowedUsersTemplate(t *testing.T) {
 	testAllowedUsersTemplate(t,
 		"{{ identity.entity.metadata.ssh_username }}",
@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	"github.com/openbao/openbao/sdk/v2/framework"
@@ -25,6 +26,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/strutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/idna"
 )
 
 var containsTemplateRegex = regexp.MustCompile(`{{.+?}}`)
@@ -68,10 +70,23 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 
 	var parsedPrincipals []string
 	if certificateType == ssh.HostCert {
-		parsedPrincipals, err = b.calculateValidPrincipals(data, req, role, "", role.AllowedDomains, role.AllowedDomainsTemplate, validateValidPrincipalForHosts(role))
+		defaultHosts := role.DefaultHosts
+		if role.DefaultHostsTemplate {
+			defaultHosts, err = b.renderHosts(role.DefaultHosts, req)
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+		}
+		parsedPrincipals, err = b.calculateValidPrincipals(data, req, role, defaultHosts, role.AllowedDomains, role.AllowedDomainsTemplate, validateValidPrincipalForHosts(role))
 		if err != nil {
 			return logical.ErrorResponse(err.Error()), nil
 		}
+		for index, principal := range parsedPrincipals {
+			parsedPrincipals[index], err = normalizeHostPrincipal(principal)
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+		}
 	} else {
 		defaultPrincipal := role.DefaultUser
 		if role.DefaultUserTemplate {
@@ -167,6 +182,27 @@ func (b *backend) renderPrincipal(principal string, req *logical.Request) (strin
 	return principal, nil
 }
 
+// renderHosts renders each of the comma-separated hosts separately, so that
+// identity metadata cannot add hosts of its own.
+func (b *backend) renderHosts(hosts string, req *logical.Request) (string, error) {
+	var rendered []string
+	for _, host := range strutil.ParseStringSlice(hosts, ",") {
+		if containsTemplateRegex.MatchString(host) && req.EntityID == "" {
+			return "", fmt.Errorf("template '%s' requires an entity to be rendered", host)
+		}
+
+		renderedHost, err := b.renderPrincipal(host, req)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(renderedHost, ",") {
+			return "", fmt.Errorf("template '%s' rendered to more than one host", host)
+		}
+		rendered = append(rendered, renderedHost)
+	}
+	return strings.Join(rendered, ","), nil
+}
+
 func (b *backend) calculateValidPrincipals(data *framework.FieldData, req *logical.Request, role *sshRole, defaultPrincipal, principalsAllowedByRole string, enableTemplating bool, validatePrincipal func([]string, string) bool) ([]string, error) {
 	validPrincipals := ""
 	validPrincipalsRaw, ok := data.GetOk("valid_principals")
@@ -212,13 +248,52 @@ func (b *backend) calculateValidPrincipals(data *framework.FieldData, req *logic
 	}
 }
 
+// normalizeHostPrincipal converts internationalized hostnames to their
+// punycode form, as used in DNS. Wildcards are only permitted as the
+// leftmost label of the hostname.
+func normalizeHostPrincipal(principal string) (string, error) {
+	host, wildcard := strings.CutPrefix(principal, "*.")
+	if strings.Contains(host, "*") {
+		return "", fmt.Errorf("%v is not a valid value for valid_principals: wildcards are only allowed as the leftmost label", principal)
+	}
+
+	for _, r := range host {
+		if r >= utf8.RuneSelf {
+			ascii, err := idna.Lookup.ToASCII(host)
+			if err != nil {
+				return "", fmt.Errorf("%v is not a valid value for valid_principals: %w", principal, err)
+			}
+			host = ascii
+			break
+		}
+	}
+
+	if wildcard {
+		return "*." + host, nil
+	}
+	return host, nil
+}
+
 func validateValidPrincipalForHosts(role *sshRole) func([]string, string) bool {
 	return func(allowedPrincipals []string, validPrincipal string) bool {
+		principal, err := normalizeHostPrincipal(validPrincipal)
+		if err != nil {
+			return false
+		}
+
+		// A wildcard only matches subdomains of the remaining hostname.
+		host, wildcard := strings.CutPrefix(strings.ToLower(principal), "*.")
 		for _, allowedPrincipal := range allowedPrincipals {
-			if allowedPrincipal == validPrincipal && role.AllowBareDomains {
+			allowed, err := normalizeHostPrincipal(allowedPrincipal)
+			if err != nil {
+				continue
+			}
+			allowed = strings.ToLower(allowed)
+
+			if allowed == host && !wildcard && role.AllowBareDomains {
 				return true
 			}
-			if role.AllowSubdomains && strings.HasSuffix(validPrincipal, "."+allowedPrincipal) {
+			if role.AllowSubdomains && (strings.HasSuffix(host, "."+allowed) || (allowed == host && wildcard)) {
 				return true
 			}
 		}
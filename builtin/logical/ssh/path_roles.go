@@ -48,6 +48,8 @@ type sshRole struct {
 	AllowedUsersTemplate       bool              `mapstructure:"allowed_users_template" json:"allowed_users_template"`
 	AllowedDomains             string            `mapstructure:"allowed_domains" json:"allowed_domains"`
 	AllowedDomainsTemplate     bool              `mapstructure:"allowed_domains_template" json:"allowed_domains_template"`
+	DefaultHosts               string            `mapstructure:"default_hosts" json:"default_hosts"`
+	DefaultHostsTemplate       bool              `mapstructure:"default_hosts_template" json:"default_hosts_template"`
 	MaxTTL                     string            `mapstructure:"max_ttl" json:"max_ttl"`
 	TTL                        string            `mapstructure:"ttl" json:"ttl"`
 	DefaultCriticalOptions     map[string]string `mapstructure:"default_critical_options" json:"default_critical_options"`
@@ -219,6 +221,23 @@ func pathRoles(b *backend) *framework.Path {
 				`,
 				Default: false,
 			},
+			"default_hosts": {
+				Type: framework.TypeString,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				Comma-separated list of hostnames used as the principals of host
+				certificates when 'valid_principals' is not specified. Like any
+				requested principal, each must be permitted by 'allowed_domains'.`,
+			},
+			"default_hosts_template": {
+				Type: framework.TypeBool,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				If set, Default hosts can be specified using identity template policies.
+				Non-templated hosts are also permitted.
+				`,
+				Default: false,
+			},
 			"ttl": {
 				Type: framework.TypeDurationSecond,
 				Description: `
@@ -499,6 +518,8 @@ func (b *backend) createCARole(allowedUsers, defaultUser, signer string, data *f
 		AllowedUsersTemplate:      data.Get("allowed_users_template").(bool),
 		AllowedDomains:            data.Get("allowed_domains").(string),
 		AllowedDomainsTemplate:    data.Get("allowed_domains_template").(bool),
+		DefaultHosts:              data.Get("default_hosts").(string),
+		DefaultHostsTemplate:      data.Get("default_hosts_template").(bool),
 		DefaultUser:               defaultUser,
 		DefaultUserTemplate:       data.Get("default_user_template").(bool),
 		AllowBareDomains:          data.Get("allow_bare_domains").(bool),
@@ -683,6 +704,8 @@ func (b *backend) parseRole(role *sshRole) (map[string]interface{}, error) {
 			"allowed_users_template":      role.AllowedUsersTemplate,
 			"allowed_domains":             role.AllowedDomains,
 			"allowed_domains_template":    role.AllowedDomainsTemplate,
+			"default_hosts":               role.DefaultHosts,
+			"default_hosts_template":      role.DefaultHostsTemplate,
 			"default_user":                role.DefaultUser,
 			"default_user_template":       role.DefaultUserTemplate,
 			"ttl":                         int64(ttl.Seconds()),
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const operationPrefixSTS = "sts"
+
+// Factory creates and configures the backend
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Backend creates a new backend with all the paths and secrets belonging to it
+func Backend() *backend {
+	var b backend
+	b.newClient = newSTSClient
+	b.Backend = &framework.Backend{
+		Help: strings.TrimSpace(backendHelp),
+
+		PathsSpecial: &logical.Paths{
+			SealWrapStorage: []string{
+				"config/root",
+			},
+		},
+
+		Paths: []*framework.Path{
+			pathConfigRoot(&b),
+			pathListRoles(&b),
+			pathRoles(&b),
+			pathCreds(&b),
+		},
+
+		Secrets: []*framework.Secret{
+			secretCreds(&b),
+		},
+
+		Clean:       b.resetClient,
+		Invalidate:  b.invalidate,
+		BackendType: logical.TypeLogical,
+	}
+
+	return &b
+}
+
+type backend struct {
+	*framework.Backend
+
+	client stsiface.STSAPI
+	lock   sync.RWMutex
+
+	// newClient builds the STS client from the root configuration; tests
+	// replace it to avoid talking to AWS.
+	newClient func(*rootConfig) (stsiface.STSAPI, error)
+}
+
+// Client returns the STS client, creating it from the stored root
+// configuration if necessary.
+func (b *backend) Client(ctx context.Context, s logical.Storage) (stsiface.STSAPI, error) {
+	b.lock.RLock()
+
+	// If we already have a client, return it
+	if b.client != nil {
+		b.lock.RUnlock()
+		return b.client, nil
+	}
+
+	b.lock.RUnlock()
+
+	config, err := readRootConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// If the client was created during the lock switch, return it
+	if b.client != nil {
+		return b.client, nil
+	}
+
+	b.client, err = b.newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client, nil
+}
+
+// resetClient forces a new client next time Client() is called.
+func (b *backend) resetClient(_ context.Context) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.client = nil
+}
+
+func (b *backend) invalidate(ctx context.Context, key string) {
+	switch key {
+	case rootConfigPath:
+		b.resetClient(ctx)
+	}
+}
+
+func newSTSClient(config *rootConfig) (stsiface.STSAPI, error) {
+	awsConfig := aws.NewConfig().
+		WithHTTPClient(cleanhttp.DefaultPooledClient()).
+		WithMaxRetries(config.MaxRetries)
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+	if config.STSEndpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.STSEndpoint)
+	}
+
+	// Credentials come from the default chain unless given.
+	if config.AccessKey != "" {
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(config.AccessKey, config.SecretKey, ""))
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return sts.New(sess), nil
+}
+
+const backendHelp = `
+The AWS STS backend issues short-lived AWS credentials by assuming an IAM
+role through the AWS Security Token Service.
+
+Each role may carry a session policy template which is rendered against
+the requesting identity, so that the issued credentials are scoped down
+to what that identity needs rather than everything the role allows.
+
+After mounting this backend, configure it using the "config/root" path.
+`
@@ -0,0 +1,245 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+type mockSTS struct {
+	stsiface.STSAPI
+
+	input *sts.AssumeRoleInput
+	err   error
+}
+
+func (m *mockSTS) AssumeRoleWithContext(_ aws.Context, input *sts.AssumeRoleInput, _ ...request.Option) (*sts.AssumeRoleOutput, error) {
+	m.input = input
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sts.AssumeRoleOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("ASIAEXAMPLE"),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("token"),
+			Expiration:      aws.Time(time.Now().Add(time.Duration(aws.Int64Value(input.DurationSeconds)) * time.Second)),
+		},
+	}, nil
+}
+
+func getBackend(t *testing.T, entity *logical.Entity) (*backend, logical.Storage, *mockSTS) {
+	t.Helper()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	sysView := logical.TestSystemView()
+	sysView.EntityVal = entity
+	config.System = sysView
+
+	mock := &mockSTS{}
+	b := Backend()
+	b.newClient = func(*rootConfig) (stsiface.STSAPI, error) {
+		return mock, nil
+	}
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/root",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"access_key": "AKIAEXAMPLE",
+			"secret_key": "secret",
+			"region":     "us-east-1",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+	}
+
+	return b, config.StorageView, mock
+}
+
+func writeRole(t *testing.T, b *backend, s logical.Storage, data map[string]interface{}) *logical.Response {
+	t.Helper()
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "roles/test",
+		Storage:   s,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestBackend_RoleValidation(t *testing.T) {
+	b, s, _ := getBackend(t, nil)
+
+	cases := map[string]map[string]interface{}{
+		"missing role_arn": {},
+		"short ttl": {
+			"role_arn": "arn:aws:iam::123456789012:role/test",
+			"ttl":      "1m",
+		},
+		"invalid json": {
+			"role_arn":       "arn:aws:iam::123456789012:role/test",
+			"session_policy": `{"Statement": [`,
+		},
+		"empty statement": {
+			"role_arn":       "arn:aws:iam::123456789012:role/test",
+			"session_policy": `{"Version": "2012-10-17", "Statement": []}`,
+		},
+		"unbalanced template": {
+			"role_arn":       "arn:aws:iam::123456789012:role/test",
+			"session_policy": `{"Statement": [{"Resource": "{{identity.entity.name"}]}`,
+		},
+		"oversized policy": {
+			"role_arn":       "arn:aws:iam::123456789012:role/test",
+			"session_policy": `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "` + strings.Repeat("a", maxSessionPolicySize) + `"}]}`,
+		},
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := writeRole(t, b, s, data)
+			if resp == nil || !resp.IsError() {
+				t.Fatalf("expected an error response, got: %#v", resp)
+			}
+		})
+	}
+}
+
+func TestBackend_CredsTemplatedSessionPolicy(t *testing.T) {
+	entity := &logical.Entity{
+		ID:   "entity-id",
+		Name: `alice"],"Resource":["*`,
+	}
+	b, s, mock := getBackend(t, entity)
+
+	resp := writeRole(t, b, s, map[string]interface{}{
+		"role_arn": "arn:aws:iam::123456789012:role/test",
+		"session_policy": `{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": "s3:GetObject",
+				"Resource": ["arn:aws:s3:::bucket/{{identity.entity.name}}/*"]
+			}]
+		}`,
+	})
+	if resp != nil && resp.IsError() {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "creds/test",
+		Storage:     s,
+		EntityID:    entity.ID,
+		DisplayName: "token-alice",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+	}
+	if resp.Data["access_key"] != "ASIAEXAMPLE" || resp.Data["session_token"] != "token" {
+		t.Fatalf("unexpected credentials: %#v", resp.Data)
+	}
+	if resp.Secret.Renewable {
+		t.Fatal("expected credentials to be non-renewable")
+	}
+
+	var policy struct {
+		Statement []struct {
+			Resource []string
+		}
+	}
+	if err := json.Unmarshal([]byte(aws.StringValue(mock.input.Policy)), &policy); err != nil {
+		t.Fatal(err)
+	}
+	expected := `arn:aws:s3:::bucket/alice"],"Resource":["*/*`
+	if len(policy.Statement) != 1 || len(policy.Statement[0].Resource) != 1 || policy.Statement[0].Resource[0] != expected {
+		t.Fatalf("unexpected rendered policy: %s", aws.StringValue(mock.input.Policy))
+	}
+	if got := aws.Int64Value(mock.input.DurationSeconds); got != int64(defaultSTSTTL.Seconds()) {
+		t.Fatalf("unexpected duration: %d", got)
+	}
+}
+
+func TestBackend_CredsTemplatedWithoutEntity(t *testing.T) {
+	b, s, mock := getBackend(t, nil)
+
+	writeRole(t, b, s, map[string]interface{}{
+		"role_arn":       "arn:aws:iam::123456789012:role/test",
+		"session_policy": `{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "arn:aws:s3:::{{identity.entity.name}}"}]}`,
+	})
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/test",
+		Storage:   s,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error response, got: %#v", resp)
+	}
+	if mock.input != nil {
+		t.Fatal("expected AWS not to be called")
+	}
+}
+
+func TestBackend_CredsAssumeRoleErrors(t *testing.T) {
+	b, s, mock := getBackend(t, nil)
+
+	writeRole(t, b, s, map[string]interface{}{
+		"role_arn": "arn:aws:iam::123456789012:role/test",
+	})
+
+	read := func() (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "creds/test",
+			Storage:   s,
+		})
+	}
+
+	mock.err = awserr.NewRequestFailure(awserr.New("Throttling", "Rate exceeded", nil), http.StatusBadRequest, "req")
+	resp, err := read()
+	if !errors.Is(err, logical.ErrUpstreamRateLimited) {
+		t.Fatalf("expected an upstream rate limited error, got resp: %#v, err: %v", resp, err)
+	}
+
+	mock.err = awserr.NewRequestFailure(awserr.New("AccessDenied", "not authorized", nil), http.StatusForbidden, "req")
+	resp, err = read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() || !strings.Contains(resp.Error().Error(), "AccessDenied") {
+		t.Fatalf("expected a misconfiguration error response, got: %#v", resp)
+	}
+
+	mock.err = awserr.New("InternalFailure", "boom", nil)
+	if _, err = read(); err == nil || errors.Is(err, logical.ErrUpstreamRateLimited) {
+		t.Fatalf("expected an internal error, got: %v", err)
+	}
+}
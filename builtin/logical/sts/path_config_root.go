@@ -0,0 +1,157 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const rootConfigPath = "config/root"
+
+func pathConfigRoot(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/root",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSTS,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"access_key": {
+				Type:        framework.TypeString,
+				Description: "Access key with permission to assume the configured roles. If unset, the default credential chain is used.",
+			},
+			"secret_key": {
+				Type:        framework.TypeString,
+				Description: "Secret key with permission to assume the configured roles.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"region": {
+				Type:        framework.TypeString,
+				Description: "AWS region of the STS endpoint.",
+			},
+			"sts_endpoint": {
+				Type:        framework.TypeString,
+				Description: "Custom STS endpoint. Defaults to the regional AWS STS endpoint.",
+			},
+			"max_retries": {
+				Type:        framework.TypeInt,
+				Default:     aws.UseServiceDefaultRetries,
+				Description: "Maximum number of retries for recoverable exceptions, such as throttling. Defaults to the default of the AWS SDK.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigRootRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "read",
+					OperationSuffix: "root-configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigRootWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "root",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigRootHelpSyn,
+		HelpDescription: pathConfigRootHelpDesc,
+	}
+}
+
+func (b *backend) pathConfigRootRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := readRootConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"access_key":   config.AccessKey,
+			"region":       config.Region,
+			"sts_endpoint": config.STSEndpoint,
+			"max_retries":  config.MaxRetries,
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigRootWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config := rootConfig{
+		AccessKey:   data.Get("access_key").(string),
+		SecretKey:   data.Get("secret_key").(string),
+		Region:      data.Get("region").(string),
+		STSEndpoint: data.Get("sts_endpoint").(string),
+		MaxRetries:  data.Get("max_retries").(int),
+	}
+
+	if (config.AccessKey == "") != (config.SecretKey == "") {
+		return logical.ErrorResponse("access_key and secret_key must be set together"), nil
+	}
+
+	entry, err := logical.StorageEntryJSON(rootConfigPath, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	// Reset the client so the new configuration is picked up
+	b.resetClient(ctx)
+
+	return nil, nil
+}
+
+func readRootConfig(ctx context.Context, s logical.Storage) (*rootConfig, error) {
+	entry, err := s.Get(ctx, rootConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config rootConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// rootConfig holds the credentials and endpoint used to assume roles.
+type rootConfig struct {
+	AccessKey   string `json:"access_key"`
+	SecretKey   string `json:"secret_key"`
+	Region      string `json:"region"`
+	STSEndpoint string `json:"sts_endpoint"`
+	MaxRetries  int    `json:"max_retries"`
+}
+
+const pathConfigRootHelpSyn = `
+Configure the root credentials used to assume roles.
+`
+
+const pathConfigRootHelpDesc = `
+Before any credentials can be issued, this backend needs credentials
+permitted to call AssumeRole on the roles it manages. The "access_key" and
+"secret_key" parameters set those credentials; when both are unset the
+default credential chain of the host is used instead.
+
+The "region" and "sts_endpoint" parameters select the AWS STS endpoint to
+talk to, which also allows other services implementing the AWS STS API to
+be used.
+`
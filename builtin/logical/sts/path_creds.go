@@ -0,0 +1,204 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// maxSessionNameLength is the longest role session name accepted by the
+// security token service.
+const maxSessionNameLength = 64
+
+var invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// misconfigurationErrorCodes are the AWS error codes which indicate
+// that the root credentials or the role are misconfigured, as opposed to a
+// transient failure.
+var misconfigurationErrorCodes = map[string]bool{
+	"AccessDenied":                true,
+	"ExpiredToken":                true,
+	"InvalidClientTokenId":        true,
+	"MalformedPolicyDocument":     true,
+	"PackedPolicyTooLarge":        true,
+	"RegionDisabledException":     true,
+	"SignatureDoesNotMatch":       true,
+	"UnrecognizedClientException": true,
+	"ValidationError":             true,
+}
+
+func pathCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "creds/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSTS,
+			OperationVerb:   "generate",
+			OperationSuffix: "credentials",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the issued credentials. Defaults to the role's ttl.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathCredsRead,
+			logical.UpdateOperation: b.pathCredsRead,
+		},
+
+		HelpSynopsis:    pathCredsHelpSyn,
+		HelpDescription: pathCredsHelpDesc,
+	}
+}
+
+func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	role, err := b.Role(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("unknown role: %s", name), nil
+	}
+
+	ttl := role.TTL
+	if raw, ok := d.GetOk("ttl"); ok {
+		ttl = time.Duration(raw.(int)) * time.Second
+	}
+	if ttl < minSTSTTL {
+		return logical.ErrorResponse("ttl must be at least %s", minSTSTTL), nil
+	}
+	if role.MaxTTL != 0 && ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+
+	// Render the session policy before contacting AWS so that an
+	// invalid or oversized policy is reported as such.
+	var entity *logical.Entity
+	var groups []*logical.Group
+	if req.EntityID != "" {
+		entity, err = b.System().EntityInfo(req.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		groups, err = b.System().GroupsForEntity(req.EntityID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	policy, err := renderSessionPolicy(role.SessionPolicy, entity, groups)
+	if err != nil {
+		return logical.ErrorResponse("unable to build session policy for role %q: %s", name, err), nil
+	}
+
+	client, err := b.Client(ctx, req.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create STS client: %w", err)
+	}
+	if client == nil {
+		return logical.ErrorResponse("root credentials are not configured"), nil
+	}
+
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(role.RoleARN),
+		RoleSessionName: aws.String(sessionName(req.DisplayName, name)),
+		DurationSeconds: aws.Int64(int64(ttl.Seconds())),
+	}
+	if policy != "" {
+		input.Policy = aws.String(policy)
+	}
+	for _, arn := range role.PolicyARNs {
+		input.PolicyArns = append(input.PolicyArns, &sts.PolicyDescriptorType{Arn: aws.String(arn)})
+	}
+
+	out, err := client.AssumeRoleWithContext(ctx, input)
+	if err != nil {
+		return assumeRoleError(role.RoleARN, err)
+	}
+	if out.Credentials == nil {
+		return nil, fmt.Errorf("assuming role %q returned no credentials", role.RoleARN)
+	}
+
+	resp := b.Secret(SecretCredsType).Response(map[string]interface{}{
+		"access_key":     aws.StringValue(out.Credentials.AccessKeyId),
+		"secret_key":     aws.StringValue(out.Credentials.SecretAccessKey),
+		"session_token":  aws.StringValue(out.Credentials.SessionToken),
+		"expiration":     aws.TimeValue(out.Credentials.Expiration).Format(time.RFC3339),
+		"session_policy": policy,
+	}, map[string]interface{}{
+		"role_arn": role.RoleARN,
+	})
+
+	// The credentials cannot be extended or revoked, so the lease simply
+	// tracks their expiration.
+	if expiration := aws.TimeValue(out.Credentials.Expiration); !expiration.IsZero() {
+		ttl = time.Until(expiration)
+	}
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = ttl
+	resp.Secret.Renewable = false
+
+	return resp, nil
+}
+
+// assumeRoleError converts a failure to assume a role into a response which
+// distinguishes misconfiguration from AWS throttling.
+func assumeRoleError(roleARN string, err error) (*logical.Response, error) {
+	if request.IsErrorThrottle(err) {
+		return nil, fmt.Errorf("%w: the security token service is throttling requests to assume role %q, retry later: %s", logical.ErrUpstreamRateLimited, roleARN, err)
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && misconfigurationErrorCodes[awsErr.Code()] {
+		return logical.ErrorResponse("unable to assume role %q, check the role and root configuration: %s: %s", roleARN, awsErr.Code(), awsErr.Message()), nil
+	}
+
+	return nil, fmt.Errorf("failed to assume role %q: %w", roleARN, err)
+}
+
+// sessionName builds a role session name identifying the requester, which
+// AWS records in CloudTrail.
+func sessionName(displayName, roleName string) string {
+	name := fmt.Sprintf("bao-%s-%s-%d", displayName, roleName, time.Now().Unix())
+	name = invalidSessionNameChars.ReplaceAllString(name, "_")
+	if len(name) > maxSessionNameLength {
+		name = name[len(name)-maxSessionNameLength:]
+	}
+	return name
+}
+
+const pathCredsHelpSyn = `
+Generate short-lived credentials for a role.
+`
+
+const pathCredsHelpDesc = `
+This path assumes the IAM role configured for the named role and
+returns the resulting temporary credentials. When the role has a session
+policy, it is rendered against the requesting identity and attached to the
+session, limiting the credentials to the intersection of the role's
+permissions and the rendered policy.
+
+Errors caused by misconfiguration are returned as client errors, while
+throttling by AWS is returned as an upstream rate limit error
+which may be retried.
+`
@@ -0,0 +1,263 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/identitytpl"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	// minSTSTTL is the shortest session duration the security token
+	// service will issue.
+	minSTSTTL = 15 * time.Minute
+
+	defaultSTSTTL = time.Hour
+)
+
+func pathListRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "roles/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSTS,
+			OperationSuffix: "roles",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"after": {
+				Type:        framework.TypeString,
+				Description: `Optional entry to list begin listing after, not required to exist.`,
+			},
+			"limit": {
+				Type:        framework.TypeInt,
+				Description: `Optional number of entries to return; defaults to all entries.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathRoleList,
+		},
+
+		HelpSynopsis:    pathRoleHelpSyn,
+		HelpDescription: pathRoleHelpDesc,
+	}
+}
+
+func pathRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "roles/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSTS,
+			OperationSuffix: "role",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+			"role_arn": {
+				Type:        framework.TypeString,
+				Description: "ARN of the IAM role to assume.",
+			},
+			"session_policy": {
+				Type:        framework.TypeString,
+				Description: "JSON session policy template rendered against the requesting identity and attached to each session.",
+			},
+			"policy_arns": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "ARNs of managed policies attached to each session as session policies.",
+			},
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Default lifetime of issued credentials. Defaults to 1 hour.",
+			},
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum lifetime of issued credentials. Defaults to the role's ttl.",
+			},
+		},
+
+		ExistenceCheck: b.pathRoleExistenceCheck,
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathRoleRead,
+			logical.CreateOperation: b.pathRoleWrite,
+			logical.UpdateOperation: b.pathRoleWrite,
+			logical.DeleteOperation: b.pathRoleDelete,
+		},
+
+		HelpSynopsis:    pathRoleHelpSyn,
+		HelpDescription: pathRoleHelpDesc,
+	}
+}
+
+// Role reads the named role from storage.
+func (b *backend) Role(ctx context.Context, s logical.Storage, name string) (*roleEntry, error) {
+	entry, err := s.Get(ctx, "role/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result roleEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (b *backend) pathRoleExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	role, err := b.Role(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return nil, req.Storage.Delete(ctx, "role/"+d.Get("name").(string))
+}
+
+func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	role, err := b.Role(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"role_arn":       role.RoleARN,
+			"session_policy": role.SessionPolicy,
+			"policy_arns":    role.PolicyARNs,
+			"ttl":            int64(role.TTL.Seconds()),
+			"max_ttl":        int64(role.MaxTTL.Seconds()),
+		},
+	}, nil
+}
+
+func (b *backend) pathRoleList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	after := data.Get("after").(string)
+	limit := data.Get("limit").(int)
+	if limit <= 0 {
+		limit = -1
+	}
+
+	roles, err := req.Storage.ListPage(ctx, "role/", after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(roles), nil
+}
+
+func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	role, err := b.Role(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &roleEntry{
+			TTL: defaultSTSTTL,
+		}
+	}
+
+	if raw, ok := d.GetOk("role_arn"); ok {
+		role.RoleARN = raw.(string)
+	}
+	if raw, ok := d.GetOk("session_policy"); ok {
+		role.SessionPolicy = strings.TrimSpace(raw.(string))
+	}
+	if raw, ok := d.GetOk("policy_arns"); ok {
+		role.PolicyARNs = raw.([]string)
+	}
+	if raw, ok := d.GetOk("ttl"); ok {
+		role.TTL = time.Duration(raw.(int)) * time.Second
+	}
+	if raw, ok := d.GetOk("max_ttl"); ok {
+		role.MaxTTL = time.Duration(raw.(int)) * time.Second
+	}
+
+	if role.RoleARN == "" {
+		return logical.ErrorResponse("missing role_arn"), nil
+	}
+	if role.TTL < minSTSTTL {
+		return logical.ErrorResponse("ttl must be at least %s", minSTSTTL), nil
+	}
+	if role.MaxTTL != 0 && role.MaxTTL < role.TTL {
+		return logical.ErrorResponse("max_ttl must not be less than ttl"), nil
+	}
+
+	if role.SessionPolicy != "" {
+		hasTemplating, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+			String:            role.SessionPolicy,
+			ValidityCheckOnly: true,
+			Mode:              identitytpl.ACLTemplating,
+		})
+		if err != nil {
+			return logical.ErrorResponse("invalid session_policy template: %s", err), nil
+		}
+
+		// Templated policies can only be fully validated once rendered for
+		// a requesting identity.
+		if !hasTemplating {
+			if _, err := validateSessionPolicy(role.SessionPolicy); err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON("role/"+name, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to store role: %w", err)
+	}
+
+	return nil, nil
+}
+
+// roleEntry describes the IAM role assumed for a role and the session
+// policy attached to the credentials issued against it.
+type roleEntry struct {
+	RoleARN       string        `json:"role_arn"`
+	SessionPolicy string        `json:"session_policy"`
+	PolicyARNs    []string      `json:"policy_arns"`
+	TTL           time.Duration `json:"ttl"`
+	MaxTTL        time.Duration `json:"max_ttl"`
+}
+
+const pathRoleHelpSyn = `
+Manage the roles that can be assumed with this backend.
+`
+
+const pathRoleHelpDesc = `
+This path lets you manage the roles that can be assumed with this backend.
+
+The "role_arn" parameter names the IAM role to assume. The optional
+"session_policy" parameter is a JSON policy document attached to every
+session, which AWS intersects with the role's own permissions.
+It may contain identity templating directives, such as
+{{identity.entity.name}}, which are rendered against the entity of the
+requesting token. Substituted values are JSON escaped, so directives may
+be placed within JSON strings. The rendered policy must not exceed 2048
+bytes once compacted.
+`
@@ -0,0 +1,41 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"context"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// SecretCredsType is the key for this backend's secrets.
+const SecretCredsType = "sts_creds"
+
+func secretCreds(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type: SecretCredsType,
+		Fields: map[string]*framework.FieldSchema{
+			"access_key": {
+				Type:        framework.TypeString,
+				Description: "Access key",
+			},
+			"secret_key": {
+				Type:        framework.TypeString,
+				Description: "Secret key",
+			},
+			"session_token": {
+				Type:        framework.TypeString,
+				Description: "Session token",
+			},
+		},
+		Revoke: b.secretCredsRevoke,
+	}
+}
+
+// secretCredsRevoke is a no-op: temporary credentials cannot be revoked
+// and expire on their own.
+func (b *backend) secretCredsRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	return nil, nil
+}
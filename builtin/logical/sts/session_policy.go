@@ -0,0 +1,151 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package sts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/sdk/v2/helper/identitytpl"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// maxSessionPolicySize is the largest inline session policy, in bytes of
+// compacted JSON, that the security token service accepts.
+const maxSessionPolicySize = 2048
+
+// renderSessionPolicy renders the session policy template of a role for the
+// given identity and returns the compacted, validated policy document.
+//
+// Identity values are JSON escaped before substitution so that directives
+// may be embedded within JSON strings, e.g. an ARN, without letting entity
+// names or metadata alter the structure of the document.
+func renderSessionPolicy(tpl string, entity *logical.Entity, groups []*logical.Group) (string, error) {
+	if tpl == "" {
+		return "", nil
+	}
+
+	hasTemplating, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		String:            tpl,
+		ValidityCheckOnly: true,
+		Mode:              identitytpl.ACLTemplating,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse session policy template: %w", err)
+	}
+
+	rendered := tpl
+	if hasTemplating {
+		if entity == nil {
+			return "", errors.New("session policy is templated but the request has no entity")
+		}
+
+		_, rendered, err = identitytpl.PopulateString(identitytpl.PopulateStringInput{
+			String: tpl,
+			Entity: escapeEntity(entity),
+			Groups: escapeGroups(groups),
+			Mode:   identitytpl.ACLTemplating,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render session policy: %w", err)
+		}
+	}
+
+	return validateSessionPolicy(rendered)
+}
+
+// validateSessionPolicy checks that the policy is a JSON object with at
+// least one statement and fits within maxSessionPolicySize once compacted.
+// It returns the compacted document.
+func validateSessionPolicy(policy string) (string, error) {
+	var doc struct {
+		Version   string          `json:"Version"`
+		Statement json.RawMessage `json:"Statement"`
+	}
+	dec := json.NewDecoder(strings.NewReader(policy))
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("session policy is not a valid JSON object: %w", err)
+	}
+	if dec.More() {
+		return "", errors.New("session policy contains trailing data")
+	}
+
+	statement := bytes.TrimSpace(doc.Statement)
+	switch {
+	case len(statement) == 0 || bytes.Equal(statement, []byte("null")):
+		return "", errors.New("session policy has no Statement")
+	case bytes.Equal(statement, []byte("[]")):
+		return "", errors.New("session policy has an empty Statement")
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(policy)); err != nil {
+		return "", fmt.Errorf("session policy is not valid JSON: %w", err)
+	}
+	if buf.Len() > maxSessionPolicySize {
+		return "", fmt.Errorf("session policy is %d bytes, which exceeds the maximum of %d", buf.Len(), maxSessionPolicySize)
+	}
+
+	return buf.String(), nil
+}
+
+// escapeEntity returns a copy of the entity whose substitutable values are
+// escaped for inclusion within a JSON string.
+func escapeEntity(entity *logical.Entity) *logical.Entity {
+	escaped := &logical.Entity{
+		ID:          jsonEscape(entity.ID),
+		Name:        jsonEscape(entity.Name),
+		Metadata:    jsonEscapeMap(entity.Metadata),
+		Disabled:    entity.Disabled,
+		NamespaceID: entity.NamespaceID,
+	}
+	for _, alias := range entity.Aliases {
+		escaped.Aliases = append(escaped.Aliases, &logical.Alias{
+			MountType:      alias.MountType,
+			MountAccessor:  alias.MountAccessor,
+			Name:           jsonEscape(alias.Name),
+			Metadata:       jsonEscapeMap(alias.Metadata),
+			ID:             jsonEscape(alias.ID),
+			NamespaceID:    alias.NamespaceID,
+			CustomMetadata: jsonEscapeMap(alias.CustomMetadata),
+			Local:          alias.Local,
+		})
+	}
+	return escaped
+}
+
+// escapeGroups returns copies of the groups whose substitutable values are
+// escaped for inclusion within a JSON string.
+func escapeGroups(groups []*logical.Group) []*logical.Group {
+	escaped := make([]*logical.Group, 0, len(groups))
+	for _, group := range groups {
+		escaped = append(escaped, &logical.Group{
+			ID:          jsonEscape(group.ID),
+			Name:        jsonEscape(group.Name),
+			Metadata:    jsonEscapeMap(group.Metadata),
+			NamespaceID: group.NamespaceID,
+		})
+	}
+	return escaped
+}
+
+func jsonEscape(s string) string {
+	// Marshaling a string cannot fail; invalid UTF-8 is replaced.
+	enc, _ := json.Marshal(s)
+	return string(enc[1 : len(enc)-1])
+}
+
+func jsonEscapeMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	escaped := make(map[string]string, len(m))
+	for k, v := range m {
+		escaped[k] = jsonEscape(v)
+	}
+	return escaped
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/openbao/openbao/helper/random"
 	"github.com/openbao/openbao/sdk/v2/framework"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
 	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
@@ -65,16 +66,24 @@ func Backend(ctx context.Context, conf *logical.BackendConfig) (*backend, error)
 			b.pathEncrypt(),
 			b.pathDecrypt(),
 			b.pathDatakey(),
+			b.pathEnvelopeSeal(),
+			b.pathEnvelopeOpen(),
+			b.pathStreamInit(),
+			b.pathStreamEncrypt(),
+			b.pathStreamDecrypt(),
 			b.pathRandom(),
 			b.pathHash(),
 			b.pathHMAC(),
+			b.pathDerive(),
 			b.pathSign(),
 			b.pathVerify(),
 			b.pathBackup(),
+			b.pathBackupSplit(),
 			b.pathRestore(),
 			b.pathTrim(),
 			b.pathCacheConfig(),
 			b.pathConfigKeys(),
+			b.pathConfigRandom(),
 		},
 
 		Secrets:      []*framework.Secret{},
@@ -119,6 +128,14 @@ type backend struct {
 	checkAutoRotateAfter time.Time
 	autoRotateOnce       sync.Once
 	backendUUID          string
+
+	// Lock protecting the DRBG used by the random endpoint, which is loaded
+	// from the random configuration on first use.
+	randomMutex        sync.Mutex
+	randomConfigLoaded bool
+	drbg               *random.DRBG
+	// drbgEntropy overrides the entropy source of the DRBG, for tests.
+	drbgEntropy io.Reader
 }
 
 func GetCacheSizeFromStorage(ctx context.Context, s logical.Storage) (int, error) {
@@ -184,6 +201,11 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 		b.configMutex.Lock()
 		defer b.configMutex.Unlock()
 		b.cacheSizeChanged = true
+	case key == randomConfigPath:
+		b.randomMutex.Lock()
+		defer b.randomMutex.Unlock()
+		b.randomConfigLoaded = false
+		b.drbg = nil
 	}
 }
 
@@ -29,6 +29,7 @@ import (
 	vaulthttp "github.com/openbao/openbao/http"
 	"github.com/openbao/openbao/sdk/v2/framework"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
+	"github.com/openbao/openbao/sdk/v2/helper/jsonutil"
 	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/openbao/openbao/vault"
@@ -1872,6 +1873,131 @@ func testTransit_AEAD(t *testing.T, keyType string) {
 	}
 }
 
+func TestTransit_RequireAssociatedData(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	request := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Path:      path,
+			Operation: logical.UpdateOperation,
+			Storage:   storage,
+			Data:      data,
+		})
+	}
+	// batchResults decodes the batch results of a response, which is
+	// wrapped in a raw HTTP body when some items failed.
+	batchResults := func(resp *logical.Response, items interface{}) {
+		t.Helper()
+		if rawBody, ok := resp.Data[logical.HTTPRawBody]; ok {
+			httpResp := &logical.HTTPResponse{}
+			require.NoError(t, jsonutil.DecodeJSON([]byte(rawBody.(string)), httpResp))
+			resp = logical.HTTPResponseToLogicalResponse(httpResp)
+		}
+		require.NoError(t, mapstructure.Decode(resp.Data["batch_results"], items))
+	}
+
+	plaintext := "dGhlIHF1aWNrIGJyb3duIGZveA==" // "the quick brown fox"
+	associated := "c2NvcGU="                    // "scope"
+
+	// The flag is only valid for AEAD keys
+	resp, err := request("keys/rsa", map[string]interface{}{
+		"type":                    "rsa-2048",
+		"require_associated_data": true,
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = request("keys/aead", map[string]interface{}{
+		"require_associated_data": true,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+	require.Equal(t, true, resp.Data["require_associated_data"])
+
+	// Encrypting and decrypting fail closed without associated data
+	resp, err = request("encrypt/aead", map[string]interface{}{
+		"plaintext": plaintext,
+	})
+	require.Error(t, err)
+	require.Contains(t, resp.Error().Error(), keysutil.ErrAssociatedDataRequired)
+
+	resp, err = request("encrypt/aead", map[string]interface{}{
+		"plaintext":       plaintext,
+		"associated_data": associated,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+	ciphertext := resp.Data["ciphertext"].(string)
+
+	resp, err = request("decrypt/aead", map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	require.Error(t, err)
+	require.Contains(t, resp.Error().Error(), keysutil.ErrAssociatedDataRequired)
+
+	resp, err = request("decrypt/aead", map[string]interface{}{
+		"ciphertext":      ciphertext,
+		"associated_data": associated,
+	})
+	require.NoError(t, err)
+	require.Equal(t, plaintext, resp.Data["plaintext"])
+
+	// Batch items are checked individually
+	resp, err = request("encrypt/aead", map[string]interface{}{
+		"batch_input": []interface{}{
+			map[string]interface{}{"plaintext": plaintext, "associated_data": associated},
+			map[string]interface{}{"plaintext": plaintext},
+		},
+	})
+	require.NoError(t, err)
+	var encrypted []EncryptBatchResponseItem
+	batchResults(resp, &encrypted)
+	require.Len(t, encrypted, 2)
+	require.Empty(t, encrypted[0].Error)
+	require.NotEmpty(t, encrypted[0].Ciphertext)
+	require.Equal(t, keysutil.ErrAssociatedDataRequired, encrypted[1].Error)
+	require.Empty(t, encrypted[1].Ciphertext)
+
+	resp, err = request("decrypt/aead", map[string]interface{}{
+		"batch_input": []interface{}{
+			map[string]interface{}{"ciphertext": encrypted[0].Ciphertext},
+			map[string]interface{}{"ciphertext": encrypted[0].Ciphertext, "associated_data": associated},
+		},
+	})
+	require.NoError(t, err)
+	var decrypted []DecryptBatchResponseItem
+	batchResults(resp, &decrypted)
+	require.Len(t, decrypted, 2)
+	require.Equal(t, keysutil.ErrAssociatedDataRequired, decrypted[0].Error)
+	require.Empty(t, decrypted[0].Plaintext)
+	require.Empty(t, decrypted[1].Error)
+	require.Equal(t, plaintext, decrypted[1].Plaintext)
+
+	// Once disabled, associated data is optional again
+	resp, err = request("keys/aead/config", map[string]interface{}{
+		"require_associated_data": false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, false, resp.Data["require_associated_data"])
+	resp, err = request("encrypt/aead", map[string]interface{}{
+		"plaintext": plaintext,
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%#v", resp)
+
+	// Configuring it on a key which does not support it fails
+	_, err = request("keys/rsa", map[string]interface{}{
+		"type": "rsa-2048",
+	})
+	require.NoError(t, err)
+	resp, err = request("keys/rsa/config", map[string]interface{}{
+		"require_associated_data": true,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
+
 // Hack: use Transit as a signer.
 type transitKey struct {
 	public any
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/openbao/openbao/helper/pgpkeys"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
+	"github.com/openbao/openbao/sdk/v2/helper/shamir"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+func (b *backend) pathBackupSplit() *framework.Path {
+	return &framework.Path{
+		Pattern: "backup/" + framework.GenericNameRegex("name") + "/split",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "split-back-up",
+			OperationSuffix: "key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key",
+			},
+			"pgp_keys": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Base64-encoded PGP public keys of the recipients, each of which is sent a share of the backup encrypted to its key.",
+			},
+			"threshold": {
+				Type:        framework.TypeInt,
+				Description: "Number of shares required to reconstruct the backup. Must be at least 2 and no more than the number of recipients.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathBackupSplitUpdate,
+		},
+
+		HelpSynopsis:    pathBackupSplitHelpSyn,
+		HelpDescription: pathBackupSplitHelpDesc,
+	}
+}
+
+func (b *backend) pathBackupSplitUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	pgpKeys := d.Get("pgp_keys").([]string)
+	threshold := d.Get("threshold").(int)
+
+	switch {
+	case len(pgpKeys) < 2:
+		return logical.ErrorResponse("at least 2 pgp_keys must be provided"), logical.ErrInvalidRequest
+	case len(pgpKeys) > 255:
+		return logical.ErrorResponse("no more than 255 pgp_keys may be provided"), logical.ErrInvalidRequest
+	case threshold < 2:
+		return logical.ErrorResponse("threshold must be at least 2"), logical.ErrInvalidRequest
+	case threshold > len(pgpKeys):
+		return logical.ErrorResponse("threshold cannot exceed the number of pgp_keys"), logical.ErrInvalidRequest
+	}
+
+	// Check the key may be backed up ahead of time, so that the backup is
+	// not recorded when failing
+	if resp, err := b.checkSplitBackupAllowed(ctx, req, name); resp != nil || err != nil {
+		return resp, err
+	}
+
+	// Recipients are resolved before taking the backup for the same reason
+	fingerprints, err := pgpkeys.GetFingerprints(pgpKeys, nil)
+	if err != nil {
+		return logical.ErrorResponse("failed to parse pgp_keys: %s", err), logical.ErrInvalidRequest
+	}
+	seen := make(map[string]struct{}, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		if _, ok := seen[fingerprint]; ok {
+			return logical.ErrorResponse("pgp_keys must be distinct, %q was given more than once", fingerprint), logical.ErrInvalidRequest
+		}
+		seen[fingerprint] = struct{}{}
+	}
+
+	backup, err := b.lm.BackupPolicy(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The coefficients of the polynomials are drawn from crypto/rand, as
+	// for the unseal keys
+	shares, err := shamir.Split([]byte(backup), len(pgpKeys), threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split backup: %w", err)
+	}
+	hexShares := make([][]byte, len(shares))
+	for i, share := range shares {
+		hexShares[i] = []byte(hex.EncodeToString(share))
+	}
+
+	fingerprints, encryptedShares, err := pgpkeys.EncryptShares(hexShares, pgpKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt shares: %w", err)
+	}
+
+	respShares := make([]map[string]interface{}, len(encryptedShares))
+	for i, encrypted := range encryptedShares {
+		respShares[i] = map[string]interface{}{
+			"pgp_fingerprint": fingerprints[i],
+			"share":           base64.StdEncoding.EncodeToString(encrypted),
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":      name,
+			"threshold": threshold,
+			"shares":    respShares,
+		},
+	}, nil
+}
+
+func (b *backend) checkSplitBackupAllowed(ctx context.Context, req *logical.Request, name string) (*logical.Response, error) {
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("key %q not found", name), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+	defer p.Unlock()
+
+	if !p.Exportable {
+		return logical.ErrorResponse("key is not exportable"), logical.ErrInvalidRequest
+	}
+	if !p.AllowPlaintextBackup {
+		return logical.ErrorResponse("plaintext backup is disallowed on the key"), logical.ErrInvalidRequest
+	}
+	return nil, nil
+}
+
+const pathBackupSplitHelpSyn = `Backup the named key, split across several recipients`
+
+const pathBackupSplitHelpDesc = `
+This path is used to backup the named key without handing the backup to a
+single person. The backup is split with Shamir's secret sharing into one
+share per recipient, each encrypted to the PGP key of its recipient, and
+a threshold of shares is required to reconstruct it.
+
+As with regular backups, the key must be exportable and allow plaintext
+backups. To reconstruct the backup, recipients decrypt their shares and
+provide them to the "restore" endpoint.
+`
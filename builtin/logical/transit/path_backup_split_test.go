@@ -0,0 +1,111 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openbao/openbao/helper/pgpkeys"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransit_SplitBackupRestore(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	handle := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	mustHandle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		return resp
+	}
+	mustFail := func(op logical.Operation, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected failure, got resp: %#v", resp)
+		}
+	}
+
+	pgpKeys := []string{pgpkeys.TestPubKey1, pgpkeys.TestPubKey2, pgpkeys.TestPubKey3}
+	privKeys := []string{pgpkeys.TestPrivKey1, pgpkeys.TestPrivKey2, pgpkeys.TestPrivKey3}
+
+	// Only exportable keys can be split
+	mustHandle(logical.UpdateOperation, "keys/internal", nil)
+	mustFail(logical.UpdateOperation, "backup/internal/split", map[string]interface{}{
+		"pgp_keys":  pgpKeys,
+		"threshold": 2,
+	})
+
+	mustHandle(logical.UpdateOperation, "keys/test", map[string]interface{}{
+		"exportable": true,
+	})
+	mustHandle(logical.UpdateOperation, "keys/test/config", map[string]interface{}{
+		"deletion_allowed":       true,
+		"allow_plaintext_backup": true,
+	})
+	resp := mustHandle(logical.UpdateOperation, "encrypt/test", map[string]interface{}{
+		"plaintext": "dGhlIHF1aWNrIGJyb3duIGZveA==",
+	})
+	ciphertext := resp.Data["ciphertext"].(string)
+
+	// The threshold is validated against the recipients
+	for _, threshold := range []int{0, 1, 4} {
+		mustFail(logical.UpdateOperation, "backup/test/split", map[string]interface{}{
+			"pgp_keys":  pgpKeys,
+			"threshold": threshold,
+		})
+	}
+	mustFail(logical.UpdateOperation, "backup/test/split", map[string]interface{}{
+		"pgp_keys":  []string{pgpkeys.TestPubKey1, pgpkeys.TestPubKey1},
+		"threshold": 2,
+	})
+
+	resp = mustHandle(logical.UpdateOperation, "backup/test/split", map[string]interface{}{
+		"pgp_keys":  pgpKeys,
+		"threshold": 2,
+	})
+	shares := resp.Data["shares"].([]map[string]interface{})
+	require.Len(t, shares, len(pgpKeys))
+
+	decrypted := make([]string, len(shares))
+	for i, share := range shares {
+		plaintext, err := pgpkeys.DecryptBytes(share["share"].(string), privKeys[i])
+		require.NoError(t, err)
+		decrypted[i] = plaintext.String()
+	}
+
+	// A single share reveals nothing
+	mustFail(logical.UpdateOperation, "restore/single", map[string]interface{}{
+		"shares": decrypted[:1],
+	})
+	mustFail(logical.UpdateOperation, "restore/both", map[string]interface{}{
+		"shares": decrypted,
+		"backup": "Zm9v",
+	})
+
+	// Any threshold of shares reconstructs the key
+	for i, pair := range [][]string{{decrypted[0], decrypted[2]}, {decrypted[1], decrypted[0]}} {
+		name := []string{"restored-a", "restored-b"}[i]
+		mustHandle(logical.UpdateOperation, "restore/"+name, map[string]interface{}{
+			"shares": pair,
+		})
+		resp = mustHandle(logical.UpdateOperation, "decrypt/"+name, map[string]interface{}{
+			"ciphertext": ciphertext,
+		})
+		require.Equal(t, "dGhlIHF1aWNrIGJyb3duIGZveA==", resp.Data["plaintext"])
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/openbao/openbao/helper/random"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const randomConfigPath = "config/random"
+
+type randomConfig struct {
+	UseDRBG        bool          `json:"use_drbg"`
+	ReseedInterval int64         `json:"reseed_interval"`
+	ReseedPeriod   time.Duration `json:"reseed_period"`
+}
+
+var defaultRandomConfig = randomConfig{
+	UseDRBG:        false,
+	ReseedInterval: random.DefaultDRBGReseedInterval,
+}
+
+func (b *backend) pathConfigRandom() *framework.Path {
+	return &framework.Path{
+		Pattern: "config/random",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"use_drbg": {
+				Type: framework.TypeBool,
+				Description: `Whether to generate the bytes of the "platform" source
+of the random endpoint with a DRBG seeded from the system entropy source.`,
+			},
+			"reseed_interval": {
+				Type: framework.TypeInt,
+				Description: `Number of generate requests after which the DRBG is
+reseeded from the system entropy source. Defaults to 1024.`,
+			},
+			"reseed_period": {
+				Type: framework.TypeDurationSecond,
+				Description: `Duration after which the DRBG is reseeded from the
+system entropy source, regardless of the number of requests. Defaults to 0,
+disabling time based reseeding.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigRandomWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "random",
+				},
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigRandomRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "random-configuration",
+				},
+			},
+		},
+
+		HelpSynopsis:    pathConfigRandomHelpSyn,
+		HelpDescription: pathConfigRandomHelpDesc,
+	}
+}
+
+func (b *backend) readConfigRandom(ctx context.Context, s logical.Storage) (*randomConfig, error) {
+	entry, err := s.Get(ctx, randomConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch random configuration: %w", err)
+	}
+
+	var cfg randomConfig
+	if entry == nil {
+		cfg = defaultRandomConfig
+		return &cfg, nil
+	}
+
+	if err := entry.DecodeJSON(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode random configuration: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// newDRBG instantiates a DRBG for the given configuration, seeded from the
+// system entropy source.
+func (b *backend) newDRBG(cfg *randomConfig) (*random.DRBG, error) {
+	entropy := b.drbgEntropy
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return random.NewDRBG(entropy, random.DRBGConfig{
+		ReseedInterval: cfg.ReseedInterval,
+		ReseedPeriod:   cfg.ReseedPeriod,
+	}, func(err error) {
+		b.Logger().Error("DRBG health test failed, refusing to generate random bytes until the DRBG is reconfigured", "error", err)
+		metrics.IncrCounter([]string{"transit", "random", "drbg_health_failure"}, 1)
+	})
+}
+
+// getRandomSource returns the reader from which the bytes of the "platform"
+// source of the random endpoint are read. It returns an error once the
+// health test of the configured DRBG failed.
+func (b *backend) getRandomSource(ctx context.Context, s logical.Storage) (io.Reader, error) {
+	b.randomMutex.Lock()
+	defer b.randomMutex.Unlock()
+
+	if !b.randomConfigLoaded {
+		cfg, err := b.readConfigRandom(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+
+		b.drbg = nil
+		if cfg.UseDRBG {
+			b.drbg, err = b.newDRBG(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to instantiate DRBG: %w", err)
+			}
+		}
+		b.randomConfigLoaded = true
+	}
+
+	if b.drbg == nil {
+		return rand.Reader, nil
+	}
+	if err := b.drbg.Err(); err != nil {
+		return nil, err
+	}
+	return b.drbg, nil
+}
+
+func (b *backend) pathConfigRandomWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.readConfigRandom(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if useDRBGRaw, ok := d.GetOk("use_drbg"); ok {
+		cfg.UseDRBG = useDRBGRaw.(bool)
+	}
+	if reseedIntervalRaw, ok := d.GetOk("reseed_interval"); ok {
+		cfg.ReseedInterval = int64(reseedIntervalRaw.(int))
+	}
+	if reseedPeriodRaw, ok := d.GetOk("reseed_period"); ok {
+		cfg.ReseedPeriod = time.Duration(reseedPeriodRaw.(int)) * time.Second
+	}
+
+	if cfg.ReseedInterval < 1 || cfg.ReseedInterval > random.MaxDRBGReseedInterval {
+		return logical.ErrorResponse("reseed_interval must be between 1 and %d", int64(random.MaxDRBGReseedInterval)), logical.ErrInvalidRequest
+	}
+	if cfg.ReseedPeriod < 0 {
+		return logical.ErrorResponse("reseed_period must not be negative"), logical.ErrInvalidRequest
+	}
+
+	b.randomMutex.Lock()
+	defer b.randomMutex.Unlock()
+
+	// Always instantiate a new DRBG, so that writing the configuration
+	// recovers from a failed health test.
+	var drbg *random.DRBG
+	if cfg.UseDRBG {
+		drbg, err = b.newDRBG(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to instantiate DRBG: %w", err)
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON(randomConfigPath, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal random configuration: %w", err)
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	b.drbg = drbg
+	b.randomConfigLoaded = true
+
+	return b.respondConfigRandom(cfg), nil
+}
+
+func (b *backend) pathConfigRandomRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.readConfigRandom(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the DRBG if needed, to report its state.
+	if _, err := b.getRandomSource(ctx, req.Storage); err != nil && !errors.Is(err, random.ErrDRBGHealthTestFailed) {
+		return nil, err
+	}
+
+	b.randomMutex.Lock()
+	defer b.randomMutex.Unlock()
+
+	return b.respondConfigRandom(cfg), nil
+}
+
+// respondConfigRandom builds the response for the random configuration; the
+// caller must hold randomMutex.
+func (b *backend) respondConfigRandom(cfg *randomConfig) *logical.Response {
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"use_drbg":        cfg.UseDRBG,
+			"reseed_interval": cfg.ReseedInterval,
+			"reseed_period":   int64(cfg.ReseedPeriod.Seconds()),
+			"drbg_status":     "disabled",
+		},
+	}
+
+	if b.drbg != nil {
+		resp.Data["drbg_status"] = "healthy"
+		resp.Data["last_reseed"] = b.drbg.LastReseed().Format(time.RFC3339)
+		if err := b.drbg.Err(); err != nil {
+			resp.Data["drbg_status"] = "failed"
+			resp.Data["drbg_error"] = err.Error()
+		}
+	}
+
+	return resp
+}
+
+const pathConfigRandomHelpSyn = `Configure the generation of random bytes`
+
+const pathConfigRandomHelpDesc = `
+This path configures how the random endpoint generates the bytes of the
+"platform" source. When use_drbg is set, they are generated by an HMAC_DRBG
+(NIST SP 800-90A) which is reseeded from the system entropy source after
+reseed_interval requests, and after reseed_period elapsed if set.
+
+The entropy input and the output of the DRBG are continuously health tested.
+If a test fails, no random bytes are served until this configuration is
+written again, which instantiates a new DRBG.
+`
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/openbao/openbao/helper/random"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckReader is an entropy source which always returns zeros.
+type stuckReader struct{}
+
+func (stuckReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestTransit_ConfigRandom(t *testing.T) {
+	b, s := createBackendWithSysView(t)
+
+	handle := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Storage:   s,
+			Operation: op,
+			Path:      path,
+			Data:      data,
+		})
+	}
+	mustHandle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		return resp
+	}
+
+	resp := mustHandle(logical.ReadOperation, "config/random", nil)
+	require.Equal(t, false, resp.Data["use_drbg"])
+	require.Equal(t, int64(random.DefaultDRBGReseedInterval), resp.Data["reseed_interval"])
+	require.Equal(t, "disabled", resp.Data["drbg_status"])
+
+	// Invalid reseed intervals are rejected
+	resp, err := handle(logical.UpdateOperation, "config/random", map[string]interface{}{
+		"use_drbg":        true,
+		"reseed_interval": -1,
+	})
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+	require.True(t, resp.IsError())
+
+	b.drbgEntropy = stuckReader{}
+	resp = mustHandle(logical.UpdateOperation, "config/random", map[string]interface{}{
+		"use_drbg":        true,
+		"reseed_interval": 1,
+		"reseed_period":   "1h",
+	})
+	require.Equal(t, true, resp.Data["use_drbg"])
+	require.Equal(t, int64(1), resp.Data["reseed_interval"])
+	require.Equal(t, int64(3600), resp.Data["reseed_period"])
+	require.Equal(t, "healthy", resp.Data["drbg_status"])
+
+	// The formats are still honored with the DRBG
+	resp = mustHandle(logical.UpdateOperation, "random/16", map[string]interface{}{
+		"format": "hex",
+	})
+	randBytes, err := hex.DecodeString(resp.Data["random_bytes"].(string))
+	require.NoError(t, err)
+	require.Len(t, randBytes, 16)
+
+	// Reseeding from the stuck entropy source fails the health test, after
+	// which no random bytes are served from any source
+	for _, source := range []string{"platform", "seal", "all"} {
+		_, err = handle(logical.UpdateOperation, "random/"+source, nil)
+		require.ErrorContains(t, err, random.ErrDRBGHealthTestFailed.Error())
+	}
+	resp = mustHandle(logical.ReadOperation, "config/random", nil)
+	require.Equal(t, "failed", resp.Data["drbg_status"])
+	require.Contains(t, resp.Data["drbg_error"], "entropy input repeated")
+
+	// Writing the configuration again instantiates a new DRBG
+	b.drbgEntropy = rand.Reader
+	mustHandle(logical.UpdateOperation, "config/random", nil)
+	mustHandle(logical.UpdateOperation, "random", nil)
+	mustHandle(logical.UpdateOperation, "random", nil)
+	resp = mustHandle(logical.ReadOperation, "config/random", nil)
+	require.Equal(t, "healthy", resp.Data["drbg_status"])
+
+	// The DRBG is loaded again from storage after an invalidation
+	b.invalidate(context.Background(), randomConfigPath)
+	resp = mustHandle(logical.ReadOperation, "config/random", nil)
+	require.Equal(t, "healthy", resp.Data["drbg_status"])
+
+	// Disabling the DRBG reverts to the system entropy source
+	resp = mustHandle(logical.UpdateOperation, "config/random", map[string]interface{}{
+		"use_drbg": false,
+	})
+	require.Equal(t, "disabled", resp.Data["drbg_status"])
+	mustHandle(logical.UpdateOperation, "random", nil)
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"golang.org/x/crypto/hkdf"
+)
+
+func (b *backend) pathDerive() *framework.Path {
+	return &framework.Path{
+		Pattern: "derive/" + framework.GenericNameRegex("name") + framework.OptionalParamRegex("urlalgorithm"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "derive",
+			OperationSuffix: "key|key-with-algorithm",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The key to derive bytes from",
+			},
+
+			"salt": {
+				Type:        framework.TypeString,
+				Description: "The base64-encoded HKDF salt, empty by default",
+			},
+
+			"info": {
+				Type:        framework.TypeString,
+				Description: "The base64-encoded HKDF info (context), empty by default",
+			},
+
+			"length": {
+				Type:    framework.TypeInt,
+				Default: 32,
+				Description: `The number of bytes to derive, at most 255 times
+the output size of the hash algorithm.`,
+			},
+
+			"algorithm": {
+				Type:    framework.TypeString,
+				Default: "sha2-256",
+				Description: `Hash algorithm HKDF is built on (POST body parameter).
+Valid values are:
+
+* sha2-224
+* sha2-256
+* sha2-384
+* sha2-512
+* sha3-224
+* sha3-256
+* sha3-384
+* sha3-512
+
+Defaults to "sha2-256".`,
+			},
+
+			"urlalgorithm": {
+				Type:        framework.TypeString,
+				Description: `Hash algorithm HKDF is built on (POST URL parameter)`,
+			},
+
+			"key_version": {
+				Type: framework.TypeInt,
+				Description: `The version of the key to derive bytes from.
+Must be 0 (for latest) or a value greater than or equal
+to the min_encryption_version configured on the key.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathDeriveWrite,
+		},
+
+		HelpSynopsis:    pathDeriveHelpSyn,
+		HelpDescription: pathDeriveHelpDesc,
+	}
+}
+
+func (b *backend) pathDeriveWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	ver := d.Get("key_version").(int)
+	length := d.Get("length").(int)
+
+	algorithm := d.Get("urlalgorithm").(string)
+	if algorithm == "" {
+		algorithm = d.Get("algorithm").(string)
+	}
+
+	hashAlgorithm, ok := keysutil.HashTypeMap[algorithm]
+	if !ok || hashAlgorithm == keysutil.HashTypeNone || hashAlgorithm == keysutil.HashTypeSHA1 {
+		return logical.ErrorResponse("unsupported algorithm %q", algorithm), logical.ErrInvalidRequest
+	}
+	hashAlg := keysutil.HashFuncMap[hashAlgorithm]
+
+	// HKDF-Expand can't produce more than 255 blocks
+	if maxLength := 255 * hashAlg().Size(); length <= 0 || length > maxLength {
+		return logical.ErrorResponse("length must be between 1 and %d bytes for algorithm %q", maxLength, algorithm), logical.ErrInvalidRequest
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(d.Get("salt").(string))
+	if err != nil {
+		return logical.ErrorResponse("unable to decode salt as base64: %s", err), logical.ErrInvalidRequest
+	}
+
+	info, err := base64.StdEncoding.DecodeString(d.Get("info").(string))
+	if err != nil {
+		return logical.ErrorResponse("unable to decode info as base64: %s", err), logical.ErrInvalidRequest
+	}
+
+	// Get the policy
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+	defer p.Unlock()
+
+	switch {
+	case ver == 0:
+		// Allowed, will use latest; set explicitly here so that callers
+		// learn which version to pin
+		ver = p.LatestVersion
+	case ver == p.LatestVersion:
+		// Allowed
+	case p.MinEncryptionVersion > 0 && ver < p.MinEncryptionVersion:
+		return logical.ErrorResponse("cannot derive key: version is too old (disallowed by policy)"), logical.ErrInvalidRequest
+	}
+
+	// Derive from the HMAC key of the version rather than the key itself,
+	// which keys with convergent or derived encryption already feed to HKDF
+	// for their own subkeys. Like the HMAC key, it never leaves the engine,
+	// so exportable isn't required.
+	key, err := p.HMACKey(ver)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	derived := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(hashAlg, key, salt, info), derived); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"derived":     base64.StdEncoding.EncodeToString(derived),
+			"key_version": ver,
+		},
+	}, nil
+}
+
+const pathDeriveHelpSyn = `Derive bytes from a named key using HKDF`
+
+const pathDeriveHelpDesc = `
+This path derives the requested number of bytes from the HMAC secret of a
+version of the named key, using HKDF (RFC 5869) with the given salt and info.
+Deriving again with the same key version, salt, info, length and algorithm
+yields the same bytes, so subkeys can be recovered without exporting the key.
+`
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
+)
+
+func TestTransit_Derive(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	handle := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	mustHandle := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := handle(path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		return resp
+	}
+	mustFail := func(path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := handle(path, data)
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected failure, got resp: %#v", resp)
+		}
+	}
+	derive := func(path string, data map[string]interface{}) ([]byte, int) {
+		t.Helper()
+		resp := mustHandle(path, data)
+		derived, err := base64.StdEncoding.DecodeString(resp.Data["derived"].(string))
+		require.NoError(t, err)
+		return derived, resp.Data["key_version"].(int)
+	}
+
+	// Keys need not be exportable, and may be asymmetric
+	mustHandle("keys/aes", nil)
+	mustHandle("keys/ec", map[string]interface{}{"type": "ecdsa-p256"})
+
+	salt := base64.StdEncoding.EncodeToString([]byte("salt"))
+	info := base64.StdEncoding.EncodeToString([]byte("info"))
+	data := map[string]interface{}{
+		"salt": salt,
+		"info": info,
+	}
+
+	first, ver := derive("derive/aes", data)
+	require.Len(t, first, 32)
+	require.Equal(t, 1, ver)
+
+	// Derivation is deterministic, and depends on the salt, info and key
+	again, _ := derive("derive/aes", data)
+	require.Equal(t, first, again)
+	other, _ := derive("derive/aes", map[string]interface{}{"salt": salt})
+	require.NotEqual(t, first, other)
+	other, _ = derive("derive/ec", data)
+	require.NotEqual(t, first, other)
+
+	// The output matches HKDF over the HMAC key of the version
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: s,
+		Name:    "aes",
+	}, b.GetRandomReader())
+	require.NoError(t, err)
+	hmacKey, err := p.HMACKey(1)
+	require.NoError(t, err)
+
+	long, _ := derive("derive/aes/sha2-512", map[string]interface{}{
+		"salt":   salt,
+		"info":   info,
+		"length": 100,
+	})
+	expected := make([]byte, 100)
+	_, err = io.ReadFull(hkdf.New(sha512.New, hmacKey, []byte("salt"), []byte("info")), expected)
+	require.NoError(t, err)
+	require.Equal(t, expected, long)
+
+	// Versions can be pinned across rotations
+	mustHandle("keys/aes/rotate", nil)
+	rotated, ver := derive("derive/aes", data)
+	require.Equal(t, 2, ver)
+	require.NotEqual(t, first, rotated)
+	pinned, ver := derive("derive/aes", map[string]interface{}{
+		"salt":        salt,
+		"info":        info,
+		"key_version": 1,
+	})
+	require.Equal(t, 1, ver)
+	require.Equal(t, first, pinned)
+
+	mustHandle("keys/aes/config", map[string]interface{}{"min_encryption_version": 2})
+	mustFail("derive/aes", map[string]interface{}{"key_version": 1})
+	mustFail("derive/aes", map[string]interface{}{"key_version": 3})
+
+	// The output length is bounded by HKDF
+	mustFail("derive/aes", map[string]interface{}{"length": 0})
+	mustFail("derive/aes", map[string]interface{}{"length": 255*32 + 1})
+	derive("derive/aes", map[string]interface{}{"length": 255 * 32})
+
+	mustFail("derive/aes", map[string]interface{}{"salt": "not base64"})
+	mustFail("derive/aes/none", nil)
+	mustFail("derive/aes/md5", nil)
+	mustFail("derive/missing", nil)
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/errutil"
+	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const envelopeVersion = 1
+
+// envelope is the JSON document, base64 encoded, returned by envelope/seal.
+// The payload is encrypted with AES-256-GCM under a data key which is
+// wrapped to each recipient with RSA-OAEP.
+type envelope struct {
+	Version    int                 `json:"version"`
+	Ciphertext []byte              `json:"ciphertext"`
+	Recipients []envelopeRecipient `json:"recipients"`
+}
+
+type envelopeRecipient struct {
+	Name       string `json:"name"`
+	WrappedKey string `json:"wrapped_key"`
+}
+
+func (b *backend) pathEnvelopeSeal() *framework.Path {
+	return &framework.Path{
+		Pattern: "envelope/seal",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "seal",
+			OperationSuffix: "envelope",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"recipients": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Names of the RSA keys to which the data key is wrapped. Keys of different sizes may be mixed.",
+			},
+			"plaintext": {
+				Type:        framework.TypeString,
+				Description: "Base64 encoded plaintext value to be encrypted",
+			},
+			"associated_data": {
+				Type:        framework.TypeString,
+				Description: "Base64 encoded data which is authenticated along with the plaintext but not encrypted, and must be given again to open the envelope.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathEnvelopeSealWrite,
+		},
+
+		HelpSynopsis:    pathEnvelopeSealHelpSyn,
+		HelpDescription: pathEnvelopeSealHelpDesc,
+	}
+}
+
+func (b *backend) pathEnvelopeOpen() *framework.Path {
+	return &framework.Path{
+		Pattern: "envelope/open/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "open",
+			OperationSuffix: "envelope",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the recipient key used to unwrap the data key",
+			},
+			"envelope": {
+				Type:        framework.TypeString,
+				Description: "The envelope returned by envelope/seal",
+			},
+			"associated_data": {
+				Type:        framework.TypeString,
+				Description: "Base64 encoded associated data given when sealing the envelope",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathEnvelopeOpenWrite,
+		},
+
+		HelpSynopsis:    pathEnvelopeOpenHelpSyn,
+		HelpDescription: pathEnvelopeOpenHelpDesc,
+	}
+}
+
+func (b *backend) pathEnvelopeSealWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	recipients := d.Get("recipients").([]string)
+	if len(recipients) == 0 {
+		return logical.ErrorResponse("at least one recipient must be provided"), logical.ErrInvalidRequest
+	}
+	seen := make(map[string]struct{}, len(recipients))
+	for _, name := range recipients {
+		if _, ok := seen[name]; ok {
+			return logical.ErrorResponse("duplicate recipient %q", name), logical.ErrInvalidRequest
+		}
+		seen[name] = struct{}{}
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(d.Get("plaintext").(string))
+	if err != nil {
+		return logical.ErrorResponse("failed to base64-decode plaintext"), logical.ErrInvalidRequest
+	}
+	associatedData, err := base64.StdEncoding.DecodeString(d.Get("associated_data").(string))
+	if err != nil {
+		return logical.ErrorResponse("failed to base64-decode associated_data"), logical.ErrInvalidRequest
+	}
+
+	// Zero out the data key once the payload is encrypted and the key is
+	// wrapped. As with the ephemeral key in import, this isn't a guarantee
+	// against memory analysis.
+	dataKey := make([]byte, 32)
+	defer func() {
+		for i := range dataKey {
+			dataKey[i] = 0
+		}
+	}()
+	if _, err := io.ReadFull(b.GetRandomReader(), dataKey); err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Version:    envelopeVersion,
+		Recipients: make([]envelopeRecipient, 0, len(recipients)),
+	}
+	for _, name := range recipients {
+		wrapped, err := b.wrapEnvelopeKey(ctx, req.Storage, name, dataKey)
+		if err != nil {
+			return respondEnvelopeError(err)
+		}
+		env.Recipients = append(env.Recipients, envelopeRecipient{
+			Name:       name,
+			WrappedKey: wrapped,
+		})
+	}
+
+	aead, err := envelopeAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(b.GetRandomReader(), nonce); err != nil {
+		return nil, err
+	}
+	env.Ciphertext = aead.Seal(nonce, nonce, plaintext, associatedData)
+
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"envelope": base64.StdEncoding.EncodeToString(encoded),
+		},
+	}, nil
+}
+
+func (b *backend) pathEnvelopeOpenWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	encoded, err := base64.StdEncoding.DecodeString(d.Get("envelope").(string))
+	if err != nil {
+		return logical.ErrorResponse("failed to base64-decode envelope"), logical.ErrInvalidRequest
+	}
+	var env envelope
+	if err := json.Unmarshal(encoded, &env); err != nil {
+		return logical.ErrorResponse("failed to parse envelope: %v", err), logical.ErrInvalidRequest
+	}
+	if env.Version != envelopeVersion {
+		return logical.ErrorResponse("unsupported envelope version %d", env.Version), logical.ErrInvalidRequest
+	}
+	associatedData, err := base64.StdEncoding.DecodeString(d.Get("associated_data").(string))
+	if err != nil {
+		return logical.ErrorResponse("failed to base64-decode associated_data"), logical.ErrInvalidRequest
+	}
+
+	var wrapped string
+	for _, recipient := range env.Recipients {
+		if recipient.Name == name {
+			wrapped = recipient.WrappedKey
+			break
+		}
+	}
+	if wrapped == "" {
+		return logical.ErrorResponse("envelope was not sealed to key %q", name), logical.ErrInvalidRequest
+	}
+
+	dataKey, err := b.unwrapEnvelopeKey(ctx, req.Storage, name, wrapped)
+	defer func() {
+		for i := range dataKey {
+			dataKey[i] = 0
+		}
+	}()
+	if err != nil {
+		return respondEnvelopeError(err)
+	}
+	if len(dataKey) != 32 {
+		return logical.ErrorResponse("invalid envelope: unexpected data key length"), logical.ErrInvalidRequest
+	}
+
+	aead, err := envelopeAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Ciphertext) < aead.NonceSize() {
+		return logical.ErrorResponse("invalid envelope: ciphertext too short"), logical.ErrInvalidRequest
+	}
+	nonce, ciphertext := env.Ciphertext[:aead.NonceSize()], env.Ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return logical.ErrorResponse("failed to decrypt envelope: %v", err), logical.ErrInvalidRequest
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+		},
+	}, nil
+}
+
+// wrapEnvelopeKey encrypts the data key with the latest version of the named
+// RSA key. Each recipient is locked in turn rather than all at once.
+func (b *backend) wrapEnvelopeKey(ctx context.Context, s logical.Storage, name string, dataKey []byte) (string, error) {
+	p, err := b.getEnvelopeRecipient(ctx, s, name)
+	if err != nil {
+		return "", err
+	}
+	defer p.Unlock()
+
+	return p.EncryptBytesWithFactory(0, nil, nil, dataKey)
+}
+
+// unwrapEnvelopeKey decrypts the data key with the named RSA key, which must
+// hold the private key of the version the data key was wrapped with.
+func (b *backend) unwrapEnvelopeKey(ctx context.Context, s logical.Storage, name string, wrapped string) ([]byte, error) {
+	p, err := b.getEnvelopeRecipient(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Unlock()
+
+	return p.DecryptBytesWithFactory(nil, nil, wrapped)
+}
+
+// getEnvelopeRecipient returns the named policy, locked for reading, if it is
+// an RSA key.
+func (b *backend) getEnvelopeRecipient(ctx context.Context, s logical.Storage, name string) (*keysutil.Policy, error) {
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: s,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("recipient key %q not found", name)}
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+
+	switch p.Type {
+	case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
+		return p, nil
+	default:
+		p.Unlock()
+		return nil, errutil.UserError{Err: fmt.Sprintf("recipient key %q is of type %v, only RSA keys may be envelope recipients", name, p.Type)}
+	}
+}
+
+func envelopeAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func respondEnvelopeError(err error) (*logical.Response, error) {
+	var userErr errutil.UserError
+	if errors.As(err, &userErr) {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	return nil, err
+}
+
+const pathEnvelopeSealHelpSyn = `Encrypt a value to one or more RSA keys`
+
+const pathEnvelopeSealHelpDesc = `
+This path generates a fresh 256-bit data key, encrypts the given plaintext
+with it using AES-GCM, and wraps the data key to the latest version of each
+of the named RSA keys. The returned envelope can be opened by any of the
+recipients with the envelope/open endpoint, or outside of OpenBao by anyone
+holding one of the private keys. The data key itself is never returned.
+`
+
+const pathEnvelopeOpenHelpSyn = `Decrypt an envelope with one of its recipient keys`
+
+const pathEnvelopeOpenHelpDesc = `
+This path unwraps the data key of an envelope created by envelope/seal with
+the named RSA key, which must be one of its recipients and hold the private
+key of the version the envelope was sealed to, and returns the decrypted
+plaintext.
+`
@@ -0,0 +1,164 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransit_Envelope(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	handle := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	mustHandle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		return resp
+	}
+	mustFail := func(op logical.Operation, path string, data map[string]interface{}) {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected failure, got resp: %#v", resp)
+		}
+	}
+
+	// Recipients may have different key sizes
+	mustHandle(logical.UpdateOperation, "keys/small", map[string]interface{}{
+		"type":       "rsa-2048",
+		"exportable": true,
+	})
+	mustHandle(logical.UpdateOperation, "keys/large", map[string]interface{}{
+		"type": "rsa-4096",
+	})
+	mustHandle(logical.UpdateOperation, "keys/other", map[string]interface{}{
+		"type": "rsa-3072",
+	})
+	mustHandle(logical.UpdateOperation, "keys/symmetric", nil)
+
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+	associatedData := base64.StdEncoding.EncodeToString([]byte("context"))
+
+	mustFail(logical.UpdateOperation, "envelope/seal", map[string]interface{}{
+		"plaintext": plaintext,
+	})
+	mustFail(logical.UpdateOperation, "envelope/seal", map[string]interface{}{
+		"recipients": "small,small",
+		"plaintext":  plaintext,
+	})
+	mustFail(logical.UpdateOperation, "envelope/seal", map[string]interface{}{
+		"recipients": "small,symmetric",
+		"plaintext":  plaintext,
+	})
+	mustFail(logical.UpdateOperation, "envelope/seal", map[string]interface{}{
+		"recipients": "small,missing",
+		"plaintext":  plaintext,
+	})
+
+	resp := mustHandle(logical.UpdateOperation, "envelope/seal", map[string]interface{}{
+		"recipients":      "small,large",
+		"plaintext":       plaintext,
+		"associated_data": associatedData,
+	})
+	sealed := resp.Data["envelope"].(string)
+	require.NotContains(t, resp.Data, "plaintext")
+
+	// Each recipient can open the envelope on its own
+	for _, name := range []string{"small", "large"} {
+		resp = mustHandle(logical.UpdateOperation, "envelope/open/"+name, map[string]interface{}{
+			"envelope":        sealed,
+			"associated_data": associatedData,
+		})
+		require.Equal(t, plaintext, resp.Data["plaintext"])
+	}
+
+	// Other keys and mismatched associated data cannot
+	mustFail(logical.UpdateOperation, "envelope/open/other", map[string]interface{}{
+		"envelope":        sealed,
+		"associated_data": associatedData,
+	})
+	mustFail(logical.UpdateOperation, "envelope/open/small", map[string]interface{}{
+		"envelope": sealed,
+	})
+
+	// A recipient listed under another name cannot unwrap the data key
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	require.NoError(t, err)
+	var env envelope
+	require.NoError(t, json.Unmarshal(raw, &env))
+	require.Len(t, env.Recipients, 2)
+	for i := range env.Recipients {
+		require.True(t, strings.HasPrefix(env.Recipients[i].WrappedKey, "vault:v1:"))
+		env.Recipients[i].Name = "other"
+	}
+	tampered, err := json.Marshal(env)
+	require.NoError(t, err)
+	mustFail(logical.UpdateOperation, "envelope/open/other", map[string]interface{}{
+		"envelope":        base64.StdEncoding.EncodeToString(tampered),
+		"associated_data": associatedData,
+	})
+
+	// The envelope can be opened outside of OpenBao with the private key
+	resp = mustHandle(logical.ReadOperation, "export/encryption-key/small/1", nil)
+	block, _ := pem.Decode([]byte(resp.Data["keys"].(map[string]string)["1"]))
+	require.NotNil(t, block)
+	privKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		require.NoError(t, err)
+		privKey = parsed.(*rsa.PrivateKey)
+	}
+	require.NoError(t, json.Unmarshal(raw, &env))
+	wrapped, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(env.Recipients[0].WrappedKey, "vault:v1:"))
+	require.NoError(t, err)
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), nil, privKey, wrapped, nil)
+	require.NoError(t, err)
+	aesBlock, err := aes.NewCipher(dataKey)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(aesBlock)
+	require.NoError(t, err)
+	opened, err := aead.Open(nil, env.Ciphertext[:aead.NonceSize()], env.Ciphertext[aead.NonceSize():], []byte("context"))
+	require.NoError(t, err)
+	require.Equal(t, "the quick brown fox", string(opened))
+
+	// Envelopes sealed to an older version can still be opened after rotation,
+	// until that version is no longer allowed for decryption
+	mustHandle(logical.UpdateOperation, "keys/small/rotate", nil)
+	resp = mustHandle(logical.UpdateOperation, "envelope/open/small", map[string]interface{}{
+		"envelope":        sealed,
+		"associated_data": associatedData,
+	})
+	require.Equal(t, plaintext, resp.Data["plaintext"])
+	mustHandle(logical.UpdateOperation, "keys/small/config", map[string]interface{}{
+		"min_decryption_version": 2,
+	})
+	mustFail(logical.UpdateOperation, "envelope/open/small", map[string]interface{}{
+		"envelope":        sealed,
+		"associated_data": associatedData,
+	})
+}
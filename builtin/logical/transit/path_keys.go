@@ -110,6 +110,15 @@ key in plaintext format. Once set,
 this cannot be disabled.`,
 			},
 
+			"require_associated_data": {
+				Type: framework.TypeBool,
+				Description: `Requires non-empty associated_data on
+every encrypt and decrypt operation with the
+key, so that ciphertexts cannot be decrypted
+outside of the scope they were encrypted for.
+Only valid for AEAD key types.`,
+			},
+
 			"context": {
 				Type: framework.TypeString,
 				Description: `Base64 encoded context for key derivation.
@@ -242,6 +251,7 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 	keySize := d.Get("key_size").(int)
 	exportable := d.Get("exportable").(bool)
 	allowPlaintextBackup := d.Get("allow_plaintext_backup").(bool)
+	requireAssociatedData := d.Get("require_associated_data").(bool)
 	autoRotatePeriod := time.Second * time.Duration(d.Get("auto_rotate_period").(int))
 
 	if autoRotatePeriod != 0 && autoRotatePeriod < time.Hour {
@@ -253,14 +263,15 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 	}
 
 	polReq := keysutil.PolicyRequest{
-		Upsert:               true,
-		Storage:              req.Storage,
-		Name:                 name,
-		Derived:              derived,
-		Convergent:           convergent,
-		Exportable:           exportable,
-		AllowPlaintextBackup: allowPlaintextBackup,
-		AutoRotatePeriod:     autoRotatePeriod,
+		Upsert:                true,
+		Storage:               req.Storage,
+		Name:                  name,
+		Derived:               derived,
+		Convergent:            convergent,
+		Exportable:            exportable,
+		AllowPlaintextBackup:  allowPlaintextBackup,
+		AutoRotatePeriod:      autoRotatePeriod,
+		RequireAssociatedData: requireAssociatedData,
 	}
 
 	switch keyType {
@@ -300,6 +311,9 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		}
 		polReq.KeySize = keySize
 	}
+	if requireAssociatedData && !polReq.KeyType.AssociatedDataSupported() {
+		return logical.ErrorResponse(fmt.Sprintf("require_associated_data is not valid for algorithm %v", polReq.KeyType)), logical.ErrInvalidRequest
+	}
 
 	p, upserted, err := b.GetPolicy(ctx, polReq, b.GetRandomReader())
 	if err != nil {
@@ -364,23 +378,24 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 	// Return the response
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"name":                   p.Name,
-			"type":                   p.Type.String(),
-			"derived":                p.Derived,
-			"deletion_allowed":       p.DeletionAllowed,
-			"min_available_version":  p.MinAvailableVersion,
-			"min_decryption_version": p.MinDecryptionVersion,
-			"min_encryption_version": p.MinEncryptionVersion,
-			"latest_version":         p.LatestVersion,
-			"exportable":             p.Exportable,
-			"allow_plaintext_backup": p.AllowPlaintextBackup,
-			"supports_encryption":    p.Type.EncryptionSupported(),
-			"supports_decryption":    p.Type.DecryptionSupported(),
-			"supports_signing":       p.Type.SigningSupported(),
-			"supports_derivation":    p.Type.DerivationSupported(),
-			"auto_rotate_period":     int64(p.AutoRotatePeriod.Seconds()),
-			"imported_key":           p.Imported,
-			"soft_deleted":           p.SoftDeleted,
+			"name":                    p.Name,
+			"type":                    p.Type.String(),
+			"derived":                 p.Derived,
+			"deletion_allowed":        p.DeletionAllowed,
+			"min_available_version":   p.MinAvailableVersion,
+			"min_decryption_version":  p.MinDecryptionVersion,
+			"min_encryption_version":  p.MinEncryptionVersion,
+			"latest_version":          p.LatestVersion,
+			"exportable":              p.Exportable,
+			"allow_plaintext_backup":  p.AllowPlaintextBackup,
+			"require_associated_data": p.RequireAssociatedData,
+			"supports_encryption":     p.Type.EncryptionSupported(),
+			"supports_decryption":     p.Type.DecryptionSupported(),
+			"supports_signing":        p.Type.SigningSupported(),
+			"supports_derivation":     p.Type.DerivationSupported(),
+			"auto_rotate_period":      int64(p.AutoRotatePeriod.Seconds()),
+			"imported_key":            p.Imported,
+			"soft_deleted":            p.SoftDeleted,
 		},
 	}
 	if p.KeySize != 0 {
@@ -59,6 +59,11 @@ the latest version of the key is allowed.`,
 				Description: `Enables taking a backup of the named key in plaintext format. Once set, this cannot be disabled.`,
 			},
 
+			"require_associated_data": {
+				Type:        framework.TypeBool,
+				Description: `Whether every encrypt and decrypt operation with the key must supply non-empty associated_data. Only valid for AEAD key types.`,
+			},
+
 			"auto_rotate_period": {
 				Type: framework.TypeDurationSecond,
 				Description: `Amount of time the key should live before
@@ -104,6 +109,7 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 	originalDeletionAllowed := p.DeletionAllowed
 	originalExportable := p.Exportable
 	originalAllowPlaintextBackup := p.AllowPlaintextBackup
+	originalRequireAssociatedData := p.RequireAssociatedData
 
 	defer func() {
 		if retErr != nil || (resp != nil && resp.IsError()) {
@@ -112,6 +118,7 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 			p.DeletionAllowed = originalDeletionAllowed
 			p.Exportable = originalExportable
 			p.AllowPlaintextBackup = originalAllowPlaintextBackup
+			p.RequireAssociatedData = originalRequireAssociatedData
 		}
 	}()
 
@@ -203,6 +210,18 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	requireAssociatedDataRaw, ok := d.GetOk("require_associated_data")
+	if ok {
+		requireAssociatedData := requireAssociatedDataRaw.(bool)
+		if requireAssociatedData && !p.Type.AssociatedDataSupported() {
+			return logical.ErrorResponse(fmt.Sprintf("require_associated_data is not valid for algorithm %v", p.Type)), nil
+		}
+		if requireAssociatedData != p.RequireAssociatedData {
+			p.RequireAssociatedData = requireAssociatedData
+			persistNeeded = true
+		}
+	}
+
 	autoRotatePeriodRaw, ok, err := d.GetOkErr("auto_rotate_period")
 	if err != nil {
 		return nil, err
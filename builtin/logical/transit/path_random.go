@@ -55,12 +55,17 @@ func (b *backend) pathRandom() *framework.Path {
 	}
 }
 
-func (b *backend) pathRandomWrite(_ context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-	return random.HandleRandomAPI(d, b.GetRandomReader())
+func (b *backend) pathRandomWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	platformSource, err := b.getRandomSource(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	return random.HandleRandomAPIWithPlatformSource(d, platformSource, b.GetRandomReader())
 }
 
 const pathRandomHelpSyn = `Generate random bytes`
 
 const pathRandomHelpDesc = `
-This function can be used to generate high-entropy random bytes.
+This function can be used to generate high-entropy random bytes. The bytes of
+the "platform" source may be generated by a DRBG, see the config/random path.
 `
@@ -5,10 +5,14 @@ package transit
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"strings"
 
 	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/shamir"
 	"github.com/openbao/openbao/sdk/v2/logical"
 )
 
@@ -27,6 +31,10 @@ func (b *backend) pathRestore() *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Backed up key data to be restored. This should be the output from the 'backup/' endpoint.",
 			},
+			"shares": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Decrypted, hex-encoded shares of a backup split by the 'backup/<name>/split' endpoint, from which to reconstruct the backup to restore. Mutually exclusive with 'backup'.",
+			},
 			"name": {
 				Type:        framework.TypeString,
 				Description: "If set, this will be the name of the restored key.",
@@ -49,8 +57,18 @@ func (b *backend) pathRestore() *framework.Path {
 
 func (b *backend) pathRestoreUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	backupB64 := d.Get("backup").(string)
+	shares := d.Get("shares").([]string)
 	force := d.Get("force").(bool)
-	if backupB64 == "" {
+	switch {
+	case backupB64 != "" && len(shares) > 0:
+		return logical.ErrorResponse("only one of 'backup' or 'shares' may be supplied"), nil
+	case len(shares) > 0:
+		var err error
+		backupB64, err = combineBackupShares(shares)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	case backupB64 == "":
 		return logical.ErrorResponse("'backup' must be supplied"), nil
 	}
 
@@ -64,9 +82,35 @@ func (b *backend) pathRestoreUpdate(ctx context.Context, req *logical.Request, d
 	return nil, b.lm.RestorePolicy(ctx, req.Storage, keyName, backupB64, force)
 }
 
+// combineBackupShares reconstructs a backup from the shares produced by a
+// split backup. Combining too few or mismatched shares yields garbage rather
+// than an error, which is caught by checking the result is a backup.
+func combineBackupShares(shares []string) (string, error) {
+	parts := make([][]byte, len(shares))
+	for i, share := range shares {
+		part, err := hex.DecodeString(strings.TrimSpace(share))
+		if err != nil {
+			return "", errors.New("shares must be hex-encoded")
+		}
+		parts[i] = part
+	}
+
+	backup, err := shamir.Combine(parts)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(backup))
+	if err != nil || !json.Valid(decoded) {
+		return "", errors.New("failed to reconstruct the backup, not enough or mismatched shares were supplied")
+	}
+	return string(backup), nil
+}
+
 const (
 	pathRestoreHelpSyn  = `Restore the named key`
-	pathRestoreHelpDesc = `This path is used to restore the named key.`
+	pathRestoreHelpDesc = `This path is used to restore the named key, from either a backup or the
+shares of a split backup.`
 )
 
 var ErrInvalidKeyName = errors.New("key names cannot be paths")
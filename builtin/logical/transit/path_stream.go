@@ -0,0 +1,377 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/errutil"
+	"github.com/openbao/openbao/sdk/v2/helper/keysutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	// streamHandlePrefix prefixes every stream handle, and identifies the
+	// version of the stream format.
+	streamHandlePrefix = "bao:stream:v1:"
+
+	// streamIDSize is the length in bytes of the random stream ID.
+	streamIDSize = 16
+
+	// maxStreamChunkSize bounds the decoded size of a single chunk, so that
+	// each request stays well within the default request size limit.
+	maxStreamChunkSize = 16 * 1024 * 1024
+)
+
+// streamAssocData supplies the associated data binding a chunk to its
+// stream and position.
+type streamAssocData []byte
+
+func (s streamAssocData) GetAssociatedData() ([]byte, error) {
+	return s, nil
+}
+
+// formatStreamHandle returns the handle for the stream with the given key
+// version and ID.
+func formatStreamHandle(ver int, id []byte) string {
+	return streamHandlePrefix + strconv.Itoa(ver) + ":" + base64.RawURLEncoding.EncodeToString(id)
+}
+
+// parseStreamHandle returns the key version and ID of the stream handle.
+func parseStreamHandle(handle string) (int, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(handle, streamHandlePrefix), ":")
+	if !strings.HasPrefix(handle, streamHandlePrefix) || len(parts) != 2 {
+		return 0, nil, fmt.Errorf("invalid stream handle")
+	}
+	ver, err := strconv.Atoi(parts[0])
+	if err != nil || ver <= 0 {
+		return 0, nil, fmt.Errorf("invalid stream handle: bad key version")
+	}
+	id, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(id) != streamIDSize {
+		return 0, nil, fmt.Errorf("invalid stream handle: bad stream ID")
+	}
+	return ver, id, nil
+}
+
+// streamAAD returns the AEAD additional data of a chunk: a domain separator,
+// the stream ID, key version, chunk index and whether it is the final chunk,
+// followed by any associated data given by the caller. Binding the index
+// and final flag prevents chunks from being reordered, dropped, spliced
+// between streams or the stream from being truncated.
+func streamAAD(id []byte, ver int, index int64, final bool, associated []byte) []byte {
+	aad := make([]byte, 0, len(streamHandlePrefix)+len(id)+4+8+1+len(associated))
+	aad = append(aad, streamHandlePrefix...)
+	aad = append(aad, id...)
+	aad = binary.BigEndian.AppendUint32(aad, uint32(ver))
+	aad = binary.BigEndian.AppendUint64(aad, uint64(index))
+	if final {
+		aad = append(aad, 1)
+	} else {
+		aad = append(aad, 0)
+	}
+	return append(aad, associated...)
+}
+
+func (b *backend) pathStreamInit() *framework.Path {
+	return &framework.Path{
+		Pattern: "stream/init/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "initialize",
+			OperationSuffix: "stream",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key",
+			},
+
+			"key_version": {
+				Type: framework.TypeInt,
+				Description: `The version of the key to use for every chunk
+of the stream. Must be 0 (for latest) or a value
+greater than or equal to the min_encryption_version
+configured on the key.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathStreamInitWrite,
+		},
+
+		HelpSynopsis:    pathStreamInitHelpSyn,
+		HelpDescription: pathStreamHelpDesc,
+	}
+}
+
+func (b *backend) pathStreamEncrypt() *framework.Path {
+	return &framework.Path{
+		Pattern: "stream/encrypt/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "encrypt",
+			OperationSuffix: "stream-chunk",
+		},
+
+		Fields: streamChunkFields("plaintext", "Base64 encoded plaintext of the chunk to encrypt"),
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathStreamChunkWrite(true),
+		},
+
+		HelpSynopsis:    pathStreamEncryptHelpSyn,
+		HelpDescription: pathStreamHelpDesc,
+	}
+}
+
+func (b *backend) pathStreamDecrypt() *framework.Path {
+	return &framework.Path{
+		Pattern: "stream/decrypt/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "decrypt",
+			OperationSuffix: "stream-chunk",
+		},
+
+		Fields: streamChunkFields("ciphertext", "Ciphertext of the chunk to decrypt"),
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathStreamChunkWrite(false),
+		},
+
+		HelpSynopsis:    pathStreamDecryptHelpSyn,
+		HelpDescription: pathStreamHelpDesc,
+	}
+}
+
+// streamChunkFields returns the fields of the chunk encrypt and decrypt
+// paths, which differ only in their input.
+func streamChunkFields(input, description string) map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"name": {
+			Type:        framework.TypeString,
+			Description: "Name of the key",
+		},
+
+		"stream": {
+			Type:        framework.TypeString,
+			Description: "The stream handle returned by stream/init",
+		},
+
+		"index": {
+			Type:        framework.TypeInt64,
+			Description: "The position of the chunk within the stream, starting at 0",
+		},
+
+		"final": {
+			Type:        framework.TypeBool,
+			Description: "Whether this is the last chunk of the stream",
+		},
+
+		input: {
+			Type:        framework.TypeString,
+			Description: description,
+		},
+
+		"context": {
+			Type:        framework.TypeString,
+			Description: "Base64 encoded context for key derivation. Required for derived keys, and must be the same for every chunk.",
+		},
+
+		"associated_data": {
+			Type: framework.TypeString,
+			Description: `Base64 encoded associated data to authenticate along
+with the chunk. It must be given again to decrypt the chunk.`,
+		},
+	}
+}
+
+func (b *backend) pathStreamInitWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	ver := d.Get("key_version").(int)
+
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+	defer p.Unlock()
+
+	switch {
+	case p.SoftDeleted:
+		return logical.ErrorResponse(keysutil.ErrSoftDeleted), logical.ErrInvalidRequest
+	case !p.Type.AssociatedDataSupported():
+		return logical.ErrorResponse(fmt.Sprintf("streaming is not supported for key type %v", p.Type)), logical.ErrInvalidRequest
+	case p.ConvergentEncryption:
+		// Convergent nonces depend only on the plaintext, so identical
+		// chunks would reuse a nonce with different additional data
+		return logical.ErrorResponse("streaming is not supported for convergent keys"), logical.ErrInvalidRequest
+	}
+
+	// Pin the version now, so that rotating the key mid-stream does not
+	// change the key of later chunks
+	switch {
+	case ver == 0:
+		ver = p.LatestVersion
+	case ver < 0:
+		return logical.ErrorResponse("requested version for encryption is negative"), logical.ErrInvalidRequest
+	case ver > p.LatestVersion:
+		return logical.ErrorResponse("requested version for encryption is higher than the latest key version"), logical.ErrInvalidRequest
+	case ver < p.MinEncryptionVersion:
+		return logical.ErrorResponse("requested version for encryption is less than the minimum encryption key version"), logical.ErrInvalidRequest
+	}
+
+	id := make([]byte, streamIDSize)
+	if _, err := io.ReadFull(b.GetRandomReader(), id); err != nil {
+		return nil, fmt.Errorf("failed to generate stream ID: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"stream":      formatStreamHandle(ver, id),
+			"key_version": ver,
+		},
+	}, nil
+}
+
+func (b *backend) pathStreamChunkWrite(encrypt bool) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		index := d.Get("index").(int64)
+		final := d.Get("final").(bool)
+
+		ver, id, err := parseStreamHandle(d.Get("stream").(string))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		if index < 0 {
+			return logical.ErrorResponse("chunk index cannot be negative"), logical.ErrInvalidRequest
+		}
+
+		var keyContext []byte
+		if contextRaw := d.Get("context").(string); len(contextRaw) != 0 {
+			keyContext, err = base64.StdEncoding.DecodeString(contextRaw)
+			if err != nil {
+				return logical.ErrorResponse("failed to base64-decode context"), logical.ErrInvalidRequest
+			}
+		}
+		var associated []byte
+		if associatedRaw := d.Get("associated_data").(string); len(associatedRaw) != 0 {
+			associated, err = base64.StdEncoding.DecodeString(associatedRaw)
+			if err != nil {
+				return logical.ErrorResponse("failed to base64-decode associated_data"), logical.ErrInvalidRequest
+			}
+		}
+
+		p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+			Storage: req.Storage,
+			Name:    name,
+		}, b.GetRandomReader())
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
+		}
+		if !b.System().CachingDisabled() {
+			p.Lock(false)
+		}
+		defer p.Unlock()
+
+		if !p.Type.AssociatedDataSupported() || p.ConvergentEncryption {
+			return logical.ErrorResponse("streaming is not supported for this key"), logical.ErrInvalidRequest
+		}
+		// The stream binding always makes the additional data non-empty, so
+		// the key's requirement is checked against the caller's part
+		if p.RequireAssociatedData && len(associated) == 0 {
+			return logical.ErrorResponse(keysutil.ErrAssociatedDataRequired), logical.ErrInvalidRequest
+		}
+		factory := streamAssocData(streamAAD(id, ver, index, final, associated))
+
+		var resp *logical.Response
+		if encrypt {
+			plaintext := d.Get("plaintext").(string)
+			if base64.StdEncoding.DecodedLen(len(plaintext)) > maxStreamChunkSize {
+				return logical.ErrorResponse(fmt.Sprintf("chunk is larger than the maximum of %d bytes", maxStreamChunkSize)), logical.ErrInvalidRequest
+			}
+
+			ciphertext, err := p.EncryptWithFactory(ver, keyContext, nil, plaintext, factory)
+			if err != nil {
+				return streamError(err)
+			}
+			resp = &logical.Response{
+				Data: map[string]interface{}{
+					"ciphertext": ciphertext,
+				},
+			}
+		} else {
+			ciphertext := d.Get("ciphertext").(string)
+			if ciphertext == "" {
+				return logical.ErrorResponse("missing ciphertext to decrypt"), logical.ErrInvalidRequest
+			}
+
+			plaintext, err := p.DecryptWithFactory(keyContext, nil, ciphertext, factory)
+			if err != nil {
+				return streamError(err)
+			}
+			resp = &logical.Response{
+				Data: map[string]interface{}{
+					"plaintext": plaintext,
+				},
+			}
+		}
+		return resp, nil
+	}
+}
+
+// streamError converts an error encrypting or decrypting a chunk into a
+// response.
+func streamError(err error) (*logical.Response, error) {
+	switch err.(type) {
+	case errutil.UserError:
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	default:
+		return nil, err
+	}
+}
+
+const pathStreamInitHelpSyn = `Start encrypting a stream with a named key`
+
+const pathStreamEncryptHelpSyn = `Encrypt a chunk of a stream with a named key`
+
+const pathStreamDecryptHelpSyn = `Decrypt a chunk of a stream with a named key`
+
+const pathStreamHelpDesc = `
+These paths encrypt and decrypt payloads too large for a single request,
+one chunk at a time. stream/init returns a handle which pins the key
+version; every chunk is then sealed independently by stream/encrypt with
+the handle, the index of the chunk and whether it is the final one bound
+into its additional data, so that chunks cannot be reordered, dropped or
+moved between streams, nor the stream truncated. stream/decrypt reverses
+this given the same handle, index and final flag.
+
+No state is kept between requests, so an aborted stream needs no cleanup.
+The handle must be stored along with the chunks, and a stream is only
+complete once its final chunk has been decrypted.
+`
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransit_Stream(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+	ctx := context.Background()
+
+	request := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+	}
+	initStream := func(ver int) string {
+		t.Helper()
+		resp, err := request("stream/init/foo", nil)
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%#v", resp)
+		require.Equal(t, ver, resp.Data["key_version"])
+		return resp.Data["stream"].(string)
+	}
+	encrypt := func(stream string, index int, final bool, chunk string) string {
+		t.Helper()
+		resp, err := request("stream/encrypt/foo", map[string]interface{}{
+			"stream":    stream,
+			"index":     index,
+			"final":     final,
+			"plaintext": base64.StdEncoding.EncodeToString([]byte(chunk)),
+		})
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%#v", resp)
+		return resp.Data["ciphertext"].(string)
+	}
+	decrypt := func(stream string, index int, final bool, ciphertext string) (string, error) {
+		resp, err := request("stream/decrypt/foo", map[string]interface{}{
+			"stream":     stream,
+			"index":      index,
+			"final":      final,
+			"ciphertext": ciphertext,
+		})
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := base64.StdEncoding.DecodeString(resp.Data["plaintext"].(string))
+		require.NoError(t, err)
+		return string(plaintext), nil
+	}
+
+	_, err := request("keys/foo", nil)
+	require.NoError(t, err)
+
+	// Rotating the key mid-stream leaves the version pinned at init
+	stream := initStream(1)
+	chunks := []string{"the quick ", "brown fox ", "jumps"}
+	var sealed []string
+	for i, chunk := range chunks {
+		if i == 1 {
+			_, err := request("keys/foo/rotate", nil)
+			require.NoError(t, err)
+		}
+		ciphertext := encrypt(stream, i, i == len(chunks)-1, chunk)
+		require.True(t, strings.HasPrefix(ciphertext, "vault:v1:"), ciphertext)
+		sealed = append(sealed, ciphertext)
+	}
+
+	var plaintext string
+	for i, ciphertext := range sealed {
+		chunk, err := decrypt(stream, i, i == len(sealed)-1, ciphertext)
+		require.NoError(t, err)
+		plaintext += chunk
+	}
+	require.Equal(t, "the quick brown fox jumps", plaintext)
+
+	// Reordered, truncated and spliced streams fail to decrypt
+	_, err = decrypt(stream, 0, false, sealed[1])
+	require.Error(t, err)
+	_, err = decrypt(stream, 1, true, sealed[1])
+	require.Error(t, err)
+	_, err = decrypt(initStream(2), 0, false, sealed[0])
+	require.Error(t, err)
+
+	// As do chunks given the wrong associated data
+	resp, err := request("stream/encrypt/foo", map[string]interface{}{
+		"stream":          stream,
+		"plaintext":       base64.StdEncoding.EncodeToString([]byte("scoped")),
+		"associated_data": base64.StdEncoding.EncodeToString([]byte("scope")),
+	})
+	require.NoError(t, err)
+	_, err = decrypt(stream, 0, false, resp.Data["ciphertext"].(string))
+	require.Error(t, err)
+
+	// Invalid handles are rejected
+	_, err = request("stream/encrypt/foo", map[string]interface{}{
+		"stream":    "bao:stream:v1:1:bad",
+		"plaintext": base64.StdEncoding.EncodeToString([]byte("chunk")),
+	})
+	require.Error(t, err)
+
+	// Keys which cannot be streamed are rejected at init
+	_, err = request("keys/rsa", map[string]interface{}{"type": "rsa-2048"})
+	require.NoError(t, err)
+	_, err = request("stream/init/rsa", nil)
+	require.Error(t, err)
+
+	_, err = request("keys/convergent", map[string]interface{}{
+		"derived":               true,
+		"convergent_encryption": true,
+	})
+	require.NoError(t, err)
+	_, err = request("stream/init/convergent", nil)
+	require.Error(t, err)
+}
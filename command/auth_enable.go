@@ -33,10 +33,12 @@ type AuthEnableCommand struct {
 	flagPluginName                string
 	flagPassthroughRequestHeaders []string
 	flagAllowedResponseHeaders    []string
+	flagRequestTimeout            time.Duration
 	flagOptions                   map[string]string
 	flagLocal                     bool
 	flagSealWrap                  bool
 	flagExternalEntropyAccess     bool
+	flagDedicatedStorageKey       bool
 	flagTokenType                 string
 	flagVersion                   int
 	flagPluginVersion             string
@@ -151,6 +153,14 @@ func (c *AuthEnableCommand) Flags() *FlagSets {
 			"values, specify this flag multiple times.",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       flagNameRequestTimeout,
+		Target:     &c.flagRequestTimeout,
+		Completion: complete.PredictAnything,
+		Usage: "The maximum duration of requests to this auth method, after which " +
+			"they are aborted. A value of 0 disables the timeout.",
+	})
+
 	f.StringVar(&StringVar{
 		Name:       "plugin-name",
 		Target:     &c.flagPluginName,
@@ -189,6 +199,15 @@ func (c *AuthEnableCommand) Flags() *FlagSets {
 		Usage:   "Enable auth method to access OpenBao's external entropy source.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:    "dedicated-storage-key",
+		Target:  &c.flagDedicatedStorageKey,
+		Default: false,
+		Usage: "Encrypt the data of the auth method with a key dedicated to it. " +
+			"The key is destroyed when the auth method is disabled, making any " +
+			"leftover data unreadable.",
+	})
+
 	f.StringVar(&StringVar{
 		Name:   flagNameTokenType,
 		Target: &c.flagTokenType,
@@ -276,6 +295,7 @@ func (c *AuthEnableCommand) Run(args []string) int {
 		Local:                 c.flagLocal,
 		SealWrap:              c.flagSealWrap,
 		ExternalEntropyAccess: c.flagExternalEntropyAccess,
+		DedicatedStorageKey:   c.flagDedicatedStorageKey,
 		Config: api.AuthConfigInput{
 			DefaultLeaseTTL: c.flagDefaultLeaseTTL.String(),
 			MaxLeaseTTL:     c.flagMaxLeaseTTL.String(),
@@ -305,6 +325,10 @@ func (c *AuthEnableCommand) Run(args []string) int {
 			authOpts.Config.AllowedResponseHeaders = c.flagAllowedResponseHeaders
 		}
 
+		if fl.Name == flagNameRequestTimeout {
+			authOpts.Config.RequestTimeout = c.flagRequestTimeout.String()
+		}
+
 		if fl.Name == flagNameTokenType {
 			authOpts.Config.TokenType = c.flagTokenType
 		}
@@ -31,6 +31,7 @@ type AuthTuneCommand struct {
 	flagMaxLeaseTTL                     time.Duration
 	flagPassthroughRequestHeaders       []string
 	flagAllowedResponseHeaders          []string
+	flagRequestTimeout                  time.Duration
 	flagOptions                         map[string]string
 	flagTokenType                       string
 	flagVersion                         int
@@ -131,6 +132,14 @@ func (c *AuthTuneCommand) Flags() *FlagSets {
 			"multiple values, specify this flag multiple times.",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       flagNameRequestTimeout,
+		Target:     &c.flagRequestTimeout,
+		Completion: complete.PredictAnything,
+		Usage: "The maximum duration of requests to this auth method, after which " +
+			"they are aborted. A value of 0 disables the timeout.",
+	})
+
 	f.StringMapVar(&StringMapVar{
 		Name:       "options",
 		Target:     &c.flagOptions,
@@ -269,6 +278,10 @@ func (c *AuthTuneCommand) Run(args []string) int {
 			mountConfigInput.AllowedResponseHeaders = c.flagAllowedResponseHeaders
 		}
 
+		if fl.Name == flagNameRequestTimeout {
+			mountConfigInput.RequestTimeout = c.flagRequestTimeout.String()
+		}
+
 		if fl.Name == flagNameTokenType {
 			mountConfigInput.TokenType = c.flagTokenType
 		}
@@ -92,6 +92,7 @@ func (b *BaseCommand) PredictVaultAvailableMounts() complete.Predictor {
 		"plugin",
 		"rabbitmq",
 		"ssh",
+		"sts",
 		"totp",
 		"transit",
 	)
@@ -114,6 +115,7 @@ func (b *BaseCommand) PredictVaultAvailableAuths() complete.Predictor {
 		"plugin",
 		"radius",
 		"userpass",
+		"webauthn",
 	)
 }
 
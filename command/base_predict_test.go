@@ -365,9 +365,11 @@ func TestPredict_Plugins(t *testing.T) {
 				"rabbitmq",
 				"radius",
 				"ssh",
+				"sts",
 				"totp",
 				"transit",
 				"userpass",
+				"webauthn",
 			},
 		},
 	}
@@ -91,6 +91,8 @@ const (
 	flagNamePassthroughRequestHeaders = "passthrough-request-headers"
 	// flagNameAllowedResponseHeaders is used to set allowed response headers from a plugin
 	flagNameAllowedResponseHeaders = "allowed-response-headers"
+	// flagNameRequestTimeout is the flag name used to bound the duration of requests to a mount
+	flagNameRequestTimeout = "request-timeout"
 	// flagNameTokenType is the flag name used to force a specific token type
 	flagNameTokenType = "token-type"
 	// flagNameAllowedManagedKeys is the flag name used for auth/secrets enable
@@ -13,6 +13,8 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/openbao/openbao/api/v2"
+	"github.com/openbao/openbao/helper/builtinplugins"
+	"github.com/openbao/openbao/vault"
 )
 
 func testKVPutCommand(tb testing.TB) (*cli.MockUi, *KVPutCommand) {
@@ -1358,7 +1360,16 @@ func TestKVPatchCommand_403Fallback(t *testing.T) {
 
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			client, closer := testVaultServer(t)
+			// The update capability only grants patch outside of strict mode
+			client, _, closer := testVaultServerCoreConfig(t, &vault.CoreConfig{
+				DisableCache:          true,
+				Logger:                defaultVaultLogger,
+				CredentialBackends:    defaultVaultCredentialBackends,
+				AuditBackends:         defaultVaultAuditBackends,
+				LogicalBackends:       defaultVaultLogicalBackends,
+				BuiltinRegistry:       builtinplugins.Registry,
+				StrictPatchCapability: true,
+			})
 			defer closer()
 
 			if err := client.Sys().Mount("kv/", &api.MountInput{
@@ -6,6 +6,7 @@ package command
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/openbao/openbao/api/v2"
@@ -19,9 +20,10 @@ var (
 
 type PluginReloadCommand struct {
 	*BaseCommand
-	plugin string
-	mounts []string
-	scope  string
+	plugin       string
+	mounts       []string
+	scope        string
+	drainTimeout time.Duration
 }
 
 func (c *PluginReloadCommand) Synopsis() string {
@@ -71,6 +73,15 @@ func (c *PluginReloadCommand) Flags() *FlagSets {
 		Usage:      "The scope of the reload, omitted for local, 'global', for replicated reloads",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       "drain-timeout",
+		Target:     &c.drainTimeout,
+		Completion: complete.PredictAnything,
+		Usage: "Reload gracefully, waiting up to this duration for in-flight " +
+			"requests to complete before aborting them. The current backends " +
+			"keep serving if the new ones fail to start.",
+	})
+
 	return set
 }
 
@@ -107,11 +118,16 @@ func (c *PluginReloadCommand) Run(args []string) int {
 		return 2
 	}
 
-	rid, err := client.Sys().ReloadPlugin(&api.ReloadPluginInput{
+	input := &api.ReloadPluginInput{
 		Plugin: c.plugin,
 		Mounts: c.mounts,
 		Scope:  c.scope,
-	})
+	}
+	if c.drainTimeout > 0 {
+		input.DrainTimeout = c.drainTimeout.String()
+	}
+
+	rid, err := client.Sys().ReloadPlugin(input)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error reloading plugin/mounts: %s", err))
 		return 2
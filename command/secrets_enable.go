@@ -32,6 +32,7 @@ type SecretsEnableCommand struct {
 	flagListingVisibility         string
 	flagPassthroughRequestHeaders []string
 	flagAllowedResponseHeaders    []string
+	flagRequestTimeout            time.Duration
 	flagForceNoCache              bool
 	flagPluginName                string
 	flagPluginVersion             string
@@ -39,6 +40,7 @@ type SecretsEnableCommand struct {
 	flagLocal                     bool
 	flagSealWrap                  bool
 	flagExternalEntropyAccess     bool
+	flagDedicatedStorageKey       bool
 	flagVersion                   int
 	flagAllowedManagedKeys        []string
 }
@@ -159,6 +161,14 @@ func (c *SecretsEnableCommand) Flags() *FlagSets {
 			"values, specify this flag multiple times.",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       flagNameRequestTimeout,
+		Target:     &c.flagRequestTimeout,
+		Completion: complete.PredictAnything,
+		Usage: "The maximum duration of requests to this secrets engine, after which " +
+			"they are aborted. A value of 0 disables the timeout.",
+	})
+
 	f.BoolVar(&BoolVar{
 		Name:    "force-no-cache",
 		Target:  &c.flagForceNoCache,
@@ -213,6 +223,15 @@ func (c *SecretsEnableCommand) Flags() *FlagSets {
 		Usage:   "Enable secrets engine to access Vault's external entropy source.",
 	})
 
+	f.BoolVar(&BoolVar{
+		Name:    "dedicated-storage-key",
+		Target:  &c.flagDedicatedStorageKey,
+		Default: false,
+		Usage: "Encrypt the data of the secrets engine with a key dedicated to it. " +
+			"The key is destroyed when the secrets engine is disabled, making any " +
+			"leftover data unreadable.",
+	})
+
 	f.IntVar(&IntVar{
 		Name:    "version",
 		Target:  &c.flagVersion,
@@ -297,6 +316,7 @@ func (c *SecretsEnableCommand) Run(args []string) int {
 		Local:                 c.flagLocal,
 		SealWrap:              c.flagSealWrap,
 		ExternalEntropyAccess: c.flagExternalEntropyAccess,
+		DedicatedStorageKey:   c.flagDedicatedStorageKey,
 		Config: api.MountConfigInput{
 			DefaultLeaseTTL: c.flagDefaultLeaseTTL.String(),
 			MaxLeaseTTL:     c.flagMaxLeaseTTL.String(),
@@ -327,6 +347,10 @@ func (c *SecretsEnableCommand) Run(args []string) int {
 			mountInput.Config.AllowedResponseHeaders = c.flagAllowedResponseHeaders
 		}
 
+		if fl.Name == flagNameRequestTimeout {
+			mountInput.Config.RequestTimeout = c.flagRequestTimeout.String()
+		}
+
 		if fl.Name == flagNameAllowedManagedKeys {
 			mountInput.Config.AllowedManagedKeys = c.flagAllowedManagedKeys
 		}
@@ -31,6 +31,7 @@ type SecretsTuneCommand struct {
 	flagMaxLeaseTTL               time.Duration
 	flagPassthroughRequestHeaders []string
 	flagAllowedResponseHeaders    []string
+	flagRequestTimeout            time.Duration
 	flagOptions                   map[string]string
 	flagVersion                   int
 	flagPluginVersion             string
@@ -127,6 +128,14 @@ func (c *SecretsTuneCommand) Flags() *FlagSets {
 			"specify multiple values, specify this flag multiple times.",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       flagNameRequestTimeout,
+		Target:     &c.flagRequestTimeout,
+		Completion: complete.PredictAnything,
+		Usage: "The maximum duration of requests to this secrets engine, after which " +
+			"they are aborted. A value of 0 disables the timeout.",
+	})
+
 	f.StringMapVar(&StringMapVar{
 		Name:       "options",
 		Target:     &c.flagOptions,
@@ -235,6 +244,10 @@ func (c *SecretsTuneCommand) Run(args []string) int {
 			mountConfigInput.AllowedResponseHeaders = c.flagAllowedResponseHeaders
 		}
 
+		if fl.Name == flagNameRequestTimeout {
+			mountConfigInput.RequestTimeout = c.flagRequestTimeout.String()
+		}
+
 		if fl.Name == flagNameAllowedManagedKeys {
 			mountConfigInput.AllowedManagedKeys = c.flagAllowedManagedKeys
 		}
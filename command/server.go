@@ -762,6 +762,42 @@ func (c *ServerCommand) setupStorage(config *server.Config) (physical.Backend, e
 	return backend, nil
 }
 
+// setupStorageMigration initializes the storage migration target, and
+// returns the backend migrating data to it online.
+func (c *ServerCommand) setupStorageMigration(config *server.Config, backend physical.Backend) (*physical.Migrator, error) {
+	target := config.StorageMigrationTarget
+	if config.Storage.Type == storageTypeRaft || target.Type == storageTypeRaft {
+		return nil, errors.New("Online storage migration does not support raft storage; use 'bao operator migrate' instead")
+	}
+	if config.HAStorage != nil {
+		return nil, errors.New("Online storage migration must run on a single node and cannot be used with HA storage")
+	}
+
+	factory, exists := c.PhysicalBackends[target.Type]
+	if !exists {
+		return nil, fmt.Errorf("Unknown storage migration target type %s", target.Type)
+	}
+
+	namedTargetLogger := c.logger.Named("storage." + target.Type)
+	c.allLoggers = append(c.allLoggers, namedTargetLogger)
+	targetBackend, err := factory(target.Config, namedTargetLogger)
+	if err != nil {
+		return nil, fmt.Errorf("Error initializing storage migration target of type %s: %w", target.Type, err)
+	}
+	if config.StorageChecksums {
+		targetBackend = physical.NewChecksummer(targetBackend)
+	}
+
+	namedMigrationLogger := c.logger.Named("storage.migration")
+	c.allLoggers = append(c.allLoggers, namedMigrationLogger)
+	migrator, err := physical.NewMigrator(context.Background(), backend, targetBackend, namedMigrationLogger)
+	if err != nil {
+		return nil, fmt.Errorf("Error initializing storage migration to %s: %w", target.Type, err)
+	}
+
+	return migrator, nil
+}
+
 func beginServiceRegistration(c *ServerCommand, config *server.Config) (sr.ServiceRegistration, error) {
 	sdFactory, ok := c.ServiceRegistrations[config.ServiceRegistration.Type]
 	if !ok {
@@ -1090,6 +1126,65 @@ func (c *ServerCommand) Run(args []string) int {
 		if c.storageMigrationActive(backend) {
 			return 1
 		}
+
+		if config.StorageChecksums {
+			backend = physical.NewChecksummer(backend)
+		}
+
+		if config.StorageMigrationTarget != nil {
+			migrator, err := c.setupStorageMigration(config, backend)
+			if err != nil {
+				c.UI.Error(err.Error())
+				return 1
+			}
+			migrator.Start()
+			defer migrator.Stop()
+			backend = migrator
+		}
+
+		if config.StorageFaultInjection != nil {
+			if config.Storage.Type == storageTypeRaft {
+				c.UI.Error("Storage fault injection does not support raft storage")
+				return 1
+			}
+			namedFaultLogger := c.logger.Named("storage.faults")
+			c.allLoggers = append(c.allLoggers, namedFaultLogger)
+			faultInjector, err := physical.NewFaultInjector(backend, config.StorageFaultInjection, namedFaultLogger)
+			if err != nil {
+				if errors.Is(err, physical.ErrFaultInjectionDisabled) {
+					err = fmt.Errorf("%w; set allow_unsafe to enable it anyway, never in production", err)
+				}
+				c.UI.Error(fmt.Sprintf("Error initializing storage fault injection: %s", err))
+				return 1
+			}
+			backend = faultInjector
+		}
+
+		if config.StorageInFlight != nil {
+			if config.Storage.Type == storageTypeRaft {
+				c.UI.Error("Storage in-flight tracking does not support raft storage")
+				return 1
+			}
+			tracker, err := physical.NewInFlightTracker(backend, *config.StorageInFlight, metricSink.Sink)
+			if err != nil {
+				c.UI.Error(fmt.Sprintf("Error initializing storage in-flight tracking: %s", err))
+				return 1
+			}
+			backend = tracker
+		}
+
+		if config.StorageRateLimit != nil {
+			if config.Storage.Type == storageTypeRaft {
+				c.UI.Error("Storage rate limiting does not support raft storage")
+				return 1
+			}
+			rateLimiter, err := physical.NewRateLimiter(backend, *config.StorageRateLimit, metricSink.Sink)
+			if err != nil {
+				c.UI.Error(fmt.Sprintf("Error initializing storage rate limiting: %s", err))
+				return 1
+			}
+			backend = rateLimiter
+		}
 	}
 
 	// Initialize the Service Discovery, if there is one
@@ -1256,6 +1351,10 @@ func (c *ServerCommand) Run(args []string) int {
 		infoKeys = append(infoKeys, "api address")
 	}
 
+	if config.StorageMigrationTarget != nil {
+		info["storage migration target"] = config.StorageMigrationTarget.Type
+		infoKeys = append(infoKeys, "storage migration target")
+	}
 	if config.HAStorage != nil {
 		info["HA storage"] = config.HAStorage.Type
 		infoKeys = append(infoKeys, "HA storage")
@@ -1518,6 +1617,9 @@ func (c *ServerCommand) Run(args []string) int {
 			// Setting log request with the new value in the config after reload
 			core.ReloadLogRequestsLevel()
 
+			// Warm the cache with the keys of the new preload manifest
+			core.ReloadCachePreload()
+
 			// Reload log level for loggers
 			if config.LogLevel != "" {
 				level, err := loghelper.ParseLogLevel(config.LogLevel)
@@ -2318,6 +2420,20 @@ func setSeal(c *ServerCommand, config *server.Config, infoKeys []string, info ma
 			config.Seals = append(config.Seals, &configutil.KMS{Type: wrapping.WrapperTypeShamir.String()})
 		}
 	}
+
+	// Several enabled seals make up a single one, which any of them can
+	// unseal
+	var enabledSeals int
+	for _, configSeal := range config.Seals {
+		if !configSeal.Disabled {
+			enabledSeals++
+		}
+	}
+	if enabledSeals > configutil.MaxSealProviders {
+		return nil, nil, nil, nil, nil, fmt.Errorf("Error parsing Seal configuration: at most %d seals may be enabled", configutil.MaxSealProviders)
+	}
+	var providers []vaultseal.MultiWrapperProvider
+
 	var createdSeals []vault.Seal = make([]vault.Seal, len(config.Seals))
 	for _, configSeal := range config.Seals {
 		sealType := wrapping.WrapperTypeShamir.String()
@@ -2341,6 +2457,28 @@ func setSeal(c *ServerCommand, config *server.Config, infoKeys []string, info ma
 					"Error parsing Seal configuration: %s", sealConfigError)
 			}
 		}
+
+		infoPrefix := ""
+		if !configSeal.Disabled && enabledSeals > 1 {
+			if wrapper == nil {
+				return nil, nil, nil, nil, nil, fmt.Errorf("Error parsing Seal configuration: %s seals cannot be combined with others", sealType)
+			}
+
+			name := configSeal.Name
+			if name == "" {
+				name = configSeal.Type
+			}
+			providers = append(providers, vaultseal.MultiWrapperProvider{
+				Name:    name,
+				Wrapper: wrapper,
+			})
+			for _, k := range sealInfoKeys {
+				infoKeys = append(infoKeys, name+" "+k)
+				info[name+" "+k] = sealInfoMap[k]
+			}
+			continue
+		}
+
 		if wrapper == nil {
 			seal = defaultSeal
 		} else {
@@ -2350,7 +2488,6 @@ func setSeal(c *ServerCommand, config *server.Config, infoKeys []string, info ma
 				return nil, nil, nil, nil, nil, err
 			}
 		}
-		infoPrefix := ""
 		if configSeal.Disabled {
 			unwrapSeal = seal
 			infoPrefix = "Old "
@@ -2364,6 +2501,23 @@ func setSeal(c *ServerCommand, config *server.Config, infoKeys []string, info ma
 		}
 		createdSeals = append(createdSeals, seal)
 	}
+
+	if len(providers) > 0 {
+		sealLogger := c.logger.ResetNamed(fmt.Sprintf("seal.%s", vaultseal.WrapperTypeMulti))
+		c.allLoggers = append(c.allLoggers, sealLogger)
+		multiWrapper, err := vaultseal.NewMultiWrapper(sealLogger, providers...)
+		if err != nil {
+			return nil, nil, nil, nil, nil, fmt.Errorf("Error parsing Seal configuration: %s", err)
+		}
+		barrierSeal, err = vault.NewAutoSeal(vaultseal.NewAccess(multiWrapper))
+		if err != nil {
+			return nil, nil, nil, nil, nil, err
+		}
+		barrierWrapper = multiWrapper
+		infoKeys = append(infoKeys, "Seal Providers")
+		info["Seal Providers"] = strings.Join(multiWrapper.Providers(), ", ")
+		createdSeals = append(createdSeals, barrierSeal)
+	}
 	return barrierSeal, barrierWrapper, unwrapSeal, createdSeals, sealConfigError, nil
 }
 
@@ -2574,12 +2728,16 @@ func createCoreConfig(c *ServerCommand, config *server.Config, backend physical.
 		Logger:                         c.logger,
 		DetectDeadlocks:                config.DetectDeadlocks,
 		ImpreciseLeaseRoleTracking:     config.ImpreciseLeaseRoleTracking,
+		StrictPatchCapability:          config.StrictPatchCapability,
+		BarrierCompression:             config.BarrierCompression,
+		ClusterCertRotationInterval:    config.ClusterCertRotationInterval,
 		DisableSentinelTrace:           config.DisableSentinelTrace,
 		DisableCache:                   config.DisableCache,
 		MaxLeaseTTL:                    config.MaxLeaseTTL,
 		DefaultLeaseTTL:                config.DefaultLeaseTTL,
 		ClusterName:                    config.ClusterName,
 		CacheSize:                      config.CacheSize,
+		StorageChecksums:               config.StorageChecksums,
 		PluginDirectory:                config.PluginDirectory,
 		PluginFileUid:                  config.PluginFileUid,
 		PluginFilePermissions:          config.PluginFilePermissions,
@@ -2791,6 +2949,12 @@ func startHttpServers(c *ServerCommand, core *vault.Core, config *server.Config,
 			handler = vaulthttp.WrapForwardedForHandler(handler, ln.Config)
 		}
 
+		// This must wrap the X-Forwarded-For handler, which replaces the
+		// address of the proxy with the address of the client.
+		if len(ln.Config.XForwardedClientCertAuthorizedAddrs) > 0 {
+			handler = vaulthttp.WrapForwardedClientCertHandler(handler, ln.Config)
+		}
+
 		// server defaults
 		server := &http.Server{
 			Handler:           handler,
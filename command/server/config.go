@@ -25,6 +25,7 @@ import (
 	"github.com/openbao/openbao/internalshared/configutil"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
 	"github.com/openbao/openbao/sdk/v2/helper/testcluster"
+	"github.com/openbao/openbao/sdk/v2/physical"
 )
 
 const (
@@ -47,14 +48,33 @@ type Config struct {
 	Storage   *Storage `hcl:"-"`
 	HAStorage *Storage `hcl:"-"`
 
+	// StorageMigrationTarget is the backend the storage is being migrated
+	// to online, if any.
+	StorageMigrationTarget *Storage `hcl:"-"`
+
+	// StorageFaultInjection configures faults injected into the storage,
+	// for testing.
+	StorageFaultInjection *physical.FaultInjectionConfig `hcl:"-"`
+
+	// StorageRateLimit paces the writes made to the storage, if set.
+	StorageRateLimit *physical.RateLimiterConfig `hcl:"-"`
+
+	// StorageInFlight reports the operations running against the storage
+	// and optionally caps their concurrency, if set.
+	StorageInFlight *physical.InFlightConfig `hcl:"-"`
+
 	ServiceRegistration *ServiceRegistration `hcl:"-"`
 
 	CacheSize                int         `hcl:"cache_size"`
+	CachePreloadManifest     string      `hcl:"cache_preload_manifest"`
 	DisableCache             bool        `hcl:"-"`
 	DisableCacheRaw          interface{} `hcl:"disable_cache"`
 	DisablePrintableCheck    bool        `hcl:"-"`
 	DisablePrintableCheckRaw interface{} `hcl:"disable_printable_check"`
 
+	StorageChecksums    bool        `hcl:"-"`
+	StorageChecksumsRaw interface{} `hcl:"storage_checksums"`
+
 	EnableUI    bool        `hcl:"-"`
 	EnableUIRaw interface{} `hcl:"ui"`
 
@@ -65,6 +85,9 @@ type Config struct {
 
 	ClusterCipherSuites string `hcl:"cluster_cipher_suites"`
 
+	ClusterCertRotationInterval    time.Duration `hcl:"-"`
+	ClusterCertRotationIntervalRaw interface{}   `hcl:"cluster_cert_rotation_interval"`
+
 	PluginDirectory string `hcl:"plugin_directory"`
 
 	PluginFileUid int `hcl:"plugin_file_uid"`
@@ -105,6 +128,10 @@ type Config struct {
 
 	ImpreciseLeaseRoleTracking bool `hcl:"imprecise_lease_role_tracking"`
 
+	StrictPatchCapability bool `hcl:"strict_patch_capability"`
+
+	BarrierCompression string `hcl:"barrier_compression"`
+
 	EnableResponseHeaderRaftNodeID    bool        `hcl:"-"`
 	EnableResponseHeaderRaftNodeIDRaw interface{} `hcl:"enable_response_header_raft_node_id"`
 
@@ -278,6 +305,26 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.HAStorage = c2.HAStorage
 	}
 
+	result.StorageMigrationTarget = c.StorageMigrationTarget
+	if c2.StorageMigrationTarget != nil {
+		result.StorageMigrationTarget = c2.StorageMigrationTarget
+	}
+
+	result.StorageFaultInjection = c.StorageFaultInjection
+	if c2.StorageFaultInjection != nil {
+		result.StorageFaultInjection = c2.StorageFaultInjection
+	}
+
+	result.StorageRateLimit = c.StorageRateLimit
+	if c2.StorageRateLimit != nil {
+		result.StorageRateLimit = c2.StorageRateLimit
+	}
+
+	result.StorageInFlight = c.StorageInFlight
+	if c2.StorageInFlight != nil {
+		result.StorageInFlight = c2.StorageInFlight
+	}
+
 	result.ServiceRegistration = c.ServiceRegistration
 	if c2.ServiceRegistration != nil {
 		result.ServiceRegistration = c2.ServiceRegistration
@@ -288,12 +335,22 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.CacheSize = c2.CacheSize
 	}
 
+	result.CachePreloadManifest = c.CachePreloadManifest
+	if c2.CachePreloadManifest != "" {
+		result.CachePreloadManifest = c2.CachePreloadManifest
+	}
+
 	// merging these booleans via an OR operation
 	result.DisableCache = c.DisableCache
 	if c2.DisableCache {
 		result.DisableCache = c2.DisableCache
 	}
 
+	result.StorageChecksums = c.StorageChecksums
+	if c2.StorageChecksums {
+		result.StorageChecksums = c2.StorageChecksums
+	}
+
 	result.DisableSentinelTrace = c.DisableSentinelTrace
 	if c2.DisableSentinelTrace {
 		result.DisableSentinelTrace = c2.DisableSentinelTrace
@@ -404,6 +461,21 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.ImpreciseLeaseRoleTracking = c2.ImpreciseLeaseRoleTracking
 	}
 
+	result.StrictPatchCapability = c.StrictPatchCapability
+	if c2.StrictPatchCapability {
+		result.StrictPatchCapability = c2.StrictPatchCapability
+	}
+
+	result.ClusterCertRotationInterval = c.ClusterCertRotationInterval
+	if c2.ClusterCertRotationInterval != 0 {
+		result.ClusterCertRotationInterval = c2.ClusterCertRotationInterval
+	}
+
+	result.BarrierCompression = c.BarrierCompression
+	if c2.BarrierCompression != "" {
+		result.BarrierCompression = c2.BarrierCompression
+	}
+
 	result.EnableResponseHeaderRaftNodeID = c.EnableResponseHeaderRaftNodeID
 	if c2.EnableResponseHeaderRaftNodeID {
 		result.EnableResponseHeaderRaftNodeID = c2.EnableResponseHeaderRaftNodeID
@@ -581,6 +653,15 @@ func ParseConfig(d, source string) (*Config, error) {
 		}
 	}
 
+	if result.ClusterCertRotationIntervalRaw != nil {
+		if result.ClusterCertRotationInterval, err = parseutil.ParseDurationSecond(result.ClusterCertRotationIntervalRaw); err != nil {
+			return nil, err
+		}
+		if result.ClusterCertRotationInterval != 0 && result.ClusterCertRotationInterval < time.Minute {
+			return nil, fmt.Errorf("cluster_cert_rotation_interval must be at least 1m, got %v", result.ClusterCertRotationInterval)
+		}
+	}
+
 	if result.EnableUIRaw != nil {
 		if result.EnableUI, err = parseutil.ParseBool(result.EnableUIRaw); err != nil {
 			return nil, err
@@ -593,6 +674,12 @@ func ParseConfig(d, source string) (*Config, error) {
 		}
 	}
 
+	if result.StorageChecksumsRaw != nil {
+		if result.StorageChecksums, err = parseutil.ParseBool(result.StorageChecksumsRaw); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.DisablePrintableCheckRaw != nil {
 		if result.DisablePrintableCheck, err = parseutil.ParseBool(result.DisablePrintableCheckRaw); err != nil {
 			return nil, err
@@ -715,6 +802,34 @@ func ParseConfig(d, source string) (*Config, error) {
 		}
 	}
 
+	if o := list.Filter("storage_migration_target"); len(o.Items) > 0 {
+		delete(result.UnusedKeys, "storage_migration_target")
+		if err := parseStorageMigrationTarget(result, o, "storage_migration_target"); err != nil {
+			return nil, fmt.Errorf("error parsing 'storage_migration_target': %w", err)
+		}
+	}
+
+	if o := list.Filter("storage_fault_injection"); len(o.Items) > 0 {
+		delete(result.UnusedKeys, "storage_fault_injection")
+		if err := parseStorageFaultInjection(result, o); err != nil {
+			return nil, fmt.Errorf("error parsing 'storage_fault_injection': %w", err)
+		}
+	}
+
+	if o := list.Filter("storage_rate_limit"); len(o.Items) > 0 {
+		delete(result.UnusedKeys, "storage_rate_limit")
+		if err := parseStorageRateLimit(result, o); err != nil {
+			return nil, fmt.Errorf("error parsing 'storage_rate_limit': %w", err)
+		}
+	}
+
+	if o := list.Filter("storage_in_flight"); len(o.Items) > 0 {
+		delete(result.UnusedKeys, "storage_in_flight")
+		if err := parseStorageInFlight(result, o); err != nil {
+			return nil, fmt.Errorf("error parsing 'storage_in_flight': %w", err)
+		}
+	}
+
 	// Parse service discovery
 	if o := list.Filter("service_registration"); len(o.Items) > 0 {
 		delete(result.UnusedKeys, "service_registration")
@@ -974,6 +1089,178 @@ func parseHAStorage(result *Config, list *ast.ObjectList, name string) error {
 	return nil
 }
 
+func parseStorageMigrationTarget(result *Config, list *ast.ObjectList, name string) error {
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one %q block is permitted", name)
+	}
+
+	// Get our item
+	item := list.Items[0]
+
+	key := name
+	if len(item.Keys) > 0 {
+		key = item.Keys[0].Token.Value().(string)
+	}
+
+	var config map[string]interface{}
+	if err := hcl.DecodeObject(&config, item.Val); err != nil {
+		return multierror.Prefix(err, fmt.Sprintf("%s.%s:", name, key))
+	}
+
+	m := make(map[string]string)
+	for key, val := range config {
+		valStr, ok := val.(string)
+		if ok {
+			m[key] = valStr
+			continue
+		}
+		valBytes, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		m[key] = string(valBytes)
+	}
+
+	result.StorageMigrationTarget = &Storage{
+		Type:   strings.ToLower(key),
+		Config: m,
+	}
+	return nil
+}
+
+func parseStorageFaultInjection(result *Config, list *ast.ObjectList) error {
+	if len(list.Items) > 1 {
+		return errors.New("only one 'storage_fault_injection' block is permitted")
+	}
+
+	item := list.Items[0]
+	ot, ok := item.Val.(*ast.ObjectType)
+	if !ok {
+		return errors.New("storage_fault_injection must be a block")
+	}
+
+	var settings struct {
+		Seed        int64 `hcl:"seed"`
+		AllowUnsafe bool  `hcl:"allow_unsafe"`
+	}
+	if err := hcl.DecodeObject(&settings, item.Val); err != nil {
+		return err
+	}
+
+	config := &physical.FaultInjectionConfig{
+		Seed:        settings.Seed,
+		AllowUnsafe: settings.AllowUnsafe,
+		Operations:  make(map[physical.Operation]*physical.OperationFaults),
+	}
+	for _, opItem := range ot.List.Filter("operation").Items {
+		if len(opItem.Keys) == 0 {
+			return errors.New("operation blocks must be named after an operation")
+		}
+		op := physical.Operation(strings.ToLower(opItem.Keys[0].Token.Value().(string)))
+		if _, ok := config.Operations[op]; ok {
+			return fmt.Errorf("duplicate operation %q", op)
+		}
+
+		var raw struct {
+			LatencyRaw    interface{} `hcl:"latency"`
+			JitterPercent int         `hcl:"jitter_percent"`
+			FailPercent   float64     `hcl:"fail_percent"`
+			FailWith      string      `hcl:"fail_with"`
+			FailAfterRaw  interface{} `hcl:"fail_after"`
+			RateLimit     float64     `hcl:"rate_limit"`
+			RateBurst     int         `hcl:"rate_burst"`
+		}
+		if err := hcl.DecodeObject(&raw, opItem.Val); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("operation.%s:", op))
+		}
+
+		faults := &physical.OperationFaults{
+			JitterPercent: raw.JitterPercent,
+			FailPercent:   raw.FailPercent,
+			FailWith:      raw.FailWith,
+			RateLimit:     raw.RateLimit,
+			RateBurst:     raw.RateBurst,
+		}
+		var err error
+		if raw.LatencyRaw != nil {
+			if faults.Latency, err = parseutil.ParseDurationSecond(raw.LatencyRaw); err != nil {
+				return fmt.Errorf("operation.%s: invalid latency: %w", op, err)
+			}
+		}
+		if raw.FailAfterRaw != nil {
+			if faults.FailAfter, err = parseutil.ParseDurationSecond(raw.FailAfterRaw); err != nil {
+				return fmt.Errorf("operation.%s: invalid fail_after: %w", op, err)
+			}
+		}
+		config.Operations[op] = faults
+	}
+
+	result.StorageFaultInjection = config
+	return nil
+}
+
+func parseStorageRateLimit(result *Config, list *ast.ObjectList) error {
+	if len(list.Items) > 1 {
+		return errors.New("only one 'storage_rate_limit' block is permitted")
+	}
+
+	item := list.Items[0]
+	if _, ok := item.Val.(*ast.ObjectType); !ok {
+		return errors.New("storage_rate_limit must be a block")
+	}
+
+	var settings struct {
+		Rate       float64 `hcl:"rate"`
+		Burst      int     `hcl:"burst"`
+		LimitLists bool    `hcl:"limit_lists"`
+		MaxQueued  int     `hcl:"max_queued"`
+	}
+	if err := hcl.DecodeObject(&settings, item.Val); err != nil {
+		return err
+	}
+
+	config := &physical.RateLimiterConfig{
+		Rate:       settings.Rate,
+		Burst:      settings.Burst,
+		LimitLists: settings.LimitLists,
+		MaxQueued:  settings.MaxQueued,
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	result.StorageRateLimit = config
+	return nil
+}
+
+func parseStorageInFlight(result *Config, list *ast.ObjectList) error {
+	if len(list.Items) > 1 {
+		return errors.New("only one 'storage_in_flight' block is permitted")
+	}
+
+	item := list.Items[0]
+	if _, ok := item.Val.(*ast.ObjectType); !ok {
+		return errors.New("storage_in_flight must be a block")
+	}
+
+	var settings struct {
+		MaxConcurrency int `hcl:"max_concurrency"`
+	}
+	if err := hcl.DecodeObject(&settings, item.Val); err != nil {
+		return err
+	}
+
+	config := &physical.InFlightConfig{
+		MaxConcurrency: settings.MaxConcurrency,
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	result.StorageInFlight = config
+	return nil
+}
+
 func parseServiceRegistration(result *Config, list *ast.ObjectList, name string) error {
 	if len(list.Items) > 1 {
 		return fmt.Errorf("only one %q block is permitted", name)
@@ -1005,6 +1292,7 @@ func parseServiceRegistration(result *Config, list *ast.ObjectList, name string)
 // Specifically, the fields that this method strips are:
 // - Storage.Config
 // - HAStorage.Config
+// - StorageMigrationTarget.Config
 // - Seals.Config
 // - Telemetry.CirconusAPIToken
 func (c *Config) Sanitized() map[string]interface{} {
@@ -1016,9 +1304,11 @@ func (c *Config) Sanitized() map[string]interface{} {
 	sharedResult := c.SharedConfig.Sanitized()
 	result := map[string]interface{}{
 		"cache_size":              c.CacheSize,
+		"cache_preload_manifest":  c.CachePreloadManifest,
 		"disable_sentinel_trace":  c.DisableSentinelTrace,
 		"disable_cache":           c.DisableCache,
 		"disable_printable_check": c.DisablePrintableCheck,
+		"storage_checksums":       c.StorageChecksums,
 
 		"enable_ui": c.EnableUI,
 
@@ -1027,6 +1317,8 @@ func (c *Config) Sanitized() map[string]interface{} {
 
 		"cluster_cipher_suites": c.ClusterCipherSuites,
 
+		"cluster_cert_rotation_interval": c.ClusterCertRotationInterval / time.Second,
+
 		"plugin_directory": c.PluginDirectory,
 
 		"plugin_file_uid": c.PluginFileUid,
@@ -1056,6 +1348,10 @@ func (c *Config) Sanitized() map[string]interface{} {
 		"detect_deadlocks": c.DetectDeadlocks,
 
 		"imprecise_lease_role_tracking": c.ImpreciseLeaseRoleTracking,
+
+		"strict_patch_capability": c.StrictPatchCapability,
+
+		"barrier_compression": c.BarrierCompression,
 	}
 	for k, v := range sharedResult {
 		result[k] = v
@@ -1099,6 +1395,13 @@ func (c *Config) Sanitized() map[string]interface{} {
 		result["ha_storage"] = sanitizedHAStorage
 	}
 
+	// Sanitize storage migration target stanza
+	if c.StorageMigrationTarget != nil {
+		result["storage_migration_target"] = map[string]interface{}{
+			"type": c.StorageMigrationTarget.Type,
+		}
+	}
+
 	// Sanitize service_registration stanza
 	if c.ServiceRegistration != nil {
 		sanitizedServiceRegistration := map[string]interface{}{
@@ -62,6 +62,22 @@ func TestParseStorage(t *testing.T) {
 	testParseStorageTemplate(t)
 }
 
+func TestParseStorageFaultInjection(t *testing.T) {
+	testParseStorageFaultInjection(t)
+}
+
+func TestParseStorageRateLimit(t *testing.T) {
+	testParseStorageRateLimit(t)
+}
+
+func TestParseStorageInFlight(t *testing.T) {
+	testParseStorageInFlight(t)
+}
+
+func TestParseStorageMigrationTarget(t *testing.T) {
+	testParseStorageMigrationTarget(t)
+}
+
 // TestConfigWithAdministrativeNamespace tests that .hcl and .json configurations are correctly parsed when the administrative_namespace_path is present.
 func TestConfigWithAdministrativeNamespace(t *testing.T) {
 	testConfigWithAdministrativeNamespaceHcl(t)
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/hcl/hcl/ast"
 	"github.com/hashicorp/hcl/hcl/token"
 	"github.com/openbao/openbao/internalshared/configutil"
+	"github.com/openbao/openbao/sdk/v2/physical"
 )
 
 var DefaultCustomHeaders = map[string]map[string]string{
@@ -691,6 +692,7 @@ func testConfig_Sanitized(t *testing.T) {
 	expected := map[string]interface{}{
 		"api_addr":                            "top_level_api_addr",
 		"cache_size":                          0,
+		"cache_preload_manifest":              "",
 		"cluster_addr":                        "top_level_cluster_addr",
 		"cluster_cipher_suites":               "",
 		"cluster_name":                        "testcluster",
@@ -703,6 +705,7 @@ func testConfig_Sanitized(t *testing.T) {
 		"plugin_file_uid":                     0,
 		"plugin_file_permissions":             0,
 		"disable_printable_check":             false,
+		"storage_checksums":                   false,
 		"disable_sealwrap":                    true,
 		"raw_storage_endpoint":                true,
 		"introspection_endpoint":              false,
@@ -777,8 +780,11 @@ func testConfig_Sanitized(t *testing.T) {
 			"num_lease_metrics_buckets":              168,
 			"add_lease_metrics_namespace_labels":     false,
 		},
-		"administrative_namespace_path": "admin/",
-		"imprecise_lease_role_tracking": false,
+		"administrative_namespace_path":  "admin/",
+		"imprecise_lease_role_tracking":  false,
+		"strict_patch_capability":        false,
+		"barrier_compression":            "",
+		"cluster_cert_rotation_interval": time.Duration(0),
 	}
 
 	addExpectedEntSanitizedConfig(expected, []string{"http"})
@@ -1017,6 +1023,162 @@ ha_storage "consul" {
 	}
 }
 
+func testParseStorageFaultInjection(t *testing.T) {
+	config, err := ParseConfig(`
+storage "inmem" {}
+storage_fault_injection {
+	seed = 42
+	allow_unsafe = true
+	operation "get" {
+		latency = "10ms"
+		jitter_percent = 20
+		fail_percent = 5
+		fail_with = "timeout"
+		fail_after = "200ms"
+	}
+	operation "PUT" {
+		rate_limit = 50
+		rate_burst = 10
+	}
+}
+`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &physical.FaultInjectionConfig{
+		Seed:        42,
+		AllowUnsafe: true,
+		Operations: map[physical.Operation]*physical.OperationFaults{
+			physical.GetOperation: {
+				Latency:       10 * time.Millisecond,
+				JitterPercent: 20,
+				FailPercent:   5,
+				FailWith:      physical.FaultTimeout,
+				FailAfter:     200 * time.Millisecond,
+			},
+			physical.PutOperation: {
+				RateLimit: 50,
+				RateBurst: 10,
+			},
+		},
+	}
+	if diff := deep.Equal(config.StorageFaultInjection, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	_, err = ParseConfig(`
+storage_fault_injection {
+	operation "get" {}
+	operation "get" {}
+}
+`, "")
+	if err == nil {
+		t.Fatal("expected an error for duplicate operations")
+	}
+}
+
+func testParseStorageRateLimit(t *testing.T) {
+	config, err := ParseConfig(`
+storage "inmem" {}
+storage_rate_limit {
+	rate = 100
+	burst = 20
+	limit_lists = true
+	max_queued = 500
+}
+`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &physical.RateLimiterConfig{
+		Rate:       100,
+		Burst:      20,
+		LimitLists: true,
+		MaxQueued:  500,
+	}
+	if diff := deep.Equal(config.StorageRateLimit, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	_, err = ParseConfig(`
+storage_rate_limit {
+	burst = 20
+}
+`, "")
+	if err == nil {
+		t.Fatal("expected an error for a missing rate")
+	}
+}
+
+func testParseStorageInFlight(t *testing.T) {
+	config, err := ParseConfig(`
+storage "inmem" {}
+storage_in_flight {
+	max_concurrency = 64
+}
+`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &physical.InFlightConfig{
+		MaxConcurrency: 64,
+	}
+	if diff := deep.Equal(config.StorageInFlight, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	_, err = ParseConfig(`
+storage_in_flight {
+	max_concurrency = -1
+}
+`, "")
+	if err == nil {
+		t.Fatal("expected an error for a negative max concurrency")
+	}
+}
+
+func testParseStorageMigrationTarget(t *testing.T) {
+	config, err := ParseConfig(`
+storage "file" {
+	path = "/bao/file"
+}
+storage_migration_target "postgresql" {
+	connection_url = "postgres://bao@localhost/bao"
+	max_parallel = 16
+}
+`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	disableSSCTs := true
+
+	expected := &Config{
+		Storage: &Storage{
+			Type: "file",
+			Config: map[string]string{
+				"path": "/bao/file",
+			},
+		},
+		StorageMigrationTarget: &Storage{
+			Type: "postgresql",
+			Config: map[string]string{
+				"connection_url": "postgres://bao@localhost/bao",
+				"max_parallel":   "16",
+			},
+		},
+		SharedConfig:     &configutil.SharedConfig{},
+		DisableSSCTokens: &disableSSCTs,
+	}
+	config.Prune()
+	if diff := deep.Equal(config, expected); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
 func testParseSeals(t *testing.T) {
 	config, err := LoadConfigFile("./test-fixtures/config_seals.hcl")
 	if err != nil {
@@ -64,6 +64,11 @@ func tcpListenerFactory(l *configutil.Listener, _ io.Writer, ui cli.Ui) (net.Lis
 		if len(l.XForwardedForAuthorizedAddrs) > 0 {
 			props["x_forwarded_for_reject_not_authorized"] = strconv.FormatBool(l.XForwardedForRejectNotAuthorized)
 		}
+
+		if len(l.XForwardedClientCertAuthorizedAddrs) > 0 {
+			props["x_forwarded_client_cert_header"] = l.XForwardedClientCertHeader
+			props["x_forwarded_client_cert_authorized_addrs"] = fmt.Sprintf("%v", l.XForwardedClientCertAuthorizedAddrs)
+		}
 	}
 
 	tlsConfig, reloadFunc, err := listenerutil.TLSConfig(l, props, ui)
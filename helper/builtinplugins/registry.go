@@ -14,12 +14,14 @@ import (
 	credLdap "github.com/openbao/openbao/builtin/credential/ldap"
 	credRadius "github.com/openbao/openbao/builtin/credential/radius"
 	credUserpass "github.com/openbao/openbao/builtin/credential/userpass"
+	credWebAuthn "github.com/openbao/openbao/builtin/credential/webauthn"
 	logicalKube "github.com/openbao/openbao/builtin/logical/kubernetes"
 	logicalKv "github.com/openbao/openbao/builtin/logical/kv"
 	logicalLDAP "github.com/openbao/openbao/builtin/logical/openldap"
 	logicalPki "github.com/openbao/openbao/builtin/logical/pki"
 	logicalRabbit "github.com/openbao/openbao/builtin/logical/rabbitmq"
 	logicalSsh "github.com/openbao/openbao/builtin/logical/ssh"
+	logicalSTS "github.com/openbao/openbao/builtin/logical/sts"
 	logicalTotp "github.com/openbao/openbao/builtin/logical/totp"
 	logicalTransit "github.com/openbao/openbao/builtin/logical/transit"
 	dbCass "github.com/openbao/openbao/plugins/database/cassandra"
@@ -79,6 +81,7 @@ func newRegistry() *registry {
 			"oidc":       {Factory: credJWT.Factory},
 			"radius":     {Factory: credRadius.Factory},
 			"userpass":   {Factory: credUserpass.Factory},
+			"webauthn":   {Factory: credWebAuthn.Factory},
 		},
 		databasePlugins: map[string]databasePlugin{
 			// These four plugins all use the same mysql implementation but with
@@ -100,6 +103,7 @@ func newRegistry() *registry {
 			"pki":        {Factory: logicalPki.Factory},
 			"rabbitmq":   {Factory: logicalRabbit.Factory},
 			"ssh":        {Factory: logicalSsh.Factory},
+			"sts":        {Factory: logicalSTS.Factory},
 			"totp":       {Factory: logicalTotp.Factory},
 			"transit":    {Factory: logicalTransit.Factory},
 		},
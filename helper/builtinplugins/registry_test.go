@@ -98,7 +98,7 @@ func Test_RegistryKeyCounts(t *testing.T) {
 		{
 			name:       "number of auth plugins",
 			pluginType: consts.PluginTypeCredential,
-			want:       9,
+			want:       10,
 		},
 		{
 			name:       "number of database plugins",
@@ -108,7 +108,7 @@ func Test_RegistryKeyCounts(t *testing.T) {
 		{
 			name:       "number of secrets plugins",
 			pluginType: consts.PluginTypeSecrets,
-			want:       9,
+			want:       10,
 		},
 	}
 	for _, tt := range tests {
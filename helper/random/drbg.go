@@ -0,0 +1,244 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package random
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// drbgEntropyBytes is the size of the entropy input used to seed and
+	// reseed the DRBG, matching its 256-bit security strength.
+	drbgEntropyBytes = 32
+
+	// drbgNonceBytes is the size of the nonce used when seeding the DRBG.
+	drbgNonceBytes = 16
+
+	// drbgMaxRequestBytes is the maximum number of bytes returned by a single
+	// generate call, as allowed by NIST SP 800-90A.
+	drbgMaxRequestBytes = 1 << 16
+
+	// DefaultDRBGReseedInterval is the default number of generate calls after
+	// which the DRBG is reseeded.
+	DefaultDRBGReseedInterval = 1024
+
+	// MaxDRBGReseedInterval is the maximum number of generate calls allowed
+	// between two reseeds of the DRBG.
+	MaxDRBGReseedInterval = 1 << 48
+)
+
+// ErrDRBGHealthTestFailed is returned by a DRBG which failed a health test.
+var ErrDRBGHealthTestFailed = errors.New("DRBG health test failed")
+
+// DRBGConfig configures the reseeding of a DRBG.
+type DRBGConfig struct {
+	// ReseedInterval is the number of generate calls after which the DRBG is
+	// reseeded. Defaults to DefaultDRBGReseedInterval.
+	ReseedInterval int64
+
+	// ReseedPeriod is the duration after which the DRBG is reseeded, on its
+	// next use. Zero disables time based reseeding.
+	ReseedPeriod time.Duration
+}
+
+// DRBG is an HMAC_DRBG using SHA-256, as specified by NIST SP 800-90A, which
+// is seeded and periodically reseeded from an entropy source.
+//
+// Both the entropy input and the generated output are subject to a continuous
+// health test, failing when a block is identical to the previous one. Once a
+// health test failed, the DRBG refuses to generate any further output.
+type DRBG struct {
+	l sync.Mutex
+
+	entropy   io.Reader
+	config    DRBGConfig
+	onFailure func(error)
+
+	key           []byte
+	v             []byte
+	reseedCounter int64
+	lastReseed    time.Time
+
+	lastEntropy []byte
+	lastBlock   []byte
+	err         error
+}
+
+// NewDRBG returns a DRBG seeded from the given entropy source. onFailure, if
+// set, is called once when a health test fails.
+func NewDRBG(entropy io.Reader, config DRBGConfig, onFailure func(error)) (*DRBG, error) {
+	if config.ReseedInterval == 0 {
+		config.ReseedInterval = DefaultDRBGReseedInterval
+	}
+	if config.ReseedInterval < 1 || config.ReseedInterval > MaxDRBGReseedInterval {
+		return nil, fmt.Errorf("reseed interval must be between 1 and %d", int64(MaxDRBGReseedInterval))
+	}
+	if config.ReseedPeriod < 0 {
+		return nil, errors.New("reseed period must not be negative")
+	}
+
+	d := &DRBG{
+		entropy:   entropy,
+		config:    config,
+		onFailure: onFailure,
+		key:       make([]byte, sha256.Size),
+		v:         bytes.Repeat([]byte{0x01}, sha256.Size),
+	}
+
+	entropyInput, err := d.readEntropy()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, drbgNonceBytes)
+	if _, err := io.ReadFull(entropy, nonce); err != nil {
+		return nil, fmt.Errorf("failed to read nonce: %w", err)
+	}
+
+	d.update(entropyInput, nonce)
+	d.reseedCounter = 1
+	d.lastReseed = time.Now()
+	return d, nil
+}
+
+// Read fills p with random bytes. It returns an error wrapping
+// ErrDRBGHealthTestFailed if a health test failed.
+func (d *DRBG) Read(p []byte) (int, error) {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	for n := 0; n < len(p); {
+		if d.err != nil {
+			return n, d.err
+		}
+
+		if d.reseedCounter > d.config.ReseedInterval ||
+			(d.config.ReseedPeriod > 0 && time.Since(d.lastReseed) >= d.config.ReseedPeriod) {
+			if err := d.reseed(); err != nil {
+				return n, err
+			}
+		}
+
+		end := n + drbgMaxRequestBytes
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := d.generate(p[n:end]); err != nil {
+			return n, err
+		}
+		n = end
+	}
+	return len(p), nil
+}
+
+// Reseed reseeds the DRBG from its entropy source.
+func (d *DRBG) Reseed() error {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	if d.err != nil {
+		return d.err
+	}
+	return d.reseed()
+}
+
+// Err returns the error of the failed health test, if any.
+func (d *DRBG) Err() error {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	return d.err
+}
+
+// LastReseed returns the time the DRBG was last seeded.
+func (d *DRBG) LastReseed() time.Time {
+	d.l.Lock()
+	defer d.l.Unlock()
+
+	return d.lastReseed
+}
+
+func (d *DRBG) reseed() error {
+	entropyInput, err := d.readEntropy()
+	if err != nil {
+		return err
+	}
+	d.update(entropyInput)
+	d.reseedCounter = 1
+	d.lastReseed = time.Now()
+	return nil
+}
+
+// readEntropy reads a block of entropy input, subjecting it to the continuous
+// health test.
+func (d *DRBG) readEntropy() ([]byte, error) {
+	entropyInput := make([]byte, drbgEntropyBytes)
+	if _, err := io.ReadFull(d.entropy, entropyInput); err != nil {
+		return nil, fmt.Errorf("failed to read entropy input: %w", err)
+	}
+	if d.lastEntropy != nil && hmac.Equal(entropyInput, d.lastEntropy) {
+		return nil, d.fail("entropy input repeated")
+	}
+	d.lastEntropy = entropyInput
+	return entropyInput, nil
+}
+
+// generate fills out with output of the DRBG, subjecting every block to the
+// continuous health test.
+func (d *DRBG) generate(out []byte) error {
+	for n := 0; n < len(out); {
+		d.v = d.hmac(d.v)
+		if d.lastBlock != nil && hmac.Equal(d.v, d.lastBlock) {
+			return d.fail("output block repeated")
+		}
+		d.lastBlock = append(d.lastBlock[:0], d.v...)
+		n += copy(out[n:], d.v)
+	}
+	d.update()
+	d.reseedCounter++
+	return nil
+}
+
+// update is the HMAC_DRBG update function, applied to the concatenation of
+// the provided data.
+func (d *DRBG) update(data ...[]byte) {
+	d.key = d.hmac(append([][]byte{d.v, {0x00}}, data...)...)
+	d.v = d.hmac(d.v)
+	if len(data) == 0 {
+		return
+	}
+	d.key = d.hmac(append([][]byte{d.v, {0x01}}, data...)...)
+	d.v = d.hmac(d.v)
+}
+
+// hmac returns the HMAC of the concatenation of the provided data under the
+// current key.
+func (d *DRBG) hmac(data ...[]byte) []byte {
+	h := hmac.New(sha256.New, d.key)
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// fail puts the DRBG in the failed state and wipes its internal state.
+func (d *DRBG) fail(reason string) error {
+	d.err = fmt.Errorf("%w: %s", ErrDRBGHealthTestFailed, reason)
+	for i := range d.key {
+		d.key[i] = 0
+	}
+	for i := range d.v {
+		d.v[i] = 0
+	}
+	if d.onFailure != nil {
+		d.onFailure(d.err)
+	}
+	return d.err
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package random
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingReader reads from r and counts the number of reads.
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestDRBG_KnownAnswer(t *testing.T) {
+	// Two instances seeded from the same entropy must agree, and differ from
+	// an instance seeded from other entropy.
+	seed := bytes.Repeat([]byte{0x42}, drbgEntropyBytes+drbgNonceBytes)
+	d1, err := NewDRBG(bytes.NewReader(seed), DRBGConfig{}, nil)
+	require.NoError(t, err)
+	d2, err := NewDRBG(bytes.NewReader(seed), DRBGConfig{}, nil)
+	require.NoError(t, err)
+
+	out1 := make([]byte, 100)
+	out2 := make([]byte, 100)
+	_, err = d1.Read(out1)
+	require.NoError(t, err)
+	_, err = d2.Read(out2)
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(out1), hex.EncodeToString(out2))
+
+	d3, err := NewDRBG(rand.Reader, DRBGConfig{}, nil)
+	require.NoError(t, err)
+	out3 := make([]byte, 100)
+	_, err = d3.Read(out3)
+	require.NoError(t, err)
+	require.NotEqual(t, out1, out3)
+}
+
+func TestDRBG_Reseed(t *testing.T) {
+	entropy := &countingReader{r: rand.Reader}
+	d, err := NewDRBG(entropy, DRBGConfig{ReseedInterval: 2}, nil)
+	require.NoError(t, err)
+	// Seeding reads the entropy input and the nonce
+	require.Equal(t, 2, entropy.reads)
+
+	buf := make([]byte, 32)
+	for i := 0; i < 2; i++ {
+		_, err = d.Read(buf)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, entropy.reads)
+
+	_, err = d.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, 3, entropy.reads)
+
+	// Large reads are split in several generate calls
+	_, err = d.Read(make([]byte, 2*drbgMaxRequestBytes+1))
+	require.NoError(t, err)
+	require.Equal(t, 4, entropy.reads)
+
+	// Time based reseeding
+	d, err = NewDRBG(entropy, DRBGConfig{ReseedPeriod: time.Millisecond}, nil)
+	require.NoError(t, err)
+	reads := entropy.reads
+	time.Sleep(2 * time.Millisecond)
+	_, err = d.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, reads+1, entropy.reads)
+}
+
+func TestDRBG_Config(t *testing.T) {
+	_, err := NewDRBG(rand.Reader, DRBGConfig{ReseedInterval: -1}, nil)
+	require.Error(t, err)
+	_, err = NewDRBG(rand.Reader, DRBGConfig{ReseedInterval: MaxDRBGReseedInterval + 1}, nil)
+	require.Error(t, err)
+	_, err = NewDRBG(rand.Reader, DRBGConfig{ReseedPeriod: -time.Second}, nil)
+	require.Error(t, err)
+	_, err = NewDRBG(bytes.NewReader(nil), DRBGConfig{}, nil)
+	require.Error(t, err)
+}
+
+func TestDRBG_HealthTestFailure(t *testing.T) {
+	// A stuck entropy source fails the continuous test on the first reseed.
+	var failures []error
+	d, err := NewDRBG(bytes.NewReader(make([]byte, 1024)), DRBGConfig{ReseedInterval: 1}, func(err error) {
+		failures = append(failures, err)
+	})
+	require.NoError(t, err)
+
+	buf := make([]byte, 32)
+	_, err = d.Read(buf)
+	require.NoError(t, err)
+
+	n, err := d.Read(buf)
+	require.ErrorIs(t, err, ErrDRBGHealthTestFailed)
+	require.Equal(t, 0, n)
+	require.Len(t, failures, 1)
+	require.ErrorIs(t, d.Err(), ErrDRBGHealthTestFailed)
+
+	// The DRBG stays failed
+	_, err = d.Read(buf)
+	require.ErrorIs(t, err, ErrDRBGHealthTestFailed)
+	require.ErrorIs(t, d.Reseed(), ErrDRBGHealthTestFailed)
+	require.Len(t, failures, 1)
+}
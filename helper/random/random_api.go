@@ -20,6 +20,13 @@ import (
 const APIMaxBytes = 128 * 1024
 
 func HandleRandomAPI(d *framework.FieldData, additionalSource io.Reader) (*logical.Response, error) {
+	return HandleRandomAPIWithPlatformSource(d, rand.Reader, additionalSource)
+}
+
+// HandleRandomAPIWithPlatformSource is like HandleRandomAPI, but reads the
+// bytes of the "platform" source from platformSource instead of the system
+// entropy source.
+func HandleRandomAPIWithPlatformSource(d *framework.FieldData, platformSource, additionalSource io.Reader) (*logical.Response, error) {
 	bytes := 0
 	// Parsing is convoluted here, but allows operators to ACL both source and byte count
 	maybeUrlBytes := d.Raw["urlbytes"]
@@ -64,7 +71,7 @@ func HandleRandomAPI(d *framework.FieldData, additionalSource io.Reader) (*logic
 	var warning string
 	switch source {
 	case "", "platform":
-		randBytes, err = uuid.GenerateRandomBytes(bytes)
+		randBytes, err = uuid.GenerateRandomBytesWithReader(bytes, platformSource)
 		if err != nil {
 			return nil, err
 		}
@@ -74,7 +81,7 @@ func HandleRandomAPI(d *framework.FieldData, additionalSource io.Reader) (*logic
 		}
 		randBytes, err = uuid.GenerateRandomBytesWithReader(bytes, additionalSource)
 	case "all":
-		randBytes, err = uuid.GenerateRandomBytes(bytes)
+		randBytes, err = uuid.GenerateRandomBytesWithReader(bytes, platformSource)
 		if err == nil && rand.Reader != additionalSource {
 			var sealBytes []byte
 			sealBytes, err = uuid.GenerateRandomBytesWithReader(bytes, additionalSource)
@@ -0,0 +1,78 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sockaddr "github.com/hashicorp/go-sockaddr"
+	"github.com/openbao/openbao/internalshared/configutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_XForwardedClientCert(t *testing.T) {
+	proxyAddr, err := sockaddr.NewIPAddr("127.0.0.1")
+	require.NoError(t, err)
+
+	handler := WrapForwardedClientCertHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, r.Header.Get("X-Client-Cert-Thumbprint"))
+		w.Write([]byte(getConnection(r).ClientCertThumbprint))
+	}), &configutil.Listener{
+		XForwardedClientCertHeader: "x-client-cert-thumbprint",
+		XForwardedClientCertAuthorizedAddrs: []*sockaddr.SockAddrMarshaler{
+			{SockAddr: proxyAddr},
+		},
+	})
+
+	// The certificate of the proxy itself, or of clients connecting directly.
+	peerCert := &x509.Certificate{Raw: []byte("peer")}
+	peerThumbprint := logical.CertificateThumbprint(peerCert)
+	thumbprint := strings.Repeat("0f", 32)
+
+	serve := func(remoteAddr string, headers ...string) (int, string) {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/v1/sys/health", nil)
+		r.RemoteAddr = remoteAddr
+		r.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{peerCert},
+		}
+		for _, header := range headers {
+			r.Header.Add("X-Client-Cert-Thumbprint", header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := serve("127.0.0.1:1234", thumbprint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, thumbprint, body)
+
+	// Colon-separated and uppercase thumbprints are normalized.
+	colons := strings.TrimSuffix(strings.Repeat("0F:", 32), ":")
+	code, body = serve("127.0.0.1:1234", colons)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, thumbprint, body)
+
+	// The certificate of a trusted proxy is never used for the client.
+	code, body = serve("127.0.0.1:1234")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, body)
+
+	code, _ = serve("127.0.0.1:1234", "not-a-thumbprint")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = serve("127.0.0.1:1234", thumbprint, thumbprint)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// The header of other clients is ignored.
+	code, body = serve("1.2.3.4:1234", thumbprint)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, peerThumbprint, body)
+}
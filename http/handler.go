@@ -6,6 +6,8 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -139,6 +141,7 @@ func handler(props *vault.HandlerProperties) http.Handler {
 		raw := vault.NewRawBackend(core)
 		strategy := vault.GenerateRecoveryTokenStrategy(props.RecoveryToken)
 		mux.Handle("/v1/sys/raw/", handleLogicalRecovery(raw, props.RecoveryToken))
+		mux.Handle("/v1/sys/raw-batch", handleLogicalRecovery(raw, props.RecoveryToken))
 		mux.Handle("/v1/sys/generate-recovery-token/attempt", handleSysGenerateRootAttempt(core, strategy))
 		mux.Handle("/v1/sys/generate-recovery-token/update", handleSysGenerateRootUpdate(core, strategy))
 	default:
@@ -524,6 +527,76 @@ func WrapForwardedForHandler(h http.Handler, l *configutil.Listener) http.Handle
 	})
 }
 
+// WrapForwardedClientCertHandler trusts the client certificate thumbprint
+// sent by the proxies at the authorized addresses of the listener in its
+// configured header. A proxy terminating TLS sends the thumbprint of the
+// client certificate it verified, which is then used in place of the peer
+// certificate of the connection to check the tokens bound to a client
+// certificate. The header is removed from the requests of any other address.
+func WrapForwardedClientCertHandler(h http.Handler, l *configutil.Listener) http.Handler {
+	headerName := textproto.CanonicalMIMEHeaderKey(l.XForwardedClientCertHeader)
+	authorizedAddrs := l.XForwardedClientCertAuthorizedAddrs
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := r.Header.Values(headerName)
+		r.Header.Del(headerName)
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+		addr, err := sockaddr.NewIPAddr(host)
+		if err != nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var found bool
+		for _, authz := range authorizedAddrs {
+			if authz.Contains(addr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			// Don't trust the header of clients which are not proxies
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		// The peer certificate of a request from a proxy is the certificate
+		// of the proxy, so it is never used even if the header is missing.
+		var thumbprint string
+		switch len(values) {
+		case 0:
+		case 1:
+			thumbprint, err = parseCertThumbprint(values[0])
+			if err != nil {
+				respondError(w, http.StatusBadRequest, fmt.Errorf("invalid %s header: %w", strings.ToLower(headerName), err))
+				return
+			}
+		default:
+			respondError(w, http.StatusBadRequest, fmt.Errorf("multiple %s headers", strings.ToLower(headerName)))
+			return
+		}
+
+		r = r.WithContext(logical.CreateContextClientCertThumbprint(r.Context(), thumbprint))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// parseCertThumbprint normalizes a hex-encoded SHA-256 certificate
+// thumbprint, which may be separated by colons, to the format of
+// logical.CertificateThumbprint.
+func parseCertThumbprint(raw string) (string, error) {
+	thumbprint := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(raw), ":", ""))
+	decoded, err := hex.DecodeString(thumbprint)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", errors.New("expected a hex-encoded SHA-256 certificate thumbprint")
+	}
+	return thumbprint, nil
+}
+
 // stripPrefix is a helper to strip a prefix from the path. It will
 // return false from the second return value if it the prefix doesn't exist.
 func stripPrefix(prefix, path string) (string, bool) {
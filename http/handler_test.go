@@ -420,6 +420,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -438,6 +439,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -454,6 +456,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -471,6 +474,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -488,6 +492,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -506,6 +511,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -522,6 +528,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -539,6 +546,7 @@ func TestSysMounts_headerAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -562,5 +562,13 @@ func getConnection(r *http.Request) (connection *logical.Connection) {
 		RemotePort: remotePort,
 		ConnState:  r.TLS,
 	}
+
+	// A trusted proxy terminating TLS replaces the peer certificate, which
+	// is then the certificate of the proxy rather than of the client.
+	if thumbprint, ok := logical.ContextClientCertThumbprintValue(r.Context()); ok {
+		connection.ClientCertThumbprint = thumbprint
+	} else if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		connection.ClientCertThumbprint = logical.CertificateThumbprint(r.TLS.PeerCertificates[0])
+	}
 	return
 }
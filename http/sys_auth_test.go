@@ -46,6 +46,7 @@ func TestSysAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -64,6 +65,7 @@ func TestSysAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -129,6 +131,7 @@ func TestSysEnableAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -146,6 +149,7 @@ func TestSysEnableAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -165,6 +169,7 @@ func TestSysEnableAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -182,6 +187,7 @@ func TestSysEnableAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -249,6 +255,7 @@ func TestSysDisableAuth(t *testing.T) {
 				"external_entropy_access": false,
 				"local":                   false,
 				"seal_wrap":               false,
+				"dedicated_storage_key":   false,
 				"options":                 interface{}(nil),
 				"plugin_version":          "",
 				"running_sha256":          "",
@@ -267,6 +274,7 @@ func TestSysDisableAuth(t *testing.T) {
 			"external_entropy_access": false,
 			"local":                   false,
 			"seal_wrap":               false,
+			"dedicated_storage_key":   false,
 			"options":                 interface{}(nil),
 			"plugin_version":          "",
 			"running_sha256":          "",
@@ -526,6 +534,7 @@ func TestSysRemountAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -543,6 +552,7 @@ func TestSysRemountAuth(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -561,6 +571,7 @@ func TestSysRemountAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -578,6 +589,7 @@ func TestSysRemountAuth(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -139,6 +139,7 @@ func TestSysConfigState_Sanitized(t *testing.T) {
 			configResp := map[string]interface{}{
 				"api_addr":                            "",
 				"cache_size":                          json.Number("0"),
+				"cache_preload_manifest":              "",
 				"cluster_addr":                        "",
 				"cluster_cipher_suites":               "",
 				"cluster_name":                        "",
@@ -149,6 +150,7 @@ func TestSysConfigState_Sanitized(t *testing.T) {
 				"disable_indexing":                    false,
 				"disable_performance_standby":         false,
 				"disable_printable_check":             false,
+				"storage_checksums":                   false,
 				"disable_sealwrap":                    false,
 				"raw_storage_endpoint":                false,
 				"detect_deadlocks":                    "",
@@ -171,9 +173,12 @@ func TestSysConfigState_Sanitized(t *testing.T) {
 						"type":   "tcp",
 					},
 				},
-				"storage":                       tc.expectedStorageOutput,
-				"administrative_namespace_path": "",
-				"imprecise_lease_role_tracking": false,
+				"storage":                        tc.expectedStorageOutput,
+				"administrative_namespace_path":  "",
+				"imprecise_lease_role_tracking":  false,
+				"strict_patch_capability":        false,
+				"barrier_compression":            "",
+				"cluster_cert_rotation_interval": json.Number("0"),
 			}
 
 			if tc.expectedHAStorageOutput != nil {
@@ -62,6 +62,10 @@ func handleSysInitPut(core *vault.Core, w http.ResponseWriter, r *http.Request)
 		StoredShares:    req.StoredShares,
 		PGPKeys:         req.PGPKeys,
 	}
+	if len(req.ShareCredentials) > 0 {
+		barrierConfig.ShareCredentials = shareCredentials(req.ShareCredentials)
+		barrierConfig.ShareCredentialsRPID = req.ShareCredentialsRPID
+	}
 
 	recoveryConfig := &vault.SealConfig{
 		SecretShares:    req.RecoveryShares,
@@ -126,6 +130,9 @@ type InitRequest struct {
 	RecoveryThreshold int      `json:"recovery_threshold"`
 	RecoveryPGPKeys   []string `json:"recovery_pgp_keys"`
 	RootTokenPGPKey   string   `json:"root_token_pgp_key"`
+
+	ShareCredentials     []string `json:"share_credentials"`
+	ShareCredentialsRPID string   `json:"share_credentials_rp_id"`
 }
 
 type InitResponse struct {
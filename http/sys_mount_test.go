@@ -46,6 +46,7 @@ func TestSysMounts(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -64,6 +65,7 @@ func TestSysMounts(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -80,6 +82,7 @@ func TestSysMounts(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -97,6 +100,7 @@ func TestSysMounts(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -114,6 +118,7 @@ func TestSysMounts(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -132,6 +137,7 @@ func TestSysMounts(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -148,6 +154,7 @@ func TestSysMounts(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -165,6 +172,7 @@ func TestSysMounts(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -229,6 +237,7 @@ func TestSysMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -246,6 +255,7 @@ func TestSysMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -264,6 +274,7 @@ func TestSysMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -280,6 +291,7 @@ func TestSysMount(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -297,6 +309,7 @@ func TestSysMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -314,6 +327,7 @@ func TestSysMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -331,6 +345,7 @@ func TestSysMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -349,6 +364,7 @@ func TestSysMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -365,6 +381,7 @@ func TestSysMount(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -382,6 +399,7 @@ func TestSysMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -547,6 +565,7 @@ func TestSysRemount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -564,6 +583,7 @@ func TestSysRemount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -582,6 +602,7 @@ func TestSysRemount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -598,6 +619,7 @@ func TestSysRemount(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -615,6 +637,7 @@ func TestSysRemount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -632,6 +655,7 @@ func TestSysRemount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -649,6 +673,7 @@ func TestSysRemount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -667,6 +692,7 @@ func TestSysRemount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -683,6 +709,7 @@ func TestSysRemount(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -700,6 +727,7 @@ func TestSysRemount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -764,6 +792,7 @@ func TestSysUnmount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -782,6 +811,7 @@ func TestSysUnmount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -798,6 +828,7 @@ func TestSysUnmount(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -815,6 +846,7 @@ func TestSysUnmount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -832,6 +864,7 @@ func TestSysUnmount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -850,6 +883,7 @@ func TestSysUnmount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -866,6 +900,7 @@ func TestSysUnmount(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -883,6 +918,7 @@ func TestSysUnmount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1033,6 +1069,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1050,6 +1087,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1068,6 +1106,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1084,6 +1123,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1101,6 +1141,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1118,6 +1159,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1135,6 +1177,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1153,6 +1196,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1169,6 +1213,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1186,6 +1231,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1276,6 +1322,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1293,6 +1340,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                map[string]interface{}{"version": "1"},
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1311,6 +1359,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              true,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1327,6 +1376,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  true,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1344,6 +1394,7 @@ func TestSysTuneMount(t *testing.T) {
 				},
 				"local":                  false,
 				"seal_wrap":              false,
+				"dedicated_storage_key":  false,
 				"options":                interface{}(nil),
 				"plugin_version":         "",
 				"running_sha256":         "",
@@ -1361,6 +1412,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1378,6 +1430,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                map[string]interface{}{"version": "1"},
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1396,6 +1449,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              true,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1412,6 +1466,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  true,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -1429,6 +1484,7 @@ func TestSysTuneMount(t *testing.T) {
 			},
 			"local":                  false,
 			"seal_wrap":              false,
+			"dedicated_storage_key":  false,
 			"options":                interface{}(nil),
 			"plugin_version":         "",
 			"running_sha256":         "",
@@ -119,14 +119,20 @@ func handleSysRekeyInitPut(ctx context.Context, core *vault.Core, recovery bool,
 	}
 
 	// Initialize the rekey
-	err := core.RekeyInit(&vault.SealConfig{
+	config := &vault.SealConfig{
 		SecretShares:         req.SecretShares,
 		SecretThreshold:      req.SecretThreshold,
 		StoredShares:         req.StoredShares,
 		PGPKeys:              req.PGPKeys,
 		Backup:               req.Backup,
 		VerificationRequired: req.RequireVerification,
-	}, recovery)
+	}
+	if len(req.ShareCredentials) > 0 {
+		config.ShareCredentials = shareCredentials(req.ShareCredentials)
+		config.ShareCredentialsRPID = req.ShareCredentialsRPID
+	}
+
+	err := core.RekeyInit(config, recovery)
 	if err != nil {
 		respondError(w, err.Code(), err)
 		return
@@ -349,6 +355,9 @@ type RekeyRequest struct {
 	PGPKeys             []string `json:"pgp_keys"`
 	Backup              bool     `json:"backup"`
 	RequireVerification bool     `json:"require_verification"`
+
+	ShareCredentials     []string `json:"share_credentials"`
+	ShareCredentialsRPID string   `json:"share_credentials_rp_id"`
 }
 
 type RekeyStatusResponse struct {
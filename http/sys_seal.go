@@ -8,7 +8,9 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
@@ -127,13 +129,23 @@ func handleSysUnseal(core *vault.Core) http.Handler {
 
 		// Attempt the unseal.  If migrate was specified, the key should correspond
 		// to the old seal.
-		if req.Migrate {
+		switch {
+		case req.Attestation != nil:
+			var attestation *vault.ShareAttestation
+			attestation, err = req.Attestation.decode()
+			if err != nil {
+				respondError(w, http.StatusBadRequest, err)
+				return
+			}
+			_, err = core.UnsealWithAttestation(key, req.Migrate, attestation)
+		case req.Migrate:
 			_, err = core.UnsealMigrate(key)
-		} else {
+		default:
 			_, err = core.Unseal(key)
 		}
 		if err != nil {
 			switch {
+			case errors.Is(err, vault.ErrShareAttestationFailed):
 			case errwrap.ContainsType(err, new(vault.ErrInvalidKey)):
 			case errwrap.Contains(err, vault.ErrBarrierInvalidKey.Error()):
 			case errwrap.Contains(err, vault.ErrBarrierNotInit.Error()):
@@ -181,4 +193,55 @@ type UnsealRequest struct {
 	Key     string
 	Reset   bool
 	Migrate bool
+
+	Attestation *UnsealAttestation `json:"attestation"`
+}
+
+// UnsealAttestation is the WebAuthn assertion of the hardware token bound to
+// the submitted key share, with each field base64url or base64 encoded.
+type UnsealAttestation struct {
+	AuthenticatorData string `json:"authenticator_data"`
+	ClientDataJSON    string `json:"client_data_json"`
+	Signature         string `json:"signature"`
+}
+
+func (a *UnsealAttestation) decode() (*vault.ShareAttestation, error) {
+	var ret vault.ShareAttestation
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *[]byte
+	}{
+		{"authenticator_data", a.AuthenticatorData, &ret.AuthenticatorData},
+		{"client_data_json", a.ClientDataJSON, &ret.ClientDataJSON},
+		{"signature", a.Signature, &ret.Signature},
+	} {
+		decoded, err := decodeAttestationField(field.value)
+		if err != nil || len(decoded) == 0 {
+			return nil, fmt.Errorf("'attestation.%s' must be a non-empty base64url or base64 string", field.name)
+		}
+		*field.dest = decoded
+	}
+	return &ret, nil
+}
+
+// decodeAttestationField decodes a field of an attestation, which WebAuthn
+// clients encode as unpadded base64url.
+func decodeAttestationField(value string) ([]byte, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "=")); err == nil {
+		return decoded, nil
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+// shareCredentials returns the share credentials registered with the given
+// PEM-encoded public keys.
+func shareCredentials(publicKeys []string) []*vault.ShareCredential {
+	creds := make([]*vault.ShareCredential, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		creds[i] = &vault.ShareCredential{
+			PublicKey: publicKey,
+		}
+	}
+	return creds
 }
@@ -54,7 +54,10 @@ type KMS struct {
 	Purpose []string `hcl:"-"`
 
 	Disabled bool
-	Config   map[string]string
+	// Name tells apart the KMS providers of a multi-provider seal, which
+	// are otherwise named after their type.
+	Name   string
+	Config map[string]string
 }
 
 func (k *KMS) GoString() string {
@@ -63,7 +66,7 @@ func (k *KMS) GoString() string {
 
 func parseKMS(result *[]*KMS, list *ast.ObjectList, blockName string, maxKMS int) error {
 	if len(list.Items) > maxKMS {
-		return fmt.Errorf("only two or less %q blocks are permitted", blockName)
+		return fmt.Errorf("only %d or less %q blocks are permitted", maxKMS, blockName)
 	}
 
 	seals := make([]*KMS, 0, len(list.Items))
@@ -102,6 +105,14 @@ func parseKMS(result *[]*KMS, list *ast.ObjectList, blockName string, maxKMS int
 			delete(m, "disabled")
 		}
 
+		var name string
+		if v, ok := m["name"]; ok {
+			if name, err = parseutil.ParseString(v); err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("%s.%s:", blockName, key))
+			}
+			delete(m, "name")
+		}
+
 		strMap := make(map[string]string, len(m))
 		for k, v := range m {
 			s, err := parseutil.ParseString(v)
@@ -115,6 +126,7 @@ func parseKMS(result *[]*KMS, list *ast.ObjectList, blockName string, maxKMS int
 			Type:     strings.ToLower(key),
 			Purpose:  purpose,
 			Disabled: disabled,
+			Name:     name,
 		}
 		if len(strMap) > 0 {
 			seal.Config = strMap
@@ -127,6 +139,11 @@ func parseKMS(result *[]*KMS, list *ast.ObjectList, blockName string, maxKMS int
 	return nil
 }
 
+// MaxSealProviders is the largest number of KMS providers a seal may be
+// made of. One more seal stanza is allowed, for a disabled seal being
+// migrated from.
+const MaxSealProviders = 8
+
 func ParseKMSes(d string) ([]*KMS, error) {
 	// Parse!
 	obj, err := hcl.Parse(d)
@@ -149,7 +166,7 @@ func ParseKMSes(d string) ([]*KMS, error) {
 	}
 
 	if o := list.Filter("seal"); len(o.Items) > 0 {
-		if err := parseKMS(&result.Seals, o, "seal", 3); err != nil {
+		if err := parseKMS(&result.Seals, o, "seal", MaxSealProviders+1); err != nil {
 			return nil, fmt.Errorf("error parsing 'seal': %w", err)
 		}
 	}
@@ -94,6 +94,10 @@ type Listener struct {
 	XForwardedForRejectNotAuthorized    bool                          `hcl:"-"`
 	XForwardedForRejectNotAuthorizedRaw interface{}                   `hcl:"x_forwarded_for_reject_not_authorized,alias:XForwardedForRejectNotAuthorized"`
 
+	XForwardedClientCertHeader             string                        `hcl:"x_forwarded_client_cert_header"`
+	XForwardedClientCertAuthorizedAddrs    []*sockaddr.SockAddrMarshaler `hcl:"-"`
+	XForwardedClientCertAuthorizedAddrsRaw interface{}                   `hcl:"x_forwarded_client_cert_authorized_addrs,alias:XForwardedClientCertAuthorizedAddrs"`
+
 	SocketMode  string `hcl:"socket_mode"`
 	SocketUser  string `hcl:"socket_user"`
 	SocketGroup string `hcl:"socket_group"`
@@ -357,6 +361,24 @@ func ParseListeners(result *SharedConfig, list *ast.ObjectList) error {
 			}
 		}
 
+		// Forwarded client certificate config
+		{
+			if l.XForwardedClientCertAuthorizedAddrsRaw != nil {
+				if l.XForwardedClientCertAuthorizedAddrs, err = parseutil.ParseAddrs(l.XForwardedClientCertAuthorizedAddrsRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("error parsing x_forwarded_client_cert_authorized_addrs: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.XForwardedClientCertAuthorizedAddrsRaw = nil
+			}
+
+			switch {
+			case l.XForwardedClientCertHeader != "" && len(l.XForwardedClientCertAuthorizedAddrs) == 0:
+				return multierror.Prefix(errors.New("x_forwarded_client_cert_header set but no x_forwarded_client_cert_authorized_addrs value"), fmt.Sprintf("listeners.%d", i))
+			case l.XForwardedClientCertHeader == "" && len(l.XForwardedClientCertAuthorizedAddrs) > 0:
+				return multierror.Prefix(errors.New("x_forwarded_client_cert_authorized_addrs set but no x_forwarded_client_cert_header value"), fmt.Sprintf("listeners.%d", i))
+			}
+		}
+
 		// Telemetry
 		{
 			if l.Telemetry.UnauthenticatedMetricsAccessRaw != nil {
@@ -58,10 +58,11 @@ var getMmapFlags = func(string) int { return 0 }
 
 // Verify RaftBackend satisfies the correct interfaces
 var (
-	_ physical.Backend       = (*RaftBackend)(nil)
-	_ physical.Transactional = (*RaftBackend)(nil)
-	_ physical.HABackend     = (*RaftBackend)(nil)
-	_ physical.Lock          = (*RaftLock)(nil)
+	_ physical.Backend        = (*RaftBackend)(nil)
+	_ physical.Transactional  = (*RaftBackend)(nil)
+	_ physical.HABackend      = (*RaftBackend)(nil)
+	_ physical.ChecksumExempt = (*RaftBackend)(nil)
+	_ physical.Lock           = (*RaftLock)(nil)
 )
 
 var (
@@ -194,6 +195,13 @@ type RaftBackend struct {
 
 	effectiveSDKVersion string
 	failGetInTxn        *uint32
+
+	// autoSnapshotConfig is the configuration of the automatic snapshots,
+	// nil if they are not enabled. autoSnapshots is the job taking them,
+	// while the node is active.
+	autoSnapshotConfig *autoSnapshotConfig
+	autoSnapshots      *autoSnapshotter
+	autoSnapshotLock   sync.Mutex
 }
 
 // LeaderJoinInfo contains information required by a node to join itself as a
@@ -520,6 +528,11 @@ func NewRaftBackend(conf map[string]string, logger log.Logger) (physical.Backend
 		return nil, fmt.Errorf("setting %s to true is only valid if at least one retry_join stanza is specified", raftNonVoterConfigKey)
 	}
 
+	autoSnapshotConfig, err := parseAutoSnapshotConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	return &RaftBackend{
 		logger:                     logger,
 		fsm:                        fsm,
@@ -540,6 +553,7 @@ func NewRaftBackend(conf map[string]string, logger log.Logger) (physical.Backend
 		nonVoter:                   nonVoter,
 		upgradeVersion:             upgradeVersion,
 		failGetInTxn:               new(uint32),
+		autoSnapshotConfig:         autoSnapshotConfig,
 	}, nil
 }
 
@@ -1490,6 +1504,12 @@ func (b *RaftBackend) Delete(ctx context.Context, path string) error {
 	return err
 }
 
+// SkipChecksums exempts raft from storage checksums: Core relies on the
+// backend being a *RaftBackend, which a physical.Checksummer would hide.
+func (b *RaftBackend) SkipChecksums() bool {
+	return true
+}
+
 // Get returns the value corresponding to the given path from the fsm
 func (b *RaftBackend) Get(ctx context.Context, path string) (*physical.Entry, error) {
 	defer metrics.MeasureSince([]string{"raft-storage", "get"}, time.Now())
@@ -160,6 +160,15 @@ type FollowerState struct {
 	LastTerm        uint64
 	IsDead          *atomic.Bool
 	DesiredSuffrage string
+
+	// DeadSince is the time at which the follower was considered dead, zero
+	// if it is alive.
+	DeadSince *atomic.Time
+
+	// HealthySince is the time since which the follower has been in contact
+	// with the leader without interruption, zero if it is not.
+	HealthySince *atomic.Time
+
 	Version         string
 	UpgradeVersion  string
 }
@@ -198,16 +207,23 @@ func (s *FollowerStates) Update(req *EchoRequestUpdate) bool {
 	state, present := s.followers[req.NodeID]
 	if !present {
 		state = &FollowerState{
-			IsDead: atomic.NewBool(false),
+			IsDead:       atomic.NewBool(false),
+			DeadSince:    atomic.NewTime(time.Time{}),
+			HealthySince: atomic.NewTime(time.Time{}),
 		}
 		s.followers[req.NodeID] = state
 	}
 
+	now := time.Now()
 	state.IsDead.Store(false)
+	state.DeadSince.Store(time.Time{})
+	if state.HealthySince.Load().IsZero() {
+		state.HealthySince.Store(now)
+	}
 	state.AppliedIndex = req.AppliedIndex
 	state.LastTerm = req.Term
 	state.DesiredSuffrage = req.DesiredSuffrage
-	state.LastHeartbeat = time.Now()
+	state.LastHeartbeat = now
 	state.Version = req.SDKVersion
 	state.UpgradeVersion = req.UpgradeVersion
 
@@ -266,6 +282,10 @@ type Delegate struct {
 	dl               sync.RWMutex
 	inflightRemovals map[raft.ServerID]bool
 	emptyVersionLogs map[raft.ServerID]struct{}
+
+	// removalLock serializes the removals of failed servers, so that each
+	// one is checked against the configuration left by the previous one.
+	removalLock sync.Mutex
 }
 
 func NewDelegate(b *RaftBackend) *Delegate {
@@ -418,8 +438,10 @@ func (d *Delegate) KnownServers() map[raft.ServerID]*autopilot.Server {
 			server.NodeType = autopilot.NodeVoter
 		}
 
-		switch state.IsDead.Load() {
-		case true:
+		// A dead server is only reported once it stayed dead for the
+		// stabilization time, so that flapping servers are not removed.
+		switch {
+		case state.IsDead.Load() && time.Since(state.DeadSince.Load()) >= d.autopilotConfig.ServerStabilizationTime:
 			d.logger.Debug("informing autopilot that the node left", "id", id)
 			server.NodeStatus = autopilot.NodeLeft
 		default:
@@ -473,6 +495,14 @@ func (d *Delegate) RemoveFailedServer(server *autopilot.Server) {
 		d.inflightRemovals[server.ID] = true
 		d.dl.Unlock()
 
+		d.removalLock.Lock()
+		defer d.removalLock.Unlock()
+
+		if err := d.checkFailedServerRemoval(server.ID); err != nil {
+			d.logger.Warn("not removing dead server", "id", server.ID, "reason", err)
+			return
+		}
+
 		d.logger.Info("removing dead server from raft configuration", "id", server.ID)
 		if future := d.raft.RemoveServer(server.ID, 0, 0); future.Error() != nil {
 			d.logger.Error("failed to remove server", "server_id", server.ID, "server_address", server.Address, "server_name", server.Name, "error", future.Error())
@@ -483,6 +513,110 @@ func (d *Delegate) RemoveFailedServer(server *autopilot.Server) {
 	}()
 }
 
+// checkFailedServerRemoval checks, against the current raft configuration,
+// that removing the failed server keeps at least min_quorum voters, and
+// enough healthy voters to make a quorum of the voters left. Only the voters
+// which have been healthy for the stabilization time are counted.
+func (d *Delegate) checkFailedServerRemoval(id raft.ServerID) error {
+	d.l.RLock()
+	defer d.l.RUnlock()
+
+	if d.raft == nil {
+		return errors.New("raft storage is sealed")
+	}
+	future := d.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return fmt.Errorf("failed to get raft configuration: %w", err)
+	}
+
+	d.followerStates.l.RLock()
+	defer d.followerStates.l.RUnlock()
+
+	now := time.Now()
+	healthy := func(id raft.ServerID) bool {
+		if string(id) == d.localID {
+			return true
+		}
+		state, ok := d.followerStates.followers[string(id)]
+		if !ok || state.IsDead.Load() {
+			return false
+		}
+		healthySince := state.HealthySince.Load()
+		return !healthySince.IsZero() &&
+			now.Sub(state.LastHeartbeat) <= d.autopilotConfig.LastContactThreshold &&
+			now.Sub(healthySince) >= d.autopilotConfig.ServerStabilizationTime
+	}
+
+	return failedServerRemovalSafe(future.Configuration().Servers, id, d.autopilotConfig.MinQuorum, healthy)
+}
+
+// quorumSafePromoter wraps a promoter to check the removal of stale voters,
+// which autopilot removes itself, like the one of failed servers.
+type quorumSafePromoter struct {
+	autopilot.Promoter
+	delegate *Delegate
+}
+
+// FilterFailedServerRemovals only lets through the first stale voter whose
+// removal is safe, so that each removal is checked against the configuration
+// left by the previous one on the next reconciliation.
+func (p *quorumSafePromoter) FilterFailedServerRemovals(conf *autopilot.Config, state *autopilot.State, failed *autopilot.FailedServers) *autopilot.FailedServers {
+	failed = p.Promoter.FilterFailedServerRemovals(conf, state, failed)
+	if failed == nil || len(failed.StaleVoters) == 0 {
+		return failed
+	}
+
+	p.delegate.removalLock.Lock()
+	defer p.delegate.removalLock.Unlock()
+
+	var staleVoters []raft.ServerID
+	for _, id := range failed.StaleVoters {
+		if err := p.delegate.checkFailedServerRemoval(id); err != nil {
+			p.delegate.logger.Warn("not removing stale server", "id", id, "reason", err)
+			continue
+		}
+		staleVoters = append(staleVoters, id)
+		break
+	}
+	failed.StaleVoters = staleVoters
+	return failed
+}
+
+// failedServerRemovalSafe returns an error if removing the server from the
+// given servers would leave fewer than minQuorum voters, or fewer healthy
+// voters than a quorum of the voters left.
+func failedServerRemovalSafe(servers []raft.Server, id raft.ServerID, minQuorum uint, healthy func(raft.ServerID) bool) error {
+	found := false
+	var voters, healthyVoters int
+	for _, server := range servers {
+		if server.ID == id {
+			found = true
+			if server.Suffrage != raft.Voter {
+				// Non-voters do not take part in the quorum.
+				return nil
+			}
+			continue
+		}
+		if server.Suffrage != raft.Voter {
+			continue
+		}
+		voters++
+		if healthy(server.ID) {
+			healthyVoters++
+		}
+	}
+
+	switch {
+	case !found:
+		return errors.New("server is not part of the raft configuration")
+	case voters < int(minQuorum):
+		return fmt.Errorf("removal would leave %d voters, fewer than min_quorum of %d", voters, minQuorum)
+	case healthyVoters < voters/2+1:
+		return fmt.Errorf("removal would leave %d healthy voters out of %d, which is not a quorum", healthyVoters, voters)
+	}
+	return nil
+}
+
 // SetFollowerStates sets the followerStates field in the backend to track peers
 // in the raft cluster.
 func (b *RaftBackend) SetFollowerStates(states *FollowerStates) {
@@ -549,16 +683,22 @@ func (b *RaftBackend) startFollowerHeartbeatTracker() {
 		b.followerStates.l.RLock()
 		myAppliedIndex := b.raft.AppliedIndex()
 		for peerID, state := range b.followerStates.followers {
-			timeSinceLastHeartbeat := time.Now().Sub(state.LastHeartbeat) / time.Millisecond
+			now := time.Now()
+			timeSinceLastHeartbeat := now.Sub(state.LastHeartbeat) / time.Millisecond
 			followerGauge(peerID, "last_heartbeat_ms", float32(timeSinceLastHeartbeat))
 			followerGauge(peerID, "applied_index_delta", float32(myAppliedIndex-state.AppliedIndex))
 
+			// Losing contact interrupts the stable period of the follower.
+			if now.Sub(state.LastHeartbeat) > b.autopilotConfig.LastContactThreshold {
+				state.HealthySince.Store(time.Time{})
+			}
+
 			if b.autopilotConfig.CleanupDeadServers && b.autopilotConfig.DeadServerLastContactThreshold != 0 {
 				if state.LastHeartbeat.IsZero() || state.IsDead.Load() {
 					continue
 				}
-				now := time.Now()
 				if now.After(state.LastHeartbeat.Add(b.autopilotConfig.DeadServerLastContactThreshold)) {
+					state.DeadSince.Store(now)
 					state.IsDead.Store(true)
 				}
 			}
@@ -782,9 +922,13 @@ func (b *RaftBackend) SetupAutopilot(ctx context.Context, storageConfig *Autopil
 	b.autopilotConfig.Merge(storageConfig)
 
 	// Create the autopilot instance
+	delegate := NewDelegate(b)
 	options := []autopilot.Option{
 		autopilot.WithLogger(b.logger),
-		autopilot.WithPromoter(autopilot.DefaultPromoter()),
+		autopilot.WithPromoter(&quorumSafePromoter{
+			Promoter: autopilot.DefaultPromoter(),
+			delegate: delegate,
+		}),
 	}
 	if b.autopilotReconcileInterval != 0 {
 		options = append(options, autopilot.WithReconcileInterval(b.autopilotReconcileInterval))
@@ -792,7 +936,7 @@ func (b *RaftBackend) SetupAutopilot(ctx context.Context, storageConfig *Autopil
 	if b.autopilotUpdateInterval != 0 {
 		options = append(options, autopilot.WithUpdateInterval(b.autopilotUpdateInterval))
 	}
-	b.autopilot = autopilot.New(b.raft, NewDelegate(b), options...)
+	b.autopilot = autopilot.New(b.raft, delegate, options...)
 	b.followerStates = followerStates
 	b.followerHeartbeatTicker = time.NewTicker(1 * time.Second)
 
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedServerRemovalSafe(t *testing.T) {
+	servers := func(voters, nonVoters int) []raft.Server {
+		var ret []raft.Server
+		for i := 0; i < voters; i++ {
+			ret = append(ret, raft.Server{ID: raft.ServerID(rune('a' + i)), Suffrage: raft.Voter})
+		}
+		for i := 0; i < nonVoters; i++ {
+			ret = append(ret, raft.Server{ID: raft.ServerID(rune('v' + i)), Suffrage: raft.Nonvoter})
+		}
+		return ret
+	}
+	unhealthy := func(ids ...raft.ServerID) func(raft.ServerID) bool {
+		return func(id raft.ServerID) bool {
+			for _, u := range ids {
+				if u == id {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	tCases := []struct {
+		name      string
+		servers   []raft.Server
+		remove    raft.ServerID
+		minQuorum uint
+		healthy   func(raft.ServerID) bool
+		safe      bool
+	}{
+		{"healthy cluster", servers(5, 0), "e", 3, unhealthy("e"), true},
+		{"below min quorum", servers(3, 0), "c", 3, unhealthy("c"), false},
+		{"no quorum left", servers(5, 0), "e", 3, unhealthy("c", "d", "e"), false},
+		{"quorum left", servers(5, 0), "e", 3, unhealthy("d", "e"), true},
+		{"non-voter", servers(3, 1), "v", 3, unhealthy("a", "b", "c", "v"), true},
+		{"unknown server", servers(5, 0), "z", 3, unhealthy(), false},
+	}
+
+	for _, tCase := range tCases {
+		err := failedServerRemovalSafe(tCase.servers, tCase.remove, tCase.minQuorum, tCase.healthy)
+		if tCase.safe {
+			require.NoError(t, err, tCase.name)
+		} else {
+			require.Error(t, err, tCase.name)
+		}
+	}
+}
+
+func TestFollowerStates_Stability(t *testing.T) {
+	s := NewFollowerStates()
+	require.True(t, s.Update(&EchoRequestUpdate{NodeID: "node1"}))
+
+	state := s.followers["node1"]
+	healthySince := state.HealthySince.Load()
+	require.False(t, healthySince.IsZero())
+
+	// Heartbeats do not restart the stable period.
+	require.False(t, s.Update(&EchoRequestUpdate{NodeID: "node1"}))
+	require.Equal(t, healthySince, state.HealthySince.Load())
+
+	// A follower coming back from the dead is alive again, and starts a new
+	// stable period once the heartbeat tracker interrupted the previous one.
+	state.IsDead.Store(true)
+	state.DeadSince.Store(time.Now())
+	state.HealthySince.Store(time.Time{})
+	s.Update(&EchoRequestUpdate{NodeID: "node1"})
+	require.False(t, state.IsDead.Load())
+	require.True(t, state.DeadSince.Load().IsZero())
+	require.False(t, state.HealthySince.Load().Before(healthySince))
+}
@@ -0,0 +1,514 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/vault/seal"
+)
+
+const (
+	// autoSnapshotPrefix and autoSnapshotSuffix surround the timestamp in
+	// the names of the automatic snapshots. Only the objects named this way
+	// are considered for retention.
+	autoSnapshotPrefix = "bao-snapshot-"
+	autoSnapshotSuffix = ".snap"
+
+	// autoSnapshotTimeFormat sorts lexicographically in chronological order.
+	autoSnapshotTimeFormat = "20060102T150405Z"
+
+	// autoSnapshotPartialSuffix is the suffix of the files being written to
+	// a file destination.
+	autoSnapshotPartialSuffix = ".partial"
+
+	// autoSnapshotUploadAttempts is the number of times an upload is
+	// attempted before the snapshot is given up on.
+	autoSnapshotUploadAttempts = 5
+)
+
+var (
+	autoSnapshotMinBackoff = time.Second
+	autoSnapshotMaxBackoff = 30 * time.Second
+)
+
+// snapshotDestination stores the automatic snapshots.
+type snapshotDestination interface {
+	// Upload stores the snapshot under the given name. A failed upload must
+	// not leave a partial snapshot behind.
+	Upload(ctx context.Context, name string, r io.Reader) error
+
+	// List returns the names of the automatic snapshots stored.
+	List(ctx context.Context) ([]string, error)
+
+	// Delete removes the snapshot of the given name.
+	Delete(ctx context.Context, name string) error
+
+	// CleanupPartial removes what remains of uploads which were interrupted,
+	// e.g. by a crash.
+	CleanupPartial(ctx context.Context) error
+}
+
+// autoSnapshotConfig is the configuration of the automatic snapshots.
+type autoSnapshotConfig struct {
+	schedule    snapshotSchedule
+	destination snapshotDestination
+
+	// retain is the number of snapshots kept, all of them if zero.
+	retain int
+}
+
+// parseAutoSnapshotConfig parses the auto_snapshot_* options of the backend.
+// It returns nil if automatic snapshots are not enabled.
+func parseAutoSnapshotConfig(conf map[string]string) (*autoSnapshotConfig, error) {
+	scheduleRaw := conf["auto_snapshot_schedule"]
+	if scheduleRaw == "" {
+		for k := range conf {
+			if strings.HasPrefix(k, "auto_snapshot_") {
+				return nil, fmt.Errorf("%s requires auto_snapshot_schedule to be set", k)
+			}
+		}
+		return nil, nil
+	}
+
+	schedule, err := parseSnapshotSchedule(scheduleRaw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auto_snapshot_schedule: %w", err)
+	}
+
+	ret := &autoSnapshotConfig{
+		schedule: schedule,
+	}
+
+	if retainRaw := conf["auto_snapshot_retain"]; retainRaw != "" {
+		ret.retain, err = strconv.Atoi(retainRaw)
+		if err != nil || ret.retain < 0 {
+			return nil, fmt.Errorf("auto_snapshot_retain must be a non-negative integer")
+		}
+	}
+
+	destination := conf["auto_snapshot_destination"]
+	if destination == "" {
+		return nil, fmt.Errorf("auto_snapshot_destination must be set")
+	}
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auto_snapshot_destination: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Host != "" || u.Path == "" {
+			return nil, fmt.Errorf("auto_snapshot_destination must be of the form file:///path/to/dir")
+		}
+		ret.destination = &fileSnapshotDestination{dir: u.Path}
+	case "s3":
+		ret.destination, err = newS3SnapshotDestination(u, conf)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auto_snapshot_destination scheme %q", u.Scheme)
+	}
+
+	return ret, nil
+}
+
+// autoSnapshotter takes the automatic snapshots on schedule.
+type autoSnapshotter struct {
+	logger  log.Logger
+	config  *autoSnapshotConfig
+	backend *RaftBackend
+	access  seal.Access
+
+	cancel context.CancelFunc
+	doneCh chan struct{}
+}
+
+// StartAutoSnapshots starts taking the automatic snapshots configured for the
+// backend, if any. It should only be called on the active node, so that the
+// snapshots are taken once for the cluster.
+func (b *RaftBackend) StartAutoSnapshots(access seal.Access) {
+	b.autoSnapshotLock.Lock()
+	defer b.autoSnapshotLock.Unlock()
+
+	if b.autoSnapshotConfig == nil || b.autoSnapshots != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &autoSnapshotter{
+		logger:  b.logger.Named("snapshot.auto"),
+		config:  b.autoSnapshotConfig,
+		backend: b,
+		access:  access,
+		cancel:  cancel,
+		doneCh:  make(chan struct{}),
+	}
+	b.autoSnapshots = s
+
+	go s.run(ctx)
+}
+
+// StopAutoSnapshots stops taking automatic snapshots, interrupting any
+// snapshot in progress, and waits for the job to exit.
+func (b *RaftBackend) StopAutoSnapshots() {
+	b.autoSnapshotLock.Lock()
+	defer b.autoSnapshotLock.Unlock()
+
+	if b.autoSnapshots == nil {
+		return
+	}
+	b.autoSnapshots.cancel()
+	<-b.autoSnapshots.doneCh
+	b.autoSnapshots = nil
+}
+
+func (s *autoSnapshotter) run(ctx context.Context) {
+	defer close(s.doneCh)
+
+	if err := s.config.destination.CleanupPartial(ctx); err != nil {
+		s.logger.Warn("failed to clean up partial snapshots", "error", err)
+	}
+
+	for {
+		next := s.config.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn("snapshot schedule does not activate anymore")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := s.snapshot(ctx, next); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("failed to take automatic snapshot", "error", err)
+			metrics.IncrCounter([]string{"raft_storage", "snapshot", "auto", "failure"}, 1)
+			continue
+		}
+		metrics.IncrCounter([]string{"raft_storage", "snapshot", "auto", "success"}, 1)
+	}
+}
+
+// snapshot takes a snapshot, uploads it and prunes the snapshots beyond the
+// retention count.
+func (s *autoSnapshotter) snapshot(ctx context.Context, now time.Time) error {
+	defer metrics.MeasureSince([]string{"raft_storage", "snapshot", "auto"}, time.Now())
+
+	name := autoSnapshotPrefix + now.UTC().Format(autoSnapshotTimeFormat) + autoSnapshotSuffix
+
+	// Spool the snapshot to a local file, so that uploads can be retried
+	// without taking another one.
+	f, err := os.CreateTemp("", "bao-auto-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	if err := s.backend.Snapshot(f, s.access); err != nil {
+		return fmt.Errorf("failed to take snapshot: %w", err)
+	}
+
+	if err := s.upload(ctx, name, f); err != nil {
+		return err
+	}
+	s.logger.Info("automatic snapshot uploaded", "name", name)
+
+	if err := s.prune(ctx); err != nil {
+		s.logger.Warn("failed to prune automatic snapshots", "error", err)
+	}
+	return nil
+}
+
+// upload uploads the snapshot, retrying with an exponential backoff.
+func (s *autoSnapshotter) upload(ctx context.Context, name string, f *os.File) error {
+	backoff := autoSnapshotMinBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind snapshot: %w", err)
+		}
+		err = s.config.destination.Upload(ctx, name, f)
+		if err == nil {
+			return nil
+		}
+		if attempt == autoSnapshotUploadAttempts {
+			break
+		}
+
+		s.logger.Warn("failed to upload snapshot, retrying", "name", name, "attempt", attempt, "backoff", backoff, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > autoSnapshotMaxBackoff {
+			backoff = autoSnapshotMaxBackoff
+		}
+	}
+
+	return fmt.Errorf("failed to upload snapshot %q after %d attempts: %w", name, autoSnapshotUploadAttempts, err)
+}
+
+// prune deletes the oldest automatic snapshots beyond the retention count.
+func (s *autoSnapshotter) prune(ctx context.Context) error {
+	if s.config.retain == 0 {
+		return nil
+	}
+
+	names, err := s.config.destination.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	if len(names) <= s.config.retain {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-s.config.retain] {
+		if err := s.config.destination.Delete(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete snapshot %q: %w", name, err)
+		}
+		s.logger.Debug("deleted automatic snapshot", "name", name)
+	}
+	return nil
+}
+
+// isAutoSnapshotName returns true if the name is the one of an automatic
+// snapshot.
+func isAutoSnapshotName(name string) bool {
+	return strings.HasPrefix(name, autoSnapshotPrefix) && strings.HasSuffix(name, autoSnapshotSuffix)
+}
+
+// fileSnapshotDestination stores the snapshots in a local directory, which
+// may be a mounted network file system.
+type fileSnapshotDestination struct {
+	dir string
+}
+
+func (d *fileSnapshotDestination) Upload(_ context.Context, name string, r io.Reader) (retErr error) {
+	if err := os.MkdirAll(d.dir, 0o700); err != nil {
+		return err
+	}
+
+	// Write to a hidden file renamed once complete, so that partial
+	// snapshots are never mistaken for complete ones.
+	f, err := os.CreateTemp(d.dir, "."+name+".*"+autoSnapshotPartialSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if retErr != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(d.dir, name))
+}
+
+func (d *fileSnapshotDestination) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && isAutoSnapshotName(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (d *fileSnapshotDestination) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(d.dir, name))
+}
+
+func (d *fileSnapshotDestination) CleanupPartial(_ context.Context) error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "."+autoSnapshotPrefix) && strings.HasSuffix(name, autoSnapshotPartialSuffix) {
+			if err := os.Remove(filepath.Join(d.dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// s3SnapshotDestination stores the snapshots in an S3 bucket, or in any
+// object storage exposing an S3-compatible API.
+type s3SnapshotDestination struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func newS3SnapshotDestination(u *url.URL, conf map[string]string) (*s3SnapshotDestination, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("auto_snapshot_destination must be of the form s3://bucket/prefix")
+	}
+
+	awsConfig := aws.NewConfig()
+	if region := conf["auto_snapshot_s3_region"]; region != "" {
+		awsConfig = awsConfig.WithRegion(region)
+	}
+	if endpoint := conf["auto_snapshot_s3_endpoint"]; endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(endpoint)
+	}
+	if raw := conf["auto_snapshot_s3_force_path_style"]; raw != "" {
+		forcePathStyle, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse auto_snapshot_s3_force_path_style: %w", err)
+		}
+		awsConfig = awsConfig.WithS3ForcePathStyle(forcePathStyle)
+	}
+
+	// Credentials come from the default chain unless given.
+	accessKey, secretKey := conf["auto_snapshot_s3_access_key"], conf["auto_snapshot_s3_secret_key"]
+	switch {
+	case accessKey != "" && secretKey != "":
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, ""))
+	case accessKey != "" || secretKey != "":
+		return nil, fmt.Errorf("auto_snapshot_s3_access_key and auto_snapshot_s3_secret_key must be set together")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
+	}
+
+	prefix := strings.TrimPrefix(u.Path, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	client := s3.New(sess)
+	return &s3SnapshotDestination{
+		client: client,
+		// Failed multipart uploads are aborted, which removes their parts.
+		uploader: s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+			u.LeavePartsOnError = false
+		}),
+		bucket: u.Host,
+		prefix: prefix,
+	}, nil
+}
+
+func (d *s3SnapshotDestination) Upload(ctx context.Context, name string, r io.Reader) error {
+	_, err := d.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.prefix + name),
+		Body:   r,
+	})
+	return err
+}
+
+func (d *s3SnapshotDestination) List(ctx context.Context) ([]string, error) {
+	var names []string
+	err := d.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(d.bucket),
+		Prefix: aws.String(d.prefix + autoSnapshotPrefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			name := strings.TrimPrefix(aws.StringValue(obj.Key), d.prefix)
+			if isAutoSnapshotName(name) {
+				names = append(names, name)
+			}
+		}
+		return true
+	})
+	return names, err
+}
+
+func (d *s3SnapshotDestination) Delete(ctx context.Context, name string) error {
+	_, err := d.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(d.bucket),
+		Key:    aws.String(d.prefix + name),
+	})
+	return err
+}
+
+func (d *s3SnapshotDestination) CleanupPartial(ctx context.Context) error {
+	var uploads []*s3.MultipartUpload
+	err := d.client.ListMultipartUploadsPagesWithContext(ctx, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(d.bucket),
+		Prefix: aws.String(d.prefix + autoSnapshotPrefix),
+	}, func(page *s3.ListMultipartUploadsOutput, _ bool) bool {
+		uploads = append(uploads, page.Uploads...)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		if _, err := d.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(d.bucket),
+			Key:      upload.Key,
+			UploadId: upload.UploadId,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	_ snapshotDestination = (*fileSnapshotDestination)(nil)
+	_ snapshotDestination = (*s3SnapshotDestination)(nil)
+)
@@ -0,0 +1,175 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package raft
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnapshotSchedule(t *testing.T) {
+	from := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC) // a Wednesday
+
+	for spec, expected := range map[string]time.Time{
+		"*/15 * * * *":     time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC),
+		"0 3 * * *":        time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC),
+		"30 2 1,15 * *":    time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC),
+		"0 0 * * 7":        time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":       time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 9-17/4 * * 1-5": time.Date(2024, 1, 31, 13, 0, 0, 0, time.UTC),
+		"@daily":           time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"@every 6h":        from.Add(6 * time.Hour),
+	} {
+		s, err := parseSnapshotSchedule(spec)
+		require.NoError(t, err, spec)
+		require.Equal(t, expected, s.Next(from), spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"0 0 31 2 *",
+		"@every 10s",
+		"@fortnightly",
+	} {
+		_, err := parseSnapshotSchedule(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestParseAutoSnapshotConfig(t *testing.T) {
+	config, err := parseAutoSnapshotConfig(map[string]string{"path": "/raft"})
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	for _, conf := range []map[string]string{
+		{"auto_snapshot_retain": "3"},
+		{"auto_snapshot_schedule": "@daily"},
+		{"auto_snapshot_schedule": "@daily", "auto_snapshot_destination": "ftp://host/dir"},
+		{"auto_snapshot_schedule": "@daily", "auto_snapshot_destination": "file://host/dir"},
+		{"auto_snapshot_schedule": "@daily", "auto_snapshot_destination": "s3:///prefix"},
+		{"auto_snapshot_schedule": "@daily", "auto_snapshot_destination": "s3://bucket", "auto_snapshot_s3_access_key": "key"},
+		{"auto_snapshot_schedule": "@daily", "auto_snapshot_destination": "file:///dir", "auto_snapshot_retain": "-1"},
+	} {
+		_, err := parseAutoSnapshotConfig(conf)
+		require.Error(t, err, "conf: %v", conf)
+	}
+
+	config, err = parseAutoSnapshotConfig(map[string]string{
+		"auto_snapshot_schedule":    "0 */6 * * *",
+		"auto_snapshot_destination": "s3://bucket/bao/snapshots",
+		"auto_snapshot_retain":      "5",
+		"auto_snapshot_s3_region":   "us-east-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, config.retain)
+	s3Dest := config.destination.(*s3SnapshotDestination)
+	require.Equal(t, "bucket", s3Dest.bucket)
+	require.Equal(t, "bao/snapshots/", s3Dest.prefix)
+}
+
+// flakyDestination fails the first uploads.
+type flakyDestination struct {
+	*fileSnapshotDestination
+	failures int
+}
+
+func (d *flakyDestination) Upload(ctx context.Context, name string, r io.Reader) error {
+	if d.failures > 0 {
+		d.failures--
+		// Consume part of the snapshot, as an interrupted upload would.
+		if _, err := io.CopyN(io.Discard, r, 10); err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	}
+	return d.fileSnapshotDestination.Upload(ctx, name, r)
+}
+
+func TestRaft_AutoSnapshot(t *testing.T) {
+	prevMin, prevMax := autoSnapshotMinBackoff, autoSnapshotMaxBackoff
+	autoSnapshotMinBackoff, autoSnapshotMaxBackoff = time.Millisecond, 2*time.Millisecond
+	defer func() {
+		autoSnapshotMinBackoff, autoSnapshotMaxBackoff = prevMin, prevMax
+	}()
+
+	raft, dir := GetRaft(t, true, false)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	dest := &flakyDestination{
+		fileSnapshotDestination: &fileSnapshotDestination{dir: filepath.Join(t.TempDir(), "snapshots")},
+		failures:                2,
+	}
+	s := &autoSnapshotter{
+		logger:  hclog.NewNullLogger(),
+		backend: raft,
+		config: &autoSnapshotConfig{
+			destination: dest,
+			retain:      2,
+		},
+	}
+
+	// Uploads are retried, without leaving partial snapshots behind.
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.snapshot(ctx, start))
+	entries, err := os.ReadDir(dest.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "bao-snapshot-20240101T000000Z.snap", entries[0].Name())
+
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	require.NotZero(t, info.Size())
+
+	// Uploads are given up on after a few attempts.
+	dest.failures = autoSnapshotUploadAttempts
+	require.Error(t, s.snapshot(ctx, start.Add(time.Hour)))
+
+	// Only the most recent snapshots are retained, and other files are left
+	// alone.
+	require.NoError(t, os.WriteFile(filepath.Join(dest.dir, "notes.txt"), []byte("keep"), 0o600))
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.snapshot(ctx, start.Add(time.Duration(i)*time.Hour)))
+	}
+	names, err := dest.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bao-snapshot-20240101T020000Z.snap", "bao-snapshot-20240101T030000Z.snap"}, names)
+	require.FileExists(t, filepath.Join(dest.dir, "notes.txt"))
+}
+
+func TestFileSnapshotDestination_CleanupPartial(t *testing.T) {
+	dest := &fileSnapshotDestination{dir: t.TempDir()}
+	ctx := context.Background()
+
+	partial := filepath.Join(dest.dir, ".bao-snapshot-20240101T000000Z.snap.123.partial")
+	require.NoError(t, os.WriteFile(partial, []byte("trunc"), 0o600))
+	require.NoError(t, dest.Upload(ctx, "bao-snapshot-20240101T010000Z.snap", io.LimitReader(zeroReader{}, 100)))
+
+	require.NoError(t, dest.CleanupPartial(ctx))
+	require.NoFileExists(t, partial)
+	names, err := dest.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bao-snapshot-20240101T010000Z.snap"}, names)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
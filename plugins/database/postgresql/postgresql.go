@@ -38,6 +38,7 @@ ALTER ROLE "{{username}}" WITH PASSWORD '{{password}}';
 
 var (
 	_ dbplugin.Database       = (*PostgreSQL)(nil)
+	_ dbplugin.UserValidator  = (*PostgreSQL)(nil)
 	_ logical.PluginVersioner = (*PostgreSQL)(nil)
 
 	// postgresEndStatement is basically the word "END" but
@@ -355,6 +356,26 @@ func (p *PostgreSQL) DeleteUser(ctx context.Context, req dbplugin.DeleteUserRequ
 	return dbplugin.DeleteUserResponse{}, p.customDeleteUser(ctx, req.Username, req.Statements.Commands)
 }
 
+func (p *PostgreSQL) ValidateUser(ctx context.Context, req dbplugin.ValidateUserRequest) (dbplugin.ValidateUserResponse, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	db, err := p.getConnection(ctx)
+	if err != nil {
+		return dbplugin.ValidateUserResponse{}, err
+	}
+
+	var exists bool
+	err = db.QueryRowContext(ctx, "SELECT exists (SELECT rolname FROM pg_roles WHERE rolname=$1);", req.Username).Scan(&exists)
+	if err != nil && err != sql.ErrNoRows {
+		return dbplugin.ValidateUserResponse{}, err
+	}
+
+	return dbplugin.ValidateUserResponse{
+		Exists: exists,
+	}, nil
+}
+
 func (p *PostgreSQL) customDeleteUser(ctx context.Context, username string, revocationStmts []string) error {
 	db, err := p.getConnection(ctx)
 	if err != nil {
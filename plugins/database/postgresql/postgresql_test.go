@@ -743,6 +743,46 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
+func TestValidateUser(t *testing.T) {
+	db, cleanup := getPostgreSQL(t, nil)
+	defer cleanup()
+
+	password := "myreallysecurepassword"
+	createReq := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "test",
+			RoleName:    "test",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{createAdminUser},
+		},
+		Password:   password,
+		Expiration: time.Now().Add(time.Minute),
+	}
+	createResp := dbtesting.AssertNewUser(t, db, createReq)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	validateReq := dbplugin.ValidateUserRequest{
+		Username: createResp.Username,
+	}
+	resp, err := db.ValidateUser(ctx, validateReq)
+	require.NoError(t, err)
+	require.True(t, resp.Exists)
+
+	// Drop the user outside of the plugin
+	conn, err := sql.Open("pgx", db.ConnectionURL)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("DROP ROLE %q;", createResp.Username))
+	require.NoError(t, err)
+
+	resp, err = db.ValidateUser(ctx, validateReq)
+	require.NoError(t, err)
+	require.False(t, resp.Exists)
+}
+
 type credsAssertion func(t testing.TB, connURL, username, password string)
 
 func assertCreds(assertions ...credsAssertion) credsAssertion {
@@ -58,6 +58,7 @@ bao auth enable "kubernetes"
 bao auth enable "ldap"
 bao auth enable "radius"
 bao auth enable "userpass"
+bao auth enable "webauthn"
 
 # Enable secrets plugins
 bao secrets enable "database"
@@ -68,6 +69,7 @@ bao secrets enable "ldap"
 bao secrets enable "pki"
 bao secrets enable "rabbitmq"
 bao secrets enable "ssh"
+bao secrets enable "sts"
 bao secrets enable "totp"
 bao secrets enable "transit"
 
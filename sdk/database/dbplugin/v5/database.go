@@ -5,6 +5,7 @@ package dbplugin
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -36,6 +37,29 @@ type Database interface {
 	Close() error
 }
 
+// ErrValidateUserUnsupported is returned by ValidateUser when the database
+// plugin does not support validating users.
+var ErrValidateUserUnsupported = errors.New("validating users is not supported by this database plugin")
+
+// UserValidator is an optional interface which a Database may implement to
+// report whether a user still exists within the database. It is used to
+// detect credentials which were revoked outside of Vault.
+type UserValidator interface {
+	// ValidateUser checks whether the user exists within the database. It
+	// should only return an error if the check itself could not be performed.
+	ValidateUser(ctx context.Context, req ValidateUserRequest) (ValidateUserResponse, error)
+}
+
+// ValidateUser calls ValidateUser on the database if it implements
+// UserValidator, and returns ErrValidateUserUnsupported otherwise.
+func ValidateUser(ctx context.Context, db Database, req ValidateUserRequest) (ValidateUserResponse, error) {
+	validator, ok := db.(UserValidator)
+	if !ok {
+		return ValidateUserResponse{}, ErrValidateUserUnsupported
+	}
+	return validator.ValidateUser(ctx, req)
+}
+
 // ///////////////////////////////////////////////////////////////////////////
 // Database Request & Response Objects
 // These request and response objects are *not* protobuf types because gRPC does not
@@ -242,6 +266,20 @@ type DeleteUserRequest struct {
 
 type DeleteUserResponse struct{}
 
+// ///////////////////////////////////////////////////////
+// ValidateUser()
+// ///////////////////////////////////////////////////////
+
+type ValidateUserRequest struct {
+	// Username to look up within the database
+	Username string
+}
+
+type ValidateUserResponse struct {
+	// Exists is set if the user exists within the database.
+	Exists bool
+}
+
 // ///////////////////////////////////////////////////////
 // Used across multiple functions
 // ///////////////////////////////////////////////////////
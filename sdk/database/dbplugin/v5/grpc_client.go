@@ -13,10 +13,13 @@ import (
 	"github.com/openbao/openbao/sdk/v2/database/dbplugin/v5/proto"
 	"github.com/openbao/openbao/sdk/v2/helper/pluginutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
 	_ Database                = gRPCClient{}
+	_ UserValidator           = gRPCClient{}
 	_ logical.PluginVersioner = gRPCClient{}
 
 	ErrPluginShutdown = errors.New("plugin shutdown")
@@ -268,6 +271,32 @@ func deleteUserRespFromProto(rpcResp *proto.DeleteUserResponse) (DeleteUserRespo
 	return DeleteUserResponse{}, nil
 }
 
+func (c gRPCClient) ValidateUser(ctx context.Context, req ValidateUserRequest) (ValidateUserResponse, error) {
+	if req.Username == "" {
+		return ValidateUserResponse{}, fmt.Errorf("missing username")
+	}
+
+	rpcReq := &proto.ValidateUserRequest{
+		Username: req.Username,
+	}
+
+	rpcResp, err := c.client.ValidateUser(ctx, rpcReq)
+	if err != nil {
+		if c.doneCtx.Err() != nil {
+			return ValidateUserResponse{}, ErrPluginShutdown
+		}
+		// Plugins built against an older SDK do not know this RPC
+		if status.Code(err) == codes.Unimplemented {
+			return ValidateUserResponse{}, ErrValidateUserUnsupported
+		}
+		return ValidateUserResponse{}, fmt.Errorf("unable to validate user: %w", err)
+	}
+
+	return ValidateUserResponse{
+		Exists: rpcResp.GetExists(),
+	}, nil
+}
+
 func (c gRPCClient) Type() (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
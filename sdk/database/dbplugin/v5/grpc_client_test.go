@@ -13,6 +13,8 @@ import (
 
 	"github.com/openbao/openbao/sdk/v2/database/dbplugin/v5/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGRPCClient_Initialize(t *testing.T) {
@@ -391,6 +393,92 @@ func TestGRPCClient_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestGRPCClient_ValidateUser(t *testing.T) {
+	runningCtx := context.Background()
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type testCase struct {
+		client       proto.DatabaseClient
+		req          ValidateUserRequest
+		doneCtx      context.Context
+		expectedResp ValidateUserResponse
+		assertErr    errorAssertion
+	}
+
+	tests := map[string]testCase{
+		"missing username": {
+			client:    fakeClient{},
+			req:       ValidateUserRequest{},
+			doneCtx:   runningCtx,
+			assertErr: assertErrNotNil,
+		},
+		"database error": {
+			client: fakeClient{
+				validateUserErr: errors.New("validate user error"),
+			},
+			req: ValidateUserRequest{
+				Username: "user",
+			},
+			doneCtx:   runningCtx,
+			assertErr: assertErrNotNil,
+		},
+		"unimplemented": {
+			client: fakeClient{
+				validateUserErr: status.Error(codes.Unimplemented, "method ValidateUser not implemented"),
+			},
+			req: ValidateUserRequest{
+				Username: "user",
+			},
+			doneCtx:   runningCtx,
+			assertErr: assertErrEquals(ErrValidateUserUnsupported),
+		},
+		"plugin shut down": {
+			client: fakeClient{
+				validateUserErr: errors.New("validate user error"),
+			},
+			req: ValidateUserRequest{
+				Username: "user",
+			},
+			doneCtx:   cancelledCtx,
+			assertErr: assertErrEquals(ErrPluginShutdown),
+		},
+		"happy path": {
+			client: fakeClient{
+				validateUserResp: &proto.ValidateUserResponse{
+					Exists: true,
+				},
+			},
+			req: ValidateUserRequest{
+				Username: "user",
+			},
+			doneCtx: runningCtx,
+			expectedResp: ValidateUserResponse{
+				Exists: true,
+			},
+			assertErr: assertErrNil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := gRPCClient{
+				client:  test.client,
+				doneCtx: test.doneCtx,
+			}
+
+			ctx := context.Background()
+
+			resp, err := c.ValidateUser(ctx, test.req)
+			test.assertErr(t, err)
+
+			if !reflect.DeepEqual(resp, test.expectedResp) {
+				t.Fatalf("Actual response: %#v\nExpected response: %#v", resp, test.expectedResp)
+			}
+		})
+	}
+}
+
 func TestGRPCClient_Type(t *testing.T) {
 	runningCtx := context.Background()
 	cancelledCtx, cancel := context.WithCancel(context.Background())
@@ -537,6 +625,9 @@ type fakeClient struct {
 	typeErr  error
 
 	closeErr error
+
+	validateUserResp *proto.ValidateUserResponse
+	validateUserErr  error
 }
 
 func (f fakeClient) Initialize(context.Context, *proto.InitializeRequest, ...grpc.CallOption) (*proto.InitializeResponse, error) {
@@ -562,3 +653,7 @@ func (f fakeClient) Type(context.Context, *proto.Empty, ...grpc.CallOption) (*pr
 func (f fakeClient) Close(context.Context, *proto.Empty, ...grpc.CallOption) (*proto.Empty, error) {
 	return &proto.Empty{}, f.typeErr
 }
+
+func (f fakeClient) ValidateUser(context.Context, *proto.ValidateUserRequest, ...grpc.CallOption) (*proto.ValidateUserResponse, error) {
+	return f.validateUserResp, f.validateUserErr
+}
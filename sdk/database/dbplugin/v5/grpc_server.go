@@ -270,6 +270,31 @@ func (g *gRPCServer) DeleteUser(ctx context.Context, req *proto.DeleteUserReques
 	return &proto.DeleteUserResponse{}, nil
 }
 
+func (g *gRPCServer) ValidateUser(ctx context.Context, req *proto.ValidateUserRequest) (*proto.ValidateUserResponse, error) {
+	if req.GetUsername() == "" {
+		return &proto.ValidateUserResponse{}, status.Errorf(codes.InvalidArgument, "no username provided")
+	}
+	dbReq := ValidateUserRequest{
+		Username: req.GetUsername(),
+	}
+
+	impl, err := g.getDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbResp, err := ValidateUser(ctx, impl, dbReq)
+	if errors.Is(err, ErrValidateUserUnsupported) {
+		return &proto.ValidateUserResponse{}, status.Error(codes.Unimplemented, err.Error())
+	}
+	if err != nil {
+		return &proto.ValidateUserResponse{}, status.Errorf(codes.Internal, "unable to validate user: %s", err)
+	}
+	return &proto.ValidateUserResponse{
+		Exists: dbResp.Exists,
+	}, nil
+}
+
 func (g *gRPCServer) Type(ctx context.Context, _ *proto.Empty) (*proto.TypeResponse, error) {
 	impl, err := g.getOrCreateDatabase(ctx)
 	if err != nil {
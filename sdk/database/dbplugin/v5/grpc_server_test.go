@@ -469,6 +469,84 @@ func TestGRPCServer_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestGRPCServer_ValidateUser(t *testing.T) {
+	type testCase struct {
+		db           Database
+		req          *proto.ValidateUserRequest
+		expectedResp *proto.ValidateUserResponse
+		expectErr    bool
+		expectCode   codes.Code
+	}
+
+	tests := map[string]testCase{
+		"missing username": {
+			db:           fakeValidatingDatabase{},
+			req:          &proto.ValidateUserRequest{},
+			expectedResp: &proto.ValidateUserResponse{},
+			expectErr:    true,
+			expectCode:   codes.InvalidArgument,
+		},
+		"unsupported": {
+			db: fakeDatabase{},
+			req: &proto.ValidateUserRequest{
+				Username: "someuser",
+			},
+			expectedResp: &proto.ValidateUserResponse{},
+			expectErr:    true,
+			expectCode:   codes.Unimplemented,
+		},
+		"database error": {
+			db: fakeValidatingDatabase{
+				validateUserErr: errors.New("validate user error"),
+			},
+			req: &proto.ValidateUserRequest{
+				Username: "someuser",
+			},
+			expectedResp: &proto.ValidateUserResponse{},
+			expectErr:    true,
+			expectCode:   codes.Internal,
+		},
+		"happy path": {
+			db: fakeValidatingDatabase{
+				validateUserResp: ValidateUserResponse{
+					Exists: true,
+				},
+			},
+			req: &proto.ValidateUserRequest{
+				Username: "someuser",
+			},
+			expectedResp: &proto.ValidateUserResponse{
+				Exists: true,
+			},
+			expectErr:  false,
+			expectCode: codes.OK,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			idCtx, g := testGrpcServer(t, test.db)
+			resp, err := g.ValidateUser(idCtx, test.req)
+
+			if test.expectErr && err == nil {
+				t.Fatalf("err expected, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Fatalf("no error expected, got: %s", err)
+			}
+
+			actualCode := status.Code(err)
+			if actualCode != test.expectCode {
+				t.Fatalf("Actual code: %s Expected code: %s", actualCode, test.expectCode)
+			}
+
+			if !reflect.DeepEqual(resp, test.expectedResp) {
+				t.Fatalf("Actual response: %#v\nExpected response: %#v", resp, test.expectedResp)
+			}
+		})
+	}
+}
+
 func TestGRPCServer_Type(t *testing.T) {
 	type testCase struct {
 		db           Database
@@ -739,6 +817,23 @@ func (e fakeDatabase) Close() error {
 	return e.closeErr
 }
 
+var (
+	_ Database      = fakeValidatingDatabase{}
+	_ UserValidator = fakeValidatingDatabase{}
+)
+
+// fakeValidatingDatabase is a fakeDatabase which also implements UserValidator.
+type fakeValidatingDatabase struct {
+	fakeDatabase
+
+	validateUserResp ValidateUserResponse
+	validateUserErr  error
+}
+
+func (e fakeValidatingDatabase) ValidateUser(ctx context.Context, req ValidateUserRequest) (ValidateUserResponse, error) {
+	return e.validateUserResp, e.validateUserErr
+}
+
 var _ Database = &recordingDatabase{}
 
 type recordingDatabase struct {
@@ -23,6 +23,7 @@ import (
 
 var (
 	_ Database                = databaseTracingMiddleware{}
+	_ UserValidator           = databaseTracingMiddleware{}
 	_ logical.PluginVersioner = databaseTracingMiddleware{}
 )
 
@@ -99,6 +100,20 @@ func (mw databaseTracingMiddleware) DeleteUser(ctx context.Context, req DeleteUs
 	return mw.next.DeleteUser(ctx, req)
 }
 
+func (mw databaseTracingMiddleware) ValidateUser(ctx context.Context, req ValidateUserRequest) (resp ValidateUserResponse, err error) {
+	defer func(then time.Time) {
+		mw.logger.Trace("validate user",
+			"status", "finished",
+			"exists", resp.Exists,
+			"err", err,
+			"took", time.Since(then))
+	}(time.Now())
+
+	mw.logger.Trace("validate user",
+		"status", "started")
+	return ValidateUser(ctx, mw.next, req)
+}
+
 func (mw databaseTracingMiddleware) Type() (string, error) {
 	return mw.next.Type()
 }
@@ -122,6 +137,7 @@ func (mw databaseTracingMiddleware) Close() (err error) {
 
 var (
 	_ Database                = databaseMetricsMiddleware{}
+	_ UserValidator           = databaseMetricsMiddleware{}
 	_ logical.PluginVersioner = databaseMetricsMiddleware{}
 )
 
@@ -212,6 +228,22 @@ func (mw databaseMetricsMiddleware) DeleteUser(ctx context.Context, req DeleteUs
 	return mw.next.DeleteUser(ctx, req)
 }
 
+func (mw databaseMetricsMiddleware) ValidateUser(ctx context.Context, req ValidateUserRequest) (resp ValidateUserResponse, err error) {
+	defer func(now time.Time) {
+		metrics.MeasureSince([]string{"database", "ValidateUser"}, now)
+		metrics.MeasureSince([]string{"database", mw.typeStr, "ValidateUser"}, now)
+
+		if err != nil {
+			metrics.IncrCounter([]string{"database", "ValidateUser", "error"}, 1)
+			metrics.IncrCounter([]string{"database", mw.typeStr, "ValidateUser", "error"}, 1)
+		}
+	}(time.Now())
+
+	metrics.IncrCounter([]string{"database", "ValidateUser"}, 1)
+	metrics.IncrCounter([]string{"database", mw.typeStr, "ValidateUser"}, 1)
+	return ValidateUser(ctx, mw.next, req)
+}
+
 func (mw databaseMetricsMiddleware) Type() (string, error) {
 	return mw.next.Type()
 }
@@ -238,6 +270,7 @@ func (mw databaseMetricsMiddleware) Close() (err error) {
 
 var (
 	_ Database                = (*DatabaseErrorSanitizerMiddleware)(nil)
+	_ UserValidator           = (*DatabaseErrorSanitizerMiddleware)(nil)
 	_ logical.PluginVersioner = (*DatabaseErrorSanitizerMiddleware)(nil)
 )
 
@@ -277,6 +310,14 @@ func (mw DatabaseErrorSanitizerMiddleware) DeleteUser(ctx context.Context, req D
 	return resp, mw.sanitize(err)
 }
 
+func (mw DatabaseErrorSanitizerMiddleware) ValidateUser(ctx context.Context, req ValidateUserRequest) (ValidateUserResponse, error) {
+	resp, err := ValidateUser(ctx, mw.next, req)
+	if errors.Is(err, ErrValidateUserUnsupported) {
+		return resp, err
+	}
+	return resp, mw.sanitize(err)
+}
+
 func (mw DatabaseErrorSanitizerMiddleware) Type() (string, error) {
 	dbType, err := mw.next.Type()
 	return dbType, mw.sanitize(err)
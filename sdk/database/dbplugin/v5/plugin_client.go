@@ -13,7 +13,10 @@ import (
 	"github.com/openbao/openbao/sdk/v2/logical"
 )
 
-var _ logical.PluginVersioner = (*DatabasePluginClient)(nil)
+var (
+	_ logical.PluginVersioner = (*DatabasePluginClient)(nil)
+	_ UserValidator           = (*DatabasePluginClient)(nil)
+)
 
 type DatabasePluginClient struct {
 	client pluginutil.PluginClient
@@ -27,6 +30,10 @@ func (dc *DatabasePluginClient) PluginVersion() logical.PluginVersion {
 	return logical.EmptyPluginVersion
 }
 
+func (dc *DatabasePluginClient) ValidateUser(ctx context.Context, req ValidateUserRequest) (ValidateUserResponse, error) {
+	return ValidateUser(ctx, dc.Database, req)
+}
+
 // This wraps the Close call and ensures we both close the database connection
 // and kill the plugin.
 func (dc *DatabasePluginClient) Close() error {
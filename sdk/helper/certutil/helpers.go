@@ -95,6 +95,17 @@ var DeltaCRLIndicatorOID = asn1.ObjectIdentifier([]int{2, 5, 29, 27})
 // > id-ce-freshestCRL OBJECT IDENTIFIER ::=  { id-ce 46 }
 var FreshestCRLOID = asn1.ObjectIdentifier([]int{2, 5, 29, 46})
 
+// OID for RFC 5280 Issuing Distribution Point CRL extension.
+//
+// > id-ce-issuingDistributionPoint OBJECT IDENTIFIER ::= { id-ce 28 }
+var IssuingDistributionPointOID = asn1.ObjectIdentifier([]int{2, 5, 29, 28})
+
+// OID for the RFC 6962 Precertificate Poison extension.
+var CTPoisonOID = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3})
+
+// OID for the RFC 6962 Embedded SCT List certificate extension.
+var CTSCTListOID = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2})
+
 // GetHexFormatted returns the byte buffer formatted in hex with
 // the specified separator between bytes.
 func GetHexFormatted(buf []byte, sep string) string {
@@ -954,7 +965,7 @@ func createCertificate(data *CreationBundle, randReader io.Reader, privateKeyGen
 		caCert := data.SigningBundle.Certificate
 		certTemplate.AuthorityKeyId = caCert.SubjectKeyId
 
-		certBytes, err = x509.CreateCertificate(randReader, certTemplate, caCert, result.PrivateKey.Public(), data.SigningBundle.PrivateKey)
+		certBytes, err = createSignedCertificate(data, randReader, certTemplate, result.PrivateKey.Public())
 	} else {
 		// Creating a self-signed root
 		if data.Params.MaxPathLength == 0 {
@@ -1156,6 +1167,43 @@ func SignCertificateWithRandomSource(data *CreationBundle, randReader io.Reader)
 	return signCertificate(data, randReader)
 }
 
+// createSignedCertificate creates the certificate from the template, signed
+// by the SigningBundle. When a PrecertificateHook is set, the precertificate
+// is created and passed to it first, and the extensions it returns are
+// added to the certificate.
+func createSignedCertificate(data *CreationBundle, randReader io.Reader, certTemplate *x509.Certificate, pub crypto.PublicKey) ([]byte, error) {
+	caCert := data.SigningBundle.Certificate
+	signer := data.SigningBundle.PrivateKey
+
+	if data.Params.PrecertificateHook != nil {
+		// Per RFC 6962 Section 3.1, the precertificate differs from the
+		// final certificate only by the poison and SCT list extensions.
+		precertTemplate := *certTemplate
+		precertTemplate.ExtraExtensions = append(append([]pkix.Extension{}, certTemplate.ExtraExtensions...), pkix.Extension{
+			Id:       CTPoisonOID,
+			Critical: true,
+			Value:    asn1.NullBytes,
+		})
+
+		precertBytes, err := x509.CreateCertificate(randReader, &precertTemplate, caCert, pub, signer)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create precertificate: %w", err)
+		}
+		precert, err := x509.ParseCertificate(precertBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse created precertificate: %w", err)
+		}
+
+		extensions, err := data.Params.PrecertificateHook(precert)
+		if err != nil {
+			return nil, err
+		}
+		certTemplate.ExtraExtensions = append(certTemplate.ExtraExtensions, extensions...)
+	}
+
+	return x509.CreateCertificate(randReader, certTemplate, caCert, pub, signer)
+}
+
 func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBundle, error) {
 	switch {
 	case data == nil:
@@ -1283,7 +1331,7 @@ func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBun
 		certTemplate.PermittedDNSDomainsCritical = true
 	}
 
-	certBytes, err = x509.CreateCertificate(randReader, certTemplate, caCert, data.CSR.PublicKey, data.SigningBundle.PrivateKey)
+	certBytes, err = createSignedCertificate(data, randReader, certTemplate, data.CSR.PublicKey)
 	if err != nil {
 		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to create certificate: %s", err)}
 	}
@@ -1453,6 +1501,41 @@ func CreateFreshestCRLExt(paths []string) (pkix.Extension, error) {
 	}, nil
 }
 
+// CreateIssuingDistributionPointExt allows creating the issuing distribution
+// point extension of partitioned CRLs, identifying the distribution point
+// whose certificates the CRL covers.
+func CreateIssuingDistributionPointExt(paths []string) (pkix.Extension, error) {
+	// distributionPointName is copied from crypto/x509 as of the go1.22.1
+	// tag; the remaining fields of the extension are left at their
+	// defaults, covering all certificates and reasons.
+	type distributionPointName struct {
+		FullName     []asn1.RawValue  `asn1:"optional,tag:0"`
+		RelativeName pkix.RDNSequence `asn1:"optional,tag:1"`
+	}
+
+	type issuingDistributionPoint struct {
+		DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+	}
+
+	var idp issuingDistributionPoint
+	for _, path := range paths {
+		idp.DistributionPoint.FullName = append(idp.DistributionPoint.FullName, asn1.RawValue{Tag: 6, Class: 2, Bytes: []byte(path)})
+	}
+
+	idpValue, err := asn1.Marshal(idp)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("unable to marshal issuing distribution point (%v): %v", paths, err)
+	}
+
+	return pkix.Extension{
+		Id: IssuingDistributionPointOID,
+		// > Although the extension is critical, conforming implementations
+		// > are not required to support this extension.
+		Critical: true,
+		Value:    idpValue,
+	}, nil
+}
+
 // ParseBasicConstraintExtension parses a basic constraint pkix.Extension, useful if attempting to validate
 // CSRs are requesting CA privileges as Go does not expose its implementation. Values returned are
 // IsCA, MaxPathLen or error. If MaxPathLen was not set, a value of -1 will be returned.
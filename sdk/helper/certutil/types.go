@@ -800,6 +800,12 @@ type CreationParameters struct {
 
 	// The explicit SKID to use; especially useful for cross-signing.
 	SKID []byte
+
+	// If set, called with the precertificate of a certificate signed by the
+	// SigningBundle: the same certificate with the critical RFC 6962 poison
+	// extension added. The returned extensions, such as a list of Signed
+	// Certificate Timestamps, are added to the final certificate.
+	PrecertificateHook func(precert *x509.Certificate) ([]pkix.Extension, error)
 }
 
 type CreationBundle struct {
@@ -21,6 +21,7 @@ var (
 	ErrNoEntityAttachedToToken       = errors.New("string contains entity template directives but no entity was provided")
 	ErrNoGroupsAttachedToToken       = errors.New("string contains groups template directives but no groups were provided")
 	ErrTemplateValueNotFound         = errors.New("no value could be found for one of the template directives")
+	ErrNoRequestDataProvided         = errors.New("string contains request template directives but no request data was provided")
 )
 
 const (
@@ -37,6 +38,11 @@ type PopulateStringInput struct {
 	Mode              int       // processing mode, ACLTemplate or JSONTemplating
 	Now               time.Time // optional, defaults to current time
 
+	// Request holds the request fields which may be referenced with
+	// request.<field> directives. Callers are responsible for only exposing
+	// fields whose values are safe to substitute.
+	Request map[string]string
+
 	templateHandler templateHandlerFunc
 	groupIDs        []string
 	groupNames      []string
@@ -364,6 +370,16 @@ func performTemplating(input string, p *PopulateStringInput) (string, error) {
 
 	case strings.HasPrefix(input, "time."):
 		return performTimeTemplating(strings.TrimPrefix(input, "time."))
+
+	case strings.HasPrefix(input, "request."):
+		if p.Request == nil {
+			return "", ErrNoRequestDataProvided
+		}
+		field := strings.TrimPrefix(input, "request.")
+		if _, ok := p.Request[field]; !ok {
+			return "", ErrTemplateValueNotFound
+		}
+		return p.templateHandler(p.Request, field)
 	}
 
 	return "", ErrTemplateValueNotFound
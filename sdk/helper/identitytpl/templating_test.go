@@ -38,6 +38,7 @@ func TestPopulate_Basic(t *testing.T) {
 		groupMetadata       map[string]string
 		groupMemberships    []string
 		now                 time.Time
+		request             map[string]string
 	}{
 		// time.* tests. Keep tests with time.Now() at the front to avoid false
 		// positives due to the second changing during the test
@@ -380,6 +381,31 @@ func TestPopulate_Basic(t *testing.T) {
 			aliasCustomMetadata: map[string]string{"foo": "abc", "bar": "123"},
 			output:              `{}`,
 		},
+		// request.* tests
+		{
+			name:    "request field",
+			input:   "secret/{{request.tenant}}/*",
+			request: map[string]string{"tenant": "acme"},
+			output:  "secret/acme/*",
+		},
+		{
+			name:    "request field not found",
+			input:   "secret/{{request.tenant}}/*",
+			request: map[string]string{"team": "acme"},
+			err:     ErrTemplateValueNotFound,
+		},
+		{
+			name:  "no request data",
+			input: "secret/{{request.tenant}}/*",
+			err:   ErrNoRequestDataProvided,
+		},
+		{
+			mode:    JSONTemplating,
+			name:    "request field JSON",
+			input:   "{{request.tenant}}",
+			request: map[string]string{"tenant": "acme"},
+			output:  `"acme"`,
+		},
 	}
 
 	for _, test := range tests {
@@ -429,6 +455,7 @@ func TestPopulate_Basic(t *testing.T) {
 			Groups:            groups,
 			NamespaceID:       "root",
 			Now:               test.now,
+			Request:           test.request,
 		})
 		if err != nil {
 			if test.err == nil {
@@ -63,6 +63,9 @@ type PolicyRequest struct {
 	// AllowImportedKeyRotation indicates whether an imported key may be rotated by Vault
 	AllowImportedKeyRotation bool
 
+	// Whether encryption and decryption must supply associated data
+	RequireAssociatedData bool
+
 	// Indicates whether a private or public key is imported/upserted
 	IsPrivateKey bool
 
@@ -389,14 +392,15 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 		}
 
 		p = &Policy{
-			l:                    new(sync.RWMutex),
-			Name:                 req.Name,
-			Type:                 req.KeyType,
-			Derived:              req.Derived,
-			Exportable:           req.Exportable,
-			AllowPlaintextBackup: req.AllowPlaintextBackup,
-			AutoRotatePeriod:     req.AutoRotatePeriod,
-			KeySize:              req.KeySize,
+			l:                     new(sync.RWMutex),
+			Name:                  req.Name,
+			Type:                  req.KeyType,
+			Derived:               req.Derived,
+			Exportable:            req.Exportable,
+			AllowPlaintextBackup:  req.AllowPlaintextBackup,
+			AutoRotatePeriod:      req.AutoRotatePeriod,
+			KeySize:               req.KeySize,
+			RequireAssociatedData: req.RequireAssociatedData,
 		}
 
 		if req.Derived {
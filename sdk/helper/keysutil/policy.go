@@ -81,6 +81,10 @@ const (
 	// deleted.
 	ErrSoftDeleted = "refusing to use soft-deleted key"
 
+	// ErrAssociatedDataRequired is returned when encrypting or decrypting
+	// without associated data with a key which requires it.
+	ErrAssociatedDataRequired = "associated data is required by this key"
+
 	// DefaultVersionTemplate is used when no version template is provided.
 	DefaultVersionTemplate = "vault:v{{version}}:"
 )
@@ -462,6 +466,11 @@ type Policy struct {
 	// AllowImportedKeyRotation indicates whether an imported key may be rotated by Vault
 	AllowImportedKeyRotation bool
 
+	// RequireAssociatedData requires non-empty associated data for every
+	// encryption and decryption with an AEAD key, so that ciphertexts are
+	// bound to the scope they were encrypted for.
+	RequireAssociatedData bool `json:"require_associated_data"`
+
 	// Whether the key has been soft deleted.
 	SoftDeleted bool `json:"soft_deleted"`
 }
@@ -915,32 +924,43 @@ func (p *Policy) Decrypt(context, nonce []byte, value string) (string, error) {
 }
 
 func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factories ...interface{}) (string, error) {
+	plain, err := p.DecryptBytesWithFactory(context, nonce, value, factories...)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(plain), nil
+}
+
+// DecryptBytesWithFactory is DecryptWithFactory returning the raw plaintext
+// rather than its base64 encoding, so that callers handling key material
+// can zero it once used.
+func (p *Policy) DecryptBytesWithFactory(context, nonce []byte, value string, factories ...interface{}) ([]byte, error) {
 	if p.SoftDeleted {
-		return "", errutil.UserError{Err: ErrSoftDeleted}
+		return nil, errutil.UserError{Err: ErrSoftDeleted}
 	}
 
 	if !p.Type.DecryptionSupported() {
-		return "", errutil.UserError{Err: fmt.Sprintf("message decryption not supported for key type %v", p.Type)}
+		return nil, errutil.UserError{Err: fmt.Sprintf("message decryption not supported for key type %v", p.Type)}
 	}
 
 	tplParts, err := p.getTemplateParts()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Verify the prefix
 	if !strings.HasPrefix(value, tplParts[0]) {
-		return "", errutil.UserError{Err: "invalid ciphertext: no prefix"}
+		return nil, errutil.UserError{Err: "invalid ciphertext: no prefix"}
 	}
 
 	splitVerCiphertext := strings.SplitN(strings.TrimPrefix(value, tplParts[0]), tplParts[1], 2)
 	if len(splitVerCiphertext) != 2 {
-		return "", errutil.UserError{Err: "invalid ciphertext: wrong number of fields"}
+		return nil, errutil.UserError{Err: "invalid ciphertext: wrong number of fields"}
 	}
 
 	ver, err := strconv.Atoi(splitVerCiphertext[0])
 	if err != nil {
-		return "", errutil.UserError{Err: "invalid ciphertext: version number could not be decoded"}
+		return nil, errutil.UserError{Err: "invalid ciphertext: version number could not be decoded"}
 	}
 
 	if ver == 0 {
@@ -950,22 +970,22 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 	}
 
 	if ver > p.LatestVersion {
-		return "", errutil.UserError{Err: "invalid ciphertext: version is too new"}
+		return nil, errutil.UserError{Err: "invalid ciphertext: version is too new"}
 	}
 
 	if p.MinDecryptionVersion > 0 && ver < p.MinDecryptionVersion {
-		return "", errutil.UserError{Err: ErrTooOld}
+		return nil, errutil.UserError{Err: ErrTooOld}
 	}
 
 	convergentVersion := p.convergentVersion(ver)
 	if p.ConvergentEncryption && convergentVersion < currentConvergentVersion && convergentVersion > 0 {
-		return "", errutil.UserError{Err: fmt.Sprintf("refusing to support decryption with old convergent encryption key: version %d", convergentVersion)}
+		return nil, errutil.UserError{Err: fmt.Sprintf("refusing to support decryption with old convergent encryption key: version %d", convergentVersion)}
 	}
 
 	// Decode the base64
 	decoded, err := base64.StdEncoding.DecodeString(splitVerCiphertext[1])
 	if err != nil {
-		return "", errutil.UserError{Err: "invalid ciphertext: could not decode base64"}
+		return nil, errutil.UserError{Err: "invalid ciphertext: could not decode base64"}
 	}
 
 	var plain []byte
@@ -979,11 +999,11 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 
 		encKey, err := p.GetKey(context, ver, numBytes)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		if len(encKey) != numBytes {
-			return "", errutil.InternalError{Err: "could not derive enc key, length not correct"}
+			return nil, errutil.InternalError{Err: "could not derive enc key, length not correct"}
 		}
 
 		symopts := SymmetricOpts{
@@ -1000,36 +1020,39 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 			case AssociatedDataFactory:
 				symopts.AdditionalData, err = factory.GetAssociatedData()
 				if err != nil {
-					return "", errutil.InternalError{Err: fmt.Sprintf("unable to get associated_data/additional_data from factory[%d]: %v", index, err)}
+					return nil, errutil.InternalError{Err: fmt.Sprintf("unable to get associated_data/additional_data from factory[%d]: %v", index, err)}
 				}
 			default:
-				return "", errutil.InternalError{Err: fmt.Sprintf("unknown type of factory[%d]: %T", index, rawFactory)}
+				return nil, errutil.InternalError{Err: fmt.Sprintf("unknown type of factory[%d]: %T", index, rawFactory)}
 			}
 		}
+		if p.RequireAssociatedData && len(symopts.AdditionalData) == 0 {
+			return nil, errutil.UserError{Err: ErrAssociatedDataRequired}
+		}
 
 		plain, err = p.SymmetricDecryptRaw(encKey, decoded, symopts)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096:
 		keyEntry, err := p.safeGetKeyEntry(ver)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		key := keyEntry.RSAKey
 		if key == nil {
-			return "", errutil.InternalError{Err: fmt.Sprintf("cannot decrypt ciphertext, key version does not have a private counterpart")}
+			return nil, errutil.InternalError{Err: fmt.Sprintf("cannot decrypt ciphertext, key version does not have a private counterpart")}
 		}
 		plain, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, key, decoded, nil)
 		if err != nil {
-			return "", errutil.InternalError{Err: fmt.Sprintf("failed to RSA decrypt the ciphertext: %v", err)}
+			return nil, errutil.InternalError{Err: fmt.Sprintf("failed to RSA decrypt the ciphertext: %v", err)}
 		}
 
 	default:
-		return "", errutil.InternalError{Err: fmt.Sprintf("unsupported key type %v", p.Type)}
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported key type %v", p.Type)}
 	}
 
-	return base64.StdEncoding.EncodeToString(plain), nil
+	return plain, nil
 }
 
 func (p *Policy) HMACKey(version int) ([]byte, error) {
@@ -1991,6 +2014,21 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 		return "", errutil.UserError{Err: err.Error()}
 	}
 
+	return p.EncryptBytesWithFactory(ver, context, nonce, plaintext, factories...)
+}
+
+// EncryptBytesWithFactory is EncryptWithFactory taking the raw plaintext
+// rather than its base64 encoding, so that callers handling key material
+// do not leave copies of it behind.
+func (p *Policy) EncryptBytesWithFactory(ver int, context []byte, nonce []byte, plaintext []byte, factories ...interface{}) (string, error) {
+	if p.SoftDeleted {
+		return "", errutil.UserError{Err: ErrSoftDeleted}
+	}
+
+	if !p.Type.EncryptionSupported() {
+		return "", errutil.UserError{Err: fmt.Sprintf("message encryption not supported for key type %v", p.Type)}
+	}
+
 	switch {
 	case ver == 0:
 		ver = p.LatestVersion
@@ -2068,6 +2106,9 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 				return "", errutil.InternalError{Err: fmt.Sprintf("unknown type of factory[%d]: %T", index, rawFactory)}
 			}
 		}
+		if p.RequireAssociatedData && len(symopts.AdditionalData) == 0 {
+			return "", errutil.UserError{Err: ErrAssociatedDataRequired}
+		}
 
 		ciphertext, err = p.SymmetricEncryptRaw(ver, encKey, plaintext, symopts)
 		if err != nil {
@@ -11,6 +11,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash"
+	"strconv"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	uuid "github.com/hashicorp/go-uuid"
@@ -21,6 +23,15 @@ const (
 	// DefaultLocation is the path in the view we store our key salt
 	// if no other path is provided.
 	DefaultLocation = "salt"
+
+	// epochsSuffix is appended to the location of the salt to store the
+	// epochs of a salt once it has been rotated.
+	epochsSuffix = "/epochs"
+
+	// epochPrefix is appended to the location of the salt, followed by the
+	// epoch number, to store the salts of the epochs after the first one. The
+	// salt of the first epoch stays at the location itself.
+	epochPrefix = "/epoch/"
 )
 
 // Salt is used to manage a persistent salt key which is used to
@@ -31,6 +42,24 @@ type Salt struct {
 	config    *Config
 	salt      string
 	generated bool
+	epoch     int
+}
+
+// Epoch describes a generation of a salt. The first salt stored at a location
+// is epoch 1, and each rotation increments the epoch.
+type Epoch struct {
+	Epoch int `json:"epoch"`
+
+	// CreatedTime is the time the salt of the epoch was generated. It is zero
+	// for the first epoch of salts generated before they could be rotated.
+	CreatedTime time.Time `json:"created_time"`
+}
+
+// epochs is the record of the epochs of a salt, stored once the salt has been
+// rotated.
+type epochs struct {
+	Current int     `json:"current"`
+	Epochs  []Epoch `json:"epochs"`
 }
 
 type HashFunc func([]byte) []byte
@@ -54,9 +83,8 @@ type Config struct {
 	HMACType string
 }
 
-// NewSalt creates a new salt based on the configuration
-func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt, error) {
-	// Setup the configuration
+// setDefaults fills in the unset fields of the configuration
+func setDefaults(config *Config) *Config {
 	if config == nil {
 		config = &Config{}
 	}
@@ -70,16 +98,34 @@ func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt,
 		config.HMAC = sha256.New
 		config.HMACType = "hmac-sha256"
 	}
+	return config
+}
+
+// NewSalt creates a new salt based on the configuration. If the salt has been
+// rotated, the salt of the current epoch is returned.
+func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt, error) {
+	// Setup the configuration
+	config = setDefaults(config)
 
 	// Create the salt
 	s := &Salt{
 		config: config,
+		epoch:  1,
 	}
 
 	// Look for the salt
 	var raw *logical.StorageEntry
 	var err error
 	if view != nil {
+		var rec *epochs
+		rec, err = readEpochs(ctx, view, config)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			return loadEpoch(ctx, view, config, rec.Current)
+		}
+
 		raw, err = view.Get(ctx, config.Location)
 		if err != nil {
 			return nil, errwrap.Wrapf("failed to read salt: {{err}}", err)
@@ -118,6 +164,139 @@ func NewSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt,
 	return s, nil
 }
 
+// epochLocation returns the location of the salt of the given epoch
+func epochLocation(config *Config, epoch int) string {
+	if epoch == 1 {
+		return config.Location
+	}
+	return config.Location + epochPrefix + strconv.Itoa(epoch)
+}
+
+// readEpochs reads the record of the epochs of a salt, which is nil if the
+// salt has never been rotated.
+func readEpochs(ctx context.Context, view logical.Storage, config *Config) (*epochs, error) {
+	raw, err := view.Get(ctx, config.Location+epochsSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read salt epochs: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	rec := new(epochs)
+	if err := raw.DecodeJSON(rec); err != nil {
+		return nil, fmt.Errorf("failed to decode salt epochs: %w", err)
+	}
+	return rec, nil
+}
+
+// loadEpoch reads the salt of the given epoch, which must exist
+func loadEpoch(ctx context.Context, view logical.Storage, config *Config, epoch int) (*Salt, error) {
+	raw, err := view.Get(ctx, epochLocation(config, epoch))
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read salt: {{err}}", err)
+	}
+	if raw == nil || len(raw.Value) == 0 {
+		return nil, fmt.Errorf("salt of epoch %d not found", epoch)
+	}
+	if config.HMAC != nil && len(config.HMACType) == 0 {
+		return nil, fmt.Errorf("HMACType must be defined")
+	}
+	return &Salt{
+		config: config,
+		salt:   string(raw.Value),
+		epoch:  epoch,
+	}, nil
+}
+
+// NewSaltEpoch returns the salt of a past or current epoch, so that values
+// can be hashed as they were during that epoch.
+func NewSaltEpoch(ctx context.Context, view logical.Storage, config *Config, epoch int) (*Salt, error) {
+	config = setDefaults(config)
+	rec, err := readEpochs(ctx, view, config)
+	if err != nil {
+		return nil, err
+	}
+	current := 1
+	if rec != nil {
+		current = rec.Current
+	}
+	if epoch < 1 || epoch > current {
+		return nil, fmt.Errorf("unknown salt epoch %d", epoch)
+	}
+	return loadEpoch(ctx, view, config, epoch)
+}
+
+// Epochs returns the epochs of the salt, oldest first. A salt which has never
+// been rotated has a single epoch.
+func Epochs(ctx context.Context, view logical.Storage, config *Config) ([]Epoch, error) {
+	config = setDefaults(config)
+	rec, err := readEpochs(ctx, view, config)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		return rec.Epochs, nil
+	}
+	return []Epoch{{Epoch: 1}}, nil
+}
+
+// RotateSalt generates a new salt and makes it the current one, incrementing
+// the epoch. The salts of previous epochs are kept, and can be loaded with
+// NewSaltEpoch. The rotation takes effect once the record of the epochs is
+// written, so an interrupted rotation leaves the current salt unchanged.
+// Callers must not rotate the same salt concurrently.
+func RotateSalt(ctx context.Context, view logical.Storage, config *Config) (*Salt, error) {
+	// Ensure the first epoch exists before moving past it
+	current, err := NewSalt(ctx, view, config)
+	if err != nil {
+		return nil, err
+	}
+	config = current.config
+
+	rec, err := readEpochs(ctx, view, config)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		rec = &epochs{
+			Current: 1,
+			Epochs:  []Epoch{{Epoch: 1}},
+		}
+	}
+
+	value, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to generate uuid: {{err}}", err)
+	}
+	next := rec.Current + 1
+	if err := view.Put(ctx, &logical.StorageEntry{
+		Key:   epochLocation(config, next),
+		Value: []byte(value),
+	}); err != nil {
+		return nil, errwrap.Wrapf("failed to persist salt: {{err}}", err)
+	}
+
+	rec.Current = next
+	rec.Epochs = append(rec.Epochs, Epoch{
+		Epoch:       next,
+		CreatedTime: time.Now().UTC(),
+	})
+	entry, err := logical.StorageEntryJSON(config.Location+epochsSuffix, rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode salt epochs: %w", err)
+	}
+	if err := view.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to persist salt epochs: %w", err)
+	}
+
+	return &Salt{
+		config:    config,
+		salt:      value,
+		generated: true,
+		epoch:     next,
+	}, nil
+}
+
 // NewNonpersistentSalt creates a new salt with default configuration and no storage usage.
 func NewNonpersistentSalt() *Salt {
 	// Setup the configuration
@@ -155,6 +334,11 @@ func (s *Salt) GetIdentifiedHMAC(data string) string {
 	return s.config.HMACType + ":" + s.GetHMAC(data)
 }
 
+// Epoch returns the epoch of the salt. It is 0 for nonpersistent salts.
+func (s *Salt) Epoch() int {
+	return s.epoch
+}
+
 // DidGenerate returns true if the underlying salt value was generated
 // on initialization.
 func (s *Salt) DidGenerate() bool {
@@ -89,3 +89,68 @@ func TestSaltID(t *testing.T) {
 		t.Fatalf("mismatch")
 	}
 }
+
+func TestRotateSalt(t *testing.T) {
+	ctx := context.Background()
+	inm := &logical.InmemStorage{}
+	conf := &Config{}
+
+	first, err := NewSalt(ctx, inm, conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if first.Epoch() != 1 {
+		t.Fatalf("bad epoch: %d", first.Epoch())
+	}
+
+	second, err := RotateSalt(ctx, inm, conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if second.Epoch() != 2 || second.salt == first.salt {
+		t.Fatalf("bad rotated salt: %d", second.Epoch())
+	}
+
+	// The first salt is still stored at the original location
+	out, err := inm.Get(ctx, DefaultLocation)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(out.Value) != first.salt {
+		t.Fatalf("first salt was overwritten")
+	}
+
+	// Loading the salt returns the current epoch
+	current, err := NewSalt(ctx, inm, conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if current.Epoch() != 2 || current.GetHMAC("foo") != second.GetHMAC("foo") {
+		t.Fatalf("bad current salt: %d", current.Epoch())
+	}
+
+	// Past epochs can still be loaded
+	past, err := NewSaltEpoch(ctx, inm, conf, 1)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if past.GetHMAC("foo") != first.GetHMAC("foo") {
+		t.Fatalf("bad past salt")
+	}
+	for _, epoch := range []int{0, 3} {
+		if _, err := NewSaltEpoch(ctx, inm, conf, epoch); err == nil {
+			t.Fatalf("expected error loading epoch %d", epoch)
+		}
+	}
+
+	epochs, err := Epochs(ctx, inm, conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(epochs) != 2 || epochs[0].Epoch != 1 || epochs[1].Epoch != 2 {
+		t.Fatalf("bad epochs: %#v", epochs)
+	}
+	if !epochs[0].CreatedTime.IsZero() || epochs[1].CreatedTime.IsZero() {
+		t.Fatalf("bad epoch creation times: %#v", epochs)
+	}
+}
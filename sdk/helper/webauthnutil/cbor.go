@@ -0,0 +1,180 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthnutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// cborMaxDepth bounds the nesting of decoded CBOR items, which in WebAuthn
+// structures never goes beyond a few levels.
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("truncated CBOR data")
+
+// DecodeCBOR decodes the CBOR item at the start of data, returning it along
+// with the number of bytes it spans. Only the subset of CBOR produced by
+// authenticators (RFC 8949 definite-length items) is supported. Integers
+// are decoded as int64, byte strings as []byte, text strings as string,
+// arrays as []interface{} and maps as map[interface{}]interface{}; tags are
+// dropped in favor of their content.
+func DecodeCBOR(data []byte) (interface{}, int, error) {
+	return cborDecodeItem(data, 0)
+}
+
+func cborDecodeItem(data []byte, depth int) (interface{}, int, error) {
+	if depth > cborMaxDepth {
+		return nil, 0, errors.New("CBOR data nested too deeply")
+	}
+	if len(data) == 0 {
+		return nil, 0, errCBORTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	// Simple values and floats carry their value in the additional info
+	if major == 7 {
+		return cborDecodeSimple(data, info)
+	}
+
+	arg, n, err := cborArgument(data, info)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer out of range")
+		}
+		return int64(arg), n, nil
+
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, 0, errors.New("CBOR integer out of range")
+		}
+		return -1 - int64(arg), n, nil
+
+	case 2, 3:
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORTruncated
+		}
+		end := n + int(arg)
+		if major == 2 {
+			return append([]byte(nil), data[n:end]...), end, nil
+		}
+		return string(data[n:end]), end, nil
+
+	case 4:
+		// Every item spans at least a byte
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORTruncated
+		}
+		items := make([]interface{}, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			item, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += m
+		}
+		return items, n, nil
+
+	case 5:
+		if arg > uint64(len(data)-n)/2 {
+			return nil, 0, errCBORTruncated
+		}
+		items := make(map[interface{}]interface{}, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			key, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, 0, fmt.Errorf("unsupported CBOR map key type %T", key)
+			}
+			if _, ok := items[key]; ok {
+				return nil, 0, fmt.Errorf("duplicate CBOR map key %v", key)
+			}
+
+			value, m, err := cborDecodeItem(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += m
+			items[key] = value
+		}
+		return items, n, nil
+
+	case 6:
+		item, m, err := cborDecodeItem(data[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return item, n + m, nil
+	}
+
+	return nil, 0, fmt.Errorf("unsupported CBOR major type %d", major)
+}
+
+// cborArgument decodes the argument of an item from its additional info and
+// the bytes following its initial byte, returning it along with the number
+// of bytes of the item read so far.
+func cborArgument(data []byte, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24:
+		if len(data) < 2 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(data[1]), 2, nil
+	case info == 25:
+		if len(data) < 3 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data[1:])), 3, nil
+	case info == 26:
+		if len(data) < 5 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:])), 5, nil
+	case info == 27:
+		if len(data) < 9 {
+			return 0, 0, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data[1:]), 9, nil
+	}
+	return 0, 0, errors.New("indefinite-length CBOR items are not supported")
+}
+
+func cborDecodeSimple(data []byte, info byte) (interface{}, int, error) {
+	switch info {
+	case 20:
+		return false, 1, nil
+	case 21:
+		return true, 1, nil
+	case 22, 23:
+		return nil, 1, nil
+	case 26:
+		if len(data) < 5 {
+			return nil, 0, errCBORTruncated
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data[1:]))), 5, nil
+	case 27:
+		if len(data) < 9 {
+			return nil, 0, errCBORTruncated
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data[1:])), 9, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported CBOR simple value %d", info)
+}
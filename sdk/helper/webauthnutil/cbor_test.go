@@ -0,0 +1,35 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthnutil
+
+import (
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// {1: 2, -1: h'010203', "fmt": "none"}
+	encoded := []byte{
+		0xa3,
+		0x01, 0x02,
+		0x20, 0x43, 0x01, 0x02, 0x03,
+		0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e',
+	}
+	decoded, n, err := DecodeCBOR(append(encoded, 0xff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(encoded) {
+		t.Fatalf("expected %d bytes to be consumed, got %d", len(encoded), n)
+	}
+	m := decoded.(map[interface{}]interface{})
+	if m[int64(1)] != int64(2) || string(m[int64(-1)].([]byte)) != "\x01\x02\x03" || m["fmt"] != "none" {
+		t.Fatalf("unexpected decoded map: %#v", m)
+	}
+
+	for i := range encoded {
+		if _, _, err := DecodeCBOR(encoded[:i]); err == nil {
+			t.Fatalf("expected truncation at %d bytes to fail", i)
+		}
+	}
+}
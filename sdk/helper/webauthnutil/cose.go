@@ -0,0 +1,177 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthnutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers supported for credentials, in order of
+// preference.
+const (
+	COSEAlgES256 int64 = -7
+	COSEAlgEdDSA int64 = -8
+	COSEAlgRS256 int64 = -257
+)
+
+var SupportedCOSEAlgorithms = []int64{COSEAlgES256, COSEAlgEdDSA, COSEAlgRS256}
+
+// COSE key parameters, from RFC 9053.
+const (
+	COSEKeyKty = 1
+	COSEKeyAlg = 3
+
+	COSEKeyCrv = -1
+	COSEKeyX   = -2
+	COSEKeyY   = -3
+	COSEKeyN   = -1
+	COSEKeyE   = -2
+
+	COSEKtyOKP = 1
+	COSEKtyEC2 = 2
+	COSEKtyRSA = 3
+
+	COSECrvP256    = 1
+	COSECrvEd25519 = 6
+)
+
+// COSEKey is the public key of a credential along with the algorithm it
+// signs with.
+type COSEKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// ParseCOSEKey parses a COSE_Key structure (RFC 9052) holding the public key
+// of a credential.
+func ParseCOSEKey(raw []byte) (*COSEKey, error) {
+	decoded, n, err := DecodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if n != len(raw) {
+		return nil, errors.New("trailing data after public key")
+	}
+	params, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("public key is not a map")
+	}
+
+	kty, ok := params[int64(COSEKeyKty)].(int64)
+	if !ok {
+		return nil, errors.New("public key is missing its key type")
+	}
+	alg, ok := params[int64(COSEKeyAlg)].(int64)
+	if !ok {
+		return nil, errors.New("public key is missing its algorithm")
+	}
+
+	switch alg {
+	case COSEAlgES256:
+		if kty != COSEKtyEC2 {
+			return nil, fmt.Errorf("invalid key type %d for ES256", kty)
+		}
+		if crv, _ := params[int64(COSEKeyCrv)].(int64); crv != COSECrvP256 {
+			return nil, fmt.Errorf("unsupported curve %d for ES256", crv)
+		}
+		x, _ := params[int64(COSEKeyX)].([]byte)
+		y, _ := params[int64(COSEKeyY)].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid ES256 public key coordinates")
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("ES256 public key is not on its curve")
+		}
+		return &COSEKey{alg: alg, key: pub}, nil
+
+	case COSEAlgEdDSA:
+		if kty != COSEKtyOKP {
+			return nil, fmt.Errorf("invalid key type %d for EdDSA", kty)
+		}
+		if crv, _ := params[int64(COSEKeyCrv)].(int64); crv != COSECrvEd25519 {
+			return nil, fmt.Errorf("unsupported curve %d for EdDSA", crv)
+		}
+		x, _ := params[int64(COSEKeyX)].([]byte)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid EdDSA public key")
+		}
+		return &COSEKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+
+	case COSEAlgRS256:
+		if kty != COSEKtyRSA {
+			return nil, fmt.Errorf("invalid key type %d for RS256", kty)
+		}
+		n, _ := params[int64(COSEKeyN)].([]byte)
+		e, _ := params[int64(COSEKeyE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RS256 public key")
+		}
+		pub := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		return &COSEKey{alg: alg, key: pub}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key algorithm %d", alg)
+}
+
+// NewCOSEKey returns the key of a credential whose public key is known
+// outside of a COSE_Key structure: an ECDSA P-256, Ed25519 or RSA key.
+func NewCOSEKey(key crypto.PublicKey) (*COSEKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %q", k.Curve.Params().Name)
+		}
+		return &COSEKey{alg: COSEAlgES256, key: k}, nil
+	case ed25519.PublicKey:
+		return &COSEKey{alg: COSEAlgEdDSA, key: k}, nil
+	case *rsa.PublicKey:
+		return &COSEKey{alg: COSEAlgRS256, key: k}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+// Algorithm returns the COSE algorithm identifier of the key.
+func (k *COSEKey) Algorithm() int64 {
+	return k.alg
+}
+
+// Verify checks the signature over the data, as produced by an
+// authenticator for an assertion.
+func (k *COSEKey) Verify(data, sig []byte) error {
+	switch k.alg {
+	case COSEAlgES256:
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(k.key.(*ecdsa.PublicKey), digest[:], sig) {
+			return errors.New("invalid signature")
+		}
+	case COSEAlgEdDSA:
+		if !ed25519.Verify(k.key.(ed25519.PublicKey), data, sig) {
+			return errors.New("invalid signature")
+		}
+	case COSEAlgRS256:
+		digest := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(k.key.(*rsa.PublicKey), crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key algorithm %d", k.alg)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthnutil
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ceremony types found in the client data of responses.
+const (
+	ClientDataTypeCreate = "webauthn.create"
+	ClientDataTypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags, from the WebAuthn Level 2 specification.
+const (
+	FlagUserPresent        = 0x01
+	FlagUserVerified       = 0x04
+	FlagAttestedCredential = 0x40
+	FlagExtensionData      = 0x80
+)
+
+// authDataMinLen is the length of the authenticator data ahead of the
+// attested credential data: the RP ID hash, flags and signature counter.
+const authDataMinLen = sha256.Size + 1 + 4
+
+// ClientData is the subset of the CollectedClientData of a response which
+// is verified.
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// AuthenticatorData is the parsed data an authenticator returns with each
+// response.
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// Only set on registration
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// DecodeBase64 decodes data sent by WebAuthn clients, which are expected to
+// use unpadded base64url but are allowed any base64 variant.
+func DecodeBase64(encoded string) ([]byte, error) {
+	encoded = strings.TrimRight(strings.TrimSpace(encoded), "=")
+	if strings.ContainsAny(encoded, "+/") {
+		return base64.RawStdEncoding.DecodeString(encoded)
+	}
+	return base64.RawURLEncoding.DecodeString(encoded)
+}
+
+// EncodeBase64 encodes data for WebAuthn clients as unpadded base64url.
+func EncodeBase64(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// ParseClientData parses the client data JSON of a response to a ceremony
+// of the given type.
+func ParseClientData(raw []byte, ceremony string) (*ClientData, error) {
+	var data ClientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse client data: %w", err)
+	}
+	if data.Type != ceremony {
+		return nil, fmt.Errorf("unexpected client data type %q", data.Type)
+	}
+	if data.Challenge == "" {
+		return nil, errors.New("client data is missing its challenge")
+	}
+	return &data, nil
+}
+
+// ParseAuthenticatorData parses authenticator data, along with the attested
+// credential data it holds on registration.
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < authDataMinLen {
+		return nil, errors.New("authenticator data is too short")
+	}
+	data := &AuthenticatorData{
+		RPIDHash:  raw[:sha256.Size],
+		Flags:     raw[sha256.Size],
+		SignCount: binary.BigEndian.Uint32(raw[sha256.Size+1:]),
+	}
+	rest := raw[authDataMinLen:]
+
+	if data.Flags&FlagAttestedCredential != 0 {
+		// AAGUID followed by the length of the credential ID
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		idLen := int(binary.BigEndian.Uint16(rest[16:]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > 1023 || len(rest) < idLen {
+			return nil, errors.New("invalid credential ID length")
+		}
+		data.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, n, err := DecodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode credential public key: %w", err)
+		}
+		data.PublicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if data.Flags&FlagExtensionData != 0 {
+		_, n, err := DecodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode extension data: %w", err)
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after authenticator data")
+	}
+	return data, nil
+}
+
+// Verify checks the authenticator data was produced for the relying party,
+// with the user present and, when required, verified.
+func (d *AuthenticatorData) Verify(rpID string, requireUV bool) error {
+	RPIDHash := sha256.Sum256([]byte(rpID))
+	if subtle.ConstantTimeCompare(d.RPIDHash, RPIDHash[:]) != 1 {
+		return errors.New("relying party ID mismatch")
+	}
+	if d.Flags&FlagUserPresent == 0 {
+		return errors.New("user presence was not asserted")
+	}
+	if requireUV && d.Flags&FlagUserVerified == 0 {
+		return errors.New("user verification is required")
+	}
+	return nil
+}
+
+// Assertion is the response of an authenticator to an authentication
+// ceremony.
+type Assertion struct {
+	AuthenticatorData []byte
+	ClientDataJSON    []byte
+	Signature         []byte
+}
+
+// VerifyAssertion checks the assertion was signed by the key for the relying
+// party, as verified by AuthenticatorData.Verify, and returns its parsed
+// authenticator data. The client data is left to the caller, who parses it
+// with ParseClientData to find the challenge.
+func VerifyAssertion(assertion *Assertion, key *COSEKey, rpID string, requireUV bool) (*AuthenticatorData, error) {
+	authData, err := ParseAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.Verify(rpID, requireUV); err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := make([]byte, 0, len(assertion.AuthenticatorData)+len(clientDataHash))
+	signed = append(signed, assertion.AuthenticatorData...)
+	signed = append(signed, clientDataHash[:]...)
+	if err := key.Verify(signed, assertion.Signature); err != nil {
+		return nil, err
+	}
+	return authData, nil
+}
+
+// ParseAttestationObject returns the authenticator data held in an
+// attestation object. Attestation statements are not verified: credentials
+// are trusted on the basis of the caller being authorized to register them.
+func ParseAttestationObject(raw []byte) ([]byte, error) {
+	decoded, n, err := DecodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attestation object: %w", err)
+	}
+	if n != len(raw) {
+		return nil, errors.New("trailing data after attestation object")
+	}
+	obj, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("attestation object is not a map")
+	}
+	authData, ok := obj["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object is missing its authenticator data")
+	}
+	return authData, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package webauthnutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+)
+
+func TestVerifyAssertion(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewCOSEKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Algorithm() != COSEAlgEdDSA {
+		t.Fatalf("unexpected algorithm %d", key.Algorithm())
+	}
+
+	clientData := []byte(`{"type":"webauthn.get","challenge":"AAAA"}`)
+	assert := func(rpID string, flags byte, signCount uint32, extra []byte) *Assertion {
+		rpIDHash := sha256.Sum256([]byte(rpID))
+		authData := append(rpIDHash[:], flags)
+		authData = binary.BigEndian.AppendUint32(authData, signCount)
+		authData = append(authData, extra...)
+
+		clientDataHash := sha256.Sum256(clientData)
+		return &Assertion{
+			AuthenticatorData: authData,
+			ClientDataJSON:    clientData,
+			Signature:         ed25519.Sign(priv, append(authData, clientDataHash[:]...)),
+		}
+	}
+
+	authData, err := VerifyAssertion(assert("example.com", FlagUserPresent, 7, nil), key, "example.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authData.SignCount != 7 {
+		t.Fatalf("expected sign count 7, got %d", authData.SignCount)
+	}
+
+	// An empty CBOR map of extensions
+	_, err = VerifyAssertion(assert("example.com", FlagUserPresent|FlagExtensionData, 8, []byte{0xa0}), key, "example.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := assert("example.com", FlagUserPresent, 9, nil)
+	tampered.ClientDataJSON = []byte(`{"type":"webauthn.get","challenge":"BBBB"}`)
+
+	for name, tc := range map[string]struct {
+		assertion *Assertion
+		requireUV bool
+	}{
+		"wrong relying party":    {assertion: assert("example.org", FlagUserPresent, 9, nil)},
+		"user not present":       {assertion: assert("example.com", 0, 9, nil)},
+		"user not verified":      {assertion: assert("example.com", FlagUserPresent, 9, nil), requireUV: true},
+		"trailing data":          {assertion: assert("example.com", FlagUserPresent, 9, []byte{0x00})},
+		"missing extension data": {assertion: assert("example.com", FlagUserPresent|FlagExtensionData, 9, nil)},
+		"truncated data":         {assertion: &Assertion{AuthenticatorData: make([]byte, 10)}},
+		"tampered client data":   {assertion: tampered},
+	} {
+		if _, err := VerifyAssertion(tc.assertion, key, "example.com", tc.requireUV); err == nil {
+			t.Fatalf("%s: expected the assertion to be rejected", name)
+		}
+	}
+}
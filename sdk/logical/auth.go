@@ -114,6 +114,12 @@ type Auth struct {
 
 	// EntityCreated is set to true if an entity is created as part of a login request
 	EntityCreated bool `json:"entity_created"`
+
+	// SuggestedRenewAt is the time at which the client is advised to renew
+	// the token. It is set by Vault core when the token's lease is registered
+	// or renewed and is left empty when renewing would not extend the token.
+	// Setting this manually will have no effect.
+	SuggestedRenewAt time.Time `json:"suggested_renew_at"`
 }
 
 func (a *Auth) GoString() string {
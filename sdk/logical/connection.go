@@ -4,7 +4,11 @@
 package logical
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 )
 
 // Connection represents the connection information for a request. This
@@ -18,4 +22,30 @@ type Connection struct {
 
 	// ConnState is the TLS connection state if applicable.
 	ConnState *tls.ConnectionState `sentinel:""`
+
+	// ClientCertThumbprint is the thumbprint of the verified client
+	// certificate of the request, as returned by CertificateThumbprint. It is
+	// taken from a trusted proxy header when the listener is configured to
+	// accept one, or from the peer certificate of ConnState otherwise.
+	ClientCertThumbprint string `json:"client_cert_thumbprint" sentinel:""`
+}
+
+// CertificateThumbprint returns the lowercase hex encoding of the SHA-256 hash
+// of the DER encoding of the certificate.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+type ctxKeyClientCertThumbprint struct{}
+
+// ContextClientCertThumbprintValue returns the client certificate thumbprint
+// forwarded by a trusted proxy for the request, if any.
+func ContextClientCertThumbprintValue(ctx context.Context) (string, bool) {
+	value, ok := ctx.Value(ctxKeyClientCertThumbprint{}).(string)
+	return value, ok
+}
+
+func CreateContextClientCertThumbprint(parent context.Context, thumbprint string) context.Context {
+	return context.WithValue(parent, ctxKeyClientCertThumbprint{}, thumbprint)
 }
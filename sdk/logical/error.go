@@ -33,6 +33,10 @@ var (
 	// response from an upstream
 	ErrUpstreamRateLimited = errors.New("upstream rate limited")
 
+	// ErrRequestTimeout is returned when a request is aborted for exceeding
+	// the request timeout of the mount serving it
+	ErrRequestTimeout = errors.New("request timed out")
+
 	// ErrPerfStandbyForward is returned when Vault is in a state such that a
 	// perf standby cannot satisfy a request
 	ErrPerfStandbyPleaseForward = errors.New("please forward to the active node")
@@ -45,12 +49,21 @@ var (
 	// rate limit quota being exceeded.
 	ErrRateLimitQuotaExceeded = errors.New("rate limit quota exceeded")
 
+	// ErrSecretCountQuotaExceeded is returned when a request is rejected due
+	// to a secret count quota being exceeded.
+	ErrSecretCountQuotaExceeded = errors.New("secret count quota exceeded")
+
 	// ErrUnrecoverable is returned when a request fails due to something that
 	// is likely to require manual intervention. This is a generic form of an
 	// unrecoverable error.
 	// e.g.: misconfigured or disconnected storage backend.
 	ErrUnrecoverable = errors.New("unrecoverable error")
 
+	// ErrLeaseInvalidated is returned by the renew callback of a secret when
+	// the secret is no longer valid, e.g. as it was revoked outside of Vault.
+	// The lease is then revoked instead of renewed.
+	ErrLeaseInvalidated = errors.New("lease invalidated by backend")
+
 	// Error indicating that the requested path used to serve a purpose in older
 	// versions, but the functionality has now been removed
 	ErrPathFunctionalityRemoved = errors.New("functionality on this path has been removed")
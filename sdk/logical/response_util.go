@@ -126,16 +126,22 @@ func RespondErrorCommon(req *Request, resp *Response, err error) (int, error) {
 			statusCode = http.StatusBadRequest
 		case errwrap.Contains(err, ErrUpstreamRateLimited.Error()):
 			statusCode = http.StatusBadGateway
+		case errwrap.Contains(err, ErrRequestTimeout.Error()):
+			statusCode = http.StatusGatewayTimeout
 		case errwrap.Contains(err, ErrRateLimitQuotaExceeded.Error()):
 			statusCode = http.StatusTooManyRequests
 		case errwrap.Contains(err, ErrLeaseCountQuotaExceeded.Error()):
 			statusCode = http.StatusTooManyRequests
+		case errwrap.Contains(err, ErrSecretCountQuotaExceeded.Error()):
+			statusCode = http.StatusTooManyRequests
 		case errwrap.Contains(err, ErrPathFunctionalityRemoved.Error()):
 			statusCode = http.StatusNotFound
 		case errwrap.Contains(err, ErrRelativePath.Error()):
 			statusCode = http.StatusBadRequest
 		case errwrap.Contains(err, ErrInvalidCredentials.Error()):
 			statusCode = http.StatusBadRequest
+		case errwrap.Contains(err, ErrLeaseInvalidated.Error()):
+			statusCode = http.StatusBadRequest
 		}
 	}
 
@@ -166,6 +166,10 @@ type TokenEntry struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs" sentinel:""`
 
+	// BoundClientCertThumbprint is the thumbprint of the client certificate
+	// this token can only be used with, if set
+	BoundClientCertThumbprint string `json:"bound_client_cert_thumbprint" mapstructure:"bound_client_cert_thumbprint" structs:"bound_client_cert_thumbprint" sentinel:""`
+
 	// NamespaceID is the identifier of the namespace to which this token is
 	// confined to. Do not return this value over the API when the token is
 	// being looked up.
@@ -45,6 +45,10 @@ func LogicalResponseToHTTPResponse(input *Response) *HTTPResponse {
 			MFARequirement:   input.Auth.MFARequirement,
 			NumUses:          input.Auth.NumUses,
 		}
+		if !input.Auth.SuggestedRenewAt.IsZero() {
+			suggestedRenewAt := input.Auth.SuggestedRenewAt.UTC()
+			httpResp.Auth.SuggestedRenewAt = &suggestedRenewAt
+		}
 	}
 
 	return httpResp
@@ -78,6 +82,9 @@ func HTTPResponseToLogicalResponse(input *HTTPResponse) *Response {
 		}
 		logicalResp.Auth.Renewable = input.Auth.Renewable
 		logicalResp.Auth.TTL = time.Second * time.Duration(input.Auth.LeaseDuration)
+		if input.Auth.SuggestedRenewAt != nil {
+			logicalResp.Auth.SuggestedRenewAt = *input.Auth.SuggestedRenewAt
+		}
 		switch input.Auth.TokenType {
 		case "service":
 			logicalResp.Auth.TokenType = TokenTypeService
@@ -115,6 +122,7 @@ type HTTPAuth struct {
 	Orphan           bool              `json:"orphan"`
 	MFARequirement   *MFARequirement   `json:"mfa_requirement"`
 	NumUses          int               `json:"num_uses"`
+	SuggestedRenewAt *time.Time        `json:"suggested_renew_at,omitempty"`
 }
 
 type HTTPWrapInfo struct {
@@ -5,22 +5,51 @@ package physical
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	metrics "github.com/armon/go-metrics"
+	radix "github.com/armon/go-radix"
 	log "github.com/hashicorp/go-hclog"
-	lru "github.com/hashicorp/golang-lru"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 	"github.com/openbao/openbao/sdk/v2/helper/pathmanager"
+	"github.com/prometheus/client_golang/prometheus"
+	uberAtomic "go.uber.org/atomic"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	// DefaultCacheSize is used if no cache size is specified for NewCache
 	DefaultCacheSize = 128 * 1024
 
+	// DefaultCacheStatsInterval is used if no interval is specified for
+	// StartStats
+	DefaultCacheStatsInterval = 10 * time.Second
+
 	// refreshCacheCtxKey is a ctx value that denotes the cache should be
 	// refreshed during a Get call.
 	refreshCacheCtxKey = "refresh_cache"
+
+	// consistentReadCtxKey is a ctx value that denotes reads must be served
+	// by the backend without touching the cache.
+	consistentReadCtxKey = "consistent_read"
+
+	// staleReadCtxKey is a ctx value holding the flag set when a read is
+	// served from a stale cached entry.
+	staleReadCtxKey = "stale_read"
+
+	// reconcileListCtxKey is a ctx value that denotes the cache should evict
+	// entries missing from the results of a List or ListPage call.
+	reconcileListCtxKey = "reconcile_list"
+
+	// scanCtxKey is a ctx value that denotes reads should be served from the
+	// cache if possible without adding to it.
+	scanCtxKey = "scan"
 )
 
 // These paths don't need to be cached by the LRU cache. This should
@@ -55,31 +84,305 @@ func cacheRefreshFromContext(ctx context.Context) bool {
 	return r
 }
 
+// CacheScanContext returns a context denoting reads that are part of a bulk
+// scan: hits are served without updating recency, and misses are not cached.
+func CacheScanContext(ctx context.Context, s bool) context.Context {
+	return context.WithValue(ctx, scanCtxKey, s)
+}
+
+// cacheScanFromContext is a helper to look up if the provided context is
+// part of a scan.
+func cacheScanFromContext(ctx context.Context) bool {
+	s, ok := ctx.Value(scanCtxKey).(bool)
+	if !ok {
+		return false
+	}
+	return s
+}
+
+// CacheConsistentReadContext returns a context denoting reads that must be
+// served by the backend, without touching the cache.
+func CacheConsistentReadContext(ctx context.Context, r bool) context.Context {
+	return context.WithValue(ctx, consistentReadCtxKey, r)
+}
+
+// cacheConsistentReadFromContext is a helper to look up if the provided
+// context is requesting consistent reads.
+func cacheConsistentReadFromContext(ctx context.Context) bool {
+	r, ok := ctx.Value(consistentReadCtxKey).(bool)
+	if !ok {
+		return false
+	}
+	return r
+}
+
+// CacheStaleReadContext returns a context recording whether any read made
+// with it was served from a stale entry; see CacheConfig.ServeStaleOnError.
+func CacheStaleReadContext(ctx context.Context) (context.Context, *atomic.Bool) {
+	stale := new(atomic.Bool)
+	return context.WithValue(ctx, staleReadCtxKey, stale), stale
+}
+
+// cacheStaleReadFromContext is a helper to look up the flag recording stale
+// reads made with the provided context, if any.
+func cacheStaleReadFromContext(ctx context.Context) *atomic.Bool {
+	stale, _ := ctx.Value(staleReadCtxKey).(*atomic.Bool)
+	return stale
+}
+
+// CacheReconcileListContext returns a context denoting that List and
+// ListPage should evict cached keys in the listed range missing from results.
+func CacheReconcileListContext(ctx context.Context, r bool) context.Context {
+	return context.WithValue(ctx, reconcileListCtxKey, r)
+}
+
+// cacheReconcileListFromContext is a helper to look up if the provided
+// context is requesting list reconciliation.
+func cacheReconcileListFromContext(ctx context.Context) bool {
+	r, ok := ctx.Value(reconcileListCtxKey).(bool)
+	if !ok {
+		return false
+	}
+	return r
+}
+
 // Cache is used to wrap an underlying physical backend
 // and provide an LRU cache layer on top. Most of the reads done by
 // Vault are for policy objects so there is a large read reduction
 // by using a simple write-through cache.
 type Cache struct {
 	backend         Backend
-	lru             *lru.TwoQueueCache
+	size            int
+	shards          int
+	policy          EvictionPolicy
+	immutableValues bool
+	versioned       bool
+	verifyChecksums bool
+	serveStale      bool
+	keyNormalizer   func(key string) string
+	maxValueBytes   int
+	lru             *cachePartition
+	partitions      *radix.Tree
 	locks           []*locksutil.LockEntry
 	logger          log.Logger
 	enabled         *uint32
 	cacheExceptions *pathmanager.PathManager
 	metricSink      metrics.MetricSink
+
+	// invalidateCallbacks are called with each key written; see OnInvalidate
+	invalidateCallbacks invalidateCallbacks
+
+	// disabledWarnOnce warns the first time the cache is queried while
+	// disabled, which is easily missed otherwise
+	disabledWarnOnce sync.Once
+
+	// reads collapses concurrent backend reads of the same key on a miss
+	reads singleflight.Group
+
+	// negativeTTL bounds how long a cached miss is trusted; zero means
+	// forever
+	negativeTTL *uberAtomic.Duration
+
+	// ttl bounds how long any cached value is trusted; zero means forever
+	ttl time.Duration
+
+	// hits and misses count lookups since stats were last emitted
+	hits   *uberAtomic.Uint64
+	misses *uberAtomic.Uint64
+
+	// totalHits and totalMisses count lookups since the cache was created
+	totalHits   *uberAtomic.Uint64
+	totalMisses *uberAtomic.Uint64
+
+	// prometheus holds native Prometheus collectors, if a registerer was
+	// configured
+	prometheus *cachePrometheusMetrics
+
+	// pins holds the cached values of pinned keys; see Pin
+	pins *pinnedEntries
+
+	// listings caches List and ListPage results, if enabled
+	listings *listingCache
+
+	// listFlights collapses concurrent identical List and ListPage calls
+	listFlights *listingFlights
+
+	// readBreaker guards backend reads on a miss, if enabled
+	readBreaker *circuitBreaker
+
+	// compression is the type used to compress cached values longer than
+	// compressionThreshold, if enabled
+	compression          string
+	compressionThreshold int
+
+	// spill is the optional on-disk tier, and spillGenerations counts
+	// invalidations per lock; only changed while holding every lock
+	spill            *cacheSpill
+	spillGenerations []*uberAtomic.Uint64
+
+	// writeBack is set while write-back mode is enabled; only changed while
+	// holding every lock
+	writeBack atomic.Pointer[writeBackBuffer]
+
+	// evictCallback is called for each entry evicted to make room; see
+	// SetEvictCallback
+	evictCallback atomic.Pointer[func(key string, entry *Entry)]
+
+	statsLock   sync.Mutex
+	statsStopCh chan struct{}
+	statsDoneCh chan struct{}
+
+	// effectiveSize is below size while shrunk under memory pressure
+	effectiveSize  *uberAtomic.Int64
+	memoryStopOnce sync.Once
+	memoryStopCh   chan struct{}
+	memoryDoneCh   chan struct{}
+}
+
+// cachePartition is an independently sized, sharded LRU; see SetPrefixBudget.
+type cachePartition struct {
+	prefix string
+	size   int
+	lru    *shardedCache
+}
+
+// negativeCacheEntry is stored in place of a nil entry when a key is missing
+// from the backend, recording when the miss was observed.
+type negativeCacheEntry struct {
+	cachedAt time.Time
+}
+
+func newCachePartition(prefix string, size, shards int, policy EvictionPolicy) *cachePartition {
+	return &cachePartition{
+		prefix: prefix,
+		size:   size,
+		lru:    newShardedCache(size, shards, policy, cachedValueSize),
+	}
+}
+
+// cachedValueSize estimates the memory held by a cached value from the
+// length of the entry's value.
+func cachedValueSize(raw interface{}) int {
+	if entry, ok := raw.(*Entry); ok {
+		return len(entry.Value)
+	}
+	return 0
+}
+
+// CacheStats is a point-in-time summary of the contents of a Cache.
+type CacheStats struct {
+	// Entries is the number of cached entries, including cached misses and
+	// pinned entries.
+	Entries int
+
+	// PinnedEntries is the number of cached entries of pinned keys, which
+	// are held in addition to MaxEntries.
+	PinnedEntries int
+
+	// MaxEntries is the configured size of the cache.
+	MaxEntries int
+
+	// EffectiveMaxEntries is the size the cache is currently held to, which
+	// is less than MaxEntries while shrunk under memory pressure.
+	EffectiveMaxEntries int
+
+	// EstimatedBytes is the total length of the cached values.
+	EstimatedBytes int64
+
+	// Hits and Misses count the lookups served from the cache and those
+	// which had to go to the backend since the cache was created.
+	Hits   uint64
+	Misses uint64
+
+	// ListingPages is the number of pages held by the listing cache, and
+	// ListingPrefixes the number of prefixes they were listed under.
+	ListingPages    int
+	ListingPrefixes int
 }
 
 // Verify Cache satisfies the correct interfaces
 var (
 	_ ToggleablePurgemonster = (*Cache)(nil)
 	_ Backend                = (*Cache)(nil)
+	_ BatchGetter            = (*Cache)(nil)
+	_ PrefixDeleter          = (*Cache)(nil)
+	_ CASBackend             = (*Cache)(nil)
 )
 
+// CacheConfig configures a cache created with NewCacheWithConfig.
+type CacheConfig struct {
+	// Size is the maximum number of cached entries. If zero, the default
+	// size is used.
+	Size int
+
+	// Shards is the number of independently locked LRUs. If zero, a default
+	// derived from GOMAXPROCS is used.
+	Shards int
+
+	// EvictionPolicy selects how entries are evicted. If empty,
+	// EvictionPolicy2Q is used.
+	EvictionPolicy EvictionPolicy
+
+	// KeyNormalizer, if set, maps each key to the form it is cached and
+	// locked under, for backends which treat distinct keys as the same.
+	KeyNormalizer func(key string) string
+
+	// ImmutableValues declares that callers never modify entry values, so
+	// the cache can share rather than copy them.
+	ImmutableValues bool
+
+	// TTL, if positive, bounds how long any value is served from the cache.
+	TTL time.Duration
+
+	// VersionedEntries keeps a cached entry rather than replace it with one
+	// carrying an older or equal Version.
+	VersionedEntries bool
+
+	// ServeStaleOnError serves cached entries, however old, when reads from
+	// the backend fail; see CacheStaleReadContext.
+	ServeStaleOnError bool
+
+	// MaxCachedValueBytes, if positive, is the largest value the cache holds.
+	MaxCachedValueBytes int
+
+	// ListingCacheSize, if positive, caches up to this many List and
+	// ListPage pages.
+	ListingCacheSize int
+
+	// PrometheusRegisterer, if set, registers native Prometheus metrics.
+	PrometheusRegisterer prometheus.Registerer
+
+	// ReadCircuitBreaker, if set, guards the backend reads made on misses,
+	// failing them with ErrCircuitOpen once open.
+	ReadCircuitBreaker *CircuitBreakerConfig
+
+	// Compression, if set, compresses values longer than CompressionThreshold
+	// while held in memory.
+	Compression string
+
+	// CompressionThreshold is the longest value cached uncompressed. If zero,
+	// DefaultCacheCompressionThreshold is used.
+	CompressionThreshold int
+
+	// MemoryPressure, if set, shrinks the cache while memory usage is high.
+	MemoryPressure *MemoryPressureConfig
+
+	// VerifyChecksums checksums each cached entry and verifies it whenever
+	// the entry is served.
+	VerifyChecksums bool
+}
+
 // NewCache returns a physical cache of the given size.
 // If no size is provided, the default size is used.
 func NewCache(b Backend, size int, logger log.Logger, metricSink metrics.MetricSink) *Cache {
+	return NewCacheWithConfig(b, CacheConfig{Size: size}, logger, metricSink)
+}
+
+// NewCacheWithConfig returns a physical cache configured by config.
+func NewCacheWithConfig(b Backend, config CacheConfig, logger log.Logger, metricSink metrics.MetricSink) *Cache {
+	size := config.Size
 	if logger.IsDebug() {
-		logger.Debug("creating LRU cache", "size", size)
+		logger.Debug("creating LRU cache", "size", size, "shards", config.Shards)
 	}
 	if size <= 0 {
 		size = DefaultCacheSize
@@ -88,28 +391,128 @@ func NewCache(b Backend, size int, logger log.Logger, metricSink metrics.MetricS
 	pm := pathmanager.New()
 	pm.AddPaths(cacheExceptionsPaths)
 
-	cache, _ := lru.New2Q(size)
 	c := &Cache{
-		backend: b,
-		lru:     cache,
-		locks:   locksutil.CreateLocks(),
-		logger:  logger,
+		backend:         b,
+		size:            size,
+		shards:          config.Shards,
+		immutableValues: config.ImmutableValues,
+		versioned:       config.VersionedEntries,
+		verifyChecksums: config.VerifyChecksums,
+		serveStale:      config.ServeStaleOnError,
+		keyNormalizer:   config.KeyNormalizer,
+		maxValueBytes:   config.MaxCachedValueBytes,
+		partitions:      radix.New(),
+		pins:            newPinnedEntries(),
+		listFlights:     newListingFlights(),
+		locks:           locksutil.CreateLocks(),
+		logger:          logger,
 		// This fails safe.
 		enabled:         new(uint32),
 		cacheExceptions: pm,
 		metricSink:      metricSink,
+		negativeTTL:     uberAtomic.NewDuration(0),
+		ttl:             config.TTL,
+		hits:            uberAtomic.NewUint64(0),
+		misses:          uberAtomic.NewUint64(0),
+		totalHits:       uberAtomic.NewUint64(0),
+		totalMisses:     uberAtomic.NewUint64(0),
+		effectiveSize:   uberAtomic.NewInt64(int64(size)),
+	}
+	if err := validateEvictionPolicy(config.EvictionPolicy); err != nil {
+		logger.Warn("evicting with the 2Q policy", "error", err)
+	} else {
+		c.policy = config.EvictionPolicy
+	}
+	c.lru = newCachePartition("", size, c.shards, c.policy)
+	if err := validateCacheCompression(config.Compression); err != nil {
+		logger.Warn("caching values uncompressed", "error", err)
+	} else if config.Compression != "" {
+		c.compression = config.Compression
+		c.compressionThreshold = config.CompressionThreshold
+		if c.compressionThreshold <= 0 {
+			c.compressionThreshold = DefaultCacheCompressionThreshold
+		}
+	}
+	if config.ReadCircuitBreaker != nil {
+		c.readBreaker = newCircuitBreaker("read", *config.ReadCircuitBreaker, metricSink)
+	}
+	if config.ListingCacheSize > 0 {
+		c.listings = newListingCache(config.ListingCacheSize)
+	}
+	if config.PrometheusRegisterer != nil {
+		c.prometheus = newCachePrometheusMetrics(config.PrometheusRegisterer, c, logger)
+	}
+	if config.MemoryPressure != nil {
+		c.startMemoryMonitor(*config.MemoryPressure)
 	}
 	return c
 }
 
 func (c *Cache) ShouldCache(key string) bool {
+	return c.shouldCache(c.cacheKey(key))
+}
+
+// cacheKey returns the key under which the given key is cached and locked;
+// see CacheConfig.KeyNormalizer.
+func (c *Cache) cacheKey(key string) string {
+	if c.keyNormalizer == nil {
+		return key
+	}
+	return c.keyNormalizer(key)
+}
+
+// shouldCache is ShouldCache for a key which is already normalized.
+func (c *Cache) shouldCache(key string) bool {
 	if atomic.LoadUint32(c.enabled) == 0 {
+		c.disabledWarnOnce.Do(func() {
+			c.logger.Warn("physical cache is disabled, passing requests through to the backend")
+		})
+		c.metricSink.IncrCounter([]string{"cache", "disabled_passthrough"}, 1)
 		return false
 	}
 
 	return !c.cacheExceptions.HasPath(key)
 }
 
+// AddCacheExceptions adds prefixes which must never be served from the
+// cache, evicting entries already cached under them.
+func (c *Cache) AddCacheExceptions(paths []string) {
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	c.cacheExceptions.AddPaths(paths)
+	c.evictMatching(c.cacheExceptions.HasPath)
+	c.invalidateSpillMatching(c.cacheExceptions.HasPath)
+}
+
+// RemoveCacheExceptions removes prefixes previously added through
+// AddCacheExceptions. The built-in exceptions cannot be removed.
+func (c *Cache) RemoveCacheExceptions(paths []string) error {
+	for _, path := range paths {
+		if strutil.StrListContains(cacheExceptionsPaths, path) {
+			return fmt.Errorf("cannot remove built-in cache exception %q", path)
+		}
+	}
+
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	c.cacheExceptions.RemovePaths(paths)
+	return nil
+}
+
+// CacheExceptions returns the prefixes which are currently excluded from
+// the cache, including the built-in ones.
+func (c *Cache) CacheExceptions() []string {
+	return c.cacheExceptions.Paths()
+}
+
 // SetEnabled is used to toggle whether the cache is on or off. It must be
 // called with true to actually activate the cache after creation.
 func (c *Cache) SetEnabled(enabled bool) {
@@ -120,105 +523,1128 @@ func (c *Cache) SetEnabled(enabled bool) {
 	atomic.StoreUint32(c.enabled, 0)
 }
 
+// Enabled returns whether the cache is currently on.
+func (c *Cache) Enabled() bool {
+	return atomic.LoadUint32(c.enabled) == 1
+}
+
+// SetNegativeTTL sets how long a miss is cached; zero caches it until evicted.
+func (c *Cache) SetNegativeTTL(ttl time.Duration) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.negativeTTL.Store(ttl)
+}
+
+// SetPrefixBudget reserves budget entries of the cache for keys under the
+// prefix, or removes the reservation if zero. This purges the cache.
+func (c *Cache) SetPrefixBudget(prefix string, budget int) error {
+	if prefix == "" {
+		return fmt.Errorf("prefix budget requires a non-empty prefix")
+	}
+	if budget < 0 {
+		return fmt.Errorf("invalid budget %d for prefix %q", budget, prefix)
+	}
+
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	budgets := map[string]int{prefix: budget}
+	c.partitions.Walk(func(p string, v interface{}) bool {
+		if p != prefix {
+			budgets[p] = v.(*cachePartition).size
+		}
+		return false
+	})
+
+	var reserved int
+	for _, size := range budgets {
+		reserved += size
+	}
+	if reserved >= c.size {
+		return fmt.Errorf("prefix budgets (%d entries) must be smaller than the cache size (%d entries)", reserved, c.size)
+	}
+
+	// Rebuild every partition so sizes stay consistent and no entry is left
+	// behind in a partition it no longer routes to.
+	c.partitions = radix.New()
+	for p, size := range budgets {
+		if size > 0 {
+			c.partitions.Insert(p, newCachePartition(p, size, c.shards, c.policy))
+		}
+	}
+	c.lru = newCachePartition("", c.size-reserved, c.shards, c.policy)
+
+	return nil
+}
+
+// partitionFor returns the partition responsible for the given key. Callers
+// must hold the lock for the key.
+func (c *Cache) partitionFor(key string) *cachePartition {
+	if _, v, ok := c.partitions.LongestPrefix(key); ok {
+		return v.(*cachePartition)
+	}
+	return c.lru
+}
+
+// forEachPartition calls fn for the default partition and every prefix
+// partition. Callers must hold at least one lock.
+func (c *Cache) forEachPartition(fn func(p *cachePartition)) {
+	fn(c.lru)
+	c.partitions.Walk(func(_ string, v interface{}) bool {
+		fn(v.(*cachePartition))
+		return false
+	})
+}
+
+// add inserts an *Entry or *negativeCacheEntry, recording evictions in ev.
+func (c *Cache) add(key string, entry interface{}, ev *evictions) {
+	c.addCachedAt(key, entry, time.Time{}, ev)
+}
+
+// addCachedAt is add for a value first cached at the given time, or now if
+// zero, from which its age is measured against the TTL.
+func (c *Cache) addCachedAt(key string, entry interface{}, cachedAt time.Time, ev *evictions) {
+	p := c.partitionFor(key)
+	if e, ok := entry.(*Entry); ok {
+		if c.versioned && c.isStale(key, e) {
+			c.metricSink.IncrCounter([]string{"cache", "skip_stale"}, 1)
+			return
+		}
+		e = c.checksumEntry(e)
+		e = c.compressEntry(e)
+		if c.ttl > 0 {
+			// Stamped on a copy, as the entry may be shared with its writer
+			if cachedAt.IsZero() {
+				cachedAt = time.Now()
+			}
+			stamped := *e
+			stamped.cachedAt = cachedAt
+			e = &stamped
+		}
+		entry = e
+	}
+
+	if c.pins.set(key, entry) {
+		return
+	}
+	if c.maxValueBytes > 0 && cachedValueSize(entry) > c.maxValueBytes {
+		p.lru.Remove(key)
+		c.metricSink.IncrCounter([]string{"cache", "skip_oversized"}, 1)
+		return
+	}
+	if evictedKey, evictedValue, evicted := p.lru.Add(key, entry); evicted {
+		c.metricSink.IncrCounterWithLabels([]string{"cache", "evict"}, 1, []metrics.Label{{Name: "prefix", Value: p.prefix}})
+		c.queueSpill(evictedKey.(string), evictedValue)
+		ev.record(c, evictedKey.(string), evictedValue)
+	}
+}
+
+// isStale returns whether the cache holds a newer version of the entry than
+// the given one. Entries without a version are never stale.
+func (c *Cache) isStale(key string, entry *Entry) bool {
+	if entry.Version == 0 {
+		return false
+	}
+	raw, ok := c.peekCached(key)
+	if !ok {
+		return false
+	}
+	cached, ok := raw.(*Entry)
+	return ok && cached.Version >= entry.Version
+}
+
+// peekCached returns the value cached for the key, pinned or in the LRU,
+// without updating its recency. Callers must hold the lock for the key.
+func (c *Cache) peekCached(key string) (interface{}, bool) {
+	if raw, pinned := c.pins.get(key); pinned {
+		return raw, raw != nil
+	}
+	return c.partitionFor(key).lru.Peek(key)
+}
+
+// uncache drops any cached value for the key, leaving it pinned if it is.
+// Callers must hold the write lock for the key.
+func (c *Cache) uncache(key string) {
+	if !c.pins.set(key, nil) {
+		c.partitionFor(key).lru.Remove(key)
+	}
+}
+
+// isExpired returns whether a cached value is too old to be served: a
+// cached miss past the negative TTL or the TTL, or an entry past the TTL.
+func (c *Cache) isExpired(raw interface{}) bool {
+	switch v := raw.(type) {
+	case *negativeCacheEntry:
+		if ttl := c.negativeTTL.Load(); ttl != 0 && time.Since(v.cachedAt) >= ttl {
+			return true
+		}
+		return c.ttl > 0 && time.Since(v.cachedAt) >= c.ttl
+	case *Entry:
+		return c.ttl > 0 && time.Since(v.cachedAt) >= c.ttl
+	}
+	return false
+}
+
+// evictions collects the entries evicted by an operation while it holds
+// locks, so that the evict callback can be run once they are released.
+type evictions struct {
+	keys    []string
+	entries []*Entry
+}
+
+func (ev *evictions) record(c *Cache, key string, value interface{}) {
+	if c.evictCallback.Load() == nil {
+		return
+	}
+	entry, _ := value.(*Entry)
+	if entry != nil {
+		var err error
+		if entry, err = c.entryView(entry); err != nil {
+			c.logger.Warn("failed to decompress evicted entry", "key", key, "error", err)
+		}
+	}
+	ev.keys = append(ev.keys, key)
+	ev.entries = append(ev.entries, entry)
+}
+
+// notifyEvictions runs the evict callback for each collected eviction.
+// Callers must not hold any lock.
+func (c *Cache) notifyEvictions(ev *evictions) {
+	if len(ev.keys) == 0 {
+		return
+	}
+	callback := c.evictCallback.Load()
+	if callback == nil {
+		return
+	}
+	for i, key := range ev.keys {
+		(*callback)(key, ev.entries[i])
+	}
+}
+
+// SetEvictCallback sets a function called, outside the cache locks, with
+// each key and entry evicted to make room. A nil function removes it.
+func (c *Cache) SetEvictCallback(fn func(key string, entry *Entry)) {
+	if fn == nil {
+		c.evictCallback.Store(nil)
+		return
+	}
+	c.evictCallback.Store(&fn)
+}
+
+// Stats returns the current number of cached entries and an estimate of the
+// memory they hold.
+func (c *Cache) Stats() CacheStats {
+	// Anything replacing the partitions holds every lock, so holding any one
+	// of them is enough to read them safely.
+	c.locks[0].RLock()
+	defer c.locks[0].RUnlock()
+
+	stats := CacheStats{
+		MaxEntries:          c.size,
+		EffectiveMaxEntries: int(c.effectiveSize.Load()),
+		Hits:                c.totalHits.Load(),
+		Misses:              c.totalMisses.Load(),
+	}
+	c.forEachPartition(func(p *cachePartition) {
+		stats.Entries += p.lru.Len()
+		stats.EstimatedBytes += p.lru.Bytes()
+	})
+	pinned, pinnedBytes := c.pins.stats()
+	stats.Entries += pinned
+	stats.PinnedEntries = pinned
+	stats.EstimatedBytes += pinnedBytes
+	if c.listings != nil {
+		stats.ListingPages, stats.ListingPrefixes = c.listings.stats()
+	}
+	return stats
+}
+
+// evictMatching removes every cached key for which match returns true,
+// returning the number of keys removed. Callers must hold every lock.
+func (c *Cache) evictMatching(match func(key string) bool) int {
+	var n int
+	c.forEachPartition(func(p *cachePartition) {
+		for _, raw := range p.lru.Keys() {
+			if key := raw.(string); match(key) {
+				p.lru.Remove(key)
+				n++
+			}
+		}
+	})
+	return n + c.pins.clearMatching(func(key string, _ interface{}) bool {
+		return match(key)
+	})
+}
+
+// StartStats starts emitting the cache.hit_ratio and cache.size gauges every
+// interval, until Stop is called.
+func (c *Cache) StartStats(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCacheStatsInterval
+	}
+
+	c.StopStats()
+
+	c.statsLock.Lock()
+	defer c.statsLock.Unlock()
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	c.statsStopCh = stopCh
+	c.statsDoneCh = doneCh
+
+	go func() {
+		defer close(doneCh)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				c.emitStats()
+			}
+		}
+	}()
+}
+
+func (c *Cache) emitStats() {
+	hits := c.hits.Swap(0)
+	misses := c.misses.Swap(0)
+	if total := hits + misses; total > 0 {
+		c.metricSink.SetGauge([]string{"cache", "hit_ratio"}, float32(hits)/float32(total))
+	}
+	c.metricSink.SetGauge([]string{"cache", "size"}, float32(c.Stats().Entries))
+}
+
+// StopStats stops the goroutine started by StartStats, if any, and waits for
+// it to exit.
+func (c *Cache) StopStats() {
+	c.statsLock.Lock()
+	defer c.statsLock.Unlock()
+
+	if c.statsStopCh == nil {
+		return
+	}
+
+	close(c.statsStopCh)
+	<-c.statsDoneCh
+	c.statsStopCh = nil
+	c.statsDoneCh = nil
+}
+
+// Stop releases any background goroutines, draining buffered writes.
+func (c *Cache) Stop() {
+	c.StopStats()
+	if err := c.DisableWriteBack(context.Background()); err != nil {
+		c.logger.Error("failed to drain buffered cache writes", "error", err)
+	}
+	c.stopMemoryMonitor()
+	c.stopSpill()
+}
+
 // Purge is used to clear the cache
 func (c *Cache) Purge(ctx context.Context) {
+	_ = c.PurgeContext(ctx)
+}
+
+// PurgeContext is like Purge, but gives up if the context is cancelled.
+func (c *Cache) PurgeContext(ctx context.Context) error {
+	unlock, err := lockAllContext(ctx, c.locks)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c.purge()
+	return nil
+}
+
+// purge empties the LRU, the spill file and the listing cache. Callers must
+// hold every lock.
+func (c *Cache) purge() {
+	c.forEachPartition(func(p *cachePartition) {
+		p.lru.Purge()
+	})
+	c.pins.clearMatching(func(string, interface{}) bool { return true })
+	c.invalidateSpillMatching(func(string) bool { return true })
+	if c.listings != nil {
+		c.listings.purge()
+	}
+}
+
+// SwapBackend replaces the backend and purges the cache, returning the
+// previous backend so that the caller can close it.
+func (c *Cache) SwapBackend(b Backend) (Backend, error) {
+	if b == nil {
+		return nil, fmt.Errorf("cannot swap in a nil backend")
+	}
+
 	// Lock the world
 	for _, lock := range c.locks {
 		lock.Lock()
 		defer lock.Unlock()
 	}
 
-	c.lru.Purge()
+	if c.writeBack.Load() != nil {
+		return nil, ErrWriteBackEnabled
+	}
+
+	old := c.backend
+	c.purge()
+	c.backend = b
+	c.metricSink.IncrCounter([]string{"cache", "swap_backend"}, 1)
+
+	return old, nil
 }
 
-func (c *Cache) Put(ctx context.Context, entry *Entry) error {
-	if entry != nil && !c.ShouldCache(entry.Key) {
-		return c.backend.Put(ctx, entry)
+// lockAllContext acquires every lock in order, unless the context is
+// cancelled first, returning a function releasing them.
+func lockAllContext(ctx context.Context, locks []*locksutil.LockEntry) (func(), error) {
+	unlock := func(held []*locksutil.LockEntry) {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+	}
+
+	for i, lock := range locks {
+		if err := lockContext(ctx, lock); err != nil {
+			unlock(locks[:i])
+			return nil, err
+		}
+	}
+	return func() { unlock(locks) }, nil
+}
+
+// lockContext acquires the lock unless the context is cancelled first.
+func lockContext(ctx context.Context, lock *locksutil.LockEntry) error {
+	if lock.TryLock() {
+		return nil
 	}
 
-	lock := locksutil.LockForKey(c.locks, entry.Key)
+	// Blocking in Lock keeps writer priority over new readers, which
+	// polling with TryLock would lose under read-heavy load.
+	acquired := make(chan struct{})
+	go func() {
+		lock.Lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		return nil
+	case <-ctx.Done():
+		// Release the lock whenever the goroutine does get it
+		go func() {
+			<-acquired
+			lock.Unlock()
+		}()
+		return ctx.Err()
+	}
+}
+
+func (c *Cache) Put(ctx context.Context, entry *Entry) (retErr error) {
+	defer c.measureSince([]string{"cache", "put"}, time.Now())
+	defer func() {
+		if retErr == nil {
+			c.notifyInvalidate(entry.Key)
+		}
+	}()
+
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+	defer c.invalidateListings(entry.Key)
+
+	// Even writes which bypass the LRU take the lock, so that they cannot
+	// race SwapBackend.
+	key := c.cacheKey(entry.Key)
+	lock := locksutil.LockForKey(c.locks, key)
 	lock.Lock()
 	defer lock.Unlock()
 
+	if wb := c.writeBack.Load(); wb != nil {
+		c.putWriteBack(wb, key, entry, &ev)
+		return nil
+	}
+	return c.put(ctx, key, entry, &ev)
+}
+
+// put writes the entry through to the backend and caches it under the
+// normalized key. Callers must hold the write lock for the key.
+func (c *Cache) put(ctx context.Context, key string, entry *Entry, ev *evictions) error {
+	if !c.shouldCache(key) {
+		return c.backend.Put(ctx, entry)
+	}
+
 	err := c.backend.Put(ctx, entry)
 	if err == nil {
 		// While lower layers could modify entry, we want to ensure we don't
-		// open ourselves up to cache modification so clone the entry.
-		cacheEntry := &Entry{
-			Key:      entry.Key,
-			SealWrap: entry.SealWrap,
-		}
-		if entry.Value != nil {
-			cacheEntry.Value = make([]byte, len(entry.Value))
-			copy(cacheEntry.Value, entry.Value)
-		}
-		if entry.ValueHash != nil {
-			cacheEntry.ValueHash = make([]byte, len(entry.ValueHash))
-			copy(cacheEntry.ValueHash, entry.ValueHash)
-		}
-		c.lru.Add(entry.Key, cacheEntry)
+		// open ourselves up to cache modification so clone the entry,
+		// unless its value is known to be immutable.
+		c.invalidateSpill(key)
+		c.add(key, c.cacheEntry(entry), ev)
 		c.metricSink.IncrCounter([]string{"cache", "write"}, 1)
 	}
 	return err
 }
 
-func (c *Cache) Get(ctx context.Context, key string) (*Entry, error) {
-	if !c.ShouldCache(key) {
-		return c.backend.Get(ctx, key)
+// cacheEntry returns the copy of an entry held by the cache, sharing the
+// value if it is immutable or seal-wrapped.
+func (c *Cache) cacheEntry(entry *Entry) *Entry {
+	if c.immutableValues || entry.SealWrap {
+		cacheEntry := *entry
+		return &cacheEntry
 	}
+	return copyEntry(entry)
+}
 
-	lock := locksutil.LockForKey(c.locks, key)
+// entryView returns a copy of a cached entry which is safe to hand out.
+func (c *Cache) entryView(entry *Entry) (*Entry, error) {
+	if entry.compressed {
+		return c.decompressEntry(entry)
+	}
+	if c.immutableValues || !entry.cachedAt.IsZero() {
+		view := *entry
+		view.cachedAt = time.Time{}
+		return &view, nil
+	}
+	return entry, nil
+}
+
+// copyEntry returns a deep copy of the entry.
+func copyEntry(entry *Entry) *Entry {
+	cacheEntry := &Entry{
+		Key:      entry.Key,
+		SealWrap: entry.SealWrap,
+		Version:  entry.Version,
+	}
+	if entry.Value != nil {
+		cacheEntry.Value = make([]byte, len(entry.Value))
+		copy(cacheEntry.Value, entry.Value)
+	}
+	if entry.ValueHash != nil {
+		cacheEntry.ValueHash = make([]byte, len(entry.ValueHash))
+		copy(cacheEntry.ValueHash, entry.ValueHash)
+	}
+	return cacheEntry
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (*Entry, error) {
+	start := time.Now()
+	result := "miss"
+	defer func() {
+		c.measureSince([]string{"cache", "get"}, start, metrics.Label{Name: "result", Value: result})
+	}()
+
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	ckey := c.cacheKey(key)
+	lock := locksutil.LockForKey(c.locks, ckey)
 	lock.RLock()
 	defer lock.RUnlock()
 
-	// Check the LRU first
-	if !cacheRefreshFromContext(ctx) {
-		if raw, ok := c.lru.Get(key); ok {
-			if raw == nil {
-				return nil, nil
-			}
-			c.metricSink.IncrCounter([]string{"cache", "hit"}, 1)
-			return raw.(*Entry), nil
+	if !c.shouldCache(ckey) || cacheConsistentReadFromContext(ctx) {
+		if entry, ok := c.lookupWriteBack(ckey); ok {
+			result = "hit"
+			return entry, nil
 		}
+		result = "bypass"
+		return c.backend.Get(ctx, key)
 	}
 
-	c.metricSink.IncrCounter([]string{"cache", "miss"}, 1)
-	// Read from the underlying backend
-	ent, err := c.backend.Get(ctx, key)
+	if entry, ok := c.lookup(ctx, ckey, &ev); ok {
+		result = "hit"
+		return entry, nil
+	}
+
+	c.recordMiss(ckey)
+
+	// Read from the underlying backend, sharing the read between concurrent
+	// misses; writes take the write lock, so none can be overwritten here.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	scan := cacheScanFromContext(ctx)
+	flightCtx := context.WithoutCancel(ctx)
+	raw, err, _ := c.reads.Do(ckey, func() (interface{}, error) {
+		trial, err := c.readBreaker.allow()
+		if err != nil {
+			return nil, err
+		}
+		ent, err := c.backend.Get(flightCtx, key)
+		c.readBreaker.done(flightCtx, trial, err)
+		if err != nil {
+			return nil, err
+		}
+		if !scan {
+			c.cacheResult(ckey, ent, &ev)
+		}
+		return ent, nil
+	})
 	if err != nil {
+		if entry, ok := c.lookupStale(ctx, ckey); ok {
+			result = "stale"
+			c.recordStale(ctx, 1)
+			return entry, nil
+		}
 		return nil, err
 	}
 
-	// Cache the result, even if nil
-	c.lru.Add(key, ent)
+	return raw.(*Entry), nil
+}
+
+// BatchGet fetches the given keys, reading the uncached ones in one batch
+// if the backend implements BatchGetter.
+func (c *Cache) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	ckeys := make([]string, len(keys))
+	for i, key := range keys {
+		ckeys[i] = c.cacheKey(key)
+	}
+	locks := locksutil.LocksForKeys(c.locks, ckeys)
+	for _, lock := range locks {
+		lock.RLock()
+		defer lock.RUnlock()
+	}
+
+	consistent := cacheConsistentReadFromContext(ctx)
+	scan := cacheScanFromContext(ctx)
+	entries := make([]*Entry, len(keys))
+	var missIndexes []int
+	var missKeys []string
+	for i, key := range keys {
+		var entry *Entry
+		var ok bool
+		if c.shouldCache(ckeys[i]) && !consistent {
+			entry, ok = c.lookup(ctx, ckeys[i], &ev)
+		} else {
+			entry, ok = c.lookupWriteBack(ckeys[i])
+		}
+		if ok {
+			entries[i] = entry
+			continue
+		}
+		missIndexes = append(missIndexes, i)
+		missKeys = append(missKeys, key)
+	}
+
+	if len(missKeys) == 0 {
+		return entries, nil
+	}
+
+	trial, err := c.readBreaker.allow()
+	var fetched []*Entry
+	if err == nil {
+		fetched, err = BatchGetEntries(ctx, c.backend, missKeys)
+		c.readBreaker.done(ctx, trial, err)
+	}
+	if err != nil {
+		// The batch is only served stale if every missed key can be served
+		// from stale entries; otherwise the backend error is returned
+		for _, i := range missIndexes {
+			entry, ok := c.lookupStale(ctx, ckeys[i])
+			if !ok {
+				return nil, err
+			}
+			entries[i] = entry
+		}
+		c.recordStale(ctx, len(missIndexes))
+		return entries, nil
+	}
+	for j, i := range missIndexes {
+		entries[i] = fetched[j]
+		if c.shouldCache(ckeys[i]) && !consistent {
+			c.recordMiss(ckeys[i])
+			if !scan {
+				c.cacheResult(ckeys[i], fetched[j], &ev)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// lookupStale returns the entry held for the key regardless of its age, if
+// stale reads are allowed. Callers must hold the lock for the key.
+func (c *Cache) lookupStale(ctx context.Context, key string) (*Entry, bool) {
+	if !c.serveStale || ctx.Err() != nil || !c.shouldCache(key) || cacheConsistentReadFromContext(ctx) {
+		return nil, false
+	}
+
+	raw, ok := c.peekCached(key)
+	if !ok {
+		return nil, false
+	}
+	switch v := raw.(type) {
+	case *negativeCacheEntry:
+		return nil, true
+	case *Entry:
+		return c.servedView(key, v)
+	}
+	return nil, false
+}
+
+// recordStale counts reads served by lookupStale, flagging them on the
+// context.
+func (c *Cache) recordStale(ctx context.Context, n int) {
+	c.metricSink.IncrCounter([]string{"cache", "stale_served"}, float32(n))
+	if stale := cacheStaleReadFromContext(ctx); stale != nil {
+		stale.Store(true)
+	}
+}
+
+// Warm reads the given keys which are not cached yet into the cache.
+func (c *Cache) Warm(ctx context.Context, keys []string) error {
+	_, err := c.WarmAbsent(ctx, keys)
+	return err
+}
+
+// WarmAbsent warms the given keys as Warm does, also returning those which
+// do not exist.
+func (c *Cache) WarmAbsent(ctx context.Context, keys []string) ([]string, error) {
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	// Keys are tracked both as given, to read from the backend, and
+	// normalized, to cache
+	seen := make(map[string]struct{}, len(keys))
+	var cacheable, ckeys []string
+	for _, key := range keys {
+		ckey := c.cacheKey(key)
+		if _, ok := seen[ckey]; ok || !c.shouldCache(ckey) {
+			continue
+		}
+		seen[ckey] = struct{}{}
+		cacheable = append(cacheable, key)
+		ckeys = append(ckeys, ckey)
+	}
+
+	for _, lock := range locksutil.LocksForKeys(c.locks, ckeys) {
+		lock.RLock()
+		defer lock.RUnlock()
+	}
+
+	var absent, missing, missingCKeys []string
+	for i, ckey := range ckeys {
+		if entry, ok := c.lookupWriteBack(ckey); ok {
+			if entry == nil {
+				absent = append(absent, cacheable[i])
+			}
+			continue
+		}
+		if raw, ok := c.peekCached(ckey); ok && !c.isExpired(raw) {
+			if _, negative := raw.(*negativeCacheEntry); negative {
+				absent = append(absent, cacheable[i])
+			}
+			continue
+		}
+		missing = append(missing, cacheable[i])
+		missingCKeys = append(missingCKeys, ckey)
+	}
+	if len(missing) == 0 {
+		return absent, nil
+	}
+	defer c.metricSink.IncrCounter([]string{"cache", "warm"}, float32(len(missing)))
+
+	if _, ok := c.backend.(BatchGetter); ok {
+		entries, err := BatchGetEntries(ctx, c.backend, missing)
+		if err == nil {
+			for i, ckey := range missingCKeys {
+				if entries[i] == nil {
+					absent = append(absent, missing[i])
+				}
+				c.cacheResult(ckey, entries[i], &ev)
+			}
+			return absent, nil
+		}
+		c.logger.Debug("failed to warm cache in a single batch, reading keys individually", "error", err)
+	}
+
+	// Read keys one at a time so that a failure only affects its own key
+	var retErr *multierror.Error
+	for i, key := range missing {
+		entry, err := c.backend.Get(ctx, key)
+		if err != nil {
+			retErr = multierror.Append(retErr, fmt.Errorf("failed to warm %q: %w", key, err))
+			continue
+		}
+		if entry == nil {
+			absent = append(absent, key)
+		}
+		c.cacheResult(missingCKeys[i], entry, &ev)
+	}
+	return absent, retErr.ErrorOrNil()
+}
+
+// measureSince records the time elapsed since start, in milliseconds, as a
+// sample of the given timing metric.
+func (c *Cache) measureSince(key []string, start time.Time, labels ...metrics.Label) {
+	elapsed := float32(time.Since(start).Nanoseconds()) / float32(time.Millisecond)
+	c.metricSink.AddSampleWithLabels(key, elapsed, labels)
+}
+
+// lookupWriteBack returns the buffered write for the key, if write-back mode
+// is enabled and there is one.
+func (c *Cache) lookupWriteBack(key string) (*Entry, bool) {
+	if wb := c.writeBack.Load(); wb != nil {
+		return wb.lookup(key)
+	}
+	return nil, false
+}
+
+// lookup returns the cached state of the key. Callers must hold the lock
+// for the key.
+func (c *Cache) lookup(ctx context.Context, key string, ev *evictions) (*Entry, bool) {
+	// Buffered writes take precedence over both the LRU and the backend
+	if entry, ok := c.lookupWriteBack(key); ok {
+		return entry, true
+	}
+
+	// Check pinned entries and then the LRU
+	if cacheRefreshFromContext(ctx) {
+		return nil, false
+	}
+	raw, pinned := c.pins.get(key)
+	if !pinned {
+		var ok bool
+		if cacheScanFromContext(ctx) {
+			// Scans must leave the recency of cached entries as it was
+			if raw, ok = c.partitionFor(key).lru.Peek(key); !ok {
+				return nil, false
+			}
+		} else if raw, ok = c.partitionFor(key).lru.Get(key); !ok {
+			return c.lookupSpill(key, ev)
+		}
+	}
+	if raw == nil || c.isExpired(raw) {
+		return nil, false
+	}
+	switch v := raw.(type) {
+	case *negativeCacheEntry:
+		return nil, true
+	case *Entry:
+		entry, ok := c.servedView(key, v)
+		if !ok {
+			return nil, false
+		}
+		c.recordHit(key)
+		return entry, true
+	}
+	return nil, false
+}
+
+// servedView returns the view of a cached entry served to a caller, or
+// false if it fails its checksum.
+func (c *Cache) servedView(key string, cached *Entry) (*Entry, bool) {
+	entry, err := c.entryView(cached)
+	if err != nil {
+		c.logger.Warn("failed to read cached entry", "key", key, "error", err)
+		return nil, false
+	}
+	if c.verifyChecksums && hasChecksum(entry) {
+		if err := VerifyChecksum(entry, "cache"); err != nil {
+			c.logger.Error("cached entry failed checksum verification, reading it from the backend", "key", key, "error", err)
+			c.metricSink.IncrCounter([]string{"cache", "checksum_mismatch"}, 1)
+			return nil, false
+		}
+		// The checksum is the cache's own business
+		view := *entry
+		view.ValueHash = nil
+		entry = &view
+	}
+	return entry, true
+}
+
+// checksumEntry returns the entry with its checksum if checksums are verified.
+func (c *Cache) checksumEntry(entry *Entry) *Entry {
+	if !c.verifyChecksums || entry.compressed || (entry.ValueHash != nil && !hasChecksum(entry)) {
+		return entry
+	}
+	// Set on a copy, as the entry may be shared with its writer
+	summed := *entry
+	summed.ValueHash = ChecksumValueHash(entry.Key, entry.Value)
+	return &summed
+}
 
-	return ent, nil
+// recordHit counts a lookup served by the cache.
+func (c *Cache) recordHit(key string) {
+	c.metricSink.IncrCounter([]string{"cache", "hit"}, 1)
+	c.hits.Inc()
+	c.totalHits.Inc()
+	if c.prometheus != nil {
+		c.prometheus.hits.WithLabelValues(mountLabel(key)).Inc()
+	}
 }
 
-func (c *Cache) Delete(ctx context.Context, key string) error {
-	if !c.ShouldCache(key) {
-		return c.backend.Delete(ctx, key)
+// recordMiss counts a lookup which had to go to the backend.
+func (c *Cache) recordMiss(key string) {
+	c.metricSink.IncrCounter([]string{"cache", "miss"}, 1)
+	c.misses.Inc()
+	c.totalMisses.Inc()
+	if c.prometheus != nil {
+		c.prometheus.misses.WithLabelValues(mountLabel(key)).Inc()
+	}
+}
+
+// Peek returns the cached entry for the key without touching its recency;
+// the boolean reports whether the key is cached at all.
+func (c *Cache) Peek(key string) (*Entry, bool) {
+	key = c.cacheKey(key)
+	if !c.shouldCache(key) {
+		return nil, false
 	}
 
 	lock := locksutil.LockForKey(c.locks, key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	raw, ok := c.peekCached(key)
+	if !ok || c.isExpired(raw) {
+		return nil, false
+	}
+	switch v := raw.(type) {
+	case *negativeCacheEntry:
+		return nil, true
+	case *Entry:
+		if !v.compressed {
+			return copyEntry(v), true
+		}
+		entry, err := c.decompressEntry(v)
+		if err != nil {
+			c.logger.Warn("failed to read cached entry", "key", key, "error", err)
+			return nil, false
+		}
+		return entry, true
+	}
+	return nil, false
+}
+
+// cacheResult caches an entry read from the backend, even if nil. Callers
+// must hold the lock for the key.
+func (c *Cache) cacheResult(key string, ent *Entry, ev *evictions) {
+	if ent == nil {
+		c.add(key, &negativeCacheEntry{cachedAt: time.Now()}, ev)
+		return
+	}
+	c.add(key, ent, ev)
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) (retErr error) {
+	defer c.measureSince([]string{"cache", "delete"}, time.Now())
+	defer func() {
+		if retErr == nil {
+			c.notifyInvalidate(key)
+		}
+	}()
+	defer c.invalidateListings(key)
+
+	ckey := c.cacheKey(key)
+	lock := locksutil.LockForKey(c.locks, ckey)
 	lock.Lock()
 	defer lock.Unlock()
 
-	err := c.backend.Delete(ctx, key)
+	if wb := c.writeBack.Load(); wb != nil {
+		c.deleteWriteBack(wb, ckey)
+		return nil
+	}
+	return c.delete(ctx, ckey, key)
+}
+
+// DeletePrefix deletes every entry under the prefix and evicts it from the
+// cache. It is not available in write-back mode.
+func (c *Cache) DeletePrefix(ctx context.Context, prefix string) error {
+	defer c.measureSince([]string{"cache", "delete_prefix"}, time.Now())
+
+	// As with List, the lock for the prefix is only held so that the delete
+	// cannot race SwapBackend or EnableWriteBack.
+	lock := locksutil.LockForKey(c.locks, prefix)
+	lock.RLock()
+	if c.writeBack.Load() != nil {
+		lock.RUnlock()
+		return ErrWriteBackEnabled
+	}
+	err := DeletePrefix(ctx, c.backend, prefix)
+	lock.RUnlock()
+
+	// Evict even if the delete failed part way. Reads of keys under the
+	// prefix which raced the delete hold the lock for their key until they
+	// have cached their result, so are evicted too.
+	c.evictPrefix(prefix)
+	return err
+}
+
+// evictPrefix drops every cached entry and listing under the prefix.
+func (c *Cache) evictPrefix(prefix string) {
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	// Normalizing the prefix may make it match more cached keys, such as
+	// when trailing slashes are dropped, but only evicting too much is safe.
+	cprefix := c.cacheKey(prefix)
+	match := func(key string) bool {
+		return strings.HasPrefix(key, cprefix)
+	}
+	n := c.evictMatching(match)
+	c.invalidateSpillMatching(match)
+	c.invalidateListingsPrefix(prefix)
+	c.metricSink.IncrCounter([]string{"cache", "delete_prefix", "evict"}, float32(n))
+}
+
+// InvalidateSealWrapped evicts every cached seal-wrapped entry, returning the
+// number evicted from memory. Call it once such values have been rewrapped.
+func (c *Cache) InvalidateSealWrapped() int {
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	sealWrapped := func(raw interface{}) bool {
+		entry, ok := raw.(*Entry)
+		return ok && entry.SealWrap
+	}
+
+	var n int
+	c.forEachPartition(func(p *cachePartition) {
+		for _, raw := range p.lru.Keys() {
+			key := raw.(string)
+			if v, ok := p.lru.Peek(key); ok && sealWrapped(v) {
+				p.lru.Remove(key)
+				n++
+			}
+		}
+	})
+	n += c.pins.clearMatching(func(_ string, raw interface{}) bool {
+		return sealWrapped(raw)
+	})
+	c.invalidateSpillSealWrapped()
+
+	c.metricSink.IncrCounter([]string{"cache", "invalidate_seal_wrapped"}, float32(n))
+	return n
+}
+
+// delete removes the key from the backend and the cache. Callers must hold
+// the write lock for the key.
+func (c *Cache) delete(ctx context.Context, key, backendKey string) error {
+	if !c.shouldCache(key) {
+		return c.backend.Delete(ctx, backendKey)
+	}
+
+	err := c.backend.Delete(ctx, backendKey)
 	if err == nil {
-		c.lru.Remove(key)
+		c.uncache(key)
+		c.invalidateSpill(key)
 	}
 	return err
 }
 
 func (c *Cache) List(ctx context.Context, prefix string) ([]string, error) {
-	// Always pass-through as this would be difficult to cache. For the same
-	// reason we don't lock as we can't reasonably know which locks to readlock
-	// ahead of time.
-	return c.backend.List(ctx, prefix)
+	// Listings are only cached if enabled; the prefix lock only keeps the
+	// listing from racing SwapBackend.
+	defer c.measureSince([]string{"cache", "list"}, time.Now())
+
+	lock := locksutil.LockForKey(c.locks, prefix)
+	lock.RLock()
+	keys, err := c.list(ctx, prefix, listingPage{limit: -1}, func(ctx context.Context) ([]string, error) {
+		return c.backend.List(ctx, prefix)
+	})
+	lock.RUnlock()
+
+	// Reconciling takes the write locks of evicted keys, which may share the
+	// lock of the prefix, so it must not be held
+	if err == nil && cacheReconcileListFromContext(ctx) {
+		c.reconcileListing(prefix, "", -1, keys)
+	}
+	return keys, err
 }
 
 func (c *Cache) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
 	// See note above about List(...).
-	return c.backend.ListPage(ctx, prefix, after, limit)
+	defer c.measureSince([]string{"cache", "list"}, time.Now())
+
+	lock := locksutil.LockForKey(c.locks, prefix)
+	lock.RLock()
+	keys, err := c.list(ctx, prefix, listingPage{after: after, limit: limit}, func(ctx context.Context) ([]string, error) {
+		return c.backend.ListPage(ctx, prefix, after, limit)
+	})
+	lock.RUnlock()
+
+	if err == nil && cacheReconcileListFromContext(ctx) {
+		c.reconcileListing(prefix, after, limit, keys)
+	}
+	return keys, err
+}
+
+// reconcileListing evicts cached entries under prefix in the range covered
+// by the listing but missing from it.
+func (c *Cache) reconcileListing(prefix string, after string, limit int, keys []string) {
+	listed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		listed[key] = struct{}{}
+	}
+
+	var last string
+	truncated := limit > 0 && len(keys) >= limit
+	if truncated && len(keys) > 0 {
+		last = keys[len(keys)-1]
+	}
+
+	covered := func(name string) bool {
+		if after != "" && name <= after {
+			return false
+		}
+		return !truncated || name <= last
+	}
+
+	// The partitions can only be read under a lock, but evicting requires
+	// the write lock of each key, so collect candidates first.
+	var stale []string
+	c.locks[0].RLock()
+	c.forEachPartition(func(p *cachePartition) {
+		for _, raw := range p.lru.Keys() {
+			key := raw.(string)
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+
+			// Keys in sub-folders are listed as the folder name with a
+			// trailing slash.
+			name := strings.TrimPrefix(key, prefix)
+			if i := strings.Index(name, "/"); i >= 0 {
+				name = name[:i+1]
+			}
+			if _, ok := listed[name]; ok || !covered(name) {
+				continue
+			}
+			stale = append(stale, key)
+		}
+	})
+	c.locks[0].RUnlock()
+
+	for _, key := range stale {
+		lock := locksutil.LockForKey(c.locks, key)
+		lock.Lock()
+		p := c.partitionFor(key)
+		if raw, ok := p.lru.Peek(key); ok {
+			// Cached misses already agree with the listing
+			if _, ok := raw.(*Entry); ok {
+				p.lru.Remove(key)
+				c.invalidateSpill(key)
+				c.metricSink.IncrCounter([]string{"cache", "reconcile", "evict"}, 1)
+			}
+		}
+		lock.Unlock()
+	}
 }
@@ -0,0 +1,199 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+const (
+	// DefaultCircuitBreakerThreshold is used if no threshold is specified in
+	// CircuitBreakerConfig
+	DefaultCircuitBreakerThreshold = 5
+
+	// DefaultCircuitBreakerCooldown is used if no cooldown is specified in
+	// CircuitBreakerConfig
+	DefaultCircuitBreakerCooldown = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned by reads which missed the cache while its
+// circuit breaker is open, instead of attempting them against the backend.
+var ErrCircuitOpen = errors.New("physical cache circuit breaker is open")
+
+// CircuitBreakerConfig configures the circuit breaker guarding the backend
+// reads of a cache; see CacheConfig.ReadCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive failed backend reads which
+	// opens the breaker.
+	Threshold int
+
+	// Window, if positive, bounds the time between the first and last of
+	// the consecutive failures; older failures are forgotten.
+	Window time.Duration
+
+	// Cooldown is how long the breaker stays open before letting a single
+	// trial read through to decide whether to close again.
+	Cooldown time.Duration
+}
+
+// CircuitBreakerState is the state of a cache's circuit breaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed lets every read through to the backend.
+	CircuitBreakerClosed CircuitBreakerState = iota
+
+	// CircuitBreakerHalfOpen lets a single trial read through to the
+	// backend, rejecting others until it completes.
+	CircuitBreakerHalfOpen
+
+	// CircuitBreakerOpen rejects every read which misses the cache.
+	CircuitBreakerOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	case CircuitBreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker tracks consecutive failures of one type of backend
+// operation. A nil breaker lets everything through.
+type circuitBreaker struct {
+	config     CircuitBreakerConfig
+	metricSink metrics.MetricSink
+	name       string
+
+	lock         sync.Mutex
+	state        CircuitBreakerState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	trial        bool
+}
+
+func newCircuitBreaker(name string, config CircuitBreakerConfig, metricSink metrics.MetricSink) *circuitBreaker {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultCircuitBreakerThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCircuitBreakerCooldown
+	}
+	return &circuitBreaker{
+		config:     config,
+		metricSink: metricSink,
+		name:       name,
+	}
+}
+
+// allow returns ErrCircuitOpen if the operation must not be attempted.
+// Otherwise the caller must pass its outcome to done, along with whether it
+// is the trial let through by a half-open breaker.
+func (b *circuitBreaker) allow() (trial bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == CircuitBreakerOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		b.setState(CircuitBreakerHalfOpen)
+	}
+	switch b.state {
+	case CircuitBreakerClosed:
+		return false, nil
+	case CircuitBreakerHalfOpen:
+		if !b.trial {
+			b.trial = true
+			return true, nil
+		}
+	}
+
+	b.metricSink.IncrCounter([]string{"cache", "circuit_breaker", b.name, "reject"}, 1)
+	return false, ErrCircuitOpen
+}
+
+// done records the outcome of an operation let through by allow. Failures
+// caused by the caller's context ending say nothing about the backend, so
+// are not counted.
+func (b *circuitBreaker) done(ctx context.Context, trial bool, err error) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if trial {
+		b.trial = false
+	}
+
+	switch {
+	case err == nil:
+		b.failures = 0
+		if b.state != CircuitBreakerClosed {
+			b.setState(CircuitBreakerClosed)
+		}
+	case ctx.Err() != nil:
+	case trial:
+		b.open()
+	case b.state == CircuitBreakerClosed:
+		now := time.Now()
+		if b.failures == 0 || (b.config.Window > 0 && now.Sub(b.firstFailure) > b.config.Window) {
+			b.failures = 0
+			b.firstFailure = now
+		}
+		b.failures++
+		if b.failures >= b.config.Threshold {
+			b.open()
+		}
+	}
+}
+
+func (b *circuitBreaker) open() {
+	b.failures = 0
+	b.openedAt = time.Now()
+	b.setState(CircuitBreakerOpen)
+}
+
+// setState changes the state of the breaker. Callers must hold its lock.
+func (b *circuitBreaker) setState(state CircuitBreakerState) {
+	b.state = state
+	b.metricSink.SetGauge([]string{"cache", "circuit_breaker", b.name, "state"}, float32(state))
+}
+
+// State returns the current state of the breaker.
+func (b *circuitBreaker) State() CircuitBreakerState {
+	if b == nil {
+		return CircuitBreakerClosed
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// An open breaker whose cooldown has elapsed lets the next read through
+	if b.state == CircuitBreakerOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		return CircuitBreakerHalfOpen
+	}
+	return b.state
+}
+
+// ReadCircuitBreakerState returns the state of the circuit breaker guarding
+// backend reads, which is always closed unless one was configured.
+func (c *Cache) ReadCircuitBreakerState() CircuitBreakerState {
+	return c.readBreaker.State()
+}
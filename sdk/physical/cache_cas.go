@@ -0,0 +1,54 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+// CompareAndSwap conditionally writes the entry through to the backend, see
+// the CompareAndSwap function; the comparison is always made against the
+// backend rather than the cached value. When the entry is written it is
+// cached as with Put. Otherwise the cached value of the key is evicted, as
+// failing to match suggests that it is stale. It is not available while
+// write-back mode is enabled, as buffered writes have yet to reach the
+// backend.
+func (c *Cache) CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (swapped bool, retErr error) {
+	defer c.measureSince([]string{"cache", "compare_and_swap"}, time.Now())
+	defer func() {
+		if swapped {
+			c.notifyInvalidate(entry.Key)
+		}
+	}()
+
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+	defer c.invalidateListings(entry.Key)
+
+	key := c.cacheKey(entry.Key)
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if c.writeBack.Load() != nil {
+		return false, ErrWriteBackEnabled
+	}
+
+	swapped, err := CompareAndSwap(ctx, c.backend, entry, expectedHash)
+	if !c.shouldCache(key) {
+		return swapped, err
+	}
+
+	c.invalidateSpill(key)
+	if err != nil || !swapped {
+		c.uncache(key)
+		return swapped, err
+	}
+	c.add(key, c.cacheEntry(entry), &ev)
+	c.metricSink.IncrCounter([]string{"cache", "write"}, 1)
+	return true, nil
+}
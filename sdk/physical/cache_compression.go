@@ -0,0 +1,118 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/openbao/openbao/sdk/v2/helper/compressutil"
+	"github.com/pierrec/lz4"
+)
+
+// DefaultCacheCompressionThreshold is used if no threshold is specified in
+// CacheConfig when compression is enabled.
+const DefaultCacheCompressionThreshold = 512
+
+var errCorruptCompressedEntry = errors.New("corrupt compressed cache entry")
+
+// validateCacheCompression returns an error if the cache cannot compress
+// values with the given type.
+func validateCacheCompression(compressionType string) error {
+	switch compressionType {
+	case "", compressutil.CompressionTypeLZ4, compressutil.CompressionTypeSnappy:
+		return nil
+	default:
+		return fmt.Errorf("unsupported cache compression type %q", compressionType)
+	}
+}
+
+// compressEntry returns the form of a cached entry held in the LRU: a copy
+// with its value compressed if compression is enabled and the value is
+// longer than the threshold, otherwise the entry itself. Seal-wrapped values
+// are ciphertext and so are never compressed, nor are values which do not
+// shrink.
+//
+// Values are compressed as single blocks rather than with the framed
+// formats of compressutil, whose readers allocate large buffers that would
+// dominate the cost of every cache hit.
+func (c *Cache) compressEntry(entry *Entry) *Entry {
+	if c.compression == "" || entry.compressed || entry.SealWrap || len(entry.Value) <= c.compressionThreshold {
+		return entry
+	}
+
+	var value []byte
+	switch c.compression {
+	case compressutil.CompressionTypeLZ4:
+		// The block is prefixed by the length of the value, which is needed
+		// to decompress it. A destination shorter than the value makes the
+		// compressor give up on values which would not shrink.
+		buf := make([]byte, len(entry.Value))
+		n := binary.PutUvarint(buf, uint64(len(entry.Value)))
+		m, err := lz4.CompressBlock(entry.Value, buf[n:], nil)
+		if err != nil || m == 0 {
+			return entry
+		}
+		value = buf[:n+m]
+	case compressutil.CompressionTypeSnappy:
+		value = snappy.Encode(nil, entry.Value)
+	}
+	if len(value) >= len(entry.Value) {
+		return entry
+	}
+
+	return &Entry{
+		Key: entry.Key,
+		// Copied so that the cache does not hold on to the larger buffer the
+		// value was compressed into
+		Value:      append([]byte(nil), value...),
+		SealWrap:   entry.SealWrap,
+		ValueHash:  entry.ValueHash,
+		Version:    entry.Version,
+		compressed: true,
+	}
+}
+
+// decompressEntry returns a copy of a compressed cached entry with its
+// value decompressed, sharing nothing with the cached entry.
+func (c *Cache) decompressEntry(entry *Entry) (*Entry, error) {
+	var value []byte
+	switch c.compression {
+	case compressutil.CompressionTypeLZ4:
+		// LZ4 cannot expand data by more than a factor of 255
+		length, n := binary.Uvarint(entry.Value)
+		if n <= 0 || length > uint64(len(entry.Value))*255 {
+			return nil, errCorruptCompressedEntry
+		}
+		value = make([]byte, length)
+		m, err := lz4.UncompressBlock(entry.Value[n:], value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errCorruptCompressedEntry, err)
+		}
+		if m != len(value) {
+			return nil, errCorruptCompressedEntry
+		}
+	case compressutil.CompressionTypeSnappy:
+		var err error
+		if value, err = snappy.Decode(nil, entry.Value); err != nil {
+			return nil, fmt.Errorf("%w: %w", errCorruptCompressedEntry, err)
+		}
+	default:
+		return nil, errCorruptCompressedEntry
+	}
+
+	plain := &Entry{
+		Key:      entry.Key,
+		Value:    value,
+		SealWrap: entry.SealWrap,
+		Version:  entry.Version,
+	}
+	if entry.ValueHash != nil {
+		plain.ValueHash = make([]byte, len(entry.ValueHash))
+		copy(plain.ValueHash, entry.ValueHash)
+	}
+	return plain, nil
+}
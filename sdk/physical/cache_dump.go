@@ -0,0 +1,130 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+// CacheEntryInfo describes an entry held in memory by a Cache without
+// revealing its value; see Cache.Dump.
+type CacheEntryInfo struct {
+	Key string
+
+	// Prefix is the prefix registered with SetPrefixBudget whose partition
+	// holds the entry, or would if it were not pinned, or empty for the
+	// default partition.
+	Prefix string
+
+	// Pinned is set for an entry of a key pinned with Pin.
+	Pinned bool
+
+	// Negative is set for a cached miss, in which case the remaining fields
+	// are unset.
+	Negative bool
+
+	// ValueLength is the length of the value.
+	ValueLength int
+
+	// ValueHash is the hex-encoded SHA-256 hash of the value, so that dumps
+	// can be compared with each other and with the backend.
+	ValueHash string
+
+	SealWrap bool
+}
+
+// Dump describes every entry currently held in memory by the cache, sorted
+// by key, for diagnosing cache coherency issues. Values themselves are never
+// included. Entries are inspected without updating their recency, each under
+// the lock for its key, so the result is not a consistent snapshot of a
+// cache which is being written to. Expired entries are omitted, as are
+// entries spilled to disk.
+func (c *Cache) Dump(ctx context.Context) ([]CacheEntryInfo, error) {
+	var partitions []*cachePartition
+	c.locks[0].RLock()
+	c.forEachPartition(func(p *cachePartition) {
+		partitions = append(partitions, p)
+	})
+	c.locks[0].RUnlock()
+
+	var infos []CacheEntryInfo
+	for _, p := range partitions {
+		for _, raw := range p.lru.Keys() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			key := raw.(string)
+			if info, ok := c.dumpEntry(p, key); ok {
+				infos = append(infos, info)
+			}
+		}
+	}
+	for _, key := range c.pins.cached() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if info, ok := c.dumpEntry(nil, key); ok {
+			infos = append(infos, info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+	return infos, nil
+}
+
+// dumpEntry describes the cached entry for the key, if the partition still
+// holds it, or if the key is still pinned when no partition is given.
+func (c *Cache) dumpEntry(p *cachePartition, key string) (CacheEntryInfo, bool) {
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	pinned := p == nil
+	var raw interface{}
+	var ok bool
+	if pinned {
+		raw, _ = c.pins.get(key)
+		ok = raw != nil
+		p = c.partitionFor(key)
+	} else {
+		raw, ok = p.lru.Peek(key)
+	}
+	if !ok || c.isExpired(raw) {
+		return CacheEntryInfo{}, false
+	}
+
+	info := CacheEntryInfo{
+		Key:    key,
+		Prefix: p.prefix,
+		Pinned: pinned,
+	}
+	switch v := raw.(type) {
+	case *negativeCacheEntry:
+		info.Negative = true
+	case *Entry:
+		entry := v
+		if entry.compressed {
+			var err error
+			if entry, err = c.decompressEntry(entry); err != nil {
+				c.logger.Warn("failed to read cached entry", "key", key, "error", err)
+				return CacheEntryInfo{}, false
+			}
+		}
+		hash := sha256.Sum256(entry.Value)
+		info.ValueLength = len(entry.Value)
+		info.ValueHash = hex.EncodeToString(hash[:])
+		info.SealWrap = entry.SealWrap
+	default:
+		return CacheEntryInfo{}, false
+	}
+	return info, true
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+const (
+	// cacheExportVersion is the version of the format written by Export.
+	cacheExportVersion = 1
+
+	// maxCacheImportRecordBytes bounds the length of a single encoded entry
+	// read by Import, so that a corrupt stream cannot exhaust memory.
+	maxCacheImportRecordBytes = 8 * 1024 * 1024
+)
+
+// cacheExportHeader is the first line of an export.
+type cacheExportHeader struct {
+	Version int `json:"version"`
+}
+
+// cacheExportRecord is a cached entry within an export, one per line.
+type cacheExportRecord struct {
+	Key       string `json:"key"`
+	Value     []byte `json:"value"`
+	SealWrap  bool   `json:"seal_wrap,omitempty"`
+	ValueHash []byte `json:"value_hash,omitempty"`
+	Version   uint64 `json:"version,omitempty"`
+}
+
+// Export writes every entry currently held in memory by the cache to w, in
+// no particular order, so that another node's cache can be primed with
+// Import; for instance one which has just installed a Raft snapshot.
+// Entries are read without updating their recency, each under the lock for
+// its key, so the result is not a consistent snapshot of a cache which is
+// being written to. Cached misses, expired entries and entries spilled to
+// disk are omitted.
+//
+// Values are written as held by the backend, so the export is as sensitive
+// as the storage itself and must be protected accordingly in transit.
+func (c *Cache) Export(ctx context.Context, w io.Writer) error {
+	var keys []string
+	c.locks[0].RLock()
+	c.forEachPartition(func(p *cachePartition) {
+		for _, raw := range p.lru.Keys() {
+			keys = append(keys, raw.(string))
+		}
+	})
+	c.locks[0].RUnlock()
+	keys = append(keys, c.pins.cached()...)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(cacheExportHeader{Version: cacheExportVersion}); err != nil {
+		return fmt.Errorf("failed to write cache export: %w", err)
+	}
+
+	var exported int
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// A key may have been pinned or unpinned since it was collected
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		record, ok := c.exportEntry(key)
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write cache export: %w", err)
+		}
+		exported++
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write cache export: %w", err)
+	}
+
+	c.metricSink.IncrCounter([]string{"cache", "export"}, float32(exported))
+	return nil
+}
+
+// exportEntry returns the record for the entry cached for the key, if it is
+// still cached.
+func (c *Cache) exportEntry(key string) (cacheExportRecord, bool) {
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	raw, ok := c.peekCached(key)
+	if !ok || c.isExpired(raw) {
+		return cacheExportRecord{}, false
+	}
+	cached, ok := raw.(*Entry)
+	if !ok {
+		return cacheExportRecord{}, false
+	}
+
+	// Cached values are never modified in place, so the view can be
+	// encoded once the lock is released.
+	entry, err := c.entryView(cached)
+	if err != nil {
+		c.logger.Warn("failed to read cached entry", "key", key, "error", err)
+		return cacheExportRecord{}, false
+	}
+	return cacheExportRecord{
+		Key:       key,
+		Value:     entry.Value,
+		SealWrap:  entry.SealWrap,
+		ValueHash: entry.ValueHash,
+		Version:   entry.Version,
+	}, true
+}
+
+// Import primes the cache with entries written by Export, so that a node
+// starts serving from memory. It must only be given an export taken from a
+// node whose storage is no newer than that of this cache's backend, as the
+// entries are cached as they are, without reading the backend.
+//
+// Keys which are not cacheable are skipped, as are keys which are already
+// cached, so importing never replaces an entry with an older one. To bound
+// the memory used by a huge export, at most as many entries as the current
+// size of the cache are imported and the rest of the export is ignored, and
+// each encoded entry must be shorter than 8MiB. Entries imported before an
+// error are left in the cache.
+func (c *Cache) Import(ctx context.Context, r io.Reader) error {
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxCacheImportRecordBytes)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read cache import: %w", err)
+		}
+		return errors.New("cache import is empty")
+	}
+	var header cacheExportHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return fmt.Errorf("failed to decode cache import header: %w", err)
+	}
+	if header.Version != cacheExportVersion {
+		return fmt.Errorf("unsupported cache import version %d", header.Version)
+	}
+
+	var imported int
+	defer func() {
+		c.metricSink.IncrCounter([]string{"cache", "import"}, float32(imported))
+	}()
+
+	limit := int(c.effectiveSize.Load())
+	for imported < limit && scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var record cacheExportRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to decode cache import entry: %w", err)
+		}
+		if record.Key == "" {
+			return errors.New("cache import entry has no key")
+		}
+		if c.importEntry(record, &ev) {
+			imported++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read cache import: %w", err)
+	}
+	return nil
+}
+
+// importEntry caches the imported entry unless the key is not cacheable or
+// already cached, returning whether it was.
+func (c *Cache) importEntry(record cacheExportRecord, ev *evictions) bool {
+	key := c.cacheKey(record.Key)
+	if !c.shouldCache(key) {
+		return false
+	}
+
+	// As with Warm, holding the read lock keeps writes to the key out until
+	// the entry is cached.
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if _, ok := c.lookupWriteBack(key); ok {
+		return false
+	}
+	if raw, ok := c.peekCached(key); ok && !c.isExpired(raw) {
+		return false
+	}
+
+	c.add(key, &Entry{
+		Key:       record.Key,
+		Value:     record.Value,
+		SealWrap:  record.SealWrap,
+		ValueHash: record.ValueHash,
+		Version:   record.Version,
+	}, ev)
+	return true
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+// invalidateCallbacks holds the functions registered through OnInvalidate.
+// Registrations replace the slice rather than appending to it in place, so
+// that it can be read without the lock once loaded.
+type invalidateCallbacks struct {
+	lock sync.Mutex
+	fns  []func(key string)
+}
+
+func (ic *invalidateCallbacks) add(fn func(key string)) {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+
+	fns := make([]func(key string), len(ic.fns), len(ic.fns)+1)
+	copy(fns, ic.fns)
+	ic.fns = append(fns, fn)
+}
+
+func (ic *invalidateCallbacks) load() []func(key string) {
+	ic.lock.Lock()
+	defer ic.lock.Unlock()
+
+	return ic.fns
+}
+
+// OnInvalidate registers a function called with the key of each successful
+// Put and Delete, and of each write committed by a transaction, once the
+// cache has been updated and the lock for the key released. It lets a
+// cluster layer tell peer nodes to Invalidate their copy of the key, keeping
+// the cache itself unaware of the cluster. Writes buffered in write-back mode
+// are reported when made rather than when flushed.
+//
+// Callbacks are called synchronously, in the order they were registered, by
+// the goroutine which made the write, which they delay until they return;
+// anything slow, such as an RPC, should be handed off. Keys are reported as
+// given to the write, not normalized. Callbacks cannot be removed.
+func (c *Cache) OnInvalidate(fn func(key string)) {
+	if fn == nil {
+		return
+	}
+	c.invalidateCallbacks.add(fn)
+}
+
+// Invalidate drops the cached entry for the key, any spilled copy of it and
+// any cached listing it may appear in, so that the next read goes to the
+// backend. It is meant to be called when another node reports a write to the
+// key, and so does not call the OnInvalidate callbacks. A write to the key
+// buffered by this node in write-back mode is kept.
+func (c *Cache) Invalidate(key string) {
+	defer c.measureSince([]string{"cache", "invalidate"}, time.Now())
+	defer c.invalidateListings(key)
+
+	ckey := c.cacheKey(key)
+	lock := locksutil.LockForKey(c.locks, ckey)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.uncache(ckey)
+	c.invalidateSpill(ckey)
+}
+
+// notifyInvalidate calls the OnInvalidate callbacks for the keys. It must be
+// called without holding any lock.
+func (c *Cache) notifyInvalidate(keys ...string) {
+	fns := c.invalidateCallbacks.load()
+	if len(fns) == 0 {
+		return
+	}
+	for _, key := range keys {
+		for _, fn := range fns {
+			fn(key)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	radix "github.com/armon/go-radix"
+	"golang.org/x/sync/singleflight"
+)
+
+// listingCache holds the results of List and ListPage calls, indexed by the
+// listed prefix so that a write can find every listing it may change: those
+// whose prefix is a prefix of the written key.
+type listingCache struct {
+	maxPages int
+
+	lock  sync.Mutex
+	tree  *radix.Tree
+	pages int
+}
+
+// listingNode holds the cached pages of a single prefix. A node is replaced
+// rather than emptied when invalidated, so that a read which started before
+// the invalidation can detect it by the node no longer being in the tree.
+// Nodes without pages are only kept while a read through is in flight, so
+// that listing many distinct prefixes cannot grow the tree beyond the pages
+// it holds.
+type listingNode struct {
+	pages map[listingPage][]string
+}
+
+// listingPage identifies a page of a listing; a limit of -1 denotes a List.
+type listingPage struct {
+	after string
+	limit int
+}
+
+func newListingCache(maxPages int) *listingCache {
+	return &listingCache{
+		maxPages: maxPages,
+		tree:     radix.New(),
+	}
+}
+
+// get returns a copy of the cached page, if any. Otherwise it returns the
+// node to later pass to store the page read from the backend.
+func (lc *listingCache) get(prefix string, page listingPage) ([]string, *listingNode, bool) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	var node *listingNode
+	if v, ok := lc.tree.Get(prefix); ok {
+		node = v.(*listingNode)
+	} else {
+		node = &listingNode{pages: make(map[listingPage][]string)}
+		lc.tree.Insert(prefix, node)
+	}
+
+	keys, ok := node.pages[page]
+	if !ok {
+		return nil, node, false
+	}
+	return append([]string(nil), keys...), nil, true
+}
+
+// store caches a page read from the backend, unless a write under the prefix
+// invalidated node since it was returned by get. Once the cache is full, it
+// is emptied rather than tracking the recency of pages.
+func (lc *listingCache) store(prefix string, page listingPage, node *listingNode, keys []string) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	if v, ok := lc.tree.Get(prefix); !ok || v.(*listingNode) != node {
+		return
+	}
+	if _, ok := node.pages[page]; ok {
+		return
+	}
+	if lc.pages >= lc.maxPages {
+		lc.tree = radix.New()
+		lc.pages = 0
+		return
+	}
+
+	node.pages[page] = append([]string(nil), keys...)
+	lc.pages++
+}
+
+// abandon drops the node returned by get after the read through failed,
+// unless a page was stored in it since.
+func (lc *listingCache) abandon(prefix string, node *listingNode) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	if v, ok := lc.tree.Get(prefix); ok && v.(*listingNode) == node && len(node.pages) == 0 {
+		lc.tree.Delete(prefix)
+	}
+}
+
+// stats returns the number of cached pages and of prefixes holding them.
+func (lc *listingCache) stats() (pages int, prefixes int) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	return lc.pages, lc.tree.Len()
+}
+
+// invalidate drops every cached listing which a write to the key may have
+// changed.
+func (lc *listingCache) invalidate(key string) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	var prefixes []string
+	lc.tree.WalkPath(key, func(prefix string, v interface{}) bool {
+		prefixes = append(prefixes, prefix)
+		lc.pages -= len(v.(*listingNode).pages)
+		return false
+	})
+	for _, prefix := range prefixes {
+		lc.tree.Delete(prefix)
+	}
+}
+
+// invalidatePrefix drops every cached listing which deleting all keys under
+// the prefix may have changed: those of its parents, as with invalidate, and
+// those of every prefix under it.
+func (lc *listingCache) invalidatePrefix(prefix string) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	var prefixes []string
+	collect := func(p string, v interface{}) bool {
+		prefixes = append(prefixes, p)
+		lc.pages -= len(v.(*listingNode).pages)
+		return false
+	}
+	lc.tree.WalkPath(prefix, collect)
+	lc.tree.WalkPrefix(prefix, func(p string, v interface{}) bool {
+		// The prefix itself was already visited by WalkPath
+		if p == prefix {
+			return false
+		}
+		return collect(p, v)
+	})
+	for _, p := range prefixes {
+		lc.tree.Delete(p)
+	}
+}
+
+// purge drops every cached listing.
+func (lc *listingCache) purge() {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	lc.tree = radix.New()
+	lc.pages = 0
+}
+
+// listingFlights collapses concurrent identical List and ListPage calls into
+// a single backend call. Unlike the listing cache, nothing is kept once the
+// call completes. A write under a listed prefix detaches the calls in flight
+// for it, so that a listing which starts after the write has completed never
+// shares a call which started before.
+type listingFlights struct {
+	group singleflight.Group
+
+	lock     sync.Mutex
+	inflight map[string]*listingFlight
+}
+
+// listingFlight is a backend call in flight for a prefix.
+type listingFlight struct {
+	prefix string
+}
+
+func newListingFlights() *listingFlights {
+	return &listingFlights{
+		inflight: make(map[string]*listingFlight),
+	}
+}
+
+// do calls fetch for the page of the prefix, or waits for the identical call
+// already in flight, returning a copy of its result that the caller may
+// modify.
+func (lf *listingFlights) do(prefix string, page listingPage, fetch func() ([]string, error)) ([]string, error) {
+	// Lengths keep keys unambiguous whatever the prefix contains
+	key := fmt.Sprintf("%d/%d/%s%s", page.limit, len(prefix), prefix, page.after)
+	raw, err, _ := lf.group.Do(key, func() (interface{}, error) {
+		flight := &listingFlight{prefix: prefix}
+		lf.lock.Lock()
+		lf.inflight[key] = flight
+		lf.lock.Unlock()
+
+		defer func() {
+			lf.lock.Lock()
+			if lf.inflight[key] == flight {
+				delete(lf.inflight, key)
+			}
+			lf.lock.Unlock()
+		}()
+
+		return fetch()
+	})
+
+	keys, _ := raw.([]string)
+	if keys == nil {
+		return nil, err
+	}
+	return append(make([]string, 0, len(keys)), keys...), err
+}
+
+// invalidate detaches every call in flight for a prefix matching match, so
+// that later identical calls go to the backend again.
+func (lf *listingFlights) invalidate(match func(prefix string) bool) {
+	lf.lock.Lock()
+	defer lf.lock.Unlock()
+
+	for key, flight := range lf.inflight {
+		if match(flight.prefix) {
+			lf.group.Forget(key)
+			delete(lf.inflight, key)
+		}
+	}
+}
+
+// list serves a List or ListPage call from the listing cache if enabled,
+// calling fetch to read through on a miss. Concurrent identical calls which
+// read through share a single call to fetch, except for consistent reads,
+// which must not be served by a call begun before them. A shared call is
+// given a context detached from that of the caller starting it, so that
+// the others are not failed when it gives up.
+func (c *Cache) list(ctx context.Context, prefix string, page listingPage, fetch func(context.Context) ([]string, error)) ([]string, error) {
+	if cacheConsistentReadFromContext(ctx) {
+		return fetch(ctx)
+	}
+	readThrough := func() ([]string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		flightCtx := context.WithoutCancel(ctx)
+		return c.listFlights.do(prefix, page, func() ([]string, error) {
+			return fetch(flightCtx)
+		})
+	}
+	if c.listings == nil || !c.ShouldCache(prefix) || cacheReconcileListFromContext(ctx) {
+		return readThrough()
+	}
+
+	var node *listingNode
+	if !cacheRefreshFromContext(ctx) {
+		var keys []string
+		var ok bool
+		if keys, node, ok = c.listings.get(prefix, page); ok {
+			c.metricSink.IncrCounter([]string{"cache", "list", "hit"}, 1)
+			return keys, nil
+		}
+	}
+
+	keys, err := readThrough()
+	if node == nil {
+		return keys, err
+	}
+	if err != nil {
+		c.listings.abandon(prefix, node)
+		return nil, err
+	}
+	c.listings.store(prefix, page, node, keys)
+	return keys, nil
+}
+
+// invalidateListings drops cached and in-flight listings which a write to
+// the key may have changed. It must be called once the write has reached the
+// backend.
+func (c *Cache) invalidateListings(key string) {
+	c.listFlights.invalidate(func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	if c.listings != nil {
+		c.listings.invalidate(key)
+	}
+}
+
+// invalidateListingsPrefix drops cached and in-flight listings which
+// deleting every key under the prefix may have changed.
+func (c *Cache) invalidateListingsPrefix(prefix string) {
+	c.listFlights.invalidate(func(p string) bool {
+		return strings.HasPrefix(prefix, p) || strings.HasPrefix(p, prefix)
+	})
+	if c.listings != nil {
+		c.listings.invalidatePrefix(prefix)
+	}
+}
@@ -0,0 +1,402 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"hash/maphash"
+	"runtime"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// twoQueueCache is a thread-safe fixed size 2Q cache implementing the same
+// algorithm as lru.TwoQueueCache. Unlike the latter, it reports the entry
+// evicted by Add and keeps a running total of the size of its values, as
+// computed by sizeOf, which is adjusted on every insertion and removal.
+type twoQueueCache struct {
+	size       int
+	recentSize int
+	capacity   int
+	sizeOf     func(value interface{}) int
+
+	lock        sync.Mutex
+	recent      *simplelru.LRU
+	frequent    *simplelru.LRU
+	recentEvict *simplelru.LRU
+	bytes       int64
+}
+
+func newTwoQueueCache(size int, sizeOf func(value interface{}) int) *twoQueueCache {
+	if size <= 0 {
+		size = 1
+	}
+	evictSize := int(float64(size) * lru.Default2QGhostEntries)
+	if evictSize <= 0 {
+		evictSize = 1
+	}
+
+	// These only fail for non-positive sizes
+	recent, _ := simplelru.NewLRU(size, nil)
+	frequent, _ := simplelru.NewLRU(size, nil)
+	recentEvict, _ := simplelru.NewLRU(evictSize, nil)
+
+	return &twoQueueCache{
+		size:        size,
+		recentSize:  int(float64(size) * lru.Default2QRecentRatio),
+		capacity:    size,
+		sizeOf:      sizeOf,
+		recent:      recent,
+		frequent:    frequent,
+		recentEvict: recentEvict,
+	}
+}
+
+// Get looks up a key's value, promoting it to the frequently used queue.
+func (c *twoQueueCache) Get(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.frequent.Get(key); ok {
+		return val, ok
+	}
+
+	// If the value is contained in recent, then we promote it to frequent
+	if val, ok := c.recent.Peek(key); ok {
+		c.recent.Remove(key)
+		c.frequent.Add(key, val)
+		return val, ok
+	}
+
+	return nil, false
+}
+
+// Add adds a value to the cache. If this evicts another entry, it is
+// returned.
+func (c *twoQueueCache) Add(key, value interface{}) (evictedKey, evictedValue interface{}, evicted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.bytes += int64(c.sizeOf(value))
+
+	// Check if the value is frequently used already, and just update the
+	// value
+	if old, ok := c.frequent.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(old))
+		c.frequent.Add(key, value)
+		return nil, nil, false
+	}
+
+	// Check if the value is recently used, and promote the value into the
+	// frequent list
+	if old, ok := c.recent.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(old))
+		c.recent.Remove(key)
+		c.frequent.Add(key, value)
+		return nil, nil, false
+	}
+
+	// If the value was recently evicted, add it to the frequently used list
+	if c.recentEvict.Contains(key) {
+		evictedKey, evictedValue, evicted = c.ensureSpace(true)
+		c.recentEvict.Remove(key)
+		c.frequent.Add(key, value)
+		return evictedKey, evictedValue, evicted
+	}
+
+	// Add to the recently seen list
+	evictedKey, evictedValue, evicted = c.ensureSpace(false)
+	c.recent.Add(key, value)
+	return evictedKey, evictedValue, evicted
+}
+
+// ensureSpace evicts an entry if the cache is full, returning it.
+func (c *twoQueueCache) ensureSpace(recentEvict bool) (interface{}, interface{}, bool) {
+	// If we have space, nothing to do
+	recentLen := c.recent.Len()
+	freqLen := c.frequent.Len()
+	if recentLen+freqLen < c.size {
+		return nil, nil, false
+	}
+
+	// If the recent buffer is larger than the target, evict from there
+	if recentLen > 0 && (recentLen > c.recentSize || (recentLen == c.recentSize && !recentEvict)) {
+		k, v, _ := c.recent.RemoveOldest()
+		c.recentEvict.Add(k, nil)
+		c.bytes -= int64(c.sizeOf(v))
+		return k, v, true
+	}
+
+	// Remove from the frequent list otherwise
+	k, v, ok := c.frequent.RemoveOldest()
+	if ok {
+		c.bytes -= int64(c.sizeOf(v))
+	}
+	return k, v, ok
+}
+
+// resizeBatch bounds the number of entries Resize evicts while holding the
+// lock, so that shrinking a large cache does not stall other callers.
+const resizeBatch = 1024
+
+// evictedPair is an entry evicted by Resize.
+type evictedPair struct {
+	key   interface{}
+	value interface{}
+}
+
+// Resize changes the capacity of the cache, returning the entries evicted to
+// fit within it.
+func (c *twoQueueCache) Resize(size int) []evictedPair {
+	if size <= 0 {
+		size = 1
+	}
+	evictSize := int(float64(size) * lru.Default2QGhostEntries)
+	if evictSize <= 0 {
+		evictSize = 1
+	}
+
+	c.lock.Lock()
+	c.size = size
+	c.recentSize = int(float64(size) * lru.Default2QRecentRatio)
+	c.recentEvict.Resize(evictSize)
+	// The queues are never shrunk, as they would evict without accounting
+	if size > c.capacity {
+		c.recent.Resize(size)
+		c.frequent.Resize(size)
+		c.capacity = size
+	}
+	c.lock.Unlock()
+
+	var evicted []evictedPair
+	for {
+		done := false
+		c.lock.Lock()
+		for i := 0; i < resizeBatch && c.recent.Len()+c.frequent.Len() > c.size; i++ {
+			k, v, ok := c.ensureSpace(false)
+			if !ok {
+				done = true
+				break
+			}
+			evicted = append(evicted, evictedPair{key: k, value: v})
+		}
+		done = done || c.recent.Len()+c.frequent.Len() <= c.size
+		c.lock.Unlock()
+
+		if done {
+			return evicted
+		}
+	}
+}
+
+// Peek returns a key's value without updating its recency.
+func (c *twoQueueCache) Peek(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.frequent.Peek(key); ok {
+		return val, ok
+	}
+	return c.recent.Peek(key)
+}
+
+// Contains checks whether the key is cached without updating its recency.
+func (c *twoQueueCache) Contains(key interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.frequent.Contains(key) || c.recent.Contains(key)
+}
+
+// Remove removes the key from the cache.
+func (c *twoQueueCache) Remove(key interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.frequent.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(val))
+		c.frequent.Remove(key)
+		return
+	}
+	if val, ok := c.recent.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(val))
+		c.recent.Remove(key)
+		return
+	}
+	c.recentEvict.Remove(key)
+}
+
+// Purge removes every entry from the cache.
+func (c *twoQueueCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.recent.Purge()
+	c.frequent.Purge()
+	c.recentEvict.Purge()
+	c.bytes = 0
+}
+
+// Len returns the number of cached entries.
+func (c *twoQueueCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.recent.Len() + c.frequent.Len()
+}
+
+// Keys returns the cached keys, frequently used ones first, each queue
+// ordered from oldest to newest.
+func (c *twoQueueCache) Keys() []interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append(c.frequent.Keys(), c.recent.Keys()...)
+}
+
+// Bytes returns the total size of the cached values.
+func (c *twoQueueCache) Bytes() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.bytes
+}
+
+// minCacheShardSize bounds the number of shards chosen by default, so that
+// small caches keep the eviction order of a single unsharded cache.
+const minCacheShardSize = 1024
+
+// defaultCacheShards returns the number of shards used for a cache of the
+// given size when none is configured: enough to spread GOMAXPROCS
+// goroutines over distinct shards, as long as each holds at least
+// minCacheShardSize entries.
+func defaultCacheShards(size int) int {
+	shards := 1
+	for shards < 4*runtime.GOMAXPROCS(0) {
+		shards *= 2
+	}
+	for shards > 1 && size/shards < minCacheShardSize {
+		shards /= 2
+	}
+	return shards
+}
+
+// shardedCache spreads keys by hash over independent caches implementing
+// the same eviction policy, so that operations on unrelated keys do not
+// contend on a single mutex. Each shard evicts independently, so eviction
+// order only approximates that of a single cache of the same total size.
+type shardedCache struct {
+	seed   maphash.Seed
+	shards []evictionCache
+}
+
+// newShardedCache splits size entries over the given number of shards; zero
+// selects the default for the size.
+func newShardedCache(size, shards int, policy EvictionPolicy, sizeOf func(value interface{}) int) *shardedCache {
+	if shards <= 0 {
+		shards = defaultCacheShards(size)
+	}
+	if shards > size {
+		shards = size
+	}
+	if shards <= 0 {
+		shards = 1
+	}
+
+	c := &shardedCache{
+		seed:   maphash.MakeSeed(),
+		shards: make([]evictionCache, shards),
+	}
+	for i := range c.shards {
+		shardSize := size / shards
+		if i < size%shards {
+			shardSize++
+		}
+		c.shards[i] = newEvictionCache(policy, shardSize, sizeOf)
+	}
+	return c
+}
+
+func (c *shardedCache) shardFor(key interface{}) evictionCache {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	return c.shards[maphash.String(c.seed, key.(string))%uint64(len(c.shards))]
+}
+
+// Resize changes the total capacity of the shards, returning the entries
+// evicted to fit within it.
+func (c *shardedCache) Resize(size int) []evictedPair {
+	shards := len(c.shards)
+	var evicted []evictedPair
+	for i, shard := range c.shards {
+		shardSize := size / shards
+		if i < size%shards {
+			shardSize++
+		}
+		evicted = append(evicted, shard.Resize(shardSize)...)
+	}
+	return evicted
+}
+
+// Get looks up a key's value in its shard.
+func (c *shardedCache) Get(key interface{}) (interface{}, bool) {
+	return c.shardFor(key).Get(key)
+}
+
+// Add adds a value to its shard, returning the entry evicted from the shard
+// to make room, if any.
+func (c *shardedCache) Add(key, value interface{}) (interface{}, interface{}, bool) {
+	return c.shardFor(key).Add(key, value)
+}
+
+// Peek returns a key's value without updating its recency.
+func (c *shardedCache) Peek(key interface{}) (interface{}, bool) {
+	return c.shardFor(key).Peek(key)
+}
+
+// Contains checks whether the key is cached without updating its recency.
+func (c *shardedCache) Contains(key interface{}) bool {
+	return c.shardFor(key).Contains(key)
+}
+
+// Remove removes the key from its shard.
+func (c *shardedCache) Remove(key interface{}) {
+	c.shardFor(key).Remove(key)
+}
+
+// Purge removes every entry from every shard.
+func (c *shardedCache) Purge() {
+	for _, shard := range c.shards {
+		shard.Purge()
+	}
+}
+
+// Len returns the number of cached entries across all shards.
+func (c *shardedCache) Len() int {
+	var n int
+	for _, shard := range c.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Keys returns the cached keys of every shard in turn.
+func (c *shardedCache) Keys() []interface{} {
+	var keys []interface{}
+	for _, shard := range c.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+	return keys
+}
+
+// Bytes returns the total size of the cached values across all shards.
+func (c *shardedCache) Bytes() int64 {
+	var n int64
+	for _, shard := range c.shards {
+		n += shard.Bytes()
+	}
+	return n
+}
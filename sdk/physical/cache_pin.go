@@ -0,0 +1,189 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+// pinnedEntries holds the cached values of pinned keys outside of the LRU, so
+// that they are never evicted. A pinned key maps to its cached value, in the
+// same form as held in the LRU, or to nil until one is cached. Its own lock
+// only protects the map; as with the LRU, callers hold the lock for a key
+// while reading or changing its value.
+type pinnedEntries struct {
+	lock    sync.RWMutex
+	entries map[string]interface{}
+}
+
+func newPinnedEntries() *pinnedEntries {
+	return &pinnedEntries{
+		entries: make(map[string]interface{}),
+	}
+}
+
+// get returns the cached value of the key, which is nil if the key is not
+// pinned or nothing is cached for it yet, and whether the key is pinned.
+func (pe *pinnedEntries) get(key string) (interface{}, bool) {
+	pe.lock.RLock()
+	defer pe.lock.RUnlock()
+
+	raw, ok := pe.entries[key]
+	return raw, ok
+}
+
+// pin pins the key, with the given cached value if it was not already
+// pinned.
+func (pe *pinnedEntries) pin(key string, raw interface{}) {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+
+	if _, ok := pe.entries[key]; !ok {
+		pe.entries[key] = raw
+	}
+}
+
+// unpin unpins the key, returning its cached value.
+func (pe *pinnedEntries) unpin(key string) interface{} {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+
+	raw := pe.entries[key]
+	delete(pe.entries, key)
+	return raw
+}
+
+// set caches the value of the key, returning false without caching it if
+// the key is not pinned.
+func (pe *pinnedEntries) set(key string, raw interface{}) bool {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+
+	if _, ok := pe.entries[key]; !ok {
+		return false
+	}
+	pe.entries[key] = raw
+	return true
+}
+
+// clearMatching drops the cached values of the pinned keys for which match
+// returns true, leaving them pinned, and returns the number dropped.
+func (pe *pinnedEntries) clearMatching(match func(key string, raw interface{}) bool) int {
+	pe.lock.Lock()
+	defer pe.lock.Unlock()
+
+	var n int
+	for key, raw := range pe.entries {
+		if raw != nil && match(key, raw) {
+			pe.entries[key] = nil
+			n++
+		}
+	}
+	return n
+}
+
+// cached returns the pinned keys which have a cached value.
+func (pe *pinnedEntries) cached() []string {
+	pe.lock.RLock()
+	defer pe.lock.RUnlock()
+
+	var keys []string
+	for key, raw := range pe.entries {
+		if raw != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// keys returns every pinned key.
+func (pe *pinnedEntries) keys() []string {
+	pe.lock.RLock()
+	defer pe.lock.RUnlock()
+
+	keys := make([]string, 0, len(pe.entries))
+	for key := range pe.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// stats returns the number of cached values and their total size.
+func (pe *pinnedEntries) stats() (int, int64) {
+	pe.lock.RLock()
+	defer pe.lock.RUnlock()
+
+	var n int
+	var bytes int64
+	for _, raw := range pe.entries {
+		if raw != nil {
+			n++
+			bytes += int64(cachedValueSize(raw))
+		}
+	}
+	return n, bytes
+}
+
+// Pin keeps the key resident in the cache regardless of LRU pressure, for
+// keys such as the mount table whose misses stall every request. The first
+// read or write of the key once pinned caches it, and it is consulted before
+// the LRU from then on. A pinned entry is still replaced by writes, dropped
+// by deletes and purges, and bypassed by reads made with
+// CacheRefreshContext, CacheConsistentReadContext or once it is older than
+// the TTL; the key stays pinned throughout, and is cached again by the next
+// read.
+//
+// Pinned entries are held in addition to Size entries and are exempt from
+// MaxCachedValueBytes and memory pressure, so every pinned value stays in
+// memory, at its full size unless compressed, until it is unpinned. Only a
+// handful of small, hot keys should be pinned.
+func (c *Cache) Pin(key string) {
+	key = c.cacheKey(key)
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Move any cached value out of the LRU so that it is served from the
+	// first read.
+	p := c.partitionFor(key)
+	raw, _ := p.lru.Peek(key)
+	p.lru.Remove(key)
+	c.pins.pin(key, raw)
+}
+
+// Unpin returns the key to the LRU, where its cached entry, if any, can be
+// evicted again. Unpinning a key which is not pinned does nothing.
+func (c *Cache) Unpin(key string) {
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	key = c.cacheKey(key)
+	lock := locksutil.LockForKey(c.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if raw := c.pins.unpin(key); raw != nil && !c.isExpired(raw) {
+		// Keep the time the entry was first cached, so that it does not
+		// outlive the TTL
+		var cachedAt time.Time
+		if entry, ok := raw.(*Entry); ok {
+			cachedAt = entry.cachedAt
+		}
+		c.addCachedAt(key, raw, cachedAt, &ev)
+	}
+}
+
+// PinnedKeys returns the pinned keys, normalized, in no particular order.
+func (c *Cache) PinnedKeys() []string {
+	return c.pins.keys()
+}
+
+// isPinned returns whether the key is pinned.
+func (c *Cache) isPinned(key string) bool {
+	_, ok := c.pins.get(key)
+	return ok
+}
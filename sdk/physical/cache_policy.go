@@ -0,0 +1,483 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// EvictionPolicy selects the algorithm a Cache uses to choose which entries
+// to evict once full; see CacheConfig.EvictionPolicy.
+type EvictionPolicy string
+
+const (
+	// EvictionPolicy2Q evicts using the 2Q algorithm, which keeps entries
+	// read more than once apart from those only read once so that a scan
+	// cannot flush the former. This is the default.
+	EvictionPolicy2Q EvictionPolicy = "2q"
+
+	// EvictionPolicyLRU evicts the least recently used entry.
+	EvictionPolicyLRU EvictionPolicy = "lru"
+
+	// EvictionPolicyARC evicts using the adaptive replacement cache
+	// algorithm, which continually balances between favouring recently and
+	// frequently used entries based on the workload.
+	EvictionPolicyARC EvictionPolicy = "arc"
+)
+
+// evictionCache is a thread-safe fixed size cache implementing one eviction
+// policy. Each reports the entry evicted by Add and keeps a running total of
+// the size of its values, as computed by the sizeOf function it was created
+// with.
+type evictionCache interface {
+	// Get looks up a key's value, updating its recency.
+	Get(key interface{}) (interface{}, bool)
+
+	// Add adds a value to the cache. If this evicts another entry, it is
+	// returned.
+	Add(key, value interface{}) (evictedKey, evictedValue interface{}, evicted bool)
+
+	// Peek returns a key's value without updating its recency.
+	Peek(key interface{}) (interface{}, bool)
+
+	// Contains checks whether the key is cached without updating its
+	// recency.
+	Contains(key interface{}) bool
+
+	// Remove removes the key from the cache.
+	Remove(key interface{})
+
+	// Purge removes every entry from the cache.
+	Purge()
+
+	// Len returns the number of cached entries.
+	Len() int
+
+	// Keys returns the cached keys.
+	Keys() []interface{}
+
+	// Bytes returns the total size of the cached values.
+	Bytes() int64
+
+	// Resize changes the capacity of the cache, returning the entries
+	// evicted to fit within it.
+	Resize(size int) []evictedPair
+}
+
+// Verify the policies satisfy the correct interfaces
+var (
+	_ evictionCache = (*twoQueueCache)(nil)
+	_ evictionCache = (*lruCache)(nil)
+	_ evictionCache = (*arcCache)(nil)
+)
+
+// validateEvictionPolicy returns an error if the policy is not known.
+func validateEvictionPolicy(policy EvictionPolicy) error {
+	switch policy {
+	case "", EvictionPolicy2Q, EvictionPolicyLRU, EvictionPolicyARC:
+		return nil
+	default:
+		return fmt.Errorf("unsupported cache eviction policy %q", policy)
+	}
+}
+
+// newEvictionCache returns a cache of the given size implementing the
+// policy, which must be valid; empty selects 2Q.
+func newEvictionCache(policy EvictionPolicy, size int, sizeOf func(value interface{}) int) evictionCache {
+	if size <= 0 {
+		size = 1
+	}
+
+	switch policy {
+	case EvictionPolicyLRU:
+		return newLRUCache(size, sizeOf)
+	case EvictionPolicyARC:
+		return newARCCache(size, sizeOf)
+	default:
+		return newTwoQueueCache(size, sizeOf)
+	}
+}
+
+// lruCache is a thread-safe fixed size LRU cache.
+type lruCache struct {
+	size   int
+	sizeOf func(value interface{}) int
+
+	lock  sync.Mutex
+	lru   *simplelru.LRU
+	bytes int64
+}
+
+func newLRUCache(size int, sizeOf func(value interface{}) int) *lruCache {
+	// This only fails for non-positive sizes. The list is never shrunk, as
+	// it would evict without accounting, so it is at least size.
+	l, _ := simplelru.NewLRU(size, nil)
+
+	return &lruCache{
+		size:   size,
+		sizeOf: sizeOf,
+		lru:    l,
+	}
+}
+
+func (c *lruCache) Get(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Get(key)
+}
+
+func (c *lruCache) Add(key, value interface{}) (evictedKey, evictedValue interface{}, evicted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.bytes += int64(c.sizeOf(value))
+	if old, ok := c.lru.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(old))
+		c.lru.Add(key, value)
+		return nil, nil, false
+	}
+
+	if c.lru.Len() >= c.size {
+		evictedKey, evictedValue, evicted = c.removeOldest()
+	}
+	c.lru.Add(key, value)
+	return evictedKey, evictedValue, evicted
+}
+
+func (c *lruCache) removeOldest() (interface{}, interface{}, bool) {
+	k, v, ok := c.lru.RemoveOldest()
+	if ok {
+		c.bytes -= int64(c.sizeOf(v))
+	}
+	return k, v, ok
+}
+
+func (c *lruCache) Peek(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Peek(key)
+}
+
+func (c *lruCache) Contains(key interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Contains(key)
+}
+
+func (c *lruCache) Remove(key interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.lru.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(val))
+		c.lru.Remove(key)
+	}
+}
+
+func (c *lruCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lru.Purge()
+	c.bytes = 0
+}
+
+func (c *lruCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Len()
+}
+
+// Keys returns the cached keys, ordered from oldest to newest.
+func (c *lruCache) Keys() []interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.lru.Keys()
+}
+
+func (c *lruCache) Bytes() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.bytes
+}
+
+func (c *lruCache) Resize(size int) []evictedPair {
+	if size <= 0 {
+		size = 1
+	}
+
+	c.lock.Lock()
+	c.size = size
+	c.lru.Resize(max(size, c.lru.Len()))
+	c.lock.Unlock()
+
+	var evicted []evictedPair
+	for {
+		c.lock.Lock()
+		for i := 0; i < resizeBatch && c.lru.Len() > c.size; i++ {
+			k, v, _ := c.removeOldest()
+			evicted = append(evicted, evictedPair{key: k, value: v})
+		}
+		done := c.lru.Len() <= c.size
+		c.lock.Unlock()
+
+		if done {
+			return evicted
+		}
+	}
+}
+
+// arcCache is a thread-safe fixed size ARC cache implementing the same
+// algorithm as lru.ARCCache, with the additions of twoQueueCache.
+type arcCache struct {
+	size     int
+	capacity int
+	sizeOf   func(value interface{}) int
+
+	lock sync.Mutex
+	// p is the target size of t1, adapted as ghost entries are hit
+	p int
+	// t1 holds entries seen once recently, and t2 those seen more than once
+	t1 *simplelru.LRU
+	t2 *simplelru.LRU
+	// b1 and b2 are ghost lists of keys recently evicted from t1 and t2
+	b1    *simplelru.LRU
+	b2    *simplelru.LRU
+	bytes int64
+}
+
+func newARCCache(size int, sizeOf func(value interface{}) int) *arcCache {
+	// These only fail for non-positive sizes
+	t1, _ := simplelru.NewLRU(size, nil)
+	t2, _ := simplelru.NewLRU(size, nil)
+	b1, _ := simplelru.NewLRU(size, nil)
+	b2, _ := simplelru.NewLRU(size, nil)
+
+	return &arcCache{
+		size:     size,
+		capacity: size,
+		sizeOf:   sizeOf,
+		t1:       t1,
+		t2:       t2,
+		b1:       b1,
+		b2:       b2,
+	}
+}
+
+// Get looks up a key's value, promoting it to the frequently used list.
+func (c *arcCache) Get(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.t1.Peek(key); ok {
+		c.t1.Remove(key)
+		c.t2.Add(key, val)
+		return val, ok
+	}
+	return c.t2.Get(key)
+}
+
+func (c *arcCache) Add(key, value interface{}) (evictedKey, evictedValue interface{}, evicted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.bytes += int64(c.sizeOf(value))
+
+	// Check if the value is cached, and promote it to the frequently used
+	// list
+	if old, ok := c.t1.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(old))
+		c.t1.Remove(key)
+		c.t2.Add(key, value)
+		return nil, nil, false
+	}
+	if old, ok := c.t2.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(old))
+		c.t2.Add(key, value)
+		return nil, nil, false
+	}
+
+	// If the key was recently evicted from the recently used list, that
+	// list is too small, so grow its target
+	if c.b1.Contains(key) {
+		delta := 1
+		if b1Len, b2Len := c.b1.Len(), c.b2.Len(); b2Len > b1Len {
+			delta = b2Len / b1Len
+		}
+		c.p = min(c.p+delta, c.size)
+
+		if c.t1.Len()+c.t2.Len() >= c.size {
+			evictedKey, evictedValue, evicted = c.replace(false)
+		}
+		c.b1.Remove(key)
+		c.t2.Add(key, value)
+		return evictedKey, evictedValue, evicted
+	}
+
+	// If it was recently evicted from the frequently used list, that list
+	// is too small, so shrink the target of the other
+	if c.b2.Contains(key) {
+		delta := 1
+		if b1Len, b2Len := c.b1.Len(), c.b2.Len(); b1Len > b2Len {
+			delta = b1Len / b2Len
+		}
+		c.p = max(c.p-delta, 0)
+
+		if c.t1.Len()+c.t2.Len() >= c.size {
+			evictedKey, evictedValue, evicted = c.replace(true)
+		}
+		c.b2.Remove(key)
+		c.t2.Add(key, value)
+		return evictedKey, evictedValue, evicted
+	}
+
+	if c.t1.Len()+c.t2.Len() >= c.size {
+		evictedKey, evictedValue, evicted = c.replace(false)
+	}
+
+	// Keep the size of the ghost lists trim
+	if c.b1.Len() > c.size-c.p {
+		c.b1.RemoveOldest()
+	}
+	if c.b2.Len() > c.p {
+		c.b2.RemoveOldest()
+	}
+
+	c.t1.Add(key, value)
+	return evictedKey, evictedValue, evicted
+}
+
+// replace evicts an entry from either list based on the target size of t1,
+// returning it.
+func (c *arcCache) replace(b2ContainsKey bool) (interface{}, interface{}, bool) {
+	var k, v interface{}
+	var ok bool
+	if t1Len := c.t1.Len(); t1Len > 0 && (t1Len > c.p || (t1Len == c.p && b2ContainsKey)) {
+		if k, v, ok = c.t1.RemoveOldest(); ok {
+			c.b1.Add(k, nil)
+		}
+	} else {
+		if k, v, ok = c.t2.RemoveOldest(); ok {
+			c.b2.Add(k, nil)
+		}
+	}
+	if ok {
+		c.bytes -= int64(c.sizeOf(v))
+	}
+	return k, v, ok
+}
+
+func (c *arcCache) Peek(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.t1.Peek(key); ok {
+		return val, ok
+	}
+	return c.t2.Peek(key)
+}
+
+func (c *arcCache) Contains(key interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.t1.Contains(key) || c.t2.Contains(key)
+}
+
+func (c *arcCache) Remove(key interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if val, ok := c.t1.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(val))
+		c.t1.Remove(key)
+		return
+	}
+	if val, ok := c.t2.Peek(key); ok {
+		c.bytes -= int64(c.sizeOf(val))
+		c.t2.Remove(key)
+		return
+	}
+	if c.b1.Remove(key) {
+		return
+	}
+	c.b2.Remove(key)
+}
+
+func (c *arcCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.t1.Purge()
+	c.t2.Purge()
+	c.b1.Purge()
+	c.b2.Purge()
+	c.bytes = 0
+}
+
+func (c *arcCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.t1.Len() + c.t2.Len()
+}
+
+// Keys returns the cached keys, recently used ones first, each list ordered
+// from oldest to newest.
+func (c *arcCache) Keys() []interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append(c.t1.Keys(), c.t2.Keys()...)
+}
+
+func (c *arcCache) Bytes() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.bytes
+}
+
+func (c *arcCache) Resize(size int) []evictedPair {
+	if size <= 0 {
+		size = 1
+	}
+
+	c.lock.Lock()
+	c.size = size
+	c.p = min(c.p, size)
+	// The lists are never shrunk, as they would evict without accounting
+	if size > c.capacity {
+		c.t1.Resize(size)
+		c.t2.Resize(size)
+		c.b1.Resize(size)
+		c.b2.Resize(size)
+		c.capacity = size
+	}
+	c.lock.Unlock()
+
+	var evicted []evictedPair
+	for {
+		c.lock.Lock()
+		for i := 0; i < resizeBatch && c.t1.Len()+c.t2.Len() > c.size; i++ {
+			k, v, _ := c.replace(false)
+			evicted = append(evicted, evictedPair{key: k, value: v})
+		}
+		done := c.t1.Len()+c.t2.Len() <= c.size
+		c.lock.Unlock()
+
+		if done {
+			return evicted
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"runtime"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// DefaultMemoryPressureInterval is used if no interval is specified in
+// MemoryPressureConfig
+const DefaultMemoryPressureInterval = 10 * time.Second
+
+// MemoryPressureConfig configures adaptive sizing of a cache under memory
+// pressure; see CacheConfig.MemoryPressure.
+type MemoryPressureConfig struct {
+	// HighWaterBytes is the memory usage above which the effective size of
+	// the cache is halved on every check, down to MinEntries.
+	HighWaterBytes uint64
+
+	// LowWaterBytes is the memory usage below which the effective size of
+	// the cache is doubled on every check, back up to its configured size.
+	// If zero, 80% of HighWaterBytes is used.
+	LowWaterBytes uint64
+
+	// MinEntries is the floor for the effective size of the cache. If zero,
+	// a sixteenth of the configured size is used.
+	MinEntries int
+
+	// Interval is how often memory usage is checked.
+	Interval time.Duration
+
+	// MemoryUsage reports the current memory usage in bytes. If nil, the
+	// heap in use reported by runtime.ReadMemStats is used.
+	MemoryUsage func() uint64
+}
+
+// heapInUse reports the bytes in in-use heap spans.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+// startMemoryMonitor starts adjusting the effective size of the cache to
+// memory usage. Stop stops it.
+func (c *Cache) startMemoryMonitor(config MemoryPressureConfig) {
+	if config.LowWaterBytes == 0 {
+		config.LowWaterBytes = config.HighWaterBytes / 10 * 8
+	}
+	if config.MinEntries <= 0 {
+		config.MinEntries = c.size / 16
+	}
+	if config.MinEntries <= 0 {
+		config.MinEntries = 1
+	}
+	if config.MinEntries > c.size {
+		config.MinEntries = c.size
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultMemoryPressureInterval
+	}
+	if config.MemoryUsage == nil {
+		config.MemoryUsage = heapInUse
+	}
+
+	c.memoryStopCh = make(chan struct{})
+	c.memoryDoneCh = make(chan struct{})
+	go c.runMemoryMonitor(config, c.memoryStopCh, c.memoryDoneCh)
+}
+
+func (c *Cache) runMemoryMonitor(config MemoryPressureConfig, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	effective := c.size
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		next := effective
+		usage := config.MemoryUsage()
+		switch {
+		case usage > config.HighWaterBytes:
+			next = effective / 2
+			if next < config.MinEntries {
+				next = config.MinEntries
+			}
+		case usage < config.LowWaterBytes:
+			next = effective * 2
+			if next > c.size {
+				next = c.size
+			}
+		}
+
+		changed := next != effective
+		if changed {
+			c.logger.Debug("resizing cache under memory pressure", "memory_usage", usage, "from", effective, "to", next)
+			c.metricSink.SetGauge([]string{"cache", "resize"}, float32(next))
+			effective = next
+			c.effectiveSize.Store(int64(effective))
+		}
+
+		// Partitions may have been rebuilt at their full size since the last
+		// check, so keep applying the effective size while shrunk.
+		if changed || effective != c.size {
+			c.resize(effective)
+		}
+	}
+}
+
+// resize scales every partition to the given share of the configured size
+// of the cache. Partitions are resized one shard at a time without holding
+// the cache's locks, so that shrinking does not stall other operations.
+func (c *Cache) resize(effective int) {
+	var partitions []*cachePartition
+	c.locks[0].RLock()
+	c.forEachPartition(func(p *cachePartition) {
+		partitions = append(partitions, p)
+	})
+	c.locks[0].RUnlock()
+
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	for _, p := range partitions {
+		target := int(int64(p.size) * int64(effective) / int64(c.size))
+		evicted := p.lru.Resize(target)
+		if len(evicted) == 0 {
+			continue
+		}
+
+		c.metricSink.IncrCounterWithLabels([]string{"cache", "evict"}, float32(len(evicted)), []metrics.Label{{Name: "prefix", Value: p.prefix}})
+		// The spill tier can only be used while holding a lock
+		c.locks[0].RLock()
+		for _, pair := range evicted {
+			c.queueSpill(pair.key.(string), pair.value)
+			ev.record(c, pair.key.(string), pair.value)
+		}
+		c.locks[0].RUnlock()
+	}
+}
+
+// stopMemoryMonitor stops adjusting the effective size of the cache, if
+// started, and restores it to its configured size.
+func (c *Cache) stopMemoryMonitor() {
+	if c.memoryStopCh == nil {
+		return
+	}
+
+	c.memoryStopOnce.Do(func() {
+		close(c.memoryStopCh)
+	})
+	<-c.memoryDoneCh
+
+	if c.effectiveSize.Swap(int64(c.size)) != int64(c.size) {
+		c.resize(c.size)
+	}
+}
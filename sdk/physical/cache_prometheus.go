@@ -0,0 +1,82 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"errors"
+	"strings"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cachePrometheusMetrics holds the native Prometheus collectors of a cache
+// created with CacheConfig.PrometheusRegisterer.
+type cachePrometheusMetrics struct {
+	hits   *prometheus.CounterVec
+	misses *prometheus.CounterVec
+}
+
+// newCachePrometheusMetrics registers the cache's collectors with reg. If
+// another cache has already registered counters of the same name, they are
+// shared with it; any other registration failure is logged and leaves the
+// affected collector unregistered.
+func newCachePrometheusMetrics(reg prometheus.Registerer, c *Cache, logger log.Logger) *cachePrometheusMetrics {
+	m := &cachePrometheusMetrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Number of storage reads served by the physical cache.",
+		}, []string{"mount"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Number of storage reads which missed the physical cache.",
+		}, []string{"mount"}),
+	}
+	m.hits = registerCounterVec(reg, m.hits, logger)
+	m.misses = registerCounterVec(reg, m.misses, logger)
+
+	size := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "cache_size",
+		Help: "Number of entries held by the physical cache.",
+	}, func() float64 {
+		return float64(c.Stats().Entries)
+	})
+	if err := reg.Register(size); err != nil {
+		logger.Warn("failed to register cache size metric", "error", err)
+	}
+
+	return m
+}
+
+func registerCounterVec(reg prometheus.Registerer, counter *prometheus.CounterVec, logger log.Logger) *prometheus.CounterVec {
+	err := reg.Register(counter)
+	if err == nil {
+		return counter
+	}
+
+	var are prometheus.AlreadyRegisteredError
+	if errors.As(err, &are) {
+		if existing, ok := are.ExistingCollector.(*prometheus.CounterVec); ok {
+			return existing
+		}
+	}
+	logger.Warn("failed to register cache metric", "error", err)
+	return counter
+}
+
+// mountLabel returns the Prometheus mount label of a storage key: its first
+// path segment, extended by the mount's UUID for keys under the logical/ and
+// auth/ prefixes so that each secret engine and auth method is reported
+// separately.
+func mountLabel(key string) string {
+	first, rest, found := strings.Cut(key, "/")
+	if !found {
+		return first
+	}
+	if first != "logical" && first != "auth" {
+		return first
+	}
+	uuid, _, _ := strings.Cut(rest, "/")
+	return first + "/" + uuid
+}
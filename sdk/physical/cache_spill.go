@@ -0,0 +1,505 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	bolt "go.etcd.io/bbolt"
+	uberAtomic "go.uber.org/atomic"
+)
+
+const (
+	// DefaultSpillMaxEntries is used if no maximum is specified in
+	// SpillConfig
+	DefaultSpillMaxEntries = 1024 * 1024
+
+	// spillQueueSize bounds the number of evictions waiting to be spilled;
+	// further evictions are dropped rather than blocking the cache.
+	spillQueueSize = 1024
+)
+
+var (
+	spillEntriesBucket = []byte("entries")
+	spillOrderBucket   = []byte("order")
+)
+
+// SpillConfig configures the on-disk tier of a cache created with
+// NewCacheWithSpill.
+type SpillConfig struct {
+	// Path is the BoltDB file holding spilled entries. Any existing contents
+	// are discarded when the cache is created.
+	Path string
+
+	// MaxEntries bounds the number of spilled entries; the oldest spilled
+	// entries are dropped beyond it.
+	MaxEntries int
+}
+
+// NewCacheWithSpill returns a physical cache of the given size which, rather
+// than discarding entries evicted from memory, spills them to a local BoltDB
+// file. Get checks memory, then the spill file, then the backend.
+//
+// Spilled values are encrypted with a key generated when the cache is
+// created and held only in memory, so the file is unreadable once the
+// process exits. Stop closes the file and disables the spill tier.
+func NewCacheWithSpill(b Backend, size int, config SpillConfig, logger log.Logger, metricSink metrics.MetricSink) (*Cache, error) {
+	spill, err := newCacheSpill(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCache(b, size, logger, metricSink)
+	c.spill = spill
+	c.spillGenerations = make([]*uberAtomic.Uint64, len(c.locks))
+	for i := range c.spillGenerations {
+		c.spillGenerations[i] = uberAtomic.NewUint64(0)
+	}
+	go c.runSpillWorker(spill)
+
+	return c, nil
+}
+
+// cacheSpill is an encrypted, size-bounded store of evicted entries. Entries
+// are keyed by storage key, with a second bucket ordering them by insertion
+// so the oldest can be dropped.
+type cacheSpill struct {
+	db         *bolt.DB
+	aead       cipher.AEAD
+	maxEntries int
+	count      int
+
+	queue  chan spilledEviction
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// spilledEviction is an entry evicted from memory and waiting to be
+// spilled, along with the key it was cached under and the invalidation
+// generation of that key at the time.
+type spilledEviction struct {
+	key        string
+	entry      *Entry
+	generation uint64
+}
+
+// spillRecord is the plaintext form of a spilled entry.
+type spillRecord struct {
+	Value     []byte `json:"value"`
+	SealWrap  bool   `json:"seal_wrap"`
+	ValueHash []byte `json:"value_hash"`
+	Version   uint64 `json:"version,omitempty"`
+
+	// CachedAt is only set if the cache has a TTL
+	CachedAt time.Time `json:"cached_at"`
+
+	// Compressed is set if Value was compressed by the cache
+	Compressed bool `json:"compressed,omitempty"`
+}
+
+func newCacheSpill(config SpillConfig) (*cacheSpill, error) {
+	if config.Path == "" {
+		return nil, errors.New("spill path is required")
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultSpillMaxEntries
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate spill key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(config.Path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	// The spill file is only a cache, so durability does not matter
+	db.NoSync = true
+
+	s := &cacheSpill{
+		db:         db,
+		aead:       aead,
+		maxEntries: config.MaxEntries,
+		queue:      make(chan spilledEviction, spillQueueSize),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+	if err := s.clear(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// clear drops every spilled entry.
+func (s *cacheSpill) clear() error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{spillEntriesBucket, spillOrderBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear spill file: %w", err)
+	}
+	s.count = 0
+	return nil
+}
+
+func (s *cacheSpill) put(key string, entry *Entry) error {
+	plaintext, err := json.Marshal(&spillRecord{
+		Value:      entry.Value,
+		SealWrap:   entry.SealWrap,
+		ValueHash:  entry.ValueHash,
+		Version:    entry.Version,
+		CachedAt:   entry.cachedAt,
+		Compressed: entry.compressed,
+	})
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// Binding the key as additional data prevents swapping values between
+	// keys in the file.
+	ciphertext := s.aead.Seal(nonce, nonce, plaintext, []byte(key))
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(spillEntriesBucket)
+		order := tx.Bucket(spillOrderBucket)
+
+		if old := entries.Get([]byte(key)); old != nil {
+			if err := order.Delete(old[:8]); err != nil {
+				return err
+			}
+			s.count--
+		}
+
+		seq, err := order.NextSequence()
+		if err != nil {
+			return err
+		}
+		seqKey := make([]byte, 8)
+		binary.BigEndian.PutUint64(seqKey, seq)
+
+		if err := order.Put(seqKey, []byte(key)); err != nil {
+			return err
+		}
+		if err := entries.Put([]byte(key), append(seqKey, ciphertext...)); err != nil {
+			return err
+		}
+		s.count++
+
+		// Drop the oldest spilled entries beyond the limit
+		cursor := order.Cursor()
+		for s.count > s.maxEntries {
+			oldSeq, oldKey := cursor.First()
+			if oldSeq == nil {
+				break
+			}
+			if err := entries.Delete(oldKey); err != nil {
+				return err
+			}
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			s.count--
+		}
+		return nil
+	})
+}
+
+func (s *cacheSpill) get(key string) (*Entry, error) {
+	var stored []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if raw := tx.Bucket(spillEntriesBucket).Get([]byte(key)); raw != nil {
+			stored = make([]byte, len(raw))
+			copy(stored, raw)
+		}
+		return nil
+	})
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	record, err := s.open(key, stored)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		Key:        key,
+		Value:      record.Value,
+		SealWrap:   record.SealWrap,
+		ValueHash:  record.ValueHash,
+		Version:    record.Version,
+		cachedAt:   record.CachedAt,
+		compressed: record.Compressed,
+	}, nil
+}
+
+// open decrypts the stored form of the spilled entry for the key.
+func (s *cacheSpill) open(key string, stored []byte) (*spillRecord, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(stored) < 8+nonceSize {
+		return nil, errors.New("spilled entry is truncated")
+	}
+	ciphertext := stored[8:]
+	plaintext, err := s.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt spilled entry: %w", err)
+	}
+
+	var record spillRecord
+	if err := json.Unmarshal(plaintext, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// sealWrappedKeys returns the keys of every spilled seal-wrapped entry.
+// Entries which cannot be decrypted are included, as they cannot be served.
+func (s *cacheSpill) sealWrappedKeys() ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(spillEntriesBucket).ForEach(func(k, v []byte) error {
+			if record, err := s.open(string(k), v); err != nil || record.SealWrap {
+				keys = append(keys, string(k))
+			}
+			return nil
+		})
+	})
+	return keys, err
+}
+
+func (s *cacheSpill) delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(spillEntriesBucket)
+		old := entries.Get([]byte(key))
+		if old == nil {
+			return nil
+		}
+		if err := tx.Bucket(spillOrderBucket).Delete(old[:8]); err != nil {
+			return err
+		}
+		s.count--
+		return entries.Delete([]byte(key))
+	})
+}
+
+// deleteMatching drops every spilled entry whose key matches.
+func (s *cacheSpill) deleteMatching(match func(key string) bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(spillEntriesBucket)
+		order := tx.Bucket(spillOrderBucket)
+
+		var matched [][]byte
+		err := entries.ForEach(func(k, v []byte) error {
+			if match(string(k)) {
+				if err := order.Delete(v[:8]); err != nil {
+					return err
+				}
+				matched = append(matched, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range matched {
+			if err := entries.Delete(k); err != nil {
+				return err
+			}
+			s.count--
+		}
+		return nil
+	})
+}
+
+// queueSpill hands an entry evicted from memory, as cached under the key, to
+// the spill worker. It
+// must not block as callers hold the lock of the key whose insertion caused
+// the eviction.
+func (c *Cache) queueSpill(key string, evicted interface{}) {
+	entry, ok := evicted.(*Entry)
+	if !ok || c.spill == nil {
+		return
+	}
+
+	generation := c.spillGenerations[locksutil.LockIndexForKey(key)].Load()
+	select {
+	case c.spill.queue <- spilledEviction{key: key, entry: entry, generation: generation}:
+	default:
+		c.metricSink.IncrCounter([]string{"cache", "spill", "drop"}, 1)
+	}
+}
+
+// runSpillWorker writes queued evictions to the spill file. Each one is
+// written under the lock of its key, and only if nothing invalidated the key
+// since it was evicted; otherwise a stale value could outlive a newer write
+// or delete.
+func (c *Cache) runSpillWorker(s *cacheSpill) {
+	defer close(s.doneCh)
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case eviction := <-s.queue:
+			key := eviction.key
+			index := locksutil.LockIndexForKey(key)
+
+			lock := c.locks[index]
+			lock.Lock()
+			if c.spillGenerations[index].Load() == eviction.generation && !c.partitionFor(key).lru.Contains(key) && !c.isPinned(key) {
+				if err := s.put(key, eviction.entry); err != nil {
+					c.logger.Warn("failed to spill evicted cache entry", "error", err)
+				} else {
+					c.metricSink.IncrCounter([]string{"cache", "spill", "write"}, 1)
+				}
+			}
+			lock.Unlock()
+		}
+	}
+}
+
+// lookupSpill returns the spilled entry for the key, if any, restoring it to
+// memory. Callers must hold the lock for the key.
+func (c *Cache) lookupSpill(key string, ev *evictions) (*Entry, bool) {
+	if c.spill == nil {
+		return nil, false
+	}
+
+	entry, err := c.spill.get(key)
+	if err != nil {
+		c.logger.Warn("failed to read spilled cache entry", "error", err)
+		return nil, false
+	}
+	if entry == nil {
+		return nil, false
+	}
+
+	if c.isExpired(entry) {
+		return nil, false
+	}
+
+	c.metricSink.IncrCounter([]string{"cache", "spill", "hit"}, 1)
+	c.addCachedAt(key, entry, entry.cachedAt, ev)
+	view, err := c.entryView(entry)
+	if err != nil {
+		c.logger.Warn("failed to read spilled cache entry", "error", err)
+		return nil, false
+	}
+	return view, true
+}
+
+// invalidateSpill drops any spilled copy of the key, and any queued spill of
+// it. Callers must hold the write lock for the key.
+func (c *Cache) invalidateSpill(key string) {
+	if c.spill == nil {
+		return
+	}
+
+	c.spillGenerations[locksutil.LockIndexForKey(key)].Inc()
+	if err := c.spill.delete(key); err != nil {
+		c.logger.Warn("failed to drop spilled cache entry", "error", err)
+	}
+}
+
+// invalidateSpillMatching drops spilled entries whose key matches, along
+// with every queued spill. Callers must hold every lock.
+func (c *Cache) invalidateSpillMatching(match func(key string) bool) {
+	if c.spill == nil {
+		return
+	}
+
+	for _, generation := range c.spillGenerations {
+		generation.Inc()
+	}
+	if err := c.spill.deleteMatching(match); err != nil {
+		c.logger.Warn("failed to drop spilled cache entries", "error", err)
+	}
+}
+
+// invalidateSpillSealWrapped drops spilled seal-wrapped entries, along with
+// every queued spill. Callers must hold every lock.
+func (c *Cache) invalidateSpillSealWrapped() {
+	if c.spill == nil {
+		return
+	}
+
+	keys, err := c.spill.sealWrappedKeys()
+	if err != nil {
+		// Drop everything rather than risk serving old ciphertext
+		c.logger.Warn("failed to find spilled seal-wrapped cache entries", "error", err)
+		c.invalidateSpillMatching(func(string) bool { return true })
+		return
+	}
+	sealWrapped := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		sealWrapped[key] = struct{}{}
+	}
+	c.invalidateSpillMatching(func(key string) bool {
+		_, ok := sealWrapped[key]
+		return ok
+	})
+}
+
+// stopSpill stops the spill worker and closes the spill file, disabling the
+// spill tier.
+func (c *Cache) stopSpill() {
+	// Stop the worker before taking the locks, as it needs them.
+	c.locks[0].RLock()
+	s := c.spill
+	c.locks[0].RUnlock()
+	if s == nil {
+		return
+	}
+
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+	<-s.doneCh
+
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	if c.spill == nil {
+		return
+	}
+	if err := c.spill.db.Close(); err != nil {
+		c.logger.Warn("failed to close spill file", "error", err)
+	}
+	c.spill = nil
+}
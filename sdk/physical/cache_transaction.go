@@ -0,0 +1,220 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+// TransactionalCache is a physical cache over a TransactionalBackend whose
+// transactions also go through the cache.
+type TransactionalCache struct {
+	*Cache
+}
+
+// Verify TransactionalCache satisfies the correct interfaces
+var (
+	_ TransactionalBackend = &TransactionalCache{}
+	_ Transaction          = &cacheTransaction{}
+)
+
+// NewTransactionalCache returns a physical cache of the given size over a
+// transactional backend.
+func NewTransactionalCache(b TransactionalBackend, size int, logger log.Logger, metricSink metrics.MetricSink) *TransactionalCache {
+	return &TransactionalCache{
+		Cache: NewCache(b, size, logger, metricSink),
+	}
+}
+
+// NewTransactionalCacheWithConfig returns a physical cache configured by
+// config over a transactional backend.
+func NewTransactionalCacheWithConfig(b TransactionalBackend, config CacheConfig, logger log.Logger, metricSink metrics.MetricSink) *TransactionalCache {
+	return &TransactionalCache{
+		Cache: NewCacheWithConfig(b, config, logger, metricSink),
+	}
+}
+
+// cacheTransaction tracks the writes of a transaction to apply them to the
+// cache once committed.
+type cacheTransaction struct {
+	cache *Cache
+	txn   Transaction
+
+	// writes holds the latest write to each normalized key, nil for a
+	// delete; written holds the keys as given
+	lock    sync.Mutex
+	writes  map[string]*Entry
+	written map[string]struct{}
+}
+
+// BeginReadOnlyTx starts a read-only transaction; see BeginTx.
+func (c *TransactionalCache) BeginReadOnlyTx(ctx context.Context) (Transaction, error) {
+	if c.writeBack.Load() != nil {
+		return nil, ErrWriteBackEnabled
+	}
+
+	txnBackend, err := c.transactionalBackend()
+	if err != nil {
+		return nil, err
+	}
+	txn, err := txnBackend.BeginReadOnlyTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTransaction(txn), nil
+}
+
+// BeginTx starts a transaction whose writes are cached once committed. It is
+// not available in write-back mode.
+func (c *TransactionalCache) BeginTx(ctx context.Context) (Transaction, error) {
+	if c.writeBack.Load() != nil {
+		return nil, ErrWriteBackEnabled
+	}
+
+	txnBackend, err := c.transactionalBackend()
+	if err != nil {
+		return nil, err
+	}
+	txn, err := txnBackend.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.newTransaction(txn), nil
+}
+
+// transactionalBackend returns the current backend if it supports
+// transactions, which it may not if another was swapped in.
+func (c *TransactionalCache) transactionalBackend() (TransactionalBackend, error) {
+	c.locks[0].RLock()
+	defer c.locks[0].RUnlock()
+
+	txnBackend, ok := c.backend.(TransactionalBackend)
+	if !ok {
+		return nil, fmt.Errorf("backend does not support transactions")
+	}
+	return txnBackend, nil
+}
+
+func (c *TransactionalCache) newTransaction(txn Transaction) *cacheTransaction {
+	return &cacheTransaction{
+		cache:   c.Cache,
+		txn:     txn,
+		writes:  make(map[string]*Entry),
+		written: make(map[string]struct{}),
+	}
+}
+
+func (t *cacheTransaction) Put(ctx context.Context, entry *Entry) error {
+	if err := t.txn.Put(ctx, entry); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.writes[t.cache.cacheKey(entry.Key)] = t.cache.cacheEntry(entry)
+	t.written[entry.Key] = struct{}{}
+	return nil
+}
+
+func (t *cacheTransaction) Delete(ctx context.Context, key string) error {
+	if err := t.txn.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.writes[t.cache.cacheKey(key)] = nil
+	t.written[key] = struct{}{}
+	return nil
+}
+
+// Get reads through the backend's transaction so that the read takes part
+// in conflict detection.
+func (t *cacheTransaction) Get(ctx context.Context, key string) (*Entry, error) {
+	return t.txn.Get(ctx, key)
+}
+
+func (t *cacheTransaction) List(ctx context.Context, prefix string) ([]string, error) {
+	return t.txn.List(ctx, prefix)
+}
+
+func (t *cacheTransaction) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return t.txn.ListPage(ctx, prefix, after, limit)
+}
+
+// Commit commits the transaction and caches its writes, holding the locks
+// of the written keys across both.
+func (t *cacheTransaction) Commit(ctx context.Context) error {
+	c := t.cache
+	var ev evictions
+	defer c.notifyEvictions(&ev)
+
+	var written []string
+	defer func() {
+		c.notifyInvalidate(written...)
+	}()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	keys := make([]string, 0, len(t.writes))
+	for key := range t.writes {
+		keys = append(keys, key)
+	}
+	for _, lock := range locksutil.LocksForKeys(c.locks, keys) {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	err := t.txn.Commit(ctx)
+
+	// Listings are invalidated even if the commit failed, as the backend
+	// may not be able to tell whether it was applied
+	for key := range t.written {
+		c.invalidateListings(key)
+	}
+	if err != nil {
+		return err
+	}
+
+	for key := range t.written {
+		written = append(written, key)
+	}
+	for key, entry := range t.writes {
+		if !c.shouldCache(key) {
+			continue
+		}
+
+		c.invalidateSpill(key)
+		if entry == nil {
+			c.uncache(key)
+			continue
+		}
+		c.add(key, entry, &ev)
+		c.metricSink.IncrCounter([]string{"cache", "write"}, 1)
+	}
+	t.writes = make(map[string]*Entry)
+	t.written = make(map[string]struct{})
+
+	return nil
+}
+
+// Rollback rolls back the underlying transaction, discarding its writes
+// without touching the cache.
+func (t *cacheTransaction) Rollback(ctx context.Context) error {
+	t.lock.Lock()
+	t.writes = make(map[string]*Entry)
+	t.written = make(map[string]struct{})
+	t.lock.Unlock()
+
+	return t.txn.Rollback(ctx)
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWriteBackFlushInterval is used if no flush interval is
+	// specified in WriteBackConfig
+	DefaultWriteBackFlushInterval = time.Second
+
+	// DefaultWriteBackBatchSize is used if no batch size is specified in
+	// WriteBackConfig
+	DefaultWriteBackBatchSize = 128
+)
+
+var ErrWriteBackEnabled = errors.New("write-back mode is already enabled")
+
+// WriteBackConfig configures the deferred write mode of the cache; see
+// Cache.EnableWriteBack.
+type WriteBackConfig struct {
+	// FlushInterval is how often buffered writes are flushed to the backend.
+	FlushInterval time.Duration
+
+	// BatchSize is the maximum number of buffered writes sent to the backend
+	// at once. Reaching it also triggers an early flush.
+	BatchSize int
+}
+
+// writeBackBuffer holds writes which have been acknowledged to the caller
+// but not yet persisted. A nil entry denotes a pending delete.
+type writeBackBuffer struct {
+	batchSize int
+
+	lock     sync.Mutex
+	pending  map[string]*Entry
+	inflight map[string]*Entry
+
+	// flushLock serializes flushes so that writes to the same key reach the
+	// backend in order.
+	flushLock sync.Mutex
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+// lookup returns the buffered state of the key, if any.
+func (wb *writeBackBuffer) lookup(key string) (*Entry, bool) {
+	wb.lock.Lock()
+	defer wb.lock.Unlock()
+
+	if entry, ok := wb.pending[key]; ok {
+		return entry, true
+	}
+	entry, ok := wb.inflight[key]
+	return entry, ok
+}
+
+// enqueue buffers the entry for the key, replacing any earlier buffered
+// write for it.
+func (wb *writeBackBuffer) enqueue(key string, entry *Entry) {
+	wb.lock.Lock()
+	wb.pending[key] = entry
+	full := len(wb.pending) >= wb.batchSize
+	wb.lock.Unlock()
+
+	if full {
+		select {
+		case wb.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// EnableWriteBack switches the cache into write-back mode: Put and Delete
+// are buffered in memory, coalescing repeated writes to the same key, and
+// acknowledged immediately. A background flusher persists them in batches,
+// inside a single transaction per batch when the backend is a
+// TransactionalBackend. Get observes buffered writes, but List and ListPage
+// only reflect them once flushed.
+//
+// This trades durability for throughput and is only suitable for bulk loads
+// which can be safely replayed: buffered writes are lost if the process
+// exits before they are flushed, and errors persisting them are only logged
+// rather than returned to the caller which made the write. Call Flush or
+// DisableWriteBack to drain the buffer.
+func (c *Cache) EnableWriteBack(config WriteBackConfig) error {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultWriteBackFlushInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultWriteBackBatchSize
+	}
+
+	// Lock the world
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	if c.writeBack.Load() != nil {
+		return ErrWriteBackEnabled
+	}
+
+	wb := &writeBackBuffer{
+		batchSize: config.BatchSize,
+		pending:   make(map[string]*Entry),
+		inflight:  make(map[string]*Entry),
+		flushCh:   make(chan struct{}, 1),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+	go c.runWriteBackFlusher(wb, config.FlushInterval)
+	c.writeBack.Store(wb)
+
+	return nil
+}
+
+// DisableWriteBack drains all buffered writes to the backend and returns the
+// cache to write-through mode. If draining fails, write-back mode remains
+// enabled so that no buffered writes are lost, and the error is returned.
+func (c *Cache) DisableWriteBack(ctx context.Context) error {
+	// Lock the world, so no further writes can be buffered while draining
+	for _, lock := range c.locks {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	wb := c.writeBack.Load()
+	if wb == nil {
+		return nil
+	}
+
+	if err := c.flushWriteBack(ctx, wb); err != nil {
+		return err
+	}
+
+	close(wb.stopCh)
+	<-wb.doneCh
+	c.writeBack.Store(nil)
+
+	return nil
+}
+
+// Flush persists all writes buffered before the call. It is a no-op unless
+// write-back mode is enabled.
+func (c *Cache) Flush(ctx context.Context) error {
+	wb := c.writeBack.Load()
+	if wb == nil {
+		return nil
+	}
+	return c.flushWriteBack(ctx, wb)
+}
+
+func (c *Cache) runWriteBackFlusher(wb *writeBackBuffer, interval time.Duration) {
+	defer close(wb.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wb.stopCh:
+			return
+		case <-ticker.C:
+		case <-wb.flushCh:
+		}
+
+		if err := c.flushWriteBack(context.Background(), wb); err != nil {
+			c.logger.Warn("failed to flush buffered cache writes, will retry", "error", err)
+		}
+	}
+}
+
+// flushWriteBack writes out pending entries in batches until none remain.
+// Entries stay visible to lookups until they have been persisted, and are
+// returned to the pending set on failure unless superseded by a newer write.
+func (c *Cache) flushWriteBack(ctx context.Context, wb *writeBackBuffer) error {
+	wb.flushLock.Lock()
+	defer wb.flushLock.Unlock()
+
+	for {
+		wb.lock.Lock()
+		if len(wb.pending) == 0 {
+			wb.lock.Unlock()
+			return nil
+		}
+		batch := make(map[string]*Entry, wb.batchSize)
+		for key, entry := range wb.pending {
+			if len(batch) == wb.batchSize {
+				break
+			}
+			batch[key] = entry
+			wb.inflight[key] = entry
+			delete(wb.pending, key)
+		}
+		wb.lock.Unlock()
+
+		err := c.writeBatch(ctx, batch)
+
+		wb.lock.Lock()
+		for key, entry := range batch {
+			// Even a failed batch may have been partially applied
+			c.invalidateListings(key)
+			delete(wb.inflight, key)
+			if err != nil {
+				if _, ok := wb.pending[key]; !ok {
+					wb.pending[key] = entry
+				}
+			}
+		}
+		wb.lock.Unlock()
+
+		if err != nil {
+			return err
+		}
+		c.metricSink.IncrCounter([]string{"cache", "write_back", "flush"}, float32(len(batch)))
+	}
+}
+
+// writeBatch applies the batch to the backend, transactionally if possible.
+func (c *Cache) writeBatch(ctx context.Context, batch map[string]*Entry) error {
+	txnBackend, ok := c.backend.(TransactionalBackend)
+	if !ok {
+		return applyBatch(ctx, c.backend, batch)
+	}
+
+	txn, err := txnBackend.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := applyBatch(ctx, txn, batch); err != nil {
+		if rollbackErr := txn.Rollback(ctx); rollbackErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rollbackErr))
+		}
+		return err
+	}
+	return txn.Commit(ctx)
+}
+
+func applyBatch(ctx context.Context, b Backend, batch map[string]*Entry) error {
+	for key, entry := range batch {
+		var err error
+		if entry == nil {
+			err = b.Delete(ctx, key)
+		} else {
+			err = b.Put(ctx, entry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// putWriteBack buffers the entry and updates the LRU as if the write had
+// already been persisted. The key is the normalized key of the entry.
+func (c *Cache) putWriteBack(wb *writeBackBuffer, key string, entry *Entry, ev *evictions) {
+	cacheEntry := c.cacheEntry(entry)
+	wb.enqueue(key, cacheEntry)
+
+	if c.shouldCache(key) {
+		c.invalidateSpill(key)
+		c.add(key, cacheEntry, ev)
+		c.metricSink.IncrCounter([]string{"cache", "write"}, 1)
+	}
+}
+
+// deleteWriteBack buffers the delete and drops any cached copy of the key.
+func (c *Cache) deleteWriteBack(wb *writeBackBuffer, key string) {
+	wb.enqueue(key, nil)
+	c.uncache(key)
+	c.invalidateSpill(key)
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ChecksumPrefix namespaces the checksums stored in Entry.ValueHash by a
+// Cache verifying checksums, so that they can be told apart from hashes
+// populated by backends for their own purposes, which are left alone.
+const ChecksumPrefix = "openbao-sha256:"
+
+// checksumFrameMagic starts every value framed by a Checksummer, followed
+// by the SHA-256 checksum and then the value itself. Values without it were
+// written before checksums were enabled and are returned unverified.
+var checksumFrameMagic = []byte("\x00obcs\x01")
+
+var checksumFrameLen = len(checksumFrameMagic) + sha256.Size
+
+// ErrChecksumMismatch is wrapped by every ChecksumError.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumError is returned when the value of an entry does not match its
+// checksum, which means it has been corrupted.
+type ChecksumError struct {
+	Key string
+
+	// Source is where the corruption was detected: "storage" for values
+	// read from the backend, "cache" for values held in memory.
+	Source string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: %s entry %q is corrupt", ErrChecksumMismatch, e.Source, e.Key)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// ChecksumExempt is implemented by backends which must not be wrapped by a
+// Checksummer, such as those which already verify the integrity of their
+// data or rely on Entry.ValueHash themselves.
+type ChecksumExempt interface {
+	SkipChecksums() bool
+}
+
+// checksum returns the SHA-256 checksum of the key and value. The key is
+// covered so that a value stored under the wrong key is detected too.
+func checksum(key string, value []byte) [sha256.Size]byte {
+	h := sha256.New()
+	var keyLen [8]byte
+	binary.BigEndian.PutUint64(keyLen[:], uint64(len(key)))
+	h.Write(keyLen[:])
+	h.Write([]byte(key))
+	h.Write(value)
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// ChecksumValueHash returns the namespaced checksum of the key and value,
+// in the form stored in Entry.ValueHash.
+func ChecksumValueHash(key string, value []byte) []byte {
+	sum := checksum(key, value)
+	return append([]byte(ChecksumPrefix), sum[:]...)
+}
+
+// hasChecksum returns whether the entry carries a namespaced checksum.
+func hasChecksum(entry *Entry) bool {
+	return bytes.HasPrefix(entry.ValueHash, []byte(ChecksumPrefix))
+}
+
+// VerifyChecksum checks the entry against the namespaced checksum in its
+// ValueHash, returning a ChecksumError from the given source on mismatch.
+// Entries without such a checksum pass.
+func VerifyChecksum(entry *Entry, source string) error {
+	if !hasChecksum(entry) {
+		return nil
+	}
+	sum := checksum(entry.Key, entry.Value)
+	if subtle.ConstantTimeCompare(entry.ValueHash[len(ChecksumPrefix):], sum[:]) != 1 {
+		return &ChecksumError{Key: entry.Key, Source: source}
+	}
+	return nil
+}
+
+// Checksummer wraps a physical backend to detect silent corruption of
+// stored values. Each value written is framed with a checksum of its key and
+// contents, which is verified when the value is read back; a mismatch fails
+// the read with a ChecksumError. Values written before checksums were
+// enabled are returned as they are.
+//
+// As most backends only persist the value of an entry, the checksum is kept
+// within the stored value rather than in ValueHash, which is passed through
+// untouched for backends which use it themselves. Corruption of entries
+// held in memory is caught by CacheConfig.VerifyChecksums instead.
+type Checksummer struct {
+	backend Backend
+}
+
+// TransactionalChecksummer is a Checksummer over a transactional backend
+// whose transactions are checksummed as well.
+type TransactionalChecksummer struct {
+	*Checksummer
+	transactional Transactional
+}
+
+// Verify Checksummer satisfies the correct interfaces
+var (
+	_ Backend              = (*Checksummer)(nil)
+	_ TransactionalBackend = (*TransactionalChecksummer)(nil)
+	_ Transaction          = (*checksumTransaction)(nil)
+)
+
+// NewChecksummer wraps the backend in a Checksummer, or a
+// TransactionalChecksummer if it is transactional. Backends exempted through
+// ChecksumExempt are returned as they are.
+func NewChecksummer(b Backend) Backend {
+	if exempt, ok := b.(ChecksumExempt); ok && exempt.SkipChecksums() {
+		return b
+	}
+	c := &Checksummer{backend: b}
+	if txn, ok := b.(Transactional); ok {
+		return &TransactionalChecksummer{
+			Checksummer:   c,
+			transactional: txn,
+		}
+	}
+	return c
+}
+
+func (c *Checksummer) Put(ctx context.Context, entry *Entry) error {
+	return putChecksummed(ctx, c.backend, entry)
+}
+
+func (c *Checksummer) Get(ctx context.Context, key string) (*Entry, error) {
+	return getChecksummed(ctx, c.backend, key)
+}
+
+func (c *Checksummer) Delete(ctx context.Context, key string) error {
+	return c.backend.Delete(ctx, key)
+}
+
+func (c *Checksummer) List(ctx context.Context, prefix string) ([]string, error) {
+	return c.backend.List(ctx, prefix)
+}
+
+func (c *Checksummer) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return c.backend.ListPage(ctx, prefix, after, limit)
+}
+
+func (c *TransactionalChecksummer) BeginReadOnlyTx(ctx context.Context) (Transaction, error) {
+	txn, err := c.transactional.BeginReadOnlyTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumTransaction{txn: txn}, nil
+}
+
+func (c *TransactionalChecksummer) BeginTx(ctx context.Context) (Transaction, error) {
+	txn, err := c.transactional.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumTransaction{txn: txn}, nil
+}
+
+// checksumTransaction checksums the writes and verifies the reads made
+// within a transaction.
+type checksumTransaction struct {
+	txn Transaction
+}
+
+func (t *checksumTransaction) Put(ctx context.Context, entry *Entry) error {
+	return putChecksummed(ctx, t.txn, entry)
+}
+
+func (t *checksumTransaction) Get(ctx context.Context, key string) (*Entry, error) {
+	return getChecksummed(ctx, t.txn, key)
+}
+
+func (t *checksumTransaction) Delete(ctx context.Context, key string) error {
+	return t.txn.Delete(ctx, key)
+}
+
+func (t *checksumTransaction) List(ctx context.Context, prefix string) ([]string, error) {
+	return t.txn.List(ctx, prefix)
+}
+
+func (t *checksumTransaction) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return t.txn.ListPage(ctx, prefix, after, limit)
+}
+
+func (t *checksumTransaction) Commit(ctx context.Context) error {
+	return t.txn.Commit(ctx)
+}
+
+func (t *checksumTransaction) Rollback(ctx context.Context) error {
+	return t.txn.Rollback(ctx)
+}
+
+// putChecksummed writes the entry to the backend with its value framed by
+// its checksum. The caller's entry is left untouched.
+func putChecksummed(ctx context.Context, b Backend, entry *Entry) error {
+	sum := checksum(entry.Key, entry.Value)
+	framed := make([]byte, 0, checksumFrameLen+len(entry.Value))
+	framed = append(framed, checksumFrameMagic...)
+	framed = append(framed, sum[:]...)
+	framed = append(framed, entry.Value...)
+
+	stored := *entry
+	stored.Value = framed
+	return b.Put(ctx, &stored)
+}
+
+// getChecksummed reads the entry from the backend, verifying and removing
+// the checksum framing its value, if any.
+func getChecksummed(ctx context.Context, b Backend, key string) (*Entry, error) {
+	entry, err := b.Get(ctx, key)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	if !bytes.HasPrefix(entry.Value, checksumFrameMagic) {
+		return entry, nil
+	}
+	if len(entry.Value) < checksumFrameLen {
+		return nil, &ChecksumError{Key: key, Source: "storage"}
+	}
+
+	stored := entry.Value[len(checksumFrameMagic):checksumFrameLen]
+	value := entry.Value[checksumFrameLen:]
+	sum := checksum(key, value)
+	if subtle.ConstantTimeCompare(stored, sum[:]) != 1 {
+		return nil, &ChecksumError{Key: key, Source: "storage"}
+	}
+
+	entry.Value = value
+	return entry, nil
+}
@@ -46,6 +46,7 @@ type storageEncodingTransaction struct {
 // Verify StorageEncoding satisfies the correct interfaces
 var (
 	_ Backend              = &storageEncoding{}
+	_ CASBackend           = &storageEncoding{}
 	_ TransactionalBackend = &transactionalStorageEncoding{}
 	_ Transaction          = &storageEncodingTransaction{}
 )
@@ -86,6 +87,18 @@ func (e *storageEncoding) Put(ctx context.Context, entry *Entry) error {
 	return e.Backend.Put(ctx, entry)
 }
 
+func (e *storageEncoding) CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (bool, error) {
+	if !utf8.ValidString(entry.Key) {
+		return false, ErrNonUTF8
+	}
+
+	if e.containsNonPrintableChars(entry.Key) {
+		return false, ErrNonPrintable
+	}
+
+	return CompareAndSwap(ctx, e.Backend, entry, expectedHash)
+}
+
 func (e *storageEncoding) Delete(ctx context.Context, key string) error {
 	if !utf8.ValidString(key) {
 		return ErrNonUTF8
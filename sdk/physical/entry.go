@@ -6,6 +6,7 @@ package physical
 import (
 	"encoding/hex"
 	"fmt"
+	"time"
 )
 
 // Entry is used to represent data stored by the physical backend
@@ -16,6 +17,19 @@ type Entry struct {
 
 	// Only used in replication
 	ValueHash []byte
+
+	// Version optionally orders the writes to a key, for backends which
+	// assign one; it must increase with every write. Zero means the version
+	// is unknown. See CacheConfig.VersionedEntries.
+	Version uint64 `json:"version,omitempty"`
+
+	// compressed is set on entries held by a Cache whose Value it has
+	// compressed; see CacheConfig.Compression
+	compressed bool
+
+	// cachedAt is when an entry held by a Cache with a TTL was cached; see
+	// CacheConfig.TTL
+	cachedAt time.Time
 }
 
 func (e *Entry) String() string {
@@ -0,0 +1,272 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"golang.org/x/time/rate"
+)
+
+// FaultError and FaultTimeout are the kinds of failures a FaultInjector can
+// inject.
+const (
+	FaultError   = "error"
+	FaultTimeout = "timeout"
+)
+
+var (
+	// ErrInjectedFault is wrapped by all errors returned by a FaultInjector.
+	ErrInjectedFault = errors.New("injected storage fault")
+
+	// ErrFaultInjectionDisabled is returned when creating a FaultInjector
+	// in a build without the faultinjection tag, unless AllowUnsafe is set.
+	ErrFaultInjectionDisabled = errors.New("storage fault injection is not available in this build")
+)
+
+// faultInjectionBuild is set by builds with the faultinjection tag, which
+// are meant for testing and staging environments.
+var faultInjectionBuild bool
+
+// FaultInjectionConfig configures a FaultInjector.
+type FaultInjectionConfig struct {
+	// Seed seeds the random decisions of the injector. With a non-zero
+	// seed, the same sequence of calls of each operation type yields the
+	// same latencies and failures. Otherwise the injector is seeded from
+	// the current time.
+	Seed int64
+
+	// Operations holds the faults to inject per operation type. Operations
+	// not present are passed through.
+	Operations map[Operation]*OperationFaults
+
+	// AllowUnsafe allows creating the injector in builds without the
+	// faultinjection tag, such as production builds.
+	AllowUnsafe bool
+}
+
+// OperationFaults describes the faults injected into one operation type.
+type OperationFaults struct {
+	// Latency is added to every call, varied by up to JitterPercent
+	// percent in either direction.
+	Latency       time.Duration
+	JitterPercent int
+
+	// FailPercent is the percentage of calls failed, after waiting for
+	// FailAfter instead of Latency. FailWith is the kind of failure, either
+	// FaultError or FaultTimeout, which defaults to FaultError.
+	FailPercent float64
+	FailWith    string
+	FailAfter   time.Duration
+
+	// RateLimit is the number of calls per second allowed, with bursts of
+	// up to RateBurst calls. Calls over the limit fail immediately, as
+	// with a backend throttling its clients. Zero disables throttling.
+	RateLimit float64
+	RateBurst int
+}
+
+// Validate checks the faults are consistent.
+func (f *OperationFaults) Validate() error {
+	switch {
+	case f.Latency < 0 || f.FailAfter < 0:
+		return errors.New("latencies cannot be negative")
+	case f.JitterPercent < 0 || f.JitterPercent > 100:
+		return errors.New("jitter percent must be between 0 and 100")
+	case f.FailPercent < 0 || f.FailPercent > 100:
+		return errors.New("fail percent must be between 0 and 100")
+	case f.RateLimit < 0 || f.RateBurst < 0:
+		return errors.New("rate limit and burst cannot be negative")
+	}
+	switch f.FailWith {
+	case "", FaultError, FaultTimeout:
+	default:
+		return fmt.Errorf("unknown failure kind %q", f.FailWith)
+	}
+	return nil
+}
+
+// operationFaults is the runtime state of the faults of one operation type.
+// Each one has its own random source so that the decisions taken for an
+// operation type do not depend on calls of other types.
+type operationFaults struct {
+	faults  OperationFaults
+	limiter *rate.Limiter
+
+	randomLock sync.Mutex
+	random     *rand.Rand
+}
+
+// FaultInjector wraps a physical backend to inject latency, errors and
+// throttling, in order to test how callers handle a misbehaving storage. It
+// must not be used in production.
+type FaultInjector struct {
+	backend Backend
+	logger  log.Logger
+	seed    int64
+
+	l          sync.RWMutex
+	operations map[Operation]*operationFaults
+}
+
+// Verify FaultInjector satisfies the correct interfaces
+var (
+	_ Backend = (*FaultInjector)(nil)
+)
+
+// NewFaultInjector returns a wrapped physical backend injecting the
+// configured faults.
+func NewFaultInjector(b Backend, config *FaultInjectionConfig, logger log.Logger) (*FaultInjector, error) {
+	if config == nil {
+		config = &FaultInjectionConfig{}
+	}
+	if !faultInjectionBuild && !config.AllowUnsafe {
+		return nil, ErrFaultInjectionDisabled
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f := &FaultInjector{
+		backend:    b,
+		logger:     logger,
+		seed:       seed,
+		operations: make(map[Operation]*operationFaults),
+	}
+	for op, faults := range config.Operations {
+		if err := f.SetFaults(op, faults); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Warn("creating storage fault injector, storage will misbehave on purpose", "seed", seed)
+	return f, nil
+}
+
+// SetFaults replaces the faults injected into an operation type, resetting
+// its random source. A nil value stops injecting faults into it.
+func (f *FaultInjector) SetFaults(op Operation, faults *OperationFaults) error {
+	switch op {
+	case DeleteOperation, GetOperation, ListOperation, PutOperation:
+	default:
+		return fmt.Errorf("unknown operation %q", op)
+	}
+
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if faults == nil {
+		delete(f.operations, op)
+		return nil
+	}
+	if err := faults.Validate(); err != nil {
+		return fmt.Errorf("invalid faults for operation %q: %w", op, err)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(op))
+	state := &operationFaults{
+		faults: *faults,
+		random: rand.New(rand.NewSource(f.seed ^ int64(h.Sum64()))),
+	}
+	if faults.RateLimit > 0 {
+		state.limiter = rate.NewLimiter(rate.Limit(faults.RateLimit), max(faults.RateBurst, 1))
+	}
+	f.operations[op] = state
+
+	f.logger.Info("changing storage faults", "operation", op, "latency", faults.Latency,
+		"fail_percent", faults.FailPercent, "fail_with", faults.FailWith, "rate_limit", faults.RateLimit)
+	return nil
+}
+
+// inject applies the faults of an operation type, returning an error if the
+// call must fail.
+func (f *FaultInjector) inject(ctx context.Context, op Operation) error {
+	f.l.RLock()
+	state := f.operations[op]
+	f.l.RUnlock()
+	if state == nil {
+		return nil
+	}
+	faults := &state.faults
+
+	if state.limiter != nil && !state.limiter.Allow() {
+		return fmt.Errorf("%w: %s throttled", ErrInjectedFault, op)
+	}
+
+	// Draw both decisions on every call, so that the sequence of decisions
+	// only depends on the number of calls.
+	state.randomLock.Lock()
+	fail := state.random.Float64()*100 < faults.FailPercent
+	percent := 100
+	if faults.JitterPercent > 0 {
+		percent += state.random.Intn(2*faults.JitterPercent+1) - faults.JitterPercent
+	}
+	state.randomLock.Unlock()
+
+	delay := faults.Latency
+	if fail {
+		delay = faults.FailAfter
+	}
+	if delay = delay * time.Duration(percent) / 100; delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if !fail {
+		return nil
+	}
+	if faults.FailWith == FaultTimeout {
+		return fmt.Errorf("%w: %s: %w", ErrInjectedFault, op, context.DeadlineExceeded)
+	}
+	return fmt.Errorf("%w: %s failed", ErrInjectedFault, op)
+}
+
+func (f *FaultInjector) Put(ctx context.Context, entry *Entry) error {
+	if err := f.inject(ctx, PutOperation); err != nil {
+		return err
+	}
+	return f.backend.Put(ctx, entry)
+}
+
+func (f *FaultInjector) Get(ctx context.Context, key string) (*Entry, error) {
+	if err := f.inject(ctx, GetOperation); err != nil {
+		return nil, err
+	}
+	return f.backend.Get(ctx, key)
+}
+
+func (f *FaultInjector) Delete(ctx context.Context, key string) error {
+	if err := f.inject(ctx, DeleteOperation); err != nil {
+		return err
+	}
+	return f.backend.Delete(ctx, key)
+}
+
+func (f *FaultInjector) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := f.inject(ctx, ListOperation); err != nil {
+		return nil, err
+	}
+	return f.backend.List(ctx, prefix)
+}
+
+func (f *FaultInjector) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	if err := f.inject(ctx, ListOperation); err != nil {
+		return nil, err
+	}
+	return f.backend.ListPage(ctx, prefix, after, limit)
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build faultinjection
+
+package physical
+
+func init() {
+	faultInjectionBuild = true
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+
+	"github.com/armon/go-metrics"
+	uberAtomic "go.uber.org/atomic"
+)
+
+// Operation types tracked by an InFlightTracker. ListPage is tracked with
+// List.
+const (
+	InFlightOpGet            = "get"
+	InFlightOpBatchGet       = "batch_get"
+	InFlightOpPut            = "put"
+	InFlightOpCompareAndSwap = "compare_and_swap"
+	InFlightOpDelete         = "delete"
+	InFlightOpList           = "list"
+)
+
+var inFlightOps = []string{
+	InFlightOpGet,
+	InFlightOpBatchGet,
+	InFlightOpPut,
+	InFlightOpCompareAndSwap,
+	InFlightOpDelete,
+	InFlightOpList,
+}
+
+// InFlightConfig configures an InFlightTracker.
+type InFlightConfig struct {
+	// MaxConcurrency is the number of operations which may run against the
+	// backend at once. Any more wait for a slot, up to the deadline of
+	// their context. If zero, the concurrency is not limited.
+	MaxConcurrency int
+}
+
+// Validate checks the configuration is consistent.
+func (c *InFlightConfig) Validate() error {
+	if c.MaxConcurrency < 0 {
+		return errors.New("max concurrency cannot be negative")
+	}
+	return nil
+}
+
+// InFlightTracker wraps a physical backend and reports the number of
+// operations running against it, per operation type, along with the number
+// of operations waiting to run when its concurrency is limited. This helps
+// telling a saturated backend apart from a slow one.
+type InFlightTracker struct {
+	backend    Backend
+	sem        chan struct{}
+	inFlight   map[string]*uberAtomic.Int64
+	queued     *uberAtomic.Int64
+	metricSink metrics.MetricSink
+}
+
+// Verify InFlightTracker satisfies the correct interfaces
+var (
+	_ Backend     = (*InFlightTracker)(nil)
+	_ BatchGetter = (*InFlightTracker)(nil)
+	_ CASBackend  = (*InFlightTracker)(nil)
+)
+
+// NewInFlightTracker returns a wrapped physical backend tracking the
+// operations made to it.
+func NewInFlightTracker(b Backend, config InFlightConfig, metricSink metrics.MetricSink) (*InFlightTracker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	t := &InFlightTracker{
+		backend:    b,
+		inFlight:   make(map[string]*uberAtomic.Int64, len(inFlightOps)),
+		queued:     uberAtomic.NewInt64(0),
+		metricSink: metricSink,
+	}
+	for _, op := range inFlightOps {
+		t.inFlight[op] = uberAtomic.NewInt64(0)
+	}
+	if config.MaxConcurrency > 0 {
+		t.sem = make(chan struct{}, config.MaxConcurrency)
+	}
+	return t, nil
+}
+
+// InFlight returns the number of operations of the given type currently
+// running against the backend.
+func (t *InFlightTracker) InFlight(op string) int {
+	counter, ok := t.inFlight[op]
+	if !ok {
+		return 0
+	}
+	return int(counter.Load())
+}
+
+// QueueDepth returns the number of operations currently waiting for a slot.
+func (t *InFlightTracker) QueueDepth() int {
+	return int(t.queued.Load())
+}
+
+func (t *InFlightTracker) setQueueDepth(queued int64) {
+	t.metricSink.SetGauge([]string{"storage", "queue_depth"}, float32(queued))
+}
+
+func (t *InFlightTracker) setInFlight(op string, inFlight int64) {
+	t.metricSink.SetGaugeWithLabels([]string{"storage", "in_flight"}, float32(inFlight), []metrics.Label{
+		{Name: "operation", Value: op},
+	})
+}
+
+// acquire waits for a slot to run an operation, unless the context is done
+// first.
+func (t *InFlightTracker) acquire(ctx context.Context) error {
+	if t.sem == nil {
+		return nil
+	}
+
+	// Operations which can run right away never queue
+	select {
+	case t.sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	t.setQueueDepth(t.queued.Inc())
+	defer func() {
+		t.setQueueDepth(t.queued.Dec())
+	}()
+
+	select {
+	case t.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin marks the start of an operation of the given type, once it may run.
+// The returned function marks its end and must be deferred, so that the
+// operation is accounted for even if the backend panics.
+func (t *InFlightTracker) begin(ctx context.Context, op string) (func(), error) {
+	if err := t.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	counter := t.inFlight[op]
+	t.setInFlight(op, counter.Inc())
+	return func() {
+		t.setInFlight(op, counter.Dec())
+		if t.sem != nil {
+			<-t.sem
+		}
+	}, nil
+}
+
+func (t *InFlightTracker) Put(ctx context.Context, entry *Entry) error {
+	done, err := t.begin(ctx, InFlightOpPut)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return t.backend.Put(ctx, entry)
+}
+
+func (t *InFlightTracker) CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (bool, error) {
+	done, err := t.begin(ctx, InFlightOpCompareAndSwap)
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	return CompareAndSwap(ctx, t.backend, entry, expectedHash)
+}
+
+func (t *InFlightTracker) Get(ctx context.Context, key string) (*Entry, error) {
+	done, err := t.begin(ctx, InFlightOpGet)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return t.backend.Get(ctx, key)
+}
+
+func (t *InFlightTracker) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	done, err := t.begin(ctx, InFlightOpBatchGet)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return BatchGetEntries(ctx, t.backend, keys)
+}
+
+func (t *InFlightTracker) Delete(ctx context.Context, key string) error {
+	done, err := t.begin(ctx, InFlightOpDelete)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return t.backend.Delete(ctx, key)
+}
+
+func (t *InFlightTracker) List(ctx context.Context, prefix string) ([]string, error) {
+	done, err := t.begin(ctx, InFlightOpList)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return t.backend.List(ctx, prefix)
+}
+
+func (t *InFlightTracker) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	done, err := t.begin(ctx, InFlightOpList)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return t.backend.ListPage(ctx, prefix, after, limit)
+}
@@ -4,13 +4,28 @@
 package inmem
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/compressutil"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/helper/strutil"
 	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -359,3 +374,3218 @@ func TestCache_Refresh(t *testing.T) {
 		t.Fatalf("expected value baz, got %s", string(r.Value))
 	}
 }
+
+func TestCache_PrefixBudget(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache := physical.NewCache(inm, 10, logger, sink)
+	cache.SetEnabled(true)
+
+	require.Error(t, cache.SetPrefixBudget("", 2))
+	require.Error(t, cache.SetPrefixBudget("sys/policy/", -1))
+	require.Error(t, cache.SetPrefixBudget("sys/policy/", 10))
+	require.NoError(t, cache.SetPrefixBudget("sys/policy/", 4))
+	require.Error(t, cache.SetPrefixBudget("sys/token/", 6))
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		err = cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("sys/policy/%d", i), Value: []byte("policy")})
+		require.NoError(t, err)
+	}
+
+	// A noisy prefix churning through the default partition must not evict
+	// the reserved entries.
+	for i := 0; i < 100; i++ {
+		err = cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("logical/%d", i), Value: []byte("data")})
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("sys/policy/%d", i)
+		require.NoError(t, inm.Delete(ctx, key))
+
+		out, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, out, "reserved entry %q should still be cached", key)
+	}
+
+	// The default partition holds the remaining six entries.
+	require.NoError(t, inm.Delete(ctx, "logical/93"))
+	require.NoError(t, inm.Delete(ctx, "logical/94"))
+	out, err := cache.Get(ctx, "logical/94")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	out, err = cache.Get(ctx, "logical/93")
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// 94 evictions from the puts plus one from caching the nil miss above.
+	intervals := sink.Data()
+	require.Len(t, intervals, 1)
+	evictions := intervals[0].Counters["cache.evict;prefix="]
+	require.Equal(t, 95, evictions.Count)
+	require.NotContains(t, intervals[0].Counters, "cache.evict;prefix=sys/policy/")
+
+	// Removing the budget returns the keys to the default partition.
+	require.NoError(t, cache.SetPrefixBudget("sys/policy/", 0))
+	require.NoError(t, cache.SetPrefixBudget("sys/token/", 6))
+}
+
+func TestCache_Stats(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache := physical.NewCache(inm, 0, logger, sink)
+	cache.SetEnabled(true)
+
+	// Stopping without starting is a no-op
+	cache.Stop()
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "bar", Value: []byte("baz")}))
+
+	for i := 0; i < 3; i++ {
+		_, err = cache.Get(ctx, "foo")
+		require.NoError(t, err)
+	}
+	_, err = cache.Get(physical.CacheRefreshContext(ctx, true), "foo")
+	require.NoError(t, err)
+
+	cache.StartStats(10 * time.Millisecond)
+	defer cache.Stop()
+
+	gauge := func(name string) (float32, bool) {
+		intervals := sink.Data()
+		g, ok := intervals[len(intervals)-1].Gauges[name]
+		return g.Value, ok
+	}
+	require.Eventually(t, func() bool {
+		_, ok := gauge("cache.hit_ratio")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	ratio, _ := gauge("cache.hit_ratio")
+	require.Equal(t, float32(0.75), ratio)
+	size, ok := gauge("cache.size")
+	require.True(t, ok)
+	require.Equal(t, float32(2), size)
+
+	// Emitting stats resets the ratio, but not the totals
+	stats := cache.Stats()
+	require.Equal(t, uint64(3), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.True(t, cache.Enabled())
+
+	// Restarting replaces the running goroutine rather than leaking it
+	cache.StartStats(10 * time.Millisecond)
+	cache.Stop()
+	cache.Stop()
+}
+
+func TestCache_NegativeTTL(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	ent := &physical.Entry{Key: "foo", Value: []byte("bar")}
+
+	// Without a TTL a cached miss is served until evicted
+	out, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, out)
+	require.NoError(t, inm.Put(ctx, ent))
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// With a TTL the miss is re-checked against the backend once it expires
+	cache.SetNegativeTTL(50 * time.Millisecond)
+	require.NoError(t, inm.Delete(ctx, "foo"))
+	cache.Purge(ctx)
+
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, out)
+	require.NoError(t, inm.Put(ctx, ent))
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	time.Sleep(60 * time.Millisecond)
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, "bar", string(out.Value))
+
+	// Positive entries are unaffected by the TTL
+	require.NoError(t, inm.Delete(ctx, "foo"))
+	time.Sleep(60 * time.Millisecond)
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+}
+
+func TestCache_WriteBack(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	for name, conf := range map[string]map[string]string{
+		"transactional":     nil,
+		"non-transactional": {"disable_transactions": "true"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			inm, err := NewInmem(conf, logger)
+			require.NoError(t, err)
+			cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+			defer cache.Stop()
+
+			ctx := context.Background()
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "existing", Value: []byte("old")}))
+
+			require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{FlushInterval: time.Hour}))
+			require.ErrorIs(t, cache.EnableWriteBack(physical.WriteBackConfig{}), physical.ErrWriteBackEnabled)
+
+			// Repeated writes to a key are coalesced and visible before being
+			// flushed, including for keys which are not cached.
+			for _, key := range []string{"foo", "core/seal-config"} {
+				require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("one")}))
+				require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("two")}))
+
+				out, err := inm.Get(ctx, key)
+				require.NoError(t, err)
+				require.Nil(t, out)
+
+				out, err = cache.Get(ctx, key)
+				require.NoError(t, err)
+				require.NotNil(t, out)
+				require.Equal(t, "two", string(out.Value))
+			}
+
+			// Deletes are buffered too
+			require.NoError(t, cache.Delete(ctx, "existing"))
+			out, err := cache.Get(ctx, "existing")
+			require.NoError(t, err)
+			require.Nil(t, out)
+			out, err = inm.Get(ctx, "existing")
+			require.NoError(t, err)
+			require.NotNil(t, out)
+
+			// A failed flush keeps the writes buffered and visible
+			inm.(interface{ FailPut(bool) }).FailPut(true)
+			require.Error(t, cache.Flush(ctx))
+			require.Error(t, cache.DisableWriteBack(ctx))
+			out, err = cache.Get(ctx, "foo")
+			require.NoError(t, err)
+			require.NotNil(t, out)
+			inm.(interface{ FailPut(bool) }).FailPut(false)
+
+			require.NoError(t, cache.Flush(ctx))
+			for _, key := range []string{"foo", "core/seal-config"} {
+				out, err = inm.Get(ctx, key)
+				require.NoError(t, err)
+				require.NotNil(t, out)
+				require.Equal(t, "two", string(out.Value))
+			}
+			out, err = inm.Get(ctx, "existing")
+			require.NoError(t, err)
+			require.Nil(t, out)
+
+			// Disabling drains anything still buffered and returns to
+			// write-through mode
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "bar", Value: []byte("baz")}))
+			require.NoError(t, cache.DisableWriteBack(ctx))
+			out, err = inm.Get(ctx, "bar")
+			require.NoError(t, err)
+			require.NotNil(t, out)
+
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "qux", Value: []byte("quux")}))
+			out, err = inm.Get(ctx, "qux")
+			require.NoError(t, err)
+			require.NotNil(t, out)
+		})
+	}
+}
+
+func TestCache_WriteBack_BatchFlush(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	defer cache.Stop()
+
+	require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{
+		FlushInterval: time.Hour,
+		BatchSize:     10,
+	}))
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+
+	// Filling a batch triggers a flush without waiting for the interval
+	require.Eventually(t, func() bool {
+		keys, err := inm.List(ctx, "foo/")
+		require.NoError(t, err)
+		return len(keys) == 10
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCache_CacheExceptions(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	ent := &physical.Entry{Key: "logical/mymount/stream/foo", Value: []byte("bar")}
+	require.NoError(t, cache.Put(ctx, ent))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "logical/mymount/config", Value: []byte("baz")}))
+	require.True(t, cache.ShouldCache(ent.Key))
+
+	cache.AddCacheExceptions([]string{"logical/mymount/stream/"})
+	require.False(t, cache.ShouldCache(ent.Key))
+	require.True(t, cache.ShouldCache("logical/mymount/config"))
+	require.Contains(t, cache.CacheExceptions(), "logical/mymount/stream/")
+	require.Contains(t, cache.CacheExceptions(), "core/seal-config")
+
+	// The previously cached entry must not be served any longer, while
+	// unrelated entries stay cached
+	require.NoError(t, inm.Delete(ctx, ent.Key))
+	require.NoError(t, inm.Delete(ctx, "logical/mymount/config"))
+	out, err := cache.Get(ctx, ent.Key)
+	require.NoError(t, err)
+	require.Nil(t, out)
+	out, err = cache.Get(ctx, "logical/mymount/config")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+
+	require.Error(t, cache.RemoveCacheExceptions([]string{"core/seal-config"}))
+	require.NoError(t, cache.RemoveCacheExceptions([]string{"logical/mymount/stream/"}))
+	require.True(t, cache.ShouldCache(ent.Key))
+	require.NotContains(t, cache.CacheExceptions(), "logical/mymount/stream/")
+}
+
+func TestCache_PurgeContext(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	latent := physical.NewLatencyInjector(inm, 0, 0, logger)
+	cache := physical.NewCache(latent, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	// Hold a key lock through a put blocked in the backend
+	ctx := context.Background()
+	latent.SetLatency(500 * time.Millisecond)
+	putDone := make(chan error)
+	go func() {
+		putDone <- cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.ErrorIs(t, cache.PurgeContext(cancelCtx), context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 400*time.Millisecond)
+
+	require.NoError(t, <-putDone)
+	latent.SetLatency(0)
+
+	// Locks acquired by the aborted purge must have been released
+	require.NoError(t, cache.PurgeContext(ctx))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("qux")}))
+	require.NoError(t, inm.Delete(ctx, "baz"))
+	out, err := cache.Get(ctx, "baz")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+}
+
+func TestCache_ReconcileList(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	setup := func(t *testing.T, keys ...string) (physical.Backend, *physical.Cache) {
+		inm, err := NewInmem(nil, logger)
+		require.NoError(t, err)
+		cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+		cache.SetEnabled(true)
+		for _, key := range keys {
+			require.NoError(t, cache.Put(context.Background(), &physical.Entry{Key: key, Value: []byte(key)}))
+		}
+		return inm, cache
+	}
+
+	// cached reports which of the keys are still served from the cache after
+	// they were deleted from the backend.
+	cached := func(t *testing.T, inm physical.Backend, cache *physical.Cache, keys ...string) []string {
+		var ret []string
+		for _, key := range keys {
+			require.NoError(t, inm.Delete(context.Background(), key))
+		}
+		for _, key := range keys {
+			out, err := cache.Get(context.Background(), key)
+			require.NoError(t, err)
+			if out != nil {
+				ret = append(ret, key)
+			}
+		}
+		return ret
+	}
+
+	reconcileCtx := physical.CacheReconcileListContext(context.Background(), true)
+
+	t.Run("opt-in", func(t *testing.T) {
+		inm, cache := setup(t, "foo/a", "foo/b")
+		require.NoError(t, inm.Delete(context.Background(), "foo/a"))
+
+		_, err := cache.List(context.Background(), "foo/")
+		require.NoError(t, err)
+		_, err = cache.List(physical.CacheReconcileListContext(context.Background(), false), "foo/")
+		require.NoError(t, err)
+		require.Equal(t, []string{"foo/a", "foo/b"}, cached(t, inm, cache, "foo/a", "foo/b"))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		inm, cache := setup(t, "foo/a", "foo/b", "foo/sub/c", "foo/gone/d", "foobar/e", "bar/f")
+		require.NoError(t, inm.Delete(context.Background(), "foo/a"))
+		require.NoError(t, inm.Delete(context.Background(), "foo/gone/d"))
+		require.NoError(t, inm.Delete(context.Background(), "foobar/e"))
+
+		keys, err := cache.List(reconcileCtx, "foo/")
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "sub/"}, keys)
+
+		// foobar/ shares the prefix string but is outside the foo/ folder
+		require.Equal(t, []string{"foo/b", "foo/sub/c", "foobar/e", "bar/f"},
+			cached(t, inm, cache, "foo/a", "foo/b", "foo/sub/c", "foo/gone/d", "foobar/e", "bar/f"))
+	})
+
+	t.Run("unterminated prefix", func(t *testing.T) {
+		inm, cache := setup(t, "foo/a", "foobar/b", "foobaz")
+		require.NoError(t, inm.Delete(context.Background(), "foobar/b"))
+		require.NoError(t, inm.Delete(context.Background(), "foobaz"))
+
+		keys, err := cache.List(reconcileCtx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"/"}, keys)
+		require.Equal(t, []string{"foo/a"}, cached(t, inm, cache, "foo/a", "foobar/b", "foobaz"))
+	})
+
+	t.Run("page", func(t *testing.T) {
+		inm, cache := setup(t, "foo/a", "foo/b", "foo/c", "foo/d", "foo/e", "foo/f")
+		for _, key := range []string{"foo/a", "foo/c", "foo/f"} {
+			require.NoError(t, inm.Delete(context.Background(), key))
+		}
+
+		// Only names after "a" up to the last result "d" are covered
+		keys, err := cache.ListPage(reconcileCtx, "foo/", "a", 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "d"}, keys)
+		require.Equal(t, []string{"foo/a", "foo/b", "foo/d", "foo/e", "foo/f"},
+			cached(t, inm, cache, "foo/a", "foo/b", "foo/c", "foo/d", "foo/e", "foo/f"))
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		inm, cache := setup(t, "foo/a", "foo/b", "foo/c", "foo/d")
+		for _, key := range []string{"foo/a", "foo/d"} {
+			require.NoError(t, inm.Delete(context.Background(), key))
+		}
+
+		// A page shorter than the limit covers everything after "a"
+		keys, err := cache.ListPage(reconcileCtx, "foo/", "a", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "c"}, keys)
+		require.Equal(t, []string{"foo/a", "foo/b", "foo/c"},
+			cached(t, inm, cache, "foo/a", "foo/b", "foo/c", "foo/d"))
+	})
+
+	t.Run("key sharing the prefix lock", func(t *testing.T) {
+		// Evicting a key guarded by the same lock as the prefix must not
+		// deadlock the listing
+		var key string
+		for i := 0; ; i++ {
+			key = fmt.Sprintf("foo/k%d", i)
+			if locksutil.LockIndexForKey(key) == locksutil.LockIndexForKey("foo/") {
+				break
+			}
+		}
+
+		for _, list := range map[string]func(*physical.Cache) ([]string, error){
+			"list": func(cache *physical.Cache) ([]string, error) {
+				return cache.List(reconcileCtx, "foo/")
+			},
+			"page": func(cache *physical.Cache) ([]string, error) {
+				return cache.ListPage(reconcileCtx, "foo/", "", 10)
+			},
+		} {
+			inm, cache := setup(t, key, "foo/kept")
+			require.NoError(t, inm.Delete(context.Background(), key))
+
+			var keys []string
+			var err error
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				keys, err = list(cache)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("reconciling listing deadlocked")
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"kept"}, keys)
+			require.Equal(t, []string{"foo/kept"}, cached(t, inm, cache, key, "foo/kept"))
+		}
+	})
+}
+
+// batchBackend records the keys requested through Get and BatchGet.
+type batchBackend struct {
+	physical.Backend
+
+	gets    []string
+	batches [][]string
+}
+
+func (b *batchBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	b.gets = append(b.gets, key)
+	return b.Backend.Get(ctx, key)
+}
+
+func (b *batchBackend) BatchGet(ctx context.Context, keys []string) ([]*physical.Entry, error) {
+	b.batches = append(b.batches, keys)
+	entries := make([]*physical.Entry, len(keys))
+	for i, key := range keys {
+		entry, err := b.Backend.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+func TestCache_BatchGet(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, key := range []string{"foo", "bar", "baz", "core/seal-config"} {
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+	}
+
+	backend := &batchBackend{Backend: inm}
+	cache := physical.NewCache(backend, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	_, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	backend.gets = nil
+
+	keys := []string{"baz", "missing", "foo", "core/seal-config", "bar"}
+	entries, err := cache.BatchGet(ctx, keys)
+	require.NoError(t, err)
+	require.Len(t, entries, len(keys))
+	for i, key := range keys {
+		if key == "missing" {
+			require.Nil(t, entries[i])
+			continue
+		}
+		require.NotNil(t, entries[i], key)
+		require.Equal(t, key, entries[i].Key)
+	}
+
+	// Only the misses go to the backend, in a single batch
+	require.Empty(t, backend.gets)
+	require.Equal(t, [][]string{{"baz", "missing", "core/seal-config", "bar"}}, backend.batches)
+
+	// The fetched entries are now cached, except for the exception path
+	backend.batches = nil
+	_, err = cache.BatchGet(ctx, keys)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"core/seal-config"}}, backend.batches)
+
+	// Backends without batch support are read sequentially
+	entries, err = physical.BatchGetEntries(ctx, inm, []string{"bar", "missing", "foo"})
+	require.NoError(t, err)
+	require.Equal(t, "bar", entries[0].Key)
+	require.Nil(t, entries[1])
+	require.Equal(t, "foo", entries[2].Key)
+}
+
+// countingBackend counts the Get calls reaching the wrapped backend.
+type countingBackend struct {
+	physical.Backend
+
+	gets atomic.Int64
+}
+
+func (b *countingBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	b.gets.Add(1)
+	return b.Backend.Get(ctx, key)
+}
+
+// gatedBackend holds reads until gate is closed, failing them if their
+// context ends first. Each read signals started once it is held.
+type gatedBackend struct {
+	physical.Backend
+
+	gate    chan struct{}
+	started chan struct{}
+}
+
+func newGatedBackend(b physical.Backend) *gatedBackend {
+	return &gatedBackend{
+		Backend: b,
+		gate:    make(chan struct{}),
+		started: make(chan struct{}, 100),
+	}
+}
+
+func (b *gatedBackend) wait(ctx context.Context) error {
+	b.started <- struct{}{}
+	select {
+	case <-b.gate:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *gatedBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.Get(ctx, key)
+}
+
+func (b *gatedBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.List(ctx, prefix)
+}
+
+func (b *gatedBackend) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.Backend.ListPage(ctx, prefix, after, limit)
+}
+
+func TestCache_CollapseMisses(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	latent := physical.NewLatencyInjector(inm, 100*time.Millisecond, 0, logger)
+	backend := &countingBackend{Backend: latent}
+	cache := physical.NewCache(backend, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	getAll := func() []error {
+		var wg sync.WaitGroup
+		errs := make([]error, 20)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				out, err := cache.Get(ctx, "foo")
+				if err == nil && (out == nil || string(out.Value) != "bar") {
+					err = fmt.Errorf("unexpected entry %#v", out)
+				}
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+		return errs
+	}
+
+	// A failed read is returned to every waiter and not cached
+	inm.(interface{ FailGet(bool) }).FailGet(true)
+	for _, err := range getAll() {
+		require.Error(t, err)
+	}
+	require.Equal(t, int64(1), backend.gets.Load())
+	inm.(interface{ FailGet(bool) }).FailGet(false)
+
+	for _, err := range getAll() {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(2), backend.gets.Load())
+
+	// The shared result was cached
+	for _, err := range getAll() {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(2), backend.gets.Load())
+
+	// Waiters are not failed by the context of the caller leading the read
+	// ending
+	gated := newGatedBackend(inm)
+	cache = physical.NewCache(gated, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader := make(chan error, 1)
+	go func() {
+		_, err := cache.Get(leaderCtx, "foo")
+		leader <- err
+	}()
+	<-gated.started
+
+	waiter := make(chan error, 1)
+	go func() {
+		out, err := cache.Get(ctx, "foo")
+		if err == nil && (out == nil || string(out.Value) != "bar") {
+			err = fmt.Errorf("unexpected entry %#v", out)
+		}
+		waiter <- err
+	}()
+	// Give the waiter time to join the read in flight
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(gated.gate)
+	require.NoError(t, <-waiter)
+	<-leader
+}
+
+func TestCache_StatsSnapshot(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 4, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	require.Equal(t, physical.CacheStats{MaxEntries: 4, EffectiveMaxEntries: 4}, cache.Stats())
+
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: make([]byte, 10)}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "bar", Value: make([]byte, 20)}))
+	require.Equal(t, physical.CacheStats{Entries: 2, MaxEntries: 4, EffectiveMaxEntries: 4, EstimatedBytes: 30}, cache.Stats())
+
+	// Overwriting adjusts by the difference
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: make([]byte, 5)}))
+	require.Equal(t, physical.CacheStats{Entries: 2, MaxEntries: 4, EffectiveMaxEntries: 4, EstimatedBytes: 25}, cache.Stats())
+
+	// Cached misses count as entries without any bytes
+	_, err = cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	require.Equal(t, physical.CacheStats{Entries: 3, MaxEntries: 4, EffectiveMaxEntries: 4, EstimatedBytes: 25, Misses: 1}, cache.Stats())
+
+	cache.Purge(ctx)
+	require.Equal(t, physical.CacheStats{MaxEntries: 4, EffectiveMaxEntries: 4, Misses: 1}, cache.Stats())
+
+	// Evicted entries are no longer counted
+	for i := 0; i < 6; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("baz/%d", i), Value: make([]byte, 3)}))
+	}
+	require.Equal(t, physical.CacheStats{Entries: 4, MaxEntries: 4, EffectiveMaxEntries: 4, EstimatedBytes: 12, Misses: 1}, cache.Stats())
+
+	require.NoError(t, cache.Delete(ctx, "baz/5"))
+	require.Equal(t, physical.CacheStats{Entries: 3, MaxEntries: 4, EffectiveMaxEntries: 4, EstimatedBytes: 9, Misses: 1}, cache.Stats())
+}
+
+func TestCache_LatencyMetrics(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache := physical.NewCache(inm, 0, logger, sink)
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	for i := 0; i < 2; i++ {
+		_, err = cache.Get(ctx, "foo")
+		require.NoError(t, err)
+	}
+	_, err = cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	_, err = cache.Get(ctx, "core/seal-config")
+	require.NoError(t, err)
+	_, err = cache.List(ctx, "")
+	require.NoError(t, err)
+	_, err = cache.ListPage(ctx, "", "", 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.Delete(ctx, "foo"))
+
+	intervals := sink.Data()
+	require.Len(t, intervals, 1)
+	samples := intervals[0].Samples
+	for name, count := range map[string]int{
+		"cache.put":               1,
+		"cache.get;result=hit":    2,
+		"cache.get;result=miss":   1,
+		"cache.get;result=bypass": 1,
+		"cache.list":              2,
+		"cache.delete":            1,
+	} {
+		require.Contains(t, samples, name)
+		require.Equal(t, count, samples[name].Count, name)
+	}
+}
+
+func TestCache_Spill(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	path := filepath.Join(t.TempDir(), "spill.db")
+	cache, err := physical.NewCacheWithSpill(inm, 4, physical.SpillConfig{Path: path}, logger, sink)
+	require.NoError(t, err)
+	cache.SetEnabled(true)
+
+	counter := func(name string) int {
+		intervals := sink.Data()
+		require.Len(t, intervals, 1)
+		intervals[0].RLock()
+		defer intervals[0].RUnlock()
+		if c, ok := intervals[0].Counters[name]; ok {
+			return c.Count
+		}
+		return 0
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte(fmt.Sprintf("secret-%d", i))}))
+	}
+	require.Eventually(t, func() bool {
+		return counter("cache.spill.write") == 6
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Evicted entries are served from the spill file rather than the backend
+	require.NoError(t, inm.Delete(ctx, "foo/0"))
+	out, err := cache.Get(ctx, "foo/0")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, []byte("secret-0"), out.Value)
+	require.Equal(t, 1, counter("cache.spill.hit"))
+
+	// Writes through the cache invalidate spilled copies
+	require.NoError(t, cache.Delete(ctx, "foo/1"))
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo/1", Value: []byte("other")}))
+	out, err = cache.Get(ctx, "foo/1")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, []byte("other"), out.Value)
+
+	cache.Stop()
+
+	// Spilled values are encrypted at rest
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "secret-")
+
+	// The cache keeps working without the spill tier
+	out, err = cache.Get(ctx, "foo/2")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, []byte("secret-2"), out.Value)
+}
+
+func TestCache_Transactions(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewTransactionalCache(inm.(physical.TransactionalBackend), 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("old")}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "bar", Value: []byte("bar")}))
+
+	get := func(t *testing.T, b physical.Backend, key string) []byte {
+		t.Helper()
+		out, err := b.Get(ctx, key)
+		require.NoError(t, err)
+		if out == nil {
+			return nil
+		}
+		return out.Value
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		txn, err := cache.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("new")}))
+		require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("baz")}))
+		require.NoError(t, txn.Delete(ctx, "bar"))
+
+		// The transaction reads its own writes, which nobody else sees
+		require.Equal(t, []byte("new"), get(t, txn, "foo"))
+		require.Equal(t, []byte("baz"), get(t, txn, "baz"))
+		require.Nil(t, get(t, txn, "bar"))
+		require.Equal(t, []byte("old"), get(t, cache, "foo"))
+		require.Equal(t, []byte("bar"), get(t, cache, "bar"))
+
+		require.NoError(t, txn.Rollback(ctx))
+
+		// Nothing from the aborted transaction made it into the cache
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("direct")}))
+		require.Equal(t, []byte("old"), get(t, cache, "foo"))
+		require.Equal(t, []byte("bar"), get(t, cache, "bar"))
+		require.Equal(t, []byte("direct"), get(t, cache, "baz"))
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		// Reads go to the backend's transaction rather than the cache
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("uncached")}))
+		txn, err := cache.BeginTx(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []byte("uncached"), get(t, txn, "baz"))
+		require.Equal(t, []byte("direct"), get(t, cache, "baz"))
+
+		require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("committed")}))
+		require.NoError(t, txn.Delete(ctx, "bar"))
+		require.NoError(t, txn.Commit(ctx))
+
+		// The committed writes are now cached; bypass the cache by writing to
+		// the backend directly to check
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("direct")}))
+		require.Equal(t, []byte("committed"), get(t, cache, "foo"))
+		require.Nil(t, get(t, cache, "bar"))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "counter", Value: []byte("1")}))
+		require.Equal(t, []byte("1"), get(t, cache, "counter"))
+
+		// Cached reads still take part in conflict detection, so a write
+		// derived from a read racing another write fails to commit
+		txn, err := cache.BeginTx(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []byte("1"), get(t, txn, "counter"))
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "counter", Value: []byte("2")}))
+		require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "total", Value: []byte("1")}))
+		require.ErrorIs(t, txn.Commit(ctx), physical.ErrTransactionCommitFailure)
+		require.Nil(t, get(t, cache, "total"))
+	})
+
+	t.Run("concurrent put", func(t *testing.T) {
+		gated := &commitGatedBackend{
+			TransactionalBackend: inm.(physical.TransactionalBackend),
+			committed:            make(chan struct{}),
+			release:              make(chan struct{}),
+		}
+		cache := physical.NewTransactionalCache(gated, 0, logger, &metrics.BlackholeSink{})
+		cache.SetEnabled(true)
+
+		txn, err := cache.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "raced", Value: []byte("txn")}))
+
+		commitErr := make(chan error, 1)
+		go func() {
+			commitErr <- txn.Commit(ctx)
+		}()
+		<-gated.committed
+
+		// A Put made once the backend committed, but before the cache was
+		// updated, must not be shadowed by the value of the transaction
+		putErr := make(chan error, 1)
+		go func() {
+			putErr <- cache.Put(ctx, &physical.Entry{Key: "raced", Value: []byte("put")})
+		}()
+		select {
+		case err := <-putErr:
+			putErr <- err
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(gated.release)
+
+		require.NoError(t, <-commitErr)
+		require.NoError(t, <-putErr)
+		require.Equal(t, []byte("put"), get(t, inm, "raced"))
+		require.Equal(t, []byte("put"), get(t, cache, "raced"))
+	})
+
+	t.Run("write-back", func(t *testing.T) {
+		require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{}))
+		defer cache.DisableWriteBack(ctx)
+
+		_, err := cache.BeginTx(ctx)
+		require.ErrorIs(t, err, physical.ErrWriteBackEnabled)
+		_, err = cache.BeginReadOnlyTx(ctx)
+		require.ErrorIs(t, err, physical.ErrWriteBackEnabled)
+	})
+}
+
+// commitGatedBackend is a transactional backend whose transactions signal
+// committed once committed to the backend, then wait for release before
+// returning.
+type commitGatedBackend struct {
+	physical.TransactionalBackend
+	committed chan struct{}
+	release   chan struct{}
+}
+
+type commitGatedTransaction struct {
+	physical.Transaction
+	b *commitGatedBackend
+}
+
+func (b *commitGatedBackend) BeginTx(ctx context.Context) (physical.Transaction, error) {
+	txn, err := b.TransactionalBackend.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &commitGatedTransaction{Transaction: txn, b: b}, nil
+}
+
+func (t *commitGatedTransaction) Commit(ctx context.Context) error {
+	err := t.Transaction.Commit(ctx)
+	close(t.b.committed)
+	<-t.b.release
+	return err
+}
+
+func TestCache_Shards(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Size: 64, Shards: 8}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	physical.ExerciseBackend(t, cache)
+	physical.ExerciseBackend_ListPrefix(t, cache)
+
+	ctx := context.Background()
+	for i := 0; i < 256; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+	stats := cache.Stats()
+	require.Equal(t, 64, stats.MaxEntries)
+	require.LessOrEqual(t, stats.Entries, 64)
+	require.Greater(t, stats.Entries, 0)
+
+	// Purge clears every shard
+	cache.Purge(ctx)
+	require.Equal(t, 0, cache.Stats().Entries)
+}
+
+func BenchmarkCache_Parallel(b *testing.B) {
+	logger := logging.NewVaultLogger(log.Error)
+	ctx := context.Background()
+
+	const numKeys = 16 * 1024
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("foo/%d", i)
+	}
+
+	for _, shards := range []int{1, 0} {
+		name := fmt.Sprintf("shards=%d", shards)
+		if shards == 0 {
+			name = "shards=default"
+		}
+		b.Run(name, func(b *testing.B) {
+			inm, err := NewInmem(nil, logger)
+			require.NoError(b, err)
+			cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Shards: shards}, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+			for _, key := range keys {
+				require.NoError(b, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("bar")}))
+			}
+
+			var next atomic.Uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := next.Add(7919)
+				for pb.Next() {
+					i++
+					key := keys[i%numKeys]
+					// One write for every fifteen reads
+					if i%16 == 0 {
+						if err := cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("baz")}); err != nil {
+							b.Fatal(err)
+						}
+						continue
+					}
+					if _, err := cache.Get(ctx, key); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestCache_EvictCallback(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 4, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	var evicted []string
+	cache.SetEvictCallback(func(key string, entry *physical.Entry) {
+		require.NotNil(t, entry)
+		require.Equal(t, key, entry.Key)
+		evicted = append(evicted, key)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 6; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+	require.Equal(t, []string{"foo/0", "foo/1"}, evicted)
+
+	// The callback runs without any lock held, so it can use the cache
+	var purgeErr error
+	cache.SetEvictCallback(func(key string, entry *physical.Entry) {
+		purgeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		purgeErr = cache.PurgeContext(purgeCtx)
+		evicted = append(evicted, key)
+	})
+	_, err = cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	require.NoError(t, purgeErr)
+	require.Equal(t, []string{"foo/0", "foo/1", "foo/2"}, evicted)
+	require.Equal(t, 0, cache.Stats().Entries)
+
+	// Removing the callback stops notifications
+	cache.SetEvictCallback(nil)
+	for i := 0; i < 6; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+	require.Len(t, evicted, 3)
+}
+
+func TestCache_ImmutableValues(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{ImmutableValues: true}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	physical.ExerciseBackend(t, cache)
+
+	ctx := context.Background()
+	value := make([]byte, 1024*1024)
+	for i := range value {
+		value[i] = byte(i)
+	}
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: value}))
+
+	// The value is shared rather than copied, but the entry itself is not
+	out, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Same(t, &value[0], &out.Value[0])
+	out.Key = "bar"
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "foo", out.Key)
+
+	// Concurrent readers of the same key do not race as long as none of
+	// them writes to the value
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				out, err := cache.Get(ctx, "foo")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				var sum int
+				for _, b := range out.Value {
+					sum += int(b)
+				}
+				if sum != 1024*1024*255/2 {
+					t.Errorf("unexpected value sum %d", sum)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCache_PrometheusMetrics(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	reg := prometheus.NewRegistry()
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{PrometheusRegisterer: reg}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "logical/1234/foo", Value: []byte("bar")}))
+	for _, key := range []string{"logical/1234/foo", "logical/1234/foo", "logical/5678/foo", "sys/token/foo", "core/foo"} {
+		_, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+	}
+
+	// A second cache on the same registerer shares the counters
+	other := physical.NewCacheWithConfig(inm, physical.CacheConfig{PrometheusRegisterer: reg}, logger, &metrics.BlackholeSink{})
+	other.SetEnabled(true)
+	_, err = other.Get(ctx, "logical/1234/foo")
+	require.NoError(t, err)
+
+	expected := `
+# HELP cache_hits_total Number of storage reads served by the physical cache.
+# TYPE cache_hits_total counter
+cache_hits_total{mount="logical/1234"} 2
+# HELP cache_misses_total Number of storage reads which missed the physical cache.
+# TYPE cache_misses_total counter
+cache_misses_total{mount="core"} 1
+cache_misses_total{mount="logical/1234"} 1
+cache_misses_total{mount="logical/5678"} 1
+cache_misses_total{mount="sys"} 1
+# HELP cache_size Number of entries held by the physical cache.
+# TYPE cache_size gauge
+cache_size 4
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected)))
+}
+
+func TestCache_SealWrapNoCopy(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	wrapped := []byte("ciphertext")
+	plain := []byte("plaintext")
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "wrapped", Value: wrapped, SealWrap: true}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "plain", Value: plain}))
+
+	out, err := cache.Get(ctx, "wrapped")
+	require.NoError(t, err)
+	require.Same(t, &wrapped[0], &out.Value[0])
+
+	out, err = cache.Get(ctx, "plain")
+	require.NoError(t, err)
+	require.NotSame(t, &plain[0], &out.Value[0])
+}
+
+func BenchmarkCache_PutSealWrap(b *testing.B) {
+	logger := logging.NewVaultLogger(log.Error)
+	ctx := context.Background()
+
+	// Roughly the shape of the entries loaded during unseal
+	value := make([]byte, 4096)
+	for _, sealWrap := range []bool{false, true} {
+		b.Run(fmt.Sprintf("seal_wrap=%t", sealWrap), func(b *testing.B) {
+			inm, err := NewInmem(nil, logger)
+			require.NoError(b, err)
+			cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				entry := &physical.Entry{Key: fmt.Sprintf("foo/%d", i%1024), Value: value, SealWrap: sealWrap}
+				if err := cache.Put(ctx, entry); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// failingBackend fails every Get of a single key.
+type failingBackend struct {
+	physical.Backend
+
+	fail string
+}
+
+func (b *failingBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	if key == b.fail {
+		return nil, fmt.Errorf("injected failure")
+	}
+	return b.Backend.Get(ctx, key)
+}
+
+func TestCache_Warm(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	backend := &batchBackend{Backend: &failingBackend{Backend: inm, fail: "fail"}}
+	cache := physical.NewCache(backend, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	for _, key := range []string{"foo", "bar", "baz", "qux", "fail", "core/poison-pill"} {
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: key, Value: []byte("old")}))
+	}
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("new")}))
+
+	get := func(key string) []byte {
+		t.Helper()
+		out, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		if out == nil {
+			return nil
+		}
+		return out.Value
+	}
+
+	// Uncached keys are fetched in a single batch, skipping duplicates,
+	// cache exceptions and keys which are already cached
+	require.NoError(t, cache.Warm(ctx, []string{"foo", "bar", "foo", "baz", "core/poison-pill"}))
+	require.Equal(t, [][]string{{"foo", "bar"}}, backend.batches)
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("direct")}))
+	require.NoError(t, inm.Delete(ctx, "bar"))
+	require.Equal(t, []byte("old"), get("foo"))
+	require.Equal(t, []byte("old"), get("bar"))
+	require.Equal(t, []byte("new"), get("baz"))
+
+	// A failing key does not prevent the others from being warmed
+	err = cache.Warm(ctx, []string{"qux", "fail"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `failed to warm "fail"`)
+	require.NoError(t, inm.Delete(ctx, "qux"))
+	require.Equal(t, []byte("old"), get("qux"))
+
+	// Keys which do not exist are reported, whether read from the backend
+	// or already cached as missing
+	absent, err := cache.WarmAbsent(ctx, []string{"foo", "missing", "core/poison-pill"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"missing"}, absent)
+	absent, err = cache.WarmAbsent(ctx, []string{"missing", "qux"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"missing"}, absent)
+}
+
+func TestCache_MaxCachedValueBytes(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{MaxCachedValueBytes: 8}, logger, sink)
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "small", Value: []byte("12345678")}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "big", Value: []byte("123456789")}))
+	require.Equal(t, 1, cache.Stats().Entries)
+
+	// Oversized entries are written through and always read through
+	out, err := inm.Get(ctx, "big")
+	require.NoError(t, err)
+	require.Equal(t, []byte("123456789"), out.Value)
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "big", Value: []byte("direct-write")}))
+	out, err = cache.Get(ctx, "big")
+	require.NoError(t, err)
+	require.Equal(t, []byte("direct-write"), out.Value)
+	require.Equal(t, 1, cache.Stats().Entries)
+
+	// Growing a cached entry past the limit drops the cached copy
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "small", Value: []byte("123456789")}))
+	require.Equal(t, 0, cache.Stats().Entries)
+
+	intervals := sink.Data()
+	require.Len(t, intervals, 1)
+	require.Equal(t, 3, intervals[0].Counters["cache.skip_oversized"].Count)
+}
+
+func TestCache_ConcurrentReadMissAndWrite(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Error)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	refreshCtx := physical.CacheRefreshContext(ctx, true)
+	keys := []string{"foo", "bar", "baz"}
+
+	// Writers race readers which always miss, so every read inserts into
+	// the cache; an insertion racing a write would leave a stale value.
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		key := key
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func(writer int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					value := []byte(fmt.Sprintf("%d-%d", writer, j))
+					if err := cache.Put(ctx, &physical.Entry{Key: key, Value: value}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}(i)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if _, err := cache.Get(refreshCtx, key); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, key := range keys {
+		cached, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		stored, err := inm.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, stored.Value, cached.Value, key)
+	}
+}
+
+// listCountingBackend counts the List and ListPage calls reaching the
+// wrapped backend, by prefix.
+type listCountingBackend struct {
+	physical.Backend
+
+	lock  sync.Mutex
+	lists map[string]int
+}
+
+func (b *listCountingBackend) count(prefix string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.lists == nil {
+		b.lists = make(map[string]int)
+	}
+	b.lists[prefix]++
+}
+
+func (b *listCountingBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	b.count(prefix)
+	return b.Backend.List(ctx, prefix)
+}
+
+func (b *listCountingBackend) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	b.count(prefix)
+	return b.Backend.ListPage(ctx, prefix, after, limit)
+}
+
+func (b *listCountingBackend) reset() map[string]int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	lists := b.lists
+	b.lists = nil
+	return lists
+}
+
+func TestCache_ListingCache(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	setup := func(t *testing.T, config physical.CacheConfig) (physical.Backend, *listCountingBackend, *physical.Cache) {
+		inm, err := NewInmem(nil, logger)
+		require.NoError(t, err)
+		backend := &listCountingBackend{Backend: inm}
+		cache := physical.NewCacheWithConfig(backend, config, logger, &metrics.BlackholeSink{})
+		cache.SetEnabled(true)
+		for _, key := range []string{"foo/a", "foo/b", "foo/c", "foo/bar/a", "foobar/a", "baz/a", "sys/expire/a"} {
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("value")}))
+		}
+		return inm, backend, cache
+	}
+	list := func(t *testing.T, cache *physical.Cache, prefix string) []string {
+		t.Helper()
+		keys, err := cache.List(ctx, prefix)
+		require.NoError(t, err)
+		return keys
+	}
+	listPage := func(t *testing.T, cache *physical.Cache, prefix, after string, limit int) []string {
+		t.Helper()
+		keys, err := cache.ListPage(ctx, prefix, after, limit)
+		require.NoError(t, err)
+		return keys
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{})
+		list(t, cache, "foo/")
+		list(t, cache, "foo/")
+		require.Equal(t, map[string]int{"foo/": 2}, backend.reset())
+	})
+
+	t.Run("caches until written", func(t *testing.T) {
+		inm, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 16})
+		require.Equal(t, []string{"a", "b", "bar/", "c"}, list(t, cache, "foo/"))
+
+		// Writes bypassing the cache are not observed
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo/d", Value: []byte("value")}))
+		require.Equal(t, []string{"a", "b", "bar/", "c"}, list(t, cache, "foo/"))
+		require.Equal(t, map[string]int{"foo/": 1}, backend.reset())
+
+		// Callers get their own copy of cached listings
+		list(t, cache, "foo/")[0] = "modified"
+		require.Equal(t, []string{"a", "b", "bar/", "c"}, list(t, cache, "foo/"))
+
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/e", Value: []byte("value")}))
+		require.Equal(t, []string{"a", "b", "bar/", "c", "d", "e"}, list(t, cache, "foo/"))
+		require.NoError(t, cache.Delete(ctx, "foo/a"))
+		require.Equal(t, []string{"b", "bar/", "c", "d", "e"}, list(t, cache, "foo/"))
+		require.Equal(t, map[string]int{"foo/": 2}, backend.reset())
+
+		// A refresh reads through
+		_, err := cache.List(physical.CacheRefreshContext(ctx, true), "foo/")
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"foo/": 1}, backend.reset())
+	})
+
+	t.Run("prefix overlap", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 16})
+		prefixes := []string{"", "f", "foo", "foo/", "foo/bar/", "foo/bar/a", "foo/baz/", "foobar/", "baz/"}
+		for _, prefix := range prefixes {
+			list(t, cache, prefix)
+		}
+		backend.reset()
+
+		// Only listings whose prefix is a prefix of the written key go
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/bar/b", Value: []byte("value")}))
+		for _, prefix := range prefixes {
+			list(t, cache, prefix)
+		}
+		require.Equal(t, map[string]int{"": 1, "f": 1, "foo": 1, "foo/": 1, "foo/bar/": 1}, backend.reset())
+		require.Equal(t, []string{"a", "b"}, list(t, cache, "foo/bar/"))
+
+		// Writing a key equal to a listed prefix also invalidates it
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/bar/a", Value: []byte("value")}))
+		list(t, cache, "foo/bar/a")
+		list(t, cache, "foobar/")
+		require.Equal(t, map[string]int{"foo/bar/a": 1}, backend.reset())
+	})
+
+	t.Run("pagination boundaries", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 16})
+
+		// Each page is cached separately, and a List is distinct from a
+		// page without a limit
+		pages := []struct {
+			after    string
+			limit    int
+			expected []string
+		}{
+			{"", 2, []string{"a", "b"}},
+			{"b", 2, []string{"bar/", "c"}},
+			{"c", 2, nil},
+			{"", 3, []string{"a", "b", "bar/"}},
+			{"a", 2, []string{"b", "bar/"}},
+		}
+		for i := 0; i < 2; i++ {
+			for _, page := range pages {
+				require.Equal(t, page.expected, listPage(t, cache, "foo/", page.after, page.limit))
+			}
+			require.Equal(t, []string{"a", "b", "bar/", "c"}, list(t, cache, "foo/"))
+		}
+		require.Equal(t, map[string]int{"foo/": len(pages) + 1}, backend.reset())
+
+		// A write past the end of a page still invalidates every page, since
+		// it may shift later ones
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/d", Value: []byte("value")}))
+		require.Equal(t, []string{"a", "b"}, listPage(t, cache, "foo/", "", 2))
+		require.Equal(t, []string{"d"}, listPage(t, cache, "foo/", "c", 2))
+		require.NoError(t, cache.Delete(ctx, "foo/a"))
+		require.Equal(t, []string{"b", "bar/"}, listPage(t, cache, "foo/", "", 2))
+		require.Equal(t, map[string]int{"foo/": 3}, backend.reset())
+	})
+
+	t.Run("exceptions and purge", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 16})
+		list(t, cache, "sys/expire/")
+		list(t, cache, "sys/expire/")
+		list(t, cache, "baz/")
+		cache.Purge(ctx)
+		list(t, cache, "baz/")
+		require.Equal(t, map[string]int{"sys/expire/": 2, "baz/": 2}, backend.reset())
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 2})
+		list(t, cache, "foo/")
+		list(t, cache, "baz/")
+		// Storing a third listing empties the full cache
+		list(t, cache, "foobar/")
+		list(t, cache, "foo/")
+		list(t, cache, "baz/")
+		require.Equal(t, map[string]int{"foo/": 2, "baz/": 2, "foobar/": 1}, backend.reset())
+	})
+
+	t.Run("failed listings", func(t *testing.T) {
+		inm, _, cache := setup(t, physical.CacheConfig{ListingCacheSize: 2})
+		list(t, cache, "foo/")
+
+		// Listings which fail leave nothing behind, however many prefixes
+		// are listed
+		inm.(interface{ FailList(bool) }).FailList(true)
+		for i := 0; i < 100; i++ {
+			_, err := cache.List(ctx, fmt.Sprintf("missing/%d/", i))
+			require.Error(t, err)
+		}
+		inm.(interface{ FailList(bool) }).FailList(false)
+
+		stats := cache.Stats()
+		require.Equal(t, 1, stats.ListingPages)
+		require.Equal(t, 1, stats.ListingPrefixes)
+	})
+
+	t.Run("write-back", func(t *testing.T) {
+		_, backend, cache := setup(t, physical.CacheConfig{ListingCacheSize: 16})
+		require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{FlushInterval: time.Hour}))
+		defer cache.DisableWriteBack(ctx)
+
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/d", Value: []byte("value")}))
+		require.Equal(t, []string{"a", "b", "bar/", "c"}, list(t, cache, "foo/"))
+
+		// Listings cached before the buffered write is flushed are dropped
+		// when it is
+		require.NoError(t, cache.Flush(ctx))
+		require.Equal(t, []string{"a", "b", "bar/", "c", "d"}, list(t, cache, "foo/"))
+		require.Equal(t, map[string]int{"foo/": 2}, backend.reset())
+	})
+}
+
+func TestCache_SwapBackend(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	newBackend := func(t *testing.T, value string, conf map[string]string) physical.Backend {
+		inm, err := NewInmem(conf, logger)
+		require.NoError(t, err)
+		for _, key := range []string{"foo", "core/poison-pill"} {
+			require.NoError(t, inm.Put(ctx, &physical.Entry{Key: key, Value: []byte(value)}))
+		}
+		return inm
+	}
+	get := func(t *testing.T, b physical.Backend, key string) []byte {
+		t.Helper()
+		out, err := b.Get(ctx, key)
+		require.NoError(t, err)
+		if out == nil {
+			return nil
+		}
+		return out.Value
+	}
+
+	first := newBackend(t, "first", nil)
+	cache := physical.NewTransactionalCache(first.(physical.TransactionalBackend), 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	require.Equal(t, []byte("first"), get(t, cache, "foo"))
+
+	// The cache is purged, so nothing read from the old backend is served
+	second := newBackend(t, "second", map[string]string{"disable_transactions": "true"})
+	old, err := cache.SwapBackend(second)
+	require.NoError(t, err)
+	require.Equal(t, first, old)
+	require.Equal(t, []byte("second"), get(t, cache, "foo"))
+	require.Equal(t, []byte("second"), get(t, cache, "core/poison-pill"))
+
+	// Transactions need a transactional backend
+	_, err = cache.BeginTx(ctx)
+	require.Error(t, err)
+
+	_, err = cache.SwapBackend(nil)
+	require.Error(t, err)
+
+	require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{}))
+	_, err = cache.SwapBackend(first)
+	require.ErrorIs(t, err, physical.ErrWriteBackEnabled)
+	require.NoError(t, cache.DisableWriteBack(ctx))
+
+	// Swapping races no operation, whether cached or not
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for _, key := range []string{"foo", "core/poison-pill"} {
+		key := key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("value")}); err != nil {
+					t.Error(err)
+				}
+				if _, err := cache.Get(ctx, key); err != nil {
+					t.Error(err)
+				}
+				if _, err := cache.List(ctx, ""); err != nil {
+					t.Error(err)
+				}
+				if err := cache.Delete(ctx, key); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	backends := []physical.Backend{first, second}
+	for i := 0; i < 50; i++ {
+		_, err := cache.SwapBackend(backends[i%2])
+		require.NoError(t, err)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestCache_Peek(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 4, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar"), ValueHash: []byte("hash")}))
+	}
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "core/poison-pill", Value: []byte("bar")}))
+
+	out, ok := cache.Peek("foo/0")
+	require.True(t, ok)
+	require.Equal(t, []byte("hash"), out.ValueHash)
+
+	// The returned entry is a copy
+	out.Value[0] = 'c'
+	out, ok = cache.Peek("foo/0")
+	require.True(t, ok)
+	require.Equal(t, []byte("bar"), out.Value)
+
+	// Peeking does not promote the entry, so it is still evicted first
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/4", Value: []byte("bar")}))
+	_, ok = cache.Peek("foo/0")
+	require.False(t, ok)
+
+	// Cached misses are reported without an entry
+	_, err = cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	out, ok = cache.Peek("missing")
+	require.True(t, ok)
+	require.Nil(t, out)
+
+	// Exceptions are never reported as cached, nor is anything when disabled
+	_, ok = cache.Peek("core/poison-pill")
+	require.False(t, ok)
+	cache.SetEnabled(false)
+	_, ok = cache.Peek("foo/4")
+	require.False(t, ok)
+}
+
+func TestCache_MemoryPressure(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+
+	var usage atomic.Uint64
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{
+		Size: 1024,
+		MemoryPressure: &physical.MemoryPressureConfig{
+			HighWaterBytes: 1000,
+			MinEntries:     64,
+			Interval:       10 * time.Millisecond,
+			MemoryUsage:    usage.Load,
+		},
+	}, logger, sink)
+	defer cache.Stop()
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	for i := 0; i < 1024; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+	require.Equal(t, 1024, cache.Stats().Entries)
+
+	// Under pressure the cache shrinks to its floor, evicting entries
+	usage.Store(2000)
+	require.Eventually(t, func() bool {
+		stats := cache.Stats()
+		return stats.EffectiveMaxEntries == 64 && stats.Entries <= 64
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1024, cache.Stats().MaxEntries)
+	require.EqualValues(t, 64, sink.Data()[0].Gauges["cache.resize"].Value)
+
+	// Writes while shrunk stay within the effective size
+	for i := 0; i < 256; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("bar/%d", i), Value: []byte("baz")}))
+	}
+	require.LessOrEqual(t, cache.Stats().Entries, 64)
+
+	// Once pressure subsides the cache grows back to its configured size
+	usage.Store(0)
+	require.Eventually(t, func() bool {
+		return cache.Stats().EffectiveMaxEntries == 1024
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 1024; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+	require.Equal(t, 1024, cache.Stats().Entries)
+
+	// Purge and Get are not blocked by the monitor
+	cache.Purge(ctx)
+	require.Equal(t, 0, cache.Stats().Entries)
+	entry, err := cache.Get(ctx, "foo/0")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+}
+
+// policyLikeValue returns a compressible JSON value of roughly the given
+// length, resembling a stored policy.
+func policyLikeValue(n, seed int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"name":"policy-` + fmt.Sprint(seed) + `","paths":[`)
+	for i := 0; b.Len() < n; i++ {
+		fmt.Fprintf(&b, `{"path":"secret/data/team-%d/app-%d/*","capabilities":["create","read","update","delete","list"]},`, seed, i)
+	}
+	b.WriteString(`{}]}`)
+	return []byte(b.String())
+}
+
+func TestCache_Compression(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	for _, compression := range []string{compressutil.CompressionTypeLZ4, compressutil.CompressionTypeSnappy} {
+		t.Run(compression, func(t *testing.T) {
+			inm, err := NewInmem(nil, logger)
+			require.NoError(t, err)
+			cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{
+				Size:                 4,
+				Compression:          compression,
+				CompressionThreshold: 64,
+			}, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+			physical.ExerciseBackend(t, cache)
+			physical.ExerciseBackend_ListPrefix(t, cache)
+			cache.Purge(context.Background())
+
+			ctx := context.Background()
+			large := policyLikeValue(4096, 0)
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/large", Value: large}))
+			require.Less(t, cache.Stats().EstimatedBytes, int64(len(large)/2))
+
+			entry, err := cache.Get(ctx, "foo/large")
+			require.NoError(t, err)
+			require.Equal(t, large, entry.Value)
+
+			// Callers get their own copy of the decompressed value
+			entry.Value[0] = 'x'
+			entry, ok := cache.Peek("foo/large")
+			require.True(t, ok)
+			require.Equal(t, large, entry.Value)
+
+			// Values under the threshold and seal-wrapped values are held
+			// as is
+			cache.Purge(ctx)
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/small", Value: []byte("bar")}))
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/wrapped", Value: large, SealWrap: true}))
+			require.EqualValues(t, len("bar")+len(large), cache.Stats().EstimatedBytes)
+
+			// Values read from the backend are compressed too, and evicted
+			// entries are reported decompressed
+			cache.Purge(ctx)
+			var evicted []*physical.Entry
+			cache.SetEvictCallback(func(key string, entry *physical.Entry) {
+				evicted = append(evicted, entry)
+			})
+			entry, err = cache.Get(ctx, "foo/large")
+			require.NoError(t, err)
+			require.Equal(t, large, entry.Value)
+			require.Less(t, cache.Stats().EstimatedBytes, int64(len(large)/2))
+			for i := 0; i < 4; i++ {
+				require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+			}
+			require.Len(t, evicted, 1)
+			require.Equal(t, large, evicted[0].Value)
+		})
+	}
+
+	// Unsupported types leave values uncompressed
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Compression: "zstd"}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	large := policyLikeValue(4096, 0)
+	require.NoError(t, cache.Put(context.Background(), &physical.Entry{Key: "foo/large", Value: large}))
+	require.EqualValues(t, len(large), cache.Stats().EstimatedBytes)
+}
+
+// BenchmarkCache_Compression reports the memory held per cached entry and the
+// cost of a cache hit for each compression type.
+func BenchmarkCache_Compression(b *testing.B) {
+	logger := logging.NewVaultLogger(log.Error)
+	ctx := context.Background()
+
+	const numKeys = 1024
+	for _, compression := range []string{"", compressutil.CompressionTypeLZ4, compressutil.CompressionTypeSnappy} {
+		name := compression
+		if name == "" {
+			name = "none"
+		}
+		b.Run(name, func(b *testing.B) {
+			inm, err := NewInmem(nil, logger)
+			require.NoError(b, err)
+			cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Size: numKeys, Compression: compression}, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+
+			for i := 0; i < numKeys; i++ {
+				require.NoError(b, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: policyLikeValue(2048, i)}))
+			}
+			cachedBytes := cache.Stats().EstimatedBytes
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := cache.Get(ctx, fmt.Sprintf("foo/%d", i%numKeys)); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(cachedBytes)/numKeys, "cached-bytes/entry")
+		})
+	}
+}
+
+func TestCache_ConsistentRead(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Size: 4, ListingCacheSize: 16}, logger, sink)
+	cache.SetEnabled(true)
+
+	ctx := context.Background()
+	consistentCtx := physical.CacheConsistentReadContext(ctx, true)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+	}
+
+	// A write made by another node is only seen by a consistent read, which
+	// leaves the cached copy alone
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo/0", Value: []byte("baz")}))
+	entry, err := cache.Get(consistentCtx, "foo/0")
+	require.NoError(t, err)
+	require.Equal(t, []byte("baz"), entry.Value)
+	entry, ok := cache.Peek("foo/0")
+	require.True(t, ok)
+	require.Equal(t, []byte("bar"), entry.Value)
+
+	// The consistent read did not promote foo/0, so it is still the first
+	// to be evicted
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/4", Value: []byte("bar")}))
+	_, ok = cache.Peek("foo/0")
+	require.False(t, ok)
+	_, ok = cache.Peek("foo/1")
+	require.True(t, ok)
+
+	// Whereas a regular read does promote it
+	_, err = cache.Get(ctx, "foo/1")
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/5", Value: []byte("bar")}))
+	_, ok = cache.Peek("foo/1")
+	require.True(t, ok)
+	_, ok = cache.Peek("foo/2")
+	require.False(t, ok)
+
+	// Neither misses nor batches are cached, and neither counts as a miss
+	entry, err = cache.Get(consistentCtx, "foo/0")
+	require.NoError(t, err)
+	require.Equal(t, []byte("baz"), entry.Value)
+	entries, err := cache.BatchGet(consistentCtx, []string{"foo/2", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), entries[0].Value)
+	require.Nil(t, entries[1])
+	for _, key := range []string{"foo/0", "foo/2", "missing"} {
+		_, ok = cache.Peek(key)
+		require.False(t, ok, key)
+	}
+	require.NotContains(t, sink.Data()[0].Counters, "cache.miss")
+
+	// Listings bypass the listing cache
+	keys, err := cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo/6", Value: []byte("bar")}))
+	keys2, err := cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, keys, keys2)
+	keys2, err = cache.List(consistentCtx, "foo/")
+	require.NoError(t, err)
+	require.Contains(t, keys2, "6")
+	keys2, err = cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, keys, keys2)
+}
+
+// flakyBackend fails every Get while fail is set, counting the Gets which
+// reached it.
+type flakyBackend struct {
+	physical.Backend
+
+	fail atomic.Bool
+	gets atomic.Int64
+}
+
+func (b *flakyBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	b.gets.Add(1)
+	if b.fail.Load() {
+		return nil, fmt.Errorf("injected failure")
+	}
+	return b.Backend.Get(ctx, key)
+}
+
+func TestCache_ReadCircuitBreaker(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	flaky := &flakyBackend{Backend: inm}
+	cache := physical.NewCacheWithConfig(flaky, physical.CacheConfig{
+		ReadCircuitBreaker: &physical.CircuitBreakerConfig{
+			Threshold: 3,
+			Cooldown:  100 * time.Millisecond,
+		},
+	}, logger, sink)
+	cache.SetEnabled(true)
+	require.Equal(t, physical.CircuitBreakerClosed, cache.ReadCircuitBreakerState())
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	// Consecutive failures open the breaker
+	flaky.fail.Store(true)
+	for i := 0; i < 3; i++ {
+		_, err := cache.Get(ctx, fmt.Sprintf("missing/%d", i))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, physical.ErrCircuitOpen)
+	}
+	require.Equal(t, physical.CircuitBreakerOpen, cache.ReadCircuitBreakerState())
+	require.EqualValues(t, physical.CircuitBreakerOpen, sink.Data()[0].Gauges["cache.circuit_breaker.read.state"].Value)
+
+	// Misses then fail fast, while hits and writes are unaffected
+	gets := flaky.gets.Load()
+	_, err = cache.Get(ctx, "missing/0")
+	require.ErrorIs(t, err, physical.ErrCircuitOpen)
+	_, err = cache.BatchGet(ctx, []string{"foo", "missing/0"})
+	require.ErrorIs(t, err, physical.ErrCircuitOpen)
+	require.Equal(t, gets, flaky.gets.Load())
+	entry, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), entry.Value)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/1", Value: []byte("bar")}))
+	require.NoError(t, cache.Delete(ctx, "foo/1"))
+
+	// A failed trial read after the cooldown opens it again
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, physical.CircuitBreakerHalfOpen, cache.ReadCircuitBreakerState())
+	_, err = cache.Get(ctx, "missing/0")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, physical.ErrCircuitOpen)
+	require.Equal(t, physical.CircuitBreakerOpen, cache.ReadCircuitBreakerState())
+
+	// A successful one closes it
+	flaky.fail.Store(false)
+	time.Sleep(100 * time.Millisecond)
+	_, err = cache.Get(ctx, "missing/0")
+	require.NoError(t, err)
+	require.Equal(t, physical.CircuitBreakerClosed, cache.ReadCircuitBreakerState())
+	require.EqualValues(t, physical.CircuitBreakerClosed, sink.Data()[0].Gauges["cache.circuit_breaker.read.state"].Value)
+
+	// Failures outside the window are not consecutive
+	cache = physical.NewCacheWithConfig(flaky, physical.CacheConfig{
+		ReadCircuitBreaker: &physical.CircuitBreakerConfig{
+			Threshold: 2,
+			Window:    50 * time.Millisecond,
+		},
+	}, logger, sink)
+	cache.SetEnabled(true)
+	flaky.fail.Store(true)
+	_, err = cache.Get(ctx, "missing/0")
+	require.Error(t, err)
+	time.Sleep(100 * time.Millisecond)
+	_, err = cache.Get(ctx, "missing/1")
+	require.Error(t, err)
+	require.Equal(t, physical.CircuitBreakerClosed, cache.ReadCircuitBreakerState())
+
+	// Nor do reads abandoned by their caller count
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cache.Get(canceledCtx, "missing/0")
+	require.Error(t, err)
+	require.Equal(t, physical.CircuitBreakerClosed, cache.ReadCircuitBreakerState())
+}
+
+func TestCache_VersionedEntries(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{VersionedEntries: true}, logger, sink)
+	cache.SetEnabled(true)
+	physical.ExerciseBackend(t, cache)
+	physical.ExerciseBackend_ListPrefix(t, cache)
+
+	ctx := context.Background()
+	requireCached := func(value string, version uint64) {
+		t.Helper()
+		entry, ok := cache.Peek("foo")
+		require.True(t, ok)
+		require.Equal(t, value, string(entry.Value))
+		require.Equal(t, version, entry.Version)
+	}
+
+	// Newer versions replace the cached entry
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v1"), Version: 1}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v3"), Version: 3}))
+	requireCached("v3", 3)
+
+	// Older and equal ones are dropped, though still written to the backend
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v2"), Version: 2}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v3 again"), Version: 3}))
+	requireCached("v3", 3)
+	require.EqualValues(t, 2, sink.Data()[0].Counters["cache.skip_stale"].Count)
+	entry, err := inm.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "v3 again", string(entry.Value))
+
+	// Without a version on either side, the last writer wins
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("unversioned")}))
+	requireCached("unversioned", 0)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v1"), Version: 1}))
+	requireCached("v1", 1)
+
+	// A delete always drops the cached entry
+	require.NoError(t, cache.Delete(ctx, "foo"))
+	entry, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v1"), Version: 1}))
+	requireCached("v1", 1)
+
+	// Without the option, versions are ignored
+	cache = physical.NewCache(inm, 0, logger, sink)
+	cache.SetEnabled(true)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v3"), Version: 3}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("v2"), Version: 2}))
+	requireCached("v2", 2)
+}
+
+// prefixDeletingBackend natively supports DeletePrefix, counting the calls.
+type prefixDeletingBackend struct {
+	physical.Backend
+
+	calls int
+}
+
+func (b *prefixDeletingBackend) DeletePrefix(ctx context.Context, prefix string) error {
+	b.calls++
+	return physical.DeletePrefix(ctx, b.Backend, prefix)
+}
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	physical.ExerciseBackend_CompareAndSwap(t, cache)
+
+	// Backends without native support fall back to a locked Get and Put
+	fallback := physical.NewCache(struct{ physical.Backend }{inm}, 0, logger, &metrics.BlackholeSink{})
+	fallback.SetEnabled(true)
+	physical.ExerciseBackend_CompareAndSwap(t, fallback)
+
+	// Successful swaps are cached
+	swapped, err := cache.CompareAndSwap(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}, nil)
+	require.NoError(t, err)
+	require.True(t, swapped)
+	entry, cached := cache.Peek("foo")
+	require.True(t, cached)
+	require.Equal(t, "bar", string(entry.Value))
+
+	// The comparison is made against the backend, and failing evicts the
+	// stale cached value
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("baz")}))
+	swapped, err = cache.CompareAndSwap(ctx, &physical.Entry{Key: "foo", Value: []byte("qux")}, physical.HashValue([]byte("bar")))
+	require.NoError(t, err)
+	require.False(t, swapped)
+	_, cached = cache.Peek("foo")
+	require.False(t, cached)
+	entry, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "baz", string(entry.Value))
+
+	// Buffered writes have yet to reach the backend to compare against
+	require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{FlushInterval: time.Hour}))
+	defer cache.DisableWriteBack(ctx)
+	_, err = cache.CompareAndSwap(ctx, &physical.Entry{Key: "foo", Value: []byte("qux")}, physical.HashValue([]byte("baz")))
+	require.ErrorIs(t, err, physical.ErrWriteBackEnabled)
+}
+
+func TestCache_DeletePrefix(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	backend := &prefixDeletingBackend{Backend: inm}
+	cache := physical.NewCacheWithConfig(backend, physical.CacheConfig{Size: 64, Shards: 4, ListingCacheSize: 16}, logger, &metrics.BlackholeSink{})
+	require.NoError(t, cache.SetPrefixBudget("foo/bar/", 16))
+	cache.SetEnabled(true)
+
+	keys := []string{"foo/a", "foo/b/c", "foo/bar/1", "foo/bar/2/x", "foo/baz", "fox", "other/foo/x"}
+	for _, key := range keys {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("bar")}))
+	}
+	requireEntries := func(present ...string) {
+		t.Helper()
+		for _, key := range keys {
+			_, cached := cache.Peek(key)
+			entry, err := inm.Get(ctx, key)
+			require.NoError(t, err)
+			if strutil.StrListContains(present, key) {
+				require.True(t, cached, key)
+				require.NotNil(t, entry, key)
+			} else {
+				require.False(t, cached, key)
+				require.Nil(t, entry, key)
+			}
+		}
+		require.Equal(t, len(present), cache.Stats().Entries)
+	}
+	requireEntries(keys...)
+
+	// Populate the listing cache
+	listed, err := cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b/", "bar/", "baz"}, listed)
+	listed, err = cache.List(ctx, "foo/bar/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2/"}, listed)
+
+	// A prefix which is not a path segment spans both the prefix partition
+	// and the default one
+	require.NoError(t, cache.DeletePrefix(ctx, "foo/ba"))
+	require.Equal(t, 1, backend.calls)
+	requireEntries("foo/a", "foo/b/c", "fox", "other/foo/x")
+	listed, err = cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b/"}, listed)
+	listed, err = cache.List(ctx, "foo/bar/")
+	require.NoError(t, err)
+	require.Empty(t, listed)
+
+	// Nested keys are deleted too, while keys merely sharing a leading
+	// string or containing the prefix elsewhere are not
+	require.NoError(t, cache.DeletePrefix(ctx, "foo/"))
+	requireEntries("fox", "other/foo/x")
+
+	// Without native support, keys are listed and deleted one by one
+	cache = physical.NewCacheWithConfig(inm, physical.CacheConfig{Shards: 8}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+	for _, key := range keys {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("bar")}))
+	}
+	require.NoError(t, cache.DeletePrefix(ctx, "foo/"))
+	requireEntries("fox", "other/foo/x")
+	require.NoError(t, cache.DeletePrefix(ctx, ""))
+	requireEntries()
+
+	// Buffered writes would resurrect deleted keys
+	require.NoError(t, cache.EnableWriteBack(physical.WriteBackConfig{}))
+	require.ErrorIs(t, cache.DeletePrefix(ctx, "foo/"), physical.ErrWriteBackEnabled)
+	require.NoError(t, cache.DisableWriteBack(ctx))
+}
+
+func TestCache_Dump(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{Size: 6, Shards: 1}, logger, &metrics.BlackholeSink{})
+	require.NoError(t, cache.SetPrefixBudget("foo/bar/", 2))
+	cache.SetEnabled(true)
+
+	dump, err := cache.Dump(ctx)
+	require.NoError(t, err)
+	require.Empty(t, dump)
+
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/b", Value: []byte("secret"), SealWrap: true}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/a", Value: []byte("bar")}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/bar/c", Value: []byte("baz")}))
+	_, err = cache.Get(ctx, "missing")
+	require.NoError(t, err)
+
+	dump, err = cache.Dump(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []physical.CacheEntryInfo{
+		{Key: "foo/a", ValueLength: 3, ValueHash: "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+		{Key: "foo/b", ValueLength: 6, ValueHash: "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b", SealWrap: true},
+		{Key: "foo/bar/c", Prefix: "foo/bar/", ValueLength: 3, ValueHash: "baa5a0964d3320fbc0c6a922140453c8513ea24ab8fd0577034804a967248096"},
+		{Key: "missing", Negative: true},
+	}, dump)
+
+	// Dumping does not promote entries: foo/b is still the least recently
+	// used entry of the default partition, so filling it evicts foo/b
+	_, err = cache.Dump(ctx)
+	require.NoError(t, err)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/d", Value: []byte("bar")}))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/e", Value: []byte("bar")}))
+	_, ok := cache.Peek("foo/b")
+	require.False(t, ok)
+
+	// Expired misses are omitted
+	cache.SetNegativeTTL(time.Nanosecond)
+	dump, err = cache.Dump(ctx)
+	require.NoError(t, err)
+	require.Len(t, dump, 4)
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cache.Dump(canceledCtx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCache_TTL(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	ctx := context.Background()
+
+	const ttl = 100 * time.Millisecond
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	cache := physical.NewCacheWithConfig(inm, physical.CacheConfig{TTL: ttl}, logger, sink)
+	cache.SetEnabled(true)
+	physical.ExerciseBackend(t, cache)
+	physical.ExerciseBackend_ListPrefix(t, cache)
+
+	requireGet := func(ctx context.Context, key, value string) {
+		t.Helper()
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		if value == "" {
+			require.Nil(t, entry)
+			return
+		}
+		require.NotNil(t, entry)
+		require.Equal(t, value, string(entry.Value))
+	}
+
+	// Writes made behind the cache's back are seen once the TTL has passed,
+	// for both entries and cached misses
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	requireGet(ctx, "missing", "")
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("baz")}))
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "missing", Value: []byte("found")}))
+	requireGet(ctx, "foo", "bar")
+	requireGet(ctx, "missing", "")
+
+	time.Sleep(ttl)
+	_, ok := cache.Peek("foo")
+	require.False(t, ok)
+	misses := sink.Data()[0].Counters["cache.miss"].Count
+	requireGet(ctx, "foo", "baz")
+	requireGet(ctx, "missing", "found")
+	require.Equal(t, misses+2, sink.Data()[0].Counters["cache.miss"].Count)
+
+	// Re-read entries are cached afresh
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("qux")}))
+	requireGet(ctx, "foo", "baz")
+	_, ok = cache.Peek("foo")
+	require.True(t, ok)
+
+	// A refresh always goes to the backend, even before the TTL has passed,
+	// and restarts the TTL
+	requireGet(physical.CacheRefreshContext(ctx, true), "foo", "qux")
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("quux")}))
+	requireGet(ctx, "foo", "qux")
+}
+
+func TestCache_EvictionPolicy(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	for _, policy := range []physical.EvictionPolicy{"", physical.EvictionPolicy2Q, physical.EvictionPolicyLRU, physical.EvictionPolicyARC, "bogus"} {
+		t.Run(string(policy), func(t *testing.T) {
+			var usage atomic.Uint64
+			inm, err := NewInmem(nil, logger)
+			require.NoError(t, err)
+			flaky := &flakyBackend{Backend: inm}
+			cache := physical.NewCacheWithConfig(flaky, physical.CacheConfig{
+				Size:           64,
+				Shards:         1,
+				EvictionPolicy: policy,
+				MemoryPressure: &physical.MemoryPressureConfig{
+					HighWaterBytes: 1000,
+					MinEntries:     16,
+					Interval:       10 * time.Millisecond,
+					MemoryUsage:    usage.Load,
+				},
+			}, logger, &metrics.BlackholeSink{})
+			defer cache.Stop()
+			cache.SetEnabled(true)
+			physical.ExerciseBackend(t, cache)
+			cache.Purge(ctx)
+
+			var evicted atomic.Int64
+			cache.SetEvictCallback(func(key string, entry *physical.Entry) {
+				evicted.Add(1)
+			})
+			for i := 0; i < 256; i++ {
+				require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("foo/%d", i), Value: []byte("bar")}))
+			}
+			stats := cache.Stats()
+			require.Equal(t, 64, stats.Entries)
+			require.EqualValues(t, 3*64, stats.EstimatedBytes)
+			require.EqualValues(t, 256-64, evicted.Load())
+
+			// The most recently written entries are still cached
+			gets := flaky.gets.Load()
+			entry, err := cache.Get(ctx, "foo/255")
+			require.NoError(t, err)
+			require.NotNil(t, entry)
+			require.Equal(t, gets, flaky.gets.Load())
+
+			// Shrinking under memory pressure evicts down to the floor
+			usage.Store(2000)
+			require.Eventually(t, func() bool {
+				stats := cache.Stats()
+				return stats.EffectiveMaxEntries == 16 && stats.Entries <= 16
+			}, 5*time.Second, 10*time.Millisecond)
+			require.EqualValues(t, 3*cache.Stats().Entries, cache.Stats().EstimatedBytes)
+			require.EqualValues(t, 256-cache.Stats().Entries, evicted.Load())
+
+			usage.Store(0)
+			require.Eventually(t, func() bool {
+				return cache.Stats().EffectiveMaxEntries == 64
+			}, 5*time.Second, 10*time.Millisecond)
+			for i := 0; i < 256; i++ {
+				require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("bar/%d", i), Value: []byte("bar")}))
+			}
+			require.Equal(t, 64, cache.Stats().Entries)
+		})
+	}
+}
+
+// BenchmarkCache_EvictionPolicy compares the hit rates of the eviction
+// policies on a trace which repeatedly reads a hot set of keys interleaved
+// with scans over keys read only once, such as those of a periodic tidy.
+func BenchmarkCache_EvictionPolicy(b *testing.B) {
+	logger := logging.NewVaultLogger(log.Error)
+	ctx := context.Background()
+
+	const (
+		size    = 1024
+		hotKeys = size / 2
+		scan    = 2 * size
+	)
+
+	for _, policy := range []physical.EvictionPolicy{physical.EvictionPolicy2Q, physical.EvictionPolicyLRU, physical.EvictionPolicyARC} {
+		b.Run(string(policy), func(b *testing.B) {
+			inm, err := NewInmem(nil, logger)
+			require.NoError(b, err)
+			flaky := &flakyBackend{Backend: inm}
+			cache := physical.NewCacheWithConfig(flaky, physical.CacheConfig{
+				Size:           size,
+				Shards:         1,
+				EvictionPolicy: policy,
+			}, logger, &metrics.BlackholeSink{})
+			cache.SetEnabled(true)
+
+			var reads int64
+			var scanned int
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Four passes over the hot set for every scan
+				for pass := 0; pass < 4; pass++ {
+					for k := 0; k < hotKeys; k++ {
+						if _, err := cache.Get(ctx, fmt.Sprintf("hot/%d", k)); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+				for k := 0; k < scan; k++ {
+					if _, err := cache.Get(ctx, fmt.Sprintf("scan/%d", scanned)); err != nil {
+						b.Fatal(err)
+					}
+					scanned++
+				}
+				reads += 4*hotKeys + scan
+			}
+			b.StopTimer()
+
+			b.ReportMetric(1-float64(flaky.gets.Load())/float64(reads), "hit-rate")
+		})
+	}
+}
+
+func TestCache_Pin(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	flaky := &flakyBackend{Backend: inm}
+	cache := physical.NewCacheWithConfig(flaky, physical.CacheConfig{Size: 4, Shards: 1}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	churn := func() {
+		for i := 0; i < 16; i++ {
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("churn/%d", i), Value: []byte("bar")}))
+		}
+	}
+	requireGet := func(key string, value []byte, fromBackend bool) {
+		t.Helper()
+		gets := flaky.gets.Load()
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		if value == nil {
+			require.Nil(t, entry)
+		} else {
+			require.NotNil(t, entry)
+			require.Equal(t, value, entry.Value)
+		}
+		if fromBackend {
+			require.Equal(t, gets+1, flaky.gets.Load())
+		} else {
+			require.Equal(t, gets, flaky.gets.Load())
+		}
+	}
+
+	// A cached entry is moved out of the LRU and survives any churn
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "core/mounts", Value: []byte("mounts")}))
+	cache.Pin("core/mounts")
+	churn()
+	requireGet("core/mounts", []byte("mounts"), false)
+	stats := cache.Stats()
+	require.Equal(t, 1, stats.PinnedEntries)
+	require.Equal(t, 5, stats.Entries)
+
+	// A key pinned before it is cached is cached by its first read
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "sys/policy/root", Value: []byte("root")}))
+	cache.Pin("sys/policy/root")
+	requireGet("sys/policy/root", []byte("root"), true)
+	churn()
+	requireGet("sys/policy/root", []byte("root"), false)
+	keys := cache.PinnedKeys()
+	sort.Strings(keys)
+	require.Equal(t, []string{"core/mounts", "sys/policy/root"}, keys)
+
+	dump, err := cache.Dump(ctx)
+	require.NoError(t, err)
+	require.Contains(t, dump, physical.CacheEntryInfo{
+		Key:         "core/mounts",
+		Pinned:      true,
+		ValueLength: 6,
+		ValueHash:   "fc076b9fe90fc87992ea7bf47f1862bd631ec8dea122bdf5f01bef15a49afe57",
+	})
+
+	// Pinned entries can be refreshed from the backend
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "core/mounts", Value: []byte("updated")}))
+	requireGet("core/mounts", []byte("mounts"), false)
+	_, err = cache.Get(physical.CacheRefreshContext(ctx, true), "core/mounts")
+	require.NoError(t, err)
+	requireGet("core/mounts", []byte("updated"), false)
+
+	// Writes replace and deletes drop pinned entries; misses are pinned too
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "core/mounts", Value: []byte("written")}))
+	churn()
+	requireGet("core/mounts", []byte("written"), false)
+	require.NoError(t, cache.Delete(ctx, "core/mounts"))
+	requireGet("core/mounts", nil, true)
+	churn()
+	requireGet("core/mounts", nil, false)
+
+	// Purging drops pinned entries but keeps the keys pinned
+	cache.Purge(ctx)
+	require.Equal(t, 0, cache.Stats().PinnedEntries)
+	requireGet("sys/policy/root", []byte("root"), true)
+	churn()
+	requireGet("sys/policy/root", []byte("root"), false)
+
+	// Unpinning returns the entry to the LRU, where it can be evicted
+	cache.Unpin("sys/policy/root")
+	cache.Unpin("core/mounts")
+	cache.Unpin("unpinned")
+	require.Empty(t, cache.PinnedKeys())
+	require.Equal(t, 0, cache.Stats().PinnedEntries)
+	entry, ok := cache.Peek("sys/policy/root")
+	require.True(t, ok)
+	require.Equal(t, []byte("root"), entry.Value)
+	churn()
+	requireGet("sys/policy/root", []byte("root"), true)
+}
+
+func TestCache_ServeStaleOnError(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+	refreshCtx := physical.CacheRefreshContext(ctx, true)
+
+	for _, serveStale := range []bool{false, true} {
+		t.Run(fmt.Sprintf("serve_stale=%t", serveStale), func(t *testing.T) {
+			sink := metrics.NewInmemSink(time.Minute, time.Minute)
+			inm, err := NewInmem(nil, logger)
+			require.NoError(t, err)
+			flaky := &flakyBackend{Backend: inm}
+			cache := physical.NewCacheWithConfig(flaky, physical.CacheConfig{ServeStaleOnError: serveStale}, logger, sink)
+			cache.SetEnabled(true)
+
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+			require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("qux")}))
+			_, err = cache.Get(ctx, "missing")
+			require.NoError(t, err)
+			require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "uncached", Value: []byte("bar")}))
+			flaky.fail.Store(true)
+
+			// Only reads which reach the backend are affected
+			entry, err := cache.Get(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, []byte("bar"), entry.Value)
+
+			staleCtx, stale := physical.CacheStaleReadContext(refreshCtx)
+			entry, err = cache.Get(staleCtx, "foo")
+			if !serveStale {
+				require.Error(t, err)
+				require.False(t, stale.Load())
+				_, err = cache.BatchGet(staleCtx, []string{"foo", "baz"})
+				require.Error(t, err)
+				require.False(t, stale.Load())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []byte("bar"), entry.Value)
+			require.True(t, stale.Load())
+			require.EqualValues(t, 1, sink.Data()[0].Counters["cache.stale_served"].Count)
+
+			// Cached misses are served stale too
+			staleCtx, stale = physical.CacheStaleReadContext(refreshCtx)
+			entry, err = cache.Get(staleCtx, "missing")
+			require.NoError(t, err)
+			require.Nil(t, entry)
+			require.True(t, stale.Load())
+
+			// Keys without a cached entry, and consistent reads, still fail
+			staleCtx, stale = physical.CacheStaleReadContext(refreshCtx)
+			_, err = cache.Get(staleCtx, "uncached")
+			require.Error(t, err)
+			_, err = cache.Get(physical.CacheConsistentReadContext(staleCtx, true), "foo")
+			require.Error(t, err)
+			require.False(t, stale.Load())
+
+			// A batch is served stale only if every key can be
+			_, err = cache.BatchGet(staleCtx, []string{"foo", "uncached"})
+			require.Error(t, err)
+			require.False(t, stale.Load())
+			entries, err := cache.BatchGet(staleCtx, []string{"foo", "baz", "missing"})
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+			require.Equal(t, []byte("bar"), entries[0].Value)
+			require.Equal(t, []byte("qux"), entries[1].Value)
+			require.Nil(t, entries[2])
+			require.True(t, stale.Load())
+			require.EqualValues(t, 5, sink.Data()[0].Counters["cache.stale_served"].Sum)
+
+			// Once the backend recovers, reads see its contents again
+			flaky.fail.Store(false)
+			require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("updated")}))
+			staleCtx, stale = physical.CacheStaleReadContext(refreshCtx)
+			entry, err = cache.Get(staleCtx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, []byte("updated"), entry.Value)
+			require.False(t, stale.Load())
+		})
+	}
+}
+
+func TestCache_CollapseListings(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	for _, key := range []string{"foo/a", "foo/b", "foo/c"} {
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: key, Value: []byte("bar")}))
+	}
+
+	latent := physical.NewLatencyInjector(inm, 100*time.Millisecond, 0, logger)
+	backend := &listCountingBackend{Backend: latent}
+	cache := physical.NewCache(backend, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	listAll := func(ctx context.Context, limits []int) []error {
+		var wg sync.WaitGroup
+		errs := make([]error, 20)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				limit := limits[i%len(limits)]
+				var keys []string
+				var err error
+				if limit < 0 {
+					keys, err = cache.List(ctx, "foo/")
+				} else {
+					keys, err = cache.ListPage(ctx, "foo/", "", limit)
+				}
+				if err == nil && (len(keys) < 1 || keys[0] != "a") {
+					err = fmt.Errorf("unexpected keys %v", keys)
+				}
+				if err == nil {
+					// Each caller gets its own copy of the shared result
+					keys[0] = "mutated"
+				}
+				errs[i] = err
+			}(i)
+		}
+		wg.Wait()
+		return errs
+	}
+
+	// Concurrent identical listings share a single backend call
+	for _, err := range listAll(ctx, []int{-1}) {
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"foo/": 1}, backend.reset())
+
+	// Listings of different pages do not
+	for _, err := range listAll(ctx, []int{-1, 1, 2}) {
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"foo/": 3}, backend.reset())
+
+	// Nor do consistent reads, or listings which are not concurrent
+	for _, err := range listAll(physical.CacheConsistentReadContext(ctx, true), []int{-1}) {
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"foo/": 20}, backend.reset())
+	for i := 0; i < 2; i++ {
+		_, err := cache.List(ctx, "foo/")
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"foo/": 2}, backend.reset())
+
+	// A listing started after a write under the prefix does not share a
+	// call started before it
+	latent.SetLatency(500 * time.Millisecond)
+	done := make(chan error)
+	go func() {
+		_, err := cache.List(ctx, "foo/")
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		backend.lock.Lock()
+		defer backend.lock.Unlock()
+		return backend.lists["foo/"] == 1
+	}, 5*time.Second, time.Millisecond)
+	latent.SetLatency(0)
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/d", Value: []byte("bar")}))
+	keys, err := cache.List(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c", "d"}, keys)
+	require.NoError(t, <-done)
+	require.Equal(t, map[string]int{"foo/": 2}, backend.reset())
+
+	// Waiters are not failed by the context of the caller leading the call
+	// ending
+	gated := newGatedBackend(inm)
+	cache = physical.NewCache(gated, 0, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader := make(chan error, 1)
+	go func() {
+		_, err := cache.List(leaderCtx, "foo/")
+		leader <- err
+	}()
+	<-gated.started
+
+	waiter := make(chan error, 1)
+	go func() {
+		keys, err := cache.List(ctx, "foo/")
+		if err == nil && len(keys) != 4 {
+			err = fmt.Errorf("unexpected keys %v", keys)
+		}
+		waiter <- err
+	}()
+	// Give the waiter time to join the call in flight
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(gated.gate)
+	require.NoError(t, <-waiter)
+	<-leader
+}
+
+func TestCache_ExportImport(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	source := physical.NewCacheWithConfig(inm, physical.CacheConfig{
+		Compression: compressutil.CompressionTypeLZ4,
+	}, logger, &metrics.BlackholeSink{})
+	source.SetEnabled(true)
+
+	entries := []*physical.Entry{
+		{Key: "foo", Value: []byte("bar")},
+		{Key: "empty", Value: []byte{}},
+		{Key: "sealed", Value: []byte("ciphertext"), SealWrap: true, ValueHash: []byte("hash")},
+		{Key: "versioned", Value: []byte("v2"), Version: 2},
+		{Key: "large", Value: policyLikeValue(4096, 1)},
+		{Key: "core/mounts", Value: []byte("mounts")},
+		{Key: "secret/a", Value: []byte("a")},
+	}
+	for _, entry := range entries {
+		require.NoError(t, source.Put(ctx, entry))
+	}
+	source.Pin("core/mounts")
+	_, err = source.Get(ctx, "missing")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, source.Export(ctx, &buf))
+	export := buf.Bytes()
+
+	// Every cached entry round-trips, reaching the new cache without reading
+	// its backend
+	empty, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	flaky := &flakyBackend{Backend: empty}
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	dest := physical.NewCacheWithConfig(flaky, physical.CacheConfig{}, logger, sink)
+	dest.SetEnabled(true)
+	require.NoError(t, dest.Import(ctx, bytes.NewReader(export)))
+	require.EqualValues(t, len(entries), sink.Data()[0].Counters["cache.import"].Sum)
+
+	for _, entry := range entries {
+		got, err := dest.Get(ctx, entry.Key)
+		require.NoError(t, err)
+		require.Equal(t, entry, got)
+	}
+	require.Zero(t, flaky.gets.Load())
+
+	sourceDump, err := source.Dump(ctx)
+	require.NoError(t, err)
+	var want []physical.CacheEntryInfo
+	for _, info := range sourceDump {
+		if !info.Negative {
+			info.Pinned = false
+			want = append(want, info)
+		}
+	}
+	destDump, err := dest.Dump(ctx)
+	require.NoError(t, err)
+	require.Equal(t, want, destDump)
+
+	// Cached keys are not replaced, and uncacheable keys are skipped
+	dest = physical.NewCacheWithConfig(flaky, physical.CacheConfig{}, logger, &metrics.BlackholeSink{})
+	dest.SetEnabled(true)
+	dest.AddCacheExceptions([]string{"secret/"})
+	require.NoError(t, dest.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("newer")}))
+	require.NoError(t, dest.Import(ctx, bytes.NewReader(export)))
+	entry, ok := dest.Peek("foo")
+	require.True(t, ok)
+	require.Equal(t, []byte("newer"), entry.Value)
+	_, ok = dest.Peek("secret/a")
+	require.False(t, ok)
+	_, ok = dest.Peek("versioned")
+	require.True(t, ok)
+
+	// Nothing is imported into a disabled cache
+	dest = physical.NewCacheWithConfig(flaky, physical.CacheConfig{}, logger, &metrics.BlackholeSink{})
+	require.NoError(t, dest.Import(ctx, bytes.NewReader(export)))
+	require.Zero(t, dest.Stats().Entries)
+
+	// Imports are bounded by the size of the cache
+	sink = metrics.NewInmemSink(time.Minute, time.Minute)
+	dest = physical.NewCacheWithConfig(flaky, physical.CacheConfig{Size: 3, Shards: 1}, logger, sink)
+	dest.SetEnabled(true)
+	require.NoError(t, dest.Import(ctx, bytes.NewReader(export)))
+	require.Equal(t, 3, dest.Stats().Entries)
+	require.EqualValues(t, 3, sink.Data()[0].Counters["cache.import"].Sum)
+
+	// Malformed imports are rejected
+	for name, input := range map[string]string{
+		"empty":    "",
+		"header":   "not json\n",
+		"version":  `{"version":2}` + "\n",
+		"entry":    `{"version":1}` + "\nnot json\n",
+		"key":      `{"version":1}` + "\n" + `{"value":"YmFy"}` + "\n",
+		"too long": `{"version":1}` + "\n" + `{"key":"foo","value":"` + strings.Repeat("A", 8*1024*1024) + `"}` + "\n",
+	} {
+		dest = physical.NewCacheWithConfig(flaky, physical.CacheConfig{}, logger, &metrics.BlackholeSink{})
+		dest.SetEnabled(true)
+		require.Error(t, dest.Import(ctx, strings.NewReader(input)), name)
+		require.Zero(t, dest.Stats().Entries, name)
+	}
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, source.Export(canceledCtx, io.Discard), context.Canceled)
+	require.ErrorIs(t, dest.Import(canceledCtx, bytes.NewReader(export)), context.Canceled)
+}
+
+func TestCache_InvalidateSealWrapped(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache, err := physical.NewCacheWithSpill(inm, 4, physical.SpillConfig{Path: filepath.Join(t.TempDir(), "spill.db")}, logger, sink)
+	require.NoError(t, err)
+	defer cache.Stop()
+	cache.SetEnabled(true)
+
+	counter := func(name string) int {
+		intervals := sink.Data()
+		intervals[0].RLock()
+		defer intervals[0].RUnlock()
+		if c, ok := intervals[0].Counters[name]; ok {
+			return int(c.Sum)
+		}
+		return 0
+	}
+
+	// Seed wrapped and unwrapped entries in the spill file, in memory and
+	// pinned
+	wrapped := []string{"spilled/wrapped", "mem/wrapped", "core/wrapped"}
+	plain := []string{"spilled/plain", "mem/plain", "core/plain"}
+	put := func(key string) {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: key, Value: []byte("old"), SealWrap: strings.HasSuffix(key, "/wrapped")}))
+	}
+	put("spilled/wrapped")
+	put("spilled/plain")
+	for i := 0; i < 4; i++ {
+		put(fmt.Sprintf("filler/%d", i))
+	}
+	put("mem/wrapped")
+	put("mem/plain")
+	require.Eventually(t, func() bool {
+		return counter("cache.spill.write") == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, key := range []string{"core/wrapped", "core/plain"} {
+		put(key)
+		cache.Pin(key)
+	}
+
+	// Rewrap values in the backend, and change the others so that serving
+	// them from the cache can be told apart
+	for _, key := range append(wrapped, plain...) {
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: key, Value: []byte("new"), SealWrap: strings.HasSuffix(key, "/wrapped")}))
+	}
+
+	require.Equal(t, 2, cache.InvalidateSealWrapped())
+	require.Equal(t, 2, counter("cache.invalidate_seal_wrapped"))
+
+	for _, key := range wrapped {
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, []byte("new"), entry.Value, key)
+	}
+	for _, key := range plain {
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, []byte("old"), entry.Value, key)
+	}
+}
+
+// slashFoldingBackend treats keys with and without a trailing slash as the
+// same key, counting reads.
+type slashFoldingBackend struct {
+	physical.Backend
+
+	gets atomic.Int64
+}
+
+func (b *slashFoldingBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	b.gets.Add(1)
+	return b.Backend.Get(ctx, strings.TrimSuffix(key, "/"))
+}
+
+func (b *slashFoldingBackend) Put(ctx context.Context, entry *physical.Entry) error {
+	folded := *entry
+	folded.Key = strings.TrimSuffix(entry.Key, "/")
+	return b.Backend.Put(ctx, &folded)
+}
+
+func (b *slashFoldingBackend) Delete(ctx context.Context, key string) error {
+	return b.Backend.Delete(ctx, strings.TrimSuffix(key, "/"))
+}
+
+func TestCache_KeyNormalizer(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	backend := &slashFoldingBackend{Backend: inm}
+	cache := physical.NewCacheWithConfig(backend, physical.CacheConfig{
+		KeyNormalizer: func(key string) string {
+			return strings.TrimSuffix(key, "/")
+		},
+	}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	// Both forms of the key are served from the same cached entry
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo/", Value: []byte("bar")}))
+	for _, key := range []string{"foo", "foo/"} {
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, entry, key)
+		require.Equal(t, []byte("bar"), entry.Value)
+	}
+	require.Equal(t, int64(0), backend.gets.Load())
+
+	// A write through either form replaces it
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("baz")}))
+	entry, err := cache.Get(ctx, "foo/")
+	require.NoError(t, err)
+	require.Equal(t, []byte("baz"), entry.Value)
+
+	// As does a delete, leaving no stale copy behind
+	require.NoError(t, cache.Delete(ctx, "foo/"))
+	entry, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	require.Equal(t, int64(1), backend.gets.Load())
+
+	// Misses are cached once for both forms too
+	entry, err = cache.Get(ctx, "foo/")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	require.Equal(t, int64(1), backend.gets.Load())
+
+	// Cache exceptions match the normalized key
+	require.True(t, cache.ShouldCache("foo/"))
+	require.False(t, cache.ShouldCache("core/seal-config/"))
+	cache.AddCacheExceptions([]string{"secret/stream"})
+	require.False(t, cache.ShouldCache("secret/stream/"))
+	require.True(t, cache.ShouldCache("secret/other/"))
+
+	// Keys are reported in their normalized form
+	cache.Pin("pinned/")
+	require.Equal(t, []string{"pinned"}, cache.PinnedKeys())
+}
+
+func TestCache_DisabledPassthrough(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	cache := physical.NewCache(inm, 0, logger, sink)
+
+	passthroughs := func() int {
+		intervals := sink.Data()
+		intervals[0].RLock()
+		defer intervals[0].RUnlock()
+		if c, ok := intervals[0].Counters["cache.disabled_passthrough"]; ok {
+			return int(c.Sum)
+		}
+		return 0
+	}
+
+	// Every request is counted while the cache is disabled
+	require.False(t, cache.ShouldCache("foo"))
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	_, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, 3, passthroughs())
+
+	// Keys excluded from the cache are not
+	cache.SetEnabled(true)
+	require.False(t, cache.ShouldCache("core/seal-config"))
+	_, err = cache.Get(ctx, "core/seal-config")
+	require.NoError(t, err)
+	_, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, 3, passthroughs())
+}
+
+func TestCache_OnInvalidate(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	local := physical.NewTransactionalCache(inm.(physical.TransactionalBackend), 0, logger, &metrics.BlackholeSink{})
+	local.SetEnabled(true)
+	peer := physical.NewCache(inm, 0, logger, &metrics.BlackholeSink{})
+	peer.SetEnabled(true)
+
+	// Wire invalidations from the local cache through to its peer, as a
+	// cluster layer would
+	var lock sync.Mutex
+	var invalidated []string
+	local.OnInvalidate(func(key string) {
+		lock.Lock()
+		invalidated = append(invalidated, key)
+		lock.Unlock()
+	})
+	local.OnInvalidate(peer.Invalidate)
+	reported := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		keys := invalidated
+		invalidated = nil
+		sort.Strings(keys)
+		return keys
+	}
+
+	peerValue := func(key string) []byte {
+		t.Helper()
+		entry, err := peer.Get(ctx, key)
+		require.NoError(t, err)
+		if entry == nil {
+			return nil
+		}
+		return entry.Value
+	}
+
+	require.NoError(t, local.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("old")}))
+	require.Equal(t, []string{"foo"}, reported())
+	require.Equal(t, []byte("old"), peerValue("foo"))
+
+	// Each write fires the callbacks once, and the peer reads it afresh
+	require.NoError(t, local.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("new")}))
+	require.Equal(t, []string{"foo"}, reported())
+	require.Equal(t, []byte("new"), peerValue("foo"))
+
+	require.NoError(t, local.Delete(ctx, "foo"))
+	require.Equal(t, []string{"foo"}, reported())
+	require.Nil(t, peerValue("foo"))
+
+	// Failed writes do not
+	inm.(*TransactionalInmemBackend).FailPut(true)
+	require.Error(t, local.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("failed")}))
+	inm.(*TransactionalInmemBackend).FailPut(false)
+	require.Empty(t, reported())
+
+	// Committed transactions report each key written once, while rolled back
+	// ones report nothing
+	txn, err := local.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "bar", Value: []byte("bar")}))
+	require.NoError(t, txn.Rollback(ctx))
+	require.Empty(t, reported())
+
+	txn, err = local.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("txn")}))
+	require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("txn2")}))
+	require.NoError(t, txn.Delete(ctx, "bar"))
+	require.Nil(t, peerValue("foo"))
+	require.NoError(t, txn.Commit(ctx))
+	require.Equal(t, []string{"bar", "foo"}, reported())
+	require.Equal(t, []byte("txn2"), peerValue("foo"))
+
+	// Invalidating a peer does not report the key again
+	peer.OnInvalidate(func(key string) {
+		t.Errorf("unexpected invalidation of %q", key)
+	})
+	peer.Invalidate("foo")
+	require.Equal(t, []byte("txn2"), peerValue("foo"))
+}
+
+func TestCache_Scan(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	backend := &countingBackend{Backend: inm}
+	cache := physical.NewCacheWithConfig(backend, physical.CacheConfig{Size: 4}, logger, &metrics.BlackholeSink{})
+	cache.SetEnabled(true)
+
+	scanCtx := physical.CacheScanContext(ctx, true)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, cache.Put(ctx, &physical.Entry{Key: fmt.Sprintf("hot/%d", i), Value: []byte("hot")}))
+	}
+	for i := 0; i < 8; i++ {
+		require.NoError(t, inm.Put(ctx, &physical.Entry{Key: fmt.Sprintf("cold/%d", i), Value: []byte("cold")}))
+	}
+
+	// Scanned keys are read from the backend without being cached, so the
+	// hot set survives a scan larger than the cache
+	for i := 0; i < 6; i++ {
+		entry, err := cache.Get(scanCtx, fmt.Sprintf("cold/%d", i))
+		require.NoError(t, err)
+		require.Equal(t, []byte("cold"), entry.Value)
+	}
+	entries, err := cache.BatchGet(scanCtx, []string{"cold/6", "cold/7", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("cold"), entries[0].Value)
+	require.Equal(t, []byte("cold"), entries[1].Value)
+	require.Nil(t, entries[2])
+	for i := 0; i < 8; i++ {
+		_, ok := cache.Peek(fmt.Sprintf("cold/%d", i))
+		require.False(t, ok)
+	}
+	_, ok := cache.Peek("missing")
+	require.False(t, ok)
+	for i := 0; i < 4; i++ {
+		_, ok := cache.Peek(fmt.Sprintf("hot/%d", i))
+		require.True(t, ok)
+	}
+
+	// Cached keys are still served from the cache
+	gets := backend.gets.Load()
+	entry, err := cache.Get(scanCtx, "hot/0")
+	require.NoError(t, err)
+	require.Equal(t, []byte("hot"), entry.Value)
+	require.Equal(t, gets, backend.gets.Load())
+
+	// Without the flag, reads are cached as usual
+	_, err = cache.Get(physical.CacheScanContext(ctx, false), "cold/0")
+	require.NoError(t, err)
+	_, ok = cache.Peek("cold/0")
+	require.True(t, ok)
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksummer(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewDirectInmem(nil, logger)
+	require.NoError(t, err)
+	c := physical.NewChecksummer(inm)
+	physical.ExerciseBackend(t, c)
+	physical.ExerciseBackend_ListPrefix(t, c)
+
+	ctx := context.Background()
+	require.NoError(t, c.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	// Values are stored framed by their checksum, which is verified and
+	// removed when they are read back
+	raw, err := inm.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("bar"), raw.Value)
+	out, err := c.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), out.Value)
+	require.Nil(t, out.ValueHash)
+
+	// A corrupted value fails the read with a typed error
+	raw.Value[len(raw.Value)-1] ^= 0x01
+	require.NoError(t, inm.Put(ctx, raw))
+	_, err = c.Get(ctx, "foo")
+	var checksumErr *physical.ChecksumError
+	require.ErrorAs(t, err, &checksumErr)
+	require.ErrorIs(t, err, physical.ErrChecksumMismatch)
+	require.Equal(t, "foo", checksumErr.Key)
+	require.Equal(t, "storage", checksumErr.Source)
+
+	// As does a value moved to another key
+	require.NoError(t, c.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	raw, err = inm.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "moved", Value: raw.Value}))
+	_, err = c.Get(ctx, "moved")
+	require.ErrorIs(t, err, physical.ErrChecksumMismatch)
+
+	// Values written before checksums were enabled are returned unverified
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "legacy", Value: []byte("old")}))
+	out, err = c.Get(ctx, "legacy")
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), out.Value)
+	require.Nil(t, out.ValueHash)
+}
+
+func TestChecksummer_Transactional(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	c := physical.NewChecksummer(inm)
+	txnBackend, ok := c.(physical.TransactionalBackend)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	txn, err := txnBackend.BeginTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, txn.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	out, err := txn.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), out.Value)
+	require.NoError(t, txn.Commit(ctx))
+
+	// Writes made in the transaction are framed in storage
+	raw, err := inm.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("bar"), raw.Value)
+	out, err = c.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), out.Value)
+}
+
+type exemptBackend struct {
+	physical.Backend
+}
+
+func (exemptBackend) SkipChecksums() bool { return true }
+
+func TestChecksummer_Exempt(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewDirectInmem(nil, logger)
+	require.NoError(t, err)
+	b := exemptBackend{Backend: inm}
+	require.Equal(t, physical.Backend(b), physical.NewChecksummer(b))
+}
+
+func TestCache_VerifyChecksums(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	sink := metrics.NewInmemSink(1000000, 1000000)
+	cache := physical.NewCacheWithConfig(physical.NewChecksummer(inm), physical.CacheConfig{
+		ImmutableValues: true,
+		VerifyChecksums: true,
+	}, logger, sink)
+	cache.SetEnabled(true)
+	physical.ExerciseBackend(t, cache)
+
+	ctx := context.Background()
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	// Flip a bit of the value held in memory, which is shared with readers
+	// as values are immutable
+	out, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	out.Value[0] ^= 0x01
+
+	// The corrupt copy is caught and the entry read again from storage
+	out, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), out.Value)
+	require.Nil(t, out.ValueHash)
+
+	intervals := sink.Data()
+	require.Len(t, intervals, 1)
+	require.Contains(t, intervals[0].Counters, "cache.checksum_mismatch")
+
+	// Entries with a hash of the backend's own are not verified
+	require.NoError(t, cache.Put(ctx, &physical.Entry{Key: "own", Value: []byte("bar"), ValueHash: []byte("other")}))
+	out, err = cache.Get(ctx, "own")
+	require.NoError(t, err)
+	require.Equal(t, []byte("other"), out.ValueHash)
+	out.Value[0] ^= 0x01
+	out, err = cache.Get(ctx, "own")
+	require.NoError(t, err)
+	require.NotEqual(t, []byte("bar"), out.Value)
+}
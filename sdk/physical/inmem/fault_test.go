@@ -0,0 +1,139 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjector_RequiresUnsafe(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	_, err = physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{}, logger)
+	require.ErrorIs(t, err, physical.ErrFaultInjectionDisabled)
+
+	_, err = physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{
+		AllowUnsafe: true,
+		Operations: map[physical.Operation]*physical.OperationFaults{
+			physical.GetOperation: {FailPercent: 120},
+		},
+	}, logger)
+	require.Error(t, err)
+
+	// Without faults, it behaves like the underlying backend.
+	f, err := physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{AllowUnsafe: true}, logger)
+	require.NoError(t, err)
+	physical.ExerciseBackend(t, f)
+	physical.ExerciseBackend_ListPrefix(t, f)
+}
+
+func TestFaultInjector_Deterministic(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	run := func(seed int64) []bool {
+		inm, err := NewInmem(nil, logger)
+		require.NoError(t, err)
+		f, err := physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{
+			Seed:        seed,
+			AllowUnsafe: true,
+			Operations: map[physical.Operation]*physical.OperationFaults{
+				physical.GetOperation: {FailPercent: 30},
+				physical.PutOperation: {FailPercent: 50},
+			},
+		}, logger)
+		require.NoError(t, err)
+
+		var failures []bool
+		for i := 0; i < 200; i++ {
+			_, err := f.Get(ctx, "foo")
+			failures = append(failures, err != nil)
+			if err != nil {
+				require.ErrorIs(t, err, physical.ErrInjectedFault)
+			}
+
+			// Calls of other operation types do not change the decisions
+			// taken for gets.
+			if i%3 == 0 {
+				f.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")})
+			}
+		}
+		return failures
+	}
+
+	first := run(42)
+	require.Equal(t, first, run(42))
+	require.NotEqual(t, first, run(43))
+
+	var failed int
+	for _, fail := range first {
+		if fail {
+			failed++
+		}
+	}
+	require.InDelta(t, 60, failed, 25)
+}
+
+func TestFaultInjector_Timeout(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	f, err := physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{
+		AllowUnsafe: true,
+		Operations: map[physical.Operation]*physical.OperationFaults{
+			physical.GetOperation: {FailPercent: 100, FailWith: physical.FaultTimeout, FailAfter: 50 * time.Millisecond},
+		},
+	}, logger)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = f.Get(ctx, "foo")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, err, physical.ErrInjectedFault)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The caller's deadline is honored.
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = f.Get(shortCtx, "foo")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.False(t, errors.Is(err, physical.ErrInjectedFault))
+
+	// Other operations are passed through, and faults can be removed.
+	require.NoError(t, f.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	require.NoError(t, f.SetFaults(physical.GetOperation, nil))
+	entry, err := f.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", string(entry.Value))
+}
+
+func TestFaultInjector_Throttle(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	f, err := physical.NewFaultInjector(inm, &physical.FaultInjectionConfig{AllowUnsafe: true}, logger)
+	require.NoError(t, err)
+	require.NoError(t, f.SetFaults(physical.ListOperation, &physical.OperationFaults{RateLimit: 1, RateBurst: 3}))
+
+	for i := 0; i < 3; i++ {
+		_, err := f.List(ctx, "")
+		require.NoError(t, err)
+	}
+	_, err = f.ListPage(ctx, "", "", 10)
+	require.ErrorIs(t, err, physical.ErrInjectedFault)
+}
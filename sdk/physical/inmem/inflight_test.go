@@ -0,0 +1,116 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingBackend blocks every Get until released, and panics on Delete.
+type blockingBackend struct {
+	physical.Backend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBackend) Get(ctx context.Context, key string) (*physical.Entry, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.Backend.Get(ctx, key)
+}
+
+func (b *blockingBackend) Delete(ctx context.Context, key string) error {
+	panic("delete failed")
+}
+
+func TestInFlightTracker(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	tracker, err := physical.NewInFlightTracker(inm, physical.InFlightConfig{MaxConcurrency: 4}, &metrics.BlackholeSink{})
+	require.NoError(t, err)
+	physical.ExerciseBackend(t, tracker)
+	physical.ExerciseBackend_ListPrefix(t, tracker)
+	physical.ExerciseBackend_CompareAndSwap(t, tracker)
+
+	_, err = physical.NewInFlightTracker(inm, physical.InFlightConfig{MaxConcurrency: -1}, &metrics.BlackholeSink{})
+	require.Error(t, err)
+}
+
+func TestInFlightTracker_Concurrency(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	backend := &blockingBackend{
+		Backend: inm,
+		started: make(chan struct{}, 2),
+		release: make(chan struct{}),
+	}
+
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	tracker, err := physical.NewInFlightTracker(backend, physical.InFlightConfig{MaxConcurrency: 1}, sink)
+	require.NoError(t, err)
+
+	// The first read takes the only slot
+	errCh := make(chan error, 2)
+	go func() {
+		_, err := tracker.Get(ctx, "foo")
+		errCh <- err
+	}()
+	<-backend.started
+	require.Equal(t, 1, tracker.InFlight(physical.InFlightOpGet))
+
+	// Waiting respects the deadline of the context
+	deadlineCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = tracker.List(deadlineCtx, "")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 0, tracker.QueueDepth())
+	require.Equal(t, 0, tracker.InFlight(physical.InFlightOpList))
+
+	// Other operations queue until the slot is released
+	go func() {
+		_, err := tracker.Get(ctx, "foo")
+		errCh <- err
+	}()
+	require.Eventually(t, func() bool {
+		return tracker.QueueDepth() == 1
+	}, time.Second, 5*time.Millisecond)
+
+	intervals := sink.Data()
+	gauge, ok := intervals[len(intervals)-1].Gauges["storage.queue_depth"]
+	require.True(t, ok)
+	require.Equal(t, float32(1), gauge.Value)
+	gauge, ok = intervals[len(intervals)-1].Gauges["storage.in_flight;operation=get"]
+	require.True(t, ok)
+	require.Equal(t, float32(1), gauge.Value)
+
+	backend.release <- struct{}{}
+	<-backend.started
+	require.Equal(t, 0, tracker.QueueDepth())
+	backend.release <- struct{}{}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-errCh)
+	}
+	require.Equal(t, 0, tracker.InFlight(physical.InFlightOpGet))
+
+	// Operations which panic still release their slot
+	require.Panics(t, func() {
+		_ = tracker.Delete(ctx, "foo")
+	})
+	require.Equal(t, 0, tracker.InFlight(physical.InFlightOpDelete))
+	require.NoError(t, tracker.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+}
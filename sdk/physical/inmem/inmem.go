@@ -21,9 +21,10 @@ import (
 
 // Verify interfaces are satisfied
 var (
-	_ physical.Backend   = (*InmemBackend)(nil)
-	_ physical.HABackend = (*InmemHABackend)(nil)
-	_ physical.Lock      = (*InmemLock)(nil)
+	_ physical.Backend    = (*InmemBackend)(nil)
+	_ physical.CASBackend = (*InmemBackend)(nil)
+	_ physical.HABackend  = (*InmemHABackend)(nil)
+	_ physical.Lock       = (*InmemLock)(nil)
 )
 
 var (
@@ -199,6 +200,28 @@ func (i *InmemBackend) PutInternal(ctx context.Context, entry *physical.Entry) e
 	return nil
 }
 
+// CompareAndSwap atomically writes the entry if the current value of its key
+// matches the expected hash, see physical.CompareAndSwap
+func (i *InmemBackend) CompareAndSwap(ctx context.Context, entry *physical.Entry, expectedHash []byte) (bool, error) {
+	i.permitPool.Acquire()
+	defer i.permitPool.Release()
+
+	i.Lock()
+	defer i.Unlock()
+
+	current, err := i.GetInternal(ctx, entry.Key)
+	if err != nil {
+		return false, err
+	}
+	if !physical.MatchesHash(current, expectedHash) {
+		return false, nil
+	}
+	if err := i.PutInternal(ctx, entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (i *InmemBackend) FailPut(fail bool) {
 	var val uint32
 	if fail {
@@ -433,6 +456,34 @@ func (i *InmemBackendTransaction) Put(ctx context.Context, entry *physical.Entry
 	i.txLock.Lock()
 	defer i.txLock.Unlock()
 
+	return i.put(ctx, entry)
+}
+
+// CompareAndSwap writes the entry within the transaction if the current value
+// of its key matches the expected hash, see physical.CompareAndSwap
+func (i *InmemBackendTransaction) CompareAndSwap(ctx context.Context, entry *physical.Entry, expectedHash []byte) (bool, error) {
+	i.txLock.Lock()
+	defer i.txLock.Unlock()
+
+	if i.finishedTx {
+		return false, physical.ErrTransactionAlreadyCommitted
+	}
+
+	current, err := i.InmemBackend.Get(ctx, entry.Key)
+	if err != nil {
+		return false, err
+	}
+	if !physical.MatchesHash(current, expectedHash) {
+		return false, nil
+	}
+	if err := i.put(ctx, entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// put writes the entry within the transaction; callers must hold txLock.
+func (i *InmemBackendTransaction) put(ctx context.Context, entry *physical.Entry) error {
 	if !i.writable {
 		return physical.ErrTransactionReadOnly
 	}
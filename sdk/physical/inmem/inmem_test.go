@@ -4,6 +4,7 @@
 package inmem
 
 import (
+	"context"
 	"testing"
 
 	log "github.com/hashicorp/go-hclog"
@@ -21,3 +22,24 @@ func TestInmem(t *testing.T) {
 	physical.ExerciseBackend(t, inm)
 	physical.ExerciseBackend_ListPrefix(t, inm)
 }
+
+func TestInmem_CompareAndSwap(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	physical.ExerciseBackend_CompareAndSwap(t, inm)
+
+	// Backends without native support fall back to a locked Get and Put
+	physical.ExerciseBackend_CompareAndSwap(t, struct{ physical.Backend }{inm})
+
+	// Transactions swap within the transaction
+	tx, err := inm.(physical.TransactionalBackend).BeginTx(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(context.Background())
+	physical.ExerciseBackend_CompareAndSwap(t, tx)
+}
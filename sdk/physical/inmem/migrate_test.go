@@ -0,0 +1,247 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+// faultyBackend fails writes to some keys, or all writes after a number of
+// them succeeded.
+type faultyBackend struct {
+	physical.Backend
+
+	l         sync.Mutex
+	puts      int
+	failAfter int
+	failKeys  map[string]struct{}
+}
+
+func (f *faultyBackend) Put(ctx context.Context, entry *physical.Entry) error {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if _, ok := f.failKeys[entry.Key]; ok {
+		return errors.New("injected failure")
+	}
+	if f.failAfter > 0 && f.puts >= f.failAfter {
+		return errors.New("injected failure")
+	}
+	f.puts++
+	return f.Backend.Put(ctx, entry)
+}
+
+func (f *faultyBackend) putCount() int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.puts
+}
+
+func newMigrationSource(t *testing.T, count int) physical.Backend {
+	source, err := NewInmem(nil, logging.NewVaultLogger(log.Debug))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("logical/%d/%04d", i%7, i)
+		require.NoError(t, source.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+	}
+	require.NoError(t, source.Put(ctx, &physical.Entry{Key: "core/seal-config", Value: []byte("seal")}))
+	require.NoError(t, source.Put(ctx, &physical.Entry{Key: "root-key", Value: []byte("root")}))
+	return source
+}
+
+func allKeys(t *testing.T, b physical.Backend) map[string]string {
+	ret := make(map[string]string)
+	ctx := context.Background()
+	var walk func(prefix string)
+	walk = func(prefix string) {
+		keys, err := b.List(ctx, prefix)
+		require.NoError(t, err)
+		for _, key := range keys {
+			if strings.HasSuffix(key, "/") {
+				walk(prefix + key)
+				continue
+			}
+			entry, err := b.Get(ctx, prefix+key)
+			require.NoError(t, err)
+			ret[prefix+key] = string(entry.Value)
+		}
+	}
+	walk("")
+	delete(ret, physical.MigrationProgressKey)
+	return ret
+}
+
+func waitForMigration(t *testing.T, m *physical.Migrator) {
+	require.Eventually(t, m.Complete, 30*time.Second, 10*time.Millisecond)
+}
+
+func TestMigrator(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	source := newMigrationSource(t, 100)
+	target, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	m, err := physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+	require.False(t, m.Complete())
+
+	// Writes and deletes issued before the copy reaches the keys are
+	// applied to both backends, and deleted keys are not brought back.
+	require.NoError(t, m.Put(ctx, &physical.Entry{Key: "logical/0/0000", Value: []byte("updated")}))
+	require.NoError(t, m.Delete(ctx, "logical/1/0001"))
+	require.NoError(t, m.Put(ctx, &physical.Entry{Key: "sys/new", Value: []byte("new")}))
+
+	// Reads are served from the source until the migration completes.
+	entry, err := m.Get(ctx, "logical/2/0002")
+	require.NoError(t, err)
+	require.Equal(t, "logical/2/0002", string(entry.Value))
+	keys, err := m.List(ctx, "")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"core/", "logical/", "root-key", "sys/"}, keys)
+
+	m.Start()
+	defer m.Stop()
+	waitForMigration(t, m)
+
+	expected := allKeys(t, source)
+	require.Len(t, expected, 102)
+	require.Equal(t, "updated", expected["logical/0/0000"])
+	require.NotContains(t, expected, "logical/1/0001")
+	require.Equal(t, expected, allKeys(t, target))
+
+	progress := m.Progress()
+	require.True(t, progress.Complete)
+	require.Len(t, progress.Prefixes, 3)
+	for prefix, prog := range progress.Prefixes {
+		require.True(t, prog.Done, prefix)
+	}
+
+	// Once complete, reads come from the target, with the progress record
+	// hidden, and writes are still mirrored to the source.
+	keys, err = m.List(ctx, "")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"core/", "logical/", "root-key", "sys/"}, keys)
+	keys, err = m.ListPage(ctx, "", "", 4)
+	require.NoError(t, err)
+	require.Equal(t, []string{"core/", "logical/", "root-key", "sys/"}, keys)
+
+	require.NoError(t, m.Delete(ctx, "root-key"))
+	entry, err = source.Get(ctx, "root-key")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	require.Equal(t, allKeys(t, source), allKeys(t, target))
+
+	// Restarting with the same backends keeps serving from the target.
+	m2, err := physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+	require.True(t, m2.Complete())
+}
+
+func TestMigrator_TargetNotEmpty(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	target, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	require.NoError(t, target.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	_, err = physical.NewMigrator(ctx, newMigrationSource(t, 1), target, logger)
+	require.ErrorIs(t, err, physical.ErrMigrationTargetNotEmpty)
+}
+
+func TestMigrator_Resume(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	source := newMigrationSource(t, 1000)
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	// The target becomes unavailable part way through the copy.
+	target := &faultyBackend{Backend: inm, failAfter: 600}
+	m, err := physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+	m.Start()
+	require.Eventually(t, func() bool { return target.putCount() >= 600 }, 30*time.Second, 10*time.Millisecond)
+
+	// Writes which cannot be mirrored nor recorded in the target are
+	// reverted and failed.
+	require.Error(t, m.Put(ctx, &physical.Entry{Key: "logical/0/0000", Value: []byte("updated")}))
+	entry, err := m.Get(ctx, "logical/0/0000")
+	require.NoError(t, err)
+	require.Equal(t, "logical/0/0000", string(entry.Value))
+	m.Stop()
+	require.False(t, m.Complete())
+
+	// After a restart, the copy resumes from the last saved cursor.
+	target = &faultyBackend{Backend: inm}
+	m, err = physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+	progress := m.Progress()
+	require.NotEmpty(t, progress.Prefixes["logical/"].Cursor)
+	require.False(t, progress.Prefixes["logical/"].Done)
+
+	m.Start()
+	defer m.Stop()
+	waitForMigration(t, m)
+
+	require.Less(t, target.putCount(), 1000)
+	require.Equal(t, allKeys(t, source), allKeys(t, inm))
+}
+
+func TestMigrator_DirtyKeys(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	source := newMigrationSource(t, 10)
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	target := &faultyBackend{Backend: inm, failKeys: map[string]struct{}{"sys/flaky": {}}}
+
+	m, err := physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+
+	// A write failing to be mirrored is still served from the source, and
+	// the key is queued to be copied again.
+	require.NoError(t, m.Put(ctx, &physical.Entry{Key: "sys/flaky", Value: []byte("v1")}))
+	entry, err := m.Get(ctx, "sys/flaky")
+	require.NoError(t, err)
+	require.Equal(t, "v1", string(entry.Value))
+	require.Equal(t, []string{"sys/flaky"}, m.Progress().Dirty)
+
+	// The dirty key is persisted, so a restart does not forget it.
+	m, err = physical.NewMigrator(ctx, source, target, logger)
+	require.NoError(t, err)
+	require.Equal(t, []string{"sys/flaky"}, m.Progress().Dirty)
+
+	// The migration does not complete while the key cannot be copied.
+	m.Start()
+	defer m.Stop()
+	require.Never(t, m.Complete, 200*time.Millisecond, 10*time.Millisecond)
+
+	target.l.Lock()
+	target.failKeys = nil
+	target.l.Unlock()
+	waitForMigration(t, m)
+
+	actual := allKeys(t, inm)
+	require.Equal(t, allKeys(t, source), actual)
+	require.Equal(t, "v1", actual["sys/flaky"])
+	require.Empty(t, m.Progress().Dirty)
+}
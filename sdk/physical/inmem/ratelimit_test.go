@@ -0,0 +1,115 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	limiter, err := physical.NewRateLimiter(inm, physical.RateLimiterConfig{Rate: 1e6, Burst: 1000, LimitLists: true}, &metrics.BlackholeSink{})
+	require.NoError(t, err)
+	physical.ExerciseBackend(t, limiter)
+	physical.ExerciseBackend_ListPrefix(t, limiter)
+	physical.ExerciseBackend_CompareAndSwap(t, limiter)
+
+	_, err = physical.NewRateLimiter(inm, physical.RateLimiterConfig{}, &metrics.BlackholeSink{})
+	require.Error(t, err)
+}
+
+func TestRateLimiter_Pacing(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	limiter, err := physical.NewRateLimiter(inm, physical.RateLimiterConfig{Rate: 50, Burst: 2}, &metrics.BlackholeSink{})
+	require.NoError(t, err)
+
+	// The burst goes through right away, later writes are paced
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, limiter.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+	}
+	require.Less(t, time.Since(start), 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Delete(ctx, "foo"))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// Reads and listings are not limited
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		_, err := limiter.Get(ctx, "foo")
+		require.NoError(t, err)
+		_, err = limiter.List(ctx, "")
+		require.NoError(t, err)
+	}
+	require.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestRateLimiter_Queue(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	limiter, err := physical.NewRateLimiter(inm, physical.RateLimiterConfig{Rate: 0.01, MaxQueued: 1}, sink)
+	require.NoError(t, err)
+
+	// Use up the only token
+	require.NoError(t, limiter.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	// Waiting respects the deadline of the context
+	deadlineCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, limiter.Put(deadlineCtx, &physical.Entry{Key: "foo", Value: []byte("baz")}))
+	require.Equal(t, 0, limiter.QueueDepth())
+
+	// Operations over the cap fail fast rather than queuing
+	waitCtx, cancelWait := context.WithCancel(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- limiter.Delete(waitCtx, "foo")
+	}()
+	require.Eventually(t, func() bool {
+		return limiter.QueueDepth() == 1
+	}, time.Second, 5*time.Millisecond)
+	require.ErrorIs(t, limiter.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("baz")}), physical.ErrRateLimitQueueFull)
+
+	intervals := sink.Data()
+	gauge, ok := intervals[len(intervals)-1].Gauges["storage.rate_limit.queue_depth"]
+	require.True(t, ok)
+	require.Equal(t, float32(1), gauge.Value)
+
+	// Waiting operations are released when their context is canceled
+	cancelWait()
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("waiting operation was not released")
+	}
+	require.Equal(t, 0, limiter.QueueDepth())
+
+	out, err := inm.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "bar", string(out.Value))
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "foo", Value: []byte("bar")}))
+
+	ro := physical.NewReadOnly(inm)
+	require.True(t, ro.IsReadOnly())
+
+	// Writes are rejected without reaching the backend
+	require.ErrorIs(t, ro.Put(ctx, &physical.Entry{Key: "baz", Value: []byte("qux")}), physical.ErrReadOnly)
+	require.ErrorIs(t, ro.Delete(ctx, "foo"), physical.ErrReadOnly)
+	out, err := inm.Get(ctx, "baz")
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// Reads pass through
+	out, err = ro.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	keys, err := ro.List(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, keys)
+	keys, err = ro.ListPage(ctx, "", "", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, keys)
+
+	ro.SetReadOnly(false)
+	require.NoError(t, ro.Delete(ctx, "foo"))
+
+	// Once writable, it behaves like the underlying backend
+	physical.ExerciseBackend(t, ro)
+	physical.ExerciseBackend_ListPrefix(t, ro)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func newShards(t *testing.T, names ...string) map[string]physical.Backend {
+	shards := make(map[string]physical.Backend, len(names))
+	for _, name := range names {
+		inm, err := NewInmem(nil, logging.NewVaultLogger(log.Debug))
+		require.NoError(t, err)
+		shards[name] = inm
+	}
+	return shards
+}
+
+func TestSharded(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+
+	_, err := physical.NewSharded(nil, 0, logger)
+	require.Error(t, err)
+
+	s, err := physical.NewSharded(newShards(t, "a", "b", "c"), 0, logger)
+	require.NoError(t, err)
+	physical.ExerciseBackend(t, s)
+	physical.ExerciseBackend_ListPrefix(t, s)
+}
+
+func TestSharded_Distribution(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	shards := newShards(t, "a", "b", "c")
+	s, err := physical.NewSharded(shards, 0, logger)
+	require.NoError(t, err)
+
+	const count = 3000
+	counts := make(map[string]int)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("logical/%d", i)
+		require.NoError(t, s.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+		counts[s.ShardFor(key)]++
+
+		entry, err := shards[s.ShardFor(key)].Get(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+	}
+	for name, n := range counts {
+		require.InDelta(t, count/3, n, count/10, name)
+	}
+
+	// Entries are fetched from their shards in batches.
+	entries, err := s.BatchGet(ctx, []string{"logical/1", "missing", "logical/2"})
+	require.NoError(t, err)
+	require.Equal(t, "logical/1", string(entries[0].Value))
+	require.Nil(t, entries[1])
+	require.Equal(t, "logical/2", string(entries[2].Value))
+
+	// Adding a shard only remaps the keys it takes over, and the placement
+	// does not depend on the order of the shards.
+	grown, err := physical.NewSharded(newShards(t, "c", "d", "b", "a"), 0, logger)
+	require.NoError(t, err)
+	var moved int
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("logical/%d", i)
+		if before, after := s.ShardFor(key), grown.ShardFor(key); before != after {
+			require.Equal(t, "d", after)
+			moved++
+		}
+	}
+	require.InDelta(t, count/4, moved, count/10)
+}
+
+func TestSharded_ListPage(t *testing.T) {
+	logger := logging.NewVaultLogger(log.Debug)
+	ctx := context.Background()
+
+	s, err := physical.NewSharded(newShards(t, "a", "b", "c", "d"), 0, logger)
+	require.NoError(t, err)
+
+	var expected []string
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("%03d", i)
+		require.NoError(t, s.Put(ctx, &physical.Entry{Key: "prefix/" + key}))
+		expected = append(expected, key)
+		if i%5 == 0 {
+			// Directories spread over several shards are listed once.
+			for j := 0; j < 4; j++ {
+				require.NoError(t, s.Put(ctx, &physical.Entry{Key: fmt.Sprintf("prefix/%s-dir/%d", key, j)}))
+			}
+			expected = append(expected, key+"-dir/")
+		}
+	}
+
+	keys, err := s.List(ctx, "prefix/")
+	require.NoError(t, err)
+	require.Equal(t, expected, keys)
+
+	for _, limit := range []int{1, 3, 7, 100} {
+		var paged []string
+		after := ""
+		for {
+			page, err := s.ListPage(ctx, "prefix/", after, limit)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(page), limit)
+			paged = append(paged, page...)
+			if len(page) < limit {
+				break
+			}
+			after = page[len(page)-1]
+		}
+		require.Equal(t, expected, paged, "limit %d", limit)
+	}
+
+	keys, err = s.ListPage(ctx, "prefix/", "", -1)
+	require.NoError(t, err)
+	require.Equal(t, expected, keys)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package inmem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+	uberAtomic "go.uber.org/atomic"
+)
+
+// unavailableBackend fails every operation while down.
+type unavailableBackend struct {
+	physical.Backend
+	down *uberAtomic.Bool
+}
+
+func (u *unavailableBackend) Put(ctx context.Context, entry *physical.Entry) error {
+	if u.down.Load() {
+		return errors.New("backend unavailable")
+	}
+	return u.Backend.Put(ctx, entry)
+}
+
+func (u *unavailableBackend) Delete(ctx context.Context, key string) error {
+	if u.down.Load() {
+		return errors.New("backend unavailable")
+	}
+	return u.Backend.Delete(ctx, key)
+}
+
+func (u *unavailableBackend) List(ctx context.Context, prefix string) ([]string, error) {
+	if u.down.Load() {
+		return nil, errors.New("backend unavailable")
+	}
+	return u.Backend.List(ctx, prefix)
+}
+
+func newTee(t *testing.T, config physical.TeeConfig) (*physical.Tee, physical.Backend, *unavailableBackend) {
+	logger := logging.NewVaultLogger(log.Debug)
+	primary, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	inm, err := NewInmem(nil, logger)
+	require.NoError(t, err)
+	secondary := &unavailableBackend{Backend: inm, down: uberAtomic.NewBool(false)}
+
+	tee := physical.NewTee(primary, secondary, config, logger, &metrics.BlackholeSink{})
+	t.Cleanup(tee.Stop)
+	return tee, primary, secondary
+}
+
+func TestTee(t *testing.T) {
+	tee, primary, secondary := newTee(t, physical.TeeConfig{})
+	physical.ExerciseBackend(t, tee)
+	physical.ExerciseBackend_ListPrefix(t, tee)
+
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("foo/%d", i)
+		require.NoError(t, tee.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+	}
+	require.NoError(t, tee.Delete(ctx, "foo/42"))
+
+	require.NoError(t, tee.Flush(ctx))
+	require.Equal(t, allKeys(t, primary), allKeys(t, secondary))
+
+	status := tee.Status()
+	require.Equal(t, physical.TeeStateHealthy, status.State)
+	require.Zero(t, status.Pending)
+	require.Zero(t, status.Lag)
+}
+
+func TestTee_SecondaryUnavailable(t *testing.T) {
+	tee, primary, secondary := newTee(t, physical.TeeConfig{
+		RetryInterval: 10 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	// Writes succeed and are queued while the secondary is down
+	secondary.down.Store(true)
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("foo/%d", i)
+		require.NoError(t, tee.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+	}
+	require.NoError(t, tee.Delete(ctx, "foo/3"))
+
+	require.Eventually(t, func() bool {
+		return tee.Status().LastError != nil
+	}, time.Second, 5*time.Millisecond)
+	status := tee.Status()
+	require.Equal(t, physical.TeeStateHealthy, status.State)
+	require.Equal(t, 11, status.Pending)
+	require.Positive(t, status.Lag)
+
+	flushCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, tee.Flush(flushCtx), context.DeadlineExceeded)
+
+	// The queue is drained once the secondary recovers
+	secondary.down.Store(false)
+	require.NoError(t, tee.Flush(ctx))
+	require.Equal(t, allKeys(t, primary), allKeys(t, secondary))
+	status = tee.Status()
+	require.Zero(t, status.Pending)
+	require.NoError(t, status.LastError)
+}
+
+func TestTee_Degraded(t *testing.T) {
+	tee, primary, secondary := newTee(t, physical.TeeConfig{
+		QueueSize:     5,
+		RetryInterval: 10 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	// The secondary holds keys deleted while the mirror is degraded
+	require.NoError(t, tee.Put(ctx, &physical.Entry{Key: "stale", Value: []byte("stale")}))
+	require.NoError(t, tee.Flush(ctx))
+
+	secondary.down.Store(true)
+	require.NoError(t, tee.Delete(ctx, "stale"))
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("foo/bar/%d", i)
+		require.NoError(t, tee.Put(ctx, &physical.Entry{Key: key, Value: []byte(key)}))
+	}
+
+	status := tee.Status()
+	require.Equal(t, physical.TeeStateDegraded, status.State)
+	require.Zero(t, status.Pending)
+	require.Equal(t, uint64(21), status.Dropped)
+	require.Positive(t, status.Lag)
+	require.ErrorIs(t, tee.Flush(ctx), physical.ErrTeeDegraded)
+
+	// Reconciliation catches up once the secondary recovers
+	secondary.down.Store(false)
+	require.Eventually(t, func() bool {
+		return tee.Status().State == physical.TeeStateHealthy
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, tee.Flush(ctx))
+	require.Equal(t, allKeys(t, primary), allKeys(t, secondary))
+
+	status = tee.Status()
+	require.Zero(t, status.Dropped)
+	require.Zero(t, status.Lag)
+}
+
+func TestTee_Reconcile(t *testing.T) {
+	tee, primary, secondary := newTee(t, physical.TeeConfig{})
+	ctx := context.Background()
+
+	require.NoError(t, primary.Put(ctx, &physical.Entry{Key: "foo/a", Value: []byte("a")}))
+	require.NoError(t, secondary.Put(ctx, &physical.Entry{Key: "foo/b", Value: []byte("b")}))
+	require.NoError(t, secondary.Put(ctx, &physical.Entry{Key: "foo/a", Value: []byte("old")}))
+
+	require.NoError(t, tee.Reconcile(ctx))
+	require.Equal(t, map[string]string{"foo/a": "a"}, allKeys(t, secondary))
+}
@@ -0,0 +1,585 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+	uberAtomic "go.uber.org/atomic"
+)
+
+const (
+	// MigrationProgressKey is where the Migrator records its progress in the
+	// target backend. It is hidden from listings and is left in place once
+	// the migration completes, so that restarting with the same
+	// configuration does not start over.
+	MigrationProgressKey = migrationProgressPrefix + "progress"
+
+	migrationProgressPrefix = "_migration/"
+
+	// migrationCheckpointInterval is the number of keys copied between two
+	// writes of the progress record.
+	migrationCheckpointInterval = 256
+
+	migrationMinBackoff = time.Second
+	migrationMaxBackoff = time.Minute
+)
+
+// ErrMigrationTargetNotEmpty is returned when starting a migration towards a
+// backend which already holds data that was not written by a migration.
+var ErrMigrationTargetNotEmpty = errors.New("storage migration target is not empty")
+
+// MigrationProgress is the persisted state of an online migration.
+type MigrationProgress struct {
+	// Prefixes holds the progress of the copy of each top-level prefix of
+	// the source, keyed by prefix.
+	Prefixes map[string]*MigrationPrefixProgress `json:"prefixes"`
+
+	// Dirty lists keys which failed to be mirrored to the target and must
+	// be copied again before the migration can complete.
+	Dirty []string `json:"dirty,omitempty"`
+
+	// Complete is set once the target holds all the data of the source.
+	// Reads are then served from the target.
+	Complete bool `json:"complete"`
+
+	// SourceOutOfDate is set when a write could not be mirrored to the
+	// source after the migration completed, meaning switching back to the
+	// source would lose data.
+	SourceOutOfDate bool `json:"source_out_of_date,omitempty"`
+}
+
+// MigrationPrefixProgress is the progress of the copy of one top-level
+// prefix. Keys are copied in lexicographic order, so everything up to and
+// including Cursor has been copied.
+type MigrationPrefixProgress struct {
+	Cursor string `json:"cursor,omitempty"`
+	Copied uint64 `json:"copied"`
+	Done   bool   `json:"done"`
+}
+
+func (p *MigrationProgress) clone() *MigrationProgress {
+	ret := &MigrationProgress{
+		Prefixes:        make(map[string]*MigrationPrefixProgress, len(p.Prefixes)),
+		Dirty:           append([]string(nil), p.Dirty...),
+		Complete:        p.Complete,
+		SourceOutOfDate: p.SourceOutOfDate,
+	}
+	for prefix, prog := range p.Prefixes {
+		c := *prog
+		ret.Prefixes[prefix] = &c
+	}
+	return ret
+}
+
+// Migrator wraps two physical backends to move data from the source to the
+// target without downtime. Writes and deletes are applied to both backends,
+// while a background copy moves the existing data over one top-level prefix
+// at a time. Until the copy completes, reads are served from the source,
+// which always holds the latest data; afterwards they are served from the
+// target, and writes keep being mirrored to the source so that the operator
+// can switch over, or back, at their convenience.
+//
+// The copy and the writes to a given key are serialized, so that the copy
+// never overwrites a newer value in the target nor brings back a deleted
+// key. The Migrator must therefore be the only writer to both backends:
+// all other nodes sharing them must be stopped during the migration.
+type Migrator struct {
+	source Backend
+	target Backend
+	logger log.Logger
+	locks  []*locksutil.LockEntry
+
+	// switchLock is held for writing while switching reads to the target,
+	// so that no write is in flight while the backends change roles.
+	switchLock sync.RWMutex
+	complete   *uberAtomic.Bool
+
+	progressLock sync.Mutex
+	progress     *MigrationProgress
+	dirty        map[string]struct{}
+
+	stopLock sync.Mutex
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// Verify Migrator satisfies the correct interfaces
+var (
+	_ Backend = (*Migrator)(nil)
+)
+
+// NewMigrator returns a physical backend migrating data from source to
+// target. Progress is loaded from the target, so that an interrupted
+// migration resumes where it left off. Call Start to begin copying.
+func NewMigrator(ctx context.Context, source, target Backend, logger log.Logger) (*Migrator, error) {
+	m := &Migrator{
+		source:   source,
+		target:   target,
+		logger:   logger,
+		locks:    locksutil.CreateLocks(),
+		complete: uberAtomic.NewBool(false),
+		dirty:    make(map[string]struct{}),
+	}
+
+	entry, err := target.Get(ctx, MigrationProgressKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage migration progress: %w", err)
+	}
+	if entry != nil {
+		progress := new(MigrationProgress)
+		if err := json.Unmarshal(entry.Value, progress); err != nil {
+			return nil, fmt.Errorf("failed to decode storage migration progress: %w", err)
+		}
+		m.progress = progress
+		for _, key := range progress.Dirty {
+			m.dirty[key] = struct{}{}
+		}
+		m.complete.Store(progress.Complete)
+		return m, nil
+	}
+
+	existing, err := target.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage migration target: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, ErrMigrationTargetNotEmpty
+	}
+
+	prefixes, err := source.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage migration source: %w", err)
+	}
+	m.progress = &MigrationProgress{
+		Prefixes: make(map[string]*MigrationPrefixProgress, len(prefixes)),
+	}
+	for _, prefix := range prefixes {
+		if prefix == migrationProgressPrefix {
+			continue
+		}
+		m.progress.Prefixes[prefix] = &MigrationPrefixProgress{}
+	}
+	if err := m.saveProgress(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start begins copying data in the background, unless the migration is
+// already complete.
+func (m *Migrator) Start() {
+	m.stopLock.Lock()
+	defer m.stopLock.Unlock()
+
+	if m.stopCh != nil || m.complete.Load() {
+		return
+	}
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go m.run(m.stopCh, m.doneCh)
+}
+
+// Stop interrupts the background copy and waits for it to return. The
+// progress made so far is saved, and the migration resumes on the next
+// Start.
+func (m *Migrator) Stop() {
+	m.stopLock.Lock()
+	defer m.stopLock.Unlock()
+
+	if m.stopCh == nil {
+		return
+	}
+	close(m.stopCh)
+	<-m.doneCh
+	m.stopCh, m.doneCh = nil, nil
+}
+
+// Complete returns whether the target holds all the data of the source.
+func (m *Migrator) Complete() bool {
+	return m.complete.Load()
+}
+
+// Progress returns a copy of the current migration progress.
+func (m *Migrator) Progress() *MigrationProgress {
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	return m.progress.clone()
+}
+
+// backends returns the backend serving reads followed by the one receiving
+// the mirrored writes.
+func (m *Migrator) backends() (primary, secondary Backend) {
+	if m.complete.Load() {
+		return m.target, m.source
+	}
+	return m.source, m.target
+}
+
+func (m *Migrator) Put(ctx context.Context, entry *Entry) error {
+	return m.write(ctx, entry.Key, func(b Backend) error {
+		return b.Put(ctx, entry)
+	})
+}
+
+func (m *Migrator) Get(ctx context.Context, key string) (*Entry, error) {
+	primary, _ := m.backends()
+	return primary.Get(ctx, key)
+}
+
+func (m *Migrator) Delete(ctx context.Context, key string) error {
+	return m.write(ctx, key, func(b Backend) error {
+		return b.Delete(ctx, key)
+	})
+}
+
+// write applies a write to both backends. If it cannot be mirrored, nor the
+// discrepancy recorded, the backend serving reads is restored to its
+// previous value and the write fails.
+func (m *Migrator) write(ctx context.Context, key string, op func(Backend) error) error {
+	m.switchLock.RLock()
+	defer m.switchLock.RUnlock()
+
+	lock := locksutil.LockForKey(m.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	primary, secondary := m.backends()
+	previous, err := primary.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := op(primary); err != nil {
+		return err
+	}
+
+	mirrorErr := op(secondary)
+	if mirrorErr == nil {
+		return nil
+	}
+	if err := m.mirrorFailed(ctx, key, mirrorErr); err == nil {
+		return nil
+	}
+
+	var restoreErr error
+	if previous == nil {
+		restoreErr = primary.Delete(ctx, key)
+	} else {
+		restoreErr = primary.Put(ctx, previous)
+	}
+	if restoreErr != nil {
+		m.logger.Error("failed to revert unmirrored write, storage migration backends are inconsistent", "key", key, "error", restoreErr)
+	}
+	return fmt.Errorf("failed to mirror write: %w", mirrorErr)
+}
+
+func (m *Migrator) List(ctx context.Context, prefix string) ([]string, error) {
+	primary, _ := m.backends()
+	keys, err := primary.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return m.hideProgress(prefix, keys), nil
+}
+
+func (m *Migrator) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	primary, _ := m.backends()
+
+	// Fetch one more entry when the progress prefix may be part of the
+	// page, so that hiding it does not end the listing early.
+	fetch := limit
+	if prefix == "" && limit > 0 {
+		fetch++
+	}
+	keys, err := primary.ListPage(ctx, prefix, after, fetch)
+	if err != nil {
+		return nil, err
+	}
+	keys = m.hideProgress(prefix, keys)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+// hideProgress removes the progress prefix from a listing of the root.
+func (m *Migrator) hideProgress(prefix string, keys []string) []string {
+	if prefix != "" {
+		return keys
+	}
+	for i, key := range keys {
+		if key == migrationProgressPrefix {
+			return append(keys[:i:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
+
+// mirrorFailed records that a write was applied to the backend serving
+// reads but not to the other one: while copying, the key is queued to be
+// copied again.
+func (m *Migrator) mirrorFailed(ctx context.Context, key string, err error) error {
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	if m.complete.Load() {
+		m.logger.Warn("failed to mirror write to storage migration source; switching back to it would lose data", "key", key, "error", err)
+		if m.progress.SourceOutOfDate {
+			return nil
+		}
+		m.progress.SourceOutOfDate = true
+	} else {
+		m.logger.Warn("failed to mirror write to storage migration target, will copy it again", "key", key, "error", err)
+		if _, ok := m.dirty[key]; ok {
+			return nil
+		}
+		m.dirty[key] = struct{}{}
+		m.progress.Dirty = append(m.progress.Dirty, key)
+	}
+
+	if err := m.saveProgressLocked(ctx); err != nil {
+		if m.complete.Load() {
+			m.progress.SourceOutOfDate = false
+		} else {
+			delete(m.dirty, key)
+			m.progress.Dirty = m.progress.Dirty[:len(m.progress.Dirty)-1]
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *Migrator) saveProgress(ctx context.Context) error {
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	return m.saveProgressLocked(ctx)
+}
+
+func (m *Migrator) saveProgressLocked(ctx context.Context) error {
+	value, err := json.Marshal(m.progress)
+	if err != nil {
+		return fmt.Errorf("failed to encode storage migration progress: %w", err)
+	}
+	if err := m.target.Put(ctx, &Entry{Key: MigrationProgressKey, Value: value}); err != nil {
+		return fmt.Errorf("failed to save storage migration progress: %w", err)
+	}
+	return nil
+}
+
+// run copies data until the migration completes or stopCh is closed,
+// retrying with a backoff on failures.
+func (m *Migrator) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-doneCh:
+		}
+	}()
+
+	backoff := migrationMinBackoff
+	for {
+		err := m.migrate(ctx)
+		if err == nil {
+			m.logger.Info("storage migration complete, serving reads from the target")
+			return
+		}
+
+		m.progressLock.Lock()
+		if perr := m.saveProgressLocked(context.Background()); perr != nil {
+			m.logger.Error("failed to save storage migration progress", "error", perr)
+		}
+		m.progressLock.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+		m.logger.Error("storage migration failed, retrying", "error", err, "backoff", backoff)
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, migrationMaxBackoff)
+	}
+}
+
+// migrate performs one pass of the copy: the top-level prefixes not done
+// yet, then the dirty keys. The migration is marked complete when nothing
+// is left to copy.
+func (m *Migrator) migrate(ctx context.Context) error {
+	m.progressLock.Lock()
+	var prefixes []string
+	for prefix, prog := range m.progress.Prefixes {
+		if !prog.Done {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	m.progressLock.Unlock()
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		m.logger.Info("copying storage prefix", "prefix", prefix)
+		if err := m.copyPrefix(ctx, prefix); err != nil {
+			return fmt.Errorf("failed to copy prefix %q: %w", prefix, err)
+		}
+	}
+
+	for {
+		key, done, err := m.tryComplete(ctx)
+		if done || err != nil {
+			return err
+		}
+
+		if err := m.copyKey(ctx, key); err != nil {
+			return fmt.Errorf("failed to copy key %q: %w", key, err)
+		}
+		if err := m.saveProgress(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// tryComplete switches reads to the target if no dirty key is left, or
+// returns the next dirty key to copy.
+func (m *Migrator) tryComplete(ctx context.Context) (string, bool, error) {
+	m.switchLock.Lock()
+	defer m.switchLock.Unlock()
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	if len(m.progress.Dirty) > 0 {
+		return m.progress.Dirty[0], false, nil
+	}
+
+	m.progress.Complete = true
+	if err := m.saveProgressLocked(ctx); err != nil {
+		m.progress.Complete = false
+		return "", false, err
+	}
+	m.complete.Store(true)
+	return "", true, nil
+}
+
+// copyPrefix copies a top-level prefix of the source, resuming after its
+// cursor, and marks it done.
+func (m *Migrator) copyPrefix(ctx context.Context, prefix string) error {
+	if strings.HasSuffix(prefix, "/") {
+		if err := m.copyTree(ctx, prefix, prefix); err != nil {
+			return err
+		}
+	} else if err := m.copyKey(ctx, prefix); err != nil {
+		return err
+	}
+
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	m.progress.Prefixes[prefix].Done = true
+	return m.saveProgressLocked(ctx)
+}
+
+// copyTree copies the keys under dir in lexicographic order, skipping
+// those up to the cursor of the top-level prefix.
+func (m *Migrator) copyTree(ctx context.Context, prefix, dir string) error {
+	keys, err := m.source.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+
+	m.progressLock.Lock()
+	prog := m.progress.Prefixes[prefix]
+	cursor := prog.Cursor
+	m.progressLock.Unlock()
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		path := dir + key
+		if strings.HasSuffix(key, "/") {
+			// A directory sorts before the keys following it, so it is
+			// only skipped when entirely before the cursor.
+			if cursor == "" || path > cursor || strings.HasPrefix(cursor, path) {
+				if err := m.copyTree(ctx, prefix, path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if cursor != "" && path <= cursor {
+			continue
+		}
+
+		if err := m.copyKey(ctx, path); err != nil {
+			return fmt.Errorf("failed to copy key %q: %w", path, err)
+		}
+
+		m.progressLock.Lock()
+		prog.Cursor = path
+		prog.Copied++
+		var err error
+		if prog.Copied%migrationCheckpointInterval == 0 {
+			err = m.saveProgressLocked(ctx)
+		}
+		m.progressLock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyKey copies the current value of a key from the source to the target.
+// The key lock is held so that concurrent writes are applied either before
+// or after the copy.
+func (m *Migrator) copyKey(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(m.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := m.source.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		// The key was deleted after being listed.
+		err = m.target.Delete(ctx, key)
+	} else {
+		err = m.target.Put(ctx, entry)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+	if _, ok := m.dirty[key]; ok {
+		delete(m.dirty, key)
+		for i, dirty := range m.progress.Dirty {
+			if dirty == key {
+				m.progress.Dirty = append(m.progress.Dirty[:i], m.progress.Dirty[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -4,10 +4,14 @@
 package physical
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"strings"
 
 	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 )
 
 const (
@@ -60,6 +64,133 @@ type Backend interface {
 	ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error)
 }
 
+// BatchGetter is an optional interface for backends which can fetch several
+// entries in a single round-trip.
+type BatchGetter interface {
+	// BatchGet is used to fetch multiple entries. The returned entries are
+	// in the same order as the keys, with nil for keys which do not exist.
+	BatchGet(ctx context.Context, keys []string) ([]*Entry, error)
+}
+
+// BatchGetEntries fetches the given keys from the backend, using BatchGet if
+// the backend implements BatchGetter and falling back to sequential Get
+// calls otherwise.
+func BatchGetEntries(ctx context.Context, b Backend, keys []string) ([]*Entry, error) {
+	if bg, ok := b.(BatchGetter); ok {
+		entries, err := bg.BatchGet(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) != len(keys) {
+			return nil, fmt.Errorf("batch get returned %d entries for %d keys", len(entries), len(keys))
+		}
+		return entries, nil
+	}
+
+	entries := make([]*Entry, len(keys))
+	for i, key := range keys {
+		entry, err := b.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// PrefixDeleter is an optional interface for backends which can delete every
+// entry under a prefix at once.
+type PrefixDeleter interface {
+	// DeletePrefix is used to permanently delete every entry whose key
+	// starts with the prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// DeletePrefix deletes every entry whose key starts with the prefix, using
+// DeletePrefix if the backend implements PrefixDeleter and falling back to
+// listing and deleting keys one at a time otherwise. The fallback is not
+// atomic: if it fails part way, some entries will have been deleted, and
+// entries written concurrently under the prefix may survive it.
+func DeletePrefix(ctx context.Context, b Backend, prefix string) error {
+	if pd, ok := b.(PrefixDeleter); ok {
+		return pd.DeletePrefix(ctx, prefix)
+	}
+
+	keys, err := b.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list %q: %w", prefix, err)
+	}
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/") {
+			if err := DeletePrefix(ctx, b, prefix+key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := b.Delete(ctx, prefix+key); err != nil {
+			return fmt.Errorf("failed to delete %q: %w", prefix+key, err)
+		}
+	}
+	return nil
+}
+
+// CASBackend is an optional interface for backends which can atomically
+// write an entry conditionally on the current value of its key.
+type CASBackend interface {
+	// CompareAndSwap writes the entry only if the current value of its key
+	// hashes to expectedHash, see HashValue, reporting whether it was
+	// written. A nil expectedHash writes the entry only if the key does not
+	// exist.
+	CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (bool, error)
+}
+
+// HashValue returns the hash of an entry value compared by CompareAndSwap.
+func HashValue(value []byte) []byte {
+	sum := sha256.Sum256(value)
+	return sum[:]
+}
+
+// MatchesHash returns whether the entry, nil if its key does not exist,
+// matches the hash expected by CompareAndSwap.
+func MatchesHash(entry *Entry, expectedHash []byte) bool {
+	if expectedHash == nil {
+		return entry == nil
+	}
+	return entry != nil && bytes.Equal(HashValue(entry.Value), expectedHash)
+}
+
+// casLocks serialize the fallback of CompareAndSwap for each key.
+var casLocks = locksutil.CreateLocks()
+
+// CompareAndSwap writes the entry to the backend only if the current value
+// of its key hashes to expectedHash, or if the key does not exist when
+// expectedHash is nil, reporting whether it was written. It uses
+// CompareAndSwap if the backend implements CASBackend and falls back to a
+// Get and Put under a lock for the key otherwise. The fallback is only
+// atomic with respect to other calls to this function in the same process:
+// writes made directly to the backend, or from other nodes, may race it.
+func CompareAndSwap(ctx context.Context, b Backend, entry *Entry, expectedHash []byte) (bool, error) {
+	if cb, ok := b.(CASBackend); ok {
+		return cb.CompareAndSwap(ctx, entry, expectedHash)
+	}
+
+	lock := locksutil.LockForKey(casLocks, entry.Key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	current, err := b.Get(ctx, entry.Key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %q: %w", entry.Key, err)
+	}
+	if !MatchesHash(current, expectedHash) {
+		return false, nil
+	}
+	if err := b.Put(ctx, entry); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // HABackend is an extensions to the standard physical
 // backend to support high-availability. Vault only expects to
 // use mutual exclusion to allow multiple instances to act as a
@@ -0,0 +1,175 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/armon/go-metrics"
+	uberAtomic "go.uber.org/atomic"
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimitMaxQueued is the number of operations which may wait for
+// a RateLimiter at once when RateLimiterConfig.MaxQueued is not set.
+const DefaultRateLimitMaxQueued = 1024
+
+// ErrRateLimitQueueFull is returned by a RateLimiter for operations which
+// would have to wait while too many others are already waiting.
+var ErrRateLimitQueueFull = errors.New("too many storage operations are waiting on the rate limit")
+
+// RateLimiterConfig configures a RateLimiter.
+type RateLimiterConfig struct {
+	// Rate is the number of operations allowed per second, with bursts of
+	// up to Burst operations. Burst defaults to 1.
+	Rate  float64
+	Burst int
+
+	// LimitLists paces List and ListPage along with writes, drawing from
+	// the same bucket.
+	LimitLists bool
+
+	// MaxQueued is the number of operations which may wait at once. Any
+	// more fail immediately with ErrRateLimitQueueFull. If zero,
+	// DefaultRateLimitMaxQueued is used.
+	MaxQueued int
+}
+
+// Validate checks the configuration is consistent.
+func (c *RateLimiterConfig) Validate() error {
+	switch {
+	case c.Rate <= 0:
+		return errors.New("rate must be positive")
+	case c.Burst < 0:
+		return errors.New("burst cannot be negative")
+	case c.MaxQueued < 0:
+		return errors.New("max queued cannot be negative")
+	}
+	return nil
+}
+
+// RateLimiter wraps a physical backend and paces writes to it with a token
+// bucket, for backends which throttle their clients aggressively. Rather
+// than failing, operations over the rate wait for their turn, up to a cap
+// on the number waiting. Reads are passed through, as are listings unless
+// configured otherwise.
+type RateLimiter struct {
+	backend    Backend
+	limiter    *rate.Limiter
+	limitLists bool
+	maxQueued  int64
+	queued     *uberAtomic.Int64
+	metricSink metrics.MetricSink
+}
+
+// Verify RateLimiter satisfies the correct interfaces
+var (
+	_ Backend     = (*RateLimiter)(nil)
+	_ BatchGetter = (*RateLimiter)(nil)
+	_ CASBackend  = (*RateLimiter)(nil)
+)
+
+// NewRateLimiter returns a wrapped physical backend pacing writes to the
+// configured rate.
+func NewRateLimiter(b Backend, config RateLimiterConfig, metricSink metrics.MetricSink) (*RateLimiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	burst := config.Burst
+	if burst == 0 {
+		burst = 1
+	}
+	maxQueued := config.MaxQueued
+	if maxQueued == 0 {
+		maxQueued = DefaultRateLimitMaxQueued
+	}
+
+	return &RateLimiter{
+		backend:    b,
+		limiter:    rate.NewLimiter(rate.Limit(config.Rate), burst),
+		limitLists: config.LimitLists,
+		maxQueued:  int64(maxQueued),
+		queued:     uberAtomic.NewInt64(0),
+		metricSink: metricSink,
+	}, nil
+}
+
+// QueueDepth returns the number of operations currently waiting.
+func (r *RateLimiter) QueueDepth() int {
+	return int(r.queued.Load())
+}
+
+// wait blocks until the operation may proceed, the context is done, or
+// it is clear that the context will be done first.
+func (r *RateLimiter) wait(ctx context.Context) error {
+	// Operations which can proceed right away never queue
+	if r.limiter.Allow() {
+		return nil
+	}
+
+	queued := r.queued.Inc()
+	defer func() {
+		r.metricSink.SetGauge([]string{"storage", "rate_limit", "queue_depth"}, float32(r.queued.Dec()))
+	}()
+	r.metricSink.SetGauge([]string{"storage", "rate_limit", "queue_depth"}, float32(queued))
+	if queued > r.maxQueued {
+		r.metricSink.IncrCounter([]string{"storage", "rate_limit", "rejected"}, 1)
+		return ErrRateLimitQueueFull
+	}
+
+	start := time.Now()
+	err := r.limiter.Wait(ctx)
+	elapsed := float32(time.Since(start).Nanoseconds()) / float32(time.Millisecond)
+	r.metricSink.AddSample([]string{"storage", "rate_limit", "wait"}, elapsed)
+	return err
+}
+
+func (r *RateLimiter) Put(ctx context.Context, entry *Entry) error {
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+	return r.backend.Put(ctx, entry)
+}
+
+func (r *RateLimiter) CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (bool, error) {
+	if err := r.wait(ctx); err != nil {
+		return false, err
+	}
+	return CompareAndSwap(ctx, r.backend, entry, expectedHash)
+}
+
+func (r *RateLimiter) Get(ctx context.Context, key string) (*Entry, error) {
+	return r.backend.Get(ctx, key)
+}
+
+func (r *RateLimiter) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	return BatchGetEntries(ctx, r.backend, keys)
+}
+
+func (r *RateLimiter) Delete(ctx context.Context, key string) error {
+	if err := r.wait(ctx); err != nil {
+		return err
+	}
+	return r.backend.Delete(ctx, key)
+}
+
+func (r *RateLimiter) List(ctx context.Context, prefix string) ([]string, error) {
+	if r.limitLists {
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r.backend.List(ctx, prefix)
+}
+
+func (r *RateLimiter) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	if r.limitLists {
+		if err := r.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return r.backend.ListPage(ctx, prefix, after, limit)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+
+	uberAtomic "go.uber.org/atomic"
+)
+
+// ErrReadOnly is returned by ReadOnly for writes while it is read-only.
+var ErrReadOnly = errors.New("physical backend is read-only")
+
+// ReadOnly wraps a physical backend and rejects writes while read-only
+// mode is on, passing reads through. This is useful for nodes which must
+// not modify storage, such as standbys, and can be switched off when the
+// node is promoted.
+type ReadOnly struct {
+	backend  Backend
+	readOnly *uberAtomic.Bool
+}
+
+// Verify ReadOnly satisfies the correct interfaces
+var (
+	_ ToggleablePurgemonster = (*ReadOnly)(nil)
+	_ Backend                = (*ReadOnly)(nil)
+	_ BatchGetter            = (*ReadOnly)(nil)
+	_ CASBackend             = (*ReadOnly)(nil)
+)
+
+// NewReadOnly returns a wrapped physical backend which starts out
+// read-only.
+func NewReadOnly(b Backend) *ReadOnly {
+	return &ReadOnly{
+		backend:  b,
+		readOnly: uberAtomic.NewBool(true),
+	}
+}
+
+// SetReadOnly toggles whether writes are rejected.
+func (r *ReadOnly) SetReadOnly(readOnly bool) {
+	r.readOnly.Store(readOnly)
+}
+
+// IsReadOnly returns whether writes are currently rejected.
+func (r *ReadOnly) IsReadOnly() bool {
+	return r.readOnly.Load()
+}
+
+// Purge is a no-op, as nothing is cached.
+func (r *ReadOnly) Purge(ctx context.Context) {}
+
+// SetEnabled is a no-op; use SetReadOnly to toggle read-only mode.
+func (r *ReadOnly) SetEnabled(bool) {}
+
+func (r *ReadOnly) Put(ctx context.Context, entry *Entry) error {
+	if r.readOnly.Load() {
+		return ErrReadOnly
+	}
+	return r.backend.Put(ctx, entry)
+}
+
+func (r *ReadOnly) CompareAndSwap(ctx context.Context, entry *Entry, expectedHash []byte) (bool, error) {
+	if r.readOnly.Load() {
+		return false, ErrReadOnly
+	}
+	return CompareAndSwap(ctx, r.backend, entry, expectedHash)
+}
+
+func (r *ReadOnly) Get(ctx context.Context, key string) (*Entry, error) {
+	return r.backend.Get(ctx, key)
+}
+
+func (r *ReadOnly) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	return BatchGetEntries(ctx, r.backend, keys)
+}
+
+func (r *ReadOnly) Delete(ctx context.Context, key string) error {
+	if r.readOnly.Load() {
+		return ErrReadOnly
+	}
+	return r.backend.Delete(ctx, key)
+}
+
+func (r *ReadOnly) List(ctx context.Context, prefix string) ([]string, error) {
+	return r.backend.List(ctx, prefix)
+}
+
+func (r *ReadOnly) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return r.backend.ListPage(ctx, prefix, after, limit)
+}
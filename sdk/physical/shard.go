@@ -0,0 +1,260 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+
+	log "github.com/hashicorp/go-hclog"
+)
+
+// DefaultShardVirtualNodes is used if no number of virtual nodes is
+// specified for NewSharded
+const DefaultShardVirtualNodes = 256
+
+// Sharded spreads keys over several backends using consistent hashing. Each
+// shard is placed on a hash ring at many points, its virtual nodes, derived
+// from its name, and a key is stored in the shard owning the first point
+// following the hash of the key. Adding or removing a shard thus only remaps
+// the keys between its points and the preceding ones, about 1/N of the keys
+// with N shards.
+//
+// Remapped keys are not moved automatically: after changing the shards, the
+// data must be rebalanced with a migration pass, moving every key whose shard
+// changed from its previous shard to its new one, before serving requests
+// with the new layout. ShardFor can be used against both layouts to find
+// those keys.
+type Sharded struct {
+	logger log.Logger
+	names  []string
+	shards []Backend
+
+	// points is the sorted hash ring, and owners the index of the shard
+	// owning each point.
+	points []uint64
+	owners []int
+}
+
+// Verify Sharded satisfies the correct interfaces
+var (
+	_ Backend     = (*Sharded)(nil)
+	_ BatchGetter = (*Sharded)(nil)
+)
+
+// NewSharded returns a physical backend spreading keys over the given
+// shards, keyed by name. Names, rather than the order of the shards,
+// determine the placement of keys, so they must be kept stable.
+func NewSharded(shards map[string]Backend, virtualNodes int, logger log.Logger) (*Sharded, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("at least one shard is required")
+	}
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultShardVirtualNodes
+	}
+
+	s := &Sharded{
+		logger: logger,
+	}
+	for name := range shards {
+		s.names = append(s.names, name)
+	}
+	sort.Strings(s.names)
+
+	type point struct {
+		hash  uint64
+		owner int
+	}
+	ring := make([]point, 0, len(shards)*virtualNodes)
+	for i, name := range s.names {
+		s.shards = append(s.shards, shards[name])
+		for v := 0; v < virtualNodes; v++ {
+			ring = append(ring, point{hash: shardHash(name + "#" + strconv.Itoa(v)), owner: i})
+		}
+	}
+	// Order colliding points by owner so that placement does not depend on
+	// map iteration order.
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash != ring[j].hash {
+			return ring[i].hash < ring[j].hash
+		}
+		return ring[i].owner < ring[j].owner
+	})
+	for _, p := range ring {
+		s.points = append(s.points, p.hash)
+		s.owners = append(s.owners, p.owner)
+	}
+
+	return s, nil
+}
+
+// shardHash hashes a string with FNV-1a, followed by a finalizer mixing the
+// bits so that similar strings are spread over the whole ring.
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// ShardFor returns the name of the shard storing the given key.
+func (s *Sharded) ShardFor(key string) string {
+	return s.names[s.shardIndex(key)]
+}
+
+func (s *Sharded) shardIndex(key string) int {
+	h := shardHash(key)
+	i := sort.Search(len(s.points), func(i int) bool { return s.points[i] >= h })
+	if i == len(s.points) {
+		i = 0
+	}
+	return s.owners[i]
+}
+
+func (s *Sharded) Put(ctx context.Context, entry *Entry) error {
+	return s.shards[s.shardIndex(entry.Key)].Put(ctx, entry)
+}
+
+func (s *Sharded) Get(ctx context.Context, key string) (*Entry, error) {
+	return s.shards[s.shardIndex(key)].Get(ctx, key)
+}
+
+func (s *Sharded) Delete(ctx context.Context, key string) error {
+	return s.shards[s.shardIndex(key)].Delete(ctx, key)
+}
+
+// BatchGet groups the keys by shard and fetches each group in one batch.
+func (s *Sharded) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	groups := make(map[int][]int)
+	for i, key := range keys {
+		shard := s.shardIndex(key)
+		groups[shard] = append(groups[shard], i)
+	}
+
+	ret := make([]*Entry, len(keys))
+	err := s.fanOut(ctx, func(ctx context.Context, shard int) error {
+		idxs := groups[shard]
+		if len(idxs) == 0 {
+			return nil
+		}
+		shardKeys := make([]string, len(idxs))
+		for j, i := range idxs {
+			shardKeys[j] = keys[i]
+		}
+		entries, err := BatchGetEntries(ctx, s.shards[shard], shardKeys)
+		if err != nil {
+			return err
+		}
+		for j, i := range idxs {
+			ret[i] = entries[j]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// List lists the prefix in all shards and merges the results. Directories
+// are usually present in several shards and are only returned once.
+func (s *Sharded) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.listMerged(ctx, func(ctx context.Context, b Backend) ([]string, error) {
+		keys, err := b.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(keys)
+		return keys, nil
+	}, 0)
+}
+
+// ListPage lists a page of the prefix in all shards and merge-sorts the
+// results. As keys are ordered the same way in all shards, the last entry
+// of a page is a cursor valid for all of them: each shard is asked for the
+// entries following it, and the first limit entries of the merge are the
+// first limit entries overall.
+func (s *Sharded) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return s.listMerged(ctx, func(ctx context.Context, b Backend) ([]string, error) {
+		return b.ListPage(ctx, prefix, after, limit)
+	}, limit)
+}
+
+// listMerged runs a sorted listing on all shards and merges the results,
+// removing duplicates, up to limit entries if positive.
+func (s *Sharded) listMerged(ctx context.Context, list func(context.Context, Backend) ([]string, error), limit int) ([]string, error) {
+	results := make([][]string, len(s.shards))
+	err := s.fanOut(ctx, func(ctx context.Context, shard int) error {
+		keys, err := list(ctx, s.shards[shard])
+		if err != nil {
+			return fmt.Errorf("failed to list shard %q: %w", s.names[shard], err)
+		}
+		results[shard] = keys
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	pos := make([]int, len(results))
+	for limit <= 0 || len(ret) < limit {
+		next := -1
+		for shard, keys := range results {
+			if pos[shard] < len(keys) && (next == -1 || keys[pos[shard]] < results[next][pos[next]]) {
+				next = shard
+			}
+		}
+		if next == -1 {
+			break
+		}
+
+		key := results[next][pos[next]]
+		if len(ret) == 0 || ret[len(ret)-1] != key {
+			ret = append(ret, key)
+		}
+		pos[next]++
+	}
+	return ret, nil
+}
+
+// fanOut calls fn for every shard in parallel, returning the first error.
+func (s *Sharded) fanOut(ctx context.Context, fn func(context.Context, int) error) error {
+	if len(s.shards) == 1 {
+		return fn(ctx, 0)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(s.shards))
+	for shard := range s.shards {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			if errs[shard] = fn(ctx, shard); errs[shard] != nil {
+				cancel()
+			}
+		}(shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
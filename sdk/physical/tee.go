@@ -0,0 +1,573 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package physical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
+)
+
+const (
+	// DefaultTeeQueueSize is used if no queue size is specified in TeeConfig
+	DefaultTeeQueueSize = 4096
+
+	// DefaultTeeRetryInterval is used if no retry interval is specified in
+	// TeeConfig
+	DefaultTeeRetryInterval = time.Second
+)
+
+// ErrTeeDegraded is returned by Tee.Flush while the secondary is missing
+// writes which were dropped from the mirror queue.
+var ErrTeeDegraded = errors.New("tee mirror is degraded, reconciliation required")
+
+// TeeState is the state of the mirror of a Tee.
+type TeeState string
+
+const (
+	// TeeStateHealthy means every write to the primary is queued to be
+	// mirrored to the secondary.
+	TeeStateHealthy TeeState = "healthy"
+
+	// TeeStateDegraded means the mirror queue overflowed, so that writes
+	// were dropped and the secondary must be reconciled with the primary.
+	TeeStateDegraded TeeState = "degraded"
+
+	// TeeStateReconciling means the secondary is being reconciled with the
+	// primary after the mirror was degraded.
+	TeeStateReconciling TeeState = "reconciling"
+)
+
+// TeeConfig configures a Tee created with NewTee.
+type TeeConfig struct {
+	// QueueSize is the maximum number of writes waiting to be mirrored to
+	// the secondary. Once it is exceeded, the queue is dropped and the
+	// mirror is degraded until the secondary is reconciled.
+	QueueSize int
+
+	// RetryInterval is how long to wait before retrying to mirror writes,
+	// or to reconcile, after the secondary failed.
+	RetryInterval time.Duration
+}
+
+// TeeStatus is a point-in-time summary of the mirror of a Tee.
+type TeeStatus struct {
+	// State is the state of the mirror.
+	State TeeState
+
+	// Pending is the number of writes waiting to be mirrored.
+	Pending int
+
+	// Lag is how far behind the primary the secondary is: the age of the
+	// oldest write not mirrored yet, or how long the mirror has been
+	// degraded.
+	Lag time.Duration
+
+	// Dropped is the number of writes discarded from the mirror queue since
+	// the secondary was last reconciled.
+	Dropped uint64
+
+	// LastError is the last error returned by the secondary, cleared once
+	// a write is mirrored successfully.
+	LastError error
+}
+
+// teeOp is a write queued to be mirrored. A nil entry denotes a delete.
+type teeOp struct {
+	seq      uint64
+	key      string
+	entry    *Entry
+	queuedAt time.Time
+}
+
+// Tee wraps a primary physical backend and mirrors every write made to it
+// to a secondary backend asynchronously, such as to keep a live backup for
+// point-in-time recovery. All reads are served from the primary, and a write
+// succeeds as soon as the primary applied it.
+//
+// Writes are mirrored in order from a bounded queue, and retried while the
+// secondary is unavailable. If the queue overflows, it is dropped and the
+// mirror is degraded: the secondary is then brought up to date by a
+// reconciliation, copying every key of the primary over, which is attempted
+// automatically until it succeeds. The Tee must be the only writer to the
+// secondary.
+type Tee struct {
+	primary       Backend
+	secondary     Backend
+	logger        log.Logger
+	metricSink    metrics.MetricSink
+	queueSize     int
+	retryInterval time.Duration
+
+	// locks serialize the writes to a key with its reconciliation, so that
+	// the reconciliation never mirrors an older value after a newer write
+	// has been queued
+	locks []*locksutil.LockEntry
+
+	// reconcileLock ensures a single reconciliation runs at a time
+	reconcileLock sync.Mutex
+
+	lock          sync.Mutex
+	queue         []*teeOp
+	seq           uint64
+	appliedSeq    uint64
+	state         TeeState
+	degradedSince time.Time
+	dropped       uint64
+	lastErr       error
+
+	// progressCh is closed and replaced whenever writes are mirrored or the
+	// state changes, waking up callers of Flush
+	progressCh chan struct{}
+
+	wakeCh   chan struct{}
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// Verify Tee satisfies the correct interfaces
+var (
+	_ Backend     = (*Tee)(nil)
+	_ BatchGetter = (*Tee)(nil)
+)
+
+// NewTee returns a physical backend serving reads from primary and mirroring
+// writes to secondary, and starts mirroring in the background until Stop is
+// called.
+func NewTee(primary, secondary Backend, config TeeConfig, logger log.Logger, metricSink metrics.MetricSink) *Tee {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultTeeQueueSize
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultTeeRetryInterval
+	}
+
+	t := &Tee{
+		primary:       primary,
+		secondary:     secondary,
+		logger:        logger,
+		metricSink:    metricSink,
+		queueSize:     config.QueueSize,
+		retryInterval: config.RetryInterval,
+		locks:         locksutil.CreateLocks(),
+		state:         TeeStateHealthy,
+		progressCh:    make(chan struct{}),
+		wakeCh:        make(chan struct{}, 1),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Stop stops mirroring and waits for the background goroutine to return.
+// Writes still queued are not mirrored: call Flush beforehand to wait for
+// them, or Reconcile the secondary afterwards.
+func (t *Tee) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopCh)
+		<-t.doneCh
+	})
+}
+
+// Status returns the current status of the mirror.
+func (t *Tee) Status() TeeStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return TeeStatus{
+		State:     t.state,
+		Pending:   len(t.queue),
+		Lag:       t.lagLocked(),
+		Dropped:   t.dropped,
+		LastError: t.lastErr,
+	}
+}
+
+// Lag returns how far behind the primary the secondary is.
+func (t *Tee) Lag() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.lagLocked()
+}
+
+func (t *Tee) lagLocked() time.Duration {
+	switch {
+	case !t.degradedSince.IsZero():
+		return time.Since(t.degradedSince)
+	case len(t.queue) > 0:
+		return time.Since(t.queue[0].queuedAt)
+	default:
+		return 0
+	}
+}
+
+// Flush blocks until every write made before the call has been mirrored to
+// the secondary, including through a reconciliation in progress. It fails
+// with ErrTeeDegraded if the mirror is degraded, or once ctx ends.
+func (t *Tee) Flush(ctx context.Context) error {
+	t.lock.Lock()
+	target := t.seq
+	t.lock.Unlock()
+
+	for {
+		t.lock.Lock()
+		state, applied, ch := t.state, t.appliedSeq, t.progressCh
+		t.lock.Unlock()
+
+		switch {
+		case state == TeeStateDegraded:
+			return ErrTeeDegraded
+		case state == TeeStateHealthy && applied >= target:
+			return nil
+		}
+
+		t.wake()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (t *Tee) Put(ctx context.Context, entry *Entry) error {
+	lock := locksutil.LockForKey(t.locks, entry.Key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := t.primary.Put(ctx, entry); err != nil {
+		return err
+	}
+
+	// Callers may reuse the entry once the write returned.
+	mirrored := *entry
+	mirrored.Value = append([]byte(nil), entry.Value...)
+	t.enqueue(entry.Key, &mirrored)
+	return nil
+}
+
+func (t *Tee) Get(ctx context.Context, key string) (*Entry, error) {
+	return t.primary.Get(ctx, key)
+}
+
+func (t *Tee) BatchGet(ctx context.Context, keys []string) ([]*Entry, error) {
+	return BatchGetEntries(ctx, t.primary, keys)
+}
+
+func (t *Tee) Delete(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(t.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := t.primary.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.enqueue(key, nil)
+	return nil
+}
+
+func (t *Tee) List(ctx context.Context, prefix string) ([]string, error) {
+	return t.primary.List(ctx, prefix)
+}
+
+func (t *Tee) ListPage(ctx context.Context, prefix string, after string, limit int) ([]string, error) {
+	return t.primary.ListPage(ctx, prefix, after, limit)
+}
+
+// enqueue queues a write applied to the primary to be mirrored, degrading
+// the mirror if the queue is full. The key lock must be held.
+func (t *Tee) enqueue(key string, entry *Entry) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.seq++
+	if t.state == TeeStateDegraded {
+		t.dropped++
+		t.appliedSeq = t.seq
+		return
+	}
+
+	if len(t.queue) >= t.queueSize {
+		t.logger.Warn("tee mirror queue is full, dropping it until the secondary is reconciled", "queue_size", t.queueSize)
+		t.dropped += uint64(len(t.queue)) + 1
+		if t.degradedSince.IsZero() {
+			t.degradedSince = t.queue[0].queuedAt
+		}
+		t.queue = nil
+		t.appliedSeq = t.seq
+		t.setStateLocked(TeeStateDegraded)
+		t.metricSink.IncrCounter([]string{"tee", "degraded"}, 1)
+		return
+	}
+
+	t.queue = append(t.queue, &teeOp{
+		seq:      t.seq,
+		key:      key,
+		entry:    entry,
+		queuedAt: time.Now(),
+	})
+	t.wake()
+}
+
+// setStateLocked changes the state of the mirror and wakes up Flush callers.
+func (t *Tee) setStateLocked(state TeeState) {
+	t.state = state
+	t.notifyLocked()
+}
+
+func (t *Tee) notifyLocked() {
+	close(t.progressCh)
+	t.progressCh = make(chan struct{})
+}
+
+func (t *Tee) wake() {
+	select {
+	case t.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (t *Tee) run() {
+	defer close(t.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-t.stopCh:
+			cancel()
+		case <-t.doneCh:
+		}
+	}()
+
+	ticker := time.NewTicker(t.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		t.mirror(ctx)
+		t.emitMetrics()
+
+		select {
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+		case <-t.wakeCh:
+		}
+	}
+}
+
+// mirror reconciles the secondary if the mirror is degraded, then applies
+// queued writes to it until the queue is empty or the secondary fails.
+func (t *Tee) mirror(ctx context.Context) {
+	t.lock.Lock()
+	degraded := t.state == TeeStateDegraded
+	t.lock.Unlock()
+
+	if degraded {
+		if err := t.Reconcile(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			t.logger.Error("failed to reconcile tee secondary, will retry", "error", err, "retry_interval", t.retryInterval)
+			return
+		}
+	}
+
+	for {
+		t.lock.Lock()
+		if len(t.queue) == 0 {
+			t.lock.Unlock()
+			return
+		}
+		op := t.queue[0]
+		t.lock.Unlock()
+
+		var err error
+		if op.entry == nil {
+			err = t.secondary.Delete(ctx, op.key)
+		} else {
+			err = t.secondary.Put(ctx, op.entry)
+		}
+
+		t.lock.Lock()
+		if err != nil {
+			if t.lastErr == nil {
+				t.logger.Warn("failed to mirror write to tee secondary, will retry", "key", op.key, "error", err)
+			}
+			t.lastErr = err
+			t.lock.Unlock()
+			t.metricSink.IncrCounter([]string{"tee", "mirror", "error"}, 1)
+			return
+		}
+		if t.lastErr != nil {
+			t.logger.Info("tee secondary is available again, resuming mirroring")
+			t.lastErr = nil
+		}
+		// The queue may have been dropped by an overflow meanwhile.
+		if len(t.queue) > 0 && t.queue[0] == op {
+			t.queue = t.queue[1:]
+			if op.seq > t.appliedSeq {
+				t.appliedSeq = op.seq
+			}
+			t.notifyLocked()
+		}
+		t.lock.Unlock()
+	}
+}
+
+func (t *Tee) emitMetrics() {
+	status := t.Status()
+	degraded := float32(0)
+	if status.State != TeeStateHealthy {
+		degraded = 1
+	}
+	t.metricSink.SetGauge([]string{"tee", "lag"}, float32(status.Lag.Milliseconds()))
+	t.metricSink.SetGauge([]string{"tee", "queue_depth"}, float32(status.Pending))
+	t.metricSink.SetGauge([]string{"tee", "degraded"}, degraded)
+}
+
+// Reconcile brings the secondary up to date with the primary: every key of
+// the primary is copied over, and every key of the secondary missing from
+// the primary is deleted. Writes keep being queued meanwhile, so that the
+// mirror is healthy again once it completes. This runs automatically while
+// the mirror is degraded, and may be called at any time, such as after the
+// secondary was restored from an older backup.
+func (t *Tee) Reconcile(ctx context.Context) error {
+	t.reconcileLock.Lock()
+	defer t.reconcileLock.Unlock()
+
+	t.lock.Lock()
+	wasDegraded := t.state == TeeStateDegraded
+	if wasDegraded {
+		t.setStateLocked(TeeStateReconciling)
+	}
+	t.lock.Unlock()
+
+	t.logger.Info("reconciling tee secondary with the primary")
+	err := t.reconcileTree(ctx, "")
+	if err == nil {
+		err = t.pruneTree(ctx, "")
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err != nil {
+		if t.state == TeeStateReconciling {
+			t.setStateLocked(TeeStateDegraded)
+		}
+		t.lastErr = err
+		return err
+	}
+
+	switch t.state {
+	case TeeStateDegraded:
+		// The queue overflowed again meanwhile.
+		return ErrTeeDegraded
+	case TeeStateReconciling:
+		t.setStateLocked(TeeStateHealthy)
+	}
+	t.degradedSince = time.Time{}
+	t.dropped = 0
+	t.lastErr = nil
+	t.logger.Info("tee secondary reconciled with the primary")
+	return nil
+}
+
+// reconcileTree copies every key of the primary under prefix to the
+// secondary.
+func (t *Tee) reconcileTree(ctx context.Context, prefix string) error {
+	keys, err := t.primary.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list primary prefix %q: %w", prefix, err)
+	}
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		path := prefix + key
+		if strings.HasSuffix(key, "/") {
+			if err := t.reconcileTree(ctx, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.reconcileKey(ctx, path); err != nil {
+			return fmt.Errorf("failed to reconcile key %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// pruneTree deletes every key of the secondary under prefix which is
+// missing from the primary.
+func (t *Tee) pruneTree(ctx context.Context, prefix string) error {
+	keys, err := t.secondary.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list secondary prefix %q: %w", prefix, err)
+	}
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		path := prefix + key
+		if strings.HasSuffix(key, "/") {
+			if err := t.pruneTree(ctx, path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.pruneKey(ctx, path); err != nil {
+			return fmt.Errorf("failed to prune key %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// pruneKey deletes a key from the secondary if it is missing from the
+// primary, holding the key lock like reconcileKey.
+func (t *Tee) pruneKey(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(t.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := t.primary.Get(ctx, key)
+	if err != nil || entry != nil {
+		return err
+	}
+	return t.secondary.Delete(ctx, key)
+}
+
+// reconcileKey copies the current value of a key from the primary to the
+// secondary, deleting it from the secondary if it is missing. The key lock
+// is held so that concurrent writes are queued either before or after the
+// copy.
+func (t *Tee) reconcileKey(ctx context.Context, key string) error {
+	lock := locksutil.LockForKey(t.locks, key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := t.primary.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return t.secondary.Delete(ctx, key)
+	}
+	return t.secondary.Put(ctx, entry)
+}
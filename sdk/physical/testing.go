@@ -387,6 +387,48 @@ func ExerciseBackend_ListPrefix(t testing.TB, b Backend) {
 	}
 }
 
+// ExerciseBackend_CompareAndSwap exercises CompareAndSwap against the
+// backend, natively if it implements CASBackend.
+func ExerciseBackend_CompareAndSwap(t testing.TB, b Backend) {
+	t.Helper()
+
+	ctx := context.Background()
+	defer b.Delete(ctx, "cas")
+
+	cas := func(value string, expectedHash []byte) bool {
+		t.Helper()
+		swapped, err := CompareAndSwap(ctx, b, &Entry{Key: "cas", Value: []byte(value)}, expectedHash)
+		require.NoError(t, err)
+		return swapped
+	}
+	requireValue := func(value string) {
+		t.Helper()
+		entry, err := b.Get(ctx, "cas")
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		require.Equal(t, value, string(entry.Value))
+	}
+
+	// A nil hash only creates the entry
+	require.True(t, cas("one", nil))
+	requireValue("one")
+	require.False(t, cas("two", nil))
+	requireValue("one")
+
+	// Otherwise the hash must match the current value
+	require.False(t, cas("two", HashValue([]byte("other"))))
+	requireValue("one")
+	require.True(t, cas("two", HashValue([]byte("one"))))
+	requireValue("two")
+
+	// Missing entries never match a hash
+	require.NoError(t, b.Delete(ctx, "cas"))
+	require.False(t, cas("three", HashValue(nil)))
+	entry, err := b.Get(ctx, "cas")
+	require.NoError(t, err)
+	require.Nil(t, entry)
+}
+
 func ExerciseHABackend(t testing.TB, b HABackend, b2 HABackend) {
 	t.Helper()
 
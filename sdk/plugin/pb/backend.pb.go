@@ -127,6 +127,7 @@ type ProtoError struct {
 	// ErrTypePermissionDenied
 	// ErrTypeMultiAuthzPending
 	// ErrTypeUnrecoverable
+	// ErrTypeLeaseInvalidated
 	ErrType uint32 `protobuf:"varint,1,opt,name=err_type,json=errType,proto3" json:"err_type,omitempty"`
 	ErrMsg  string `protobuf:"bytes,2,opt,name=err_msg,json=errMsg,proto3" json:"err_msg,omitempty"`
 	ErrCode int64  `protobuf:"varint,3,opt,name=err_code,json=errCode,proto3" json:"err_code,omitempty"`
@@ -29,6 +29,7 @@ const (
 	ErrTypePermissionDenied
 	ErrTypeMultiAuthzPending
 	ErrTypeUnrecoverable
+	ErrTypeLeaseInvalidated
 )
 
 func ProtoErrToErr(e *ProtoError) error {
@@ -60,6 +61,8 @@ func ProtoErrToErr(e *ProtoError) error {
 		err = logical.ErrMultiAuthzPending
 	case ErrTypeUnrecoverable:
 		err = logical.ErrUnrecoverable
+	case ErrTypeLeaseInvalidated:
+		err = logical.ErrLeaseInvalidated
 	}
 
 	return err
@@ -99,6 +102,8 @@ func ErrToProtoErr(e error) *ProtoError {
 		pbErr.ErrType = ErrTypeMultiAuthzPending
 	case e == logical.ErrUnrecoverable:
 		pbErr.ErrType = ErrTypeUnrecoverable
+	case e == logical.ErrLeaseInvalidated:
+		pbErr.ErrType = ErrTypeLeaseInvalidated
 	}
 
 	return pbErr
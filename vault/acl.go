@@ -35,6 +35,10 @@ type ACL struct {
 
 	// Stores policies that are actually RGPs for later fetching
 	rgpPolicies []*Policy
+
+	// strictPatch disables granting the "patch" capability along with
+	// "update"; see effectiveCapabilities
+	strictPatch bool
 }
 
 type PolicyCheckOpts struct {
@@ -66,6 +70,13 @@ type SentinelResults struct {
 
 // NewACL is used to construct a policy based ACL from a set of policies.
 func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
+	return newACL(ctx, policies, false)
+}
+
+// newACL constructs a policy based ACL from a set of policies. If trace is
+// set, the ACL records which policy rules make up each of its permissions, so
+// that its decisions can be explained.
+func newACL(ctx context.Context, policies []*Policy, trace bool) (*ACL, error) {
 	// Initialize
 	a := &ACL{
 		exactRules:           radix.New(),
@@ -135,6 +146,10 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 				// Store this policy name as the policy that permits these
 				// capabilities
 				clonedPerms.GrantingPoliciesMap = addGrantingPoliciesToMap(nil, policy, clonedPerms.CapabilitiesBitmap)
+				if trace {
+					clonedPerms.rulePath = pc.globPath()
+					clonedPerms.sources = []aclRuleSource{newACLRuleSource(policy, pc.Permissions.CapabilitiesBitmap)}
+				}
 				switch {
 				case pc.HasSegmentWildcards:
 					a.segmentWildcardPaths[pc.Path] = clonedPerms
@@ -146,6 +161,9 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 
 			// these are the ones already in the tree
 			existingPerms := raw.(*ACLPermissions)
+			if trace {
+				existingPerms.sources = append(existingPerms.sources, newACLRuleSource(policy, pc.Permissions.CapabilitiesBitmap))
+			}
 
 			switch {
 			case existingPerms.CapabilitiesBitmap&DenyCapabilityInt > 0:
@@ -311,6 +329,17 @@ func (a *ACL) Capabilities(ctx context.Context, path string) (pathCapabilities [
 	return
 }
 
+// effectiveCapabilities returns the capabilities granted by a bitmap of
+// policy capabilities. For backwards compatibility with policies written
+// before the "patch" capability existed, "update" also grants "patch" unless
+// the ACL is strict about it.
+func (a *ACL) effectiveCapabilities(capabilitiesBitmap uint32) uint32 {
+	if !a.strictPatch && capabilitiesBitmap&UpdateCapabilityInt > 0 {
+		capabilitiesBitmap |= PatchCapabilityInt
+	}
+	return capabilitiesBitmap
+}
+
 // AllowOperation is used to check if the given operation is permitted.
 func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheckOnly bool) (ret *ACLResults) {
 	ret = new(ACLResults)
@@ -354,44 +383,14 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 		}
 	}
 
-	// Find an exact matching rule, look for prefix if no match
-	var capabilities uint32
-	raw, ok := a.exactRules.Get(path)
-	if ok {
-		permissions = raw.(*ACLPermissions)
-		capabilities = permissions.CapabilitiesBitmap
-		goto CHECK
-	}
-	if op == logical.ListOperation {
-		raw, ok = a.exactRules.Get(strings.TrimSuffix(path, "/"))
-		if ok {
-			permissions = raw.(*ACLPermissions)
-			capabilities = permissions.CapabilitiesBitmap
-			goto CHECK
-		}
-	}
-
-	// List operations need to check without the trailing slash first, because
-	// there could be other rules with trailing wildcards that will match the
-	// path
-	if op == logical.ListOperation && strings.HasSuffix(path, "/") {
-		permissions = a.CheckAllowedFromNonExactPaths(strings.TrimSuffix(path, "/"), false)
-		if permissions != nil {
-			capabilities = permissions.CapabilitiesBitmap
-			goto CHECK
-		}
-	}
-	permissions = a.CheckAllowedFromNonExactPaths(path, false)
-	if permissions != nil {
-		capabilities = permissions.CapabilitiesBitmap
-		goto CHECK
+	permissions = a.matchingPermissions(path, op)
+	if permissions == nil {
+		// No exact, prefix, or segment wildcard paths found, return without
+		// setting allowed
+		return
 	}
+	capabilities := a.effectiveCapabilities(permissions.CapabilitiesBitmap)
 
-	// No exact, prefix, or segment wildcard paths found, return without
-	// setting allowed
-	return
-
-CHECK:
 	// Check if the minimum permissions are met
 	// If "deny" has been explicitly set, only deny will be in the map, so we
 	// only need to check for the existence of other values
@@ -427,6 +426,10 @@ CHECK:
 	case logical.PatchOperation:
 		operationAllowed = capabilities&PatchCapabilityInt > 0
 		grantingPolicies = permissions.GrantingPoliciesMap[PatchCapabilityInt]
+		if permissions.CapabilitiesBitmap&PatchCapabilityInt == 0 {
+			// Granted through "update"
+			grantingPolicies = permissions.GrantingPoliciesMap[UpdateCapabilityInt]
+		}
 
 	// These three re-use UpdateCapabilityInt since that's the most appropriate
 	// capability/operation mapping
@@ -528,6 +531,34 @@ CHECK:
 	return
 }
 
+// matchingPermissions returns the permissions of the rule controlling the
+// given operation on the path, relative to the root namespace, or nil if no
+// rule matches it.
+func (a *ACL) matchingPermissions(path string, op logical.Operation) *ACLPermissions {
+	// Find an exact matching rule, look for prefix if no match
+	raw, ok := a.exactRules.Get(path)
+	if ok {
+		return raw.(*ACLPermissions)
+	}
+	if op == logical.ListOperation {
+		raw, ok = a.exactRules.Get(strings.TrimSuffix(path, "/"))
+		if ok {
+			return raw.(*ACLPermissions)
+		}
+	}
+
+	// List operations need to check without the trailing slash first, because
+	// there could be other rules with trailing wildcards that will match the
+	// path
+	if op == logical.ListOperation && strings.HasSuffix(path, "/") {
+		permissions := a.CheckAllowedFromNonExactPaths(strings.TrimSuffix(path, "/"), false)
+		if permissions != nil {
+			return permissions
+		}
+	}
+	return a.CheckAllowedFromNonExactPaths(path, false)
+}
+
 type wcPathDescr struct {
 	firstWCOrGlob int
 	wildcards     int
@@ -0,0 +1,172 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// aclCapabilityOrder is the order in which capabilities are reported.
+var aclCapabilityOrder = []string{
+	DenyCapability,
+	SudoCapability,
+	ReadCapability,
+	ListCapability,
+	UpdateCapability,
+	DeleteCapability,
+	CreateCapability,
+	PatchCapability,
+}
+
+// aclRuleSource records a policy path rule merged into a set of ACL
+// permissions.
+type aclRuleSource struct {
+	policy             logical.PolicyInfo
+	capabilitiesBitmap uint32
+}
+
+func newACLRuleSource(policy *Policy, capabilitiesBitmap uint32) aclRuleSource {
+	return aclRuleSource{
+		policy: logical.PolicyInfo{
+			Name:          policy.Name,
+			NamespaceId:   policy.namespace.ID,
+			NamespacePath: policy.namespace.Path,
+			Type:          "acl",
+		},
+		capabilitiesBitmap: capabilitiesBitmap,
+	}
+}
+
+// globPath returns the path of the rule as written in policies.
+func (pc *PathRules) globPath() string {
+	if pc.IsPrefix && !pc.HasSegmentWildcards {
+		return pc.Path + "*"
+	}
+	return pc.Path
+}
+
+// aclCapabilityNames returns the names of the capabilities of the bitmap.
+func aclCapabilityNames(capabilitiesBitmap uint32) []string {
+	names := []string{}
+	for _, name := range aclCapabilityOrder {
+		if capabilitiesBitmap&cap2Int[name] > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// operationCapability returns the capability required by the operation, or an
+// empty string if the operation is not controlled by a capability.
+func operationCapability(op logical.Operation) string {
+	switch op {
+	case logical.ReadOperation:
+		return ReadCapability
+	case logical.ListOperation:
+		return ListCapability
+	case logical.UpdateOperation, logical.RevokeOperation, logical.RenewOperation, logical.RollbackOperation:
+		return UpdateCapability
+	case logical.DeleteOperation:
+		return DeleteCapability
+	case logical.CreateOperation:
+		return CreateCapability
+	case logical.PatchOperation:
+		return PatchCapability
+	default:
+		return ""
+	}
+}
+
+// ACLRuleExplanation describes a policy rule taking part in an ACL decision.
+type ACLRuleExplanation struct {
+	Policy       logical.PolicyInfo
+	Capabilities []string
+}
+
+// ACLExplanation describes how an ACL decides on a request.
+type ACLExplanation struct {
+	// Allowed is true if the ACL allows the request.
+	Allowed bool
+
+	// Root is true if the ACL is the one of the root policy.
+	Root bool
+
+	// Capability is the capability the operation of the request requires.
+	Capability string
+
+	// MatchedPath is the path glob of the rules controlling the request, as
+	// written in the policies, if any rule matches the request path.
+	MatchedPath string
+
+	// Capabilities are the capabilities granted on the matched path.
+	Capabilities []string
+
+	// Rules are the policy rules on the matched path, which make up the
+	// capabilities granted.
+	Rules []*ACLRuleExplanation
+
+	// Reason describes the decision, without naming any policy.
+	Reason string
+}
+
+// Explain describes how the ACL decides on the request. The ACL must have
+// been built with tracing for the rules to be reported.
+func (a *ACL) Explain(ctx context.Context, req *logical.Request) (*ACLExplanation, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &ACLExplanation{
+		Capability: operationCapability(req.Operation),
+	}
+
+	res := a.AllowOperation(ctx, req, false)
+	ret.Allowed = res.Allowed
+	if res.IsRoot {
+		ret.Root = true
+		ret.Capabilities = []string{RootCapability}
+		ret.Reason = "the root policy grants every capability"
+		return ret, nil
+	}
+
+	perms := a.matchingPermissions(strings.TrimLeft(ns.Path+req.Path, "/"), req.Operation)
+	if perms == nil {
+		ret.Capabilities = []string{}
+		ret.Reason = "no policy rule matches the path"
+		return ret, nil
+	}
+
+	capabilities := a.effectiveCapabilities(perms.CapabilitiesBitmap)
+	ret.MatchedPath = perms.rulePath
+	ret.Capabilities = aclCapabilityNames(capabilities)
+	for _, source := range perms.sources {
+		ret.Rules = append(ret.Rules, &ACLRuleExplanation{
+			Policy:       source.policy,
+			Capabilities: aclCapabilityNames(source.capabilitiesBitmap),
+		})
+	}
+
+	switch {
+	case perms.CapabilitiesBitmap&DenyCapabilityInt > 0:
+		ret.Reason = "a policy rule on the matched path explicitly denies access"
+	case ret.Capability == "":
+		ret.Reason = fmt.Sprintf("the %q operation is not controlled by capabilities", req.Operation)
+	case capabilities&cap2Int[ret.Capability] == 0:
+		ret.Reason = fmt.Sprintf("no policy rule on the matched path grants the %q capability", ret.Capability)
+	case !ret.Allowed:
+		ret.Reason = fmt.Sprintf("the %q capability is granted on the matched path, but the request does not meet its parameter or response wrapping constraints", ret.Capability)
+	case perms.CapabilitiesBitmap&cap2Int[ret.Capability] == 0:
+		ret.Reason = fmt.Sprintf("the %q capability is implied by the %q capability granted on the matched path", ret.Capability, UpdateCapability)
+	default:
+		ret.Reason = fmt.Sprintf("the %q capability is granted on the matched path", ret.Capability)
+	}
+
+	return ret, nil
+}
@@ -140,18 +140,74 @@ func testACLCapabilities(t *testing.T, ns *namespace.Namespace) {
 	}
 
 	actual = acl.Capabilities(ctx, "dev/")
-	expected = []string{"sudo", "read", "list", "update", "delete", "create"}
+	expected = []string{"sudo", "read", "list", "update", "delete", "create", "patch"}
 	if !reflect.DeepEqual(actual, expected) {
 		t.Fatalf("bad: path: %s\ngot\n%#v\nexpected\n%#v\n", "dev/", actual, expected)
 	}
 
 	actual = acl.Capabilities(ctx, "stage/aws/test")
-	expected = []string{"sudo", "read", "list", "update"}
+	expected = []string{"sudo", "read", "list", "update", "patch"}
 	if !reflect.DeepEqual(actual, expected) {
 		t.Fatalf("bad: path: %s\ngot\n%#v\nexpected\n%#v\n", "stage/aws/test", actual, expected)
 	}
 }
 
+func TestACL_PatchCapability(t *testing.T) {
+	ns := namespace.RootNamespace
+	ctx := namespace.ContextWithNamespace(context.Background(), ns)
+	policy, err := ParseACLPolicy(ns, grantingTestPolicy)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	policyInfo := []logical.PolicyInfo{{
+		Name:          "granting_policy",
+		NamespaceId:   "root",
+		NamespacePath: "",
+		Type:          "acl",
+	}}
+
+	patch := &logical.Request{
+		Path:      "kv/path/longer2",
+		Operation: logical.PatchOperation,
+	}
+
+	// By default, "update" implies "patch"
+	acl, err := NewACL(ctx, []*Policy{policy})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	res := acl.AllowOperation(ctx, patch, false)
+	if !res.Allowed {
+		t.Fatal("expected patch to be allowed by update")
+	}
+	if !reflect.DeepEqual(res.GrantingPolicies, policyInfo) {
+		t.Fatalf("bad granting policies: %#v", res.GrantingPolicies)
+	}
+	actual := acl.Capabilities(ctx, "kv/path/longer2")
+	expected := []string{"update", "patch"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: got\n%#v\nexpected\n%#v\n", actual, expected)
+	}
+
+	// Paths without "update" still require "patch"
+	patch.Path = "kv/path/foo"
+	if acl.AllowOperation(ctx, patch, false).Allowed {
+		t.Fatal("expected patch to be denied")
+	}
+
+	// In strict mode, "update" does not imply "patch"
+	acl.strictPatch = true
+	patch.Path = "kv/path/longer2"
+	if acl.AllowOperation(ctx, patch, false).Allowed {
+		t.Fatal("expected patch to be denied in strict mode")
+	}
+	actual = acl.Capabilities(ctx, "kv/path/longer2")
+	expected = []string{"update"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: got\n%#v\nexpected\n%#v\n", actual, expected)
+	}
+}
+
 func TestACL_Root(t *testing.T) {
 	t.Run("root-ns", func(t *testing.T) {
 		t.Parallel()
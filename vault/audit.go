@@ -102,6 +102,11 @@ func (c *Core) enableAudit(ctx context.Context, entry *MountEntry, updateStorage
 		}
 		entry.Accessor = accessor
 	}
+	filter, err := parseAuditFilter(entry.Options)
+	if err != nil {
+		return err
+	}
+
 	viewPath := entry.ViewPath()
 	view := NewBarrierView(c.barrier, viewPath)
 	origViewReadOnlyErr := view.getReadOnlyErr()
@@ -154,7 +159,7 @@ func (c *Core) enableAudit(ctx context.Context, entry *MountEntry, updateStorage
 	c.audit = newTable
 
 	// Register the backend
-	c.auditBroker.Register(entry.Path, backend, view, entry.Local)
+	c.auditBroker.Register(entry.Path, backend, view, entry.Local, filter)
 	if c.logger.IsInfo() {
 		c.logger.Info("enabled audit backend", "path", entry.Path, "type", entry.Type)
 	}
@@ -207,7 +212,7 @@ func (c *Core) disableAudit(ctx context.Context, path string, updateStorage bool
 	c.audit = newTable
 
 	// Unmount the backend
-	c.auditBroker.Deregister(path)
+	c.auditBroker.Deregister(ctx, path)
 	if c.logger.IsInfo() {
 		c.logger.Info("disabled audit backend", "path", path)
 	}
@@ -411,8 +416,16 @@ func (c *Core) setupAudits(ctx context.Context) error {
 			continue
 		}
 
+		// A filter which cannot be parsed must not cause entries to be
+		// dropped, so the backend logs everything instead.
+		filter, err := parseAuditFilter(entry.Options)
+		if err != nil {
+			c.logger.Error("invalid audit entry filter, logging all entries", "path", entry.Path, "error", err)
+			filter = nil
+		}
+
 		// Mount the backend
-		broker.Register(entry.Path, backend, view, entry.Local)
+		broker.Register(entry.Path, backend, view, entry.Local, filter)
 
 		successCount++
 	}
@@ -437,6 +450,10 @@ func (c *Core) teardownAudits() error {
 		}
 	}
 
+	if c.auditBroker != nil {
+		c.auditBroker.Close(context.Background())
+	}
+
 	c.audit = nil
 	c.auditBroker = nil
 	return nil
@@ -460,21 +477,25 @@ func (c *Core) removeAuditReloadFunc(entry *MountEntry) {
 	}
 }
 
+// auditSaltConfig returns the configuration of the salt used by audit backends
+// to HMAC sensitive values, which is stored in the view of each backend.
+func auditSaltConfig() *salt.Config {
+	return &salt.Config{
+		HMAC:     sha256.New,
+		HMACType: "hmac-sha256",
+		Location: salt.DefaultLocation,
+	}
+}
+
 // newAuditBackend is used to create and configure a new audit backend by name
 func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logical.Storage, conf map[string]string) (audit.Backend, error) {
 	f, ok := c.auditBackends[entry.Type]
 	if !ok {
 		return nil, fmt.Errorf("unknown backend type: %q", entry.Type)
 	}
-	saltConfig := &salt.Config{
-		HMAC:     sha256.New,
-		HMACType: "hmac-sha256",
-		Location: salt.DefaultLocation,
-	}
-
 	be, err := f(ctx, &audit.BackendConfig{
 		SaltView:   view,
-		SaltConfig: saltConfig,
+		SaltConfig: auditSaltConfig(),
 		Config:     conf,
 	})
 	if err != nil {
@@ -14,6 +14,8 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/openbao/openbao/audit"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/helper/salt"
 	"github.com/openbao/openbao/sdk/v2/logical"
 )
 
@@ -21,6 +23,7 @@ type backendEntry struct {
 	backend audit.Backend
 	view    *BarrierView
 	local   bool
+	filter  *auditFilter
 }
 
 // AuditBroker is used to provide a single ingest interface to auditable
@@ -40,22 +43,50 @@ func NewAuditBroker(log log.Logger) *AuditBroker {
 	return b
 }
 
-// Register is used to add new audit backend to the broker
-func (a *AuditBroker) Register(name string, b audit.Backend, v *BarrierView, local bool) {
+// Register is used to add new audit backend to the broker. Entries excluded
+// by the filter, if any, are not logged by the backend.
+func (a *AuditBroker) Register(name string, b audit.Backend, v *BarrierView, local bool, filter *auditFilter) {
 	a.Lock()
 	defer a.Unlock()
 	a.backends[name] = backendEntry{
 		backend: b,
 		view:    v,
 		local:   local,
+		filter:  filter,
 	}
 }
 
-// Deregister is used to remove an audit backend from the broker
-func (a *AuditBroker) Deregister(name string) {
+// Deregister is used to remove an audit backend from the broker. Backends
+// implementing audit.Closer are closed once they no longer receive entries.
+func (a *AuditBroker) Deregister(ctx context.Context, name string) {
 	a.Lock()
-	defer a.Unlock()
+	be, ok := a.backends[name]
 	delete(a.backends, name)
+	a.Unlock()
+
+	if ok {
+		a.closeBackend(ctx, name, be)
+	}
+}
+
+// Close closes all the registered backends implementing audit.Closer. It is
+// used when the broker is torn down.
+func (a *AuditBroker) Close(ctx context.Context) {
+	a.Lock()
+	defer a.Unlock()
+	for name, be := range a.backends {
+		a.closeBackend(ctx, name, be)
+	}
+}
+
+func (a *AuditBroker) closeBackend(ctx context.Context, name string, be backendEntry) {
+	closer, ok := be.backend.(audit.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(ctx); err != nil {
+		a.logger.Error("failed to close audit backend", "backend", name, "error", err)
+	}
 }
 
 // IsRegistered is used to check if a given audit backend is registered
@@ -89,6 +120,62 @@ func (a *AuditBroker) GetHash(ctx context.Context, name string, input string) (s
 	return be.backend.GetHash(ctx, input)
 }
 
+// GetHashWithEpoch returns a hash using the salt of the given backend as it
+// was during the given epoch, so that values logged before the salt was
+// rotated can be correlated with those logged after.
+func (a *AuditBroker) GetHashWithEpoch(ctx context.Context, name string, input string, epoch int) (string, error) {
+	a.RLock()
+	defer a.RUnlock()
+	be, ok := a.backends[name]
+	if !ok {
+		return "", fmt.Errorf("unknown audit backend %q", name)
+	}
+
+	s, err := salt.NewSaltEpoch(ctx, be.view, auditSaltConfig(), epoch)
+	if err != nil {
+		return "", err
+	}
+	return audit.HashString(s, input), nil
+}
+
+// SaltEpochs returns the epochs of the salt of the given backend, oldest
+// first, along with the current epoch.
+func (a *AuditBroker) SaltEpochs(ctx context.Context, name string) ([]salt.Epoch, int, error) {
+	a.RLock()
+	defer a.RUnlock()
+	be, ok := a.backends[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown audit backend %q", name)
+	}
+
+	epochs, err := salt.Epochs(ctx, be.view, auditSaltConfig())
+	if err != nil {
+		return nil, 0, err
+	}
+	return epochs, epochs[len(epochs)-1].Epoch, nil
+}
+
+// RotateSalt rotates the salt of the given backend and returns the new epoch.
+// The broker is locked for the duration of the rotation, so every entry logged
+// after it returns is hashed with the new salt. Other backends are unaffected.
+func (a *AuditBroker) RotateSalt(ctx context.Context, name string) (int, error) {
+	a.Lock()
+	defer a.Unlock()
+	be, ok := a.backends[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown audit backend %q", name)
+	}
+
+	s, err := salt.RotateSalt(ctx, be.view, auditSaltConfig())
+	if err != nil {
+		return 0, err
+	}
+
+	// Drop the salt cached by the backend so that it loads the new one
+	be.backend.Invalidate(ctx)
+	return s.Epoch(), nil
+}
+
 // LogRequest is used to ensure all the audit backends have an opportunity to
 // log the given request and that *at least one* succeeds.
 func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, headersConfig *AuditedHeadersConfig) (ret error) {
@@ -133,9 +220,22 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 		in.Request.Headers = headers
 	}()
 
-	// Ensure at least one backend logs
+	nsPath := ""
+	if ns, err := namespace.FromContext(ctx); err == nil {
+		nsPath = ns.Path
+	}
+
+	// Ensure at least one backend logs, unless every backend filtered the
+	// request out.
 	anyLogged := false
+	filtered := 0
 	for name, be := range a.backends {
+		if be.filter.excludes(nsPath, in) {
+			metrics.IncrCounter([]string{"audit", name, "log_request_filtered"}, 1)
+			filtered++
+			continue
+		}
+
 		in.Request.Headers = nil
 		transHeaders, thErr := headersConfig.ApplyConfig(ctx, headers, be.backend.GetHash)
 		if thErr != nil {
@@ -153,7 +253,7 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 			anyLogged = true
 		}
 	}
-	if !anyLogged && len(a.backends) > 0 {
+	if !anyLogged && len(a.backends) > filtered {
 		retErr = multierror.Append(retErr, fmt.Errorf("no audit backend succeeded in logging the request"))
 	}
 
@@ -198,9 +298,22 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 		in.Request.Headers = headers
 	}()
 
-	// Ensure at least one backend logs
+	nsPath := ""
+	if ns, err := namespace.FromContext(ctx); err == nil {
+		nsPath = ns.Path
+	}
+
+	// Ensure at least one backend logs, unless every backend filtered the
+	// response out.
 	anyLogged := false
+	filtered := 0
 	for name, be := range a.backends {
+		if be.filter.excludes(nsPath, in) {
+			metrics.IncrCounter([]string{"audit", name, "log_response_filtered"}, 1)
+			filtered++
+			continue
+		}
+
 		in.Request.Headers = nil
 		transHeaders, thErr := headersConfig.ApplyConfig(ctx, headers, be.backend.GetHash)
 		if thErr != nil {
@@ -218,7 +331,7 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 			anyLogged = true
 		}
 	}
-	if !anyLogged && len(a.backends) > 0 {
+	if !anyLogged && len(a.backends) > filtered {
 		retErr = multierror.Append(retErr, fmt.Errorf("no audit backend succeeded in logging the response"))
 	}
 
@@ -0,0 +1,180 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+const (
+	// auditFilterAllowOption and auditFilterDenyOption are the audit device
+	// options holding the filter rules of the device, as a comma-separated
+	// list of [<operation>:]<path prefix> rules.
+	auditFilterAllowOption = "filter_allow"
+	auditFilterDenyOption  = "filter_deny"
+)
+
+// auditFilterOperations are the operations which may be named by audit
+// filter rules.
+var auditFilterOperations = map[logical.Operation]struct{}{
+	logical.CreateOperation:         {},
+	logical.ReadOperation:           {},
+	logical.UpdateOperation:         {},
+	logical.PatchOperation:          {},
+	logical.DeleteOperation:         {},
+	logical.ListOperation:           {},
+	logical.HelpOperation:           {},
+	logical.AliasLookaheadOperation: {},
+	logical.ResolveRoleOperation:    {},
+	logical.HeaderOperation:         {},
+	logical.RevokeOperation:         {},
+	logical.RenewOperation:          {},
+	logical.RollbackOperation:       {},
+}
+
+// auditUnfilteredPaths are the path prefixes, relative to the namespace of
+// the request, which are always audited: changes to the audit devices
+// themselves and the generation of new root credentials.
+var auditUnfilteredPaths = []string{
+	"sys/audit",
+	"sys/generate-root",
+	"sys/generate-recovery-token",
+	"sys/rekey",
+}
+
+// auditFilterRule matches requests by path prefix and, optionally, operation.
+type auditFilterRule struct {
+	operation logical.Operation
+	prefix    string
+}
+
+func (r *auditFilterRule) matches(op logical.Operation, path string) bool {
+	if r.operation != "" && r.operation != op {
+		return false
+	}
+	return strings.HasPrefix(path, r.prefix)
+}
+
+// auditFilter decides which entries an audit device skips. An entry is
+// skipped if the allow list is not empty and none of its rules match, or if
+// any rule of the deny list matches.
+type auditFilter struct {
+	allow []*auditFilterRule
+	deny  []*auditFilterRule
+}
+
+// parseAuditFilter parses the filter rules of an audit device from its
+// options. A nil filter is returned if the device has no filter rules.
+func parseAuditFilter(options map[string]string) (*auditFilter, error) {
+	allow, err := parseAuditFilterRules(options[auditFilterAllowOption])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", auditFilterAllowOption, err)
+	}
+	deny, err := parseAuditFilterRules(options[auditFilterDenyOption])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", auditFilterDenyOption, err)
+	}
+
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+
+	return &auditFilter{
+		allow: allow,
+		deny:  deny,
+	}, nil
+}
+
+func parseAuditFilterRules(raw string) ([]*auditFilterRule, error) {
+	var rules []*auditFilterRule
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule := &auditFilterRule{
+			prefix: item,
+		}
+		if op, prefix, ok := strings.Cut(item, ":"); ok {
+			if _, known := auditFilterOperations[logical.Operation(op)]; known {
+				rule.operation = logical.Operation(op)
+				rule.prefix = prefix
+			}
+		}
+
+		rule.prefix = strings.TrimPrefix(rule.prefix, "/")
+		if rule.prefix == "" && rule.operation == "" {
+			return nil, fmt.Errorf("rule %q matches no path", item)
+		}
+		if strings.ContainsAny(rule.prefix, "*? ") {
+			return nil, fmt.Errorf("rule %q must be a literal path prefix", item)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// excludes returns true if the audit device should not log the given entry.
+// Entries describing failed requests, logins and changes to security-critical
+// paths are never excluded.
+func (f *auditFilter) excludes(nsPath string, in *logical.LogInput) bool {
+	if f == nil || in.Request == nil || auditEntryUnfiltered(in) {
+		return false
+	}
+
+	path := nsPath + in.Request.Path
+	op := in.Request.Operation
+
+	if len(f.allow) > 0 {
+		allowed := false
+		for _, rule := range f.allow {
+			if rule.matches(op, path) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return true
+		}
+	}
+
+	for _, rule := range f.deny {
+		if rule.matches(op, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditEntryUnfiltered returns true if the entry is a security-critical event
+// which must be logged by every audit device regardless of its filter.
+func auditEntryUnfiltered(in *logical.LogInput) bool {
+	// Failed requests, including authentication and permission failures.
+	if in.OuterErr != nil || in.Response.IsError() {
+		return true
+	}
+
+	// Logins and other requests issuing tokens.
+	if in.Response != nil && in.Response.Auth != nil {
+		return true
+	}
+	if strings.HasPrefix(in.Request.Path, "auth/") && (in.Auth == nil || in.Auth.ClientToken == "") {
+		return true
+	}
+
+	for _, prefix := range auditUnfilteredPaths {
+		if strings.HasPrefix(in.Request.Path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
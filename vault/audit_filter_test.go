@@ -0,0 +1,134 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/helper/testhelpers/corehelpers"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	filter, err := parseAuditFilter(map[string]string{"file_path": "stdout"})
+	require.NoError(t, err)
+	require.Nil(t, filter)
+
+	filter, err = parseAuditFilter(map[string]string{
+		auditFilterAllowOption: "secret/, ns1/kv/",
+		auditFilterDenyOption:  "read:sys/health,list:,secret/data:foo",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*auditFilterRule{
+		{prefix: "secret/"},
+		{prefix: "ns1/kv/"},
+	}, filter.allow)
+	require.Equal(t, []*auditFilterRule{
+		{operation: logical.ReadOperation, prefix: "sys/health"},
+		{operation: logical.ListOperation},
+		{prefix: "secret/data:foo"},
+	}, filter.deny)
+
+	for _, options := range []map[string]string{
+		{auditFilterAllowOption: "secret/*"},
+		{auditFilterDenyOption: "/"},
+	} {
+		_, err := parseAuditFilter(options)
+		require.Error(t, err, "options: %v", options)
+	}
+}
+
+func TestAuditBroker_Filter(t *testing.T) {
+	b := NewAuditBroker(logging.NewVaultLogger(log.Trace))
+	all := corehelpers.TestNoopAudit(t, nil)
+	filtered := corehelpers.TestNoopAudit(t, nil)
+	filter, err := parseAuditFilter(map[string]string{
+		auditFilterAllowOption: "secret/,sys/",
+		auditFilterDenyOption:  "read:sys/health",
+	})
+	require.NoError(t, err)
+	b.Register("all", all, nil, false, nil)
+	b.Register("filtered", filtered, nil, false, filter)
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: make(map[string]*auditedHeaderSettings),
+	}
+	ctx := namespace.RootContext(context.Background())
+	auth := &logical.Auth{ClientToken: "foo"}
+	logged := func(in *logical.LogInput) bool {
+		t.Helper()
+		before := len(filtered.Req)
+		require.NoError(t, b.LogRequest(ctx, in, headersConf))
+		return len(filtered.Req) > before
+	}
+	request := func(op logical.Operation, path string) *logical.LogInput {
+		return &logical.LogInput{
+			Auth: auth,
+			Request: &logical.Request{
+				Operation: op,
+				Path:      path,
+			},
+		}
+	}
+
+	require.True(t, logged(request(logical.ReadOperation, "secret/foo")))
+	require.True(t, logged(request(logical.UpdateOperation, "sys/health")))
+	require.False(t, logged(request(logical.ReadOperation, "sys/health")))
+	require.False(t, logged(request(logical.ReadOperation, "kv/foo")))
+
+	// Security-critical entries cannot be filtered out.
+	failed := request(logical.ReadOperation, "kv/foo")
+	failed.OuterErr = logical.ErrPermissionDenied
+	require.True(t, logged(failed))
+
+	login := &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "auth/userpass/login/foo",
+		},
+	}
+	require.True(t, logged(login))
+	require.True(t, logged(request(logical.UpdateOperation, "sys/audit/file")))
+
+	require.Len(t, all.Req, 7)
+
+	// Responses are filtered the same way.
+	resp := request(logical.ReadOperation, "kv/foo")
+	resp.Response = &logical.Response{}
+	require.NoError(t, b.LogResponse(ctx, resp, headersConf))
+	require.Empty(t, filtered.Resp)
+	resp.Response = logical.ErrorResponse("permission denied")
+	require.NoError(t, b.LogResponse(ctx, resp, headersConf))
+	require.Len(t, filtered.Resp, 1)
+
+	// An entry which every backend filters out is not a failure, but a
+	// failing backend still is.
+	b.Deregister(ctx, "all")
+	require.NoError(t, b.LogRequest(ctx, request(logical.ReadOperation, "kv/foo"), headersConf))
+	filtered.ReqErr = errors.New("failed")
+	require.Error(t, b.LogRequest(ctx, request(logical.ReadOperation, "secret/foo"), headersConf))
+}
+
+func TestCore_EnableAudit_InvalidFilter(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	c.auditBackends["noop"] = corehelpers.NoopAuditFactory(nil)
+
+	me := &MountEntry{
+		Table: auditTableType,
+		Path:  "foo",
+		Type:  "noop",
+		Options: map[string]string{
+			auditFilterDenyOption: "read:sys/*",
+		},
+	}
+	err := c.enableAudit(namespace.RootContext(nil), me, true)
+	require.Error(t, err)
+	require.False(t, c.auditBroker.IsRegistered("foo/"))
+}
@@ -189,7 +189,13 @@ func (c *Core) enableCredentialInternal(ctx context.Context, entry *MountEntry,
 	newTable := c.auth.shallowClone()
 	newTable.Entries = append(newTable.Entries, entry)
 	if updateStorage {
+		if err := c.createMountStorageKey(ctx, entry); err != nil {
+			return err
+		}
 		if err := c.persistAuth(ctx, newTable, &entry.Local); err != nil {
+			if err := c.destroyMountStorageKey(ctx, entry); err != nil {
+				c.logger.Error("failed to destroy storage key of failed auth mount", "error", err)
+			}
 			return fmt.Errorf("failed to update auth table: %w", err)
 		}
 	}
@@ -258,6 +264,7 @@ func (c *Core) disableCredentialInternal(ctx context.Context, path string, updat
 	// Get the backend/mount entry for this path, used to remove ignored
 	// replication prefixes
 	backend := c.router.MatchingBackend(ctx, path)
+	entry := c.router.MatchingMountEntry(ctx, path)
 
 	// Mark the entry as tainted
 	if err := c.taintCredEntry(ctx, ns.ID, path, updateStorage); err != nil {
@@ -290,6 +297,13 @@ func (c *Core) disableCredentialInternal(ctx context.Context, path string, updat
 	case !updateStorage:
 		// Don't attempt to clear data, replication will handle this
 	default:
+		// Shred the data first, so that it is unreadable even if clearing
+		// the view is interrupted
+		if err := c.destroyMountStorageKey(ctx, entry); err != nil {
+			c.logger.Error("failed to destroy storage key of auth mount being disabled", "error", err, "path", path)
+			return err
+		}
+
 		// Have writable storage, remove the whole thing
 		if err := logical.ClearViewWithLogging(ctx, view, c.logger.Named("auth.deletion").With("namespace", ns.ID, "path", path)); err != nil {
 			c.logger.Error("failed to clear view for path being unmounted", "error", err, "path", path)
@@ -130,6 +130,14 @@ type SecurityBarrierCore interface {
 	// CheckUpgrade looks for an upgrade to the current term and installs it
 	CheckUpgrade(ctx context.Context) (bool, uint32, error)
 
+	// CreateMountKey creates a key dedicated to the mount stored under the
+	// given prefix, used to encrypt all of its data
+	CreateMountKey(ctx context.Context, prefix string, reader io.Reader) error
+
+	// DestroyMountKey destroys the key dedicated to the mount stored under
+	// the given prefix, making its data unreadable
+	DestroyMountKey(ctx context.Context, prefix string) error
+
 	// ActiveKeyInfo is used to inform details about the active key
 	ActiveKeyInfo() (*KeyInfo, error)
 
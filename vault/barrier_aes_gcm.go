@@ -21,6 +21,7 @@ import (
 
 	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/openbao/openbao/sdk/v2/helper/compressutil"
 	"github.com/openbao/openbao/sdk/v2/helper/jsonutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/openbao/openbao/sdk/v2/physical"
@@ -45,6 +46,10 @@ const (
 const (
 	AESGCMVersion1 = 0x1
 	AESGCMVersion2 = 0x2
+
+	// AESGCMVersion3 is AESGCMVersion2 with a key dedicated to the mount
+	// the value belongs to, derived from the term key.
+	AESGCMVersion3 = 0x3
 )
 
 // barrierInit is the JSON encoded value stored
@@ -83,14 +88,19 @@ type AESGCMBarrier struct {
 	keyring *Keyring
 
 	// cache is used to reduce the number of AEAD constructions we do
-	cache     map[uint32]cipher.AEAD
-	cacheLock sync.RWMutex
+	cache      map[uint32]cipher.AEAD
+	mountCache map[mountAEADCacheKey]cipher.AEAD
+	cacheLock  sync.RWMutex
 
 	// currentAESGCMVersionByte is prefixed to a message to allow for
 	// future versioning of barrier implementations. It's var instead
 	// of const to allow for testing
 	currentAESGCMVersionByte byte
 
+	// compression is the configuration used to compress values before they
+	// are encrypted, nil when they are stored uncompressed
+	compression atomic.Pointer[compressutil.CompressionConfig]
+
 	initialized atomic.Bool
 
 	UnaccountedEncryptions *atomic.Int64
@@ -140,6 +150,7 @@ func NewAESGCMBarrier(storage physical.Backend) (SecurityBarrier, error) {
 		backend:                  storage,
 		sealed:                   true,
 		cache:                    make(map[uint32]cipher.AEAD),
+		mountCache:               make(map[mountAEADCacheKey]cipher.AEAD),
 		currentAESGCMVersionByte: byte(AESGCMVersion2),
 		UnaccountedEncryptions:   atomic.NewInt64(0),
 		RemoteEncryptions:        atomic.NewInt64(0),
@@ -226,7 +237,7 @@ func (b *AESGCMBarrier) Initialize(ctx context.Context, key, sealKey []byte, rea
 			return err
 		}
 
-		err = b.putInternal(ctx, b.backend, 1, primary, &logical.StorageEntry{
+		err = b.putInternal(ctx, b.backend, 1, b.currentAESGCMVersionByte, primary, &logical.StorageEntry{
 			Key:   shamirKekPath,
 			Value: sealKey,
 		})
@@ -310,7 +321,7 @@ func (b *AESGCMBarrier) persistKeyringInternal(ctx context.Context, keyring *Key
 	if err != nil {
 		return fmt.Errorf("failed to retrieve AES-GCM AEAD from active key: %w", err)
 	}
-	value, err = b.encryptTracked(rootKeyPath, activeKey.Term, aead, keyBuf)
+	value, err = b.encryptTracked(rootKeyPath, activeKey.Term, b.currentAESGCMVersionByte, aead, keyBuf)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt and track active key value: %w", err)
 	}
@@ -423,6 +434,7 @@ func (b *AESGCMBarrier) recoverKeyring(plaintext []byte) error {
 
 	// Setup the keyring and finish
 	b.cache = make(map[uint32]cipher.AEAD)
+	b.mountCache = make(map[mountAEADCacheKey]cipher.AEAD)
 	b.keyring = keyring
 	return nil
 }
@@ -596,6 +608,7 @@ func (b *AESGCMBarrier) Seal() error {
 
 	// Remove the primary key, and seal the vault
 	b.cache = make(map[uint32]cipher.AEAD)
+	b.mountCache = make(map[mountAEADCacheKey]cipher.AEAD)
 	b.keyring.Zeroize(true)
 	b.keyring = nil
 	b.sealed = true
@@ -673,7 +686,7 @@ func (b *AESGCMBarrier) CreateUpgrade(ctx context.Context, term uint32) error {
 	}
 
 	key := fmt.Sprintf("%s%d", keyringUpgradePrefix, prevTerm)
-	value, err := b.encryptTracked(key, prevTerm, primary, buf)
+	value, err := b.encryptTracked(key, prevTerm, b.currentAESGCMVersionByte, primary, buf)
 	b.l.RUnlock()
 	if err != nil {
 		return err
@@ -849,17 +862,21 @@ func (b *AESGCMBarrier) putWithBackend(ctx context.Context, backend physical.Bac
 	}
 
 	term := b.keyring.ActiveTerm()
-	primary, err := b.aeadForTerm(term)
+	primary, version, err := b.aeadForPath(term, entry.Key)
 	b.l.RUnlock()
 	if err != nil {
 		return err
 	}
 
-	return b.putInternal(ctx, backend, term, primary, entry)
+	return b.putInternal(ctx, backend, term, version, primary, entry)
 }
 
-func (b *AESGCMBarrier) putInternal(ctx context.Context, backend physical.Backend, term uint32, primary cipher.AEAD, entry *logical.StorageEntry) error {
-	value, err := b.encryptTracked(entry.Key, term, primary, entry.Value)
+func (b *AESGCMBarrier) putInternal(ctx context.Context, backend physical.Backend, term uint32, version byte, primary cipher.AEAD, entry *logical.StorageEntry) error {
+	plain, version, err := b.compress(entry.Value, version)
+	if err != nil {
+		return err
+	}
+	value, err := b.encryptTracked(entry.Key, term, version, primary, plain)
 	if err != nil {
 		return err
 	}
@@ -902,7 +919,7 @@ func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, backend physical.Ba
 		return nil, nil
 	}
 
-	if len(pe.Value) < 4 {
+	if len(pe.Value) < 5 {
 		if getLock {
 			b.l.RUnlock()
 		}
@@ -915,7 +932,7 @@ func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, backend physical.Ba
 	// Get the GCM by term
 	// It is expensive to do this first but it is not a
 	// normal case that this won't match
-	gcm, err := b.aeadForTerm(term)
+	gcm, err := b.aeadForValue(term, pe.Value[4], key)
 	if getLock {
 		b.l.RUnlock()
 	}
@@ -941,6 +958,65 @@ func (b *AESGCMBarrier) lockSwitchedGet(ctx context.Context, backend physical.Ba
 	return entry, nil
 }
 
+// BatchGet is used to fetch several entries, in a single round-trip if the
+// storage backend supports it. The entries are returned in the same order as
+// the keys, with nil for the keys which do not exist.
+func (b *AESGCMBarrier) BatchGet(ctx context.Context, keys []string) ([]*logical.StorageEntry, error) {
+	defer metrics.MeasureSince([]string{"barrier", "batch_get"}, time.Now())
+	b.l.RLock()
+	if b.sealed {
+		b.l.RUnlock()
+		return nil, ErrBarrierSealed
+	}
+
+	pes, err := physical.BatchGetEntries(ctx, b.backend, keys)
+	if err != nil {
+		b.l.RUnlock()
+		return nil, err
+	}
+
+	gcms := make([]cipher.AEAD, len(pes))
+	for i, pe := range pes {
+		if pe == nil {
+			continue
+		}
+		if len(pe.Value) < 5 {
+			b.l.RUnlock()
+			return nil, fmt.Errorf("invalid value for %q", keys[i])
+		}
+
+		term := binary.BigEndian.Uint32(pe.Value[:4])
+		gcm, err := b.aeadForValue(term, pe.Value[4], keys[i])
+		if err != nil {
+			b.l.RUnlock()
+			return nil, err
+		}
+		if gcm == nil {
+			b.l.RUnlock()
+			return nil, fmt.Errorf("no decryption key available for term %d", term)
+		}
+		gcms[i] = gcm
+	}
+	b.l.RUnlock()
+
+	entries := make([]*logical.StorageEntry, len(pes))
+	for i, pe := range pes {
+		if pe == nil {
+			continue
+		}
+		plain, err := b.decrypt(keys[i], gcms[i], pe.Value)
+		if err != nil {
+			return nil, fmt.Errorf("decryption of %q failed: %w", keys[i], err)
+		}
+		entries[i] = &logical.StorageEntry{
+			Key:      keys[i],
+			Value:    plain,
+			SealWrap: pe.SealWrap,
+		}
+	}
+	return entries, nil
+}
+
 // Delete is used to permanently delete an entry
 func (b *AESGCMBarrier) Delete(ctx context.Context, key string) error {
 	return b.deleteWithBackend(ctx, b.backend, key)
@@ -1043,6 +1119,12 @@ func (b *AESGCMBarrier) aeadFromKey(key []byte) (cipher.AEAD, error) {
 
 // encrypt is used to encrypt a value
 func (b *AESGCMBarrier) encrypt(path string, term uint32, gcm cipher.AEAD, plain []byte) ([]byte, error) {
+	return b.encryptWithVersion(path, term, b.currentAESGCMVersionByte, gcm, plain)
+}
+
+// encryptWithVersion is used to encrypt a value with the given version of the
+// storage methodology
+func (b *AESGCMBarrier) encryptWithVersion(path string, term uint32, version byte, gcm cipher.AEAD, plain []byte) ([]byte, error) {
 	// Allocate the output buffer with room for tern, version byte,
 	// nonce, GCM tag and the plaintext
 
@@ -1059,7 +1141,7 @@ func (b *AESGCMBarrier) encrypt(path string, term uint32, gcm cipher.AEAD, plain
 	binary.BigEndian.PutUint32(out[:4], term)
 
 	// Set the version byte
-	out[4] = b.currentAESGCMVersionByte
+	out[4] = version
 
 	// Generate a random nonce
 	nonce := out[5 : 5+gcm.NonceSize()]
@@ -1072,15 +1154,11 @@ func (b *AESGCMBarrier) encrypt(path string, term uint32, gcm cipher.AEAD, plain
 	}
 
 	// Seal the output
-	switch b.currentAESGCMVersionByte {
+	switch version &^ aesgcmCompressedFlag {
 	case AESGCMVersion1:
 		out = gcm.Seal(out, nonce, plain, nil)
-	case AESGCMVersion2:
-		aad := []byte(nil)
-		if path != "" {
-			aad = []byte(path)
-		}
-		out = gcm.Seal(out, nonce, plain, aad)
+	case AESGCMVersion2, AESGCMVersion3:
+		out = gcm.Seal(out, nonce, plain, aadForVersion(path, version))
 	default:
 		panic("Unknown AESGCM version")
 	}
@@ -1108,18 +1186,21 @@ func (b *AESGCMBarrier) decrypt(path string, gcm cipher.AEAD, cipher []byte) ([]
 	out := make([]byte, 0, len(raw)-gcm.NonceSize())
 
 	// Attempt to open
-	switch cipher[4] {
+	var plain []byte
+	var err error
+	switch cipher[4] &^ aesgcmCompressedFlag {
 	case AESGCMVersion1:
-		return gcm.Open(out, nonce, raw, nil)
-	case AESGCMVersion2:
-		aad := []byte(nil)
-		if path != "" {
-			aad = []byte(path)
-		}
-		return gcm.Open(out, nonce, raw, aad)
+		plain, err = gcm.Open(out, nonce, raw, nil)
+	case AESGCMVersion2, AESGCMVersion3:
+		plain, err = gcm.Open(out, nonce, raw, aadForVersion(path, cipher[4]))
 	default:
 		return nil, fmt.Errorf("version bytes mis-match")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decompress(plain, cipher[4])
 }
 
 // Encrypt is used to encrypt in-memory for the BarrierEncryptor interface
@@ -1131,13 +1212,13 @@ func (b *AESGCMBarrier) Encrypt(ctx context.Context, key string, plaintext []byt
 	}
 
 	term := b.keyring.ActiveTerm()
-	primary, err := b.aeadForTerm(term)
+	primary, version, err := b.aeadForPath(term, key)
 	b.l.RUnlock()
 	if err != nil {
 		return nil, err
 	}
 
-	ciphertext, err := b.encryptTracked(key, term, primary, plaintext)
+	ciphertext, err := b.encryptTracked(key, term, version, primary, plaintext)
 	if err != nil {
 		return nil, err
 	}
@@ -1159,7 +1240,7 @@ func (b *AESGCMBarrier) Decrypt(_ context.Context, key string, ciphertext []byte
 	}
 
 	// Verify the term
-	if len(ciphertext) < 4 {
+	if len(ciphertext) < 5 {
 		b.l.RUnlock()
 		return nil, fmt.Errorf("invalid ciphertext term")
 	}
@@ -1168,7 +1249,7 @@ func (b *AESGCMBarrier) Decrypt(_ context.Context, key string, ciphertext []byte
 	// Get the GCM by term
 	// It is expensive to do this first but it is not a
 	// normal case that this won't match
-	gcm, err := b.aeadForTerm(term)
+	gcm, err := b.aeadForValue(term, ciphertext[4], key)
 	b.l.RUnlock()
 	if err != nil {
 		return nil, err
@@ -1220,8 +1301,8 @@ func (b *AESGCMBarrier) AddRemoteEncryptions(encryptions int64) {
 	b.RemoteEncryptions.Add(encryptions)
 }
 
-func (b *AESGCMBarrier) encryptTracked(path string, term uint32, gcm cipher.AEAD, buf []byte) ([]byte, error) {
-	ct, err := b.encrypt(path, term, gcm, buf)
+func (b *AESGCMBarrier) encryptTracked(path string, term uint32, version byte, gcm cipher.AEAD, buf []byte) ([]byte, error) {
+	ct, err := b.encryptWithVersion(path, term, version, gcm, buf)
 	if err != nil {
 		return nil, err
 	}
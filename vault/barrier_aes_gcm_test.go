@@ -788,3 +788,159 @@ func TestBarrier_persistKeyring_Context(t *testing.T) {
 		})
 	}
 }
+
+func TestAESGCMBarrier_MountKeys(t *testing.T) {
+	ctx := context.Background()
+	inm, sb, key := mockBarrier(t)
+	b := sb.(*TransactionalAESGCMBarrier)
+
+	put := func(path, value string) {
+		t.Helper()
+		require.NoError(t, b.Put(ctx, &logical.StorageEntry{Key: path, Value: []byte(value)}))
+	}
+	get := func(path string) (string, error) {
+		t.Helper()
+		entry, err := b.Get(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		require.NotNil(t, entry)
+		return string(entry.Value), nil
+	}
+	version := func(path string) byte {
+		t.Helper()
+		pe, err := inm.Get(ctx, path)
+		require.NoError(t, err)
+		return pe.Value[4]
+	}
+
+	require.Error(t, b.CreateMountKey(ctx, "sys/", rand.Reader))
+	require.Error(t, b.CreateMountKey(ctx, "logical/mount", rand.Reader))
+
+	put("logical/mount/before", "before")
+	require.NoError(t, b.CreateMountKey(ctx, "logical/mount/", rand.Reader))
+	require.NoError(t, b.CreateMountKey(ctx, "logical/mount/", rand.Reader))
+	put("logical/mount/after", "after")
+	put("logical/other/after", "other")
+	require.Equal(t, byte(AESGCMVersion2), version("logical/mount/before"))
+	require.Equal(t, byte(AESGCMVersion3), version("logical/mount/after"))
+	require.Equal(t, byte(AESGCMVersion2), version("logical/other/after"))
+
+	ciphertext, err := b.Encrypt(ctx, "logical/mount/encrypted", []byte("encrypted"))
+	require.NoError(t, err)
+	require.Equal(t, byte(AESGCMVersion3), ciphertext[4])
+
+	// Values moved to another mount cannot be decrypted.
+	pe, err := inm.Get(ctx, "logical/mount/after")
+	require.NoError(t, err)
+	require.NoError(t, inm.Put(ctx, &physical.Entry{Key: "logical/other/moved", Value: pe.Value}))
+	_, err = get("logical/other/moved")
+	require.ErrorIs(t, err, ErrMountKeyDestroyed)
+
+	// The keys survive rotations and seals.
+	_, err = b.Rotate(ctx, rand.Reader)
+	require.NoError(t, err)
+	put("logical/mount/rotated", "rotated")
+	require.NoError(t, b.Seal())
+	require.NoError(t, b.Unseal(ctx, key))
+	for _, path := range []string{"before", "after", "rotated"} {
+		value, err := get("logical/mount/" + path)
+		require.NoError(t, err)
+		require.Equal(t, path, value)
+	}
+	plaintext, err := b.Decrypt(ctx, "logical/mount/encrypted", ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "encrypted", string(plaintext))
+
+	// Destroying the key shreds the data written after its creation.
+	require.NoError(t, b.DestroyMountKey(ctx, "logical/mount/"))
+	require.NoError(t, b.DestroyMountKey(ctx, "logical/mount/"))
+	for _, path := range []string{"after", "rotated"} {
+		_, err = get("logical/mount/" + path)
+		require.ErrorIs(t, err, ErrMountKeyDestroyed)
+	}
+	_, err = b.Decrypt(ctx, "logical/mount/encrypted", ciphertext)
+	require.ErrorIs(t, err, ErrMountKeyDestroyed)
+	value, err := get("logical/mount/before")
+	require.NoError(t, err)
+	require.Equal(t, "before", value)
+	value, err = get("logical/other/after")
+	require.NoError(t, err)
+	require.Equal(t, "other", value)
+
+	// The destruction is persisted.
+	require.NoError(t, b.Seal())
+	require.NoError(t, b.Unseal(ctx, key))
+	_, err = get("logical/mount/after")
+	require.ErrorIs(t, err, ErrMountKeyDestroyed)
+}
+
+func TestAESGCMBarrier_Compression(t *testing.T) {
+	ctx := context.Background()
+	inm, sb, _ := mockBarrier(t)
+	b := sb.(*TransactionalAESGCMBarrier)
+
+	compressible := bytes.Repeat([]byte("certificate"), 1000)
+	incompressible := make([]byte, 4096)
+	_, err := rand.Read(incompressible)
+	require.NoError(t, err)
+
+	put := func(path string, value []byte) {
+		t.Helper()
+		require.NoError(t, b.Put(ctx, &logical.StorageEntry{Key: path, Value: value}))
+	}
+	get := func(path string) []byte {
+		t.Helper()
+		entry, err := b.Get(ctx, path)
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		return entry.Value
+	}
+	stored := func(path string) *physical.Entry {
+		t.Helper()
+		pe, err := inm.Get(ctx, path)
+		require.NoError(t, err)
+		return pe
+	}
+
+	// Values written before compression is enabled stay readable
+	put("before", compressible)
+
+	require.Error(t, b.SetCompression("bogus"))
+	require.NoError(t, b.SetCompression("lz4"))
+	put("compressible", compressible)
+	put("incompressible", incompressible)
+	put("tiny", []byte("tiny"))
+	put("kept", compressible)
+
+	require.Equal(t, byte(AESGCMVersion2), stored("before").Value[4])
+	require.Equal(t, byte(AESGCMVersion2)|aesgcmCompressedFlag, stored("compressible").Value[4])
+	require.Less(t, len(stored("compressible").Value), len(compressible))
+	require.Equal(t, byte(AESGCMVersion2), stored("incompressible").Value[4])
+	require.Equal(t, byte(AESGCMVersion2), stored("tiny").Value[4])
+
+	require.Equal(t, compressible, get("before"))
+	require.Equal(t, compressible, get("compressible"))
+	require.Equal(t, incompressible, get("incompressible"))
+	require.Equal(t, []byte("tiny"), get("tiny"))
+
+	entries, err := b.BatchGet(ctx, []string{"compressible", "tiny"})
+	require.NoError(t, err)
+	require.Equal(t, compressible, entries[0].Value)
+	require.Equal(t, []byte("tiny"), entries[1].Value)
+
+	// Tampering with the compression flag fails decryption
+	for _, path := range []string{"compressible", "tiny"} {
+		pe := stored(path)
+		pe.Value[4] ^= aesgcmCompressedFlag
+		require.NoError(t, inm.Put(ctx, pe))
+		_, err = b.Get(ctx, path)
+		require.Error(t, err)
+	}
+
+	// Compressed values stay readable once compression is disabled
+	require.NoError(t, b.SetCompression(""))
+	require.Equal(t, compressible, get("kept"))
+	put("after", compressible)
+	require.Equal(t, byte(AESGCMVersion2), stored("after").Value[4])
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+
+	"github.com/openbao/openbao/sdk/v2/helper/compressutil"
+)
+
+const (
+	// aesgcmCompressedFlag is set on the version byte of values whose
+	// plaintext was compressed before being encrypted. Values written
+	// without compression, including all values written before it was
+	// supported, never carry it and are read unchanged.
+	aesgcmCompressedFlag byte = 0x80
+
+	// minBarrierCompressionSize is the size under which values are never
+	// compressed, as the gain would not be worth the cost.
+	minBarrierCompressionSize = 512
+)
+
+// SetCompression configures the algorithm used to compress values before
+// they are encrypted, one of the types supported by compressutil. An empty
+// type disables compression of new values; existing compressed values can
+// always be read regardless of this setting.
+func (b *AESGCMBarrier) SetCompression(compressionType string) error {
+	var config *compressutil.CompressionConfig
+	switch compressionType {
+	case "":
+	case compressutil.CompressionTypeGzip, compressutil.CompressionTypeLZW,
+		compressutil.CompressionTypeSnappy, compressutil.CompressionTypeLZ4:
+		config = &compressutil.CompressionConfig{Type: compressionType}
+	default:
+		return fmt.Errorf("unsupported barrier compression type %q", compressionType)
+	}
+
+	b.compression.Store(config)
+	return nil
+}
+
+// compress returns the plaintext to encrypt for a value along with the
+// version byte to store it with. Small values and values which do not shrink
+// when compressed, such as values which are already compressed, are returned
+// as is. Compression happens before encryption, so seal wrapping still
+// applies to the resulting ciphertext.
+func (b *AESGCMBarrier) compress(plain []byte, version byte) ([]byte, byte, error) {
+	config := b.compression.Load()
+	if config == nil || len(plain) < minBarrierCompressionSize || version == AESGCMVersion1 {
+		return plain, version, nil
+	}
+
+	compressed, err := compressutil.Compress(plain, config)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if len(compressed) >= len(plain) {
+		return plain, version, nil
+	}
+
+	return compressed, version | aesgcmCompressedFlag, nil
+}
+
+// decompress reverses compress for a decrypted value stored with the given
+// version byte.
+func decompress(plain []byte, version byte) ([]byte, error) {
+	if version&aesgcmCompressedFlag == 0 {
+		return plain, nil
+	}
+
+	out, notCompressed, err := compressutil.Decompress(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	if notCompressed {
+		return nil, fmt.Errorf("value flagged as compressed is not compressed")
+	}
+	return out, nil
+}
+
+// aadForVersion returns the additional data authenticating a value at the
+// given path. The version byte of compressed values is authenticated, so the
+// flag cannot be added or removed without failing decryption; other values
+// keep the format they always had.
+func aadForVersion(path string, version byte) []byte {
+	if version&aesgcmCompressedFlag != 0 {
+		return append([]byte{version}, path...)
+	}
+	if path == "" {
+		return nil
+	}
+	return []byte(path)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// ErrMountKeyDestroyed is returned when reading a value encrypted with the
+// dedicated key of a mount once that key has been destroyed.
+var ErrMountKeyDestroyed = errors.New("the storage key of this mount has been destroyed")
+
+// mountAEADCacheKey identifies an AEAD derived for a mount in the cache
+type mountAEADCacheKey struct {
+	term   uint32
+	prefix string
+}
+
+// mountKeyPrefix returns the storage prefix of the secret or auth mount
+// owning the given path, such as "logical/<uuid>/", or an empty string for
+// paths outside of mounts.
+func mountKeyPrefix(path string) string {
+	for _, root := range []string{backendBarrierPrefix, credentialBarrierPrefix} {
+		rest, ok := strings.CutPrefix(path, root)
+		if !ok {
+			continue
+		}
+		if idx := strings.IndexByte(rest, '/'); idx > 0 {
+			return root + rest[:idx+1]
+		}
+	}
+	return ""
+}
+
+// deriveMountKey derives the key encrypting the data of a mount for a term
+// from the term key and the secret of the mount. Both are required: the
+// data is unreadable once the secret is destroyed, and rotating the barrier
+// rotates the keys of the mounts.
+func deriveMountKey(termKey, mountKey []byte, prefix string) ([]byte, error) {
+	key := make([]byte, len(termKey))
+	kdf := hkdf.New(sha256.New, termKey, mountKey, []byte("mount:"+prefix))
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, fmt.Errorf("failed to derive mount key: %w", err)
+	}
+	return key, nil
+}
+
+// aeadForPath returns the AEAD and version byte used to encrypt a value at
+// the given path with the given term. Values of mounts with a dedicated key
+// use a key derived for the mount, including seal wrapped values: seal
+// wrapping is applied by the storage on top of the barrier encryption, so
+// shredding covers them as well. Callers must hold b.l.
+func (b *AESGCMBarrier) aeadForPath(term uint32, path string) (cipher.AEAD, byte, error) {
+	if b.keyring != nil {
+		if prefix := mountKeyPrefix(path); prefix != "" && b.keyring.MountKey(prefix) != nil {
+			aead, err := b.aeadForMount(term, prefix)
+			return aead, AESGCMVersion3, err
+		}
+	}
+
+	aead, err := b.aeadForTerm(term)
+	return aead, b.currentAESGCMVersionByte, err
+}
+
+// aeadForValue returns the AEAD used to decrypt a value at the given path,
+// encrypted with the given term and version. Callers must hold b.l.
+func (b *AESGCMBarrier) aeadForValue(term uint32, version byte, path string) (cipher.AEAD, error) {
+	if version&^aesgcmCompressedFlag != AESGCMVersion3 {
+		return b.aeadForTerm(term)
+	}
+
+	prefix := mountKeyPrefix(path)
+	if prefix == "" || b.keyring == nil || b.keyring.MountKey(prefix) == nil {
+		return nil, ErrMountKeyDestroyed
+	}
+	return b.aeadForMount(term, prefix)
+}
+
+// aeadForMount returns the AES-GCM AEAD for the given term of the mount
+// stored under the given prefix
+func (b *AESGCMBarrier) aeadForMount(term uint32, prefix string) (cipher.AEAD, error) {
+	cacheKey := mountAEADCacheKey{term: term, prefix: prefix}
+
+	// Check the cache for the aead
+	b.cacheLock.RLock()
+	aead, ok := b.mountCache[cacheKey]
+	b.cacheLock.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	// Read the underlying keys
+	termKey := b.keyring.TermKey(term)
+	if termKey == nil {
+		return nil, nil
+	}
+	key, err := deriveMountKey(termKey.Value, b.keyring.MountKey(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer memzero(key)
+
+	// Create a new aead
+	aead, err = b.aeadFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the cache
+	b.cacheLock.Lock()
+	b.mountCache[cacheKey] = aead
+	b.cacheLock.Unlock()
+	return aead, nil
+}
+
+// CreateMountKey creates the secret dedicated to the mount stored under the
+// given prefix. Data written under the prefix afterwards is encrypted with
+// keys derived from it. Creating the key of a mount which already has one is
+// a no-op.
+func (b *AESGCMBarrier) CreateMountKey(ctx context.Context, prefix string, reader io.Reader) error {
+	if !strings.HasSuffix(prefix, "/") || mountKeyPrefix(prefix) != prefix {
+		return fmt.Errorf("invalid mount storage prefix %q", prefix)
+	}
+
+	b.l.Lock()
+	defer b.l.Unlock()
+	if b.sealed {
+		return ErrBarrierSealed
+	}
+	if b.keyring.MountKey(prefix) != nil {
+		return nil
+	}
+
+	// Generate a new key
+	key, err := b.GenerateKey(reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate mount key: %w", err)
+	}
+
+	newKeyring, err := b.keyring.AddMountKey(prefix, key)
+	if err != nil {
+		return fmt.Errorf("failed to add mount key: %w", err)
+	}
+
+	// Persist the new keyring
+	if err := b.persistKeyring(ctx, newKeyring); err != nil {
+		return err
+	}
+
+	// Swap the keyrings
+	b.keyring = newKeyring
+	return nil
+}
+
+// DestroyMountKey destroys the secret dedicated to the mount stored under
+// the given prefix, making all data encrypted with it unreadable, wherever
+// it was copied to. Only backups taken before still hold the secret. It is
+// removed from the keyring in a single write, so that the data is either
+// fully readable or fully shredded.
+func (b *AESGCMBarrier) DestroyMountKey(ctx context.Context, prefix string) error {
+	b.l.Lock()
+	defer b.l.Unlock()
+	if b.sealed {
+		return ErrBarrierSealed
+	}
+
+	key := b.keyring.MountKey(prefix)
+	if key == nil {
+		return nil
+	}
+
+	// Persist the new keyring
+	newKeyring := b.keyring.RemoveMountKey(prefix)
+	if err := b.persistKeyring(ctx, newKeyring); err != nil {
+		return err
+	}
+
+	// Swap the keyrings
+	b.keyring = newKeyring
+
+	// Drop all derived keys from the cache
+	b.cacheLock.Lock()
+	for cacheKey := range b.mountCache {
+		if cacheKey.prefix == prefix {
+			delete(b.mountCache, cacheKey)
+		}
+	}
+	b.cacheLock.Unlock()
+
+	memzero(key)
+	return nil
+}
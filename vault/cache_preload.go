@@ -0,0 +1,91 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/command/server"
+)
+
+// cacheWarmer is implemented by the physical cache.
+type cacheWarmer interface {
+	WarmAbsent(ctx context.Context, keys []string) ([]string, error)
+}
+
+// readCachePreloadManifest reads the storage keys listed in a cache preload
+// manifest, one per line. Blank lines and lines starting with "#" are
+// ignored.
+func readCachePreloadManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// preloadPhysicalCache warms the physical cache with the keys listed in the
+// configured preload manifest, if any. As the cache only affects
+// performance, failures are logged rather than returned.
+func (c *Core) preloadPhysicalCache(ctx context.Context) {
+	if c.cachingDisabled {
+		return
+	}
+	conf := c.rawConfig.Load()
+	if conf == nil {
+		return
+	}
+	path := conf.(*server.Config).CachePreloadManifest
+	if path == "" {
+		return
+	}
+
+	warmer, ok := c.physicalCache.(cacheWarmer)
+	if !ok {
+		c.logger.Warn("the physical cache does not support preloading, ignoring cache_preload_manifest")
+		return
+	}
+	keys, err := readCachePreloadManifest(path)
+	if err != nil {
+		c.logger.Error("failed to read cache preload manifest", "path", path, "error", err)
+		return
+	}
+
+	start := time.Now()
+	absent, err := warmer.WarmAbsent(ctx, keys)
+	if err != nil {
+		c.logger.Warn("failed to preload some keys into the cache", "error", err)
+	}
+	if len(absent) > 0 {
+		c.logger.Warn("cache preload manifest lists keys which do not exist", "keys", absent)
+	}
+	c.logger.Info("preloaded cache", "path", path, "keys", len(keys), "duration", time.Since(start))
+}
+
+// ReloadCachePreload reads the cache preload manifest again and warms the
+// keys it lists, so that the warm set can be changed without restarting.
+func (c *Core) ReloadCachePreload() {
+	if c.Sealed() {
+		return
+	}
+	c.preloadPhysicalCache(context.Background())
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openbao/openbao/command/server"
+	"github.com/openbao/openbao/sdk/v2/physical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCachePreloadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest")
+	require.NoError(t, os.WriteFile(path, []byte("# hot keys\ncore/mounts\n\n  core/auth  \n#core/keyring\n"), 0o600))
+
+	keys, err := readCachePreloadManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"core/mounts", "core/auth"}, keys)
+
+	_, err = readCachePreloadManifest(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestCore_ReloadCachePreload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest")
+	require.NoError(t, os.WriteFile(path, []byte("core/keyring\ndoes/not/exist\n"), 0o600))
+
+	c, _, _ := TestCoreUnsealedWithConfig(t, &CoreConfig{
+		RawConfig: &server.Config{
+			CachePreloadManifest: path,
+		},
+	})
+	cache := c.physicalCache.(interface {
+		Peek(key string) (*physical.Entry, bool)
+	})
+	cached := func(key string) bool {
+		entry, ok := cache.Peek(key)
+		return ok && entry != nil
+	}
+
+	// Missing keys do not prevent the others from being preloaded
+	c.physicalCache.Purge(context.Background())
+	c.ReloadCachePreload()
+	require.True(t, cached("core/keyring"))
+	require.False(t, cached("core/auth"))
+
+	// The manifest is read again on reload
+	require.NoError(t, os.WriteFile(path, []byte("core/auth\n"), 0o600))
+	c.physicalCache.Purge(context.Background())
+	c.ReloadCachePreload()
+	require.True(t, cached("core/auth"))
+	require.False(t, cached("core/keyring"))
+
+	// An unreadable manifest is not fatal
+	require.NoError(t, os.Remove(path))
+	c.ReloadCachePreload()
+}
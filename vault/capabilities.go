@@ -30,6 +30,23 @@ func (c *Core) Capabilities(ctx context.Context, token, path string) ([]string,
 		return nil, &logical.StatusBadRequest{Err: "invalid token"}
 	}
 
+	acl, err := c.tokenACL(ctx, te, false)
+	if err != nil {
+		return nil, err
+	}
+	if acl == nil {
+		return []string{DenyCapability}, nil
+	}
+
+	capabilities := acl.Capabilities(ctx, path)
+	sort.Strings(capabilities)
+	return capabilities, nil
+}
+
+// tokenACL returns the ACL of the given token, built in the namespace of the
+// token, or nil if the token has no policies. If trace is set, the ACL records
+// the provenance of its permissions.
+func (c *Core) tokenACL(ctx context.Context, te *logical.TokenEntry, trace bool) (*ACL, error) {
 	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, c)
 	if err != nil {
 		return nil, err
@@ -73,18 +90,14 @@ func (c *Core) Capabilities(ctx context.Context, token, path string) ([]string,
 	}
 
 	if policyCount == 0 {
-		return []string{DenyCapability}, nil
+		return nil, nil
 	}
 
 	// Construct the corresponding ACL object. ACL construction should be
 	// performed on the token's namespace.
 	tokenCtx := namespace.ContextWithNamespace(ctx, tokenNS)
-	acl, err := c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
-	if err != nil {
-		return nil, err
+	if trace {
+		return c.policyStore.tracedACL(tokenCtx, entity, policyNames, policies...)
 	}
-
-	capabilities := acl.Capabilities(ctx, path)
-	sort.Strings(capabilities)
-	return capabilities, nil
+	return c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
 }
@@ -87,7 +87,7 @@ path "secret/sample" {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	expected := []string{"create", "read", "sudo", "delete", "update"}
+	expected := []string{"create", "read", "sudo", "delete", "update", "patch"}
 	sort.Strings(actual)
 	sort.Strings(expected)
 	if !reflect.DeepEqual(actual, expected) {
@@ -112,7 +112,7 @@ path "secret/sample" {
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	expected = []string{"create", "read", "sudo", "delete", "update", "list"}
+	expected = []string{"create", "read", "sudo", "delete", "update", "patch", "list"}
 	sort.Strings(actual)
 	sort.Strings(expected)
 	if !reflect.DeepEqual(actual, expected) {
@@ -157,7 +157,7 @@ func TestCapabilities_TemplatedPolicies(t *testing.T) {
 			}
 			`,
 			fmt.Sprintf("secret/%s/sample", entityID),
-			[]string{"update", "create"},
+			[]string{"update", "create", "patch"},
 		},
 		{
 			`{"name": "testpolicy", "path": {"secret/{{identity.entity.id}}/sample": {"capabilities": ["read", "create"]}}}`,
@@ -4,10 +4,12 @@
 package vault
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/json"
@@ -33,6 +35,10 @@ const (
 	corePrivateKeyTypeP521    = "p521"
 	corePrivateKeyTypeED25519 = "ed25519"
 
+	// How soon the active node tries again after failing to rotate the local
+	// cluster cert
+	clusterCertRotationRetryInterval = 10 * time.Second
+
 	// Internal so as not to log a trace message
 	IntNoForwardingHeaderName = "X-Vault-Internal-No-Request-Forwarding"
 )
@@ -99,53 +105,241 @@ func (c *Core) loadLocalClusterTLS(adv activeAdvertisement) (retErr error) {
 		}
 	}()
 
-	switch {
-	case adv.ClusterAddr == "":
+	// Only the active node serves connections with the previous cert
+	c.localClusterPrevCert.Store((*tls.Certificate)(nil))
+
+	if adv.ClusterAddr == "" {
 		// Clustering disabled on the server, don't try to look for params
 		return nil
+	}
+
+	key, certBytes, cert, err := c.parseLocalClusterTLS(adv)
+	if err != nil {
+		return err
+	}
+
+	c.localClusterPrivateKey.Store(key)
+	c.localClusterCert.Store(certBytes)
+	c.localClusterParsedCert.Store(cert)
+
+	return nil
+}
 
+// parseLocalClusterTLS returns the local cluster key and cert of an
+// advertisement.
+func (c *Core) parseLocalClusterTLS(adv activeAdvertisement) (*ecdsa.PrivateKey, []byte, *x509.Certificate, error) {
+	switch {
 	case adv.ClusterKeyParams == nil:
 		c.logger.Error("no key params found loading local cluster TLS information")
-		return fmt.Errorf("no local cluster key params found")
+		return nil, nil, nil, fmt.Errorf("no local cluster key params found")
 
 	case adv.ClusterKeyParams.X == nil, adv.ClusterKeyParams.Y == nil, adv.ClusterKeyParams.D == nil:
 		c.logger.Error("failed to parse local cluster key due to missing params")
-		return fmt.Errorf("failed to parse local cluster key")
+		return nil, nil, nil, fmt.Errorf("failed to parse local cluster key")
 
 	case adv.ClusterKeyParams.Type != corePrivateKeyTypeP521:
 		c.logger.Error("unknown local cluster key type", "key_type", adv.ClusterKeyParams.Type)
-		return fmt.Errorf("failed to find valid local cluster key type")
+		return nil, nil, nil, fmt.Errorf("failed to find valid local cluster key type")
 
 	case adv.ClusterCert == nil || len(adv.ClusterCert) == 0:
 		c.logger.Error("no local cluster cert found")
-		return fmt.Errorf("no local cluster cert found")
+		return nil, nil, nil, fmt.Errorf("no local cluster cert found")
 
 	}
 
-	c.localClusterPrivateKey.Store(&ecdsa.PrivateKey{
+	key := &ecdsa.PrivateKey{
 		PublicKey: ecdsa.PublicKey{
 			Curve: elliptic.P521(),
 			X:     adv.ClusterKeyParams.X,
 			Y:     adv.ClusterKeyParams.Y,
 		},
 		D: adv.ClusterKeyParams.D,
-	})
+	}
 
 	locCert := make([]byte, len(adv.ClusterCert))
 	copy(locCert, adv.ClusterCert)
-	c.localClusterCert.Store(locCert)
 
 	cert, err := x509.ParseCertificate(adv.ClusterCert)
 	if err != nil {
 		c.logger.Error("failed parsing local cluster certificate", "error", err)
-		return fmt.Errorf("error parsing local cluster certificate: %w", err)
+		return nil, nil, nil, fmt.Errorf("error parsing local cluster certificate: %w", err)
+	}
+
+	return key, locCert, cert, nil
+}
+
+// checkClusterTLSUpgrades loads the local cluster cert advertised by the
+// active node once it could have been rotated. It leaves the current cert
+// and forwarding connection alone otherwise: the active node trusts the
+// previous cert until it expires.
+func (c *Core) checkClusterTLSUpgrades(ctx context.Context) error {
+	parsedCert := c.localClusterParsedCert.Load().(*x509.Certificate)
+	if parsedCert == nil {
+		return nil
+	}
+
+	// Certs are rotated after half their lifetime at the earliest
+	lifetime := parsedCert.NotAfter.Sub(parsedCert.NotBefore)
+	if time.Now().Before(parsedCert.NotBefore.Add(lifetime / 2)) {
+		return nil
 	}
 
+	return c.reloadLocalClusterTLS(ctx)
+}
+
+// reloadLocalClusterTLS loads the local cluster key and cert from the
+// advertisement of the current active node, if they have changed.
+func (c *Core) reloadLocalClusterTLS(ctx context.Context) error {
+	c.leaderParamsLock.Lock()
+	defer c.leaderParamsLock.Unlock()
+
+	clusterLeaderParams := c.clusterLeaderParams.Load().(*ClusterLeaderParams)
+	if clusterLeaderParams == nil || clusterLeaderParams.LeaderUUID == "" {
+		return nil
+	}
+
+	entry, err := c.barrier.Get(ctx, coreLeaderPrefix+clusterLeaderParams.LeaderUUID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+
+	var adv activeAdvertisement
+	if err := jsonutil.DecodeJSON(entry.Value, &adv); err != nil {
+		return err
+	}
+	if adv.ClusterAddr == "" || bytes.Equal(adv.ClusterCert, c.localClusterCert.Load().([]byte)) {
+		return nil
+	}
+
+	key, certBytes, cert, err := c.parseLocalClusterTLS(adv)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("loading rotated local cluster certificate", "host", cert.Subject.CommonName)
+	c.localClusterPrivateKey.Store(key)
+	c.localClusterCert.Store(certBytes)
 	c.localClusterParsedCert.Store(cert)
 
 	return nil
 }
 
+// periodicRotateClusterTLS replaces the local cluster key and cert every
+// rotation interval for as long as this node is active.
+func (c *Core) periodicRotateClusterTLS(ctx context.Context, leaderUUID string) {
+	timer := time.NewTimer(c.clusterCertRotationInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			next := c.clusterCertRotationInterval
+			if err := c.rotateClusterTLS(ctx, leaderUUID); err != nil {
+				c.logger.Error("failed to rotate local cluster certificate, keeping the current one", "error", err)
+				next = clusterCertRotationRetryInterval
+			}
+			timer.Reset(next)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rotateClusterTLS generates a new local cluster key and cert and advertises
+// them to standbys. The replaced cert remains trusted until it expires, so
+// standbys which have yet to load the new one can still connect.
+func (c *Core) rotateClusterTLS(ctx context.Context, leaderUUID string) error {
+	c.clusterParamsLock.Lock()
+	defer c.clusterParamsLock.Unlock()
+
+	key, err := ecdsa.GenerateKey(elliptic.P521(), c.secureRandomReader)
+	if err != nil {
+		return fmt.Errorf("failed to generate local cluster key: %w", err)
+	}
+
+	certBytes, parsedCert, err := c.generateLocalClusterCert(key)
+	if err != nil {
+		return err
+	}
+
+	// Standbys only learn of the new cert through the advertisement, so the
+	// current one must stay in use if it can't be updated.
+	if err := c.storeLeaderAdvertisement(ctx, leaderUUID, key, certBytes); err != nil {
+		return fmt.Errorf("failed to advertise local cluster certificate: %w", err)
+	}
+
+	prevKey := c.localClusterPrivateKey.Load().(*ecdsa.PrivateKey)
+	prevCert := c.localClusterCert.Load().([]byte)
+	prevParsedCert := c.localClusterParsedCert.Load().(*x509.Certificate)
+	if prevKey != nil && prevParsedCert != nil {
+		c.localClusterPrevCert.Store(&tls.Certificate{
+			Certificate: [][]byte{prevCert},
+			PrivateKey:  prevKey,
+			Leaf:        prevParsedCert,
+		})
+	}
+
+	c.localClusterPrivateKey.Store(key)
+	c.localClusterCert.Store(certBytes)
+	c.localClusterParsedCert.Store(parsedCert)
+
+	c.logger.Info("rotated local cluster certificate", "host", parsedCert.Subject.CommonName)
+	return nil
+}
+
+// generateLocalClusterCert creates a self-signed certificate for the given
+// local cluster key. When certs are rotated, it is valid for two rotation
+// intervals, so that it is still trusted for an interval after it has been
+// replaced.
+func (c *Core) generateLocalClusterCert(key *ecdsa.PrivateKey) ([]byte, *x509.Certificate, error) {
+	host, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, nil, err
+	}
+	host = fmt.Sprintf("fw-%s", host)
+	c.logger.Debug("generating local cluster certificate", "host", host)
+
+	// 30 years of single-active uptime ought to be enough for anybody
+	notAfter := time.Now().Add(262980 * time.Hour)
+	if c.clusterCertRotationInterval > 0 {
+		notAfter = time.Now().Add(2 * c.clusterCertRotationInterval)
+	}
+
+	template := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: host,
+		},
+		DNSNames: []string{host},
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth,
+			x509.ExtKeyUsageClientAuth,
+		},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign,
+		SerialNumber:          big.NewInt(mathrand.Int63()),
+		NotBefore:             time.Now().Add(-30 * time.Second),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		c.logger.Error("error generating self-signed cert", "error", err)
+		return nil, nil, fmt.Errorf("unable to generate local cluster certificate: %w", err)
+	}
+
+	parsedCert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		c.logger.Error("error parsing self-signed cert", "error", err)
+		return nil, nil, fmt.Errorf("error parsing generated certificate: %w", err)
+	}
+
+	return certBytes, parsedCert, nil
+}
+
 // setupCluster creates storage entries for holding Vault cluster information.
 // Entries will be created only if they are not already present. If clusterName
 // is not supplied, this method will auto-generate it.
@@ -221,41 +415,10 @@ func (c *Core) setupCluster(ctx context.Context) error {
 
 		// Create a certificate
 		if c.localClusterCert.Load().([]byte) == nil {
-			host, err := uuid.GenerateUUID()
+			certBytes, parsedCert, err := c.generateLocalClusterCert(c.localClusterPrivateKey.Load().(*ecdsa.PrivateKey))
 			if err != nil {
 				return err
 			}
-			host = fmt.Sprintf("fw-%s", host)
-			c.logger.Debug("generating local cluster certificate", "host", host)
-			template := &x509.Certificate{
-				Subject: pkix.Name{
-					CommonName: host,
-				},
-				DNSNames: []string{host},
-				ExtKeyUsage: []x509.ExtKeyUsage{
-					x509.ExtKeyUsageServerAuth,
-					x509.ExtKeyUsageClientAuth,
-				},
-				KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign,
-				SerialNumber: big.NewInt(mathrand.Int63()),
-				NotBefore:    time.Now().Add(-30 * time.Second),
-				// 30 years of single-active uptime ought to be enough for anybody
-				NotAfter:              time.Now().Add(262980 * time.Hour),
-				BasicConstraintsValid: true,
-				IsCA:                  true,
-			}
-
-			certBytes, err := x509.CreateCertificate(rand.Reader, template, template, c.localClusterPrivateKey.Load().(*ecdsa.PrivateKey).Public(), c.localClusterPrivateKey.Load().(*ecdsa.PrivateKey))
-			if err != nil {
-				c.logger.Error("error generating self-signed cert", "error", err)
-				return fmt.Errorf("unable to generate local cluster certificate: %w", err)
-			}
-
-			parsedCert, err := x509.ParseCertificate(certBytes)
-			if err != nil {
-				c.logger.Error("error parsing self-signed cert", "error", err)
-				return fmt.Errorf("error parsing generated certificate: %w", err)
-			}
 
 			c.localClusterCert.Store(certBytes)
 			c.localClusterParsedCert.Store(parsedCert)
@@ -7,8 +7,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sync"
 	"testing"
 	"time"
@@ -250,6 +252,95 @@ func testCluster_ForwardRequestsCommon(t *testing.T, clusterOpts *TestClusterOpt
 	testCluster_Forwarding(t, cluster, 1, 2, root, "core3")
 }
 
+func TestCluster_RotateClusterTLS(t *testing.T) {
+	cluster := NewTestCluster(t, &CoreConfig{
+		ClusterCertRotationInterval: time.Hour,
+	}, nil)
+	cores := cluster.Cores
+	cores[0].Handler.(*http.ServeMux).HandleFunc("/core1", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(201)
+		w.Write([]byte("core1"))
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	root := cluster.RootToken
+	active := cores[0].Core
+	TestWaitActiveForwardingReady(t, active)
+
+	testCluster_ForwardRequests(t, cores[1], root, "core1")
+	testCluster_ForwardRequests(t, cores[2], root, "core1")
+
+	prevCert := active.localClusterParsedCert.Load().(*x509.Certificate)
+	if lifetime := prevCert.NotAfter.Sub(prevCert.NotBefore); lifetime > 2*time.Hour+time.Minute {
+		t.Fatalf("expected cert lifetime of two rotation intervals, got %v", lifetime)
+	}
+	leaderUUID := cores[1].clusterLeaderParams.Load().(*ClusterLeaderParams).LeaderUUID
+
+	// A failed rotation keeps the current cert
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := active.rotateClusterTLS(canceledCtx, leaderUUID); err == nil {
+		t.Fatal("expected rotation to fail")
+	}
+	if !active.localClusterParsedCert.Load().(*x509.Certificate).Equal(prevCert) {
+		t.Fatal("expected failed rotation to keep the current cert")
+	}
+
+	if err := active.rotateClusterTLS(context.Background(), leaderUUID); err != nil {
+		t.Fatal(err)
+	}
+	newCert := active.localClusterParsedCert.Load().(*x509.Certificate)
+	if newCert.Equal(prevCert) {
+		t.Fatal("expected a new cert")
+	}
+
+	handler, ok := active.getClusterListener().Handler(consts.RequestForwardingALPN)
+	if !ok {
+		t.Fatal("no request forwarding handler")
+	}
+	caList, err := handler.CALookup(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caList) != 2 {
+		t.Fatalf("expected both certs to be trusted, got %d", len(caList))
+	}
+
+	// Existing connections keep working
+	testCluster_ForwardRequests(t, cores[1], root, "core1")
+
+	// New connections from standbys holding the previous cert too
+	clusterURL, err := url.Parse(active.ClusterAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dial := cores[2].getClusterListener().GetDialerFunc(context.Background(), consts.RequestForwardingALPN)
+	testDial := func(expected *x509.Certificate) {
+		t.Helper()
+		conn, err := dial(clusterURL.Host, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if peer := conn.(*tls.Conn).ConnectionState().PeerCertificates[0]; !peer.Equal(expected) {
+			t.Fatalf("expected active node to present %s, got %s", expected.Subject.CommonName, peer.Subject.CommonName)
+		}
+	}
+	testDial(prevCert)
+
+	// Standbys then load the new one
+	if err := cores[2].reloadLocalClusterTLS(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !cores[2].localClusterParsedCert.Load().(*x509.Certificate).Equal(newCert) {
+		t.Fatal("expected standby to load the new cert")
+	}
+	testDial(newCert)
+	testCluster_ForwardRequests(t, cores[2], root, "core1")
+}
+
 func testCluster_Forwarding(t *testing.T, cluster *TestCluster, oldLeaderCoreIdx, newLeaderCoreIdx int, rootToken, remoteCoreID string) {
 	t.Logf("new leaderidx will be %d, stepping down other cores to make it so", newLeaderCoreIdx)
 	err := cluster.Cores[oldLeaderCoreIdx].StepDown(context.Background(), &logical.Request{
@@ -305,6 +305,12 @@ type Core struct {
 	// unlockInfo has the keys provided to Unseal until the threshold number of parts is available, as well as the operation nonce
 	unlockInfo *unlockInformation
 
+	// unsealChallenge is the single-use challenge which the hardware token
+	// assertions submitted with the key shares must sign, if the shares are
+	// bound to credentials.
+	unsealChallenge     []byte
+	unsealChallengeLock sync.Mutex
+
 	// generateRootProgress holds the shares until we reach enough
 	// to verify the master key
 	generateRootConfig   *GenerateRootConfig
@@ -449,6 +455,11 @@ type Core struct {
 	localClusterCert *atomic.Value
 	// The parsed form of the local cluster cert
 	localClusterParsedCert *atomic.Value
+	// The cluster cert replaced by the last rotation, still trusted until it
+	// expires so that connections set up before the rotation keep working
+	localClusterPrevCert *atomic.Value
+	// How often the active node rotates the local cluster cert, if at all
+	clusterCertRotationInterval time.Duration
 	// The TCP addresses we should use for clustering
 	clusterListenerAddrs []*net.TCPAddr
 	// The handler to use for request forwarding
@@ -477,6 +488,11 @@ type Core struct {
 	// CORS Information
 	corsConfig *CORSConfig
 
+	// wrappingConfigs caches the response wrapping configuration of each
+	// namespace, by namespace ID
+	wrappingConfigs    map[string]*WrappingConfig
+	wrappingConfigLock sync.RWMutex
+
 	// replicationState keeps the current replication state cached for quick
 	// lookup; activeNodeReplicationState stores the active value on standbys
 	replicationState           *uint32
@@ -631,6 +647,10 @@ type Core struct {
 	// If any role based quota (LCQ or RLQ) is enabled, don't track lease counts by role
 	impreciseLeaseRoleTracking bool
 
+	// Require the "patch" capability for patch operations, rather than
+	// also accepting "update"
+	strictPatchCapability bool
+
 	// Config value for "detect_deadlocks".
 	detectDeadlocks []string
 }
@@ -693,6 +713,14 @@ type CoreConfig struct {
 	// If any role based quota (LCQ or RLQ) is enabled, don't track lease counts by role
 	ImpreciseLeaseRoleTracking bool
 
+	// Require the "patch" capability for patch operations, rather than
+	// also accepting "update"
+	StrictPatchCapability bool
+
+	// Compression algorithm applied to values before the barrier encrypts
+	// them, empty to store them uncompressed
+	BarrierCompression string
+
 	// Disables the trace display for Sentinel checks
 	DisableSentinelTrace bool
 
@@ -702,6 +730,10 @@ type CoreConfig struct {
 	// Custom cache size for the LRU cache on the physical backend, or zero for default
 	CacheSize int
 
+	// StorageChecksums makes the LRU cache on the physical backend verify
+	// the checksums of the entries it serves
+	StorageChecksums bool
+
 	// Set as the leader address for HA
 	RedirectAddr string
 
@@ -753,6 +785,10 @@ type CoreConfig struct {
 
 	ClusterHeartbeatInterval time.Duration
 
+	// Interval at which the active node replaces its request forwarding
+	// TLS certificate, zero to keep it for as long as the node is active
+	ClusterCertRotationInterval time.Duration
+
 	// number of workers to use for lease revocation in the expiration manager
 	NumExpirationWorkers int
 
@@ -923,6 +959,7 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		localClusterPrivateKey:         new(atomic.Value),
 		localClusterCert:               new(atomic.Value),
 		localClusterParsedCert:         new(atomic.Value),
+		localClusterPrevCert:           new(atomic.Value),
 		activeNodeReplicationState:     new(uint32),
 		keepHALockOnStepDown:           new(uint32),
 		replicationFailure:             new(uint32),
@@ -940,6 +977,7 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		raftInfo:                       new(atomic.Value),
 		raftJoinDoneCh:                 make(chan struct{}),
 		clusterHeartbeatInterval:       clusterHeartbeatInterval,
+		clusterCertRotationInterval:    conf.ClusterCertRotationInterval,
 		keyRotateGracePeriod:           new(int64),
 		numExpirationWorkers:           conf.NumExpirationWorkers,
 		raftFollowerStates:             raft.NewFollowerStates(),
@@ -954,6 +992,7 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 		expirationRevokeRetryBase:      conf.ExpirationRevokeRetryBase,
 		numRollbackWorkers:             conf.NumRollbackWorkers,
 		impreciseLeaseRoleTracking:     conf.ImpreciseLeaseRoleTracking,
+		strictPatchCapability:          conf.StrictPatchCapability,
 		detectDeadlocks:                detectDeadlocks,
 	}
 
@@ -979,6 +1018,7 @@ func CreateCore(conf *CoreConfig) (*Core, error) {
 	c.localClusterCert.Store(([]byte)(nil))
 	c.localClusterParsedCert.Store((*x509.Certificate)(nil))
 	c.localClusterPrivateKey.Store((*ecdsa.PrivateKey)(nil))
+	c.localClusterPrevCert.Store((*tls.Certificate)(nil))
 
 	c.clusterLeaderParams.Store((*ClusterLeaderParams)(nil))
 	c.clusterAddr.Store(conf.ClusterAddr)
@@ -1051,6 +1091,16 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	if err != nil {
 		return nil, fmt.Errorf("barrier setup failed: %w", err)
 	}
+	if conf.BarrierCompression != "" {
+		compressor, ok := c.barrier.(interface{ SetCompression(string) error })
+		if !ok {
+			return nil, errors.New("barrier does not support compression")
+		}
+		if err := compressor.SetCompression(conf.BarrierCompression); err != nil {
+			return nil, fmt.Errorf("barrier setup failed: %w", err)
+		}
+		c.logger.Warn("barrier compression enabled; compressed values cannot be read by versions without compression support", "type", conf.BarrierCompression)
+	}
 
 	// We create the funcs here, then populate the given config with it so that
 	// the caller can share state
@@ -1378,13 +1428,20 @@ func (c *Core) ResetUnsealProcess() {
 }
 
 func (c *Core) UnsealMigrate(key []byte) (bool, error) {
-	err := c.unsealFragment(key, true)
+	err := c.unsealFragment(key, true, nil)
 	return !c.Sealed(), err
 }
 
 // Unseal is used to provide one of the key parts to unseal the Vault.
 func (c *Core) Unseal(key []byte) (bool, error) {
-	err := c.unsealFragment(key, false)
+	err := c.unsealFragment(key, false, nil)
+	return !c.Sealed(), err
+}
+
+// UnsealWithAttestation is used to provide one of the key parts to unseal the
+// Vault, along with the assertion of the hardware token bound to it.
+func (c *Core) UnsealWithAttestation(key []byte, migrate bool, attestation *ShareAttestation) (bool, error) {
+	err := c.unsealFragment(key, migrate, attestation)
 	return !c.Sealed(), err
 }
 
@@ -1407,10 +1464,13 @@ func (c *Core) Unseal(key []byte) (bool, error) {
 // read from storage.  For autoseal the combined key isn't used
 // except to verify that the stored recovery key matches.
 //
+// If the key shares are bound to hardware token credentials, the
+// fragment is only recorded once the attestation is verified.
+//
 // In migration scenarios a side-effect of unsealing is that
 // the members of c.migrationInfo are populated (excluding
 // .seal, which must already be populated before unseal is called.)
-func (c *Core) unsealFragment(key []byte, migrate bool) error {
+func (c *Core) unsealFragment(key []byte, migrate bool, attestation *ShareAttestation) error {
 	defer metrics.MeasureSince([]string{"core", "unseal"}, time.Now())
 
 	c.stateLock.Lock()
@@ -1467,6 +1527,10 @@ func (c *Core) unsealFragment(key []byte, migrate bool) error {
 		sealToUse = c.migrationInfo.seal
 	}
 
+	if err := c.verifyShareAttestation(ctx, sealToUse, key, attestation); err != nil {
+		return err
+	}
+
 	newKey, err := c.recordUnsealPart(key)
 	if !newKey || err != nil {
 		return err
@@ -1593,11 +1657,9 @@ func (c *Core) recordUnsealPart(key []byte) (bool, error) {
 	return true, nil
 }
 
-// getUnsealKey uses key fragments recorded by recordUnsealPart and
-// returns the combined key if the key share threshold is met.
-// If the key fragments are part of a recovery key, also verify that
-// it matches the stored recovery key on disk.
-func (c *Core) getUnsealKey(ctx context.Context, seal Seal) ([]byte, error) {
+// unsealConfig returns the configuration of the key shares submitted to
+// unseal using the given seal.
+func (c *Core) unsealConfig(ctx context.Context, seal Seal) (*SealConfig, error) {
 	var config *SealConfig
 	var err error
 
@@ -1620,6 +1682,19 @@ func (c *Core) getUnsealKey(ctx context.Context, seal Seal) ([]byte, error) {
 		return nil, fmt.Errorf("failed to obtain seal/recovery configuration")
 	}
 
+	return config, nil
+}
+
+// getUnsealKey uses key fragments recorded by recordUnsealPart and
+// returns the combined key if the key share threshold is met.
+// If the key fragments are part of a recovery key, also verify that
+// it matches the stored recovery key on disk.
+func (c *Core) getUnsealKey(ctx context.Context, seal Seal) ([]byte, error) {
+	config, err := c.unsealConfig(ctx, seal)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if we don't have enough keys to unlock, proceed through the rest of
 	// the call only if we have met the threshold
 	if len(c.unlockInfo.Parts) < config.SecretThreshold {
@@ -2251,6 +2326,7 @@ func (s standardUnsealStrategy) unseal(ctx context.Context, logger log.Logger, c
 	if err := c.loadCORSConfig(ctx); err != nil {
 		return err
 	}
+	c.resetWrappingConfigs()
 	if err := c.loadCredentials(ctx); err != nil {
 		return err
 	}
@@ -2342,6 +2418,7 @@ func (c *Core) postUnseal(ctx context.Context, ctxCancelFunc context.CancelFunc,
 	c.physicalCache.Purge(ctx)
 	if !c.cachingDisabled {
 		c.physicalCache.SetEnabled(true)
+		c.preloadPhysicalCache(ctx)
 	}
 
 	// Purge these for safety in case of a rekey
@@ -4,6 +4,7 @@
 package vault
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"reflect"
@@ -356,6 +357,43 @@ func TestNewCore_badRedirectAddr(t *testing.T) {
 	}
 }
 
+func TestNewCore_barrierCompression(t *testing.T) {
+	logger = logging.NewVaultLogger(log.Trace)
+
+	inm, err := inmem.NewInmem(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewCore(&CoreConfig{
+		BarrierCompression: "bogus",
+		Physical:           inm,
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	c, _, _ := TestCoreUnsealedWithConfig(t, &CoreConfig{BarrierCompression: "snappy"})
+	value := bytes.Repeat([]byte("a"), 4096)
+	if err := c.barrier.Put(context.Background(), &logical.StorageEntry{Key: "foo", Value: value}); err != nil {
+		t.Fatal(err)
+	}
+	pe, err := c.physical.Get(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pe.Value[4]&aesgcmCompressedFlag == 0 {
+		t.Fatal("expected the value to be compressed")
+	}
+	entry, err := c.barrier.Get(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(entry.Value, value) {
+		t.Fatal("value mismatch")
+	}
+}
+
 func TestSealConfig_Invalid(t *testing.T) {
 	s := &SealConfig{
 		SecretShares:    2,
@@ -16,7 +16,11 @@ func coreInit(c *Core, conf *CoreConfig) error {
 	// Wrap the physical backend in a cache layer if enabled
 	cacheLogger := c.baseLogger.Named("storage.cache")
 	c.allLoggers = append(c.allLoggers, cacheLogger)
-	c.physical = physical.NewCache(phys, conf.CacheSize, cacheLogger, c.MetricSink().Sink)
+	cacheConfig := physical.CacheConfig{
+		Size:            conf.CacheSize,
+		VerifyChecksums: conf.StorageChecksums,
+	}
+	c.physical = physical.NewCacheWithConfig(phys, cacheConfig, cacheLogger, c.MetricSink().Sink)
 	c.physicalCache = c.physical.(physical.ToggleablePurgemonster)
 
 	// Wrap in encoding checks
@@ -5,6 +5,8 @@ package vault
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +34,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/jsonutil"
 	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/openbao/openbao/vault/quotas"
 	uberAtomic "go.uber.org/atomic"
 )
 
@@ -81,6 +84,13 @@ const (
 	// list API **without** the `force` flag set
 	MaxIrrevocableLeasesToReturn = 10000
 
+	// suggestedRenewFraction is the fraction of a token's remaining lease
+	// after which clients are advised to renew it. Each token is further
+	// offset by up to suggestedRenewJitter so that clients sharing a TTL do
+	// not all renew at once.
+	suggestedRenewFraction = 0.6
+	suggestedRenewJitter   = 0.2
+
 	MaxIrrevocableLeasesWarning = "Command halted because many irrevocable leases were found. To emit the entire list, re-run the command with force set true."
 )
 
@@ -502,6 +512,7 @@ func (m *ExpirationManager) invalidate(key string) {
 				pending.timer.Stop()
 				m.pending.Delete(leaseID)
 				m.leaseCount--
+				m.releaseLeaseCountQuota(leaseID)
 			default:
 				// Update the lease in memory
 				m.updatePendingInternal(le)
@@ -518,6 +529,7 @@ func (m *ExpirationManager) invalidate(key string) {
 					m.irrevocableLeaseCount--
 
 					m.leaseCount--
+					m.releaseLeaseCountQuota(leaseID)
 				}
 				return
 			}
@@ -1032,6 +1044,7 @@ func (m *ExpirationManager) revokeCommon(ctx context.Context, leaseID string, fo
 		m.irrevocable.Delete(leaseID)
 		m.irrevocableLeaseCount--
 	}
+	m.releaseLeaseCountQuota(leaseID)
 	m.pendingLock.Unlock()
 
 	if m.logger.IsInfo() && !skipToken && m.logLeaseExpirations {
@@ -1221,6 +1234,17 @@ func (m *ExpirationManager) Renew(ctx context.Context, leaseID string, increment
 
 	// Attempt to renew the entry
 	resp, err := m.renewEntry(ctx, le, increment)
+	if errors.Is(err, logical.ErrLeaseInvalidated) {
+		// The backend found the secret to be no longer valid: expire the
+		// lease right away so that it is revoked by the expiration routine.
+		m.logger.Warn("lease invalidated by backend during renewal, revoking it", "lease_id", leaseID)
+		le.ExpireTime = time.Now()
+		if err := m.persistEntry(ctx, le); err != nil {
+			return nil, err
+		}
+		m.updatePending(le)
+		return nil, fmt.Errorf("%w: the lease is being revoked", logical.ErrLeaseInvalidated)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1398,6 +1422,7 @@ func (m *ExpirationManager) RenewToken(ctx context.Context, req *logical.Request
 	le.Auth = resp.Auth
 	le.ExpireTime = resp.Auth.ExpirationTime()
 	le.LastRenewalTime = time.Now()
+	le.Auth.SuggestedRenewAt = le.suggestedRenewAt(le.maxExpireTime(sysView))
 
 	if err := m.persistEntry(ctx, le); err != nil {
 		return nil, err
@@ -1454,6 +1479,7 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		Data:            resp.Data,
 		Secret:          resp.Secret,
 		LoginRole:       loginRole,
+		EntityID:        te.EntityID,
 		IssueTime:       time.Now(),
 		ExpireTime:      resp.Secret.ExpirationTime(),
 		namespace:       ns,
@@ -1493,6 +1519,7 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 				retErr = multierror.Append(retErr, fmt.Errorf("an additional error was encountered removing lease indexes associated with the newly-generated secret: %w", err))
 			}
 
+			m.releaseLeaseCountQuota(leaseID)
 			m.deleteLockForLease(leaseID)
 		}
 	}()
@@ -1512,6 +1539,12 @@ func (m *ExpirationManager) Register(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	// Count the lease against its lease count quota before persisting it, so
+	// that the generated secret is revoked if the quota is exceeded.
+	if err := m.applyLeaseCountQuota(ctx, le); err != nil {
+		return "", err
+	}
+
 	// Acquire the lock here so persistEntry and updatePending are atomic,
 	// although it is *very unlikely* that anybody could grab the lease ID
 	// before this function returns. (They could find it in an index, or
@@ -1600,18 +1633,27 @@ func (m *ExpirationManager) RegisterAuth(ctx context.Context, te *logical.TokenE
 		Auth:        auth,
 		Path:        te.Path,
 		LoginRole:   loginRole,
+		EntityID:    auth.EntityID,
 		IssueTime:   time.Now(),
 		ExpireTime:  authExpirationTime,
 		namespace:   tokenNS,
 		Version:     1,
 	}
 
+	sysView := m.router.MatchingSystemView(saltCtx, te.Path)
+	auth.SuggestedRenewAt = le.suggestedRenewAt(le.maxExpireTime(sysView))
+
 	leaseLock := m.lockForLeaseID(leaseID)
 	leaseLock.Lock()
 	defer leaseLock.Unlock()
 
+	if err := m.applyLeaseCountQuota(ctx, &le); err != nil {
+		return err
+	}
+
 	// Encode the entry
 	if err := m.persistEntry(ctx, &le); err != nil {
+		m.releaseLeaseCountQuota(leaseID)
 		return err
 	}
 
@@ -1713,6 +1755,7 @@ func (m *ExpirationManager) leaseTimesForExport(le *leaseEntry) *leaseEntry {
 		ret.Auth = &logical.Auth{}
 		ret.Auth.Renewable = le.Auth.Renewable
 		ret.Auth.TTL = le.Auth.TTL
+		ret.Auth.SuggestedRenewAt = le.Auth.SuggestedRenewAt
 	}
 
 	return ret
@@ -1820,6 +1863,7 @@ func (m *ExpirationManager) updatePendingInternal(le *leaseEntry) {
 			info.(pendingInfo).timer.Stop()
 			m.pending.Delete(le.LeaseID)
 			m.leaseCount--
+			m.releaseLeaseCountQuota(le.LeaseID)
 		}
 		return
 	}
@@ -1871,6 +1915,12 @@ func (m *ExpirationManager) updatePendingInternal(le *leaseEntry) {
 	}
 	if leaseCreated {
 		m.leaseCount++
+
+		action := quotas.LeaseActionCreated
+		if m.inRestoreMode() {
+			action = quotas.LeaseActionLoaded
+		}
+		m.updateLeaseCountQuotas(action, le)
 	}
 }
 
@@ -2482,6 +2532,77 @@ func (m *ExpirationManager) removeFromPending(ctx context.Context, leaseID strin
 	}
 }
 
+// leaseQuotaRequest returns the request used to count the given lease against
+// lease count quotas.
+func (m *ExpirationManager) leaseQuotaRequest(le *leaseEntry) *quotas.Request {
+	ns := le.namespace
+	if ns == nil {
+		ns = namespace.RootNamespace
+	}
+
+	mountPath := strings.TrimPrefix(m.router.MatchingMount(namespace.ContextWithNamespace(m.quitContext, ns), le.Path), ns.Path)
+
+	// Leases persisted before entities were tracked on them only carry the
+	// entity of auth leases.
+	entityID := le.EntityID
+	if entityID == "" && le.Auth != nil {
+		entityID = le.Auth.EntityID
+	}
+
+	return &quotas.Request{
+		Type:          quotas.TypeLeaseCount,
+		Path:          le.Path,
+		Role:          le.LoginRole,
+		NamespacePath: ns.Path,
+		MountPath:     mountPath,
+		LeaseID:       le.LeaseID,
+		EntityID:      entityID,
+	}
+}
+
+// applyLeaseCountQuota counts a lease which is about to be created against the
+// applicable lease count quota, if any, and returns an error wrapping
+// quotas.ErrLeaseCountQuotaExceeded if the quota does not allow it.
+func (m *ExpirationManager) applyLeaseCountQuota(ctx context.Context, le *leaseEntry) error {
+	if m.core.quotaManager == nil {
+		return nil
+	}
+
+	quotaResp, err := m.core.quotaManager.ApplyQuota(ctx, m.leaseQuotaRequest(le))
+	if err != nil {
+		return fmt.Errorf("failed to apply lease count quota: %w", err)
+	}
+	if !quotaResp.Allowed {
+		return fmt.Errorf("request path %q: %w", le.Path, quotas.ErrLeaseCountQuotaExceeded)
+	}
+
+	return nil
+}
+
+// updateLeaseCountQuotas informs the quota manager of a lease being created or
+// loaded.
+func (m *ExpirationManager) updateLeaseCountQuotas(action quotas.LeaseAction, le *leaseEntry) {
+	if m.core.quotaManager == nil {
+		return
+	}
+
+	if err := m.core.quotaManager.UpdateLeaseCounts(m.quitContext, action, m.leaseQuotaRequest(le)); err != nil {
+		m.logger.Error("failed to update lease count quotas", "lease_id", le.LeaseID, "action", action.String(), "error", err)
+	}
+}
+
+// releaseLeaseCountQuota removes a lease from the lease count quotas. It is
+// safe to call for leases which are not counted against any quota.
+func (m *ExpirationManager) releaseLeaseCountQuota(leaseID string) {
+	if m.core.quotaManager == nil {
+		return
+	}
+
+	if err := m.core.quotaManager.UpdateLeaseCounts(m.quitContext, quotas.LeaseActionDeleted, &quotas.Request{LeaseID: leaseID}); err != nil {
+		m.logger.Error("failed to update lease count quotas", "lease_id", leaseID, "action", quotas.LeaseActionDeleted.String(), "error", err)
+	}
+}
+
 // Marks a pending lease as irrevocable. Because the lease is being moved from
 // pending to irrevocable, no total lease count metrics/quotas updates are needed.
 // However, irrevocable lease count will need to be incremented
@@ -2692,6 +2813,11 @@ type leaseEntry struct {
 	// based on login roles upon lease expiry.
 	LoginRole string `json:"login_role"`
 
+	// EntityID is the identity entity of the token which created this lease,
+	// if any. It is required to attribute the lease to per-entity lease count
+	// quotas upon restore.
+	EntityID string `json:"entity_id"`
+
 	// Version is used to track new different versions of leases. V0 (or
 	// zero-value) had non-root namespaced secondary indexes live in the root
 	// namespace, and V1 has secondary indexes live in the matching namespace.
@@ -2741,6 +2867,70 @@ func (le *leaseEntry) renewable() (bool, error) {
 	return true, nil
 }
 
+// suggestedRenewAt returns the time at which the client holding this token
+// lease should renew it, or the zero time if the lease is not renewable or
+// cannot be extended past maxExpireTime. The hint falls between 60% and 80%
+// of the remaining lease; the exact point is derived from the lease ID so
+// that it is stable for a given token but spread across tokens.
+func (le *leaseEntry) suggestedRenewAt(maxExpireTime time.Time) time.Time {
+	if le.Auth == nil || !le.Auth.Renewable || le.ClientTokenType == logical.TokenTypeBatch {
+		return time.Time{}
+	}
+	if le.ExpireTime.IsZero() {
+		return time.Time{}
+	}
+	// Renewing would at best extend the lease by a fraction of a second
+	if !maxExpireTime.IsZero() && maxExpireTime.Sub(le.ExpireTime) < time.Second {
+		return time.Time{}
+	}
+
+	start := le.IssueTime
+	if !le.LastRenewalTime.IsZero() {
+		start = le.LastRenewalTime
+	}
+	remaining := le.ExpireTime.Sub(start)
+	if remaining <= 0 {
+		return time.Time{}
+	}
+
+	sum := sha256.Sum256([]byte(le.LeaseID))
+	jitter := float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+	fraction := suggestedRenewFraction + suggestedRenewJitter*jitter
+	return start.Add(time.Duration(float64(remaining) * fraction)).Truncate(time.Second)
+}
+
+// maxExpireTime returns the time past which the token of this lease cannot
+// be renewed, mirroring the limits applied by framework.CalculateTTL,
+// or the zero time if there is no such limit.
+func (le *leaseEntry) maxExpireTime(sysView logical.SystemView) time.Time {
+	if le.Auth == nil {
+		return time.Time{}
+	}
+
+	// Periodic tokens are only bounded by their explicit max TTL
+	if le.Auth.Period > 0 {
+		if le.Auth.ExplicitMaxTTL > 0 {
+			return le.IssueTime.Add(le.Auth.ExplicitMaxTTL)
+		}
+		return time.Time{}
+	}
+
+	var maxTTL time.Duration
+	if sysView != nil {
+		maxTTL = sysView.MaxLeaseTTL()
+	}
+	if le.Auth.MaxTTL > 0 && (maxTTL == 0 || le.Auth.MaxTTL < maxTTL) {
+		maxTTL = le.Auth.MaxTTL
+	}
+	if le.Auth.ExplicitMaxTTL > 0 && (maxTTL == 0 || le.Auth.ExplicitMaxTTL < maxTTL) {
+		maxTTL = le.Auth.ExplicitMaxTTL
+	}
+	if maxTTL == 0 {
+		return time.Time{}
+	}
+	return le.IssueTime.Add(maxTTL)
+}
+
 func (le *leaseEntry) ttl() int64 {
 	return int64(le.ExpireTime.Sub(time.Now().Round(time.Second)).Seconds())
 }
@@ -1648,6 +1648,111 @@ func TestExpiration_RenewToken(t *testing.T) {
 	}
 }
 
+func TestExpiration_SuggestedRenewAt(t *testing.T) {
+	exp := mockExpiration(t)
+	ctx := namespace.RootContext(nil)
+
+	register := func(auth *logical.Auth) *logical.TokenEntry {
+		t.Helper()
+		te := &logical.TokenEntry{
+			Path:         "auth/token/create",
+			NamespaceID:  namespace.RootNamespaceID,
+			TTL:          auth.TTL,
+			Policies:     []string{"default"},
+			CreationTime: time.Now().Unix(),
+		}
+		if err := exp.tokenStore.create(ctx, te); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		auth.ClientToken = te.ID
+		if err := exp.RegisterAuth(ctx, te, auth, ""); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return te
+	}
+
+	// Renewable tokens get a hint between 60% and 80% of their TTL
+	start := time.Now()
+	auth := &logical.Auth{
+		LeaseOptions: logical.LeaseOptions{
+			TTL:       time.Hour,
+			MaxTTL:    3 * time.Hour,
+			Renewable: true,
+		},
+	}
+	te := register(auth)
+	hint := auth.SuggestedRenewAt
+	if hint.Before(start.Add(36*time.Minute-time.Second)) || hint.After(time.Now().Add(48*time.Minute)) {
+		t.Fatalf("bad hint %v for token issued at %v", hint, start)
+	}
+
+	// The hint is stable across lookups
+	for i := 0; i < 2; i++ {
+		leaseTimes, err := exp.FetchLeaseTimesByToken(ctx, te)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !leaseTimes.Auth.SuggestedRenewAt.Equal(hint) {
+			t.Fatalf("expected hint %v, got %v", hint, leaseTimes.Auth.SuggestedRenewAt)
+		}
+	}
+
+	// Renewing the token computes a new hint
+	out, err := exp.RenewToken(ctx, &logical.Request{}, te, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.Auth.SuggestedRenewAt.After(hint) {
+		t.Fatalf("expected hint after %v, got %v", hint, out.Auth.SuggestedRenewAt)
+	}
+
+	// Tokens which cannot be renewed or extended get no hint
+	for name, opts := range map[string]logical.LeaseOptions{
+		"not renewable": {TTL: time.Hour, Renewable: false},
+		"at max ttl":    {TTL: time.Hour, MaxTTL: time.Hour, Renewable: true},
+	} {
+		auth := &logical.Auth{LeaseOptions: opts}
+		register(auth)
+		if !auth.SuggestedRenewAt.IsZero() {
+			t.Fatalf("%s: expected no hint, got %v", name, auth.SuggestedRenewAt)
+		}
+	}
+}
+
+func TestLeaseEntry_SuggestedRenewAt(t *testing.T) {
+	issueTime := time.Now().Truncate(time.Second)
+	le := &leaseEntry{
+		LeaseID:    "auth/token/login/foo",
+		Auth:       &logical.Auth{LeaseOptions: logical.LeaseOptions{Renewable: true}},
+		IssueTime:  issueTime,
+		ExpireTime: issueTime.Add(100 * time.Hour),
+	}
+
+	hint := le.suggestedRenewAt(time.Time{})
+	if hint.Before(issueTime.Add(60*time.Hour)) || hint.After(issueTime.Add(80*time.Hour)) {
+		t.Fatalf("bad hint: %v", hint.Sub(issueTime))
+	}
+	if !le.suggestedRenewAt(time.Time{}).Equal(hint) {
+		t.Fatal("expected the hint to be deterministic")
+	}
+
+	// Different tokens are spread over the renewal window
+	hints := make(map[time.Time]struct{})
+	for i := 0; i < 10; i++ {
+		le.LeaseID = fmt.Sprintf("auth/token/login/%d", i)
+		hints[le.suggestedRenewAt(time.Time{})] = struct{}{}
+	}
+	if len(hints) < 2 {
+		t.Fatal("expected the hints to be jittered")
+	}
+
+	// Batch tokens never get a hint
+	le.ClientTokenType = logical.TokenTypeBatch
+	if !le.suggestedRenewAt(time.Time{}).IsZero() {
+		t.Fatal("expected no hint for a batch token")
+	}
+}
+
 func TestExpiration_RenewToken_period(t *testing.T) {
 	exp := mockExpiration(t)
 	root := &logical.TokenEntry{
@@ -1956,6 +2061,81 @@ func TestExpiration_Renew_NotRenewable(t *testing.T) {
 	}
 }
 
+func TestExpiration_Renew_LeaseInvalidated(t *testing.T) {
+	exp := mockExpiration(t)
+	noop := &NoopBackend{
+		RequestHandler: func(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+			if req.Operation == logical.RenewOperation {
+				return nil, logical.ErrLeaseInvalidated
+			}
+			return nil, nil
+		},
+	}
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = exp.router.Mount(noop, "prod/aws/", &MountEntry{Path: "prod/aws/", Type: "noop", UUID: meUUID, Accessor: "noop-accessor", namespace: namespace.RootNamespace}, view)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "prod/aws/foo",
+		ClientToken: "foobar",
+	}
+	req.SetTokenEntry(&logical.TokenEntry{ID: "foobar", NamespaceID: "root"})
+	resp := &logical.Response{
+		Secret: &logical.Secret{
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       time.Hour,
+				Renewable: true,
+			},
+		},
+		Data: map[string]interface{}{
+			"access_key": "xyz",
+			"secret_key": "abcd",
+		},
+	}
+
+	id, err := exp.Register(namespace.RootContext(nil), req, resp, "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	_, err = exp.Renew(namespace.RootContext(nil), id, 0)
+	if !errors.Is(err, logical.ErrLeaseInvalidated) {
+		t.Fatalf("expected lease invalidated error, got: %v", err)
+	}
+
+	// The lease is revoked although its TTL has not elapsed
+	start := time.Now()
+	for {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("lease was not revoked")
+		}
+
+		req = nil
+		noop.Lock()
+		if len(noop.Requests) >= 2 {
+			req = noop.Requests[1]
+		}
+		noop.Unlock()
+
+		if req == nil {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		if req.Operation != logical.RevokeOperation {
+			t.Fatalf("Bad: %v", req)
+		}
+		break
+	}
+}
+
 func TestExpiration_Renew_RevokeOnExpire(t *testing.T) {
 	exp := mockExpiration(t)
 	noop := &NoopBackend{}
@@ -328,3 +328,116 @@ func TestLoginMfaGenerateTOTPTestAuditIncluded(t *testing.T) {
 		t.Fatalf("failed to destroy the MFA secret: %s", err)
 	}
 }
+
+func TestLoginMfaPolicyEnforcementAndBatchExemption(t *testing.T) {
+	cluster := vault.NewTestCluster(t, &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": userpass.Factory,
+		},
+	},
+		&vault.TestClusterOptions{
+			HandlerFunc: vaulthttp.Handler,
+		})
+
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	client := cluster.Cores[0].Client
+	mountAccessor := testhelpers.SetupUserpassMountAccessor(t, client)
+
+	userClient, _, _ := testhelpers.CreateEntityAndAlias(t, client, mountAccessor, "entity1", "testuser1")
+	_, entityID2, _ := testhelpers.CreateEntityAndAlias(t, client, mountAccessor, "entity2", "testuser2")
+
+	methodID := testhelpers.SetupTOTPMethod(t, client, map[string]interface{}{
+		"issuer":    "yCorp",
+		"period":    5,
+		"algorithm": "SHA1",
+		"digits":    6,
+		"skew":      1,
+		"key_size":  10,
+		"qr_size":   100,
+	})
+
+	// Only logins issuing tokens with the sensitive policy require MFA
+	testhelpers.SetupMFALoginEnforcement(t, client, map[string]interface{}{
+		"name":                "sensitive",
+		"mfa_method_ids":      []string{methodID},
+		"policies":            []string{"sensitive"},
+		"exempt_batch_tokens": true,
+	})
+
+	resp, err := client.Logical().Read("identity/mfa/login-enforcement/sensitive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["exempt_batch_tokens"] != true || fmt.Sprint(resp.Data["policies"]) != "[sensitive]" {
+		t.Fatalf("unexpected login enforcement: %#v", resp.Data)
+	}
+
+	login := func(username string) *api.Secret {
+		t.Helper()
+		secret, err := userClient.Logical().Write("auth/userpass/login/"+username, map[string]interface{}{
+			"password": "testpassword",
+		})
+		if err != nil {
+			t.Fatalf("failed to login as %s: %v", username, err)
+		}
+		if secret == nil || secret.Auth == nil {
+			t.Fatalf("login as %s returned no auth: %#v", username, secret)
+		}
+		return secret
+	}
+	requireMFA := func(username string, required bool) *api.Secret {
+		t.Helper()
+		secret := login(username)
+		if required && (secret.Auth.MFARequirement == nil || secret.Auth.ClientToken != "") {
+			t.Fatalf("expected login as %s to require MFA: %#v", username, secret.Auth)
+		}
+		if !required && (secret.Auth.MFARequirement != nil || secret.Auth.ClientToken == "") {
+			t.Fatalf("expected login as %s not to require MFA: %#v", username, secret.Auth)
+		}
+		return secret
+	}
+
+	requireMFA("testuser1", false)
+	requireMFA("testuser2", false)
+
+	// Policies attached by the auth method
+	_, err = client.Logical().Write("auth/userpass/users/testuser1", map[string]interface{}{
+		"token_policies": []string{"sensitive"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireMFA("testuser1", true)
+
+	// Policies derived from the entity
+	_, err = client.Logical().Write("identity/entity/id/"+entityID2, map[string]interface{}{
+		"policies": []string{"sensitive"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireMFA("testuser2", true)
+
+	// Batch tokens are exempt, and the exemption is reported
+	_, err = client.Logical().Write("auth/userpass/users/testuser1", map[string]interface{}{
+		"token_type": "batch",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := requireMFA("testuser1", false)
+	if !strings.Contains(strings.Join(secret.Warnings, ""), `login is exempt from MFA login enforcement "sensitive"`) {
+		t.Fatalf("expected a warning about the exemption, got: %v", secret.Warnings)
+	}
+
+	// Unless the enforcement does not allow it
+	testhelpers.SetupMFALoginEnforcement(t, client, map[string]interface{}{
+		"name":                "sensitive",
+		"mfa_method_ids":      []string{methodID},
+		"policies":            []string{"sensitive"},
+		"exempt_batch_tokens": false,
+	})
+	requireMFA("testuser1", true)
+}
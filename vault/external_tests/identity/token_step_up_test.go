@@ -0,0 +1,153 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	upAuth "github.com/openbao/openbao/api/auth/userpass/v2"
+	"github.com/openbao/openbao/builtin/credential/userpass"
+	"github.com/openbao/openbao/builtin/logical/totp"
+	"github.com/openbao/openbao/helper/testhelpers"
+	vaulthttp "github.com/openbao/openbao/http"
+	"github.com/openbao/openbao/sdk/v2/helper/strutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/openbao/openbao/vault"
+)
+
+func TestTokenStepUpTOTP(t *testing.T) {
+	cluster := vault.NewTestCluster(t, &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": userpass.Factory,
+		},
+		LogicalBackends: map[string]logical.Factory{
+			"totp": totp.Factory,
+		},
+	}, &vault.TestClusterOptions{
+		HandlerFunc: vaulthttp.Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	ctx := context.Background()
+	client := cluster.Cores[0].Client
+
+	testhelpers.SetupTOTPMount(t, client)
+	mountAccessor := testhelpers.SetupUserpassMountAccessor(t, client)
+	userClient, entityID, _ := testhelpers.CreateEntityAndAlias(t, client, mountAccessor, "entity1", "testuser1")
+
+	methodID := testhelpers.SetupTOTPMethod(t, client, map[string]interface{}{
+		"issuer":    "yCorp",
+		"period":    30,
+		"algorithm": "SHA1",
+		"digits":    6,
+		"skew":      0,
+		"key_size":  20,
+	})
+	enginePath := testhelpers.RegisterEntityInTOTPEngine(t, client, entityID, methodID)
+
+	// Step-up does not depend on login MFA, so log in without it
+	if _, err := client.Logical().DeleteWithContext(ctx, "identity/mfa/login-enforcement/"+methodID[0:4]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user's session lasts two minutes
+	if _, err := client.Logical().WriteWithContext(ctx, "auth/userpass/users/testuser1", map[string]interface{}{
+		"token_ttl":      "2m",
+		"token_policies": "step-up",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Sys().PutPolicyWithContext(ctx, "step-up", `path "auth/token/step-up/*" { capabilities = ["update"] }`); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Sys().PutPolicyWithContext(ctx, "elevated", `path "secret/*" { capabilities = ["delete"] }`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Logical().WriteWithContext(ctx, "auth/token/roles/sensitive", map[string]interface{}{
+		"step_up_mfa_method_id": methodID,
+		"step_up_policies":      "elevated",
+		"step_up_ttl":           "10m",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Logical().WriteWithContext(ctx, "auth/token/roles/plain", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only TOTP methods can be used for step-up
+	if _, err := client.Logical().WriteWithContext(ctx, "auth/token/roles/invalid", map[string]interface{}{
+		"step_up_mfa_method_id": "not-a-method",
+	}); err == nil {
+		t.Fatal("expected an unknown step-up method to be rejected")
+	}
+
+	upMethod, err := upAuth.NewUserpassAuth("testuser1", &upAuth.Password{FromString: "testpassword"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSecret, err := userClient.Auth().Login(ctx, upMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken := userSecret.Auth.ClientToken
+
+	passcode := testhelpers.GetTOTPCodeFromEngine(t, client, enginePath)
+	secret, err := userClient.Logical().WriteWithContext(ctx, "auth/token/step-up/sensitive", map[string]interface{}{
+		"passcode": passcode,
+	})
+	if err != nil {
+		t.Fatalf("step-up failed: %v", err)
+	}
+	if secret.Auth == nil || !strutil.StrListContains(secret.Auth.Policies, "elevated") {
+		t.Fatalf("expected the stepped up token to have the elevated policy, got: %#v", secret.Auth)
+	}
+	if secret.Auth.LeaseDuration > 120 {
+		t.Fatalf("expected the stepped up token not to outlive its parent, ttl: %d", secret.Auth.LeaseDuration)
+	}
+
+	// The stepped up token is a child of the session which cannot be renewed
+	// past it
+	lookup, err := client.Logical().WriteWithContext(ctx, "auth/token/lookup", map[string]interface{}{
+		"token": secret.Auth.ClientToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookup.Data["orphan"].(bool) || lookup.Data["entity_id"] != entityID {
+		t.Fatalf("unexpected stepped up token: %#v", lookup.Data)
+	}
+	explicitMaxTTL, err := lookup.Data["explicit_max_ttl"].(json.Number).Int64()
+	if err != nil || explicitMaxTTL == 0 || explicitMaxTTL > 120 {
+		t.Fatalf("expected explicit max TTL to be bound by the parent, got: %v", lookup.Data["explicit_max_ttl"])
+	}
+
+	// Passcodes cannot be replayed
+	_, err = userClient.Logical().WriteWithContext(ctx, "auth/token/step-up/sensitive", map[string]interface{}{
+		"passcode": passcode,
+	})
+	if err == nil || !strings.Contains(err.Error(), "code already used") {
+		t.Fatalf("expected a replayed passcode to be rejected, err: %v", err)
+	}
+
+	// Roles without a step-up method cannot be used
+	_, err = userClient.Logical().WriteWithContext(ctx, "auth/token/step-up/plain", map[string]interface{}{
+		"passcode": passcode,
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not allow step-up") {
+		t.Fatalf("expected step-up against a role without a method to fail, err: %v", err)
+	}
+
+	// Revoking the session revokes the stepped up token
+	userClient.SetToken(client.Token())
+	if err := userClient.Auth().Token().RevokeTree(userToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Auth().Token().Lookup(secret.Auth.ClientToken); err == nil {
+		t.Fatal("expected the stepped up token to be revoked with its parent")
+	}
+}
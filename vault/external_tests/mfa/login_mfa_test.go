@@ -663,7 +663,7 @@ func TestLoginMFA_LoginEnforcement_RequiredParameters(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected an error but didn't get one")
 	}
-	if !strings.Contains(err.Error(), "One of auth_method_accessors, auth_method_types, identity_group_ids, identity_entity_ids must be specified") {
+	if !strings.Contains(err.Error(), "One of auth_method_accessors, auth_method_types, identity_group_ids, identity_entity_ids, policies must be specified") {
 		t.Fatal("expected an error about required fields but didn't get one")
 	}
 }
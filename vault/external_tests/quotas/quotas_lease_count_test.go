@@ -0,0 +1,139 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package quotas
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+	"github.com/openbao/openbao/helper/testhelpers/teststorage"
+	"github.com/openbao/openbao/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotas_LeaseCountQuota_PerEntity(t *testing.T) {
+	conf, opts := teststorage.ClusterSetup(coreConfig, nil, nil)
+	opts.NoDefaultQuotas = true
+	cluster := vault.NewTestCluster(t, conf, opts)
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	core := cluster.Cores[0].Core
+	client := cluster.Cores[0].Client
+	vault.TestWaitActive(t, core)
+
+	setupMounts(t, client)
+
+	err := client.Sys().PutPolicy("pki-issue", `path "pki/issue/test" { capabilities = ["update"] }`)
+	require.NoError(t, err)
+	for username, password := range map[string]string{"foo": "bar", "baz": "qux"} {
+		_, err = client.Logical().Write("auth/userpass/users/"+username, map[string]interface{}{
+			"password":       password,
+			"token_policies": "pki-issue",
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = client.Logical().Write("sys/quotas/lease-count/pki-lcq", map[string]interface{}{
+		"path": "pki/",
+	})
+	require.Error(t, err, "max_leases is required")
+
+	_, err = client.Logical().Write("sys/quotas/lease-count/pki-lcq", map[string]interface{}{
+		"path":       "pki/",
+		"max_leases": 2,
+		"per_entity": true,
+	})
+	require.NoError(t, err)
+
+	login := func(username, password string) *api.Client {
+		t.Helper()
+		secret, err := client.Logical().Write("auth/userpass/login/"+username, map[string]interface{}{
+			"password": password,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, secret.Auth.EntityID)
+
+		userClient, err := client.Clone()
+		require.NoError(t, err)
+		userClient.SetToken(secret.Auth.ClientToken)
+		return userClient
+	}
+	foo := login("foo", "bar")
+	baz := login("baz", "qux")
+
+	issue := func(c *api.Client, ttl string) (string, error) {
+		t.Helper()
+		secret, err := c.Logical().Write("pki/issue/test", map[string]interface{}{
+			"common_name": "test.testvault.com",
+			"ttl":         ttl,
+		})
+		if err != nil {
+			return "", err
+		}
+		require.NotEmpty(t, secret.LeaseID)
+		return secret.LeaseID, nil
+	}
+	requireExceeded := func(c *api.Client) {
+		t.Helper()
+		_, err := issue(c, "1h")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "lease count quota exceeded")
+		require.Contains(t, err.Error(), "Code: 429")
+	}
+	counter := func() int64 {
+		t.Helper()
+		secret, err := client.Logical().Read("sys/quotas/lease-count/pki-lcq")
+		require.NoError(t, err)
+		require.Equal(t, "lease-count", secret.Data["type"])
+		require.Equal(t, true, secret.Data["per_entity"])
+		count, err := secret.Data["counter"].(json.Number).Int64()
+		require.NoError(t, err)
+		return count
+	}
+
+	fooLease, err := issue(foo, "1h")
+	require.NoError(t, err)
+	_, err = issue(foo, "1h")
+	require.NoError(t, err)
+	requireExceeded(foo)
+
+	// Other entities have a limit of their own.
+	_, err = issue(baz, "1h")
+	require.NoError(t, err)
+
+	// The root token has no entity and uses the shared bucket.
+	_, err = issue(client, "1h")
+	require.NoError(t, err)
+	_, err = issue(client, "2s")
+	require.NoError(t, err)
+	requireExceeded(client)
+	require.EqualValues(t, 5, counter())
+
+	// Revoking a lease frees room for its entity.
+	require.NoError(t, client.Sys().Revoke(fooLease))
+	require.EqualValues(t, 4, counter())
+	_, err = issue(foo, "1h")
+	require.NoError(t, err)
+	requireExceeded(foo)
+
+	// So does the expiry of a lease.
+	require.Eventually(t, func() bool {
+		return counter() == 4
+	}, 30*time.Second, 250*time.Millisecond)
+	_, err = issue(client, "1h")
+	require.NoError(t, err)
+
+	// Deleting the quota lifts the limit.
+	_, err = client.Logical().Delete("sys/quotas/lease-count/pki-lcq")
+	require.NoError(t, err)
+	_, err = issue(foo, "1h")
+	require.NoError(t, err)
+
+	s, err := client.Logical().List("sys/quotas/lease-count")
+	require.NoError(t, err)
+	require.Nil(t, s)
+}
@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
@@ -609,6 +610,7 @@ func (c *Core) waitForLeadership(newLeaderCh chan func(), manualStepDownCh, stop
 			c.localClusterParsedCert.Store((*x509.Certificate)(nil))
 			c.localClusterCert.Store(([]byte)(nil))
 			c.localClusterPrivateKey.Store((*ecdsa.PrivateKey)(nil))
+			c.localClusterPrevCert.Store((*tls.Certificate)(nil))
 
 			if err := c.setupCluster(activeCtx); err != nil {
 				c.heldHALock = nil
@@ -648,6 +650,10 @@ func (c *Core) waitForLeadership(newLeaderCh chan func(), manualStepDownCh, stop
 			continue
 		}
 
+		if c.clusterCertRotationInterval > 0 && c.ClusterAddr() != "" {
+			go c.periodicRotateClusterTLS(activeCtx, uuid)
+		}
+
 		// Monitor a loss of leadership
 		select {
 		case <-leaderLostCh:
@@ -904,6 +910,10 @@ func (c *Core) periodicCheckKeyUpgrades(ctx context.Context, stopCh chan struct{
 					c.logger.Error("key rotation periodic upgrade check failed", "error", err)
 				}
 
+				if err := c.checkClusterTLSUpgrades(ctx); err != nil {
+					c.logger.Error("cluster tls periodic upgrade check failed", "error", err)
+				}
+
 				if isRaft {
 					hasState, err := raftBackend.HasState()
 					if err != nil {
@@ -1082,6 +1092,23 @@ func (c *Core) advertiseLeader(ctx context.Context, uuid string, leaderLostCh <-
 		return fmt.Errorf("unknown cluster private key type %T", c.localClusterPrivateKey.Load())
 	}
 
+	if err := c.storeLeaderAdvertisement(ctx, uuid, key, c.localClusterCert.Load().([]byte)); err != nil {
+		return err
+	}
+
+	if c.serviceRegistration != nil {
+		if err := c.serviceRegistration.NotifyActiveStateChange(true); err != nil {
+			if c.logger.IsWarn() {
+				c.logger.Warn("failed to notify active status", "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// storeLeaderAdvertisement writes the advertisement standbys read to reach
+// the current node as leader, with the given local cluster key and cert.
+func (c *Core) storeLeaderAdvertisement(ctx context.Context, uuid string, key *ecdsa.PrivateKey, locCert []byte) error {
 	keyParams := &certutil.ClusterKeyParams{
 		Type: corePrivateKeyTypeP521,
 		X:    key.X,
@@ -1089,7 +1116,6 @@ func (c *Core) advertiseLeader(ctx context.Context, uuid string, leaderLostCh <-
 		D:    key.D,
 	}
 
-	locCert := c.localClusterCert.Load().([]byte)
 	localCert := make([]byte, len(locCert))
 	copy(localCert, locCert)
 	adv := &activeAdvertisement{
@@ -1106,19 +1132,7 @@ func (c *Core) advertiseLeader(ctx context.Context, uuid string, leaderLostCh <-
 		Key:   coreLeaderPrefix + uuid,
 		Value: val,
 	}
-	err = c.barrier.Put(ctx, ent)
-	if err != nil {
-		return err
-	}
-
-	if c.serviceRegistration != nil {
-		if err := c.serviceRegistration.NotifyActiveStateChange(true); err != nil {
-			if c.logger.IsWarn() {
-				c.logger.Warn("failed to notify active status", "error", err)
-			}
-		}
-	}
-	return nil
+	return c.barrier.Put(ctx, ent)
 }
 
 func (c *Core) cleanLeaderPrefix(ctx context.Context, uuid string, leaderLostCh <-chan struct{}) {
@@ -603,6 +603,14 @@ func mfaPaths(i *IdentityStore) []*framework.Path {
 					Type:        framework.TypeStringSlice,
 					Description: "Array of identity entity IDs",
 				},
+				"policies": {
+					Type:        framework.TypeStringSlice,
+					Description: "Array of policy names. Logins issuing a token with any of these policies, including policies derived from identity, are subject to the enforcement",
+				},
+				"exempt_batch_tokens": {
+					Type:        framework.TypeBool,
+					Description: "If set, logins issuing batch tokens are exempt from the enforcement. Exempted logins are reported in a warning of the login response",
+				},
 			},
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.ReadOperation: &framework.PathOperation{
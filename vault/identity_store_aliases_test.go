@@ -168,6 +168,117 @@ func TestIdentityStore_CaseInsensitiveEntityAliasName(t *testing.T) {
 	}
 }
 
+func TestIdentityStore_CaseInsensitiveAliasMount(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	i, accessor, c := testIdentityStoreWithAppRoleAuth(ctx, t)
+
+	// Operate on case sensitive names, as the identity store does once case
+	// variants of names were found in storage
+	i.disableLowerCasedNames = true
+	if err := i.resetDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	handle := func(b logical.Backend, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Path:      path,
+			Operation: op,
+			Data:      data,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: err: %v\nresp: %#v", err, resp)
+		}
+		return resp
+	}
+	createAlias := func(name string) (string, string) {
+		t.Helper()
+		resp := handle(i, logical.UpdateOperation, "entity-alias", map[string]interface{}{
+			"mount_accessor": accessor,
+			"name":           name,
+		})
+		return resp.Data["id"].(string), resp.Data["canonical_id"].(string)
+	}
+	entityID := func(name string) string {
+		t.Helper()
+		entity, err := i.entityByAliasFactors(accessor, name, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entity == nil {
+			return ""
+		}
+		return entity.ID
+	}
+
+	// Case variants of alias names belong to distinct entities by default
+	bobAliasID, bobEntityID := createAlias("Bob")
+	_, otherBobEntityID := createAlias("bob")
+	if bobEntityID == otherBobEntityID {
+		t.Fatalf("expected distinct entities")
+	}
+	if id := entityID("BOB"); id != "" {
+		t.Fatalf("expected no entity, got %q", id)
+	}
+
+	handle(c.systemBackend, logical.UpdateOperation, "auth/approle/tune", map[string]interface{}{
+		"alias_case_insensitive": true,
+	})
+	resp := handle(c.systemBackend, logical.ReadOperation, "auth/approle/tune", nil)
+	if resp.Data["alias_case_insensitive"] != true {
+		t.Fatalf("expected alias_case_insensitive to be set: %#v", resp.Data)
+	}
+
+	// Exact matches keep resolving to their own entities, other case variants
+	// resolve to one of them
+	if id := entityID("Bob"); id != bobEntityID {
+		t.Fatalf("bad entity: expected %q, actual %q", bobEntityID, id)
+	}
+	if id := entityID("bob"); id != otherBobEntityID {
+		t.Fatalf("bad entity: expected %q, actual %q", otherBobEntityID, id)
+	}
+	if id := entityID("BOB"); id != bobEntityID && id != otherBobEntityID {
+		t.Fatalf("expected a case variant to match, got %q", id)
+	}
+
+	// Creating and logging in with a case variant reuses the existing alias
+	aliceAliasID, aliceEntityID := createAlias("Alice")
+	if id, _ := createAlias("alice"); id != aliceAliasID {
+		t.Fatalf("bad alias: expected %q, actual %q", aliceAliasID, id)
+	}
+	entity, created, err := i.CreateOrFetchEntity(ctx, &logical.Alias{
+		MountType:     "approle",
+		MountAccessor: accessor,
+		Name:          "ALICE",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || entity.ID != aliceEntityID {
+		t.Fatalf("expected entity %q to be fetched, got %q (created: %t)", aliceEntityID, entity.ID, created)
+	}
+
+	// Duplicates which predate the option can be merged
+	handle(i, logical.UpdateOperation, "entity/merge", map[string]interface{}{
+		"from_entity_ids":               []string{otherBobEntityID},
+		"to_entity_id":                  bobEntityID,
+		"conflicting_alias_ids_to_keep": []string{bobAliasID},
+	})
+	for _, name := range []string{"Bob", "bob", "BOB"} {
+		if id := entityID(name); id != bobEntityID {
+			t.Fatalf("bad entity for %q: expected %q, actual %q", name, bobEntityID, id)
+		}
+	}
+
+	// Without the option, names must match exactly again
+	handle(c.systemBackend, logical.UpdateOperation, "auth/approle/tune", map[string]interface{}{
+		"alias_case_insensitive": false,
+	})
+	if id := entityID("ALICE"); id != "" {
+		t.Fatalf("expected no entity, got %q", id)
+	}
+}
+
 // This test is required because MemDB does not take care of ensuring
 // uniqueness of indexes that are marked unique.
 func TestIdentityStore_AliasSameAliasNames(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
@@ -227,6 +228,15 @@ func entityPaths(i *IdentityStore) []*framework.Path {
 					Type:        framework.TypeBool,
 					Description: "Setting this will follow the 'mine' strategy for merging MFA secrets. If there are secrets of the same type both in entities that are merged from and in entity into which all others are getting merged, secrets in the destination will be unaltered. If not set, this API will throw an error containing all the conflicts.",
 				},
+				"conflict_resolution": {
+					Type:          framework.TypeString,
+					Description:   "Strategy used to merge the metadata of the entities. One of 'prefer-newest' or 'prefer-oldest', which keep the metadata of all entities and resolve conflicting keys with the value of the most or least recently updated entity, or 'union-metadata', which keeps the metadata of all entities and resolves conflicting keys with the value of the entity merged into. If not set, only the metadata of the entity merged into is kept.",
+					AllowedValues: []interface{}{entityMergePreferNewest, entityMergePreferOldest, entityMergeUnionMetadata},
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "If set, the merge is not performed and the changes it would make are returned instead.",
+				},
 			},
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.UpdateOperation: &framework.PathOperation{
@@ -266,6 +276,15 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 			force = forceInterface.(bool)
 		}
 
+		metadataStrategy := d.Get("conflict_resolution").(string)
+		switch metadataStrategy {
+		case "", entityMergePreferNewest, entityMergePreferOldest, entityMergeUnionMetadata:
+		default:
+			return logical.ErrorResponse("unknown conflict resolution strategy %q", metadataStrategy), nil
+		}
+
+		dryRun := d.Get("dry_run").(bool)
+
 		// Create a MemDB transaction to merge entities
 		i.lock.Lock()
 		defer i.lock.Unlock()
@@ -278,7 +297,19 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 			return nil, err
 		}
 
-		userErr, intErr, aliases := i.mergeEntity(ctx, txn, toEntity, fromEntityIDs, conflictingAliasIDsToKeep, force, false, false, true, false)
+		report := &entityMergeReport{}
+		userErr, intErr, aliases := i.mergeEntity(ctx, txn, toEntity, fromEntityIDs, conflictingAliasIDsToKeep, force, false, false, !dryRun, false, metadataStrategy, report)
+		if userErr != nil && dryRun && len(aliases) > 0 {
+			// Report the alias clashes which would have to be resolved
+			// for the merge to succeed.
+			return &logical.Response{
+				Data: map[string]interface{}{
+					"dry_run":         true,
+					"alias_conflicts": aliases,
+				},
+				Warnings: []string{userErr.Error()},
+			}, nil
+		}
 		if userErr != nil {
 			// Not an error due to alias clash, return like normal
 			if len(aliases) == 0 {
@@ -298,6 +329,11 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 			return nil, intErr
 		}
 
+		if dryRun {
+			// The transaction is aborted, leaving the entities as they are.
+			return i.entityMergeDryRunResponse(txn, toEntity, report)
+		}
+
 		// Committing the transaction *after* successfully performing storage
 		// persistence
 		txn.Commit()
@@ -306,6 +342,46 @@ func (i *IdentityStore) pathEntityMergeID() framework.OperationFunc {
 	}
 }
 
+// entityMergeDryRunResponse describes the state of the entity merged into,
+// as found in the transaction of a merge which is not committed.
+func (i *IdentityStore) entityMergeDryRunResponse(txn *memdb.Txn, toEntity *identity.Entity, report *entityMergeReport) (*logical.Response, error) {
+	aliasIDs := make([]string, 0, len(toEntity.Aliases))
+	for _, alias := range toEntity.Aliases {
+		aliasIDs = append(aliasIDs, alias.ID)
+	}
+
+	groups, err := i.MemDBGroupsByMemberEntityIDInTxn(txn, toEntity.ID, false, false)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	deletedAliasIDs := report.DeletedAliasIDs
+	if deletedAliasIDs == nil {
+		deletedAliasIDs = []string{}
+	}
+	conflicts := report.MetadataConflicts
+	if conflicts == nil {
+		conflicts = []entityMetadataConflict{}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"dry_run":            true,
+			"id":                 toEntity.ID,
+			"metadata":           toEntity.Metadata,
+			"metadata_conflicts": conflicts,
+			"aliases":            aliasIDs,
+			"deleted_alias_ids":  deletedAliasIDs,
+			"group_ids":          groupIDs,
+			"merged_entity_ids":  toEntity.MergedEntityIDs,
+		},
+	}, nil
+}
+
 // handleEntityUpdateCommon is used to update an entity
 func (i *IdentityStore) handleEntityUpdateCommon() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -834,7 +910,7 @@ func (i *IdentityStore) handlePathEntityListCommon(ctx context.Context, req *log
 }
 
 func (i *IdentityStore) mergeEntityAsPartOfUpsert(ctx context.Context, txn *memdb.Txn, toEntity *identity.Entity, fromEntityID string, persist bool) (error, error) {
-	err1, err2, _ := i.mergeEntity(ctx, txn, toEntity, []string{fromEntityID}, []string{}, true, false, true, persist, true)
+	err1, err2, _ := i.mergeEntity(ctx, txn, toEntity, []string{fromEntityID}, []string{}, true, false, true, persist, true, "", nil)
 	return err1, err2
 }
 
@@ -848,7 +924,100 @@ type aliasClashInformation struct {
 	MountPath string `json:"mount_path"`
 }
 
-func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntity *identity.Entity, fromEntityIDs, conflictingAliasIDsToKeep []string, force, grabLock, mergePolicies, persist, forceMergeAliases bool) (error, error, []aliasClashInformation) {
+// The strategies used to merge the metadata of entities. Without a strategy,
+// only the metadata of the entity merged into is kept.
+const (
+	entityMergePreferNewest  = "prefer-newest"
+	entityMergePreferOldest  = "prefer-oldest"
+	entityMergeUnionMetadata = "union-metadata"
+)
+
+// entityMetadataConflict describes a metadata key which is set to different
+// values by the merged entities, and how it was resolved.
+type entityMetadataConflict struct {
+	Key      string            `json:"key"`
+	Value    string            `json:"value"`
+	EntityID string            `json:"entity_id"`
+	Values   map[string]string `json:"values"`
+}
+
+// entityMergeReport collects the changes made by an entity merge which are
+// not visible on the resulting entity.
+type entityMergeReport struct {
+	MetadataConflicts []entityMetadataConflict
+	DeletedAliasIDs   []string
+}
+
+func (r *entityMergeReport) deletedAlias(id string) {
+	if r != nil {
+		r.DeletedAliasIDs = append(r.DeletedAliasIDs, id)
+	}
+}
+
+// mergeEntityMetadata merges the metadata of the given entities into the
+// metadata of toEntity according to the strategy, returning the conflicting
+// keys and how they were resolved.
+func mergeEntityMetadata(toEntity *identity.Entity, fromEntities []*identity.Entity, strategy string) []entityMetadataConflict {
+	if strategy == "" {
+		return nil
+	}
+
+	// Order the entities by precedence; the first entity setting a key
+	// provides its value.
+	entities := append([]*identity.Entity{toEntity}, fromEntities...)
+	switch strategy {
+	case entityMergePreferNewest:
+		sort.SliceStable(entities, func(a, b int) bool {
+			return entities[a].LastUpdateTime.AsTime().After(entities[b].LastUpdateTime.AsTime())
+		})
+	case entityMergePreferOldest:
+		sort.SliceStable(entities, func(a, b int) bool {
+			return entities[a].LastUpdateTime.AsTime().Before(entities[b].LastUpdateTime.AsTime())
+		})
+	}
+
+	metadata := make(map[string]string)
+	source := make(map[string]string)
+	values := make(map[string]map[string]string)
+	for _, entity := range entities {
+		for key, value := range entity.Metadata {
+			if values[key] == nil {
+				values[key] = make(map[string]string)
+			}
+			values[key][entity.ID] = value
+
+			if _, ok := metadata[key]; !ok {
+				metadata[key] = value
+				source[key] = entity.ID
+			}
+		}
+	}
+
+	var conflicts []entityMetadataConflict
+	for key, entityValues := range values {
+		for _, value := range entityValues {
+			if value != metadata[key] {
+				conflicts = append(conflicts, entityMetadataConflict{
+					Key:      key,
+					Value:    metadata[key],
+					EntityID: source[key],
+					Values:   entityValues,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(conflicts, func(a, b int) bool {
+		return conflicts[a].Key < conflicts[b].Key
+	})
+
+	if len(metadata) > 0 {
+		toEntity.Metadata = metadata
+	}
+	return conflicts
+}
+
+func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntity *identity.Entity, fromEntityIDs, conflictingAliasIDsToKeep []string, force, grabLock, mergePolicies, persist, forceMergeAliases bool, metadataStrategy string, report *entityMergeReport) (error, error, []aliasClashInformation) {
 	if grabLock {
 		i.lock.Lock()
 		defer i.lock.Unlock()
@@ -976,7 +1145,11 @@ func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntit
 		return aliasClashError, nil, aliasesInvolvedInClashes
 	}
 
-	var fromEntityGroups []*identity.Group
+	// The groups the merged entities were members of, each group being
+	// updated once all the entities were removed from it.
+	var fromEntityGroupIDs []string
+	fromEntityGroups := make(map[string]*identity.Group)
+	var fromEntities []*identity.Entity
 
 	toEntityAccessors := make(map[string][]string)
 
@@ -1010,6 +1183,7 @@ func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntit
 			return errors.New("entity id to merge from does not belong to this namespace"), nil, nil
 		}
 
+	fromAliases:
 		for _, fromAlias := range fromEntity.Aliases {
 			// If true, we need to handle conflicts (conflict = both aliases share the same mount accessor)
 			if toAliasIds, ok := toEntityAccessors[fromAlias.MountAccessor]; ok {
@@ -1019,26 +1193,29 @@ func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntit
 					// This case's code is the same as when the user selects to keep the from_entity alias
 					// but is kept separate for clarity
 					if forceMergeAliases {
-						i.logger.Info("Deleting to_entity alias during entity merge", "to_entity", toEntity.ID, "deleted_alias", toAliasId)
+						i.logger.Warn("Deleting to_entity alias clashing with a from_entity alias during entity merge", "to_entity", toEntity.ID, "from_entity", fromEntityID, "deleted_alias", toAliasId, "kept_alias", fromAlias.ID, "mount_accessor", fromAlias.MountAccessor)
 						err := i.MemDBDeleteAliasByIDInTxn(txn, toAliasId, false)
 						if err != nil {
 							return nil, fmt.Errorf("failed to delete orphaned alias during merge: %w", err), nil
 						}
+						report.deletedAlias(toAliasId)
 					} else if strutil.StrListContains(conflictingAliasIDsToKeep, toAliasId) {
 						i.logger.Info("Deleting from_entity alias during entity merge", "from_entity", fromEntityID, "deleted_alias", fromAlias.ID)
 						err := i.MemDBDeleteAliasByIDInTxn(txn, fromAlias.ID, false)
 						if err != nil {
 							return nil, fmt.Errorf("failed to delete orphaned alias during merge: %w", err), nil
 						}
+						report.deletedAlias(fromAlias.ID)
 
 						// Continue to next alias, as there's no alias to merge left in the from_entity
-						continue
+						continue fromAliases
 					} else if strutil.StrListContains(conflictingAliasIDsToKeep, fromAlias.ID) {
 						i.logger.Info("Deleting to_entity alias during entity merge", "to_entity", toEntity.ID, "deleted_alias", toAliasId)
 						err := i.MemDBDeleteAliasByIDInTxn(txn, toAliasId, false)
 						if err != nil {
 							return nil, fmt.Errorf("failed to delete orphaned alias during merge: %w", err), nil
 						}
+						report.deletedAlias(toAliasId)
 					} else {
 						return fmt.Errorf("conflicting mount accessors in following alias IDs and neither were present in conflicting_alias_ids_to_keep: %s, %s", fromAlias.ID, toAliasId), nil, nil
 					}
@@ -1086,9 +1263,14 @@ func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntit
 				return nil, err, nil
 			}
 
-			fromEntityGroups = append(fromEntityGroups, group)
+			if _, ok := fromEntityGroups[group.ID]; !ok {
+				fromEntityGroupIDs = append(fromEntityGroupIDs, group.ID)
+			}
+			fromEntityGroups[group.ID] = group
 		}
 
+		fromEntities = append(fromEntities, fromEntity)
+
 		// Delete the entity which we are merging from in MemDB using the same transaction
 		err = i.MemDBDeleteEntityByIDInTxn(txn, fromEntity.ID)
 		if err != nil {
@@ -1104,13 +1286,24 @@ func (i *IdentityStore) mergeEntity(ctx context.Context, txn *memdb.Txn, toEntit
 		}
 	}
 
+	conflicts := mergeEntityMetadata(toEntity, fromEntities, metadataStrategy)
+	if report != nil {
+		report.MetadataConflicts = conflicts
+	}
+
 	// Update MemDB with changes to the entity we are merging to
 	err = i.MemDBUpsertEntityInTxn(txn, toEntity)
 	if err != nil {
 		return nil, err, nil
 	}
 
-	for _, group := range fromEntityGroups {
+	// Add the entity we are merging to to the groups of the merged entities,
+	// unless it already is a member of them.
+	for _, groupID := range fromEntityGroupIDs {
+		group := fromEntityGroups[groupID]
+		if strutil.StrListContains(group.MemberEntityIDs, toEntity.ID) {
+			continue
+		}
 		group.MemberEntityIDs = append(group.MemberEntityIDs, toEntity.ID)
 		err = i.UpsertGroupInTxn(ctx, txn, group, persist)
 		if err != nil {
@@ -1161,7 +1354,11 @@ var entityHelp = map[string][2]string{
 	},
 	"entity-merge-id": {
 		"Merge two or more entities together",
-		"",
+		`The entities in from_entity_ids are merged into to_entity_id. Their
+metadata is discarded, unless conflict_resolution selects how metadata keys
+set on several entities are resolved. With dry_run set, the merge is not
+performed and the resulting entity is returned instead, along with the
+metadata conflicts and alias clashes found.`,
 	},
 	"batch-delete": {
 		"Delete all of the entities provided",
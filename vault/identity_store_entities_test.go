@@ -17,6 +17,7 @@ import (
 	"github.com/openbao/openbao/helper/namespace"
 	"github.com/openbao/openbao/sdk/v2/helper/strutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIdentityStore_EntityDeleteGroupMembershipUpdate(t *testing.T) {
@@ -1313,3 +1314,148 @@ func TestIdentityStore_MergeEntitiesByID_DuplicateFromEntityIDs(t *testing.T) {
 		t.Fatalf("invalid number of entity policies; expected: 2, actualL: %d", len(entity1Lookup.Policies))
 	}
 }
+
+func TestIdentityStore_MergeEntities_ConflictResolution(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+	is, approleAccessor, upAccessor, _ := testIdentityStoreWithAppRoleUserpassAuth(ctx, t)
+
+	write := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		require.False(t, resp != nil && resp.IsError(), "resp: %#v", resp)
+		return resp
+	}
+	createEntity := func(name string, metadata ...string) string {
+		t.Helper()
+		return write("entity", map[string]interface{}{
+			"name":     name,
+			"metadata": metadata,
+		}).Data["id"].(string)
+	}
+	merge := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := is.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "entity/merge",
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	older := createEntity("older", "team=a", "shared=x", "only_older=1")
+	newer := createEntity("newer", "team=b", "shared=x", "only_newer=2")
+	groupID := write("group", map[string]interface{}{
+		"member_entity_ids": []string{older, newer},
+	}).Data["id"].(string)
+
+	resp := merge(map[string]interface{}{
+		"to_entity_id":        older,
+		"from_entity_ids":     newer,
+		"conflict_resolution": "prefer-longest",
+	})
+	require.True(t, resp.IsError())
+
+	for strategy, team := range map[string]string{
+		entityMergePreferNewest:  "b",
+		entityMergePreferOldest:  "a",
+		entityMergeUnionMetadata: "a",
+	} {
+		resp := merge(map[string]interface{}{
+			"to_entity_id":        older,
+			"from_entity_ids":     newer,
+			"conflict_resolution": strategy,
+			"dry_run":             true,
+		})
+		require.False(t, resp.IsError(), "resp: %#v", resp)
+		require.Equal(t, true, resp.Data["dry_run"])
+		require.Equal(t, map[string]string{
+			"team":       team,
+			"shared":     "x",
+			"only_older": "1",
+			"only_newer": "2",
+		}, resp.Data["metadata"], "strategy: %s", strategy)
+
+		conflicts := resp.Data["metadata_conflicts"].([]entityMetadataConflict)
+		require.Len(t, conflicts, 1)
+		require.Equal(t, "team", conflicts[0].Key)
+		require.Equal(t, team, conflicts[0].Value)
+		require.Equal(t, map[string]string{older: "a", newer: "b"}, conflicts[0].Values)
+		require.Equal(t, []string{groupID}, resp.Data["group_ids"])
+		require.Equal(t, []string{newer}, resp.Data["merged_entity_ids"])
+	}
+
+	// Nothing changed during the dry runs.
+	entity, err := is.MemDBEntityByID(newer, false)
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	entity, err = is.MemDBEntityByID(older, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "a", "shared": "x", "only_older": "1"}, entity.Metadata)
+	group, err := is.MemDBGroupByID(groupID, false)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{older, newer}, group.MemberEntityIDs)
+
+	resp = merge(map[string]interface{}{
+		"to_entity_id":        older,
+		"from_entity_ids":     newer,
+		"conflict_resolution": entityMergeUnionMetadata,
+	})
+	require.Nil(t, resp)
+
+	entity, err = is.MemDBEntityByID(older, false)
+	require.NoError(t, err)
+	require.Equal(t, "2", entity.Metadata["only_newer"])
+	entity, err = is.MemDBEntityByID(newer, false)
+	require.NoError(t, err)
+	require.Nil(t, entity)
+
+	// Group memberships are unioned without duplicates.
+	group, err = is.MemDBGroupByID(groupID, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{older}, group.MemberEntityIDs)
+
+	// Clashing aliases are reported by dry runs.
+	first := createEntity("first")
+	second := createEntity("second")
+	for entityID, accessor := range map[string]string{first: approleAccessor, second: approleAccessor} {
+		write("entity-alias", map[string]interface{}{
+			"name":           "alias-" + entityID,
+			"canonical_id":   entityID,
+			"mount_accessor": accessor,
+		})
+	}
+	write("entity-alias", map[string]interface{}{
+		"name":           "userpass-alias",
+		"canonical_id":   second,
+		"mount_accessor": upAccessor,
+	})
+
+	resp = merge(map[string]interface{}{
+		"to_entity_id":    first,
+		"from_entity_ids": second,
+		"dry_run":         true,
+	})
+	require.False(t, resp.IsError())
+	require.Len(t, resp.Data["alias_conflicts"], 2)
+	require.NotEmpty(t, resp.Warnings)
+
+	entity, err = is.MemDBEntityByID(first, false)
+	require.NoError(t, err)
+	toAliasID := entity.Aliases[0].ID
+	resp = merge(map[string]interface{}{
+		"to_entity_id":                  first,
+		"from_entity_ids":               second,
+		"conflicting_alias_ids_to_keep": toAliasID,
+		"dry_run":                       true,
+	})
+	require.False(t, resp.IsError(), "resp: %#v", resp)
+	require.Len(t, resp.Data["deleted_alias_ids"], 1)
+	require.Len(t, resp.Data["aliases"], 2)
+	require.Contains(t, resp.Data["aliases"], toAliasID)
+}
@@ -78,6 +78,17 @@ func (c *Core) loadIdentityStoreArtifacts(ctx context.Context) error {
 	return loadFunc(ctx)
 }
 
+// aliasNamesCaseInsensitive returns true if the aliases of the mount with the
+// given accessor are matched case-insensitively even though the identity store
+// operates on case sensitive names.
+func (i *IdentityStore) aliasNamesCaseInsensitive(mountAccessor string) bool {
+	if !i.disableLowerCasedNames || i.router == nil {
+		return false
+	}
+	mountEntry := i.router.MatchingMountByAccessor(mountAccessor)
+	return mountEntry != nil && mountEntry.Config.AliasCaseInsensitive
+}
+
 func (i *IdentityStore) sanitizeName(name string) string {
 	if i.disableLowerCasedNames {
 		return name
@@ -408,8 +419,11 @@ func (i *IdentityStore) upsertEntityInTxn(ctx context.Context, txn *memdb.Txn, e
 	aliasFactors := make([]string, len(entity.Aliases))
 
 	for index, alias := range entity.Aliases {
-		// Verify that alias is not associated to a different one already
-		aliasByFactors, err := i.MemDBAliasByFactors(alias.MountAccessor, alias.Name, false, false)
+		// Verify that alias is not associated to a different one already.
+		// Aliases only differing in case are not merged here, even if their
+		// mount treats alias names case-insensitively; they have to be
+		// merged explicitly.
+		aliasByFactors, err := i.memDBAliasByExactFactors(alias.MountAccessor, alias.Name)
 		if err != nil {
 			return err
 		}
@@ -779,6 +793,39 @@ func (i *IdentityStore) MemDBAliasByFactors(mountAccessor, aliasName string, clo
 	return i.MemDBAliasByFactorsInTxn(txn, mountAccessor, aliasName, clone, groupAlias)
 }
 
+// memDBAliasByExactFactors fetches the entity alias with the given mount
+// accessor and name, regardless of the case sensitivity of the mount.
+func (i *IdentityStore) memDBAliasByExactFactors(mountAccessor, aliasName string) (*identity.Alias, error) {
+	if aliasName == "" {
+		return nil, fmt.Errorf("missing alias name")
+	}
+
+	if mountAccessor == "" {
+		return nil, fmt.Errorf("missing mount accessor")
+	}
+
+	txn := i.db.Txn(false)
+
+	aliasRaw, err := txn.First(entityAliasesTable, "factors", mountAccessor, aliasName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch alias from memdb using factors: %w", err)
+	}
+
+	if aliasRaw == nil {
+		return nil, nil
+	}
+
+	alias, ok := aliasRaw.(*identity.Alias)
+	if !ok {
+		return nil, fmt.Errorf("failed to declare the type of fetched alias")
+	}
+
+	return alias, nil
+}
+
+// MemDBAliasByFactorsInTxn fetches the alias with the given mount accessor and
+// name. If the mount treats alias names case-insensitively, an alias whose
+// name only differs in case is returned when none matches exactly.
 func (i *IdentityStore) MemDBAliasByFactorsInTxn(txn *memdb.Txn, mountAccessor, aliasName string, clone bool, groupAlias bool) (*identity.Alias, error) {
 	if txn == nil {
 		return nil, fmt.Errorf("nil txn")
@@ -802,6 +849,16 @@ func (i *IdentityStore) MemDBAliasByFactorsInTxn(txn *memdb.Txn, mountAccessor,
 		return nil, fmt.Errorf("failed to fetch alias from memdb using factors: %w", err)
 	}
 
+	// An alias with the exact name is preferred, so that the entities of
+	// case-variant aliases created before the mount started treating alias
+	// names case-insensitively keep being used until they are merged.
+	if aliasRaw == nil && i.aliasNamesCaseInsensitive(mountAccessor) {
+		aliasRaw, err = txn.First(tableName, "factors_case_insensitive", mountAccessor, aliasName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch alias from memdb using factors: %w", err)
+		}
+	}
+
 	if aliasRaw == nil {
 		return nil, nil
 	}
@@ -139,6 +139,9 @@ func (c *Core) generateShares(sc *SealConfig) ([]byte, [][]byte, error) {
 		unsealKeys = shares
 	}
 
+	// Bind the share credentials before the shares get encrypted
+	sc.bindShareCredentials(unsealKeys)
+
 	// If we have PGP keys, perform the encryption
 	if len(sc.PGPKeys) > 0 {
 		hexEncodedShares := make([][]byte, len(unsealKeys))
@@ -175,6 +178,9 @@ func (c *Core) Initialize(ctx context.Context, initParams *InitParams) (*InitRes
 		if len(barrierConfig.PGPKeys) > 0 {
 			return nil, fmt.Errorf("PGP keys not supported when storing shares")
 		}
+		if len(barrierConfig.ShareCredentials) > 0 {
+			return nil, fmt.Errorf("share credentials not supported when storing shares")
+		}
 		barrierConfig.SecretShares = 1
 		barrierConfig.SecretThreshold = 1
 		if barrierConfig.StoredShares != 1 {
@@ -196,6 +202,9 @@ func (c *Core) Initialize(ctx context.Context, initParams *InitParams) (*InitRes
 		if len(recoveryConfig.PGPKeys) > 0 && len(recoveryConfig.PGPKeys) != recoveryConfig.SecretShares {
 			return nil, fmt.Errorf("incorrect number of PGP keys for recovery")
 		}
+		if len(recoveryConfig.ShareCredentials) > 0 {
+			return nil, fmt.Errorf("share credentials not supported for recovery keys")
+		}
 	}
 
 	if c.seal.RecoveryKeySupported() {
@@ -267,6 +276,8 @@ func (c *Core) Initialize(ctx context.Context, initParams *InitParams) (*InitRes
 	var sealKeyShares [][]byte
 
 	if barrierConfig.StoredShares == 1 && c.seal.BarrierType() == wrapping.WrapperTypeShamir {
+		// This binds the share credentials to the seal key shares, which are
+		// the ones handed out.
 		sealKey, sealKeyShares, err = c.generateShares(barrierConfig)
 		if err != nil {
 			c.logger.Error("error generating shares", "error", err)
@@ -42,6 +42,11 @@ type Keyring struct {
 	keys           map[uint32]*Key
 	activeTerm     uint32
 	rotationConfig KeyRotationConfig
+
+	// mountKeys holds the secrets dedicated to mounts, keyed by the storage
+	// prefix of the mount. Data under such a prefix is encrypted with a key
+	// derived from both the term key and the mount secret.
+	mountKeys map[string][]byte
 }
 
 // EncodedKeyring is used for serialization of the keyring
@@ -49,6 +54,7 @@ type EncodedKeyring struct {
 	MasterKey      []byte
 	Keys           []*Key
 	RotationConfig KeyRotationConfig
+	MountKeys      map[string][]byte `json:",omitempty"`
 }
 
 // Key represents a single term, along with the key used.
@@ -86,6 +92,7 @@ func NewKeyring() *Keyring {
 		keys:           make(map[uint32]*Key),
 		activeTerm:     0,
 		rotationConfig: defaultRotationConfig,
+		mountKeys:      make(map[string][]byte),
 	}
 	return k
 }
@@ -97,10 +104,14 @@ func (k *Keyring) Clone() *Keyring {
 		keys:           make(map[uint32]*Key, len(k.keys)),
 		activeTerm:     k.activeTerm,
 		rotationConfig: k.rotationConfig,
+		mountKeys:      make(map[string][]byte, len(k.mountKeys)),
 	}
 	for idx, key := range k.keys {
 		clone.keys[idx] = key
 	}
+	for prefix, key := range k.mountKeys {
+		clone.mountKeys[prefix] = key
+	}
 	return clone
 }
 
@@ -173,6 +184,39 @@ func (k *Keyring) TermKey(term uint32) *Key {
 	return k.keys[term]
 }
 
+// AddMountKey adds the secret dedicated to the mount stored under the given
+// prefix
+func (k *Keyring) AddMountKey(prefix string, value []byte) (*Keyring, error) {
+	if exist, ok := k.mountKeys[prefix]; ok {
+		if !bytes.Equal(value, exist) {
+			return nil, fmt.Errorf("conflicting key for mount %q already installed", prefix)
+		}
+		return k, nil
+	}
+
+	clone := k.Clone()
+	clone.mountKeys[prefix] = value
+	return clone, nil
+}
+
+// RemoveMountKey removes the secret dedicated to the mount stored under the
+// given prefix
+func (k *Keyring) RemoveMountKey(prefix string) *Keyring {
+	if _, ok := k.mountKeys[prefix]; !ok {
+		return k
+	}
+
+	clone := k.Clone()
+	delete(clone.mountKeys, prefix)
+	return clone
+}
+
+// MountKey returns the secret dedicated to the mount stored under the given
+// prefix, or nil
+func (k *Keyring) MountKey(prefix string) []byte {
+	return k.mountKeys[prefix]
+}
+
 // SetRootKey is used to update the root key
 func (k *Keyring) SetRootKey(val []byte) *Keyring {
 	valCopy := make([]byte, len(val))
@@ -193,6 +237,7 @@ func (k *Keyring) Serialize() ([]byte, error) {
 	enc := EncodedKeyring{
 		MasterKey:      k.rootKey,
 		RotationConfig: k.rotationConfig,
+		MountKeys:      k.mountKeys,
 	}
 	for _, key := range k.keys {
 		enc.Keys = append(enc.Keys, key)
@@ -222,6 +267,9 @@ func DeserializeKeyring(buf []byte) (*Keyring, error) {
 			k.activeTerm = key.Term
 		}
 	}
+	for prefix, key := range enc.MountKeys {
+		k.mountKeys[prefix] = key
+	}
 	return k, nil
 }
 
@@ -241,6 +289,9 @@ func (k *Keyring) Zeroize(keysToo bool) {
 	for _, key := range k.keys {
 		memzero(key.Value)
 	}
+	for _, key := range k.mountKeys {
+		memzero(key)
+	}
 }
 
 func (c KeyRotationConfig) Clone() KeyRotationConfig {
@@ -181,6 +181,50 @@ func TestKeyring_Serialize(t *testing.T) {
 	}
 }
 
+func TestKeyring_MountKeys(t *testing.T) {
+	k := NewKeyring()
+	mountKey := []byte("mount")
+
+	k1, err := k.AddMountKey("logical/foo/", mountKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if k.MountKey("logical/foo/") != nil {
+		t.Fatalf("original keyring modified")
+	}
+	if !bytes.Equal(k1.MountKey("logical/foo/"), mountKey) {
+		t.Fatalf("bad: %v", k1.MountKey("logical/foo/"))
+	}
+
+	// Adding the same key is idempotent, a different one is rejected
+	if _, err := k1.AddMountKey("logical/foo/", mountKey); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := k1.AddMountKey("logical/foo/", []byte("other")); err == nil {
+		t.Fatalf("expected conflict")
+	}
+
+	buf, err := k1.Serialize()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	k2, err := DeserializeKeyring(buf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(k2.MountKey("logical/foo/"), mountKey) {
+		t.Fatalf("bad: %v", k2.MountKey("logical/foo/"))
+	}
+
+	k3 := k2.RemoveMountKey("logical/foo/")
+	if k3.MountKey("logical/foo/") != nil {
+		t.Fatalf("mount key not removed")
+	}
+	if k2.MountKey("logical/foo/") == nil {
+		t.Fatalf("original keyring modified")
+	}
+}
+
 func TestKey_Serialize(t *testing.T) {
 	k := &Key{
 		Term:        10,
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	log "github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/mapstructure"
 	"github.com/openbao/openbao/sdk/v2/framework"
 	"github.com/openbao/openbao/sdk/v2/helper/compressutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
@@ -25,6 +26,16 @@ var protectedPaths = []string{
 	coreLocalClusterInfoPath,
 }
 
+// rawBatchMaxOperations is the maximum number of operations accepted by a
+// single request to the raw batch endpoint.
+const rawBatchMaxOperations = 256
+
+// barrierBatchGetter is implemented by barriers which can fetch several
+// entries at once.
+type barrierBatchGetter interface {
+	BatchGet(ctx context.Context, keys []string) ([]*logical.StorageEntry, error)
+}
+
 type RawBackend struct {
 	*framework.Backend
 	barrier      SecurityBarrier
@@ -83,15 +94,32 @@ func (b *RawBackend) handleRawRead(ctx context.Context, req *logical.Request, da
 		return nil, nil
 	}
 
+	value, err := rawOutputValue(entry, compressed, encoding)
+	if err != nil {
+		return handleErrorNoReadOnlyForward(err)
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"value": value,
+		},
+	}
+	return resp, nil
+}
+
+// rawOutputValue returns the value of the entry as returned by the raw
+// endpoints, decompressing it if requested.
+func rawOutputValue(entry *logical.StorageEntry, compressed bool, encoding string) (interface{}, error) {
 	valueBytes := entry.Value
 	if compressed {
 		// Run this through the decompression helper to see if it's been compressed.
 		// If the input contained the compression canary, `valueBytes` will hold
 		// the decompressed data. If the input was not compressed, then `valueBytes`
 		// will be nil.
+		var err error
 		valueBytes, _, err = compressutil.Decompress(entry.Value)
 		if err != nil {
-			return handleErrorNoReadOnlyForward(err)
+			return nil, err
 		}
 
 		// `valueBytes` is nil if the input is uncompressed. In that case set it to the original input.
@@ -105,13 +133,7 @@ func (b *RawBackend) handleRawRead(ctx context.Context, req *logical.Request, da
 	if encoding == "base64" {
 		value = valueBytes
 	}
-
-	resp := &logical.Response{
-		Data: map[string]interface{}{
-			"value": value,
-		},
-	}
-	return resp, nil
+	return value, nil
 }
 
 // handleRawWrite is used to write directly to the barrier
@@ -140,35 +162,57 @@ func (b *RawBackend) handleRawWrite(ctx context.Context, req *logical.Request, d
 		}
 	}
 
-	v := data.Get("value").(string)
-	value := []byte(v)
-	if encoding == "base64" {
-		var err error
-		value, err = base64.StdEncoding.DecodeString(v)
-		if err != nil {
-			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
-		}
-	}
-
+	var existing *logical.StorageEntry
 	if req.Operation == logical.UpdateOperation {
 		// Check if this is an existing value with compression applied, if so, use the same compression (or no compression)
-		entry, err := b.barrier.Get(ctx, path)
+		var err error
+		existing, err = b.barrier.Get(ctx, path)
 		if err != nil {
 			return handleErrorNoReadOnlyForward(err)
 		}
-		if entry == nil {
+		if existing == nil {
 			err := fmt.Sprintf("cannot figure out compression type because entry does not exist")
 			return logical.ErrorResponse(err), logical.ErrInvalidRequest
 		}
+	}
+
+	value, err := rawInputValue(existing, data.Get("value").(string), encoding, compressionType, compressionTypeOk)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
 
+	entry := &logical.StorageEntry{
+		Key:   path,
+		Value: value,
+	}
+
+	if err := b.barrier.Put(ctx, entry); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	return nil, nil
+}
+
+// rawInputValue returns the value to store for a value given to the raw
+// endpoints. If the entry exists, it is compressed the same way as the
+// existing entry unless a compression type is given.
+func rawInputValue(existing *logical.StorageEntry, v, encoding, compressionType string, compressionTypeOk bool) ([]byte, error) {
+	value := []byte(v)
+	if encoding == "base64" {
+		var err error
+		value, err = base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if existing != nil {
 		// For cases where DecompressWithCanary errored, treat entry as non-compressed data.
-		_, existingCompressionType, _, _ := compressutil.DecompressWithCanary(entry.Value)
+		_, existingCompressionType, _, _ := compressutil.DecompressWithCanary(existing.Value)
 
 		// Ensure compression_type matches existing entries' compression
 		// except allow writing non-compressed data over compressed data
 		if existingCompressionType != compressionType && compressionType != "" {
-			err := fmt.Sprintf("the entry uses a different compression scheme then compression_type")
-			return logical.ErrorResponse(err), logical.ErrInvalidRequest
+			return nil, fmt.Errorf("the entry uses a different compression scheme then compression_type")
 		}
 
 		if !compressionTypeOk {
@@ -183,44 +227,31 @@ func (b *RawBackend) handleRawWrite(ctx context.Context, req *logical.Request, d
 			config = &compressutil.CompressionConfig{
 				Type: compressutil.CompressionTypeLZ4,
 			}
-			break
 		case compressutil.CompressionTypeLZW:
 			config = &compressutil.CompressionConfig{
 				Type: compressutil.CompressionTypeLZW,
 			}
-			break
 		case compressutil.CompressionTypeGzip:
 			config = &compressutil.CompressionConfig{
 				Type:                 compressutil.CompressionTypeGzip,
 				GzipCompressionLevel: gzip.BestCompression,
 			}
-			break
 		case compressutil.CompressionTypeSnappy:
 			config = &compressutil.CompressionConfig{
 				Type: compressutil.CompressionTypeSnappy,
 			}
-			break
 		default:
-			err := fmt.Sprintf("invalid compression type %q", compressionType)
-			return logical.ErrorResponse(err), logical.ErrInvalidRequest
+			return nil, fmt.Errorf("invalid compression type %q", compressionType)
 		}
 
 		var err error
 		value, err = compressutil.Compress(value, config)
 		if err != nil {
-			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+			return nil, err
 		}
 	}
 
-	entry := &logical.StorageEntry{
-		Key:   path,
-		Value: value,
-	}
-
-	if err := b.barrier.Put(ctx, entry); err != nil {
-		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
-	}
-	return nil, nil
+	return value, nil
 }
 
 // handleRawDelete is used to delete directly from the barrier
@@ -277,6 +308,203 @@ func (b *RawBackend) handleRawList(ctx context.Context, req *logical.Request, da
 	return logical.ListResponse(keys), nil
 }
 
+// rawBatchOperation is a single operation of a request to the raw batch
+// endpoint.
+type rawBatchOperation struct {
+	Operation       string  `mapstructure:"operation"`
+	Path            string  `mapstructure:"path"`
+	Value           string  `mapstructure:"value"`
+	Encoding        string  `mapstructure:"encoding"`
+	Compressed      *bool   `mapstructure:"compressed"`
+	CompressionType *string `mapstructure:"compression_type"`
+}
+
+// handleRawBatch is used to run several get, put and delete operations
+// directly against the barrier. Consecutive gets are fetched together. The
+// operations are independent: the failure of one is reported in its result
+// and does not prevent the others from running.
+func (b *RawBackend) handleRawBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	rawOps := data.Get("operations").([]interface{})
+	switch {
+	case len(rawOps) == 0:
+		return logical.ErrorResponse("no operations given"), logical.ErrInvalidRequest
+	case len(rawOps) > rawBatchMaxOperations:
+		return logical.ErrorResponse("at most %d operations are allowed per request, got %d", rawBatchMaxOperations, len(rawOps)), logical.ErrInvalidRequest
+	}
+
+	ops := make([]*rawBatchOperation, len(rawOps))
+	for i, rawOp := range rawOps {
+		op := new(rawBatchOperation)
+		if err := mapstructure.Decode(rawOp, op); err != nil {
+			return logical.ErrorResponse("invalid operation at index %d: %v", i, err), logical.ErrInvalidRequest
+		}
+		ops[i] = op
+	}
+
+	results := make([]map[string]interface{}, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].Operation != "get" {
+			results[i] = b.rawBatchRun(ctx, ops[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(ops) && ops[j].Operation == "get" {
+			j++
+		}
+		b.rawBatchGet(ctx, ops[i:j], results[i:j])
+		i = j
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"results": results,
+		},
+	}, nil
+}
+
+// rawBatchCheck validates an operation of a batch, returning an error if it
+// cannot be run.
+func (b *RawBackend) rawBatchCheck(op *rawBatchOperation) error {
+	var verb string
+	switch op.Operation {
+	case "get":
+		verb = "read"
+	case "put":
+		verb = "write"
+	case "delete":
+		verb = "delete"
+	default:
+		return fmt.Errorf("invalid operation %q", op.Operation)
+	}
+
+	if op.Path == "" {
+		return fmt.Errorf("missing path")
+	}
+	if op.Encoding != "" && op.Encoding != "base64" {
+		return fmt.Errorf("invalid encoding %q", op.Encoding)
+	}
+
+	// Prevent access of protected paths
+	for _, p := range protectedPaths {
+		if strings.HasPrefix(op.Path, p) {
+			return fmt.Errorf("cannot %s %q", verb, op.Path)
+		}
+	}
+
+	if b.recoveryMode {
+		b.logger.Info("batch "+verb, "path", op.Path)
+	}
+	return nil
+}
+
+// rawBatchGet runs consecutive get operations of a batch, fetching their
+// entries together if the barrier supports it.
+func (b *RawBackend) rawBatchGet(ctx context.Context, ops []*rawBatchOperation, results []map[string]interface{}) {
+	var keys []string
+	var indexes []int
+	for i, op := range ops {
+		results[i] = rawBatchResult(op)
+		if err := b.rawBatchCheck(op); err != nil {
+			results[i]["error"] = err.Error()
+			continue
+		}
+		keys = append(keys, op.Path)
+		indexes = append(indexes, i)
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	var entries []*logical.StorageEntry
+	if bg, ok := b.barrier.(barrierBatchGetter); ok {
+		var err error
+		entries, err = bg.BatchGet(ctx, keys)
+		if err != nil {
+			// Fall back to fetching the entries one at a time, so that the
+			// error is only reported for the operations it concerns
+			b.logger.Debug("failed to fetch entries in a batch", "error", err)
+			entries = nil
+		}
+	}
+
+	for n, i := range indexes {
+		op := ops[i]
+
+		var entry *logical.StorageEntry
+		if entries != nil {
+			entry = entries[n]
+		} else {
+			var err error
+			entry, err = b.barrier.Get(ctx, op.Path)
+			if err != nil {
+				results[i]["error"] = err.Error()
+				continue
+			}
+		}
+		if entry == nil {
+			continue
+		}
+
+		// Preserve pre-existing behavior to decompress if `compressed` is missing
+		compressed := op.Compressed == nil || *op.Compressed
+		value, err := rawOutputValue(entry, compressed, op.Encoding)
+		if err != nil {
+			results[i]["error"] = err.Error()
+			continue
+		}
+		results[i]["value"] = value
+	}
+}
+
+// rawBatchRun runs a put or delete operation of a batch.
+func (b *RawBackend) rawBatchRun(ctx context.Context, op *rawBatchOperation) map[string]interface{} {
+	result := rawBatchResult(op)
+	if err := b.rawBatchCheck(op); err != nil {
+		result["error"] = err.Error()
+		return result
+	}
+
+	var err error
+	switch op.Operation {
+	case "put":
+		var existing *logical.StorageEntry
+		existing, err = b.barrier.Get(ctx, op.Path)
+		if err != nil {
+			break
+		}
+
+		compressionType := ""
+		if op.CompressionType != nil {
+			compressionType = *op.CompressionType
+		}
+		var value []byte
+		value, err = rawInputValue(existing, op.Value, op.Encoding, compressionType, op.CompressionType != nil)
+		if err != nil {
+			break
+		}
+
+		err = b.barrier.Put(ctx, &logical.StorageEntry{
+			Key:   op.Path,
+			Value: value,
+		})
+	case "delete":
+		err = b.barrier.Delete(ctx, op.Path)
+	}
+	if err != nil {
+		result["error"] = err.Error()
+	}
+	return result
+}
+
+func rawBatchResult(op *rawBatchOperation) map[string]interface{} {
+	return map[string]interface{}{
+		"operation": op.Operation,
+		"path":      op.Path,
+	}
+}
+
 // existenceCheck checks if entry exists, used in handleRawWrite for update or create operations
 func (b *RawBackend) existenceCheck(ctx context.Context, request *logical.Request, data *framework.FieldData) (bool, error) {
 	path := data.Get("path").(string)
@@ -407,5 +635,41 @@ func rawPaths(prefix string, r *RawBackend) []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["raw"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["raw"][1]),
 		},
+		{
+			Pattern: prefix + "raw-batch$",
+
+			Fields: map[string]*framework.FieldSchema{
+				"operations": {
+					Type:        framework.TypeSlice,
+					Description: `Operations to run, each with an "operation" of "get", "put" or "delete", a "path", and the "value", "encoding", "compressed" and "compression_type" parameters of the raw endpoint as applicable.`,
+					Required:    true,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: r.handleRawBatch,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationPrefix: "raw",
+						OperationVerb:   "batch",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"results": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Run several operations on keys at once.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["raw-batch"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["raw-batch"][1]),
+		},
 	}
 }
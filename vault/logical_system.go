@@ -47,6 +47,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/roottoken"
 	"github.com/openbao/openbao/sdk/v2/helper/wrapping"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/openbao/openbao/sdk/v2/physical"
 	"github.com/openbao/openbao/version"
 	"golang.org/x/crypto/sha3"
 )
@@ -54,6 +55,14 @@ import (
 const (
 	maxBytes    = 128 * 1024
 	globalScope = "global"
+
+	// revokeBatchDefaultLimit is the default number of tokens revoked by a
+	// single call to sys/revoke-batch
+	revokeBatchDefaultLimit = 1000
+
+	// revokeBatchProgressInterval is the number of tokens after which the
+	// progress of a batch revocation is logged
+	revokeBatchProgressInterval = 100
 )
 
 func systemBackendMemDBSchema() *memdb.DBSchema {
@@ -83,8 +92,10 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"remount",
 				"audit",
 				"audit/*",
+				"audit-salt/*",
 				"raw",
 				"raw/*",
+				"raw-batch",
 				"rotate",
 				"config/cors",
 				"config/auditing/*",
@@ -92,11 +103,13 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"plugins/catalog/*",
 				"revoke-prefix/*",
 				"revoke-force/*",
+				"revoke-batch",
 				"leases/revoke-prefix/*",
 				"leases/revoke-force/*",
 				"leases/lookup/*",
 				"leases",
 				"internal/inspect/*",
+				"internal/cache/physical",
 			},
 
 			Unauthenticated: []string{
@@ -141,6 +154,7 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsCatalogCRUDPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsReloadPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.auditPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.auditSaltPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.mountPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.authPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.lockedUserPaths()...)
@@ -613,6 +627,7 @@ func getVersion(d *framework.FieldData) (version string, builtin bool, err error
 func (b *SystemBackend) handlePluginReloadUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	pluginName := d.Get("plugin").(string)
 	pluginMounts := d.Get("mounts").([]string)
+	drainTimeout := time.Duration(d.Get("drain_timeout").(int)) * time.Second
 
 	if pluginName != "" && len(pluginMounts) > 0 {
 		return logical.ErrorResponse("plugin and mounts cannot be set at the same time"), nil
@@ -620,14 +635,17 @@ func (b *SystemBackend) handlePluginReloadUpdate(ctx context.Context, req *logic
 	if pluginName == "" && len(pluginMounts) == 0 {
 		return logical.ErrorResponse("plugin or mounts must be provided"), nil
 	}
+	if drainTimeout < 0 {
+		return logical.ErrorResponse("drain_timeout must not be negative"), nil
+	}
 
 	if pluginName != "" {
-		err := b.Core.reloadMatchingPlugin(ctx, pluginName)
+		err := b.Core.reloadMatchingPlugin(ctx, pluginName, drainTimeout)
 		if err != nil {
 			return nil, err
 		}
 	} else if len(pluginMounts) > 0 {
-		err := b.Core.reloadMatchingPluginMounts(ctx, pluginMounts)
+		err := b.Core.reloadMatchingPluginMounts(ctx, pluginMounts, drainTimeout)
 		if err != nil {
 			return nil, err
 		}
@@ -876,6 +894,7 @@ func (b *SystemBackend) mountInfo(ctx context.Context, entry *MountEntry) map[st
 		"local":                   entry.Local,
 		"seal_wrap":               entry.SealWrap,
 		"external_entropy_access": entry.ExternalEntropyAccess,
+		"dedicated_storage_key":   entry.DedicatedStorageKey,
 		"options":                 entry.Options,
 		"uuid":                    entry.UUID,
 		"plugin_version":          entry.Version,
@@ -907,8 +926,14 @@ func (b *SystemBackend) mountInfo(ctx context.Context, entry *MountEntry) map[st
 	if rawVal, ok := entry.synthesizedConfigCache.Load("allowed_managed_keys"); ok {
 		entryConfig["allowed_managed_keys"] = rawVal.([]string)
 	}
+	if rawVal, ok := entry.synthesizedConfigCache.Load("request_timeout"); ok {
+		entryConfig["request_timeout"] = int64(rawVal.(time.Duration).Seconds())
+	}
 	if entry.Table == credentialTableType {
 		entryConfig["token_type"] = entry.Config.TokenType.String()
+		if entry.Config.AliasCaseInsensitive {
+			entryConfig["alias_case_insensitive"] = true
+		}
 	}
 	if entry.Config.UserLockoutConfig != nil {
 		userLockoutConfig := map[string]interface{}{
@@ -972,6 +997,7 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 	pluginName := data.Get("plugin_name").(string)
 	sealWrap := data.Get("seal_wrap").(bool)
 	externalEntropyAccess := data.Get("external_entropy_access").(bool)
+	dedicatedStorageKey := data.Get("dedicated_storage_key").(bool)
 	options := data.Get("options").(map[string]string)
 
 	var config MountConfig
@@ -1108,6 +1134,15 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 	if len(apiConfig.AllowedManagedKeys) > 0 {
 		config.AllowedManagedKeys = apiConfig.AllowedManagedKeys
 	}
+	if apiConfig.RequestTimeout != "" {
+		requestTimeout, err := parseutil.ParseDurationSecond(apiConfig.RequestTimeout)
+		if err != nil || requestTimeout < 0 {
+			return logical.ErrorResponse(fmt.Sprintf(
+					"unable to parse request timeout of %s", apiConfig.RequestTimeout)),
+				logical.ErrInvalidRequest
+		}
+		config.RequestTimeout = requestTimeout
+	}
 
 	// Create the mount entry
 	me := &MountEntry{
@@ -1119,6 +1154,7 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 		Local:                 local,
 		SealWrap:              sealWrap,
 		ExternalEntropyAccess: externalEntropyAccess,
+		DedicatedStorageKey:   dedicatedStorageKey,
 		Options:               options,
 		Version:               pluginVersion,
 	}
@@ -1344,6 +1380,29 @@ func (b *SystemBackend) handleRemount(ctx context.Context, req *logical.Request,
 		return handleError(fmt.Errorf("path already in use at %q", match))
 	}
 
+	// Report what refers to the mount instead of moving it
+	if data.Get("dry_run").(bool) {
+		refs, err := b.Core.remountReferences(ctx, fromPathDetails)
+		if err != nil {
+			return handleError(err)
+		}
+
+		resp := &logical.Response{
+			Data: map[string]interface{}{
+				"from":          fromPathDetails.MountPath,
+				"to":            toPathDetails.MountPath,
+				"dry_run":       true,
+				"policies":      refs.Policies,
+				"audit_filters": refs.AuditFilters,
+				"quotas":        refs.Quotas,
+			},
+		}
+		if len(refs.Quotas) > 0 {
+			resp.AddWarning("Quotas are moved along with the mount and need no update")
+		}
+		return resp, nil
+	}
+
 	migrationID, err := b.Core.createMigrationStatus(fromPathDetails, toPathDetails)
 	if err != nil {
 		return nil, fmt.Errorf("Error creating migration status %+v", err)
@@ -1491,6 +1550,9 @@ func (b *SystemBackend) handleTuneReadCommon(ctx context.Context, path string) (
 
 	if mountEntry.Table == credentialTableType {
 		resp.Data["token_type"] = mountEntry.Config.TokenType.String()
+		if mountEntry.Config.AliasCaseInsensitive {
+			resp.Data["alias_case_insensitive"] = true
+		}
 	}
 
 	if rawVal, ok := mountEntry.synthesizedConfigCache.Load("audit_non_hmac_request_keys"); ok {
@@ -1517,6 +1579,10 @@ func (b *SystemBackend) handleTuneReadCommon(ctx context.Context, path string) (
 		resp.Data["allowed_managed_keys"] = rawVal.([]string)
 	}
 
+	if rawVal, ok := mountEntry.synthesizedConfigCache.Load("request_timeout"); ok {
+		resp.Data["request_timeout"] = int64(rawVal.(time.Duration).Seconds())
+	}
+
 	if mountEntry.Config.UserLockoutConfig != nil {
 		resp.Data["user_lockout_counter_reset_duration"] = int64(mountEntry.Config.UserLockoutConfig.LockoutCounterReset.Seconds())
 		resp.Data["user_lockout_threshold"] = mountEntry.Config.UserLockoutConfig.LockoutThreshold
@@ -1919,6 +1985,26 @@ func (b *SystemBackend) handleTuneWriteCommon(ctx context.Context, path string,
 		}
 	}
 
+	if rawVal, ok := data.GetOk("alias_case_insensitive"); ok {
+		if !strings.HasPrefix(path, "auth/") {
+			return logical.ErrorResponse("'alias_case_insensitive' can only be modified on auth mounts"), logical.ErrInvalidRequest
+		}
+
+		caseInsensitive := rawVal.(bool)
+		oldVal := mountEntry.Config.AliasCaseInsensitive
+		mountEntry.Config.AliasCaseInsensitive = caseInsensitive
+
+		// Update the mount table
+		if err := b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local); err != nil {
+			mountEntry.Config.AliasCaseInsensitive = oldVal
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of alias_case_insensitive successful", "path", path, "alias_case_insensitive", caseInsensitive)
+		}
+	}
+
 	if rawVal, ok := data.GetOk("passthrough_request_headers"); ok {
 		headers := rawVal.([]string)
 
@@ -1996,6 +2082,35 @@ func (b *SystemBackend) handleTuneWriteCommon(ctx context.Context, path string,
 		}
 	}
 
+	if rawVal, ok := data.GetOk("request_timeout"); ok {
+		requestTimeout := time.Duration(rawVal.(int)) * time.Second
+		if requestTimeout < 0 {
+			return logical.ErrorResponse("request_timeout must not be negative"), logical.ErrInvalidRequest
+		}
+
+		oldVal := mountEntry.Config.RequestTimeout
+		mountEntry.Config.RequestTimeout = requestTimeout
+
+		// Update the mount table
+		var err error
+		switch {
+		case strings.HasPrefix(path, "auth/"):
+			err = b.Core.persistAuth(ctx, b.Core.auth, &mountEntry.Local)
+		default:
+			err = b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local)
+		}
+		if err != nil {
+			mountEntry.Config.RequestTimeout = oldVal
+			return handleError(err)
+		}
+
+		mountEntry.SyncCache()
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of request_timeout successful", "path", path, "request_timeout", requestTimeout)
+		}
+	}
+
 	var err error
 	var resp *logical.Response
 	var options map[string]string
@@ -2288,6 +2403,81 @@ func (b *SystemBackend) handleRevokePrefixCommon(ctx context.Context,
 	return logical.RespondWithStatusCode(nil, nil, http.StatusAccepted)
 }
 
+// handleRevokeBatch is used to revoke a batch of tokens, given by accessor or
+// by the entity they belong to, along with their leases
+func (b *SystemBackend) handleRevokeBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	accessors := data.Get("accessors").([]string)
+	entityID := data.Get("entity_id").(string)
+	after := data.Get("after").(string)
+	limit := data.Get("limit").(int)
+
+	switch {
+	case len(accessors) == 0 && entityID == "":
+		return logical.ErrorResponse("one of accessors or entity_id is required"), logical.ErrInvalidRequest
+	case len(accessors) > 0 && entityID != "":
+		return logical.ErrorResponse("accessors and entity_id are mutually exclusive"), logical.ErrInvalidRequest
+	case after != "" && entityID == "":
+		return logical.ErrorResponse("after can only be used with entity_id"), logical.ErrInvalidRequest
+	case limit < 0:
+		return logical.ErrorResponse("limit cannot be negative"), logical.ErrInvalidRequest
+	}
+	if limit == 0 {
+		limit = revokeBatchDefaultLimit
+	}
+	accessors = strutil.RemoveDuplicates(accessors, false)
+	if len(accessors) > limit {
+		return logical.ErrorResponse(fmt.Sprintf("at most %d accessors can be revoked at once", limit)), logical.ErrInvalidRequest
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revokeCtx := namespace.ContextWithNamespace(b.Core.activeContext, ns)
+
+	var next string
+	if entityID != "" {
+		accessors, next, err = b.Core.tokenStore.entityAccessors(revokeCtx, entityID, after, limit)
+		if err != nil {
+			b.Backend.Logger().Error("failed to look up entity tokens", "entity_id", entityID, "error", err)
+			return handleErrorNoReadOnlyForward(err)
+		}
+	}
+
+	revoked := make([]string, 0, len(accessors))
+	failed := make(map[string]interface{})
+	for i, accessor := range accessors {
+		if err := b.Core.tokenStore.revokeByAccessor(revokeCtx, accessor); err != nil {
+			if errwrap.Contains(err, logical.ErrReadOnly.Error()) {
+				return handleErrorNoReadOnlyForward(err)
+			}
+			failed[accessor] = err.Error()
+		} else {
+			revoked = append(revoked, accessor)
+		}
+
+		if (i+1)%revokeBatchProgressInterval == 0 {
+			b.Backend.Logger().Info("batch token revocation in progress", "processed", i+1, "total", len(accessors), "failed", len(failed))
+		}
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"revoked": revoked,
+			"errors":  failed,
+		},
+	}
+	if next != "" {
+		resp.Data["after"] = next
+	}
+	if len(failed) > 0 {
+		b.Backend.Logger().Warn("failed to revoke some tokens of the batch", "revoked", len(revoked), "failed", len(failed))
+		resp.AddWarning(fmt.Sprintf("Failed to revoke %d of %d tokens", len(failed), len(accessors)))
+	}
+
+	return resp, nil
+}
+
 // handleAuthTable handles the "auth" endpoint to provide the auth table
 func (b *SystemBackend) handleAuthTable(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	ns, err := namespace.FromContext(ctx)
@@ -2379,6 +2569,7 @@ func (b *SystemBackend) handleEnableAuth(ctx context.Context, req *logical.Reque
 	pluginName := data.Get("plugin_name").(string)
 	sealWrap := data.Get("seal_wrap").(bool)
 	externalEntropyAccess := data.Get("external_entropy_access").(bool)
+	dedicatedStorageKey := data.Get("dedicated_storage_key").(bool)
 	options := data.Get("options").(map[string]string)
 
 	var config MountConfig
@@ -2453,6 +2644,8 @@ func (b *SystemBackend) handleEnableAuth(ctx context.Context, req *logical.Reque
 			"invalid value for 'token_type'")), logical.ErrInvalidRequest
 	}
 
+	config.AliasCaseInsensitive = apiConfig.AliasCaseInsensitive
+
 	switch logicalType {
 	case "":
 		return logical.ErrorResponse(
@@ -2504,6 +2697,15 @@ func (b *SystemBackend) handleEnableAuth(ctx context.Context, req *logical.Reque
 	if len(apiConfig.AllowedManagedKeys) > 0 {
 		config.AllowedManagedKeys = apiConfig.AllowedManagedKeys
 	}
+	if apiConfig.RequestTimeout != "" {
+		requestTimeout, err := parseutil.ParseDurationSecond(apiConfig.RequestTimeout)
+		if err != nil || requestTimeout < 0 {
+			return logical.ErrorResponse(fmt.Sprintf(
+					"unable to parse request timeout of %s", apiConfig.RequestTimeout)),
+				logical.ErrInvalidRequest
+		}
+		config.RequestTimeout = requestTimeout
+	}
 
 	// Create the mount entry
 	me := &MountEntry{
@@ -2515,6 +2717,7 @@ func (b *SystemBackend) handleEnableAuth(ctx context.Context, req *logical.Reque
 		Local:                 local,
 		SealWrap:              sealWrap,
 		ExternalEntropyAccess: externalEntropyAccess,
+		DedicatedStorageKey:   dedicatedStorageKey,
 		Options:               options,
 		Version:               pluginVersion,
 	}
@@ -2769,6 +2972,171 @@ func (b *SystemBackend) handlePoliciesDelete(policyType PolicyType) framework.Op
 	}
 }
 
+// explainOperations are the operations whose policy decisions can be
+// explained.
+var explainOperations = map[logical.Operation]struct{}{
+	logical.CreateOperation: {},
+	logical.ReadOperation:   {},
+	logical.UpdateOperation: {},
+	logical.PatchOperation:  {},
+	logical.DeleteOperation: {},
+	logical.ListOperation:   {},
+}
+
+// handlePoliciesExplain explains how the policies of a token, or a set of
+// policies, decide on an operation on a path. The rules of the policies the
+// caller is not allowed to read are redacted.
+func (b *SystemBackend) handlePoliciesExplain(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	path := strings.TrimPrefix(d.Get("path").(string), "/")
+	if path == "" {
+		return logical.ErrorResponse("missing path"), nil
+	}
+	op := logical.Operation(strings.ToLower(d.Get("operation").(string)))
+	if _, ok := explainOperations[op]; !ok {
+		return logical.ErrorResponse("unsupported operation %q", op), nil
+	}
+
+	token := d.Get("token").(string)
+	policyNames := d.Get("policies").([]string)
+	if token != "" && len(policyNames) > 0 {
+		return logical.ErrorResponse("only one of token or policies may be provided"), nil
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	callerACL, callerTE, _, _, err := b.Core.fetchACLTokenEntryAndEntity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{}
+
+	// Inline policies are only part of tokens, and only shown to their
+	// holders.
+	self := false
+	var acl *ACL
+	if len(policyNames) > 0 {
+		for _, name := range policyNames {
+			policy, err := b.Core.policyStore.GetPolicy(ctx, name, PolicyTypeACL)
+			if err != nil {
+				return nil, err
+			}
+			if policy == nil {
+				resp.AddWarning(fmt.Sprintf("policy %q does not exist", name))
+			}
+		}
+		acl, err = b.Core.policyStore.tracedACL(ctx, nil, map[string][]string{ns.ID: policyNames})
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+	} else {
+		te := callerTE
+		if token != "" {
+			te, err = b.Core.tokenStore.Lookup(ctx, token)
+			if err != nil {
+				return nil, err
+			}
+			if te == nil {
+				return nil, &logical.StatusBadRequest{Err: "invalid token"}
+			}
+		}
+		self = te.ID == callerTE.ID
+		acl, err = b.Core.tokenACL(ctx, te, true)
+		if err != nil {
+			if errwrap.Contains(err, logical.ErrPermissionDenied.Error()) {
+				return nil, &logical.StatusBadRequest{Err: "invalid token"}
+			}
+			return nil, err
+		}
+	}
+
+	explanation := &ACLExplanation{
+		Capability:   operationCapability(op),
+		Capabilities: []string{},
+		Reason:       "no policy applies",
+	}
+	if acl != nil {
+		explanation, err = acl.Explain(ctx, &logical.Request{
+			Operation: op,
+			Path:      path,
+			Data:      d.Get("data").(map[string]interface{}),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rules := []map[string]interface{}{}
+	redacted := 0
+	for _, rule := range explanation.Rules {
+		visible, err := b.policyReadable(ctx, callerACL, rule.Policy, self)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			redacted++
+			continue
+		}
+		rules = append(rules, map[string]interface{}{
+			"policy":         rule.Policy.Name,
+			"namespace_path": rule.Policy.NamespacePath,
+			"inline":         rule.Policy.Name == "",
+			"capabilities":   rule.Capabilities,
+		})
+	}
+
+	resp.Data = map[string]interface{}{
+		"path":           path,
+		"operation":      string(op),
+		"allowed":        explanation.Allowed,
+		"root":           explanation.Root,
+		"capability":     explanation.Capability,
+		"capabilities":   explanation.Capabilities,
+		"rules":          rules,
+		"redacted_rules": redacted,
+		"reason":         explanation.Reason,
+	}
+	// The matched path is part of the rules, so it is only shown along with
+	// one of them.
+	if explanation.MatchedPath != "" && len(rules) > 0 {
+		resp.Data["matched_path"] = explanation.MatchedPath
+	}
+
+	return resp, nil
+}
+
+// policyReadable returns true if the caller may read the contents of the
+// policy, either through the policy endpoints or, for inline policies, by
+// holding the token.
+func (b *SystemBackend) policyReadable(ctx context.Context, callerACL *ACL, policy logical.PolicyInfo, self bool) (bool, error) {
+	if policy.Name == "" {
+		return self, nil
+	}
+
+	policyNS, err := NamespaceByID(ctx, policy.NamespaceId, b.Core)
+	if err != nil {
+		return false, err
+	}
+	if policyNS == nil {
+		return false, nil
+	}
+	policyCtx := namespace.ContextWithNamespace(ctx, policyNS)
+
+	for _, path := range []string{"sys/policies/acl/", "sys/policy/"} {
+		res := callerACL.AllowOperation(policyCtx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      path + policy.Name,
+		}, false)
+		if res.Allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type passwordPolicyConfig struct {
 	HCLPolicy string `json:"policy"`
 }
@@ -3006,7 +3374,13 @@ func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Reques
 
 	path = sanitizePath(path)
 
-	hash, err := b.Core.auditBroker.GetHash(ctx, path, input)
+	var hash string
+	var err error
+	if epoch := data.Get("epoch").(int); epoch != 0 {
+		hash, err = b.Core.auditBroker.GetHashWithEpoch(ctx, path, input, epoch)
+	} else {
+		hash, err = b.Core.auditBroker.GetHash(ctx, path, input)
+	}
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
@@ -3018,6 +3392,52 @@ func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Reques
 	}, nil
 }
 
+// handleAuditSaltRead lists the epochs of the salt of an audit backend
+func (b *SystemBackend) handleAuditSaltRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	epochs, current, err := b.Core.auditBroker.SaltEpochs(ctx, path)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	epochInfo := make([]map[string]interface{}, 0, len(epochs))
+	for _, epoch := range epochs {
+		info := map[string]interface{}{
+			"epoch": epoch.Epoch,
+		}
+		if !epoch.CreatedTime.IsZero() {
+			info["created_time"] = epoch.CreatedTime.Format(time.RFC3339)
+		}
+		epochInfo = append(epochInfo, info)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"current_epoch": current,
+			"epochs":        epochInfo,
+		},
+	}, nil
+}
+
+// handleAuditSaltRotate rotates the salt of an audit backend
+func (b *SystemBackend) handleAuditSaltRotate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	epoch, err := b.Core.auditBroker.RotateSalt(ctx, path)
+	if err != nil {
+		b.Backend.Logger().Error("audit salt rotation failed", "path", path, "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	b.Backend.Logger().Info("rotated audit salt", "path", path, "epoch", epoch)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"current_epoch": epoch,
+		},
+	}, nil
+}
+
 // handleEnableAudit is used to enable a new audit backend
 func (b *SystemBackend) handleEnableAudit(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	local := data.Get("local").(bool)
@@ -3707,6 +4127,89 @@ func (b *SystemBackend) handleWrappingLookup(ctx context.Context, req *logical.R
 	return resp, nil
 }
 
+func (b *SystemBackend) handleWrappingConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := b.Core.wrappingConfig(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedPaths := conf.AllowedPaths
+	if allowedPaths == nil {
+		allowedPaths = []string{}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default_ttl":   int64(conf.DefaultTTL.Seconds()),
+			"max_ttl":       int64(conf.MaxTTL.Seconds()),
+			"allowed_paths": allowedPaths,
+		},
+	}, nil
+}
+
+func (b *SystemBackend) handleWrappingConfigUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := b.Core.wrappingConfig(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &WrappingConfig{
+		DefaultTTL:   current.DefaultTTL,
+		MaxTTL:       current.MaxTTL,
+		AllowedPaths: current.AllowedPaths,
+	}
+	if raw, ok := data.GetOk("default_ttl"); ok {
+		conf.DefaultTTL = time.Duration(raw.(int)) * time.Second
+	}
+	if raw, ok := data.GetOk("max_ttl"); ok {
+		conf.MaxTTL = time.Duration(raw.(int)) * time.Second
+	}
+	if raw, ok := data.GetOk("allowed_paths"); ok {
+		conf.AllowedPaths = nil
+		for _, path := range raw.([]string) {
+			path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+			if path == "" {
+				return logical.ErrorResponse("allowed_paths cannot contain empty paths"), logical.ErrInvalidRequest
+			}
+			conf.AllowedPaths = append(conf.AllowedPaths, path)
+		}
+	}
+
+	if conf.DefaultTTL < 0 || conf.MaxTTL < 0 {
+		return logical.ErrorResponse("default_ttl and max_ttl cannot be negative"), logical.ErrInvalidRequest
+	}
+	if conf.MaxTTL > 0 && conf.DefaultTTL > conf.MaxTTL {
+		return logical.ErrorResponse("default_ttl cannot be greater than max_ttl"), logical.ErrInvalidRequest
+	}
+
+	if err := b.Core.setWrappingConfig(ctx, ns, conf); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *SystemBackend) handleWrappingConfigDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.Core.setWrappingConfig(ctx, ns, nil); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
 func (b *SystemBackend) handleWrappingRewrap(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	// If a third party is rewrapping (rather than the calling token being the
 	// wrapping token) we detect this so that we can revoke the original
@@ -4126,6 +4629,39 @@ func (b *SystemBackend) pathInternalCountersEntities(ctx context.Context, req *l
 	return resp, nil
 }
 
+// cacheIntrospector is implemented by the physical cache, to report its
+// state without exposing any of the entries it holds.
+type cacheIntrospector interface {
+	Enabled() bool
+	Stats() physical.CacheStats
+	CacheExceptions() []string
+}
+
+func (b *SystemBackend) pathInternalCachePhysical(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cache, ok := b.Core.physicalCache.(cacheIntrospector)
+	if !ok {
+		return logical.ErrorResponse("the physical cache does not report statistics"), nil
+	}
+
+	stats := cache.Stats()
+	exceptions := cache.CacheExceptions()
+	sort.Strings(exceptions)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":         cache.Enabled(),
+			"size":            stats.MaxEntries,
+			"effective_size":  stats.EffectiveMaxEntries,
+			"entries":         stats.Entries,
+			"pinned_entries":  stats.PinnedEntries,
+			"estimated_bytes": stats.EstimatedBytes,
+			"hits":            stats.Hits,
+			"misses":          stats.Misses,
+			"exceptions":      exceptions,
+		},
+	}, nil
+}
+
 func (b *SystemBackend) pathInternalInspectRouter(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	b.Core.introspectionEnabledLock.Lock()
 	defer b.Core.introspectionEnabledLock.Unlock()
@@ -4435,6 +4971,11 @@ type SealStatusResponse struct {
 	RecoverySeal bool     `json:"recovery_seal"`
 	StorageType  string   `json:"storage_type,omitempty"`
 	Warnings     []string `json:"warnings,omitempty"`
+
+	// AttestationChallenge is the challenge to sign with the hardware token
+	// bound to the next key share submitted, if the shares are bound to
+	// credentials.
+	AttestationChallenge string `json:"attestation_challenge,omitempty"`
 }
 
 func (core *Core) GetSealStatus(ctx context.Context, lock bool) (*SealStatusResponse, error) {
@@ -4502,6 +5043,13 @@ func (core *Core) GetSealStatus(ctx context.Context, lock bool) (*SealStatusResp
 		StorageType:  core.StorageType(),
 	}
 
+	if sealed && len(sealConfig.ShareCredentials) > 0 {
+		s.AttestationChallenge, err = core.UnsealChallenge()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return s, nil
 }
 
@@ -5079,6 +5627,10 @@ in the plugin catalog.`,
 		`Whether to give the mount access to OpenBao's external entropy.`,
 	},
 
+	"dedicated_storage_key": {
+		`Whether to encrypt the data of the mount with a key dedicated to it, destroyed when the mount is disabled.`,
+	},
+
 	"tune_default_lease_ttl": {
 		`The default lease TTL for this mount.`,
 	},
@@ -5109,7 +5661,8 @@ in the plugin catalog.`,
 This path responds to the following HTTP methods.
 
     POST /sys/remount
-        Changes the mount point of an already-mounted backend.
+        Changes the mount point of an already-mounted backend. With
+        dry_run set, reports what refers to the mount point instead.
 		`,
 	},
 
@@ -5204,6 +5757,21 @@ are ignored, this setting is not supported.
 `,
 	},
 
+	"revoke-batch": {
+		"Revoke a batch of tokens by accessor or by entity.",
+		`
+Revokes the tokens with the given accessors, or the tokens of the given
+entity, along with their child tokens and leases. Only the tokens of the
+namespace of the request and of its child namespaces can be revoked.
+
+At most "limit" tokens are revoked by a single request. When revoking the
+tokens of an entity, the response has an "after" value if more tokens may
+remain; pass it as "after" to the next request to resume the revocation. The
+accessors of the revoked tokens are returned in "revoked", and the errors
+of the tokens which could not be revoked in "errors", by accessor.
+		`,
+	},
+
 	"revoke-prefix": {
 		"Revoke all secrets generated in a given prefix",
 		`
@@ -5320,6 +5888,17 @@ or delete a policy.
 		`,
 	},
 
+	"policies-explain": {
+		`Explain the policy decision on an operation on a path.`,
+		`
+Returns the policy rules matching the path, the path glob controlling the
+decision and the capabilities granted, for the policies of the given token, of
+the calling token if none is given, or for the given set of policies. Templated
+policies are resolved against the identity of the token. The rules of the
+policies which the caller is not allowed to read are redacted.
+		`,
+	},
+
 	"policy-name": {
 		`The name of the policy. Example: "ops"`,
 		"",
@@ -5350,6 +5929,30 @@ or delete a policy.
 		"",
 	},
 
+	"audit_epoch": {
+		`The epoch of the audit backend's salt to hash with. Defaults to the current epoch.`,
+		"",
+	},
+
+	"audit-salt": {
+		"List the epochs of the salt of an audit backend.",
+		`
+Audit backends HMAC sensitive values with a salt. Each rotation of the salt
+starts a new epoch, which is recorded as "hmac_epoch" in the entries logged
+with it. The salts of past epochs are kept so that values can still be hashed
+as they were logged, through the "epoch" parameter of sys/audit-hash.
+		`,
+	},
+
+	"audit-salt-rotate": {
+		"Rotate the salt of an audit backend.",
+		`
+Generates a new salt for the audit backend and starts a new epoch. Entries
+logged once the rotation completes are hashed with the new salt. Each audit
+backend has its own salt, which is rotated independently of the others.
+		`,
+	},
+
 	"audit-table": {
 		"List the currently enabled audit backends.",
 		`
@@ -5489,6 +6092,13 @@ it.`,
 		`Returns the creation TTL and creation time of a response-wrapped token.`,
 	},
 
+	"wrapping-config": {
+		"Configures response wrapping in the namespace.",
+		`Sets the default TTL used when response wrapping is asked for with a TTL
+		of zero, the maximum TTL of wrapping tokens and the path prefixes whose
+		responses may be wrapped in the namespace.`,
+	},
+
 	"rewrap": {
 		"Rotates a response-wrapped token.",
 		`Rotates a response-wrapped token; the output is a new token with the same
@@ -5602,6 +6212,10 @@ This path responds to the following HTTP methods.
 		`The mount paths of the plugin backends to reload.`,
 		"",
 	},
+	"plugin-backend-reload-drain-timeout": {
+		`If set, reload the backends gracefully: new requests to their mounts are held back while those in flight complete, for up to this duration, after which they are aborted. The backends are only swapped once the new ones have started.`,
+		"",
+	},
 	"hash": {
 		"Generate a hash sum for input data",
 		"Generates a hash sum of the given algorithm against the given input data.",
@@ -5626,10 +6240,27 @@ This path responds to the following HTTP methods.
 		"The type of token to issue (service or batch).",
 		"",
 	},
+	"request_timeout": {
+		"The maximum duration of requests to the mount, after which they are aborted. Zero disables the timeout.",
+		"",
+	},
+	"alias_case_insensitive": {
+		"Whether the identity aliases of the auth mount are matched to entities case-insensitively.",
+		"",
+	},
 	"raw": {
 		"Write, Read, and Delete data directly in the Storage backend.",
 		"",
 	},
+	"raw-batch": {
+		"Read, Write, and Delete several keys directly in the Storage backend at once.",
+		`
+Runs a list of get, put and delete operations in order and returns the result
+of each. An operation failing does not prevent the following ones from
+running; its error is reported in its result instead. Consecutive gets are
+fetched from the storage backend in a single round-trip when it supports it.
+		`,
+	},
 	"internal-ui-feature-flags": {
 		"Enabled feature flags. Internal API; its location, inputs, and outputs may change.",
 		"",
@@ -5672,6 +6303,15 @@ This path responds to the following HTTP methods.
 		"Count of active entities in this OpenBao cluster.",
 		"Count of active entities in this OpenBao cluster.",
 	},
+	"internal-cache-physical": {
+		"Configuration and statistics of the physical storage cache.",
+		`
+This path reports whether the physical storage cache is enabled, its
+configured and effective size, the number of entries it holds, the hits and
+misses since startup, and the paths excluded from caching. Cached keys and
+values are never returned.
+		`,
+	},
 	"internal-inspect-router": {
 		"Information on the entries in each of the trees in the router. Inspectable trees are uuid, accessor, storage, and root.",
 		`
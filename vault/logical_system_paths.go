@@ -1478,6 +1478,11 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 			"input": {
 				Type: framework.TypeString,
 			},
+
+			"epoch": {
+				Type:        framework.TypeInt,
+				Description: strings.TrimSpace(sysHelp["audit_epoch"][0]),
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -1502,6 +1507,89 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) auditSaltPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "audit-salt/(?P<path>.+)/rotate$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "auditing",
+				OperationVerb:   "rotate",
+				OperationSuffix: "salt",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleAuditSaltRotate,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"current_epoch": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Rotate the salt used by the audit device to HMAC sensitive values.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["audit-salt-rotate"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["audit-salt-rotate"][1]),
+		},
+
+		{
+			Pattern: "audit-salt/(?P<path>.+)",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "auditing",
+				OperationSuffix: "salt-epochs",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleAuditSaltRead,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"current_epoch": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"epochs": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "List the epochs of the salt used by the audit device.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["audit-salt"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["audit-salt"][1]),
+		},
+	}
+}
+
 func (b *SystemBackend) auditPaths() []*framework.Path {
 	return []*framework.Path{
 		b.auditHashPath(),
@@ -2031,6 +2119,10 @@ func (b *SystemBackend) pluginsReloadPath() *framework.Path {
 				Type:        framework.TypeString,
 				Description: strings.TrimSpace(sysHelp["plugin-backend-reload-scope"][0]),
 			},
+			"drain_timeout": {
+				Type:        framework.TypeDurationSecond,
+				Description: strings.TrimSpace(sysHelp["plugin-backend-reload-drain-timeout"][0]),
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -2057,7 +2149,7 @@ func (b *SystemBackend) pluginsReloadPath() *framework.Path {
 					}},
 				},
 				Summary:     "Reload mounted plugin backends.",
-				Description: "Either the plugin name (`plugin`) or the desired plugin backend mounts (`mounts`) must be provided, but not both. In the case that the plugin name is provided, all mounted paths that use that plugin backend will be reloaded.  If (`scope`) is provided and is (`global`), the plugin(s) are reloaded globally.",
+				Description: "Either the plugin name (`plugin`) or the desired plugin backend mounts (`mounts`) must be provided, but not both. In the case that the plugin name is provided, all mounted paths that use that plugin backend will be reloaded.  If (`scope`) is provided and is (`global`), the plugin(s) are reloaded globally. If (`drain_timeout`) is provided, the plugin(s) are reloaded gracefully, draining in-flight requests first.",
 			},
 		},
 
@@ -2338,6 +2430,10 @@ func (b *SystemBackend) internalPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: true,
 								},
+								"dedicated_storage_key": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
 								"options": {
 									Type:     framework.TypeMap,
 									Required: true,
@@ -2515,6 +2611,65 @@ func (b *SystemBackend) internalPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-counters-entities"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["internal-counters-entities"][1]),
 		},
+		{
+			Pattern: "internal/cache/physical",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "internal",
+				OperationVerb:   "read",
+				OperationSuffix: "physical-cache",
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathInternalCachePhysical,
+					Summary:  "Report the configuration and statistics of the physical storage cache.",
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"enabled": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"size": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"effective_size": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"entries": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"pinned_entries": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"estimated_bytes": {
+									Type:     framework.TypeInt64,
+									Required: true,
+								},
+								"hits": {
+									Type:     framework.TypeInt64,
+									Required: true,
+								},
+								"misses": {
+									Type:     framework.TypeInt64,
+									Required: true,
+								},
+								"exceptions": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(sysHelp["internal-cache-physical"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["internal-cache-physical"][1]),
+		},
 	}
 }
 
@@ -2889,6 +3044,64 @@ func (b *SystemBackend) leasePaths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(sysHelp["revoke-force"][1]),
 		},
 
+		{
+			Pattern: "revoke-batch$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "tokens",
+				OperationVerb:   "revoke",
+				OperationSuffix: "batch",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"accessors": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The accessors of the tokens to revoke. Mutually exclusive with entity_id.",
+				},
+				"entity_id": {
+					Type:        framework.TypeString,
+					Description: "The ID of the entity whose tokens to revoke. Mutually exclusive with accessors.",
+				},
+				"after": {
+					Type:        framework.TypeString,
+					Description: "The after value returned by a previous request, to resume revoking the tokens of an entity.",
+				},
+				"limit": {
+					Type:        framework.TypeInt,
+					Description: "The maximum number of tokens to revoke. Defaults to 1000.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleRevokeBatch,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"revoked": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"errors": {
+									Type:     framework.TypeMap,
+									Required: true,
+								},
+								"after": {
+									Type:     framework.TypeString,
+									Required: false,
+								},
+							},
+						}},
+					},
+					Summary: "Revokes a batch of tokens, given by accessor or entity, and their leases.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["revoke-batch"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["revoke-batch"][1]),
+		},
+
 		{
 			Pattern: "(leases/)?revoke-prefix/(?P<prefix>.+)",
 
@@ -3073,6 +3286,10 @@ func (b *SystemBackend) remountPaths() []*framework.Path {
 					Type:        framework.TypeString,
 					Description: "The new mount point.",
 				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "If true, report the policies, audit filters and quotas referring to the previous mount point instead of moving the mount.",
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -3083,8 +3300,25 @@ func (b *SystemBackend) remountPaths() []*framework.Path {
 							Description: "OK",
 							Fields: map[string]*framework.FieldSchema{
 								"migration_id": {
-									Type:     framework.TypeString,
-									Required: true,
+									Type: framework.TypeString,
+								},
+								"from": {
+									Type: framework.TypeString,
+								},
+								"to": {
+									Type: framework.TypeString,
+								},
+								"dry_run": {
+									Type: framework.TypeBool,
+								},
+								"policies": {
+									Type: framework.TypeSlice,
+								},
+								"audit_filters": {
+									Type: framework.TypeSlice,
+								},
+								"quotas": {
+									Type: framework.TypeSlice,
 								},
 							},
 						}},
@@ -3362,6 +3596,14 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 					Type:        framework.TypeMap,
 					Description: strings.TrimSpace(sysHelp["tune_user_lockout_config"][0]),
 				},
+				"request_timeout": {
+					Type:        framework.TypeDurationSecond,
+					Description: strings.TrimSpace(sysHelp["request_timeout"][0]),
+				},
+				"alias_case_insensitive": {
+					Type:        framework.TypeBool,
+					Description: strings.TrimSpace(sysHelp["alias_case_insensitive"][0]),
+				},
 				"plugin_version": {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["plugin-catalog_version"][0]),
@@ -3444,6 +3686,16 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: false,
 								},
+								"request_timeout": {
+									Type:        framework.TypeInt64,
+									Description: strings.TrimSpace(sysHelp["request_timeout"][0]),
+									Required:    false,
+								},
+								"alias_case_insensitive": {
+									Type:        framework.TypeBool,
+									Description: strings.TrimSpace(sysHelp["alias_case_insensitive"][0]),
+									Required:    false,
+								},
 								"options": {
 									Type:     framework.TypeMap,
 									Required: false,
@@ -3513,6 +3765,11 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 					Default:     false,
 					Description: strings.TrimSpace(sysHelp["external_entropy_access"][0]),
 				},
+				"dedicated_storage_key": {
+					Type:        framework.TypeBool,
+					Default:     false,
+					Description: strings.TrimSpace(sysHelp["dedicated_storage_key"][0]),
+				},
 				"plugin_name": {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["auth_plugin"][0]),
@@ -3562,6 +3819,10 @@ func (b *SystemBackend) authPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: true,
 								},
+								"dedicated_storage_key": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
 								"options": {
 									Type:     framework.TypeMap,
 									Required: true,
@@ -3856,6 +4117,95 @@ func (b *SystemBackend) policyPaths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(sysHelp["policy"][1]),
 		},
 
+		{
+			Pattern: "policies/explain$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "policies",
+				OperationVerb:   "explain",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"path": {
+					Type:        framework.TypeString,
+					Description: "Path of the request to explain.",
+				},
+				"operation": {
+					Type:        framework.TypeString,
+					Description: "Operation of the request to explain: create, read, update, patch, delete or list.",
+				},
+				"token": {
+					Type:        framework.TypeString,
+					Description: "Token whose policies are explained. Defaults to the calling token.",
+				},
+				"policies": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Policies to explain instead of the ones of a token.",
+				},
+				"data": {
+					Type:        framework.TypeMap,
+					Description: "Parameters of the request to explain, checked against the parameter constraints of the policies.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesExplain,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"path": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"operation": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"allowed": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"root": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"capability": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"capabilities": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+								"matched_path": {
+									Type:     framework.TypeString,
+									Required: false,
+								},
+								"rules": {
+									Type:     framework.TypeSlice,
+									Required: true,
+								},
+								"redacted_rules": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"reason": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Explain the policy decision on an operation on a path.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policies-explain"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policies-explain"][1]),
+		},
+
 		{
 			Pattern: "policies/password/?$",
 
@@ -4149,6 +4499,59 @@ func (b *SystemBackend) wrappingPaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysHelp["rewrap"][0]),
 			HelpDescription: strings.TrimSpace(sysHelp["rewrap"][1]),
 		},
+
+		{
+			Pattern: "wrapping/config$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "wrapping",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"default_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The TTL of the wrapping tokens of requests asking for response wrapping with a TTL of zero.",
+				},
+				"max_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The longest TTL allowed for wrapping tokens in the namespace.",
+				},
+				"allowed_paths": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The path prefixes whose responses may be wrapped. If empty, the responses of every path may be wrapped.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleWrappingConfigRead,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "read",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Read the response wrapping configuration of the namespace.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleWrappingConfigUpdate,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "configure",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Configure response wrapping in the namespace.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleWrappingConfigDelete,
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb:   "delete",
+						OperationSuffix: "configuration",
+					},
+					Summary: "Remove the response wrapping configuration of the namespace.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["wrapping-config"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["wrapping-config"][1]),
+		},
 	}
 }
 
@@ -4218,6 +4621,10 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Type:        framework.TypeMap,
 					Description: strings.TrimSpace(sysHelp["tune_user_lockout_config"][0]),
 				},
+				"request_timeout": {
+					Type:        framework.TypeDurationSecond,
+					Description: strings.TrimSpace(sysHelp["request_timeout"][0]),
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -4311,6 +4718,11 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: false,
 								},
+								"request_timeout": {
+									Type:        framework.TypeInt64,
+									Description: strings.TrimSpace(sysHelp["request_timeout"][0]),
+									Required:    false,
+								},
 							},
 						}},
 					},
@@ -4372,6 +4784,11 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Default:     false,
 					Description: strings.TrimSpace(sysHelp["external_entropy_access"][0]),
 				},
+				"dedicated_storage_key": {
+					Type:        framework.TypeBool,
+					Default:     false,
+					Description: strings.TrimSpace(sysHelp["dedicated_storage_key"][0]),
+				},
 				"plugin_name": {
 					Type:        framework.TypeString,
 					Description: strings.TrimSpace(sysHelp["mount_plugin_name"][0]),
@@ -4427,6 +4844,10 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Type:     framework.TypeBool,
 									Required: true,
 								},
+								"dedicated_storage_key": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
 								"options": {
 									Type:        framework.TypeKVPairs,
 									Description: strings.TrimSpace(sysHelp["mount_options"][0]),
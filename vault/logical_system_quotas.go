@@ -218,6 +218,286 @@ from any further requests until after the 'block_interval' has elapsed.`,
 			HelpSynopsis:    strings.TrimSpace(quotasHelp["rate-limit"][0]),
 			HelpDescription: strings.TrimSpace(quotasHelp["rate-limit"][1]),
 		},
+		{
+			Pattern: "quotas/lease-count/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "lease-count-quotas",
+				OperationVerb:   "list",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasList(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["lease-count-list"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["lease-count-list"][1]),
+		},
+		{
+			Pattern: "quotas/lease-count/" + framework.GenericNameRegex("name"),
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "lease-count-quotas",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Type of the quota rule.",
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the quota rule.",
+				},
+				"path": {
+					Type: framework.TypeString,
+					Description: `Path of the mount or namespace to apply the quota. A blank path configures a
+global quota. For example namespace1/ adds a quota to a full namespace,
+namespace1/auth/userpass adds a quota to userpass in namespace1.`,
+				},
+				"role": {
+					Type: framework.TypeString,
+					Description: `Login role to apply this quota to. Note that when set, path must be configured
+to a valid auth method with a concept of roles.`,
+				},
+				"max_leases": {
+					Type: framework.TypeInt,
+					Description: `The maximum number of leases to be allowed by the quota rule. The 'max_leases'
+must be positive.`,
+				},
+				"per_entity": {
+					Type: framework.TypeBool,
+					Description: `If set, 'max_leases' applies to the leases of each identity entity separately.
+Leases of tokens without an entity share a single limit.`,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasUpdate(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "write",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: http.StatusText(http.StatusNoContent),
+						}},
+					},
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"name": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"path": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"role": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"max_leases": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"per_entity": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+								"counter": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasDelete(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "delete",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["lease-count"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["lease-count"][1]),
+		},
+		{
+			Pattern: "quotas/secret-count/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secret-count-quotas",
+				OperationVerb:   "list",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleSecretCountQuotasList(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"keys": {
+									Type:     framework.TypeStringSlice,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["secret-count-list"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["secret-count-list"][1]),
+		},
+		{
+			Pattern: "quotas/secret-count/" + framework.GenericNameRegex("name"),
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secret-count-quotas",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Type of the quota rule.",
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the quota rule.",
+				},
+				"path": {
+					Type: framework.TypeString,
+					Description: `Path of the KV mount to apply the quota. A blank path configures a quota on
+all the KV mounts of the namespace.`,
+				},
+				"max_secrets": {
+					Type: framework.TypeInt,
+					Description: `The maximum number of secrets to be allowed by the quota rule. The
+'max_secrets' must be positive.`,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleSecretCountQuotasUpdate(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "write",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: http.StatusText(http.StatusNoContent),
+						}},
+					},
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleSecretCountQuotasRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"name": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"path": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"max_secrets": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"counter": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleSecretCountQuotasDelete(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "delete",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["secret-count"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["secret-count"][1]),
+		},
+		{
+			Pattern: "quotas/secret-count/" + framework.GenericNameRegex("name") + "/recount$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "secret-count-quotas",
+				OperationVerb:   "recount",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the quota rule.",
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleSecretCountQuotasRecount(),
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"counter": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["secret-count-recount"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["secret-count-recount"][1]),
+		},
 	}
 }
 
@@ -300,42 +580,10 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 			return logical.ErrorResponse("'block' is invalid"), nil
 		}
 
-		mountPath := sanitizePath(d.Get("path").(string))
 		ns := namespace.RootNamespace
-		if ns.ID != namespace.RootNamespaceID {
-			mountPath = strings.TrimPrefix(mountPath, ns.Path)
-		}
-
-		var pathSuffix string
-		if mountPath != "" {
-			me := b.Core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, ns), mountPath)
-			if me == nil {
-				return logical.ErrorResponse("invalid mount path %q", mountPath), nil
-			}
-
-			mountAPIPath := me.APIPathNoNamespace()
-			pathSuffix = strings.TrimSuffix(strings.TrimPrefix(mountPath, mountAPIPath), "/")
-			mountPath = mountAPIPath
-		}
-
-		role := d.Get("role").(string)
-		// If this is a quota with a role, ensure the backend supports role resolution
-		if role != "" {
-			if pathSuffix != "" {
-				return logical.ErrorResponse("Quotas cannot contain both a path suffix and a role. If a role is provided, path must be a valid auth mount with a concept of roles"), nil
-			}
-			authBackend := b.Core.router.MatchingBackend(namespace.ContextWithNamespace(ctx, ns), mountPath)
-			if authBackend == nil || authBackend.Type() != logical.TypeCredential {
-				return logical.ErrorResponse("Mount path %q is not a valid auth method and therefore unsuitable for use with role-based quotas", mountPath), nil
-			}
-			// We will always error as we aren't supplying real data, but we're looking for "unsupported operation" in particular
-			_, err := authBackend.HandleRequest(ctx, &logical.Request{
-				Path:      "login",
-				Operation: logical.ResolveRoleOperation,
-			})
-			if err != nil && (err == logical.ErrUnsupportedOperation || err == logical.ErrUnsupportedPath) {
-				return logical.ErrorResponse("Mount path %q does not support use with role-based quotas", mountPath), nil
-			}
+		mountPath, pathSuffix, role, errResp := b.quotaFactors(ctx, ns, d)
+		if errResp != nil {
+			return errResp, nil
 		}
 
 		// Disallow creation of new quota that has properties similar to an
@@ -441,6 +689,351 @@ func (b *SystemBackend) handleRateLimitQuotasDelete() framework.OperationFunc {
 	}
 }
 
+// quotaFactors parses the path and role of a quota rule into the mount path,
+// path suffix and role the rule applies to. A non-nil response is returned
+// when they are invalid.
+func (b *SystemBackend) quotaFactors(ctx context.Context, ns *namespace.Namespace, d *framework.FieldData) (string, string, string, *logical.Response) {
+	mountPath := sanitizePath(d.Get("path").(string))
+	if ns.ID != namespace.RootNamespaceID {
+		mountPath = strings.TrimPrefix(mountPath, ns.Path)
+	}
+
+	var pathSuffix string
+	if mountPath != "" {
+		me := b.Core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, ns), mountPath)
+		if me == nil {
+			return "", "", "", logical.ErrorResponse("invalid mount path %q", mountPath)
+		}
+
+		mountAPIPath := me.APIPathNoNamespace()
+		pathSuffix = strings.TrimSuffix(strings.TrimPrefix(mountPath, mountAPIPath), "/")
+		mountPath = mountAPIPath
+	}
+
+	role := d.Get("role").(string)
+	// If this is a quota with a role, ensure the backend supports role resolution
+	if role != "" {
+		if pathSuffix != "" {
+			return "", "", "", logical.ErrorResponse("Quotas cannot contain both a path suffix and a role. If a role is provided, path must be a valid auth mount with a concept of roles")
+		}
+		authBackend := b.Core.router.MatchingBackend(namespace.ContextWithNamespace(ctx, ns), mountPath)
+		if authBackend == nil || authBackend.Type() != logical.TypeCredential {
+			return "", "", "", logical.ErrorResponse("Mount path %q is not a valid auth method and therefore unsuitable for use with role-based quotas", mountPath)
+		}
+		// We will always error as we aren't supplying real data, but we're looking for "unsupported operation" in particular
+		_, err := authBackend.HandleRequest(ctx, &logical.Request{
+			Path:      "login",
+			Operation: logical.ResolveRoleOperation,
+		})
+		if err != nil && (err == logical.ErrUnsupportedOperation || err == logical.ErrUnsupportedPath) {
+			return "", "", "", logical.ErrorResponse("Mount path %q does not support use with role-based quotas", mountPath)
+		}
+	}
+
+	return mountPath, pathSuffix, role, nil
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		names, err := b.Core.quotaManager.QuotaNames(quotas.TypeLeaseCount)
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+
+		qType := quotas.TypeLeaseCount.String()
+		maxLeases := d.Get("max_leases").(int)
+		if maxLeases <= 0 {
+			return logical.ErrorResponse("'max_leases' is invalid"), nil
+		}
+		perEntity := d.Get("per_entity").(bool)
+
+		ns := namespace.RootNamespace
+		mountPath, pathSuffix, role, errResp := b.quotaFactors(ctx, ns, d)
+		if errResp != nil {
+			return errResp, nil
+		}
+
+		// Disallow creation of new quota that has properties similar to an
+		// existing quota.
+		quotaByFactors, err := b.Core.quotaManager.QuotaByFactors(ctx, qType, ns.Path, mountPath, pathSuffix, role)
+		if err != nil {
+			return nil, err
+		}
+		if quotaByFactors != nil && quotaByFactors.QuotaName() != name {
+			return logical.ErrorResponse("quota rule with similar properties exists under the name %q", quotaByFactors.QuotaName()), nil
+		}
+
+		// If a quota already exists, fetch and update it.
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case quota == nil:
+			quota = quotas.NewLeaseCountQuota(name, ns.Path, mountPath, pathSuffix, role, maxLeases, perEntity)
+		default:
+			// Re-inserting the already indexed object in memdb might cause problems.
+			// So, clone the object. See https://github.com/hashicorp/go-memdb/issues/76.
+			clonedQuota := quota.Clone()
+			lcq := clonedQuota.(*quotas.LeaseCountQuota)
+			lcq.NamespacePath = ns.Path
+			lcq.MountPath = mountPath
+			lcq.PathSuffix = pathSuffix
+			lcq.Role = role
+			lcq.MaxLeases = maxLeases
+			lcq.PerEntity = perEntity
+			quota = lcq
+		}
+
+		entry, err := logical.StorageEntryJSON(quotas.QuotaStoragePath(qType, name), quota)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.SetQuota(ctx, qType, quota, false); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeLeaseCount.String()
+
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+		if quota == nil {
+			return nil, nil
+		}
+
+		lcq := quota.(*quotas.LeaseCountQuota)
+
+		nsPath := lcq.NamespacePath
+		if lcq.NamespacePath == "root" {
+			nsPath = ""
+		}
+
+		data := map[string]interface{}{
+			"type":       qType,
+			"name":       lcq.Name,
+			"path":       nsPath + lcq.MountPath + lcq.PathSuffix,
+			"role":       lcq.Role,
+			"max_leases": lcq.MaxLeases,
+			"per_entity": lcq.PerEntity,
+			"counter":    lcq.Count(),
+		}
+
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeLeaseCount.String()
+
+		if err := req.Storage.Delete(ctx, quotas.QuotaStoragePath(qType, name)); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.DeleteQuota(ctx, qType, name); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretCountQuotasList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		names, err := b.Core.quotaManager.QuotaNames(quotas.TypeSecretCount)
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleSecretCountQuotasUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+
+		qType := quotas.TypeSecretCount.String()
+		maxSecrets := d.Get("max_secrets").(int)
+		if maxSecrets <= 0 {
+			return logical.ErrorResponse("'max_secrets' is invalid"), nil
+		}
+
+		ns := namespace.RootNamespace
+		mountPath := sanitizePath(d.Get("path").(string))
+		if mountPath != "" {
+			me := b.Core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, ns), mountPath)
+			if me == nil {
+				return logical.ErrorResponse("invalid mount path %q", mountPath), nil
+			}
+			if me.Type != mountTypeKV && me.Type != "generic" {
+				return logical.ErrorResponse("mount path %q is not a KV mount", mountPath), nil
+			}
+			if mountPath != me.APIPathNoNamespace() {
+				return logical.ErrorResponse("secret count quotas apply to whole KV mounts, not paths within them"), nil
+			}
+		}
+
+		// Disallow creation of new quota that has properties similar to an
+		// existing quota.
+		quotaByFactors, err := b.Core.quotaManager.QuotaByFactors(ctx, qType, ns.Path, mountPath, "", "")
+		if err != nil {
+			return nil, err
+		}
+		if quotaByFactors != nil && quotaByFactors.QuotaName() != name {
+			return logical.ErrorResponse("quota rule with similar properties exists under the name %q", quotaByFactors.QuotaName()), nil
+		}
+
+		// If a quota already exists, fetch and update it.
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case quota == nil:
+			quota = quotas.NewSecretCountQuota(name, ns.Path, mountPath, maxSecrets)
+		default:
+			// Re-inserting the already indexed object in memdb might cause problems.
+			// So, clone the object. See https://github.com/hashicorp/go-memdb/issues/76.
+			clonedQuota := quota.Clone()
+			scq := clonedQuota.(*quotas.SecretCountQuota)
+			scq.NamespacePath = ns.Path
+			scq.MountPath = mountPath
+			scq.MaxSecrets = maxSecrets
+			quota = scq
+		}
+
+		entry, err := logical.StorageEntryJSON(quotas.QuotaStoragePath(qType, name), quota)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.SetQuota(ctx, qType, quota, false); err != nil {
+			return nil, err
+		}
+
+		// The quota may now apply to other mounts, and take mounts from other
+		// quotas, so count their secrets again
+		if _, err := b.Core.recountSecretCountQuotas(ctx, ""); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretCountQuotasRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeSecretCount.String()
+
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+		if quota == nil {
+			return nil, nil
+		}
+
+		scq := quota.(*quotas.SecretCountQuota)
+
+		nsPath := scq.NamespacePath
+		if scq.NamespacePath == "root" {
+			nsPath = ""
+		}
+
+		data := map[string]interface{}{
+			"type":        qType,
+			"name":        scq.Name,
+			"path":        nsPath + scq.MountPath,
+			"max_secrets": scq.MaxSecrets,
+			"counter":     scq.Count(),
+		}
+
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretCountQuotasDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeSecretCount.String()
+
+		if err := req.Storage.Delete(ctx, quotas.QuotaStoragePath(qType, name)); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.DeleteQuota(ctx, qType, name); err != nil {
+			return nil, err
+		}
+
+		// The mounts of the deleted quota may fall under another one now
+		if _, err := b.Core.recountSecretCountQuotas(ctx, ""); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleSecretCountQuotasRecount() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+
+		quota, err := b.Core.quotaManager.QuotaByName(quotas.TypeSecretCount.String(), name)
+		if err != nil {
+			return nil, err
+		}
+		if quota == nil {
+			return logical.ErrorResponse("secret count quota %q not found", name), logical.ErrInvalidRequest
+		}
+
+		counts, err := b.Core.recountSecretCountQuotas(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"counter": counts[name],
+			},
+		}, nil
+	}
+}
+
 var quotasHelp = map[string][2]string{
 	"quotas-config": {
 		"Create, update and read the quota configuration.",
@@ -458,4 +1051,35 @@ IP address.`,
 		"Lists the names of all the rate limit quotas.",
 		"This list contains quota definitions from all the namespaces.",
 	},
+	"lease-count": {
+		`Get, create or update lease count resource quota for an optional namespace or
+mount.`,
+		`A lease count quota limits the number of leases which may exist at any given
+time. A lease count quota can be created at the root level or defined on a
+namespace or mount by specifying a 'path'. When 'per_entity' is set, the limit
+applies to the leases created by each identity entity separately, with the
+leases of tokens without an entity sharing a single limit.`,
+	},
+	"lease-count-list": {
+		"Lists the names of all the lease count quotas.",
+		"This list contains quota definitions from all the namespaces.",
+	},
+	"secret-count": {
+		`Get, create or update secret count resource quota for an optional namespace or
+KV mount.`,
+		`A secret count quota limits the number of secrets which may exist in KV mounts.
+A secret count quota can be created for all the KV mounts of a namespace, or
+for a single KV mount by specifying a 'path'. Writes which would create a new
+secret over the limit are rejected. In KV version 2, a secret is removed, and
+stops counting against the quota, when its metadata is deleted.`,
+	},
+	"secret-count-list": {
+		"Lists the names of all the secret count quotas.",
+		"This list contains quota definitions from all the namespaces.",
+	},
+	"secret-count-recount": {
+		"Count the secrets of a secret count quota again.",
+		`Walks the KV mounts the quota applies to and replaces its counter with the
+number of secrets found, correcting any drift.`,
+	},
 }
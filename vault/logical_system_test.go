@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -21,6 +22,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	semver "github.com/hashicorp/go-version"
 	"github.com/mitchellh/mapstructure"
+	auditFile "github.com/openbao/openbao/builtin/audit/file"
 	credUserpass "github.com/openbao/openbao/builtin/credential/userpass"
 	"github.com/openbao/openbao/helper/builtinplugins"
 	"github.com/openbao/openbao/helper/identity"
@@ -34,9 +36,11 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/jsonutil"
 	"github.com/openbao/openbao/sdk/v2/helper/logging"
 	"github.com/openbao/openbao/sdk/v2/helper/pluginutil"
+	"github.com/openbao/openbao/sdk/v2/helper/strutil"
 	"github.com/openbao/openbao/sdk/v2/helper/testhelpers/schema"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/openbao/openbao/version"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSystemBackend_RootPaths(t *testing.T) {
@@ -45,8 +49,10 @@ func TestSystemBackend_RootPaths(t *testing.T) {
 		"remount",
 		"audit",
 		"audit/*",
+		"audit-salt/*",
 		"raw",
 		"raw/*",
+		"raw-batch",
 		"rotate",
 		"config/cors",
 		"config/auditing/*",
@@ -54,11 +60,13 @@ func TestSystemBackend_RootPaths(t *testing.T) {
 		"plugins/catalog/*",
 		"revoke-prefix/*",
 		"revoke-force/*",
+		"revoke-batch",
 		"leases/revoke-prefix/*",
 		"leases/revoke-force/*",
 		"leases/lookup/*",
 		"leases",
 		"internal/inspect/*",
+		"internal/cache/physical",
 	}
 
 	b := testSystemBackend(t)
@@ -187,6 +195,7 @@ func TestSystemBackend_mounts(t *testing.T) {
 		"secret/": map[string]interface{}{
 			"type":                    "kv",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "key/value secret storage",
 			"accessor":                resp.Data["secret/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["secret/"].(map[string]interface{})["uuid"],
@@ -208,6 +217,7 @@ func TestSystemBackend_mounts(t *testing.T) {
 		"sys/": map[string]interface{}{
 			"type":                    "system",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "system endpoints used for control, policy and debugging",
 			"accessor":                resp.Data["sys/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["sys/"].(map[string]interface{})["uuid"],
@@ -228,6 +238,7 @@ func TestSystemBackend_mounts(t *testing.T) {
 			"description":             "per-token private secret storage",
 			"type":                    "cubbyhole",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"accessor":                resp.Data["cubbyhole/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["cubbyhole/"].(map[string]interface{})["uuid"],
 			"config": map[string]interface{}{
@@ -246,6 +257,7 @@ func TestSystemBackend_mounts(t *testing.T) {
 			"description":             "identity store",
 			"type":                    "identity",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"accessor":                resp.Data["identity/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["identity/"].(map[string]interface{})["uuid"],
 			"config": map[string]interface{}{
@@ -326,6 +338,7 @@ func TestSystemBackend_mount(t *testing.T) {
 		"secret/": map[string]interface{}{
 			"type":                    "kv",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "key/value secret storage",
 			"accessor":                resp.Data["secret/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["secret/"].(map[string]interface{})["uuid"],
@@ -347,6 +360,7 @@ func TestSystemBackend_mount(t *testing.T) {
 		"sys/": map[string]interface{}{
 			"type":                    "system",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "system endpoints used for control, policy and debugging",
 			"accessor":                resp.Data["sys/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["sys/"].(map[string]interface{})["uuid"],
@@ -367,6 +381,7 @@ func TestSystemBackend_mount(t *testing.T) {
 			"description":             "per-token private secret storage",
 			"type":                    "cubbyhole",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"accessor":                resp.Data["cubbyhole/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["cubbyhole/"].(map[string]interface{})["uuid"],
 			"config": map[string]interface{}{
@@ -385,6 +400,7 @@ func TestSystemBackend_mount(t *testing.T) {
 			"description":             "identity store",
 			"type":                    "identity",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"accessor":                resp.Data["identity/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["identity/"].(map[string]interface{})["uuid"],
 			"config": map[string]interface{}{
@@ -404,6 +420,7 @@ func TestSystemBackend_mount(t *testing.T) {
 			"description":             "",
 			"type":                    "kv",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"accessor":                resp.Data["prod/secret/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["prod/secret/"].(map[string]interface{})["uuid"],
 			"config": map[string]interface{}{
@@ -602,10 +619,10 @@ func TestSystemBackend_PathCapabilities(t *testing.T) {
 	testMakeServiceTokenViaBackend(t, core.tokenStore, rootToken, "tokenid", "", []string{"test"})
 
 	nonRootCheckFunc := func(t *testing.T, resp *logical.Response) {
-		expected1 := []string{"create", "sudo", "update"}
+		expected1 := []string{"create", "patch", "sudo", "update"}
 		expected2 := expected1
-		expected3 := []string{"update"}
-		expected4 := []string{"delete", "read", "update"}
+		expected3 := []string{"patch", "update"}
+		expected4 := []string{"delete", "patch", "read", "update"}
 
 		if !reflect.DeepEqual(resp.Data[path1], expected1) ||
 			!reflect.DeepEqual(resp.Data[path2], expected2) ||
@@ -740,7 +757,7 @@ func testCapabilities(t *testing.T, endpoint string) {
 	}
 
 	actual = resp.Data["capabilities"]
-	expected = []string{"create", "sudo", "update"}
+	expected = []string{"create", "patch", "sudo", "update"}
 	if !reflect.DeepEqual(actual, expected) {
 		t.Fatalf("bad: got\n%#v\nexpected\n%#v\n", actual, expected)
 	}
@@ -798,7 +815,7 @@ func TestSystemBackend_CapabilitiesAccessor_BC(t *testing.T) {
 	}
 
 	actual = resp.Data["capabilities"]
-	expected = []string{"create", "sudo", "update"}
+	expected = []string{"create", "patch", "sudo", "update"}
 	if !reflect.DeepEqual(actual, expected) {
 		t.Fatalf("bad: got\n%#v\nexpected\n%#v\n", actual, expected)
 	}
@@ -1972,6 +1989,120 @@ func TestSystemBackend_revokePrefixAuth_origUrl(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_revokeBatch(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+	ts := core.tokenStore
+
+	makeToken := func(entityID string) *logical.TokenEntry {
+		t.Helper()
+		te := &logical.TokenEntry{
+			Path:        "auth/token/create",
+			Policies:    []string{"default"},
+			TTL:         time.Hour,
+			EntityID:    entityID,
+			NamespaceID: namespace.RootNamespaceID,
+		}
+		testMakeTokenDirectly(t, ts, te)
+		require.NoError(t, ts.expiration.RegisterAuth(ctx, te, &logical.Auth{
+			ClientToken: te.ID,
+			Accessor:    te.Accessor,
+			LeaseOptions: logical.LeaseOptions{
+				TTL: time.Hour,
+			},
+		}, ""))
+		return te
+	}
+	revokeBatch := func(token string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		return core.HandleRequest(ctx, &logical.Request{
+			Operation:   logical.UpdateOperation,
+			Path:        "sys/revoke-batch",
+			ClientToken: token,
+			Data:        data,
+		})
+	}
+	requireRevoked := func(te *logical.TokenEntry, revoked bool) {
+		t.Helper()
+		out, err := ts.Lookup(ctx, te.ID)
+		require.NoError(t, err)
+		require.Equal(t, revoked, out == nil)
+	}
+
+	var entityTokens []*logical.TokenEntry
+	for i := 0; i < 5; i++ {
+		entityTokens = append(entityTokens, makeToken("entity1"))
+	}
+	other := makeToken("entity2")
+
+	for _, data := range []map[string]interface{}{
+		{},
+		{"entity_id": "entity1", "accessors": other.Accessor},
+		{"accessors": other.Accessor, "after": "foo"},
+		{"accessors": other.Accessor + ",foo", "limit": 1},
+	} {
+		_, err := revokeBatch(root, data)
+		require.ErrorIs(t, err, logical.ErrInvalidRequest, "data: %v", data)
+	}
+
+	// The tokens of an entity are revoked in bounded, resumable steps.
+	var revoked []string
+	var after string
+	for steps := 0; ; steps++ {
+		require.Less(t, steps, 5)
+		resp, err := revokeBatch(root, map[string]interface{}{
+			"entity_id": "entity1",
+			"limit":     2,
+			"after":     after,
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Data["errors"])
+		revoked = append(revoked, resp.Data["revoked"].([]string)...)
+
+		next, ok := resp.Data["after"]
+		if !ok {
+			break
+		}
+		after = next.(string)
+	}
+	require.Len(t, revoked, len(entityTokens))
+	for _, te := range entityTokens {
+		require.Contains(t, revoked, te.Accessor)
+		requireRevoked(te, true)
+	}
+	requireRevoked(other, false)
+
+	// Failures are reported by accessor.
+	resp, err := revokeBatch(root, map[string]interface{}{
+		"accessors": []string{other.Accessor, "foo", entityTokens[0].Accessor},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{other.Accessor}, resp.Data["revoked"])
+	require.Len(t, resp.Data["errors"], 2)
+	require.Contains(t, resp.Data["errors"], "foo")
+	require.NotEmpty(t, resp.Warnings)
+	requireRevoked(other, true)
+
+	// Batch revocation requires sudo.
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `path "sys/revoke-batch" { capabilities = ["update"] }`)
+	require.NoError(t, err)
+	policy.Name = "revoke-batch"
+	require.NoError(t, core.policyStore.SetPolicy(ctx, policy))
+	te := &logical.TokenEntry{
+		Path:        "auth/token/create",
+		Policies:    []string{"revoke-batch"},
+		TTL:         time.Hour,
+		NamespaceID: namespace.RootNamespaceID,
+	}
+	testMakeTokenDirectly(t, ts, te)
+	victim := makeToken("entity3")
+	_, err = revokeBatch(te.ID, map[string]interface{}{
+		"entity_id": "entity3",
+	})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	requireRevoked(victim, false)
+}
+
 func TestSystemBackend_authTable(t *testing.T) {
 	b := testSystemBackend(t)
 	req := logical.TestRequest(t, logical.ReadOperation, "auth")
@@ -1990,6 +2121,7 @@ func TestSystemBackend_authTable(t *testing.T) {
 		"token/": map[string]interface{}{
 			"type":                    "token",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "token based credentials",
 			"accessor":                resp.Data["token/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["token/"].(map[string]interface{})["uuid"],
@@ -2076,6 +2208,7 @@ func TestSystemBackend_enableAuth(t *testing.T) {
 		"foo/": map[string]interface{}{
 			"type":                    "noop",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "",
 			"accessor":                resp.Data["foo/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["foo/"].(map[string]interface{})["uuid"],
@@ -2095,6 +2228,7 @@ func TestSystemBackend_enableAuth(t *testing.T) {
 		"token/": map[string]interface{}{
 			"type":                    "token",
 			"external_entropy_access": false,
+			"dedicated_storage_key":   false,
 			"description":             "token based credentials",
 			"accessor":                resp.Data["token/"].(map[string]interface{})["accessor"],
 			"uuid":                    resp.Data["token/"].(map[string]interface{})["uuid"],
@@ -2518,6 +2652,90 @@ func TestSystemBackend_auditHash(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_auditSalt(t *testing.T) {
+	c, b, root := testCoreSystemBackend(t)
+	c.auditBackends["file"] = auditFile.Factory
+	ctx := namespace.RootContext(nil)
+	logPath := filepath.Join(t.TempDir(), "audit.log")
+
+	handle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, op, path)
+		req.Data = data
+		resp, err := b.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		if resp != nil && !resp.IsError() {
+			schema.ValidateResponse(
+				t,
+				schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+				resp,
+				true,
+			)
+		}
+		return resp
+	}
+	hash := func(epoch int) string {
+		t.Helper()
+		resp := handle(logical.UpdateOperation, "audit-hash/foo", map[string]interface{}{
+			"input": "bar",
+			"epoch": epoch,
+		})
+		require.False(t, resp.IsError(), "%#v", resp)
+		return resp.Data["hash"].(string)
+	}
+	lastEpoch := func() string {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "sys/mounts")
+		req.ClientToken = root
+		_, err := c.HandleRequest(ctx, req)
+		require.NoError(t, err)
+
+		raw, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+		var entry map[string]interface{}
+		require.NoError(t, jsonutil.DecodeJSON([]byte(lines[len(lines)-1]), &entry))
+		require.Equal(t, "response", entry["type"])
+		return entry["hmac_epoch"].(json.Number).String()
+	}
+
+	handle(logical.UpdateOperation, "audit/foo", map[string]interface{}{
+		"type":    "file",
+		"options": map[string]string{"file_path": logPath},
+	})
+
+	resp := handle(logical.ReadOperation, "audit-salt/foo", nil)
+	require.Equal(t, 1, resp.Data["current_epoch"])
+	require.Len(t, resp.Data["epochs"], 1)
+	first := hash(0)
+	require.Equal(t, "1", lastEpoch())
+
+	resp = handle(logical.UpdateOperation, "audit-salt/foo/rotate", nil)
+	require.Equal(t, 2, resp.Data["current_epoch"])
+	require.Equal(t, "2", lastEpoch())
+
+	// Values can still be hashed with the salt of a past epoch
+	require.NotEqual(t, first, hash(0))
+	require.Equal(t, hash(0), hash(2))
+	require.Equal(t, first, hash(1))
+	resp = handle(logical.UpdateOperation, "audit-hash/foo", map[string]interface{}{
+		"input": "bar",
+		"epoch": 3,
+	})
+	require.True(t, resp.IsError())
+
+	resp = handle(logical.ReadOperation, "audit-salt/foo", nil)
+	require.Equal(t, 2, resp.Data["current_epoch"])
+	epochs := resp.Data["epochs"].([]map[string]interface{})
+	require.Len(t, epochs, 2)
+	require.NotContains(t, epochs[0], "created_time")
+	require.Equal(t, 2, epochs[1]["epoch"])
+	require.NotEmpty(t, epochs[1]["created_time"])
+
+	resp = handle(logical.UpdateOperation, "audit-salt/missing/rotate", nil)
+	require.True(t, resp.IsError())
+}
+
 func TestSystemBackend_enableAudit_invalid(t *testing.T) {
 	b := testSystemBackend(t)
 	req := logical.TestRequest(t, logical.UpdateOperation, "audit/foo")
@@ -3169,6 +3387,95 @@ func TestSystemBackend_rawDelete(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_rawBatch(t *testing.T) {
+	_, b, _ := testCoreSystemBackendRaw(t)
+	ctx := namespace.RootContext(nil)
+
+	batch := func(ops ...map[string]interface{}) []map[string]interface{} {
+		t.Helper()
+		operations := make([]interface{}, len(ops))
+		for i, op := range ops {
+			operations[i] = op
+		}
+		req := logical.TestRequest(t, logical.UpdateOperation, "raw-batch")
+		req.Data["operations"] = operations
+		resp, err := b.HandleRequest(ctx, req)
+		if err != nil || resp.IsError() {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		schema.ValidateResponse(
+			t,
+			schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+			resp,
+			true,
+		)
+		return resp.Data["results"].([]map[string]interface{})
+	}
+
+	results := batch(
+		map[string]interface{}{"operation": "put", "path": "test/a", "value": "foo"},
+		map[string]interface{}{"operation": "put", "path": "test/b", "value": "YmFy", "encoding": "base64", "compression_type": "gzip"},
+		map[string]interface{}{"operation": "put", "path": keyringPath, "value": "foo"},
+		map[string]interface{}{"operation": "get", "path": "test/a"},
+		map[string]interface{}{"operation": "get", "path": "test/b"},
+		map[string]interface{}{"operation": "get", "path": "test/b", "compressed": false},
+		map[string]interface{}{"operation": "get", "path": "test/missing"},
+		map[string]interface{}{"operation": "get", "path": keyringPath},
+		map[string]interface{}{"operation": "delete", "path": "test/a"},
+		map[string]interface{}{"operation": "get", "path": "test/a"},
+		map[string]interface{}{"operation": "list", "path": "test/"},
+	)
+	require.Len(t, results, 11)
+
+	// Operations on protected paths and invalid operations fail on their own
+	for i, result := range results {
+		switch i {
+		case 2, 7, 10:
+			require.Contains(t, result, "error", "result %d", i)
+		default:
+			require.NotContains(t, result, "error", "result %d", i)
+		}
+	}
+	require.Equal(t, "foo", results[3]["value"])
+	require.Equal(t, "bar", results[4]["value"])
+	require.NotEqual(t, "bar", results[5]["value"])
+	require.NotContains(t, results[6], "value")
+	require.NotContains(t, results[9], "value")
+
+	// Puts keep the compression of existing entries
+	results = batch(
+		map[string]interface{}{"operation": "put", "path": "test/b", "value": "baz"},
+		map[string]interface{}{"operation": "get", "path": "test/b", "compressed": false},
+		map[string]interface{}{"operation": "get", "path": "test/b"},
+	)
+	require.NotEqual(t, "baz", results[1]["value"])
+	require.Equal(t, "baz", results[2]["value"])
+
+	// The number of operations is capped
+	ops := make([]interface{}, rawBatchMaxOperations+1)
+	for i := range ops {
+		ops[i] = map[string]interface{}{"operation": "get", "path": "test/b"}
+	}
+	for _, operations := range [][]interface{}{nil, ops} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "raw-batch")
+		req.Data["operations"] = operations
+		resp, err := b.HandleRequest(ctx, req)
+		require.ErrorIs(t, err, logical.ErrInvalidRequest)
+		require.True(t, resp.IsError())
+	}
+}
+
+func TestSystemBackend_rawBatch_Disabled(t *testing.T) {
+	_, b, _ := testCoreSystemBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "raw-batch")
+	req.Data["operations"] = []interface{}{
+		map[string]interface{}{"operation": "get", "path": "test/a"},
+	}
+	_, err := b.HandleRequest(namespace.RootContext(nil), req)
+	require.ErrorIs(t, err, logical.ErrUnsupportedPath)
+}
+
 func TestSystemBackend_keyStatus(t *testing.T) {
 	b := testSystemBackend(t)
 	req := logical.TestRequest(t, logical.ReadOperation, "key-status")
@@ -3774,6 +4081,7 @@ func TestSystemBackend_InternalUIMounts(t *testing.T) {
 			"secret/": map[string]interface{}{
 				"type":                    "kv",
 				"external_entropy_access": false,
+				"dedicated_storage_key":   false,
 				"description":             "key/value secret storage",
 				"accessor":                resp.Data["secret"].(map[string]interface{})["secret/"].(map[string]interface{})["accessor"],
 				"uuid":                    resp.Data["secret"].(map[string]interface{})["secret/"].(map[string]interface{})["uuid"],
@@ -3795,6 +4103,7 @@ func TestSystemBackend_InternalUIMounts(t *testing.T) {
 			"sys/": map[string]interface{}{
 				"type":                    "system",
 				"external_entropy_access": false,
+				"dedicated_storage_key":   false,
 				"description":             "system endpoints used for control, policy and debugging",
 				"accessor":                resp.Data["secret"].(map[string]interface{})["sys/"].(map[string]interface{})["accessor"],
 				"uuid":                    resp.Data["secret"].(map[string]interface{})["sys/"].(map[string]interface{})["uuid"],
@@ -3815,6 +4124,7 @@ func TestSystemBackend_InternalUIMounts(t *testing.T) {
 				"description":             "per-token private secret storage",
 				"type":                    "cubbyhole",
 				"external_entropy_access": false,
+				"dedicated_storage_key":   false,
 				"accessor":                resp.Data["secret"].(map[string]interface{})["cubbyhole/"].(map[string]interface{})["accessor"],
 				"uuid":                    resp.Data["secret"].(map[string]interface{})["cubbyhole/"].(map[string]interface{})["uuid"],
 				"config": map[string]interface{}{
@@ -3833,6 +4143,7 @@ func TestSystemBackend_InternalUIMounts(t *testing.T) {
 				"description":             "identity store",
 				"type":                    "identity",
 				"external_entropy_access": false,
+				"dedicated_storage_key":   false,
 				"accessor":                resp.Data["secret"].(map[string]interface{})["identity/"].(map[string]interface{})["accessor"],
 				"uuid":                    resp.Data["secret"].(map[string]interface{})["identity/"].(map[string]interface{})["uuid"],
 				"config": map[string]interface{}{
@@ -3860,6 +4171,7 @@ func TestSystemBackend_InternalUIMounts(t *testing.T) {
 				},
 				"type":                    "token",
 				"external_entropy_access": false,
+				"dedicated_storage_key":   false,
 				"description":             "token based credentials",
 				"accessor":                resp.Data["auth"].(map[string]interface{})["token/"].(map[string]interface{})["accessor"],
 				"uuid":                    resp.Data["auth"].(map[string]interface{})["token/"].(map[string]interface{})["uuid"],
@@ -4023,6 +4335,53 @@ func TestSystemBackend_InternalUIMount(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_InternalCachePhysical(t *testing.T) {
+	core, b, rootToken := testCoreSystemBackend(t)
+	systemBackend := b.(*SystemBackend)
+
+	read := func() *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "internal/cache/physical")
+		req.ClientToken = rootToken
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("Bad %#v %#v", err, resp)
+		}
+		schema.ValidateResponse(
+			t,
+			schema.GetResponseSchema(t, systemBackend.Route(req.Path), req.Operation),
+			resp,
+			true,
+		)
+		return resp
+	}
+
+	resp := read()
+	if resp.Data["enabled"] != true {
+		t.Fatalf("expected the cache to be enabled: %#v", resp.Data)
+	}
+	if resp.Data["entries"].(int) == 0 {
+		t.Fatalf("expected cached entries: %#v", resp.Data)
+	}
+	if !strutil.StrListContains(resp.Data["exceptions"].([]string), "core/seal-config") {
+		t.Fatalf("expected the default exceptions: %#v", resp.Data)
+	}
+
+	// Exceptions added at runtime are reported
+	core.physicalCache.(interface{ AddCacheExceptions([]string) }).AddCacheExceptions([]string{"sys/runtime/"})
+	resp = read()
+	if !strutil.StrListContains(resp.Data["exceptions"].([]string), "sys/runtime/") {
+		t.Fatalf("expected the runtime exception: %#v", resp.Data)
+	}
+
+	// Only aggregate statistics are returned
+	for key, value := range resp.Data {
+		if _, ok := value.(map[string]interface{}); ok {
+			t.Fatalf("unexpected nested field %q: %#v", key, value)
+		}
+	}
+}
+
 func TestSystemBackend_OpenAPI(t *testing.T) {
 	_, b, rootToken := testCoreSystemBackend(t)
 
@@ -5934,3 +6293,141 @@ func TestCanUnseal_WithNonExistentBuiltinPluginVersion_InMountStorage(t *testing
 		}
 	}
 }
+
+func TestSystemBackend_PoliciesExplain(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	request := func(token string, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := core.HandleRequest(ctx, &logical.Request{
+			Operation:   op,
+			Path:        path,
+			ClientToken: token,
+			Data:        data,
+		})
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "resp: %#v", resp)
+		return resp
+	}
+	for name, policy := range map[string]string{
+		"kv-read":  `path "secret/*" { capabilities = ["read", "list"] }`,
+		"kv-write": `path "secret/*" { capabilities = ["update"] }`,
+		"kv-deny":  `path "secret/private" { capabilities = ["deny"] }`,
+		"kv-owner": `path "secret/{{identity.entity.id}}/*" { capabilities = ["create", "update"] }`,
+		"explain": `
+path "sys/policies/explain" { capabilities = ["update"] }
+path "sys/policies/acl/kv-read" { capabilities = ["read"] }`,
+	} {
+		request(root, logical.UpdateOperation, "sys/policies/acl/"+name, map[string]interface{}{"policy": policy})
+	}
+	explain := func(token string, data map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		return request(token, logical.UpdateOperation, "sys/policies/explain", data).Data
+	}
+
+	data := explain(root, map[string]interface{}{
+		"policies":  "kv-read,kv-write",
+		"path":      "secret/foo",
+		"operation": "update",
+	})
+	require.Equal(t, true, data["allowed"])
+	require.Equal(t, "update", data["capability"])
+	require.Equal(t, "secret/*", data["matched_path"])
+	require.Equal(t, []string{"read", "list", "update", "patch"}, data["capabilities"])
+	require.Equal(t, []map[string]interface{}{
+		{"policy": "kv-read", "namespace_path": "", "inline": false, "capabilities": []string{"read", "list"}},
+		{"policy": "kv-write", "namespace_path": "", "inline": false, "capabilities": []string{"update"}},
+	}, data["rules"])
+
+	data = explain(root, map[string]interface{}{
+		"policies":  []string{"kv-read", "kv-deny"},
+		"path":      "secret/private",
+		"operation": "read",
+	})
+	require.Equal(t, false, data["allowed"])
+	require.Equal(t, "secret/private", data["matched_path"])
+	require.Contains(t, data["reason"], "explicitly denies")
+
+	data = explain(root, map[string]interface{}{
+		"policies":  "kv-read",
+		"path":      "kv/foo",
+		"operation": "read",
+	})
+	require.Equal(t, false, data["allowed"])
+	require.NotContains(t, data, "matched_path")
+	require.Empty(t, data["rules"])
+
+	// Templated policies are resolved against the identity of the token.
+	resp := request(root, logical.UpdateOperation, "identity/entity", map[string]interface{}{"name": "owner"})
+	entityID := resp.Data["id"].(string)
+	owner := &logical.TokenEntry{
+		Path:     "auth/token/create",
+		Policies: []string{"kv-owner", "explain"},
+		EntityID: entityID,
+		TTL:      time.Hour,
+	}
+	testMakeTokenDirectly(t, core.tokenStore, owner)
+	data = explain(root, map[string]interface{}{
+		"token":     owner.ID,
+		"path":      "secret/" + entityID + "/foo",
+		"operation": "create",
+	})
+	require.Equal(t, true, data["allowed"])
+	require.Equal(t, "secret/"+entityID+"/*", data["matched_path"])
+
+	// The rules of the policies the caller cannot read are redacted.
+	caller := &logical.TokenEntry{
+		Path:         "auth/token/create",
+		Policies:     []string{"explain"},
+		TTL:          time.Hour,
+		InlinePolicy: `path "secret/inline" { capabilities = ["read"] }`,
+	}
+	testMakeTokenDirectly(t, core.tokenStore, caller)
+	data = explain(caller.ID, map[string]interface{}{
+		"policies":  "kv-write",
+		"path":      "secret/foo",
+		"operation": "update",
+	})
+	require.Equal(t, true, data["allowed"])
+	require.NotContains(t, data, "matched_path")
+	require.Empty(t, data["rules"])
+	require.Equal(t, 1, data["redacted_rules"])
+
+	data = explain(caller.ID, map[string]interface{}{
+		"policies":  "kv-read,kv-write",
+		"path":      "secret/foo",
+		"operation": "read",
+	})
+	require.Equal(t, "secret/*", data["matched_path"])
+	require.Len(t, data["rules"], 1)
+	require.Equal(t, 1, data["redacted_rules"])
+
+	// Inline policies are only shown to the holder of the token.
+	data = explain(caller.ID, map[string]interface{}{
+		"path":      "secret/inline",
+		"operation": "read",
+	})
+	require.Equal(t, true, data["allowed"])
+	require.Equal(t, "secret/inline", data["matched_path"])
+	require.Len(t, data["rules"], 1)
+	data = explain(owner.ID, map[string]interface{}{
+		"token":     caller.ID,
+		"path":      "secret/inline",
+		"operation": "read",
+	})
+	require.Equal(t, true, data["allowed"])
+	require.Empty(t, data["rules"])
+
+	resp, err := core.HandleRequest(ctx, &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        "sys/policies/explain",
+		ClientToken: root,
+		Data: map[string]interface{}{
+			"path":      "secret/foo",
+			"operation": "sudo",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
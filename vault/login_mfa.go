@@ -37,6 +37,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/identitytpl"
 	"github.com/openbao/openbao/sdk/v2/helper/jsonutil"
 	"github.com/openbao/openbao/sdk/v2/helper/parseutil"
+	"github.com/openbao/openbao/sdk/v2/helper/policyutil"
 	"github.com/openbao/openbao/sdk/v2/helper/strutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/patrickmn/go-cache"
@@ -758,6 +759,12 @@ func (b *LoginMFABackend) handleMFALoginValidate(ctx context.Context, req *logic
 	if err != nil || cachedResponseAuth == nil {
 		return logical.ErrorResponse("invalid request ID"), nil
 	}
+
+	// Expired login requests are removed periodically; make sure one which
+	// has not been removed yet cannot be validated either.
+	if time.Since(cachedResponseAuth.TimeOfStorage) > defaultMFAAuthResponseTTL {
+		return logical.ErrorResponse("invalid request ID"), nil
+	}
 	defer func() {
 		// Only if retErr is NOT nil, then push back the valid entry
 		if retErr == nil {
@@ -784,7 +791,7 @@ func (b *LoginMFABackend) handleMFALoginValidate(ctx context.Context, req *logic
 	}
 
 	// finding the MFAEnforcement config that matches our ns. ns could be root as well
-	matchedMfaEnforcementList, err := b.Core.buildMFAEnforcementConfigList(ctx, entity, cachedResponseAuth.RequestPath)
+	matchedMfaEnforcementList, _, err := b.Core.buildMFAEnforcementConfigList(ctx, entity, cachedResponseAuth.CachedAuth, cachedResponseAuth.RequestPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find MFAEnforcement configuration")
 	}
@@ -944,7 +951,7 @@ func (i *IdentityStore) handleMFALoginEnforcementUpdate(ctx context.Context, req
 	}
 	eConfig.MFAMethodIDs = mfaMethodIds.([]string)
 
-	oneOfLastFour := false
+	hasCondition := false
 	authMethodAccessors, ok := d.GetOk("auth_method_accessors")
 	if ok {
 		for _, accessor := range authMethodAccessors.([]string) {
@@ -959,7 +966,7 @@ func (i *IdentityStore) handleMFALoginEnforcementUpdate(ctx context.Context, req
 			}
 		}
 		eConfig.AuthMethodAccessors = authMethodAccessors.([]string)
-		oneOfLastFour = true
+		hasCondition = true
 	}
 
 	authMethodTypes, ok := d.GetOk("auth_method_types")
@@ -976,7 +983,7 @@ func (i *IdentityStore) handleMFALoginEnforcementUpdate(ctx context.Context, req
 			}
 		}
 		eConfig.AuthMethodTypes = authMethodTypes.([]string)
-		oneOfLastFour = true
+		hasCondition = true
 	}
 
 	identityGroupIds, ok := d.GetOk("identity_group_ids")
@@ -991,7 +998,7 @@ func (i *IdentityStore) handleMFALoginEnforcementUpdate(ctx context.Context, req
 			}
 		}
 		eConfig.IdentityGroupIds = identityGroupIds.([]string)
-		oneOfLastFour = true
+		hasCondition = true
 	}
 
 	identityEntityIds, ok := d.GetOk("identity_entity_ids")
@@ -1006,11 +1013,23 @@ func (i *IdentityStore) handleMFALoginEnforcementUpdate(ctx context.Context, req
 			}
 		}
 		eConfig.IdentityEntityIDs = identityEntityIds.([]string)
-		oneOfLastFour = true
+		hasCondition = true
+	}
+
+	policies, ok := d.GetOk("policies")
+	if ok {
+		eConfig.Policies = policyutil.SanitizePolicies(policies.([]string), policyutil.DoNotAddDefaultPolicy)
+		if len(eConfig.Policies) > 0 {
+			hasCondition = true
+		}
 	}
 
-	if !oneOfLastFour {
-		return logical.ErrorResponse("One of auth_method_accessors, auth_method_types, identity_group_ids, identity_entity_ids must be specified"), nil
+	if !hasCondition {
+		return logical.ErrorResponse("One of auth_method_accessors, auth_method_types, identity_group_ids, identity_entity_ids, policies must be specified"), nil
+	}
+
+	if exemptBatchTokens, ok := d.GetOk("exempt_batch_tokens"); ok {
+		eConfig.ExemptBatchTokens = exemptBatchTokens.(bool)
 	}
 
 	// Store the config
@@ -1422,6 +1441,8 @@ func (b *LoginMFABackend) mfaLoginEnforcementConfigToMap(eConfig *mfa.MFAEnforce
 	resp["auth_method_types"] = append([]string{}, eConfig.AuthMethodTypes...)
 	resp["identity_group_ids"] = append([]string{}, eConfig.IdentityGroupIds...)
 	resp["identity_entity_ids"] = append([]string{}, eConfig.IdentityEntityIDs...)
+	resp["policies"] = append([]string{}, eConfig.Policies...)
+	resp["exempt_batch_tokens"] = eConfig.ExemptBatchTokens
 	resp["id"] = eConfig.ID
 	return resp, nil
 }
@@ -1722,23 +1743,36 @@ func (c *Core) validateLoginMFAInternal(ctx context.Context, methodID string, en
 	}
 }
 
-func (c *Core) buildMFAEnforcementConfigList(ctx context.Context, entity *identity.Entity, reqPath string) ([]*mfa.MFAEnforcementConfig, error) {
+// buildMFAEnforcementConfigList returns the login enforcements which apply to
+// a login, along with the ones it is exempt from as it issues a batch token.
+func (c *Core) buildMFAEnforcementConfigList(ctx context.Context, entity *identity.Entity, auth *logical.Auth, reqPath string) ([]*mfa.MFAEnforcementConfig, []*mfa.MFAEnforcementConfig, error) {
 	ns, err := namespace.FromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get namespace from context. %s, %v", "error", err)
+		return nil, nil, fmt.Errorf("failed to get namespace from context. %s, %v", "error", err)
 	}
 
 	eConfigIter, err := c.loginMFABackend.MemDBMFALoginEnforcementConfigIterator()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	me := c.router.MatchingMountEntry(ctx, reqPath)
 	if me == nil {
-		return nil, fmt.Errorf("failed to find matching mount entry for path %v", reqPath)
+		return nil, nil, fmt.Errorf("failed to find matching mount entry for path %v", reqPath)
 	}
 
-	var matchedMfaEnforcementConfig []*mfa.MFAEnforcementConfig
+	var matchedMfaEnforcementConfig, exemptMfaEnforcementConfig []*mfa.MFAEnforcementConfig
+	addMatch := func(eConfig *mfa.MFAEnforcementConfig) {
+		if eConfig.ExemptBatchTokens && auth.TokenType == logical.TokenTypeBatch {
+			exemptMfaEnforcementConfig = append(exemptMfaEnforcementConfig, eConfig)
+			return
+		}
+		matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+	}
+
+	// The policies of the token are only computed if an enforcement
+	// depends on them
+	var tokenPolicies []string
 	// finding the MFAEnforcement config that matches our ns. ns could be root as well
 ECONFIG_LOOP:
 	for eConfigRaw := eConfigIter.Next(); eConfigRaw != nil; eConfigRaw = eConfigIter.Next() {
@@ -1748,7 +1782,7 @@ ECONFIG_LOOP:
 		// i.e. is it the req's ns or an ancestor of req's ns?
 		eConfigNS, err := c.NamespaceByID(ctx, eConfig.NamespaceID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to find the MFAEnforcementConfig namespace")
+			return nil, nil, fmt.Errorf("failed to find the MFAEnforcementConfig namespace")
 		}
 
 		if eConfig == nil || eConfigNS == nil || (eConfigNS.ID != ns.ID && !ns.HasParent(eConfigNS)) {
@@ -1759,29 +1793,44 @@ ECONFIG_LOOP:
 		// having mount type/accessor
 		if entity != nil {
 			if entity.NamespaceID != ns.ID {
-				return nil, fmt.Errorf("entity namespace ID is different than the current ns ID")
+				return nil, nil, fmt.Errorf("entity namespace ID is different than the current ns ID")
 			}
 
 			// Check if entityID is in the MFAEnforcement config
 			if strutil.StrListContains(eConfig.IdentityEntityIDs, entity.ID) {
-				matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+				addMatch(eConfig)
 				continue
 			}
 
 			// Retrieve entity groups
 			directGroups, inheritedGroups, err := c.identityStore.groupsByEntityID(entity.ID)
 			if err != nil {
-				return nil, fmt.Errorf("error on retrieving groups by entityID in MFA")
+				return nil, nil, fmt.Errorf("error on retrieving groups by entityID in MFA")
 			}
 			for _, g := range directGroups {
 				if strutil.StrListContains(eConfig.IdentityGroupIds, g.ID) {
-					matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+					addMatch(eConfig)
 					continue ECONFIG_LOOP
 				}
 			}
 			for _, g := range inheritedGroups {
 				if strutil.StrListContains(eConfig.IdentityGroupIds, g.ID) {
-					matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+					addMatch(eConfig)
+					continue ECONFIG_LOOP
+				}
+			}
+		}
+
+		if len(eConfig.Policies) > 0 {
+			if tokenPolicies == nil {
+				tokenPolicies, err = c.loginTokenPolicies(ctx, ns, auth)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			for _, policy := range eConfig.Policies {
+				if strutil.StrListContains(tokenPolicies, policy) {
+					addMatch(eConfig)
 					continue ECONFIG_LOOP
 				}
 			}
@@ -1789,20 +1838,32 @@ ECONFIG_LOOP:
 
 		for _, acc := range eConfig.AuthMethodAccessors {
 			if me != nil && me.Accessor == acc {
-				matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+				addMatch(eConfig)
 				continue ECONFIG_LOOP
 			}
 		}
 
 		for _, authT := range eConfig.AuthMethodTypes {
 			if me != nil && me.Type == authT {
-				matchedMfaEnforcementConfig = append(matchedMfaEnforcementConfig, eConfig)
+				addMatch(eConfig)
 				continue ECONFIG_LOOP
 			}
 		}
 	}
 
-	return matchedMfaEnforcementConfig, nil
+	return matchedMfaEnforcementConfig, exemptMfaEnforcementConfig, nil
+}
+
+// loginTokenPolicies returns the policies of the token a login would issue,
+// including the policies derived from its entity, as LoginCreateToken assigns
+// them.
+func (c *Core) loginTokenPolicies(ctx context.Context, ns *namespace.Namespace, auth *logical.Auth) ([]string, error) {
+	_, identityPolicies, err := c.fetchEntityAndDerivedPolicies(ctx, ns, auth.EntityID, false)
+	if err != nil {
+		return nil, err
+	}
+	tokenPolicies := policyutil.SanitizePolicies(auth.Policies, !auth.NoDefaultPolicy)
+	return policyutil.SanitizePolicies(append(tokenPolicies, identityPolicies[ns.ID]...), policyutil.DoNotAddDefaultPolicy), nil
 }
 
 func formatUsername(format string, alias *identity.Alias, entity *identity.Entity) string {
@@ -332,6 +332,7 @@ type MountEntry struct {
 	Local                 bool              `json:"local"`                             // Local mounts are not replicated or affected by replication
 	SealWrap              bool              `json:"seal_wrap"`                         // Whether to wrap CSPs
 	ExternalEntropyAccess bool              `json:"external_entropy_access,omitempty"` // Whether to allow external entropy source access
+	DedicatedStorageKey   bool              `json:"dedicated_storage_key,omitempty"`   // Whether to encrypt the storage of the mount with a key dedicated to it
 	Tainted               bool              `json:"tainted,omitempty"`                 // Set as a Write-Ahead flag for unmount/remount
 	MountState            string            `json:"mount_state,omitempty"`             // The current mount state.  The only non-empty mount state right now is "unmounting"
 	NamespaceID           string            `json:"namespace_id"`
@@ -364,6 +365,8 @@ type MountConfig struct {
 	TokenType                 logical.TokenType     `json:"token_type,omitempty" structs:"token_type" mapstructure:"token_type"`
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
+	RequestTimeout            time.Duration         `json:"request_timeout,omitempty" structs:"request_timeout" mapstructure:"request_timeout"`
+	AliasCaseInsensitive      bool                  `json:"alias_case_insensitive,omitempty" structs:"alias_case_insensitive" mapstructure:"alias_case_insensitive"`
 
 	// PluginName is the name of the plugin registered in the catalog.
 	//
@@ -399,6 +402,8 @@ type APIMountConfig struct {
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
 	PluginVersion             string                `json:"plugin_version,omitempty" mapstructure:"plugin_version"`
+	RequestTimeout            string                `json:"request_timeout,omitempty" structs:"request_timeout" mapstructure:"request_timeout"`
+	AliasCaseInsensitive      bool                  `json:"alias_case_insensitive,omitempty" structs:"alias_case_insensitive" mapstructure:"alias_case_insensitive"`
 
 	// PluginName is the name of the plugin registered in the catalog.
 	//
@@ -500,6 +505,12 @@ func (e *MountEntry) SyncCache() {
 	} else {
 		e.synthesizedConfigCache.Store("allowed_managed_keys", e.Config.AllowedManagedKeys)
 	}
+
+	if e.Config.RequestTimeout <= 0 {
+		e.synthesizedConfigCache.Delete("request_timeout")
+	} else {
+		e.synthesizedConfigCache.Store("request_timeout", e.Config.RequestTimeout)
+	}
 }
 
 func (entry *MountEntry) Deserialize() map[string]interface{} {
@@ -692,8 +703,14 @@ func (c *Core) mountInternal(ctx context.Context, entry *MountEntry, updateStora
 	newTable := c.mounts.shallowClone()
 	newTable.Entries = append(newTable.Entries, entry)
 	if updateStorage {
+		if err := c.createMountStorageKey(ctx, entry); err != nil {
+			return err
+		}
 		if err := c.persistMounts(ctx, newTable, &entry.Local); err != nil {
 			c.logger.Error("failed to update mount table", "error", err)
+			if err := c.destroyMountStorageKey(ctx, entry); err != nil {
+				c.logger.Error("failed to destroy storage key of failed mount", "error", err)
+			}
 			return logical.CodedError(500, "failed to update mount table")
 		}
 	}
@@ -800,6 +817,7 @@ func (c *Core) unmountInternal(ctx context.Context, path string, updateStorage b
 	// Get the backend/mount entry for this path, used to remove ignored
 	// replication prefixes
 	backend := c.router.MatchingBackend(ctx, path)
+	entry := c.router.MatchingMountEntry(ctx, path)
 
 	// Mark the entry as tainted
 	if err := c.taintMountEntry(ctx, ns.ID, path, updateStorage, true); err != nil {
@@ -840,6 +858,13 @@ func (c *Core) unmountInternal(ctx context.Context, path string, updateStorage b
 	case !updateStorage:
 		// Don't attempt to clear data, replication will handle this
 	default:
+		// Shred the data first, so that it is unreadable even if clearing
+		// the view is interrupted
+		if err := c.destroyMountStorageKey(ctx, entry); err != nil {
+			c.logger.Error("failed to destroy storage key of mount being unmounted", "error", err, "path", path)
+			return err
+		}
+
 		// Have writable storage, remove the whole thing
 		if err := logical.ClearViewWithLogging(ctx, view, c.logger.Named("secrets.deletion").With("namespace", ns.ID, "path", path)); err != nil {
 			c.logger.Error("failed to clear view for path being unmounted", "error", err, "path", path)
@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -475,6 +476,51 @@ func TestCore_Unmount(t *testing.T) {
 	}
 }
 
+func TestCore_Unmount_DedicatedStorageKey(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+	c.logicalBackends["noop"] = func(context.Context, *logical.BackendConfig) (logical.Backend, error) {
+		return &NoopBackend{}, nil
+	}
+
+	me := &MountEntry{
+		Table:               mountTableType,
+		Path:                "test/",
+		Type:                "noop",
+		DedicatedStorageKey: true,
+	}
+	if err := c.mount(ctx, me); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	view := c.router.MatchingStorageByAPIPath(ctx, "test/")
+	if err := view.Put(context.Background(), &logical.StorageEntry{Key: "shredme", Value: []byte("test")}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Keep a copy of the raw value, as a backup of the storage would
+	path := me.ViewPath() + "shredme"
+	raw, err := c.physical.Get(context.Background(), path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if raw == nil || raw.Value[4] != AESGCMVersion3 {
+		t.Fatalf("bad: %#v", raw)
+	}
+
+	if err := c.unmount(ctx, "test/"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Restoring the value does not make it readable again
+	if err := c.physical.Put(context.Background(), raw); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := c.barrier.Get(context.Background(), path); !errors.Is(err, ErrMountKeyDestroyed) {
+		t.Fatalf("expected destroyed key error, got: %v", err)
+	}
+}
+
 func TestCore_Unmount_Cleanup(t *testing.T) {
 	testCore_Unmount_Cleanup(t, false)
 	testCore_Unmount_Cleanup(t, true)
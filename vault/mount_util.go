@@ -4,6 +4,8 @@
 package vault
 
 import (
+	"context"
+	"fmt"
 	"path"
 )
 
@@ -47,3 +49,28 @@ func (c *Core) mountEntrySysView(entry *MountEntry) extendedSystemView {
 
 	return esi
 }
+
+// createMountStorageKey creates the storage key dedicated to the given mount,
+// if it requested one. It must be called before anything is written to the
+// view of the mount.
+func (c *Core) createMountStorageKey(ctx context.Context, entry *MountEntry) error {
+	if !entry.DedicatedStorageKey {
+		return nil
+	}
+	if err := c.barrier.CreateMountKey(ctx, entry.ViewPath(), c.secureRandomReader); err != nil {
+		return fmt.Errorf("failed to create dedicated storage key: %w", err)
+	}
+	return nil
+}
+
+// destroyMountStorageKey destroys the storage key dedicated to the given
+// mount, if any, making the data left in its view unreadable.
+func (c *Core) destroyMountStorageKey(ctx context.Context, entry *MountEntry) error {
+	if entry == nil || !entry.DedicatedStorageKey {
+		return nil
+	}
+	if err := c.barrier.DestroyMountKey(ctx, entry.ViewPath()); err != nil {
+		return fmt.Errorf("failed to destroy dedicated storage key: %w", err)
+	}
+	return nil
+}
@@ -6,11 +6,14 @@ package vault
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/openbao/openbao/helper/namespace"
 	"github.com/openbao/openbao/helper/versions"
 
+	"github.com/armon/go-radix"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
@@ -19,8 +22,9 @@ import (
 )
 
 // reloadMatchingPluginMounts reloads provided mounts, regardless of
-// plugin name, as long as the backend type is plugin.
-func (c *Core) reloadMatchingPluginMounts(ctx context.Context, mounts []string) error {
+// plugin name, as long as the backend type is plugin. Mounts are reloaded
+// gracefully if a drain timeout is given; see reloadBackendGraceful.
+func (c *Core) reloadMatchingPluginMounts(ctx context.Context, mounts []string, drainTimeout time.Duration) error {
 	c.mountsLock.RLock()
 	defer c.mountsLock.RUnlock()
 	c.authLock.RLock()
@@ -60,7 +64,7 @@ func (c *Core) reloadMatchingPluginMounts(ctx context.Context, mounts []string)
 			continue
 		}
 
-		err := c.reloadBackendCommon(ctx, entry, isAuth)
+		err := c.reloadBackend(ctx, entry, isAuth, drainTimeout)
 		if err != nil {
 			errors = multierror.Append(errors, fmt.Errorf("cannot reload plugin on %q: %w", mount, err))
 			continue
@@ -72,8 +76,8 @@ func (c *Core) reloadMatchingPluginMounts(ctx context.Context, mounts []string)
 
 // reloadPlugin reloads all mounted backends that are of
 // plugin pluginName (name of the plugin as registered in
-// the plugin catalog).
-func (c *Core) reloadMatchingPlugin(ctx context.Context, pluginName string) error {
+// the plugin catalog), gracefully if a drain timeout is given.
+func (c *Core) reloadMatchingPlugin(ctx context.Context, pluginName string, drainTimeout time.Duration) error {
 	c.mountsLock.RLock()
 	defer c.mountsLock.RUnlock()
 	c.authLock.RLock()
@@ -91,7 +95,7 @@ func (c *Core) reloadMatchingPlugin(ctx context.Context, pluginName string) erro
 			continue
 		}
 		if entry.Type == pluginName || (entry.Type == "plugin" && entry.Config.PluginName == pluginName) {
-			err := c.reloadBackendCommon(ctx, entry, false)
+			err := c.reloadBackend(ctx, entry, false, drainTimeout)
 			if err != nil {
 				return err
 			}
@@ -107,7 +111,7 @@ func (c *Core) reloadMatchingPlugin(ctx context.Context, pluginName string) erro
 		}
 
 		if entry.Type == pluginName || (entry.Type == "plugin" && entry.Config.PluginName == pluginName) {
-			err := c.reloadBackendCommon(ctx, entry, true)
+			err := c.reloadBackend(ctx, entry, true, drainTimeout)
 			if err != nil {
 				return err
 			}
@@ -118,6 +122,15 @@ func (c *Core) reloadMatchingPlugin(ctx context.Context, pluginName string) erro
 	return nil
 }
 
+// reloadBackend reloads the backend of the mount, gracefully if a drain
+// timeout is given.
+func (c *Core) reloadBackend(ctx context.Context, entry *MountEntry, isAuth bool, drainTimeout time.Duration) error {
+	if drainTimeout > 0 {
+		return c.reloadBackendGraceful(ctx, entry, isAuth, drainTimeout)
+	}
+	return c.reloadBackendCommon(ctx, entry, isAuth)
+}
+
 // reloadBackendCommon is a generic method to reload a backend provided a
 // MountEntry.
 func (c *Core) reloadBackendCommon(ctx context.Context, entry *MountEntry, isAuth bool) error {
@@ -187,18 +200,7 @@ func (c *Core) reloadBackendCommon(ctx context.Context, entry *MountEntry, isAut
 		return fmt.Errorf("nil backend of type %q returned from creation function", entry.Type)
 	}
 
-	// update the entry running version with the configured version, which was verified during registration.
-	entry.RunningVersion = entry.Version
-	if entry.RunningVersion == "" {
-		// don't set the running version to a builtin if it is running as an external plugin
-		if entry.RunningSha256 == "" {
-			if isAuth {
-				entry.RunningVersion = versions.GetBuiltinVersion(consts.PluginTypeCredential, entry.Type)
-			} else {
-				entry.RunningVersion = versions.GetBuiltinVersion(consts.PluginTypeSecrets, entry.Type)
-			}
-		}
-	}
+	entry.RunningVersion = runningPluginVersion(entry, isAuth)
 
 	// update the mount table since we changed the runningSha
 	if oldSha != entry.RunningSha256 && MountTableUpdateStorage {
@@ -242,3 +244,157 @@ func (c *Core) reloadBackendCommon(ctx context.Context, entry *MountEntry, isAut
 
 	return nil
 }
+
+// reloadBackendGraceful reloads the backend of a mount without interrupting
+// the requests it is serving. The new backend is started while the current
+// one keeps serving; new requests to the mount are then held back while
+// those in flight drain, which are aborted if they do not complete within
+// drainTimeout. The backends are only swapped once the new one has been
+// initialized and the mount table updated, so that any failure leaves the
+// current backend serving.
+func (c *Core) reloadBackendGraceful(ctx context.Context, entry *MountEntry, isAuth bool, drainTimeout time.Duration) error {
+	entry.SyncCache()
+
+	if strutil.StrListContains(singletonMounts, entry.Type) {
+		c.logger.Debug("skipping reload of singleton mount", "type", entry.Type)
+		return nil
+	}
+
+	path := entry.Path
+	if isAuth {
+		path = credentialRoutePrefix + path
+	}
+	path = entry.Namespace().Path + path
+
+	raw, ok := c.router.root.Get(path)
+	if !ok {
+		return nil
+	}
+	re := raw.(*routeEntry)
+
+	sysView := c.mountEntrySysView(entry)
+
+	var backend logical.Backend
+	var runningSha string
+	var err error
+	if !isAuth {
+		backend, runningSha, err = c.newLogicalBackend(ctx, entry, sysView, re.storageView)
+	} else {
+		backend, runningSha, err = c.newCredentialBackend(ctx, entry, sysView, re.storageView)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start new backend: %w", err)
+	}
+	if backend == nil {
+		return fmt.Errorf("nil backend of type %q returned from creation function", entry.Type)
+	}
+
+	if err := c.drainRouteEntry(ctx, re, path, drainTimeout); err != nil {
+		backend.Cleanup(ctx)
+		return err
+	}
+	defer re.l.Unlock()
+
+	if err := backend.Initialize(ctx, &logical.InitializationRequest{Storage: re.storageView}); err != nil {
+		backend.Cleanup(ctx)
+		return fmt.Errorf("failed to initialize new backend: %w", err)
+	}
+
+	var rootPaths *radix.Tree
+	var loginPaths *loginPathsEntry
+	if paths := backend.SpecialPaths(); paths != nil {
+		rootPaths = pathsToRadix(paths.Root)
+		loginPaths, err = parseUnauthenticatedPaths(paths.Unauthenticated)
+		if err != nil {
+			backend.Cleanup(ctx)
+			return err
+		}
+	}
+
+	oldSha, oldVersion := entry.RunningSha256, entry.RunningVersion
+	entry.RunningSha256 = runningSha
+	entry.RunningVersion = runningPluginVersion(entry, isAuth)
+
+	// update the mount table since we changed the runningSha
+	if oldSha != entry.RunningSha256 && MountTableUpdateStorage {
+		if isAuth {
+			err = c.persistAuth(ctx, c.auth, &entry.Local)
+		} else {
+			err = c.persistMounts(ctx, c.mounts, &entry.Local)
+		}
+		if err != nil {
+			entry.RunningSha256, entry.RunningVersion = oldSha, oldVersion
+			backend.Cleanup(ctx)
+			return err
+		}
+	}
+
+	oldBackend := re.backend
+	re.backend = backend
+	if rootPaths != nil {
+		re.rootPaths.Store(rootPaths)
+		re.loginPaths.Store(loginPaths)
+	}
+
+	// The old backend is idle now, so its plugin connection is simply
+	// closed rather than reloaded
+	if oldBackend != nil {
+		oldBackend.Cleanup(ctx)
+	}
+
+	return nil
+}
+
+// drainRouteEntry write-locks the route entry once the requests in flight
+// have completed, holding new requests back meanwhile. Requests still in
+// flight after the timeout are aborted with a 503 error. On success, the
+// caller must unlock the route entry.
+func (c *Core) drainRouteEntry(ctx context.Context, re *routeEntry, path string, timeout time.Duration) error {
+	locked := make(chan struct{})
+	go func() {
+		re.l.Lock()
+		close(locked)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		// Let requests through again once the lock has been acquired
+		go func() {
+			<-locked
+			re.l.Unlock()
+		}()
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	c.logger.Warn("timed out draining requests for plugin reload, aborting them", "path", path, "drain_timeout", timeout)
+	re.abortCancel(logical.CodedError(http.StatusServiceUnavailable,
+		fmt.Sprintf("request to %q aborted as the plugin reload timed out waiting for it", path)))
+	<-locked
+
+	// Requests tied to the cancelled context would be aborted for good
+	re.abort, re.abortCancel = context.WithCancelCause(context.Background())
+	return nil
+}
+
+// runningPluginVersion returns the version the backend of the entry runs,
+// which is the configured version verified during registration.
+func runningPluginVersion(entry *MountEntry, isAuth bool) string {
+	if entry.Version != "" {
+		return entry.Version
+	}
+
+	// don't set the running version to a builtin if it is running as an external plugin
+	if entry.RunningSha256 != "" {
+		return ""
+	}
+	if isAuth {
+		return versions.GetBuiltinVersion(consts.PluginTypeCredential, entry.Type)
+	}
+	return versions.GetBuiltinVersion(consts.PluginTypeSecrets, entry.Type)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+func TestCore_ReloadBackendGraceful(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var generation int
+	var failStart bool
+	c.logicalBackends["noop"] = func(context.Context, *logical.BackendConfig) (logical.Backend, error) {
+		if failStart {
+			return nil, errors.New("plugin failed to start")
+		}
+		generation++
+		gen := generation
+		return &NoopBackend{
+			RequestHandler: func(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+				if req.Path == "slow" {
+					started <- struct{}{}
+					select {
+					case <-release:
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					}
+				}
+				return &logical.Response{
+					Data: map[string]interface{}{
+						"generation": gen,
+					},
+				}, nil
+			},
+		}, nil
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/mounts/foo")
+	req.Data["type"] = "noop"
+	req.ClientToken = root
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	route := func(path string) (*logical.Response, error) {
+		return c.router.Route(ctx, &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "foo/" + path,
+		})
+	}
+	assertGeneration := func(expected int) {
+		t.Helper()
+		resp, err := route("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gen := resp.Data["generation"]; gen != expected {
+			t.Fatalf("expected backend generation %d, got %v", expected, gen)
+		}
+	}
+	assertGeneration(1)
+
+	// In-flight requests completing within the drain timeout are unaffected
+	done := make(chan error, 1)
+	go func() {
+		_, err := route("slow")
+		done <- err
+	}()
+	<-started
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	if err := c.reloadMatchingPluginMounts(ctx, []string{"foo"}, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("drained request failed: %v", err)
+	}
+	assertGeneration(2)
+
+	// Those exceeding it are aborted
+	release = make(chan struct{})
+	defer close(release)
+	go func() {
+		_, err := route("slow")
+		done <- err
+	}()
+	<-started
+	if err := c.reloadMatchingPluginMounts(ctx, []string{"foo"}, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	err := <-done
+	var coded logical.HTTPCodedError
+	if !errors.As(err, &coded) || coded.Code() != http.StatusServiceUnavailable {
+		t.Fatalf("expected aborted request, got: %v", err)
+	}
+	assertGeneration(3)
+
+	// Requests are no longer aborted once the reload has completed
+	go func() {
+		_, err := route("slow")
+		done <- err
+	}()
+	<-started
+	release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatalf("request failed after reload: %v", err)
+	}
+
+	// A backend failing to start leaves the current one serving
+	failStart = true
+	if err := c.reloadMatchingPluginMounts(ctx, []string{"foo"}, time.Second); err == nil {
+		t.Fatal("expected reload to fail")
+	}
+	assertGeneration(3)
+}
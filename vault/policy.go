@@ -87,6 +87,10 @@ type Policy struct {
 	Type      PolicyType
 	Templated bool
 	namespace *namespace.Namespace
+
+	// requestParameters are the request fields which the paths of the policy
+	// may reference, by name.
+	requestParameters map[string]*requestParameter
 }
 
 // ShallowClone returns a shallow clone of the policy. This should not be used
@@ -99,6 +103,8 @@ func (p *Policy) ShallowClone() *Policy {
 		Type:      p.Type,
 		Templated: p.Templated,
 		namespace: p.namespace,
+
+		requestParameters: p.requestParameters,
 	}
 }
 
@@ -136,6 +142,12 @@ type ACLPermissions struct {
 	RequiredParameters  []string
 	MFAMethods          []string
 	GrantingPoliciesMap map[uint32][]logical.PolicyInfo
+
+	// rulePath and sources record the path glob of the rules merged into
+	// these permissions and the policies they come from, only for ACLs
+	// built to explain their decisions.
+	rulePath string
+	sources  []aclRuleSource
 }
 
 func (p *ACLPermissions) Clone() (*ACLPermissions, error) {
@@ -224,14 +236,15 @@ func addGrantingPoliciesToMap(m map[uint32][]logical.PolicyInfo, policy *Policy,
 // intermediary set of policies, before being compiled into
 // the ACL
 func ParseACLPolicy(ns *namespace.Namespace, rules string) (*Policy, error) {
-	return parseACLPolicyWithTemplating(ns, rules, false, nil, nil)
+	return parseACLPolicyWithTemplating(ns, rules, false, nil, nil, nil)
 }
 
 // parseACLPolicyWithTemplating performs the actual work and checks whether we
 // should perform substitutions. If performTemplating is true we know that it
 // is templated so we don't check again, otherwise we check to see if it's a
-// templated policy.
-func parseACLPolicyWithTemplating(ns *namespace.Namespace, rules string, performTemplating bool, entity *identity.Entity, groups []*identity.Group) (*Policy, error) {
+// templated policy. The request data is only used for the fields declared by
+// the request_parameter blocks of the policy.
+func parseACLPolicyWithTemplating(ns *namespace.Namespace, rules string, performTemplating bool, entity *identity.Entity, groups []*identity.Group, requestData map[string]interface{}) (*Policy, error) {
 	// Parse the rules
 	root, err := hcl.Parse(rules)
 	if err != nil {
@@ -248,6 +261,7 @@ func parseACLPolicyWithTemplating(ns *namespace.Namespace, rules string, perform
 	valid := []string{
 		"name",
 		"path",
+		"request_parameter",
 	}
 	if err := hclutil.CheckHCLKeys(list, valid); err != nil {
 		return nil, fmt.Errorf("failed to parse policy: %w", err)
@@ -263,8 +277,14 @@ func parseACLPolicyWithTemplating(ns *namespace.Namespace, rules string, perform
 		return nil, fmt.Errorf("failed to parse policy: %w", err)
 	}
 
+	if o := list.Filter("request_parameter"); len(o.Items) > 0 {
+		if err := parseRequestParameters(&p, o); err != nil {
+			return nil, fmt.Errorf("failed to parse policy: %w", err)
+		}
+	}
+
 	if o := list.Filter("path"); len(o.Items) > 0 {
-		if err := parsePaths(&p, o, performTemplating, entity, groups); err != nil {
+		if err := parsePaths(&p, o, performTemplating, entity, groups, requestData); err != nil {
 			return nil, fmt.Errorf("failed to parse policy: %w", err)
 		}
 	}
@@ -272,7 +292,12 @@ func parseACLPolicyWithTemplating(ns *namespace.Namespace, rules string, perform
 	return &p, nil
 }
 
-func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, entity *identity.Entity, groups []*identity.Group) error {
+func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, entity *identity.Entity, groups []*identity.Group, requestData map[string]interface{}) error {
+	var request map[string]string
+	if performTemplating {
+		request = result.requestTemplateData(requestData)
+	}
+
 	paths := make([]*PathRules, 0, len(list.Items))
 	for _, item := range list.Items {
 		key := "path"
@@ -288,10 +313,18 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 				Entity:      identity.ToSDKEntity(entity),
 				Groups:      identity.ToSDKGroups(groups),
 				NamespaceID: result.namespace.ID,
+				Request:     request,
 			})
 			if err != nil {
 				continue
 			}
+			if len(requestTemplateFields(key)) > 0 {
+				// Values are restricted to a single path segment, but make
+				// sure nothing let a request escape the path of the rule.
+				if err := checkRequestTemplatedPath(key, templated); err != nil {
+					continue
+				}
+			}
 			key = templated
 		} else {
 			hasTemplating, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
@@ -302,6 +335,11 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 			if err != nil {
 				return fmt.Errorf("failed to validate policy templating: %w", err)
 			}
+			for _, field := range requestTemplateFields(key) {
+				if _, ok := result.requestParameters[field]; !ok {
+					return fmt.Errorf("path %q: request field %q must be declared in a request_parameter block", key, field)
+				}
+			}
 			if hasTemplating {
 				result.Templated = true
 			}
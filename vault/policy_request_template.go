@@ -0,0 +1,159 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/openbao/openbao/sdk/v2/helper/hclutil"
+)
+
+// requestTemplatePrefix is the prefix of the template directives referencing
+// request fields.
+const requestTemplatePrefix = "request."
+
+// requestParameterNameRegex matches the names of the request fields which
+// may be declared for templating.
+var requestParameterNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type policyRequestDataContextKey struct{}
+
+// contextWithPolicyRequestData returns a context carrying the data of the
+// request that ACL policies are evaluated for, so that policies declaring
+// request parameters may reference it.
+func contextWithPolicyRequestData(ctx context.Context, data map[string]interface{}) context.Context {
+	return context.WithValue(ctx, policyRequestDataContextKey{}, data)
+}
+
+// policyRequestDataFromContext returns the request data carried by the
+// context, if any.
+func policyRequestDataFromContext(ctx context.Context) map[string]interface{} {
+	data, _ := ctx.Value(policyRequestDataContextKey{}).(map[string]interface{})
+	return data
+}
+
+// requestParameter is a request field declared by a policy for templating.
+type requestParameter struct {
+	Pattern string `hcl:"pattern"`
+
+	// pattern is the compiled pattern, anchored to match the whole value.
+	pattern *regexp.Regexp
+}
+
+// parseRequestParameters parses the request_parameter blocks of a policy.
+func parseRequestParameters(result *Policy, list *ast.ObjectList) error {
+	result.requestParameters = make(map[string]*requestParameter, len(list.Items))
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			return fmt.Errorf("request_parameter: missing field name")
+		}
+		name := item.Keys[0].Token.Value().(string)
+		if !requestParameterNameRegex.MatchString(name) {
+			return fmt.Errorf("request_parameter %q: invalid field name", name)
+		}
+		if _, ok := result.requestParameters[name]; ok {
+			return fmt.Errorf("request_parameter %q: declared more than once", name)
+		}
+
+		if err := hclutil.CheckHCLKeys(item.Val, []string{"pattern"}); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("request_parameter %q:", name))
+		}
+
+		var param requestParameter
+		if err := hcl.DecodeObject(&param, item.Val); err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("request_parameter %q:", name))
+		}
+		if param.Pattern == "" {
+			return fmt.Errorf("request_parameter %q: pattern is required", name)
+		}
+		pattern, err := regexp.Compile(`^(?:` + param.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("request_parameter %q: invalid pattern: %w", name, err)
+		}
+		param.pattern = pattern
+
+		result.requestParameters[name] = &param
+	}
+
+	return nil
+}
+
+// requestTemplateFields returns the request fields referenced by the template
+// directives of the string. The string must be balanced.
+func requestTemplateFields(s string) []string {
+	var fields []string
+	for _, piece := range strings.Split(s, "{{")[1:] {
+		directive := strings.TrimSpace(strings.SplitN(piece, "}}", 2)[0])
+		if strings.HasPrefix(directive, requestTemplatePrefix) {
+			fields = append(fields, strings.TrimPrefix(directive, requestTemplatePrefix))
+		}
+	}
+	return fields
+}
+
+// validRequestTemplateValue returns true if the value may be substituted in a
+// policy path: it must make up a single path segment, without any glob.
+func validRequestTemplateValue(value string) bool {
+	if value == "" || value == "." || value == ".." {
+		return false
+	}
+	return !strings.ContainsAny(value, "/*+{}")
+}
+
+// requestTemplateData returns the request fields declared by the policy whose
+// values may be substituted. Fields with a value which is not a string, which
+// does not match the declared pattern or which is not a single path segment
+// are left out, so that the rules referencing them are dropped.
+func (p *Policy) requestTemplateData(data map[string]interface{}) map[string]string {
+	if data == nil || len(p.requestParameters) == 0 {
+		return nil
+	}
+
+	ret := make(map[string]string, len(p.requestParameters))
+	for name, param := range p.requestParameters {
+		value, ok := data[name].(string)
+		if !ok || !validRequestTemplateValue(value) || !param.pattern.MatchString(value) {
+			continue
+		}
+		ret[name] = value
+	}
+	return ret
+}
+
+// checkRequestTemplatedPath checks that rendering the request fields did not
+// make the path escape the literal prefix of its template, nor add any
+// segment or glob to it.
+func checkRequestTemplatedPath(template, rendered string) error {
+	prefix := strings.SplitN(template, "{{", 2)[0]
+	if !strings.HasPrefix(rendered, prefix) {
+		return fmt.Errorf("rendered path does not start with %q", prefix)
+	}
+
+	var literal strings.Builder
+	for i, piece := range strings.Split(template, "{{") {
+		if i > 0 {
+			piece = strings.SplitN(piece, "}}", 2)[1]
+		}
+		literal.WriteString(piece)
+	}
+	for _, c := range []string{"/", "*", "+"} {
+		if strings.Count(rendered, c) != strings.Count(literal.String(), c) {
+			return fmt.Errorf("rendered path has an unexpected %q", c)
+		}
+	}
+
+	for _, segment := range strings.Split(rendered, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("rendered path has a relative segment")
+		}
+	}
+
+	return nil
+}
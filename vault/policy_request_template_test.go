@@ -0,0 +1,159 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+const testRequestTemplatedPolicy = `
+name = "tenants"
+request_parameter "tenant" {
+	pattern = "[a-z0-9-]+"
+}
+path "secret/tenants/{{request.tenant}}/*" {
+	capabilities = ["create", "update", "read"]
+}
+path "secret/shared" {
+	capabilities = ["read"]
+}
+`
+
+func TestPolicy_ParseRequestParameters(t *testing.T) {
+	for name, rules := range map[string]string{
+		"undeclared field": `
+path "secret/{{request.tenant}}" { capabilities = ["read"] }
+`,
+		"other field declared": `
+request_parameter "team" { pattern = ".*" }
+path "secret/{{request.tenant}}" { capabilities = ["read"] }
+`,
+		"missing pattern": `
+request_parameter "tenant" {}
+`,
+		"invalid pattern": `
+request_parameter "tenant" { pattern = "[" }
+`,
+		"invalid name": `
+request_parameter "ten.ant" { pattern = ".*" }
+`,
+		"duplicate": `
+request_parameter "tenant" { pattern = ".*" }
+request_parameter "tenant" { pattern = ".*" }
+`,
+		"invalid key": `
+request_parameter "tenant" {
+	pattern = ".*"
+	values  = ["acme"]
+}
+`,
+	} {
+		_, err := ParseACLPolicy(namespace.RootNamespace, rules)
+		require.Error(t, err, name)
+	}
+
+	p, err := ParseACLPolicy(namespace.RootNamespace, testRequestTemplatedPolicy)
+	require.NoError(t, err)
+	require.True(t, p.Templated)
+	require.Contains(t, p.requestParameters, "tenant")
+}
+
+func TestPolicy_RequestTemplating(t *testing.T) {
+	tCases := []struct {
+		name     string
+		data     map[string]interface{}
+		expected string
+	}{
+		{"valid", map[string]interface{}{"tenant": "acme"}, "secret/tenants/acme/"},
+		{"no data", nil, ""},
+		{"missing", map[string]interface{}{"team": "acme"}, ""},
+		{"not a string", map[string]interface{}{"tenant": []string{"acme"}}, ""},
+		{"pattern mismatch", map[string]interface{}{"tenant": "ACME"}, ""},
+		{"partial pattern match", map[string]interface{}{"tenant": "acme/../admin"}, ""},
+		{"parent segment", map[string]interface{}{"tenant": ".."}, ""},
+		{"empty", map[string]interface{}{"tenant": ""}, ""},
+	}
+
+	for _, tCase := range tCases {
+		p, err := parseACLPolicyWithTemplating(namespace.RootNamespace, testRequestTemplatedPolicy, true, nil, nil, tCase.data)
+		require.NoError(t, err, tCase.name)
+
+		var paths []string
+		for _, pc := range p.Paths {
+			paths = append(paths, pc.Path)
+		}
+		if tCase.expected == "" {
+			require.Equal(t, []string{"secret/shared"}, paths, tCase.name)
+		} else {
+			require.Equal(t, []string{tCase.expected, "secret/shared"}, paths, tCase.name)
+		}
+	}
+
+	// Values matching a permissive pattern still cannot add segments or globs.
+	permissive := `
+request_parameter "tenant" { pattern = ".*" }
+path "secret/tenants/{{request.tenant}}" { capabilities = ["read"] }
+`
+	for _, value := range []string{"acme/admin", "*", "+", "acme*", ".", "{{request.tenant}}"} {
+		p, err := parseACLPolicyWithTemplating(namespace.RootNamespace, permissive, true, nil, nil, map[string]interface{}{"tenant": value})
+		require.NoError(t, err)
+		require.Empty(t, p.Paths, value)
+	}
+}
+
+func TestCheckRequestTemplatedPath(t *testing.T) {
+	template := "secret/{{request.tenant}}/*"
+	require.NoError(t, checkRequestTemplatedPath(template, "secret/acme/*"))
+	for _, rendered := range []string{
+		"other/acme/*",
+		"secret/acme/admin/*",
+		"secret/acme*/*",
+		"secret/../*",
+	} {
+		require.Error(t, checkRequestTemplatedPath(template, rendered), rendered)
+	}
+}
+
+func TestCore_RequestTemplatedPolicy(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, testRequestTemplatedPolicy)
+	require.NoError(t, err)
+	require.NoError(t, c.policyStore.SetPolicy(ctx, policy))
+
+	testMakeTokenDirectly(t, c.tokenStore, &logical.TokenEntry{
+		ID:       "tenanttoken",
+		Path:     "auth/token/create",
+		Policies: []string{"tenants"},
+		TTL:      time.Hour,
+	})
+
+	write := func(token, path string, data map[string]interface{}) error {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.ClientToken = token
+		req.Data = data
+		_, err := c.HandleRequest(ctx, req)
+		return err
+	}
+
+	require.NoError(t, write("tenanttoken", "secret/tenants/acme/foo", map[string]interface{}{"tenant": "acme"}))
+
+	// The request field must point at the path being requested.
+	err = write("tenanttoken", "secret/tenants/globex/foo", map[string]interface{}{"tenant": "acme"})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	err = write("tenanttoken", "secret/tenants/globex/foo", nil)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// Crafted values do not grant anything beyond the intended prefix.
+	require.NoError(t, write(root, "secret/admin", map[string]interface{}{"tenant": "x"}))
+	err = write("tenanttoken", "secret/admin", map[string]interface{}{"tenant": "../../admin"})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+}
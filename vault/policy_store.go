@@ -605,6 +605,16 @@ func (ps *PolicyStore) switchedDeletePolicy(ctx context.Context, name string, po
 // ACL is used to return an ACL which is built using the
 // named policies and pre-fetched policies if given.
 func (ps *PolicyStore) ACL(ctx context.Context, entity *identity.Entity, policyNames map[string][]string, additionalPolicies ...*Policy) (*ACL, error) {
+	return ps.acl(ctx, entity, policyNames, false, additionalPolicies)
+}
+
+// tracedACL is used to return an ACL which records the provenance of its
+// permissions, in order to explain its decisions.
+func (ps *PolicyStore) tracedACL(ctx context.Context, entity *identity.Entity, policyNames map[string][]string, additionalPolicies ...*Policy) (*ACL, error) {
+	return ps.acl(ctx, entity, policyNames, true, additionalPolicies)
+}
+
+func (ps *PolicyStore) acl(ctx context.Context, entity *identity.Entity, policyNames map[string][]string, trace bool, additionalPolicies []*Policy) (*ACL, error) {
 	var allPolicies []*Policy
 
 	// Fetch the named policies
@@ -645,7 +655,7 @@ func (ps *PolicyStore) ACL(ctx context.Context, entity *identity.Entity, policyN
 					groups = append(directGroups, inheritedGroups...)
 				}
 			}
-			p, err := parseACLPolicyWithTemplating(policy.namespace, policy.Raw, true, entity, groups)
+			p, err := parseACLPolicyWithTemplating(policy.namespace, policy.Raw, true, entity, groups, policyRequestDataFromContext(ctx))
 			if err != nil {
 				return nil, fmt.Errorf("error parsing templated policy %q: %w", policy.Name, err)
 			}
@@ -655,10 +665,11 @@ func (ps *PolicyStore) ACL(ctx context.Context, entity *identity.Entity, policyN
 	}
 
 	// Construct the ACL
-	acl, err := NewACL(ctx, allPolicies)
+	acl, err := newACL(ctx, allPolicies, trace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct ACL: %w", err)
 	}
+	acl.strictPatch = ps.core.strictPatchCapability
 
 	return acl, nil
 }
@@ -27,10 +27,10 @@ const (
 	TypeRateLimit Type = "rate-limit"
 
 	// TypeLeaseCount represents the lease count limiting quota type
-	//
-	// This was a Vault Enterprise feature; the constant was left for
-	// historical reasons.
 	TypeLeaseCount Type = "lease-count"
+
+	// TypeSecretCount represents the KV secret count limiting quota type
+	TypeSecretCount Type = "secret-count"
 )
 
 // LeaseAction is the action taken by the expiration manager on the lease. The
@@ -80,6 +80,10 @@ func (q Type) String() string {
 	switch q {
 	case TypeRateLimit:
 		return "rate-limit"
+	case TypeLeaseCount:
+		return "lease-count"
+	case TypeSecretCount:
+		return "secret-count"
 	}
 	return "unknown"
 }
@@ -108,6 +112,10 @@ const (
 	// infer a Vault node is operating with an initial default set and on a subsequent
 	// update to that set, we should not overwrite it on Setup.
 	DefaultRateLimitExemptPathsToggle = StoragePrefix + "default_rate_limit_exempt_paths_toggle"
+
+	// SecretCountPrefix is the prefix for the physical location where the
+	// number of secrets counted against each secret count quota is persisted.
+	SecretCountPrefix = StoragePrefix + "secret-count-usage/"
 )
 
 var (
@@ -118,6 +126,10 @@ var (
 	// ErrRateLimitQuotaExceeded is returned when a request is rejected due to a
 	// rate limit quota being exceeded.
 	ErrRateLimitQuotaExceeded = errors.New("rate limit quota exceeded")
+
+	// ErrSecretCountQuotaExceeded is returned when a request is rejected due
+	// to a secret count quota being exceeded.
+	ErrSecretCountQuotaExceeded = errors.New("secret count quota exceeded")
 )
 
 var defaultExemptPaths = []string{
@@ -176,6 +188,18 @@ type Manager struct {
 
 	// dbAndCacheLock is a lock for db and path caches that need to be reset during Reset()
 	dbAndCacheLock locking.RWMutex
+
+	// leaseCache holds the quota request of every lease known to the
+	// expiration manager, keyed by lease ID. It is used to recount the leases
+	// of lease count quotas when those change.
+	leaseCache map[string]*Request
+
+	// leaseCacheLock is a lock for the lease cache
+	leaseCacheLock locking.RWMutex
+
+	// secretCountLock serializes the updates of the secret count quota
+	// counters, so that they are persisted in order
+	secretCountLock locking.RWMutex
 }
 
 // QuotaLeaseInformation contains all of the information lease-count quotas require
@@ -267,6 +291,14 @@ type Request struct {
 	// ClientAddress is client unique addressable string (e.g. IP address). It can
 	// be empty if the quota type does not need it.
 	ClientAddress string
+
+	// LeaseID is the identifier of the lease being created. It is only used by
+	// lease count quotas.
+	LeaseID string
+
+	// EntityID is the identity entity of the token creating the lease, if
+	// any. It is only used by per-entity lease count quotas.
+	EntityID string
 }
 
 // NewManager creates and initializes a new quota manager to hold all the quota
@@ -283,9 +315,12 @@ func NewManager(logger log.Logger, ms *metricsutil.ClusterMetricSink, detectDead
 		metricSink:           ms,
 		rateLimitPathManager: pathmanager.New(),
 		config:               new(Config),
+		leaseCache:           make(map[string]*Request),
 		quotaLock:            &locking.SyncRWMutex{},
 		quotaConfigLock:      &locking.SyncRWMutex{},
 		dbAndCacheLock:       &locking.SyncRWMutex{},
+		leaseCacheLock:       &locking.SyncRWMutex{},
+		secretCountLock:      &locking.SyncRWMutex{},
 	}
 
 	if detectDeadlocks {
@@ -293,6 +328,8 @@ func NewManager(logger log.Logger, ms *metricsutil.ClusterMetricSink, detectDead
 		manager.quotaLock = &locking.DeadlockRWMutex{}
 		manager.quotaConfigLock = &locking.DeadlockRWMutex{}
 		manager.dbAndCacheLock = &locking.DeadlockRWMutex{}
+		manager.leaseCacheLock = &locking.DeadlockRWMutex{}
+		manager.secretCountLock = &locking.DeadlockRWMutex{}
 	}
 
 	return manager, nil
@@ -310,6 +347,9 @@ func (m *Manager) SetQuota(ctx context.Context, qType string, quota Quota, loadi
 // setQuotaLocked creates a transaction, passes it into setQuotaLockedWithTxn and manages its lifecycle
 // along with updating lease quota counts
 func (m *Manager) setQuotaLocked(ctx context.Context, qType string, quota Quota, loading bool) error {
+	m.leaseCacheLock.Lock()
+	defer m.leaseCacheLock.Unlock()
+
 	txn := m.db.Txn(true)
 	defer txn.Abort()
 
@@ -318,6 +358,12 @@ func (m *Manager) setQuotaLocked(ctx context.Context, qType string, quota Quota,
 		return err
 	}
 
+	if qType == TypeLeaseCount.String() {
+		if err := m.recountLeasesLocked(txn); err != nil {
+			return err
+		}
+	}
+
 	if loading {
 		txn.Commit()
 		return nil
@@ -628,8 +674,10 @@ func (m *Manager) QueryResolveRoleQuotas(req *Request) (bool, error) {
 func (m *Manager) DeleteQuota(ctx context.Context, qType string, name string) error {
 	m.quotaLock.Lock()
 	m.dbAndCacheLock.RLock()
+	m.leaseCacheLock.Lock()
 	defer m.quotaLock.Unlock()
 	defer m.dbAndCacheLock.RUnlock()
+	defer m.leaseCacheLock.Unlock()
 
 	txn := m.db.Txn(true)
 	defer txn.Abort()
@@ -652,6 +700,18 @@ func (m *Manager) DeleteQuota(ctx context.Context, qType string, name string) er
 		return err
 	}
 
+	if qType == TypeLeaseCount.String() {
+		if err := m.recountLeasesLocked(txn); err != nil {
+			return err
+		}
+	}
+
+	if qType == TypeSecretCount.String() && m.storage != nil {
+		if err := m.storage.Delete(ctx, SecretCountStoragePath(name)); err != nil {
+			return err
+		}
+	}
+
 	txn.Commit()
 	return nil
 }
@@ -676,6 +736,167 @@ func (m *Manager) ApplyQuota(ctx context.Context, req *Request) (Response, error
 	return quota.allow(ctx, req)
 }
 
+// UpdateLeaseCounts updates the lease cache and the counters of the lease
+// count quotas with the action taken by the expiration manager on a lease.
+// For deletions, only the LeaseID of the request needs to be set. Deleting a
+// lease which is not counted is a no-op, so that leases which failed to
+// register can be released as well.
+func (m *Manager) UpdateLeaseCounts(ctx context.Context, action LeaseAction, req *Request) error {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+	m.leaseCacheLock.Lock()
+	defer m.leaseCacheLock.Unlock()
+
+	switch action {
+	case LeaseActionCreated, LeaseActionLoaded:
+		leaseReq := *req
+		leaseReq.Type = TypeLeaseCount
+		m.leaseCache[req.LeaseID] = &leaseReq
+
+		quota, err := m.queryQuota(nil, &leaseReq)
+		if err != nil {
+			return err
+		}
+		if quota != nil {
+			quota.(*LeaseCountQuota).track(&leaseReq)
+		}
+
+	case LeaseActionDeleted:
+		delete(m.leaseCache, req.LeaseID)
+
+		txn := m.db.Txn(false)
+		iter, err := txn.Get(TypeLeaseCount.String(), indexID)
+		if err != nil {
+			return err
+		}
+		for raw := iter.Next(); raw != nil; raw = iter.Next() {
+			raw.(*LeaseCountQuota).untrack(req.LeaseID)
+		}
+
+	default:
+		return fmt.Errorf("unsupported lease action: %v", action)
+	}
+
+	return nil
+}
+
+// ReserveSecret counts a KV secret which is about to be created against the
+// applicable secret count quota, if any, and persists the new count. When
+// allowed, the secret must be released with ReleaseSecret if it ends up not
+// being created.
+func (m *Manager) ReserveSecret(ctx context.Context, req *Request) (Response, error) {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+	m.secretCountLock.Lock()
+	defer m.secretCountLock.Unlock()
+
+	req.Type = TypeSecretCount
+	quota, err := m.queryQuota(nil, req)
+	if err != nil {
+		return Response{}, err
+	}
+	if quota == nil {
+		return Response{Allowed: true}, nil
+	}
+
+	resp, err := quota.allow(ctx, req)
+	if err != nil || !resp.Allowed {
+		return resp, err
+	}
+
+	scq := quota.(*SecretCountQuota)
+	if err := m.persistSecretCountLocked(ctx, scq); err != nil {
+		scq.release()
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// ReleaseSecret removes a KV secret which was deleted, or which was reserved
+// but not created, from the applicable secret count quota, if any.
+func (m *Manager) ReleaseSecret(ctx context.Context, req *Request) error {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+	m.secretCountLock.Lock()
+	defer m.secretCountLock.Unlock()
+
+	req.Type = TypeSecretCount
+	quota, err := m.queryQuota(nil, req)
+	if err != nil || quota == nil {
+		return err
+	}
+
+	scq := quota.(*SecretCountQuota)
+	scq.release()
+	return m.persistSecretCountLocked(ctx, scq)
+}
+
+// SetSecretCount replaces the number of secrets counted against the named
+// secret count quota, such as after recounting the secrets it applies to.
+func (m *Manager) SetSecretCount(ctx context.Context, name string, count int) error {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+	m.secretCountLock.Lock()
+	defer m.secretCountLock.Unlock()
+
+	quota, err := m.quotaByNameLocked(TypeSecretCount.String(), name)
+	if err != nil {
+		return err
+	}
+	if quota == nil {
+		return fmt.Errorf("secret count quota %q not found", name)
+	}
+
+	scq := quota.(*SecretCountQuota)
+	scq.setCount(count)
+	return m.persistSecretCountLocked(ctx, scq)
+}
+
+// persistSecretCountLocked stores the counter of the given secret count
+// quota. It must be called with the secret count lock held.
+func (m *Manager) persistSecretCountLocked(ctx context.Context, scq *SecretCountQuota) error {
+	if m.storage == nil {
+		return nil
+	}
+
+	entry, err := logical.StorageEntryJSON(SecretCountStoragePath(scq.Name), scq.Count())
+	if err != nil {
+		return err
+	}
+	return m.storage.Put(ctx, entry)
+}
+
+// recountLeasesLocked recomputes the counters of all the lease count quotas in
+// the given transaction from the lease cache, as changing any lease count
+// quota may change the quota each lease is counted against. It must be called
+// with the lease cache lock held.
+func (m *Manager) recountLeasesLocked(txn *memdb.Txn) error {
+	iter, err := txn.Get(TypeLeaseCount.String(), indexID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		raw.(*LeaseCountQuota).reset()
+		found = true
+	}
+	if !found {
+		return nil
+	}
+
+	for _, req := range m.leaseCache {
+		quota, err := m.queryQuota(txn, req)
+		if err != nil {
+			return err
+		}
+		if quota != nil {
+			quota.(*LeaseCountQuota).track(req)
+		}
+	}
+
+	return nil
+}
+
 // SetEnableRateLimitAuditLogging updates the operator preference regarding the
 // audit logging behavior.
 func (m *Manager) SetEnableRateLimitAuditLogging(val bool) {
@@ -804,6 +1025,10 @@ func (m *Manager) resetCache() error {
 		return err
 	}
 	m.db = db
+
+	m.leaseCacheLock.Lock()
+	m.leaseCache = make(map[string]*Request)
+	m.leaseCacheLock.Unlock()
 	return nil
 }
 
@@ -1020,6 +1245,10 @@ func Load(ctx context.Context, storage logical.Storage, qType, name string) (Quo
 	switch qType {
 	case TypeRateLimit.String():
 		quota = &RateLimitQuota{}
+	case TypeLeaseCount.String():
+		quota = &LeaseCountQuota{}
+	case TypeSecretCount.String():
+		quota = &SecretCountQuota{}
 	default:
 		return nil, fmt.Errorf("unsupported type: %v", qType)
 	}
@@ -1102,6 +1331,12 @@ func (m *Manager) setupQuotaType(ctx context.Context, storage logical.Storage, q
 			continue
 		}
 
+		if scq, ok := quota.(*SecretCountQuota); ok {
+			if scq.count, err = loadSecretCount(ctx, storage, name); err != nil {
+				return err
+			}
+		}
+
 		err = m.setQuotaLocked(ctx, quotaType, quota, true)
 		if err != nil {
 			return err
@@ -1117,14 +1352,39 @@ func QuotaStoragePath(quotaType, name string) string {
 	return path.Join(StoragePrefix+quotaType, name)
 }
 
+// SecretCountStoragePath returns the storage path suffix for persisting the
+// counter of a secret count quota.
+func SecretCountStoragePath(name string) string {
+	return SecretCountPrefix + name
+}
+
+// loadSecretCount reads the persisted counter of a secret count quota.
+func loadSecretCount(ctx context.Context, storage logical.Storage, name string) (int, error) {
+	var count int
+	entry, err := storage.Get(ctx, SecretCountStoragePath(name))
+	if err != nil {
+		return 0, err
+	}
+	if entry == nil {
+		return 0, nil
+	}
+
+	if err := entry.DecodeJSON(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // HandleRemount updates the quota subsystem about the remount operation that
 // took place. Quota manager will trigger the quota specific updates including
 // the mount path update and the namespace update
 func (m *Manager) HandleRemount(ctx context.Context, from, to namespace.MountPathDetails) error {
 	m.quotaLock.Lock()
 	m.dbAndCacheLock.RLock()
+	m.leaseCacheLock.Lock()
 	defer m.quotaLock.Unlock()
 	defer m.dbAndCacheLock.RUnlock()
+	defer m.leaseCacheLock.Unlock()
 
 	// Grab a write transaction, as we want to save the updated quota in memdb
 	txn := m.db.Txn(true)
@@ -1188,19 +1448,58 @@ func (m *Manager) HandleRemount(ctx context.Context, from, to namespace.MountPat
 		return err
 	}
 
+	if err := m.recountLeasesLocked(txn); err != nil {
+		return err
+	}
+
 	txn.Commit()
 
 	return nil
 }
 
+// QuotaNamesForMount returns the names of the quota rules, by type, which
+// apply to the given mount and would be updated by HandleRemount.
+func (m *Manager) QuotaNamesForMount(from namespace.MountPathDetails) (map[string][]string, error) {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+
+	fromNs := from.Namespace.Path
+	if fromNs == "" {
+		fromNs = namespace.RootNamespaceID
+	}
+
+	txn := m.db.Txn(false)
+	names := make(map[string][]string)
+	for _, quotaType := range quotaTypes() {
+		// Rules for the whole mount, those with a path prefix and those
+		// with a role, as updated by HandleRemount
+		for _, args := range [][]interface{}{
+			{fromNs, from.MountPath, false, false},
+			{fromNs, from.MountPath, true, false},
+			{fromNs, from.MountPath, false, true},
+		} {
+			iter, err := txn.Get(quotaType, indexNamespaceMount, args...)
+			if err != nil {
+				return nil, err
+			}
+			for raw := iter.Next(); raw != nil; raw = iter.Next() {
+				names[quotaType] = append(names[quotaType], raw.(Quota).QuotaName())
+			}
+		}
+	}
+	return names, nil
+}
+
 // HandleBackendDisabling updates the quota subsystem with the disabling of auth
 // or secret engine disabling. This should only be called on the primary cluster
 // node.
 func (m *Manager) HandleBackendDisabling(ctx context.Context, nsPath, mountPath string) error {
 	m.quotaLock.Lock()
 	m.dbAndCacheLock.RLock()
+	m.leaseCacheLock.Lock()
 	defer m.quotaLock.Unlock()
 	defer m.dbAndCacheLock.RUnlock()
+	defer m.leaseCacheLock.Unlock()
 
 	txn := m.db.Txn(true)
 	defer txn.Abort()
@@ -1225,6 +1524,11 @@ func (m *Manager) HandleBackendDisabling(ctx context.Context, nsPath, mountPath
 				if err := m.storage.Delete(ctx, QuotaStoragePath(quotaType, quota.QuotaName())); err != nil {
 					return fmt.Errorf("failed to delete quota from storage after mount disabling; namespace %q, err %v", nsPath, err)
 				}
+				if quotaType == TypeSecretCount.String() {
+					if err := m.storage.Delete(ctx, SecretCountStoragePath(quota.QuotaName())); err != nil {
+						return fmt.Errorf("failed to delete quota from storage after mount disabling; namespace %q, err %v", nsPath, err)
+					}
+				}
 			}
 		}
 		return nil
@@ -1248,6 +1552,10 @@ func (m *Manager) HandleBackendDisabling(ctx context.Context, nsPath, mountPath
 		return err
 	}
 
+	if err := m.recountLeasesLocked(txn); err != nil {
+		return err
+	}
+
 	txn.Commit()
 
 	return nil
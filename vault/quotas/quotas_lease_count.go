@@ -0,0 +1,265 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package quotas
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-uuid"
+	"github.com/openbao/openbao/helper/metricsutil"
+	"github.com/openbao/openbao/sdk/v2/helper/cryptoutil"
+)
+
+// NoEntityBucket is the bucket of a per-entity lease count quota which holds
+// the leases created by tokens that are not tied to an identity entity, such
+// as root tokens or orphan batch tokens. All such leases share this bucket
+// and are limited as though they belonged to a single entity.
+const NoEntityBucket = "_no_entity"
+
+// Ensure that LeaseCountQuota implements the Quota interface
+var _ Quota = (*LeaseCountQuota)(nil)
+
+// LeaseCountQuota represents the quota rule properties that is used to limit
+// the number of leases which may exist at any given time for a namespace or
+// mount.
+type LeaseCountQuota struct {
+	// ID is the identifier of the quota
+	ID string `json:"id"`
+
+	// Type of quota this represents
+	Type Type `json:"type"`
+
+	// Name of the quota rule
+	Name string `json:"name"`
+
+	// NamespacePath is the path of the namespace to which this quota is
+	// applicable.
+	NamespacePath string `json:"namespace_path"`
+
+	// MountPath is the path of the mount to which this quota is applicable
+	MountPath string `json:"mount_path"`
+
+	// Role is the role on an auth mount to apply the quota to upon /login requests
+	// Not applicable for use with path suffixes
+	Role string `json:"role"`
+
+	// PathSuffix is the path suffix to which this quota is applicable
+	PathSuffix string `json:"path_suffix"`
+
+	// MaxLeases defines the maximum number of leases allowed by the quota. When
+	// PerEntity is set, this is the maximum number of leases of each entity.
+	MaxLeases int `json:"max_leases"`
+
+	// PerEntity, if set, enforces MaxLeases separately for each identity
+	// entity instead of across all the leases the quota applies to.
+	PerEntity bool `json:"per_entity"`
+
+	lock       *sync.Mutex
+	counts     map[string]int
+	leases     map[string]string
+	logger     log.Logger
+	metricSink *metricsutil.ClusterMetricSink
+}
+
+// NewLeaseCountQuota creates a quota checker for imposing limits on the number
+// of leases which may exist at a time. When perEntity is set, the limit applies
+// to the leases of each identity entity separately.
+func NewLeaseCountQuota(name, nsPath, mountPath, pathSuffix, role string, maxLeases int, perEntity bool) *LeaseCountQuota {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		// Fall back to generating with a hash of the name, later in initialize
+		id = ""
+	}
+	return &LeaseCountQuota{
+		Name:          name,
+		ID:            id,
+		Type:          TypeLeaseCount,
+		NamespacePath: nsPath,
+		MountPath:     mountPath,
+		Role:          role,
+		PathSuffix:    pathSuffix,
+		MaxLeases:     maxLeases,
+		PerEntity:     perEntity,
+	}
+}
+
+func (q *LeaseCountQuota) Clone() Quota {
+	return &LeaseCountQuota{
+		ID:            q.ID,
+		Name:          q.Name,
+		MountPath:     q.MountPath,
+		Role:          q.Role,
+		Type:          q.Type,
+		NamespacePath: q.NamespacePath,
+		PathSuffix:    q.PathSuffix,
+		MaxLeases:     q.MaxLeases,
+		PerEntity:     q.PerEntity,
+	}
+}
+
+// initialize ensures the namespace and max leases are initialized, sets the ID
+// if it's currently empty and resets the lease counters. The counters are
+// populated again by the quota manager from its lease cache.
+func (lcq *LeaseCountQuota) initialize(logger log.Logger, ms *metricsutil.ClusterMetricSink) error {
+	if lcq.lock == nil {
+		lcq.lock = new(sync.Mutex)
+	}
+
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	// Memdb requires a non-empty value for indexing
+	if lcq.NamespacePath == "" {
+		lcq.NamespacePath = "root"
+	}
+
+	if lcq.MaxLeases <= 0 {
+		return fmt.Errorf("invalid max leases: %v", lcq.MaxLeases)
+	}
+
+	if logger != nil {
+		lcq.logger = logger
+	}
+
+	if lcq.metricSink == nil {
+		lcq.metricSink = ms
+	}
+
+	if lcq.ID == "" {
+		lcq.ID = hex.EncodeToString(cryptoutil.Blake2b256Hash(lcq.Name))
+	}
+
+	lcq.counts = make(map[string]int)
+	lcq.leases = make(map[string]string)
+
+	return nil
+}
+
+// quotaID returns the identifier of the quota rule
+func (lcq *LeaseCountQuota) quotaID() string {
+	return lcq.ID
+}
+
+// QuotaName returns the name of the quota rule
+func (lcq *LeaseCountQuota) QuotaName() string {
+	return lcq.Name
+}
+
+// bucket returns the counter of the quota to which the lease described by the
+// request is attributed.
+func (lcq *LeaseCountQuota) bucket(req *Request) string {
+	switch {
+	case !lcq.PerEntity:
+		return ""
+	case req.EntityID == "":
+		return NoEntityBucket
+	default:
+		return req.EntityID
+	}
+}
+
+// allow decides if the lease described by the request may be created. An
+// error will be returned if the lease ID is empty. When allowed, the lease is
+// counted against the quota right away so that concurrent requests cannot
+// exceed the limit; a lease which is already counted is always allowed.
+func (lcq *LeaseCountQuota) allow(ctx context.Context, req *Request) (Response, error) {
+	var resp Response
+
+	if req.LeaseID == "" {
+		return resp, fmt.Errorf("missing lease ID in quota request")
+	}
+
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	if _, ok := lcq.leases[req.LeaseID]; ok {
+		resp.Allowed = true
+		return resp, nil
+	}
+
+	bucket := lcq.bucket(req)
+	if lcq.counts[bucket] >= lcq.MaxLeases {
+		lcq.metricSink.IncrCounterWithLabels([]string{"quota", "lease_count", "violation"}, 1, []metrics.Label{{Name: "name", Value: lcq.Name}})
+		return resp, nil
+	}
+
+	lcq.counts[bucket]++
+	lcq.leases[req.LeaseID] = bucket
+	resp.Allowed = true
+	return resp, nil
+}
+
+// track counts an existing lease against the quota, regardless of the limit.
+func (lcq *LeaseCountQuota) track(req *Request) {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	if _, ok := lcq.leases[req.LeaseID]; ok {
+		return
+	}
+
+	bucket := lcq.bucket(req)
+	lcq.counts[bucket]++
+	lcq.leases[req.LeaseID] = bucket
+}
+
+// untrack removes a lease from the quota, if it was counted against it.
+func (lcq *LeaseCountQuota) untrack(leaseID string) {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	bucket, ok := lcq.leases[leaseID]
+	if !ok {
+		return
+	}
+
+	delete(lcq.leases, leaseID)
+	lcq.counts[bucket]--
+	if lcq.counts[bucket] <= 0 {
+		delete(lcq.counts, bucket)
+	}
+}
+
+// reset clears all the lease counters of the quota.
+func (lcq *LeaseCountQuota) reset() {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	lcq.counts = make(map[string]int)
+	lcq.leases = make(map[string]string)
+}
+
+// Count returns the total number of leases counted against the quota.
+func (lcq *LeaseCountQuota) Count() int {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	return len(lcq.leases)
+}
+
+// BucketCount returns the number of leases counted against the given bucket
+// of the quota. For per-entity quotas, the bucket is an entity ID or
+// NoEntityBucket.
+func (lcq *LeaseCountQuota) BucketCount(bucket string) int {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	return lcq.counts[bucket]
+}
+
+// close is a no-op for lease count quotas, as they do not run any background
+// routines.
+func (lcq *LeaseCountQuota) close(ctx context.Context) error {
+	return nil
+}
+
+func (lcq *LeaseCountQuota) handleRemount(mountpath, nspath string) {
+	lcq.MountPath = mountpath
+	lcq.NamespacePath = nspath
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/helper/metricsutil"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLeaseCountQuota(t *testing.T) {
+	lcq := NewLeaseCountQuota("test-lease-count", "qa", "/foo/bar", "", "", 0, false)
+	require.Error(t, lcq.initialize(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink()))
+
+	lcq = NewLeaseCountQuota("test-lease-count", "qa", "/foo/bar", "", "", 10, true)
+	require.NoError(t, lcq.initialize(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink()))
+	require.Equal(t, TypeLeaseCount, lcq.Type)
+	require.NotEmpty(t, lcq.ID)
+}
+
+func TestLeaseCountQuota_Allow(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink(), true)
+	require.NoError(t, err)
+
+	lcq := NewLeaseCountQuota("per-entity", "", "pki/", "", "", 2, true)
+	require.NoError(t, qm.SetQuota(context.Background(), TypeLeaseCount.String(), lcq, false))
+
+	leaseNum := 0
+	allow := func(entityID string) (string, bool) {
+		t.Helper()
+		leaseNum++
+		leaseID := fmt.Sprintf("pki/issue/test/%d", leaseNum)
+		resp, err := qm.ApplyQuota(context.Background(), &Request{
+			Type:      TypeLeaseCount,
+			Path:      "pki/issue/test",
+			MountPath: "pki/",
+			LeaseID:   leaseID,
+			EntityID:  entityID,
+		})
+		require.NoError(t, err)
+		return leaseID, resp.Allowed
+	}
+
+	// Each entity has its own limit.
+	first, allowed := allow("entity-1")
+	require.True(t, allowed)
+	_, allowed = allow("entity-1")
+	require.True(t, allowed)
+	_, allowed = allow("entity-1")
+	require.False(t, allowed)
+	_, allowed = allow("entity-2")
+	require.True(t, allowed)
+
+	// Leases without an entity share a single bucket.
+	_, allowed = allow("")
+	require.True(t, allowed)
+	_, allowed = allow("")
+	require.True(t, allowed)
+	_, allowed = allow("")
+	require.False(t, allowed)
+	require.Equal(t, 2, lcq.BucketCount(NoEntityBucket))
+	require.Equal(t, 5, lcq.Count())
+
+	// Asking again for a lease which is already counted is always allowed.
+	resp, err := qm.ApplyQuota(context.Background(), &Request{
+		Type:      TypeLeaseCount,
+		Path:      "pki/issue/test",
+		MountPath: "pki/",
+		LeaseID:   first,
+		EntityID:  "entity-1",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.Equal(t, 2, lcq.BucketCount("entity-1"))
+
+	// Releasing a lease frees room for its entity only.
+	require.NoError(t, qm.UpdateLeaseCounts(context.Background(), LeaseActionDeleted, &Request{LeaseID: first}))
+	require.Equal(t, 1, lcq.BucketCount("entity-1"))
+	_, allowed = allow("")
+	require.False(t, allowed)
+	_, allowed = allow("entity-1")
+	require.True(t, allowed)
+
+	// Requests not covered by the quota are always allowed.
+	resp, err = qm.ApplyQuota(context.Background(), &Request{
+		Type:      TypeLeaseCount,
+		Path:      "kv/creds",
+		MountPath: "kv/",
+		LeaseID:   "kv/creds/1",
+		EntityID:  "entity-1",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+
+	_, err = qm.ApplyQuota(context.Background(), &Request{
+		Type:      TypeLeaseCount,
+		Path:      "pki/issue/test",
+		MountPath: "pki/",
+	})
+	require.Error(t, err)
+}
+
+func TestLeaseCountQuota_Recount(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink(), true)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i, entityID := range []string{"entity-1", "entity-1", "entity-2", ""} {
+		require.NoError(t, qm.UpdateLeaseCounts(ctx, LeaseActionLoaded, &Request{
+			Path:      "pki/issue/test",
+			MountPath: "pki/",
+			LeaseID:   fmt.Sprintf("pki/issue/test/%d", i),
+			EntityID:  entityID,
+		}))
+	}
+	require.NoError(t, qm.UpdateLeaseCounts(ctx, LeaseActionCreated, &Request{
+		Path:      "kv/creds",
+		MountPath: "kv/",
+		LeaseID:   "kv/creds/1",
+	}))
+
+	// Existing leases are counted against new quotas.
+	global := NewLeaseCountQuota("global", "", "", "", "", 10, false)
+	require.NoError(t, qm.SetQuota(ctx, TypeLeaseCount.String(), global, false))
+	require.Equal(t, 5, global.Count())
+
+	// A more specific quota takes over the leases of its mount.
+	mount := NewLeaseCountQuota("mount", "", "pki/", "", "", 1, true)
+	require.NoError(t, qm.SetQuota(ctx, TypeLeaseCount.String(), mount, false))
+	require.Equal(t, 4, mount.Count())
+	require.Equal(t, 2, mount.BucketCount("entity-1"))
+	require.Equal(t, 1, mount.BucketCount(NoEntityBucket))
+	require.Equal(t, 1, global.Count())
+
+	// Updating a quota carries its leases over.
+	updated := mount.Clone().(*LeaseCountQuota)
+	updated.PerEntity = false
+	require.NoError(t, qm.SetQuota(ctx, TypeLeaseCount.String(), updated, false))
+	require.Equal(t, 4, updated.BucketCount(""))
+
+	// Deleting a quota hands its leases back to the remaining ones.
+	require.NoError(t, qm.DeleteQuota(ctx, TypeLeaseCount.String(), "mount"))
+	require.Equal(t, 5, global.Count())
+
+	require.NoError(t, qm.UpdateLeaseCounts(ctx, LeaseActionDeleted, &Request{LeaseID: "kv/creds/1"}))
+	require.NoError(t, qm.UpdateLeaseCounts(ctx, LeaseActionDeleted, &Request{LeaseID: "kv/creds/1"}))
+	require.Equal(t, 4, global.Count())
+}
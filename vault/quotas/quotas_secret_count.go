@@ -0,0 +1,191 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package quotas
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-uuid"
+	"github.com/openbao/openbao/helper/metricsutil"
+	"github.com/openbao/openbao/sdk/v2/helper/cryptoutil"
+)
+
+// Ensure that SecretCountQuota implements the Quota interface
+var _ Quota = (*SecretCountQuota)(nil)
+
+// SecretCountQuota represents the quota rule properties that is used to limit
+// the number of secrets which may exist in the KV mounts of a namespace, or in
+// a single KV mount.
+type SecretCountQuota struct {
+	// ID is the identifier of the quota
+	ID string `json:"id"`
+
+	// Type of quota this represents
+	Type Type `json:"type"`
+
+	// Name of the quota rule
+	Name string `json:"name"`
+
+	// NamespacePath is the path of the namespace to which this quota is
+	// applicable.
+	NamespacePath string `json:"namespace_path"`
+
+	// MountPath is the path of the KV mount to which this quota is applicable
+	MountPath string `json:"mount_path"`
+
+	// PathSuffix and Role are never set on secret count quotas. They are
+	// only present so that the quotas can be indexed like the other types.
+	PathSuffix string `json:"path_suffix"`
+	Role       string `json:"role"`
+
+	// MaxSecrets defines the maximum number of secrets allowed by the quota.
+	MaxSecrets int `json:"max_secrets"`
+
+	lock       *sync.Mutex
+	count      int
+	logger     log.Logger
+	metricSink *metricsutil.ClusterMetricSink
+}
+
+// NewSecretCountQuota creates a quota checker for imposing limits on the
+// number of secrets which may exist at a time.
+func NewSecretCountQuota(name, nsPath, mountPath string, maxSecrets int) *SecretCountQuota {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		// Fall back to generating with a hash of the name, later in initialize
+		id = ""
+	}
+	return &SecretCountQuota{
+		Name:          name,
+		ID:            id,
+		Type:          TypeSecretCount,
+		NamespacePath: nsPath,
+		MountPath:     mountPath,
+		MaxSecrets:    maxSecrets,
+		lock:          new(sync.Mutex),
+	}
+}
+
+// Clone creates a copy of the quota, including the number of secrets counted
+// against it.
+func (q *SecretCountQuota) Clone() Quota {
+	return &SecretCountQuota{
+		ID:            q.ID,
+		Name:          q.Name,
+		MountPath:     q.MountPath,
+		Type:          q.Type,
+		NamespacePath: q.NamespacePath,
+		MaxSecrets:    q.MaxSecrets,
+		lock:          new(sync.Mutex),
+		count:         q.Count(),
+	}
+}
+
+// initialize ensures the namespace and max secrets are initialized and sets
+// the ID if it's currently empty. Unlike lease count quotas, the counter is
+// left as is; it is persisted separately and loaded by the quota manager.
+func (scq *SecretCountQuota) initialize(logger log.Logger, ms *metricsutil.ClusterMetricSink) error {
+	if scq.lock == nil {
+		scq.lock = new(sync.Mutex)
+	}
+
+	scq.lock.Lock()
+	defer scq.lock.Unlock()
+
+	// Memdb requires a non-empty value for indexing
+	if scq.NamespacePath == "" {
+		scq.NamespacePath = "root"
+	}
+
+	if scq.MaxSecrets <= 0 {
+		return fmt.Errorf("invalid max secrets: %v", scq.MaxSecrets)
+	}
+
+	if logger != nil {
+		scq.logger = logger
+	}
+
+	if scq.metricSink == nil {
+		scq.metricSink = ms
+	}
+
+	if scq.ID == "" {
+		scq.ID = hex.EncodeToString(cryptoutil.Blake2b256Hash(scq.Name))
+	}
+
+	return nil
+}
+
+// quotaID returns the identifier of the quota rule
+func (scq *SecretCountQuota) quotaID() string {
+	return scq.ID
+}
+
+// QuotaName returns the name of the quota rule
+func (scq *SecretCountQuota) QuotaName() string {
+	return scq.Name
+}
+
+// allow decides if a secret may be created. When allowed, the secret is
+// counted against the quota right away so that concurrent requests cannot
+// exceed the limit; it must be released if it ends up not being created.
+func (scq *SecretCountQuota) allow(ctx context.Context, req *Request) (Response, error) {
+	resp := Response{
+		Access: &access{quotaID: scq.ID},
+	}
+
+	scq.lock.Lock()
+	defer scq.lock.Unlock()
+
+	if scq.count >= scq.MaxSecrets {
+		scq.metricSink.IncrCounterWithLabels([]string{"quota", "secret_count", "violation"}, 1, []metrics.Label{{Name: "name", Value: scq.Name}})
+		return resp, nil
+	}
+
+	scq.count++
+	resp.Allowed = true
+	return resp, nil
+}
+
+// release removes a secret from the quota.
+func (scq *SecretCountQuota) release() {
+	scq.lock.Lock()
+	defer scq.lock.Unlock()
+
+	if scq.count > 0 {
+		scq.count--
+	}
+}
+
+// setCount replaces the number of secrets counted against the quota.
+func (scq *SecretCountQuota) setCount(count int) {
+	scq.lock.Lock()
+	defer scq.lock.Unlock()
+
+	scq.count = count
+}
+
+// Count returns the number of secrets counted against the quota.
+func (scq *SecretCountQuota) Count() int {
+	scq.lock.Lock()
+	defer scq.lock.Unlock()
+
+	return scq.count
+}
+
+// close is a no-op for secret count quotas, as they do not run any background
+// routines.
+func (scq *SecretCountQuota) close(ctx context.Context) error {
+	return nil
+}
+
+func (scq *SecretCountQuota) handleRemount(mountpath, nspath string) {
+	scq.MountPath = mountpath
+	scq.NamespacePath = nspath
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package quotas
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/helper/metricsutil"
+	"github.com/openbao/openbao/sdk/v2/helper/logging"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSecretCountQuota(t *testing.T) {
+	scq := NewSecretCountQuota("test-secret-count", "qa", "kv/", 0)
+	require.Error(t, scq.initialize(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink()))
+
+	scq = NewSecretCountQuota("test-secret-count", "qa", "kv/", 10)
+	require.NoError(t, scq.initialize(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink()))
+	require.Equal(t, TypeSecretCount, scq.Type)
+	require.NotEmpty(t, scq.ID)
+}
+
+func TestSecretCountQuota_Reserve(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink(), true)
+	require.NoError(t, err)
+	require.NoError(t, qm.Setup(ctx, storage))
+
+	scq := NewSecretCountQuota("kv", "", "kv/", 2)
+	entry, err := logical.StorageEntryJSON(QuotaStoragePath(TypeSecretCount.String(), scq.Name), scq)
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, entry))
+	require.NoError(t, qm.SetQuota(ctx, TypeSecretCount.String(), scq, false))
+
+	reserve := func(mountPath string) bool {
+		t.Helper()
+		resp, err := qm.ReserveSecret(ctx, &Request{
+			Path:      mountPath,
+			MountPath: mountPath,
+		})
+		require.NoError(t, err)
+		return resp.Allowed
+	}
+
+	require.True(t, reserve("kv/"))
+	require.True(t, reserve("kv/"))
+	require.False(t, reserve("kv/"))
+	require.Equal(t, 2, scq.Count())
+
+	// Other mounts are not limited
+	require.True(t, reserve("other/"))
+
+	// Released secrets free room
+	require.NoError(t, qm.ReleaseSecret(ctx, &Request{Path: "kv/", MountPath: "kv/"}))
+	require.Equal(t, 1, scq.Count())
+	require.True(t, reserve("kv/"))
+
+	// The counter is persisted, and loaded again with the quota
+	require.NoError(t, qm.SetSecretCount(ctx, "kv", 1))
+	require.NoError(t, qm.Setup(ctx, storage))
+	quota, err := qm.QuotaByName(TypeSecretCount.String(), "kv")
+	require.NoError(t, err)
+	require.Equal(t, 1, quota.(*SecretCountQuota).Count())
+
+	// Deleting the quota deletes its counter
+	require.NoError(t, storage.Delete(ctx, QuotaStoragePath(TypeSecretCount.String(), "kv")))
+	require.NoError(t, qm.DeleteQuota(ctx, TypeSecretCount.String(), "kv"))
+	entry, err = storage.Get(ctx, SecretCountStoragePath("kv"))
+	require.NoError(t, err)
+	require.Nil(t, entry)
+}
@@ -6,5 +6,7 @@ package quotas
 func quotaTypes() []string {
 	return []string{
 		TypeRateLimit.String(),
+		TypeLeaseCount.String(),
+		TypeSecretCount.String(),
 	}
 }
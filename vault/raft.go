@@ -193,6 +193,12 @@ func (c *Core) setupRaftActiveNode(ctx context.Context) error {
 
 	c.pendingRaftPeers = &sync.Map{}
 
+	// Automatic snapshots are taken by the active node only. They are
+	// meaningless when raft is only used for HA.
+	if !c.isRaftHAOnly() {
+		raftBackend.StartAutoSnapshots(c.seal.GetAccess())
+	}
+
 	// Reload the raft TLS keys to ensure we are using the latest version.
 	if err := c.checkRaftTLSKeyUpgrades(ctx); err != nil {
 		return err
@@ -212,6 +218,7 @@ func (c *Core) stopRaftActiveNode() {
 	if !raftBackend.AutopilotDisabled() {
 		raftBackend.StopAutopilot()
 	}
+	raftBackend.StopAutoSnapshots()
 
 	c.pendingRaftPeers = nil
 	c.stopPeriodicRaftTLSRotate()
@@ -192,6 +192,9 @@ func (c *Core) BarrierRekeyInit(config *SealConfig) logical.HTTPCodedError {
 		if len(config.PGPKeys) > 0 {
 			return logical.CodedError(http.StatusBadRequest, "PGP key encryption not supported when using stored keys")
 		}
+		if len(config.ShareCredentials) > 0 {
+			return logical.CodedError(http.StatusBadRequest, "share credentials not supported when using stored keys")
+		}
 		if config.Backup {
 			return logical.CodedError(http.StatusBadRequest, "key backup not supported when using stored keys")
 		}
@@ -249,6 +252,9 @@ func (c *Core) RecoveryRekeyInit(config *SealConfig) logical.HTTPCodedError {
 	if config.StoredShares > 0 {
 		return logical.CodedError(http.StatusBadRequest, "stored shares not supported by recovery key")
 	}
+	if len(config.ShareCredentials) > 0 {
+		return logical.CodedError(http.StatusBadRequest, "share credentials not supported by recovery key")
+	}
 
 	// Check if the seal configuration is valid
 	if err := config.Validate(); err != nil {
@@ -451,6 +457,7 @@ func (c *Core) BarrierRekeyUpdate(ctx context.Context, key []byte, nonce string)
 			}
 			results.SecretShares = shares
 		}
+		c.barrierRekeyConfig.bindShareCredentials(results.SecretShares)
 	}
 
 	// If PGP keys are passed in, encrypt shares with corresponding PGP keys.
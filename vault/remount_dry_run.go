@@ -0,0 +1,229 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openbao/openbao/helper/namespace"
+)
+
+// The ways in which a policy path may refer to a mount, from the most to
+// the least specific, as reported by a remount dry run.
+const (
+	remountRefExact     = "exact"
+	remountRefPrefix    = "prefix"
+	remountRefWildcard  = "wildcard"
+	remountRefTemplated = "templated"
+)
+
+// remountPolicyReference is a path of a policy which refers to the mount
+// being moved.
+type remountPolicyReference struct {
+	Policy       string   `json:"policy" mapstructure:"policy"`
+	Path         string   `json:"path" mapstructure:"path"`
+	Capabilities []string `json:"capabilities" mapstructure:"capabilities"`
+	Match        string   `json:"match" mapstructure:"match"`
+}
+
+// remountAuditReference is an audit filter rule which refers to the mount
+// being moved.
+type remountAuditReference struct {
+	Device string `json:"device" mapstructure:"device"`
+	Option string `json:"option" mapstructure:"option"`
+	Rule   string `json:"rule" mapstructure:"rule"`
+}
+
+// remountQuotaReference is a quota rule applying to the mount being moved,
+// which the remount updates by itself.
+type remountQuotaReference struct {
+	Type string `json:"type" mapstructure:"type"`
+	Name string `json:"name" mapstructure:"name"`
+}
+
+// remountReferences lists what refers to a mount and would be affected by
+// moving it, without moving it.
+type remountReferences struct {
+	Policies     []*remountPolicyReference `json:"policies" mapstructure:"policies"`
+	AuditFilters []*remountAuditReference  `json:"audit_filters" mapstructure:"audit_filters"`
+	Quotas       []*remountQuotaReference  `json:"quotas" mapstructure:"quotas"`
+}
+
+// remountReferences collects the ACL policies, audit filter rules and quota
+// rules which refer to the mount at from. Policies and audit filters are
+// matched against their parsed paths only, so that this stays cheap even
+// with thousands of policies, most of which are served from the policy
+// cache.
+func (c *Core) remountReferences(ctx context.Context, from namespace.MountPathDetails) (*remountReferences, error) {
+	mountPath := sanitizePath(from.MountPath)
+	refs := &remountReferences{
+		Policies:     []*remountPolicyReference{},
+		AuditFilters: []*remountAuditReference{},
+		Quotas:       []*remountQuotaReference{},
+	}
+
+	policyCtx := namespace.ContextWithNamespace(ctx, from.Namespace)
+	names, err := c.policyStore.ListPolicies(policyCtx, PolicyTypeACL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	for _, name := range names {
+		policy, err := c.policyStore.GetPolicy(policyCtx, name, PolicyTypeACL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %q: %w", name, err)
+		}
+		if policy == nil {
+			continue
+		}
+		for _, rule := range policy.Paths {
+			match, ok := policyPathReferencesMount(rule, mountPath)
+			if !ok {
+				continue
+			}
+			path := rule.Path
+			if rule.IsPrefix {
+				path += "*"
+			}
+			refs.Policies = append(refs.Policies, &remountPolicyReference{
+				Policy:       name,
+				Path:         path,
+				Capabilities: rule.Capabilities,
+				Match:        match,
+			})
+		}
+	}
+
+	// Audit filters match against the full path of requests, including
+	// their namespace
+	auditPath := from.Namespace.Path + mountPath
+	c.auditLock.RLock()
+	var auditEntries []*MountEntry
+	if c.audit != nil {
+		auditEntries = c.audit.Entries
+	}
+	for _, entry := range auditEntries {
+		for _, option := range []string{auditFilterAllowOption, auditFilterDenyOption} {
+			rules, err := parseAuditFilterRules(entry.Options[option])
+			if err != nil {
+				continue
+			}
+			for _, rule := range rules {
+				if auditRuleReferencesMount(rule, auditPath) {
+					raw := rule.prefix
+					if rule.operation != "" {
+						raw = string(rule.operation) + ":" + raw
+					}
+					refs.AuditFilters = append(refs.AuditFilters, &remountAuditReference{
+						Device: entry.Path,
+						Option: option,
+						Rule:   raw,
+					})
+				}
+			}
+		}
+	}
+	c.auditLock.RUnlock()
+
+	if c.quotaManager == nil {
+		return refs, nil
+	}
+	quotaNames, err := c.quotaManager.QuotaNamesForMount(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up quotas: %w", err)
+	}
+	for quotaType, names := range quotaNames {
+		for _, name := range names {
+			refs.Quotas = append(refs.Quotas, &remountQuotaReference{
+				Type: quotaType,
+				Name: name,
+			})
+		}
+	}
+	sort.Slice(refs.Quotas, func(i, j int) bool {
+		if refs.Quotas[i].Type != refs.Quotas[j].Type {
+			return refs.Quotas[i].Type < refs.Quotas[j].Type
+		}
+		return refs.Quotas[i].Name < refs.Quotas[j].Name
+	})
+
+	return refs, nil
+}
+
+// policyPathReferencesMount returns whether the policy path could match
+// any path within the mount, and how. Paths are compared segment by
+// segment: "+" segments and templated segments match any segment of the
+// mount path, and a trailing glob matches any segment it is a prefix of.
+func policyPathReferencesMount(rule *PathRules, mountPath string) (string, bool) {
+	mountSegs := strings.Split(strings.TrimSuffix(mountPath, "/"), "/")
+	ruleSegs := strings.Split(rule.Path, "/")
+
+	var wildcard, templated bool
+	for i, mountSeg := range mountSegs {
+		if i >= len(ruleSegs) {
+			// The rule ends above the mount
+			return "", false
+		}
+		ruleSeg := ruleSegs[i]
+		last := i == len(ruleSegs)-1
+
+		// Segment wildcard rules keep their trailing glob
+		glob := last && (rule.IsPrefix || strings.HasSuffix(ruleSeg, "*"))
+		if glob {
+			ruleSeg = strings.TrimSuffix(ruleSeg, "*")
+		}
+
+		switch {
+		case strings.Contains(ruleSeg, "{{"):
+			templated = true
+			// Literal text ahead of the template still has to match
+			literal, _, _ := strings.Cut(ruleSeg, "{{")
+			if !strings.HasPrefix(mountSeg, literal) {
+				return "", false
+			}
+		case ruleSeg == "+":
+			wildcard = true
+		case glob:
+			if !strings.HasPrefix(mountSeg, ruleSeg) {
+				return "", false
+			}
+			if ruleSeg != mountSeg {
+				wildcard = true
+			}
+		case ruleSeg != mountSeg:
+			return "", false
+		}
+
+		if glob {
+			// The rest of the path is covered by the glob
+			break
+		}
+		if last && i < len(mountSegs)-1 {
+			return "", false
+		}
+	}
+
+	switch {
+	case templated:
+		return remountRefTemplated, true
+	case wildcard:
+		return remountRefWildcard, true
+	case rule.IsPrefix:
+		return remountRefPrefix, true
+	}
+	return remountRefExact, true
+}
+
+// auditRuleReferencesMount returns whether the audit filter rule matches
+// paths within the mount, either pointing into it or covering it entirely.
+func auditRuleReferencesMount(rule *auditFilterRule, mountPath string) bool {
+	if rule.prefix == "" {
+		return false
+	}
+	return strings.HasPrefix(rule.prefix, mountPath) ||
+		strings.HasPrefix(mountPath, rule.prefix) ||
+		rule.prefix == strings.TrimSuffix(mountPath, "/")
+}
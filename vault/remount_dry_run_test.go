@@ -0,0 +1,135 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+func TestPolicyPathReferencesMount(t *testing.T) {
+	cases := []struct {
+		path  string
+		match string
+	}{
+		{"secret/data/foo", remountRefExact},
+		{"secret/", remountRefExact},
+		{"secret/*", remountRefPrefix},
+		{"secret*", remountRefPrefix},
+		{"*", remountRefWildcard},
+		{"sec*", remountRefWildcard},
+		{"+/data/foo", remountRefWildcard},
+		{"secret/+/foo", remountRefExact},
+		{"{{identity.entity.name}}/data/*", remountRefTemplated},
+		{"secret/data/{{identity.entity.name}}/*", remountRefPrefix},
+		{"sec{{identity.entity.name}}/*", remountRefTemplated},
+		{"kv{{identity.entity.name}}/*", ""},
+		{"secretive/*", ""},
+		{"secrets", ""},
+		{"sys/mounts", ""},
+		{"sys/*", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			policy, err := ParseACLPolicy(namespace.RootNamespace, fmt.Sprintf(`path %q { capabilities = ["read"] }`, tc.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			match, ok := policyPathReferencesMount(policy.Paths[0], "secret/")
+			if ok != (tc.match != "") {
+				t.Fatalf("expected reference %t, got %t", tc.match != "", ok)
+			}
+			if match != tc.match {
+				t.Fatalf("expected match %q, got %q", tc.match, match)
+			}
+		})
+	}
+}
+
+func TestSystemBackend_remount_dryRun(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	policies := []string{`
+name = "exact"
+path "secret/data/foo" {
+	capabilities = ["read"]
+}
+path "sys/mounts" {
+	capabilities = ["read"]
+}
+`, `
+name = "templated"
+path "secret/data/{{identity.entity.name}}/*" {
+	capabilities = ["read", "update"]
+}
+path "{{identity.entity.metadata.mount}}/config" {
+	capabilities = ["read"]
+}
+`, `
+name = "unrelated"
+path "kv/*" {
+	capabilities = ["read"]
+}
+`}
+	for _, raw := range policies {
+		policy, err := ParseACLPolicy(namespace.RootNamespace, raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.policyStore.SetPolicy(ctx, policy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "remount")
+	req.Data["from"] = "secret"
+	req.Data["to"] = "foo"
+	req.Data["dry_run"] = true
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	if _, ok := resp.Data["migration_id"]; ok {
+		t.Fatal("dry run started a migration")
+	}
+	if c.router.MatchingMountEntry(ctx, "secret/") == nil {
+		t.Fatal("dry run moved the mount")
+	}
+
+	refs := resp.Data["policies"].([]*remountPolicyReference)
+	expected := map[string]string{
+		"exact/secret/data/foo":                               remountRefExact,
+		"templated/secret/data/{{identity.entity.name}}/*":    remountRefPrefix,
+		"templated/{{identity.entity.metadata.mount}}/config": remountRefTemplated,
+	}
+	if len(refs) != len(expected) {
+		t.Fatalf("expected %d policy references, got %#v", len(expected), refs)
+	}
+	for _, ref := range refs {
+		match, ok := expected[ref.Policy+"/"+ref.Path]
+		if !ok {
+			t.Fatalf("unexpected policy reference %#v", ref)
+		}
+		if ref.Match != match {
+			t.Fatalf("expected %s/%s to match %q, got %q", ref.Policy, ref.Path, match, ref.Match)
+		}
+	}
+
+	// Validation still applies to dry runs
+	req = logical.TestRequest(t, logical.UpdateOperation, "remount")
+	req.Data["from"] = "secret"
+	req.Data["to"] = "sys"
+	req.Data["dry_run"] = true
+	resp, err = b.HandleRequest(ctx, req)
+	if err == nil || !resp.IsError() {
+		t.Fatalf("expected error, got resp: %#v", resp)
+	}
+}
@@ -21,12 +21,18 @@ import (
 	log "github.com/hashicorp/go-hclog"
 	"github.com/openbao/openbao/helper/forwarding"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
+	"github.com/openbao/openbao/sdk/v2/logical"
 	"github.com/openbao/openbao/vault/cluster"
 	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 )
 
+// forwardedClientCertThumbprintHeader carries the client certificate
+// thumbprint of a forwarded request from the standby node which got it from a
+// trusted proxy to the active node.
+const forwardedClientCertThumbprintHeader = "X-Vault-Forwarded-Client-Cert-Thumbprint"
+
 type requestForwardingHandler struct {
 	fws         *http2.Server
 	fwRPCServer *grpc.Server
@@ -115,6 +121,13 @@ func (c *requestForwardingClusterClient) CACert(ctx context.Context) *x509.Certi
 // ServerLookup satisfies the ClusterHandler interface and returns the server's
 // tls certs.
 func (rf *requestForwardingHandler) ServerLookup(ctx context.Context, clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	// Standbys which have yet to load a rotated cert still ask for the
+	// previous one
+	prevCert := rf.core.localClusterPrevCert.Load().(*tls.Certificate)
+	if prevCert != nil && clientHello.ServerName == prevCert.Leaf.Subject.CommonName && time.Now().Before(prevCert.Leaf.NotAfter) {
+		return prevCert, nil
+	}
+
 	currCert := rf.core.localClusterCert.Load().([]byte)
 	if len(currCert) == 0 {
 		return nil, fmt.Errorf("got forwarding connection but no local cert")
@@ -138,7 +151,13 @@ func (rf *requestForwardingHandler) CALookup(ctx context.Context) ([]*x509.Certi
 		return nil, fmt.Errorf("forwarding connection client but no local cert")
 	}
 
-	return []*x509.Certificate{parsedCert}, nil
+	caList := []*x509.Certificate{parsedCert}
+	prevCert := rf.core.localClusterPrevCert.Load().(*tls.Certificate)
+	if prevCert != nil && time.Now().Before(prevCert.Leaf.NotAfter) {
+		caList = append(caList, prevCert.Leaf)
+	}
+
+	return caList, nil
 }
 
 // Handoff serves a request forwarding connection.
@@ -347,6 +366,14 @@ func (c *Core) ForwardRequest(req *http.Request) (int, http.Header, []byte, erro
 		c.logger.Error("got nil forwarding RPC request")
 		return 0, nil, nil, fmt.Errorf("got nil forwarding RPC request")
 	}
+
+	// The header is only ever set here, never taken from the client.
+	delete(freq.HeaderEntries, forwardedClientCertThumbprintHeader)
+	if thumbprint, ok := logical.ContextClientCertThumbprintValue(req.Context()); ok {
+		freq.HeaderEntries[forwardedClientCertThumbprintHeader] = &forwarding.HeaderEntry{
+			Values: []string{thumbprint},
+		}
+	}
 	resp, err := c.rpcForwardingClient.ForwardRequest(req.Context(), freq)
 	if err != nil {
 		metrics.IncrCounter([]string{"ha", "rpc", "client", "forward", "errors"}, 1)
@@ -15,6 +15,7 @@ import (
 	"github.com/openbao/openbao/helper/forwarding"
 	"github.com/openbao/openbao/physical/raft"
 	"github.com/openbao/openbao/sdk/v2/helper/consts"
+	"github.com/openbao/openbao/sdk/v2/logical"
 )
 
 type forwardedRequestRPCServer struct {
@@ -32,6 +33,15 @@ func (s *forwardedRequestRPCServer) ForwardRequest(ctx context.Context, freq *fo
 		return nil, err
 	}
 
+	// Only standby nodes which trusted the proxy sending the request set the
+	// client certificate thumbprint header
+	if values, ok := req.Header[forwardedClientCertThumbprintHeader]; ok {
+		req.Header.Del(forwardedClientCertThumbprintHeader)
+		if len(values) > 0 {
+			req = req.WithContext(logical.CreateContextClientCertThumbprint(req.Context(), values[0]))
+		}
+	}
+
 	// A very dummy response writer that doesn't follow normal semantics, just
 	// lets you write a status code (last written wins) and a body. But it
 	// meets the interface requirements.
@@ -36,6 +36,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/policyutil"
 	"github.com/openbao/openbao/sdk/v2/helper/wrapping"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/openbao/openbao/vault/quotas"
 	"github.com/openbao/openbao/vault/tokens"
 )
 
@@ -262,6 +263,11 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		}
 	}
 
+	// Client certificate checks bind all tokens, including root tokens
+	if !tokenClientCertMatches(te, req) {
+		return nil, nil, nil, nil, logical.ErrPermissionDenied
+	}
+
 	policyNames := make(map[string][]string)
 	// Add tokens policies
 	policyNames[te.NamespaceID] = append(policyNames[te.NamespaceID], te.Policies...)
@@ -313,7 +319,9 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 	}
 
 	// Construct the corresponding ACL object. ACL construction should be
-	// performed on the token's namespace.
+	// performed on the token's namespace. The request data is made available
+	// to the policies declaring request parameters.
+	tokenCtx = contextWithPolicyRequestData(tokenCtx, req.Data)
 	acl, err := c.policyStore.ACL(tokenCtx, entity, policyNames, policies...)
 	if err != nil {
 		c.logger.Error("failed to construct ACL", "error", err)
@@ -447,6 +455,12 @@ func (c *Core) CheckToken(ctx context.Context, req *logical.Request, unauth bool
 		req.ClientID = clientID
 	}
 
+	// Apply the response wrapping configuration of the namespace before the
+	// policies check the wrapping TTL.
+	if err := c.checkWrappingConfig(ctx, req); err != nil {
+		return auth, te, err
+	}
+
 	// Check the standard non-root ACLs. Return the token entry if it's not
 	// allowed so we can decrement the use count.
 	authResults := c.performPolicyChecks(ctx, acl, te, req, entity, &PolicyCheckOpts{
@@ -583,10 +597,11 @@ func (c *Core) handleCancelableRequest(ctx context.Context, req *logical.Request
 		switch req.Path {
 		// Route the token wrapping request to its respective sys NS
 		case "sys/wrapping/lookup", "sys/wrapping/rewrap", "sys/wrapping/unwrap":
-			ctx = newCtx
 			// A lookup on a token that is about to expire returns nil, which means by the
 			// time we can validate a wrapping token lookup will return nil since it will
-			// be revoked after the call. So we have to do the validation here.
+			// be revoked after the call. So we have to do the validation here. This is
+			// done in the namespace of the request, as wrapping tokens cannot be used
+			// outside of the namespace they were created in.
 			valid, err := c.validateWrappingToken(ctx, req)
 			if err != nil {
 				return logical.ErrorResponse(fmt.Sprintf("error validating wrapping token: %s", err.Error())), logical.ErrPermissionDenied
@@ -594,6 +609,7 @@ func (c *Core) handleCancelableRequest(ctx context.Context, req *logical.Request
 			if !valid {
 				return nil, consts.ErrInvalidWrappingToken
 			}
+			ctx = newCtx
 
 		// The -self paths have no meaning outside of the token NS, so
 		// requests for these paths always go to the token NS
@@ -960,8 +976,22 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 		return nil, auth, retErr
 	}
 
+	// Count secrets created in KV mounts against the secret count quotas
+	secretChange, err := c.applySecretCountQuota(ctx, entry, req)
+	if err != nil {
+		if !errors.Is(err, quotas.ErrSecretCountQuotaExceeded) {
+			c.logger.Error("failed to apply secret count quota", "request_path", req.Path, "error", err)
+			err = ErrInternalError
+		}
+		retErr = multierror.Append(retErr, err)
+		return nil, auth, retErr
+	}
+
 	// Route the request
 	resp, routeErr := c.doRouting(ctx, req)
+	if secretChange != 0 {
+		c.settleSecretCountQuota(ctx, entry, secretChange, resp, routeErr)
+	}
 	if resp != nil {
 
 		// If wrapping is used, use the shortest between the request and response
@@ -1069,6 +1099,10 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 
 			leaseID, err := c.expiration.Register(ctx, req, resp, "")
 			if err != nil {
+				if errors.Is(err, quotas.ErrLeaseCountQuotaExceeded) {
+					retErr = multierror.Append(retErr, err)
+					return nil, auth, retErr
+				}
 				c.logger.Error("failed to register lease", "request_path", req.Path, "error", err)
 				retErr = multierror.Append(retErr, ErrInternalError)
 				return nil, auth, retErr
@@ -1154,6 +1188,10 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 					if err := c.tokenStore.revokeOrphan(ctx, resp.Auth.ClientToken); err != nil {
 						c.logger.Warn("failed to clean up token lease during auth/token/ request", "request_path", req.Path, "error", err)
 					}
+					if errors.Is(err, quotas.ErrLeaseCountQuotaExceeded) {
+						retErr = multierror.Append(retErr, err)
+						return nil, auth, retErr
+					}
 					c.logger.Error("failed to register token lease during auth/token/ request", "request_path", req.Path, "error", err)
 					retErr = multierror.Append(retErr, ErrInternalError)
 					return nil, auth, retErr
@@ -1230,9 +1268,12 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 		// If it is an internal error we return that, otherwise we
 		// return invalid request so that the status codes can be correct
 		var errType error
-		switch ctErr {
-		case ErrInternalError, logical.ErrPermissionDenied:
+		switch {
+		case ctErr == ErrInternalError:
 			errType = ctErr
+		case errors.Is(ctErr, logical.ErrPermissionDenied):
+			// Such as a denial by the response wrapping configuration
+			errType = logical.ErrPermissionDenied
 		default:
 			errType = logical.ErrInvalidRequest
 		}
@@ -1430,11 +1471,18 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 		}
 		// finding the MFAEnforcementConfig that matches the ns and either of
 		// entityID, MountAccessor, GroupID, or Auth type.
-		matchedMfaEnforcementList, err := c.buildMFAEnforcementConfigList(ctx, entity, req.Path)
+		matchedMfaEnforcementList, exemptMfaEnforcementList, err := c.buildMFAEnforcementConfigList(ctx, entity, auth, req.Path)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to find MFAEnforcement configuration, error: %v", err)
 		}
 
+		// Logins exempted from an enforcement because they issue batch tokens
+		// are reported, so that the exemption shows up in the audit log.
+		for _, eConfig := range exemptMfaEnforcementList {
+			resp.AddWarning(fmt.Sprintf("login is exempt from MFA login enforcement %q as it issues a batch token", eConfig.Name))
+			c.logger.Info("login exempt from MFA login enforcement", "enforcement", eConfig.Name, "path", req.Path, "entity_id", auth.EntityID)
+		}
+
 		// (for the context, a response warning above says: "primary cluster
 		// doesn't yet issue entities for local auth mounts; falling back
 		// to not issuing entities for local auth mounts")
@@ -1629,7 +1677,7 @@ func (c *Core) LoginCreateToken(ctx context.Context, ns *namespace.Namespace, re
 		if auth.TokenType != logical.TokenTypeBatch {
 			leaseGenerated = true
 		}
-	case err == ErrInternalError:
+	case err == ErrInternalError, errors.Is(err, quotas.ErrLeaseCountQuotaExceeded):
 		return false, nil, err
 	default:
 		return false, logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -1978,6 +2026,9 @@ func (c *Core) RegisterAuth(ctx context.Context, tokenTTL time.Duration, path st
 			if err := c.tokenStore.revokeOrphan(ctx, te.ID); err != nil {
 				c.logger.Warn("failed to clean up token lease during login request", "request_path", path, "error", err)
 			}
+			if errors.Is(err, quotas.ErrLeaseCountQuotaExceeded) {
+				return err
+			}
 			c.logger.Error("failed to register token lease during login request", "request_path", path, "error", err)
 			return ErrInternalError
 		}
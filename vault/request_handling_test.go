@@ -268,24 +268,25 @@ func TestRequestHandling_Login_PeriodicToken(t *testing.T) {
 	}
 
 	exp := map[string]interface{}{
-		"accessor":         accessor,
-		"creation_time":    resp.Data["creation_time"].(int64),
-		"creation_ttl":     int64(5),
-		"display_name":     "approle",
-		"entity_id":        entityID,
-		"expire_time":      resp.Data["expire_time"].(time.Time),
-		"explicit_max_ttl": int64(0),
-		"id":               loginToken,
-		"issue_time":       resp.Data["issue_time"].(time.Time),
-		"meta":             map[string]string{"role_name": "role-period"},
-		"num_uses":         0,
-		"orphan":           true,
-		"path":             "auth/approle/login",
-		"period":           int64(5),
-		"policies":         []string{"default"},
-		"renewable":        true,
-		"ttl":              int64(5),
-		"type":             "service",
+		"accessor":           accessor,
+		"creation_time":      resp.Data["creation_time"].(int64),
+		"creation_ttl":       int64(5),
+		"display_name":       "approle",
+		"entity_id":          entityID,
+		"expire_time":        resp.Data["expire_time"].(time.Time),
+		"explicit_max_ttl":   int64(0),
+		"id":                 loginToken,
+		"issue_time":         resp.Data["issue_time"].(time.Time),
+		"meta":               map[string]string{"role_name": "role-period"},
+		"num_uses":           0,
+		"orphan":             true,
+		"path":               "auth/approle/login",
+		"period":             int64(5),
+		"policies":           []string{"default"},
+		"renewable":          true,
+		"suggested_renew_at": resp.Data["suggested_renew_at"].(time.Time),
+		"ttl":                int64(5),
+		"type":               "service",
 	}
 
 	if diff := deep.Equal(resp.Data, exp); diff != nil {
@@ -66,6 +66,12 @@ type routeEntry struct {
 	rootPaths     atomic.Value
 	loginPaths    atomic.Value
 	l             sync.RWMutex
+
+	// abort is cancelled, with the error to fail them with, to abort the
+	// requests in flight when a graceful reload of the backend times out
+	// draining them. It is replaced under the write lock once cancelled.
+	abort       context.Context
+	abortCancel context.CancelCauseFunc
 }
 
 type wildcardPath struct {
@@ -195,6 +201,7 @@ func (r *Router) Mount(backend logical.Backend, prefix string, mountEntry *Mount
 		storagePrefix: storageView.Prefix(),
 		storageView:   storageView,
 	}
+	re.abort, re.abortCancel = context.WithCancelCause(context.Background())
 	re.rootPaths.Store(pathsToRadix(paths.Root))
 	loginPathsEntry, err := parseUnauthenticatedPaths(paths.Unauthenticated)
 	if err != nil {
@@ -593,9 +600,23 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 	// token store; such a request will have already been routed through the
 	// token store -> exp manager -> here so we need to not grab the lock again
 	// or we'll be recursively grabbing it.
+	var abort context.Context
 	if !(req.Operation == logical.RenewOperation && strings.HasPrefix(req.Path, "auth/token/")) {
 		re.l.RLock()
 		defer re.l.RUnlock()
+
+		// Tie the request to the route entry so that a graceful reload can
+		// abort it if it does not complete in time
+		abort = re.abort
+		if abort != nil {
+			var cancel context.CancelCauseFunc
+			ctx, cancel = context.WithCancelCause(ctx)
+			defer cancel(nil)
+			stop := context.AfterFunc(abort, func() {
+				cancel(context.Cause(abort))
+			})
+			defer stop()
+		}
 	}
 
 	// Filtered mounts will have a nil backend
@@ -767,12 +788,37 @@ func (r *Router) routeCommon(ctx context.Context, req *logical.Request, existenc
 		req.SetTokenEntry(reqTokenEntry)
 	}()
 
+	// Bound the time the backend may spend on the request. The deadline is
+	// carried by the context down to storage and plugin RPC calls.
+	var requestTimeout time.Duration
+	if rawVal, ok := re.mountEntry.synthesizedConfigCache.Load("request_timeout"); ok {
+		requestTimeout = rawVal.(time.Duration)
+	}
+	var timeoutErr error
+	if requestTimeout > 0 {
+		timeoutErr = fmt.Errorf("%w: mount %q did not complete the request within %s", logical.ErrRequestTimeout, mount, requestTimeout)
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, requestTimeout, timeoutErr)
+		defer cancel()
+	}
+
 	// Invoke the backend
 	if existenceCheck {
 		ok, exists, err := re.backend.HandleExistenceCheck(ctx, req)
+		if err != nil && timeoutErr != nil && context.Cause(ctx) == timeoutErr {
+			err = timeoutErr
+		}
 		return nil, ok, exists, err
 	} else {
 		resp, err := re.backend.HandleRequest(ctx, req)
+		if err != nil && timeoutErr != nil && context.Cause(ctx) == timeoutErr {
+			r.logger.Warn("request timed out", "mount", mount, "path", req.Path, "request_timeout", requestTimeout)
+			return nil, false, false, timeoutErr
+		}
+		if err != nil && abort != nil && abort.Err() != nil && context.Cause(ctx) == context.Cause(abort) {
+			return nil, false, false, context.Cause(abort)
+		}
 		if resp != nil {
 			if len(allowedResponseHeaders) > 0 {
 				resp.Headers = filteredHeaders(resp.Headers, allowedResponseHeaders, nil)
@@ -4,9 +4,13 @@
 package vault
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-uuid"
 	"github.com/openbao/openbao/helper/namespace"
@@ -631,3 +635,76 @@ func TestParseUnauthenticatedPaths_Error(t *testing.T) {
 		}
 	}
 }
+
+func TestRouter_RequestTimeout(t *testing.T) {
+	r := NewRouter()
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "logical/")
+
+	meUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mountEntry := &MountEntry{
+		Path:        "slow/",
+		UUID:        meUUID,
+		Accessor:    "slowaccessor",
+		NamespaceID: namespace.RootNamespaceID,
+		namespace:   namespace.RootNamespace,
+		Config: MountConfig{
+			RequestTimeout: 50 * time.Millisecond,
+		},
+	}
+	mountEntry.SyncCache()
+
+	n := &NoopBackend{
+		RequestHandler: func(ctx context.Context, req *logical.Request) (*logical.Response, error) {
+			if req.Path == "fast" {
+				return nil, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	if err := r.Mount(n, "slow/", mountEntry, view); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &logical.Request{
+		Path: "slow/hang",
+	}
+	start := time.Now()
+	_, err = r.Route(namespace.RootContext(nil), req)
+	if !errors.Is(err, logical.ErrRequestTimeout) {
+		t.Fatalf("expected request timeout, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"slow/"`) {
+		t.Fatalf("expected error to identify the mount, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("request was not aborted, took %s", elapsed)
+	}
+	if code, _ := logical.RespondErrorCommon(req, nil, err); code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status %d, got %d", http.StatusGatewayTimeout, code)
+	}
+
+	// Requests completing in time are unaffected
+	req = &logical.Request{
+		Path: "slow/fast",
+	}
+	if _, err := r.Route(namespace.RootContext(nil), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A caller's own cancellation is not reported as a timeout
+	ctx, cancel := context.WithCancel(namespace.RootContext(nil))
+	cancel()
+	req = &logical.Request{
+		Path: "slow/hang",
+	}
+	_, err = r.Route(ctx, req)
+	if err == nil || errors.Is(err, logical.ErrRequestTimeout) {
+		t.Fatalf("expected cancellation error, got: %v", err)
+	}
+}
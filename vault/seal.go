@@ -334,6 +334,20 @@ type SealConfig struct {
 	// How many keys to store, for seals that support storage.  Always 0 or 1.
 	StoredShares int `json:"stored_shares" mapstructure:"stored_shares"`
 
+	// ShareCredentials are the hardware token credentials registered for the
+	// key shares, if requested. A share bound to a credential is only counted
+	// when submitted along with an assertion of that credential. If
+	// provided, it must match SecretShares. Ordering is important.
+	ShareCredentials []*ShareCredential `json:"share_credentials,omitempty" mapstructure:"share_credentials"`
+
+	// ShareCredentialsRPID is the WebAuthn relying party ID the share
+	// credentials were registered for.
+	ShareCredentialsRPID string `json:"share_credentials_rp_id,omitempty" mapstructure:"share_credentials_rp_id"`
+
+	// Providers are the names of the KMS providers of a multi-provider auto
+	// seal, any of which can unseal.
+	Providers []string `json:"providers,omitempty" mapstructure:"providers"`
+
 	// Stores the progress of the rekey operation (key shares)
 	RekeyProgress [][]byte `json:"-"`
 
@@ -392,6 +406,19 @@ func (s *SealConfig) Validate() error {
 			}
 		}
 	}
+	if len(s.ShareCredentials) > 0 && len(s.ShareCredentials) != s.SecretShares {
+		return fmt.Errorf("count mismatch between number of provided share credentials and number of shares")
+	}
+	if len(s.ShareCredentials) > 0 {
+		if s.ShareCredentialsRPID == "" {
+			return fmt.Errorf("a relying party ID must be provided with share credentials")
+		}
+		for _, cred := range s.ShareCredentials {
+			if _, err := parseShareCredentialKey(cred.PublicKey); err != nil {
+				return fmt.Errorf("error parsing given share credential: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -405,11 +432,22 @@ func (s *SealConfig) Clone() *SealConfig {
 		StoredShares:         s.StoredShares,
 		VerificationRequired: s.VerificationRequired,
 		VerificationNonce:    s.VerificationNonce,
+		ShareCredentialsRPID: s.ShareCredentialsRPID,
 	}
 	if len(s.PGPKeys) > 0 {
 		ret.PGPKeys = make([]string, len(s.PGPKeys))
 		copy(ret.PGPKeys, s.PGPKeys)
 	}
+	if len(s.Providers) > 0 {
+		ret.Providers = make([]string, len(s.Providers))
+		copy(ret.Providers, s.Providers)
+	}
+	if len(s.ShareCredentials) > 0 {
+		ret.ShareCredentials = make([]*ShareCredential, len(s.ShareCredentials))
+		for i, cred := range s.ShareCredentials {
+			ret.ShareCredentials[i] = cred.clone()
+		}
+	}
 	if len(s.VerificationKey) > 0 {
 		ret.VerificationKey = make([]byte, len(s.VerificationKey))
 		copy(ret.VerificationKey, s.VerificationKey)
@@ -0,0 +1,259 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package seal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	proto "github.com/golang/protobuf/proto"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	wrapping "github.com/openbao/go-kms-wrapping/v2"
+)
+
+// WrapperTypeMulti is the type of MultiWrapper, recorded in the barrier seal
+// configuration like that of any other auto seal.
+const WrapperTypeMulti wrapping.WrapperType = "multi"
+
+// MultiWrapperProvider is one of the KMS providers of a MultiWrapper. Names
+// identify the data keys each provider wrapped, so they must be unique and
+// kept when the provider is reconfigured.
+type MultiWrapperProvider struct {
+	Name    string
+	Wrapper wrapping.Wrapper
+}
+
+// MultiWrapper encrypts data under a random data key, which it wraps with
+// each of its providers, so that any one of them can decrypt it again.
+type MultiWrapper struct {
+	providers []MultiWrapperProvider
+	logger    log.Logger
+}
+
+var (
+	_ wrapping.Wrapper       = (*MultiWrapper)(nil)
+	_ wrapping.InitFinalizer = (*MultiWrapper)(nil)
+)
+
+// multiBlob is the ciphertext of the blobs encrypted by a MultiWrapper.
+type multiBlob struct {
+	Ciphertext []byte            `json:"ciphertext"`
+	Iv         []byte            `json:"iv"`
+	Keys       []multiWrappedKey `json:"keys"`
+}
+
+type multiWrappedKey struct {
+	Name string `json:"name"`
+	// Blob is the proto encoded blob of the data key wrapped by the
+	// provider.
+	Blob []byte `json:"blob"`
+}
+
+// NewMultiWrapper creates a MultiWrapper with the given providers, which
+// are tried in order when decrypting.
+func NewMultiWrapper(logger log.Logger, providers ...MultiWrapperProvider) (*MultiWrapper, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("at least one provider is required")
+	}
+
+	names := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		if provider.Name == "" {
+			return nil, errors.New("providers must be named")
+		}
+		if _, ok := names[provider.Name]; ok {
+			return nil, fmt.Errorf("duplicate provider name %q", provider.Name)
+		}
+		names[provider.Name] = struct{}{}
+	}
+
+	if logger == nil {
+		logger = log.NewNullLogger()
+	}
+
+	return &MultiWrapper{
+		providers: providers,
+		logger:    logger,
+	}, nil
+}
+
+// Providers returns the names of the providers, in order.
+func (m *MultiWrapper) Providers() []string {
+	names := make([]string, 0, len(m.providers))
+	for _, provider := range m.providers {
+		names = append(names, provider.Name)
+	}
+	return names
+}
+
+func (m *MultiWrapper) Type(_ context.Context) (wrapping.WrapperType, error) {
+	return WrapperTypeMulti, nil
+}
+
+// KeyId combines the key IDs of all providers, so that it changes, and
+// stored keys get wrapped again, when providers are added or removed as
+// well as when any of their keys rotate.
+func (m *MultiWrapper) KeyId(ctx context.Context) (string, error) {
+	ids := make([]string, 0, len(m.providers))
+	for _, provider := range m.providers {
+		keyId, err := provider.Wrapper.KeyId(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get key ID of provider %q: %w", provider.Name, err)
+		}
+		ids = append(ids, provider.Name+"="+keyId)
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ","), nil
+}
+
+// SetConfig does nothing; providers are configured individually.
+func (m *MultiWrapper) SetConfig(_ context.Context, _ ...wrapping.Option) (*wrapping.WrapperConfig, error) {
+	return nil, nil
+}
+
+// Init initializes all providers. It only fails when none could be
+// initialized, as the others are enough to unseal.
+func (m *MultiWrapper) Init(ctx context.Context, options ...wrapping.Option) error {
+	var errs *multierror.Error
+	for _, provider := range m.providers {
+		initFinalizer, ok := provider.Wrapper.(wrapping.InitFinalizer)
+		if !ok {
+			continue
+		}
+		if err := initFinalizer.Init(ctx, options...); err != nil {
+			m.logger.Warn("failed to initialize seal provider", "provider", provider.Name, "error", err)
+			errs = multierror.Append(errs, fmt.Errorf("provider %q: %w", provider.Name, err))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) == len(m.providers) {
+		return errs.ErrorOrNil()
+	}
+	return nil
+}
+
+func (m *MultiWrapper) Finalize(ctx context.Context, options ...wrapping.Option) error {
+	var errs *multierror.Error
+	for _, provider := range m.providers {
+		if initFinalizer, ok := provider.Wrapper.(wrapping.InitFinalizer); ok {
+			if err := initFinalizer.Finalize(ctx, options...); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("provider %q: %w", provider.Name, err))
+			}
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Encrypt encrypts the plaintext and wraps its data key with every
+// provider. It fails if any provider does, as the blob would otherwise not
+// survive the loss of the providers which did wrap its key.
+func (m *MultiWrapper) Encrypt(ctx context.Context, plaintext []byte, options ...wrapping.Option) (*wrapping.BlobInfo, error) {
+	keyId, err := m.KeyId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := wrapping.EnvelopeEncrypt(plaintext, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data: %w", err)
+	}
+
+	blob := multiBlob{
+		Ciphertext: env.Ciphertext,
+		Iv:         env.Iv,
+		Keys:       make([]multiWrappedKey, 0, len(m.providers)),
+	}
+	for _, provider := range m.providers {
+		wrapped, err := provider.Wrapper.Encrypt(ctx, env.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key with provider %q: %w", provider.Name, err)
+		}
+		wrappedBytes, err := proto.Marshal(wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data key wrapped by provider %q: %w", provider.Name, err)
+		}
+		blob.Keys = append(blob.Keys, multiWrappedKey{
+			Name: provider.Name,
+			Blob: wrappedBytes,
+		})
+	}
+
+	ciphertext, err := json.Marshal(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrapping.BlobInfo{
+		Ciphertext: ciphertext,
+		KeyInfo: &wrapping.KeyInfo{
+			KeyId: keyId,
+		},
+	}, nil
+}
+
+// Decrypt unwraps the data key with the first provider able to, trying the
+// next when a provider fails or returns a key which doesn't decrypt the
+// data.
+func (m *MultiWrapper) Decrypt(ctx context.Context, in *wrapping.BlobInfo, options ...wrapping.Option) ([]byte, error) {
+	if in == nil {
+		return nil, errors.New("given input for decryption is nil")
+	}
+
+	var blob multiBlob
+	if err := json.Unmarshal(in.Ciphertext, &blob); err != nil {
+		return nil, fmt.Errorf("failed to decode multi-provider blob: %w", err)
+	}
+
+	wrappedKeys := make(map[string][]byte, len(blob.Keys))
+	for _, key := range blob.Keys {
+		wrappedKeys[key.Name] = key.Blob
+	}
+
+	var errs *multierror.Error
+	for _, provider := range m.providers {
+		wrappedBytes, ok := wrappedKeys[provider.Name]
+		if !ok {
+			// Added after the data was encrypted
+			continue
+		}
+
+		pt, err := m.decryptWith(ctx, provider, wrappedBytes, &blob, options...)
+		if err != nil {
+			m.logger.Warn("failed to decrypt with seal provider, trying the next", "provider", provider.Name, "error", err)
+			errs = multierror.Append(errs, fmt.Errorf("provider %q: %w", provider.Name, err))
+			continue
+		}
+		return pt, nil
+	}
+
+	if errs == nil {
+		return nil, errors.New("data key not wrapped by any configured provider")
+	}
+	return nil, fmt.Errorf("failed to decrypt with any provider: %w", errs)
+}
+
+func (m *MultiWrapper) decryptWith(ctx context.Context, provider MultiWrapperProvider, wrappedBytes []byte, blob *multiBlob, options ...wrapping.Option) ([]byte, error) {
+	wrapped := &wrapping.BlobInfo{}
+	if err := proto.Unmarshal(wrappedBytes, wrapped); err != nil {
+		return nil, fmt.Errorf("failed to decode wrapped data key: %w", err)
+	}
+
+	key, err := provider.Wrapper.Decrypt(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data is authenticated, so a malformed key fails here
+	return wrapping.EnvelopeDecrypt(&wrapping.EnvelopeInfo{
+		Ciphertext: blob.Ciphertext,
+		Key:        key,
+		Iv:         blob.Iv,
+	}, options...)
+}
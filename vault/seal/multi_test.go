@@ -0,0 +1,107 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package seal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	wrapping "github.com/openbao/go-kms-wrapping/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiWrapper(t *testing.T) {
+	ctx := context.Background()
+	plaintext := []byte("barrier keys")
+
+	a := wrapping.NewTestWrapper([]byte("secret-a"))
+	b := wrapping.NewTestWrapper([]byte("secret-b"))
+	multi, err := NewMultiWrapper(nil,
+		MultiWrapperProvider{Name: "a", Wrapper: a},
+		MultiWrapperProvider{Name: "b", Wrapper: b},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, multi.Providers())
+
+	typ, err := multi.Type(ctx)
+	require.NoError(t, err)
+	require.Equal(t, WrapperTypeMulti, typ)
+
+	blob, err := multi.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	keyId, err := multi.KeyId(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a=static-key,b=static-key", keyId)
+	require.Equal(t, keyId, blob.KeyInfo.KeyId)
+
+	pt, err := multi.Decrypt(ctx, blob)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, pt)
+
+	// Any one provider is enough to decrypt
+	a.ReturnDecryptError = errors.New("unreachable")
+	pt, err = multi.Decrypt(ctx, blob)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, pt)
+
+	b.ReturnDecryptError = errors.New("unreachable")
+	_, err = multi.Decrypt(ctx, blob)
+	require.Error(t, err)
+
+	// ...but all are needed to encrypt
+	a.ReturnDecryptError, b.ReturnDecryptError = nil, nil
+	b.ReturnEncryptError = errors.New("unreachable")
+	_, err = multi.Encrypt(ctx, plaintext)
+	require.Error(t, err)
+	b.ReturnEncryptError = nil
+
+	// A provider returning the wrong data key is skipped
+	wrong, err := NewMultiWrapper(nil,
+		MultiWrapperProvider{Name: "a", Wrapper: wrapping.NewTestWrapper([]byte("other"))},
+		MultiWrapperProvider{Name: "b", Wrapper: b},
+	)
+	require.NoError(t, err)
+	pt, err = wrong.Decrypt(ctx, blob)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, pt)
+
+	// Providers added since encryption are skipped, and change the key ID so
+	// that stored keys get wrapped again
+	c := wrapping.NewTestWrapper([]byte("secret-c"))
+	c.SetKeyId("key-c")
+	grown, err := NewMultiWrapper(nil,
+		MultiWrapperProvider{Name: "c", Wrapper: c},
+		MultiWrapperProvider{Name: "b", Wrapper: b},
+	)
+	require.NoError(t, err)
+	pt, err = grown.Decrypt(ctx, blob)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, pt)
+	grownKeyId, err := grown.KeyId(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "b=static-key,c=key-c", grownKeyId)
+
+	// Without any provider in common, nothing can be decrypted
+	onlyC, err := NewMultiWrapper(nil, MultiWrapperProvider{Name: "c", Wrapper: c})
+	require.NoError(t, err)
+	_, err = onlyC.Decrypt(ctx, blob)
+	require.Error(t, err)
+}
+
+func TestNewMultiWrapper_Validation(t *testing.T) {
+	w := wrapping.NewTestWrapper([]byte("secret"))
+
+	_, err := NewMultiWrapper(nil)
+	require.Error(t, err)
+
+	_, err = NewMultiWrapper(nil, MultiWrapperProvider{Wrapper: w})
+	require.Error(t, err)
+
+	_, err = NewMultiWrapper(nil,
+		MultiWrapperProvider{Name: "a", Wrapper: w},
+		MultiWrapperProvider{Name: "a", Wrapper: w},
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+)
+
+// shareChallengeSize is the size in bytes of the unseal challenge.
+const shareChallengeSize = 32
+
+// ErrShareAttestationFailed is returned when a key share bound to a hardware
+// token credential is submitted without a valid assertion of the credential.
+// It purposely carries no detail about the failure nor the unseal progress.
+var ErrShareAttestationFailed = errors.New("unseal key share attestation failed")
+
+// ShareCredential is a hardware token credential registered for one of the
+// key shares.
+type ShareCredential struct {
+	// PublicKey is the PEM-encoded public key of the credential, either an
+	// ECDSA P-256 or an Ed25519 key.
+	PublicKey string `json:"public_key" mapstructure:"public_key"`
+
+	// ShareHash is the SHA-256 hash of the key share bound to the
+	// credential, set when the shares are generated.
+	ShareHash []byte `json:"share_hash" mapstructure:"share_hash"`
+
+	// SignCount is the last signature counter reported by the token, which
+	// must increase with each assertion unless the token keeps none.
+	SignCount uint32 `json:"sign_count,omitempty" mapstructure:"sign_count"`
+}
+
+func (sc *ShareCredential) clone() *ShareCredential {
+	ret := &ShareCredential{
+		PublicKey: sc.PublicKey,
+		SignCount: sc.SignCount,
+	}
+	if len(sc.ShareHash) > 0 {
+		ret.ShareHash = make([]byte, len(sc.ShareHash))
+		copy(ret.ShareHash, sc.ShareHash)
+	}
+	return ret
+}
+
+// ShareAttestation is the WebAuthn assertion of a hardware token, submitted
+// along with the key share bound to its credential.
+type ShareAttestation struct {
+	AuthenticatorData []byte
+	ClientDataJSON    []byte
+	Signature         []byte
+}
+
+// parseShareCredentialKey parses the PEM-encoded public key of a share
+// credential.
+func parseShareCredentialKey(pemKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("public key must be a PEM-encoded PUBLIC KEY block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %q", k.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	return key, nil
+}
+
+// bindShareCredentials binds each share credential of the configuration to
+// the key share of the same index. The shares must not be encrypted yet.
+func (s *SealConfig) bindShareCredentials(shares [][]byte) {
+	for i, cred := range s.ShareCredentials {
+		if i >= len(shares) {
+			break
+		}
+		sum := sha256.Sum256(shares[i])
+		cred.ShareHash = sum[:]
+	}
+}
+
+// verify checks that the assertion was signed by the credential for the given
+// relying party and challenge, with the user present, and returns the
+// signature counter of the authenticator.
+func (sc *ShareCredential) verify(rpID string, challenge []byte, att *ShareAttestation) (uint32, error) {
+	pub, err := parseShareCredentialKey(sc.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	key, err := webauthnutil.NewCOSEKey(pub)
+	if err != nil {
+		return 0, err
+	}
+
+	clientData, err := webauthnutil.ParseClientData(att.ClientDataJSON, webauthnutil.ClientDataTypeGet)
+	if err != nil {
+		return 0, err
+	}
+	signedChallenge, err := webauthnutil.DecodeBase64(clientData.Challenge)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode challenge: %w", err)
+	}
+	if subtle.ConstantTimeCompare(signedChallenge, challenge) != 1 {
+		return 0, fmt.Errorf("challenge mismatch")
+	}
+
+	authData, err := webauthnutil.VerifyAssertion(&webauthnutil.Assertion{
+		AuthenticatorData: att.AuthenticatorData,
+		ClientDataJSON:    att.ClientDataJSON,
+		Signature:         att.Signature,
+	}, key, rpID, false)
+	if err != nil {
+		return 0, err
+	}
+	return authData.SignCount, nil
+}
+
+// UnsealChallenge returns the challenge which the hardware token assertion
+// submitted with the next key share must sign. The challenge is consumed by
+// the next submission of a key share bound to a credential, whether its
+// attestation succeeds or not.
+func (c *Core) UnsealChallenge() (string, error) {
+	c.unsealChallengeLock.Lock()
+	defer c.unsealChallengeLock.Unlock()
+
+	if c.unsealChallenge == nil {
+		challenge := make([]byte, shareChallengeSize)
+		if _, err := c.secureRandomReader.Read(challenge); err != nil {
+			return "", fmt.Errorf("failed to generate unseal challenge: %w", err)
+		}
+		c.unsealChallenge = challenge
+	}
+
+	return base64.RawURLEncoding.EncodeToString(c.unsealChallenge), nil
+}
+
+// consumeUnsealChallenge returns the current unseal challenge, if any, and
+// clears it so that it cannot be used again.
+func (c *Core) consumeUnsealChallenge() []byte {
+	c.unsealChallengeLock.Lock()
+	defer c.unsealChallengeLock.Unlock()
+
+	challenge := c.unsealChallenge
+	c.unsealChallenge = nil
+	return challenge
+}
+
+// verifyShareAttestation checks the attestation submitted with a key share,
+// if the key shares of the given seal are bound to credentials, and records
+// the signature counter of the credential. Every failure returns
+// ErrShareAttestationFailed, the details being only logged.
+func (c *Core) verifyShareAttestation(ctx context.Context, seal Seal, key []byte, attestation *ShareAttestation) error {
+	config, err := c.unsealConfig(ctx, seal)
+	if err != nil {
+		return err
+	}
+	if len(config.ShareCredentials) == 0 {
+		return nil
+	}
+
+	fail := func(reason string, args ...interface{}) error {
+		c.logger.Warn("unseal key share attestation failed", append([]interface{}{"reason", reason}, args...)...)
+		return ErrShareAttestationFailed
+	}
+
+	if attestation == nil {
+		return fail("no attestation was provided")
+	}
+
+	sum := sha256.Sum256(key)
+	index := -1
+	for i, cred := range config.ShareCredentials {
+		if subtle.ConstantTimeCompare(cred.ShareHash, sum[:]) == 1 {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fail("no credential is bound to the key share")
+	}
+
+	// Consume the challenge before verifying the assertion, so that it
+	// cannot be replayed whether this attempt succeeds or not. Only shares
+	// bound to a credential get this far, so that anyone submitting keys
+	// cannot keep share holders from using the challenge.
+	challenge := c.consumeUnsealChallenge()
+	if challenge == nil {
+		return fail("no unseal challenge was issued")
+	}
+
+	cred := config.ShareCredentials[index]
+	signCount, err := cred.verify(config.ShareCredentialsRPID, challenge, attestation)
+	if err != nil {
+		return fail(err.Error(), "credential", index)
+	}
+	if (signCount != 0 || cred.SignCount != 0) && signCount <= cred.SignCount {
+		return fail("signature counter did not increase, the hardware token may have been cloned",
+			"credential", index, "stored_sign_count", cred.SignCount, "sign_count", signCount)
+	}
+
+	// Record the counter where unsealConfig read it from. The configuration
+	// of the leader used while joining a raft cluster can't be written, as
+	// storage is not set up yet.
+	config = config.Clone()
+	config.ShareCredentials[index].SignCount = signCount
+	switch {
+	case seal.RecoveryKeySupported():
+		err = seal.SetRecoveryConfig(ctx, config)
+	case c.isRaftUnseal():
+	default:
+		err = seal.SetBarrierConfig(ctx, config)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record the signature counter of the credential: %w", err)
+	}
+
+	c.logger.Info("unseal key share attested", "credential", index, "sign_count", signCount)
+	return nil
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/openbao/openbao/sdk/v2/helper/webauthnutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testShareCredentialsRPID = "openbao.example.com"
+
+func testShareCredentialKey(t *testing.T, ec bool) (crypto.Signer, string) {
+	t.Helper()
+	var signer crypto.Signer
+	var err error
+	if ec {
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	} else {
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	require.NoError(t, err)
+	return signer, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// testShareAttestation returns a WebAuthn assertion of the challenge signed
+// with the given key, as a hardware token would.
+func testShareAttestation(t *testing.T, signer crypto.Signer, rpID, challenge string, flags byte, signCount uint32) *ShareAttestation {
+	t.Helper()
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], flags)
+	authData = binary.BigEndian.AppendUint32(authData, signCount)
+
+	clientData, err := json.Marshal(map[string]string{
+		"type":      webauthnutil.ClientDataTypeGet,
+		"challenge": challenge,
+		"origin":    "https://" + rpID,
+	})
+	require.NoError(t, err)
+
+	clientDataHash := sha256.Sum256(clientData)
+	signed := append(append([]byte{}, authData...), clientDataHash[:]...)
+
+	var sig []byte
+	switch k := signer.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(signed)
+		sig, err = ecdsa.SignASN1(rand.Reader, k, digest[:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, signed)
+	}
+	require.NoError(t, err)
+
+	return &ShareAttestation{
+		AuthenticatorData: authData,
+		ClientDataJSON:    clientData,
+		Signature:         sig,
+	}
+}
+
+func TestSealConfig_ShareCredentials(t *testing.T) {
+	_, publicKey := testShareCredentialKey(t, true)
+	_, truncatedKey := testShareCredentialKey(t, false)
+	truncatedKey = truncatedKey[:len(truncatedKey)/2]
+
+	for _, config := range []*SealConfig{
+		{SecretShares: 2, SecretThreshold: 2, ShareCredentialsRPID: testShareCredentialsRPID, ShareCredentials: []*ShareCredential{{PublicKey: publicKey}}},
+		{SecretShares: 1, SecretThreshold: 1, ShareCredentials: []*ShareCredential{{PublicKey: publicKey}}},
+		{SecretShares: 1, SecretThreshold: 1, ShareCredentialsRPID: testShareCredentialsRPID, ShareCredentials: []*ShareCredential{{PublicKey: truncatedKey}}},
+	} {
+		require.Error(t, config.Validate())
+	}
+
+	config := &SealConfig{
+		SecretShares:         1,
+		SecretThreshold:      1,
+		ShareCredentialsRPID: testShareCredentialsRPID,
+		ShareCredentials:     []*ShareCredential{{PublicKey: publicKey}},
+	}
+	require.NoError(t, config.Validate())
+
+	clone := config.Clone()
+	clone.bindShareCredentials([][]byte{[]byte("share")})
+	require.Empty(t, config.ShareCredentials[0].ShareHash)
+	require.NotEmpty(t, clone.ShareCredentials[0].ShareHash)
+	require.Equal(t, testShareCredentialsRPID, clone.ShareCredentialsRPID)
+}
+
+func TestCore_UnsealWithAttestation(t *testing.T) {
+	ctx := context.Background()
+	c := TestCore(t)
+
+	signers := make([]crypto.Signer, 3)
+	barrierConfig := &SealConfig{
+		SecretShares:         3,
+		SecretThreshold:      2,
+		StoredShares:         1,
+		ShareCredentialsRPID: testShareCredentialsRPID,
+	}
+	for i := range signers {
+		var publicKey string
+		signers[i], publicKey = testShareCredentialKey(t, i == 0)
+		barrierConfig.ShareCredentials = append(barrierConfig.ShareCredentials, &ShareCredential{PublicKey: publicKey})
+	}
+
+	_, err := c.Initialize(ctx, &InitParams{
+		BarrierConfig: &SealConfig{
+			SecretShares:         3,
+			SecretThreshold:      2,
+			StoredShares:         1,
+			ShareCredentialsRPID: testShareCredentialsRPID,
+			ShareCredentials:     barrierConfig.ShareCredentials[:2],
+		},
+		RecoveryConfig: &SealConfig{},
+	})
+	require.Error(t, err)
+
+	result, err := c.Initialize(ctx, &InitParams{
+		BarrierConfig:  barrierConfig,
+		RecoveryConfig: &SealConfig{},
+	})
+	require.NoError(t, err)
+	shares := result.SecretShares
+	require.Len(t, shares, 3)
+
+	challenge := func() string {
+		t.Helper()
+		status, err := c.GetSealStatus(ctx, true)
+		require.NoError(t, err)
+		require.NotEmpty(t, status.AttestationChallenge)
+		return status.AttestationChallenge
+	}
+	requireFailed := func(share []byte, attestation *ShareAttestation) {
+		t.Helper()
+		unsealed, err := c.UnsealWithAttestation(share, false, attestation)
+		require.ErrorIs(t, err, ErrShareAttestationFailed)
+		require.False(t, unsealed)
+		require.Equal(t, ErrShareAttestationFailed.Error(), err.Error())
+	}
+	requireProgress := func(expected int) {
+		t.Helper()
+		progress, _ := c.SecretProgress(true)
+		require.Equal(t, expected, progress)
+	}
+
+	// Shares are not counted without a valid assertion of their credential.
+	requireFailed(shares[0], nil)
+	_, err = c.Unseal(shares[0])
+	require.ErrorIs(t, err, ErrShareAttestationFailed)
+	requireFailed(shares[0], testShareAttestation(t, signers[1], testShareCredentialsRPID, challenge(), webauthnutil.FlagUserPresent, 42))
+	requireFailed(shares[0], testShareAttestation(t, signers[0], "example.com", challenge(), webauthnutil.FlagUserPresent, 42))
+	requireFailed(shares[0], testShareAttestation(t, signers[0], testShareCredentialsRPID, challenge(), 0, 42))
+	requireProgress(0)
+
+	// Key shares bound to no credential don't consume the challenge, so that
+	// they cannot be used to keep share holders from unsealing.
+	current := challenge()
+	unknownShare := make([]byte, len(shares[0]))
+	requireFailed(unknownShare, testShareAttestation(t, signers[0], testShareCredentialsRPID, current, webauthnutil.FlagUserPresent, 42))
+	requireProgress(0)
+	require.Equal(t, current, challenge())
+
+	unsealed, err := c.UnsealWithAttestation(shares[0], false, testShareAttestation(t, signers[0], testShareCredentialsRPID, current, webauthnutil.FlagUserPresent, 42))
+	require.NoError(t, err)
+	require.False(t, unsealed)
+	requireProgress(1)
+
+	// Challenges are single-use, so that assertions cannot be replayed, even
+	// if the attempt consuming the challenge failed.
+	replayed := testShareAttestation(t, signers[1], testShareCredentialsRPID, challenge(), webauthnutil.FlagUserPresent, 7)
+	requireFailed(shares[2], replayed)
+	requireFailed(shares[1], replayed)
+	requireProgress(1)
+
+	unsealed, err = c.UnsealWithAttestation(shares[1], false, testShareAttestation(t, signers[1], testShareCredentialsRPID, challenge(), webauthnutil.FlagUserPresent, 7))
+	require.NoError(t, err)
+	require.True(t, unsealed)
+
+	status, err := c.GetSealStatus(ctx, true)
+	require.NoError(t, err)
+	require.Empty(t, status.AttestationChallenge)
+
+	// The signature counters of the credentials are persisted along with
+	// the seal configuration.
+	entry, err := c.physical.Get(ctx, barrierSealConfigPath)
+	require.NoError(t, err)
+	var stored SealConfig
+	require.NoError(t, json.Unmarshal(entry.Value, &stored))
+	require.Equal(t, uint32(42), stored.ShareCredentials[0].SignCount)
+	require.Equal(t, uint32(7), stored.ShareCredentials[1].SignCount)
+	require.Zero(t, stored.ShareCredentials[2].SignCount)
+
+	// Once recorded, a counter must increase with each assertion, as a
+	// cloned token would reuse it.
+	require.NoError(t, c.Seal(result.RootToken))
+	requireFailed(shares[0], testShareAttestation(t, signers[0], testShareCredentialsRPID, challenge(), webauthnutil.FlagUserPresent, 42))
+	requireProgress(0)
+	unsealed, err = c.UnsealWithAttestation(shares[0], false, testShareAttestation(t, signers[0], testShareCredentialsRPID, challenge(), webauthnutil.FlagUserPresent, 43))
+	require.NoError(t, err)
+	require.False(t, unsealed)
+	requireProgress(1)
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	mathrand "math/rand"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -186,9 +187,36 @@ func (d *autoSeal) UpgradeKeys(ctx context.Context) error {
 	if err := d.upgradeStoredKeys(ctx); err != nil {
 		return err
 	}
+	if err := d.upgradeBarrierConfig(ctx); err != nil {
+		return err
+	}
 	return nil
 }
 
+// providers returns the names of the KMS providers of a multi-provider seal,
+// or nil for any other.
+func (d *autoSeal) providers() []string {
+	if multi, ok := d.GetWrapper().(*seal.MultiWrapper); ok {
+		return multi.Providers()
+	}
+	return nil
+}
+
+// upgradeBarrierConfig records the current providers of a multi-provider
+// seal in the barrier config, once the stored keys have been wrapped by them.
+func (d *autoSeal) upgradeBarrierConfig(ctx context.Context) error {
+	conf, err := d.BarrierConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if conf == nil || slices.Equal(conf.Providers, d.providers()) {
+		return nil
+	}
+
+	d.logger.Info("updating seal providers", "from", conf.Providers, "to", d.providers())
+	return d.SetBarrierConfig(ctx, conf)
+}
+
 func (d *autoSeal) BarrierConfig(ctx context.Context) (*SealConfig, error) {
 	if d.barrierConfig.Load().(*SealConfig) != nil {
 		return d.barrierConfig.Load().(*SealConfig).Clone(), nil
@@ -249,6 +277,7 @@ func (d *autoSeal) SetBarrierConfig(ctx context.Context, conf *SealConfig) error
 	}
 
 	conf.Type = d.BarrierType().String()
+	conf.Providers = d.providers()
 
 	// Encode the seal configuration
 	buf, err := json.Marshal(conf)
@@ -176,6 +176,87 @@ func TestAutoSeal_UpgradeKeys(t *testing.T) {
 	check()
 }
 
+func TestAutoSeal_MultiProviders(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+	ctx := context.Background()
+
+	a := wrapping.NewTestWrapper([]byte("secret-a"))
+	b := wrapping.NewTestWrapper([]byte("secret-b"))
+	c := wrapping.NewTestWrapper([]byte("secret-c"))
+	newSeal := func(providers ...seal.MultiWrapperProvider) *autoSeal {
+		t.Helper()
+		multi, err := seal.NewMultiWrapper(nil, providers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		autoSeal, err := NewAutoSeal(seal.NewAccess(multi))
+		if err != nil {
+			t.Fatal(err)
+		}
+		autoSeal.SetCore(core)
+		return autoSeal
+	}
+	checkProviders := func(autoSeal *autoSeal, want []string) {
+		t.Helper()
+		autoSeal.SetCachedBarrierConfig(nil)
+		conf, err := autoSeal.BarrierConfig(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, conf.Providers) {
+			t.Fatalf("incorrect providers: want %v, got %v", want, conf.Providers)
+		}
+	}
+	checkKeys := func(autoSeal *autoSeal, want [][]byte) {
+		t.Helper()
+		got, err := autoSeal.GetStoredKeys(ctx)
+		if err != nil {
+			t.Fatalf("GetStoredKeys: want no error, got %v", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("incorrect stored keys: want %v, got %v", want, got)
+		}
+	}
+
+	ab := newSeal(
+		seal.MultiWrapperProvider{Name: "a", Wrapper: a},
+		seal.MultiWrapperProvider{Name: "b", Wrapper: b},
+	)
+	if err := ab.SetBarrierConfig(ctx, &SealConfig{
+		SecretShares:    1,
+		SecretThreshold: 1,
+		StoredShares:    1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkProviders(ab, []string{"a", "b"})
+
+	keys := [][]byte{[]byte("grist")}
+	if err := ab.SetStoredKeys(ctx, keys); err != nil {
+		t.Fatalf("SetStoredKeys: want no error, got %v", err)
+	}
+	if err := ab.SetRecoveryKey(ctx, []byte("falernum")); err != nil {
+		t.Fatalf("SetRecoveryKey: want no error, got %v", err)
+	}
+
+	// Replacing a provider keeps the keys readable through the other, and
+	// upgrading wraps them with the new one
+	bc := newSeal(
+		seal.MultiWrapperProvider{Name: "b", Wrapper: b},
+		seal.MultiWrapperProvider{Name: "c", Wrapper: c},
+	)
+	checkKeys(bc, keys)
+	if err := bc.UpgradeKeys(ctx); err != nil {
+		t.Fatalf("UpgradeKeys: want no error, got %v", err)
+	}
+	checkProviders(bc, []string{"b", "c"})
+
+	checkKeys(newSeal(seal.MultiWrapperProvider{Name: "c", Wrapper: c}), keys)
+	if _, err := newSeal(seal.MultiWrapperProvider{Name: "a", Wrapper: a}).GetStoredKeys(ctx); err == nil {
+		t.Fatal("expected keys to no longer be wrapped by the removed provider")
+	}
+}
+
 func TestAutoSeal_HealthCheck(t *testing.T) {
 	inmemSink := metrics.NewInmemSink(
 		1000000*time.Hour,
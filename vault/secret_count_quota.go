@@ -0,0 +1,173 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/openbao/openbao/vault/quotas"
+)
+
+// kvSecretChange returns 1 if the request creates a secret in a KV mount, -1
+// if it deletes one which exists, and 0 otherwise. In KV version 2, a secret
+// exists as long as its metadata does: deleting or destroying its versions
+// does not remove it, deleting its metadata does.
+func (c *Core) kvSecretChange(ctx context.Context, entry *MountEntry, req *logical.Request) (int, error) {
+	if entry == nil || (entry.Type != mountTypeKV && entry.Type != "generic") {
+		return 0, nil
+	}
+
+	secretPath := strings.TrimPrefix(req.Path, entry.Path)
+	v2 := entry.Options["version"] == "2"
+	if v2 {
+		switch {
+		case strings.HasPrefix(secretPath, "metadata/"):
+			secretPath = strings.TrimPrefix(secretPath, "metadata/")
+		case strings.HasPrefix(secretPath, "data/") && req.Operation == logical.CreateOperation:
+			secretPath = strings.TrimPrefix(secretPath, "data/")
+		default:
+			return 0, nil
+		}
+	}
+	if secretPath == "" || strings.HasSuffix(secretPath, "/") {
+		return 0, nil
+	}
+
+	switch req.Operation {
+	case logical.CreateOperation:
+		// The existence check has already established that the secret does
+		// not exist
+		return 1, nil
+	case logical.DeleteOperation:
+		_, _, exists, err := c.router.RouteExistenceCheck(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      req.Path,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			return -1, nil
+		}
+	}
+	return 0, nil
+}
+
+// secretCountQuotaRequest returns the request used to count secrets of the
+// given mount against secret count quotas.
+func secretCountQuotaRequest(ns *namespace.Namespace, entry *MountEntry) *quotas.Request {
+	return &quotas.Request{
+		Type:          quotas.TypeSecretCount,
+		Path:          ns.Path + entry.Path,
+		NamespacePath: ns.Path,
+		MountPath:     entry.Path,
+	}
+}
+
+// applySecretCountQuota counts a secret which the request is about to create
+// against the applicable secret count quota, if any, and returns an error
+// wrapping quotas.ErrSecretCountQuotaExceeded if the quota does not allow it.
+// The returned change must be passed to settleSecretCountQuota along with the
+// outcome of the request.
+func (c *Core) applySecretCountQuota(ctx context.Context, entry *MountEntry, req *logical.Request) (int, error) {
+	if c.quotaManager == nil {
+		return 0, nil
+	}
+
+	change, err := c.kvSecretChange(ctx, entry, req)
+	if err != nil || change <= 0 {
+		return change, err
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	quotaResp, err := c.quotaManager.ReserveSecret(ctx, secretCountQuotaRequest(ns, entry))
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply secret count quota: %w", err)
+	}
+	if !quotaResp.Allowed {
+		return 0, fmt.Errorf("request path %q: %w", req.Path, quotas.ErrSecretCountQuotaExceeded)
+	}
+	return change, nil
+}
+
+// settleSecretCountQuota releases the secret reserved for a creation which
+// failed, or the secret removed by a successful deletion.
+func (c *Core) settleSecretCountQuota(ctx context.Context, entry *MountEntry, change int, resp *logical.Response, err error) {
+	failed := err != nil || (resp != nil && resp.IsError())
+	switch {
+	case change > 0 && failed:
+	case change < 0 && !failed:
+	default:
+		return
+	}
+
+	ns, nsErr := namespace.FromContext(ctx)
+	if nsErr != nil {
+		c.logger.Error("failed to update secret count quotas", "error", nsErr)
+		return
+	}
+	if err := c.quotaManager.ReleaseSecret(ctx, secretCountQuotaRequest(ns, entry)); err != nil {
+		c.logger.Error("failed to update secret count quotas", "mount_point", ns.Path+entry.Path, "error", err)
+	}
+}
+
+// recountSecretCountQuotas counts the secrets of every KV mount and sets the
+// counters of the secret count quotas they fall under, correcting any drift
+// such as from secrets removed by the KV mounts themselves. If name is set,
+// only that quota is updated. Secrets written while the mounts are walked may
+// or may not be counted.
+func (c *Core) recountSecretCountQuotas(ctx context.Context, name string) (map[string]int, error) {
+	names, err := c.quotaManager.QuotaNames(quotas.TypeSecretCount)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(names))
+	for _, n := range names {
+		if name == "" || n == name {
+			counts[n] = 0
+		}
+	}
+	if len(counts) == 0 {
+		return counts, nil
+	}
+
+	ctx = namespace.RootContext(ctx)
+	for _, m := range c.findKvMounts() {
+		quota, err := c.quotaManager.QueryQuota(&quotas.Request{
+			Type:          quotas.TypeSecretCount,
+			Path:          m.Namespace.Path + m.MountPoint,
+			NamespacePath: m.Namespace.Path,
+			MountPath:     m.MountPoint,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if quota == nil {
+			continue
+		}
+		if _, ok := counts[quota.QuotaName()]; !ok {
+			continue
+		}
+
+		c.walkKvMountSecrets(ctx, m)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		counts[quota.QuotaName()] += m.NumSecrets
+	}
+
+	for n, count := range counts {
+		if err := c.quotaManager.SetSecretCount(ctx, n, count); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"net/http"
+	"testing"
+
+	logicalKv "github.com/openbao/openbao/builtin/logical/kv"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_SecretCountQuota(t *testing.T) {
+	// Use the real KV implementation instead of Passthrough
+	AddTestLogicalBackend("kv", logicalKv.Factory)
+	defer func() {
+		delete(testLogicalBackends, "kv")
+	}()
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	for path, version := range map[string]string{"kv1/": "1", "kv2/": "2"} {
+		require.NoError(t, core.mount(ctx, &MountEntry{
+			Table:   mountTableType,
+			Path:    path,
+			Type:    "kv",
+			Options: map[string]string{"version": version},
+		}))
+	}
+
+	handle := func(op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, op, path)
+		req.ClientToken = root
+		req.Data = data
+		return core.HandleRequest(ctx, req)
+	}
+	mustHandle := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := handle(op, path, data)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("resp: %#v\nerr: %v", resp, err)
+		}
+		return resp
+	}
+	write := func(path string) error {
+		t.Helper()
+		_, err := handle(logical.UpdateOperation, path, map[string]interface{}{
+			"data": map[string]interface{}{"foo": "bar"},
+			"foo":  "bar",
+		})
+		return err
+	}
+	requireExceeded := func(path string) {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.ClientToken = root
+		req.Data = map[string]interface{}{"data": map[string]interface{}{"foo": "bar"}, "foo": "bar"}
+		resp, err := core.HandleRequest(ctx, req)
+		require.ErrorContains(t, err, "secret count quota exceeded")
+		status, _ := logical.RespondErrorCommon(req, resp, err)
+		require.Equal(t, http.StatusTooManyRequests, status)
+	}
+	counter := func(name string) int {
+		t.Helper()
+		resp := mustHandle(logical.ReadOperation, "sys/quotas/secret-count/"+name, nil)
+		require.Equal(t, "secret-count", resp.Data["type"])
+		return resp.Data["counter"].(int)
+	}
+
+	// Only whole KV mounts and positive limits are accepted
+	for _, data := range []map[string]interface{}{
+		{"path": "kv2/", "max_secrets": 0},
+		{"path": "kv2/data/", "max_secrets": 3},
+		{"path": "sys/", "max_secrets": 3},
+	} {
+		resp, err := handle(logical.UpdateOperation, "sys/quotas/secret-count/kv2", data)
+		require.NoError(t, err)
+		require.True(t, resp.IsError())
+	}
+
+	// Existing secrets are counted when the quota is created
+	require.NoError(t, write("kv2/data/a"))
+	require.NoError(t, write("kv2/data/b"))
+	mustHandle(logical.UpdateOperation, "sys/quotas/secret-count/kv2", map[string]interface{}{
+		"path":        "kv2/",
+		"max_secrets": 3,
+	})
+	require.Equal(t, 2, counter("kv2"))
+
+	require.NoError(t, write("kv2/data/c"))
+	requireExceeded("kv2/data/d")
+	require.Equal(t, 3, counter("kv2"))
+
+	// Updating existing secrets is still allowed
+	require.NoError(t, write("kv2/data/a"))
+
+	// Deleting and destroying versions keeps the secret, deleting its
+	// metadata removes it
+	mustHandle(logical.DeleteOperation, "kv2/data/a", nil)
+	mustHandle(logical.UpdateOperation, "kv2/destroy/a", map[string]interface{}{
+		"versions": []int{1, 2},
+	})
+	require.Equal(t, 3, counter("kv2"))
+	requireExceeded("kv2/data/d")
+	mustHandle(logical.DeleteOperation, "kv2/metadata/a", nil)
+	require.Equal(t, 2, counter("kv2"))
+	mustHandle(logical.DeleteOperation, "kv2/metadata/a", nil)
+	require.Equal(t, 2, counter("kv2"))
+
+	// Writing metadata alone creates a secret
+	mustHandle(logical.UpdateOperation, "kv2/metadata/d", map[string]interface{}{
+		"max_versions": 2,
+	})
+	require.Equal(t, 3, counter("kv2"))
+	mustHandle(logical.DeleteOperation, "kv2/metadata/d", nil)
+
+	// Failed writes do not count
+	_, err := handle(logical.UpdateOperation, "kv2/data/e", map[string]interface{}{
+		"data":    map[string]interface{}{"foo": "bar"},
+		"options": map[string]interface{}{"cas": 5},
+	})
+	require.Error(t, err)
+	require.Equal(t, 2, counter("kv2"))
+
+	// Drift is corrected by recounting
+	require.NoError(t, core.quotaManager.SetSecretCount(ctx, "kv2", 0))
+	require.Equal(t, 0, counter("kv2"))
+	resp := mustHandle(logical.UpdateOperation, "sys/quotas/secret-count/kv2/recount", nil)
+	require.Equal(t, 2, resp.Data["counter"])
+	require.Equal(t, 2, counter("kv2"))
+	_, err = handle(logical.UpdateOperation, "sys/quotas/secret-count/missing/recount", nil)
+	require.Error(t, err)
+
+	// A namespace quota applies to the KV mounts without a quota of their own
+	mustHandle(logical.UpdateOperation, "sys/quotas/secret-count/global", map[string]interface{}{
+		"max_secrets": 1,
+	})
+	require.Equal(t, 0, counter("global"))
+	require.NoError(t, write("kv1/a"))
+	requireExceeded("kv1/b")
+	require.NoError(t, write("kv2/data/f"))
+	require.Equal(t, 1, counter("global"))
+	require.Equal(t, 3, counter("kv2"))
+	mustHandle(logical.DeleteOperation, "kv1/a", nil)
+	require.Equal(t, 0, counter("global"))
+	require.NoError(t, write("kv1/b"))
+
+	// The counters survive reloading the quotas
+	require.NoError(t, core.setupQuotas(ctx))
+	require.Equal(t, 1, counter("global"))
+	require.Equal(t, 3, counter("kv2"))
+
+	// Deleting the mount quota puts its secrets under the namespace quota
+	mustHandle(logical.DeleteOperation, "sys/quotas/secret-count/kv2", nil)
+	require.Equal(t, 4, counter("global"))
+	requireExceeded("kv2/data/g")
+	resp = mustHandle(logical.ListOperation, "sys/quotas/secret-count", nil)
+	require.Equal(t, []string{"global"}, resp.Data["keys"])
+}
@@ -228,6 +228,8 @@ func TestCoreWithSealAndUINoCleanup(t testing.T, opts *CoreConfig) *Core {
 	conf.DetectDeadlocks = opts.DetectDeadlocks
 	conf.AdministrativeNamespacePath = opts.AdministrativeNamespacePath
 	conf.ImpreciseLeaseRoleTracking = opts.ImpreciseLeaseRoleTracking
+	conf.StrictPatchCapability = opts.StrictPatchCapability
+	conf.BarrierCompression = opts.BarrierCompression
 
 	if opts.Logger != nil {
 		conf.Logger = opts.Logger
@@ -1537,6 +1539,9 @@ func NewTestCluster(t testing.T, base *CoreConfig, opts *TestClusterOptions) *Te
 		coreConfig.AdministrativeNamespacePath = base.AdministrativeNamespacePath
 		coreConfig.ServiceRegistration = base.ServiceRegistration
 		coreConfig.ImpreciseLeaseRoleTracking = base.ImpreciseLeaseRoleTracking
+		coreConfig.StrictPatchCapability = base.StrictPatchCapability
+		coreConfig.BarrierCompression = base.BarrierCompression
+		coreConfig.ClusterCertRotationInterval = base.ClusterCertRotationInterval
 
 		if base.BuiltinRegistry != nil {
 			coreConfig.BuiltinRegistry = base.BuiltinRegistry
@@ -7,6 +7,7 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -196,6 +197,10 @@ func (ts *TokenStore) paths() []*framework.Path {
 			Type:        framework.TypeStringSlice,
 			Description: "List of policies for the token",
 		},
+		"bind_client_cert": {
+			Type:        framework.TypeBool,
+			Description: tokenBindClientCertHelp,
+		},
 	}
 
 	fieldsForCreateWithRole := map[string]*framework.FieldSchema{
@@ -281,6 +286,33 @@ func (ts *TokenStore) paths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(tokenCreateRoleHelp),
 		},
 
+		{
+			Pattern: "step-up/" + framework.GenericNameRegex("role_name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"role_name": {
+					Type:        framework.TypeString,
+					Description: "Name of the role",
+				},
+				"passcode": {
+					Type:        framework.TypeString,
+					Description: "TOTP passcode for the role's step-up MFA method",
+				},
+			},
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixToken,
+				OperationVerb:   "step-up",
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: ts.handleStepUp,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(tokenStepUpHelp),
+			HelpDescription: strings.TrimSpace(tokenStepUpHelpDesc),
+		},
+
 		{
 			Pattern: "create$",
 
@@ -618,6 +650,11 @@ func (ts *TokenStore) paths() []*framework.Path {
 				Description: tokenOrphanHelp,
 			},
 
+			"bind_client_cert": {
+				Type:        framework.TypeBool,
+				Description: tokenBindClientCertHelp,
+			},
+
 			"period": {
 				Type:        framework.TypeDurationSecond,
 				Description: "Use 'token_period' instead.",
@@ -651,6 +688,21 @@ func (ts *TokenStore) paths() []*framework.Path {
 				Type:        framework.TypeCommaStringSlice,
 				Description: "String or JSON list of allowed entity aliases. If set, specifies the entity aliases which are allowed to be used during token generation. This field supports globbing.",
 			},
+
+			"step_up_mfa_method_id": {
+				Type:        framework.TypeString,
+				Description: tokenStepUpMFAMethodIDHelp,
+			},
+
+			"step_up_policies": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: tokenStepUpPoliciesHelp,
+			},
+
+			"step_up_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: tokenStepUpTTLHelp,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -870,6 +922,10 @@ type tsRoleEntry struct {
 	// If true, tokens created using this role will be orphans
 	Orphan bool `json:"orphan" mapstructure:"orphan" structs:"orphan"`
 
+	// If true, tokens created using this role will be bound to the client
+	// certificate of the creation request
+	BindClientCert bool `json:"bind_client_cert" mapstructure:"bind_client_cert" structs:"bind_client_cert"`
+
 	// If non-zero, tokens created using this role will be able to be renewed
 	// forever, but will have a fixed renewal period of this value
 	Period time.Duration `json:"period" mapstructure:"period" structs:"period"`
@@ -890,6 +946,16 @@ type tsRoleEntry struct {
 
 	// The set of allowed entity aliases used during token creation
 	AllowedEntityAliases []string `json:"allowed_entity_aliases" mapstructure:"allowed_entity_aliases" structs:"allowed_entity_aliases"`
+
+	// If set, tokens can be stepped up against this role by presenting a
+	// passcode for this TOTP MFA method
+	StepUpMFAMethodID string `json:"step_up_mfa_method_id" mapstructure:"step_up_mfa_method_id" structs:"step_up_mfa_method_id"`
+
+	// The policies added to the caller's policies when stepping up
+	StepUpPolicies []string `json:"step_up_policies" mapstructure:"step_up_policies" structs:"step_up_policies"`
+
+	// The TTL of stepped up tokens
+	StepUpTTL time.Duration `json:"step_up_ttl" mapstructure:"step_up_ttl" structs:"step_up_ttl"`
 }
 
 type accessorEntry struct {
@@ -2561,6 +2627,109 @@ func (ts *TokenStore) handleUpdateRevokeAccessor(ctx context.Context, req *logic
 	return nil, nil
 }
 
+// entityAccessorsPageSize is the number of accessor index entries read at once
+// when looking for the tokens of an entity.
+const entityAccessorsPageSize = 256
+
+// errTokenNotFound is returned by revokeByAccessor for accessors which match
+// no token the caller may revoke.
+var errTokenNotFound = errors.New("no token found with this accessor")
+
+// revokeByAccessor revokes the token with the given accessor, along with its
+// child tokens and leases. Only tokens of the namespace of the context and of
+// its child namespaces can be revoked.
+func (ts *TokenStore) revokeByAccessor(ctx context.Context, accessor string) error {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	aEntry, err := ts.lookupByAccessor(ctx, accessor, false, true)
+	if err != nil {
+		return err
+	}
+	if aEntry == nil || aEntry.TokenID == "" {
+		return errTokenNotFound
+	}
+
+	te, err := ts.Lookup(ctx, aEntry.TokenID)
+	if err != nil {
+		return err
+	}
+	if te == nil {
+		return errTokenNotFound
+	}
+
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, ts.core)
+	if err != nil {
+		return err
+	}
+	if tokenNS == nil {
+		return namespace.ErrNoNamespace
+	}
+	// Don't disclose the existence of tokens outside of the namespace
+	if tokenNS.ID != ns.ID && !tokenNS.HasParent(ns) {
+		return errTokenNotFound
+	}
+
+	revokeCtx := namespace.ContextWithNamespace(ts.quitContext, tokenNS)
+	leaseID, err := ts.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
+	if err != nil {
+		return err
+	}
+
+	return ts.expiration.Revoke(revokeCtx, leaseID)
+}
+
+// entityAccessors returns the accessors of up to limit tokens of the entity
+// in the namespace of the context, scanning the accessor index in order from
+// after. The second return value is the index key to resume scanning after,
+// or an empty string once the whole index has been scanned.
+func (ts *TokenStore) entityAccessors(ctx context.Context, entityID, after string, limit int) ([]string, string, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	view := ts.accessorView(ns)
+
+	var accessors []string
+	for {
+		keys, err := view.ListPage(ctx, "", after, entityAccessorsPageSize)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, key := range keys {
+			after = key
+
+			aEntry, err := ts.lookupByAccessor(ctx, key, true, false)
+			if err != nil {
+				return nil, "", err
+			}
+			if aEntry == nil || aEntry.TokenID == "" || aEntry.NamespaceID != ns.ID {
+				continue
+			}
+
+			te, err := ts.Lookup(ctx, aEntry.TokenID)
+			if err != nil {
+				return nil, "", err
+			}
+			if te == nil || te.EntityID != entityID {
+				continue
+			}
+
+			accessors = append(accessors, aEntry.AccessorID)
+			if len(accessors) == limit {
+				return accessors, after, nil
+			}
+		}
+
+		if len(keys) < entityAccessorsPageSize {
+			return accessors, "", nil
+		}
+	}
+}
+
 // handleCreate handles the auth/token/create path for creation of new orphan
 // tokens
 func (ts *TokenStore) handleCreateOrphan(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -3004,15 +3173,29 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 	case te.Parent != "":
 		te.EntityID = parent.EntityID
 
-		// If the parent has bound CIDRs, copy those into the child. We don't
-		// do this if role is not nil because then we always use the role's
-		// bound CIDRs; roles allow escalation of privilege in proper
-		// circumstances.
+		// If the parent has bound CIDRs or a bound client certificate, copy
+		// those into the child. We don't do this if role is not nil because
+		// then we always use the role's bound CIDRs; roles allow escalation
+		// of privilege in proper circumstances.
 		if role == nil {
 			te.BoundCIDRs = parent.BoundCIDRs
+			te.BoundClientCertThumbprint = parent.BoundClientCertThumbprint
 		}
 	}
 
+	// Bind the token to the client certificate of the request, so that the
+	// token cannot be used without the matching private key
+	if d.Get("bind_client_cert").(bool) || (role != nil && role.BindClientCert) {
+		thumbprint := requestClientCertThumbprint(req)
+		if thumbprint == "" {
+			return logical.ErrorResponse("binding the token to a client certificate requires a client certificate"), logical.ErrInvalidRequest
+		}
+		te.BoundClientCertThumbprint = thumbprint
+	}
+	if te.BoundClientCertThumbprint != "" && te.Type == logical.TokenTypeBatch {
+		return logical.ErrorResponse("batch tokens cannot be bound to a client certificate"), logical.ErrInvalidRequest
+	}
+
 	var explicitMaxTTLToUse time.Duration
 	if explicitMaxTTL != "" {
 		dur, err := parseutil.ParseDurationSecond(explicitMaxTTL)
@@ -3347,6 +3530,10 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		resp.Data["bound_cidrs"] = out.BoundCIDRs
 	}
 
+	if out.BoundClientCertThumbprint != "" {
+		resp.Data["bound_client_cert_thumbprint"] = out.BoundClientCertThumbprint
+	}
+
 	tokenNS, err := NamespaceByID(ctx, out.NamespaceID, ts.core)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -3376,6 +3563,9 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		renewable, _ := leaseTimes.renewable()
 		resp.Data["renewable"] = renewable
 		resp.Data["issue_time"] = leaseTimes.IssueTime
+		if leaseTimes.Auth != nil && !leaseTimes.Auth.SuggestedRenewAt.IsZero() {
+			resp.Data["suggested_renew_at"] = leaseTimes.Auth.SuggestedRenewAt
+		}
 	}
 
 	if out.EntityID != "" {
@@ -3425,12 +3615,37 @@ func (ts *TokenStore) handleRenew(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("batch tokens cannot be renewed"), nil
 	}
 
+	// Tokens bound to a client certificate can only be renewed by a client
+	// presenting it, whoever holds the token
+	if !tokenClientCertMatches(te, req) {
+		return logical.ErrorResponse("token is bound to a different client certificate"), logical.ErrPermissionDenied
+	}
+
 	// Renew the token and its children
 	resp, err = ts.expiration.RenewToken(ctx, req, te, increment)
 
 	return resp, err
 }
 
+// requestClientCertThumbprint returns the thumbprint of the client
+// certificate of the request, or an empty string if it has none.
+func requestClientCertThumbprint(req *logical.Request) string {
+	if req.Connection == nil {
+		return ""
+	}
+	return req.Connection.ClientCertThumbprint
+}
+
+// tokenClientCertMatches returns true if the token is not bound to a client
+// certificate or if the request presents the certificate it is bound to.
+func tokenClientCertMatches(te *logical.TokenEntry, req *logical.Request) bool {
+	if te.BoundClientCertThumbprint == "" {
+		return true
+	}
+	thumbprint := requestClientCertThumbprint(req)
+	return thumbprint != "" && subtle.ConstantTimeCompare([]byte(thumbprint), []byte(te.BoundClientCertThumbprint)) == 1
+}
+
 func (ts *TokenStore) authRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	if req.Auth == nil {
 		return nil, fmt.Errorf("request auth is nil")
@@ -3556,11 +3771,15 @@ func (ts *TokenStore) tokenStoreRoleRead(ctx context.Context, req *logical.Reque
 			"allowed_policies_glob":    role.AllowedPoliciesGlob,
 			"name":                     role.Name,
 			"orphan":                   role.Orphan,
+			"bind_client_cert":         role.BindClientCert,
 			"path_suffix":              role.PathSuffix,
 			"renewable":                role.Renewable,
 			"token_type":               role.TokenType.String(),
 			"allowed_entity_aliases":   role.AllowedEntityAliases,
 			"token_no_default_policy":  role.TokenNoDefaultPolicy,
+			"step_up_mfa_method_id":    role.StepUpMFAMethodID,
+			"step_up_policies":         role.StepUpPolicies,
+			"step_up_ttl":              int64(role.StepUpTTL.Seconds()),
 		},
 	}
 
@@ -3624,6 +3843,10 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 			entry.Renewable = data.Get("renewable").(bool)
 		}
 
+		if bindClientCertRaw, ok := data.GetOk("bind_client_cert"); ok {
+			entry.BindClientCert = bindClientCertRaw.(bool)
+		}
+
 		pathSuffixInt, ok := data.GetOk("path_suffix")
 		if ok {
 			pathSuffix := pathSuffixInt.(string)
@@ -3811,6 +4034,9 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 		if entry.ExplicitMaxTTL != 0 || entry.TokenExplicitMaxTTL != 0 {
 			return logical.ErrorResponse("'token_type' cannot be 'batch' when role is set to generate tokens with an explicit max TTL"), nil
 		}
+		if entry.BindClientCert {
+			return logical.ErrorResponse("'token_type' cannot be 'batch' when role is set to bind tokens to a client certificate"), nil
+		}
 	}
 
 	allowedEntityAliasesRaw, ok := data.GetOk("allowed_entity_aliases")
@@ -3823,6 +4049,10 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 		return nil, err
 	}
 
+	if errResp := ts.parseStepUpFields(ctx, ns, entry, data); errResp != nil {
+		return errResp, nil
+	}
+
 	// Store it
 	jsonEntry, err := logical.StorageEntryJSON(name, entry)
 	if err != nil {
@@ -4191,6 +4421,27 @@ no effect on the token being renewed.`
 	tokenRenewableHelp = `Tokens created via this role will be
 renewable or not according to this value.
 Defaults to "true".`
+	tokenStepUpMFAMethodIDHelp = `The ID of a TOTP MFA method. If set,
+tokens can be stepped up against this role
+by presenting a passcode for this method.`
+	tokenStepUpPoliciesHelp = `Policies added to the caller's policies
+in tokens issued by step-up.`
+	tokenStepUpTTLHelp = `The TTL of tokens issued by step-up. Tokens
+never outlive the token that was stepped up.
+Defaults to 5 minutes.`
+	tokenBindClientCertHelp = `If true, the token is bound to the client certificate
+of the creation request and can only be used, including to renew it, by
+requests presenting the same certificate.`
+	tokenStepUpHelp     = `Exchange a TOTP passcode for a short-lived child token with additional policies.`
+	tokenStepUpHelpDesc = `
+This endpoint validates a passcode for the role's step-up TOTP MFA
+method against the MFA secret registered for the calling token's entity.
+On success it issues a child of the calling token with the role's
+step-up policies added to its own. The child's TTL is the role's
+step_up_ttl, and its explicit max TTL is set so that it cannot be
+renewed past the calling token's expiration. Each passcode can only be
+used once.
+`
 	tokenListAccessorsHelp = `List token accessors, which can then be
 be used to iterate and discover their properties
 or revoke them. Because this can be used to
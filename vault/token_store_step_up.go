@@ -0,0 +1,198 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/framework"
+	"github.com/openbao/openbao/sdk/v2/helper/policyutil"
+	"github.com/openbao/openbao/sdk/v2/helper/strutil"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// defaultStepUpTTL is the TTL of stepped up tokens when the role does not set
+// one.
+const defaultStepUpTTL = 5 * time.Minute
+
+// parseStepUpFields sets the step-up fields of the role from the request. A
+// non-nil response is returned when they are invalid.
+func (ts *TokenStore) parseStepUpFields(ctx context.Context, ns *namespace.Namespace, entry *tsRoleEntry, data *framework.FieldData) *logical.Response {
+	if methodIDRaw, ok := data.GetOk("step_up_mfa_method_id"); ok {
+		entry.StepUpMFAMethodID = methodIDRaw.(string)
+	}
+	if policiesRaw, ok := data.GetOk("step_up_policies"); ok {
+		entry.StepUpPolicies = policyutil.SanitizePolicies(policiesRaw.([]string), policyutil.DoNotAddDefaultPolicy)
+	}
+	if ttlRaw, ok := data.GetOk("step_up_ttl"); ok {
+		entry.StepUpTTL = time.Second * time.Duration(ttlRaw.(int))
+	}
+
+	if entry.StepUpTTL < 0 {
+		return logical.ErrorResponse("'step_up_ttl' must be positive")
+	}
+	for _, policy := range entry.StepUpPolicies {
+		if policy == "root" || strutil.StrListContains(nonAssignablePolicies, policy) {
+			return logical.ErrorResponse(fmt.Sprintf("cannot assign policy %q on step-up", policy))
+		}
+	}
+
+	if entry.StepUpMFAMethodID == "" {
+		return nil
+	}
+
+	mConfig, err := ts.core.loginMFABackend.MemDBMFAConfigByID(entry.StepUpMFAMethodID)
+	if err != nil || mConfig == nil {
+		return logical.ErrorResponse("'step_up_mfa_method_id' does not refer to an existing MFA method")
+	}
+	if mConfig.Type != mfaMethodTypeTOTP {
+		return logical.ErrorResponse("'step_up_mfa_method_id' must refer to a TOTP MFA method")
+	}
+	mfaNs, err := NamespaceByID(ctx, mConfig.NamespaceID, ts.core)
+	if err != nil || mfaNs == nil || (ns.ID != mfaNs.ID && !ns.HasParent(mfaNs)) {
+		return logical.ErrorResponse("'step_up_mfa_method_id' refers to an MFA method in an incompatible namespace")
+	}
+
+	return nil
+}
+
+// handleStepUp handles the auth/token/step-up/<role> path. It validates a
+// passcode for the role's TOTP method against the calling token's entity,
+// then issues a child token with the role's step-up policies added.
+func (ts *TokenStore) handleStepUp(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("role_name").(string)
+	role, err := ts.tokenStoreRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown role %s", name)), nil
+	}
+	if role.StepUpMFAMethodID == "" {
+		return logical.ErrorResponse(fmt.Sprintf("role %s does not allow step-up", name)), logical.ErrInvalidRequest
+	}
+
+	passcode := d.Get("passcode").(string)
+	if passcode == "" {
+		return logical.ErrorResponse("missing passcode"), logical.ErrInvalidRequest
+	}
+
+	parent, err := ts.Lookup(ctx, req.ClientToken)
+	if err != nil {
+		return nil, fmt.Errorf("parent token lookup failed: %w", err)
+	}
+	if parent == nil {
+		return logical.ErrorResponse("parent token lookup failed: no parent found"), logical.ErrInvalidRequest
+	}
+	if parent.Type == logical.TokenTypeBatch {
+		return logical.ErrorResponse("batch tokens cannot be stepped up"), logical.ErrInvalidRequest
+	}
+	if parent.NumUses > 0 {
+		return logical.ErrorResponse("restricted use tokens cannot be stepped up"), logical.ErrInvalidRequest
+	}
+	if parent.EntityID == "" {
+		return logical.ErrorResponse("tokens without an entity cannot be stepped up"), logical.ErrInvalidRequest
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ns.ID != parent.NamespaceID {
+		return logical.ErrorResponse("tokens can only be stepped up in their own namespace"), logical.ErrInvalidRequest
+	}
+
+	entity, err := ts.core.identityStore.MemDBEntityByID(parent.EntityID, false)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil || entity.Disabled {
+		return logical.ErrorResponse("the token's entity is missing or disabled"), logical.ErrPermissionDenied
+	}
+
+	// Used passcodes are tracked alongside those of login MFA, so a passcode
+	// cannot be replayed here or at login within its validity period
+	var remoteAddr string
+	if req.Connection != nil {
+		remoteAddr = req.Connection.RemoteAddr
+	}
+	if err := ts.core.validateLoginMFAInternal(ctx, role.StepUpMFAMethodID, entity, remoteAddr, []string{passcode}); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("step-up validation failed: %v", err)), logical.ErrPermissionDenied
+	}
+
+	policies := append(append([]string{}, parent.Policies...), role.StepUpPolicies...)
+	te := logical.TokenEntry{
+		Parent: req.ClientToken,
+
+		// The role is recorded only in the path, so that renewals use the
+		// explicit max TTL of the token rather than that of the role
+		Path: fmt.Sprintf("auth/token/step-up/%s", role.Name),
+
+		Policies:     policyutil.SanitizePolicies(policies, policyutil.DoNotAddDefaultPolicy),
+		Meta:         parent.Meta,
+		DisplayName:  parent.DisplayName,
+		CreationTime: time.Now().Unix(),
+		NamespaceID:  ns.ID,
+		EntityID:     parent.EntityID,
+		BoundCIDRs:   parent.BoundCIDRs,
+		Type:         logical.TokenTypeService,
+	}
+
+	// Bound the token's lifetime, including renewals, by the parent's
+	ttl := role.StepUpTTL
+	if ttl == 0 {
+		ttl = defaultStepUpTTL
+	}
+	explicitMaxTTL := role.TokenExplicitMaxTTL
+	leaseTimes, err := ts.expiration.FetchLeaseTimesByToken(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	if leaseTimes != nil && !leaseTimes.ExpireTime.IsZero() {
+		remaining := leaseTimes.ExpireTime.Sub(time.Unix(te.CreationTime, 0))
+		if remaining <= 0 {
+			return logical.ErrorResponse("the token has expired"), logical.ErrPermissionDenied
+		}
+		if explicitMaxTTL == 0 || remaining < explicitMaxTTL {
+			explicitMaxTTL = remaining
+		}
+	}
+
+	resp := &logical.Response{}
+	sysView := ts.System().(extendedSystemView)
+	ttl, warnings, err := framework.CalculateTTL(sysView, 0, ttl, 0, 0, explicitMaxTTL, time.Unix(te.CreationTime, 0))
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		resp.AddWarning(warning)
+	}
+	te.TTL = ttl
+	te.ExplicitMaxTTL = explicitMaxTTL
+
+	if err := ts.create(ctx, &te); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	resp.Auth = &logical.Auth{
+		DisplayName: te.DisplayName,
+		Policies:    te.Policies,
+		Metadata:    te.Meta,
+		LeaseOptions: logical.LeaseOptions{
+			TTL:       te.TTL,
+			Renewable: role.Renewable,
+		},
+		ClientToken:    te.ID,
+		Accessor:       te.Accessor,
+		EntityID:       te.EntityID,
+		ExplicitMaxTTL: te.ExplicitMaxTTL,
+		CreationPath:   te.Path,
+		TokenType:      te.Type,
+	}
+
+	return resp, nil
+}
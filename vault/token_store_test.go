@@ -33,6 +33,7 @@ import (
 	"github.com/openbao/openbao/sdk/v2/helper/locksutil"
 	"github.com/openbao/openbao/sdk/v2/helper/tokenutil"
 	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTokenStore_CreateOrphanResponse(t *testing.T) {
@@ -2523,6 +2524,12 @@ func testTokenStoreHandleRequestLookup(t *testing.T, batch, periodic bool) {
 		t.Fatal("expire time is default time")
 	}
 	delete(resp.Data, "expire_time")
+	if !batch {
+		if resp.Data["suggested_renew_at"].(time.Time).IsZero() {
+			t.Fatal("suggested renew time is default time")
+		}
+		delete(resp.Data, "suggested_renew_at")
+	}
 
 	// Depending on timing of the test this may have ticked down, so accept 3599
 	if resp.Data["ttl"].(int64) == 3599 {
@@ -2611,6 +2618,10 @@ func TestTokenStore_HandleRequest_LookupSelf(t *testing.T) {
 		t.Fatalf("expire time was zero")
 	}
 	delete(resp.Data, "expire_time")
+	if resp.Data["suggested_renew_at"].(time.Time).IsZero() {
+		t.Fatalf("suggested renew time was zero")
+	}
+	delete(resp.Data, "suggested_renew_at")
 
 	// Depending on timing of the test this may have ticked down, so accept 3599
 	if resp.Data["ttl"].(int64) == 3599 {
@@ -3207,6 +3218,7 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 	expected := map[string]interface{}{
 		"name":                     "test",
 		"orphan":                   true,
+		"bind_client_cert":         false,
 		"token_period":             int64(259200),
 		"period":                   int64(259200),
 		"allowed_policies":         []string{"test1", "test2"},
@@ -3221,6 +3233,9 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 		"token_num_uses":           123,
 		"allowed_entity_aliases":   []string(nil),
 		"token_no_default_policy":  false,
+		"step_up_mfa_method_id":    "",
+		"step_up_policies":         []string(nil),
+		"step_up_ttl":              int64(0),
 	}
 
 	if resp.Data["bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "0.0.0.0/0" {
@@ -3272,6 +3287,7 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 	expected = map[string]interface{}{
 		"name":                     "test",
 		"orphan":                   true,
+		"bind_client_cert":         false,
 		"period":                   int64(284400),
 		"token_period":             int64(284400),
 		"allowed_policies":         []string{"test3"},
@@ -3285,6 +3301,9 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 		"token_type":               "default-service",
 		"allowed_entity_aliases":   []string(nil),
 		"token_no_default_policy":  true,
+		"step_up_mfa_method_id":    "",
+		"step_up_policies":         []string(nil),
+		"step_up_ttl":              int64(0),
 	}
 
 	if resp.Data["bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "0.0.0.0/0" {
@@ -3325,6 +3344,7 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 	expected = map[string]interface{}{
 		"name":                     "test",
 		"orphan":                   true,
+		"bind_client_cert":         false,
 		"explicit_max_ttl":         int64(5),
 		"token_explicit_max_ttl":   int64(5),
 		"allowed_policies":         []string{"test3"},
@@ -3338,6 +3358,9 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 		"token_type":               "default-service",
 		"allowed_entity_aliases":   []string(nil),
 		"token_no_default_policy":  true,
+		"step_up_mfa_method_id":    "",
+		"step_up_policies":         []string(nil),
+		"step_up_ttl":              int64(0),
 	}
 
 	if resp.Data["bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "0.0.0.0/0" {
@@ -3379,6 +3402,7 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 	expected = map[string]interface{}{
 		"name":                     "test",
 		"orphan":                   true,
+		"bind_client_cert":         false,
 		"token_explicit_max_ttl":   int64(5),
 		"explicit_max_ttl":         int64(5),
 		"allowed_policies":         []string{"test3"},
@@ -3392,6 +3416,9 @@ func TestTokenStore_RoleCRUD(t *testing.T) {
 		"token_type":               "default-service",
 		"allowed_entity_aliases":   []string(nil),
 		"token_no_default_policy":  false,
+		"step_up_mfa_method_id":    "",
+		"step_up_policies":         []string(nil),
+		"step_up_ttl":              int64(0),
 	}
 
 	if diff := deep.Equal(expected, resp.Data); diff != nil {
@@ -4448,6 +4475,7 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 		expected := map[string]interface{}{
 			"name":                     "test",
 			"orphan":                   false,
+			"bind_client_cert":         false,
 			"period":                   int64(1),
 			"token_period":             int64(1),
 			"allowed_policies":         []string(nil),
@@ -4461,6 +4489,9 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 			"token_type":               "batch",
 			"allowed_entity_aliases":   []string(nil),
 			"token_no_default_policy":  false,
+			"step_up_mfa_method_id":    "",
+			"step_up_policies":         []string(nil),
+			"step_up_ttl":              int64(0),
 		}
 
 		if resp.Data["bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "127.0.0.1" {
@@ -4504,6 +4535,7 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 		expected := map[string]interface{}{
 			"name":                     "test",
 			"orphan":                   false,
+			"bind_client_cert":         false,
 			"period":                   int64(5),
 			"token_period":             int64(5),
 			"allowed_policies":         []string(nil),
@@ -4517,6 +4549,9 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 			"token_type":               "default-service",
 			"allowed_entity_aliases":   []string(nil),
 			"token_no_default_policy":  false,
+			"step_up_mfa_method_id":    "",
+			"step_up_policies":         []string(nil),
+			"step_up_ttl":              int64(0),
 		}
 
 		if resp.Data["bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "127.0.0.1" {
@@ -4559,6 +4594,7 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 		expected := map[string]interface{}{
 			"name":                     "test",
 			"orphan":                   false,
+			"bind_client_cert":         false,
 			"period":                   int64(0),
 			"token_period":             int64(7),
 			"allowed_policies":         []string(nil),
@@ -4572,6 +4608,9 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 			"token_type":               "default-service",
 			"allowed_entity_aliases":   []string(nil),
 			"token_no_default_policy":  false,
+			"step_up_mfa_method_id":    "",
+			"step_up_policies":         []string(nil),
+			"step_up_ttl":              int64(0),
 		}
 
 		if resp.Data["token_bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "127.0.0.1" {
@@ -4616,6 +4655,7 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 		expected := map[string]interface{}{
 			"name":                     "test",
 			"orphan":                   false,
+			"bind_client_cert":         false,
 			"period":                   int64(0),
 			"token_period":             int64(5),
 			"allowed_policies":         []string(nil),
@@ -4629,6 +4669,9 @@ func TestTokenStore_RoleTokenFields(t *testing.T) {
 			"token_type":               "service",
 			"allowed_entity_aliases":   []string(nil),
 			"token_no_default_policy":  false,
+			"step_up_mfa_method_id":    "",
+			"step_up_policies":         []string(nil),
+			"step_up_ttl":              int64(0),
 		}
 
 		if resp.Data["token_bound_cidrs"].([]*sockaddr.SockAddrMarshaler)[0].String() != "127.0.0.1" {
@@ -6179,3 +6222,109 @@ func TestTokenStore_Collectors(t *testing.T) {
 	// Need to set up router for this to work, TODO
 	// ts.gaugeCollectorByMethod( ctx )
 }
+
+func TestTokenStore_BindClientCert(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	thumbprint := strings.Repeat("0f", 32)
+	otherThumbprint := strings.Repeat("ab", 32)
+	request := func(op logical.Operation, path, token, certThumbprint string, data map[string]interface{}) (*logical.Response, error) {
+		t.Helper()
+		return c.HandleRequest(ctx, &logical.Request{
+			Operation:   op,
+			Path:        path,
+			ClientToken: token,
+			Data:        data,
+			Connection: &logical.Connection{
+				ClientCertThumbprint: certThumbprint,
+			},
+		})
+	}
+	create := func(certThumbprint string, data map[string]interface{}) string {
+		t.Helper()
+		resp, err := request(logical.UpdateOperation, "auth/token/create", root, certThumbprint, data)
+		require.NoError(t, err)
+		require.NotNil(t, resp.Auth)
+		return resp.Auth.ClientToken
+	}
+
+	// Binding requires a client certificate and a service token.
+	_, err := request(logical.UpdateOperation, "auth/token/create", root, "", map[string]interface{}{
+		"bind_client_cert": true,
+	})
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+	_, err = request(logical.UpdateOperation, "auth/token/create", root, thumbprint, map[string]interface{}{
+		"bind_client_cert": true,
+		"type":             "batch",
+		"policies":         "default",
+	})
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+
+	token := create(thumbprint, map[string]interface{}{
+		"bind_client_cert": true,
+		"ttl":              "1h",
+	})
+
+	resp, err := request(logical.ReadOperation, "auth/token/lookup-self", token, thumbprint, nil)
+	require.NoError(t, err)
+	require.Equal(t, thumbprint, resp.Data["bound_client_cert_thumbprint"])
+
+	for _, certThumbprint := range []string{"", otherThumbprint} {
+		_, err = request(logical.ReadOperation, "auth/token/lookup-self", token, certThumbprint, nil)
+		require.ErrorIs(t, err, logical.ErrPermissionDenied)
+		_, err = request(logical.UpdateOperation, "auth/token/renew-self", token, certThumbprint, nil)
+		require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+		// Renewing on behalf of the token holder needs the bound certificate
+		// too.
+		_, err = request(logical.UpdateOperation, "auth/token/renew", root, certThumbprint, map[string]interface{}{
+			"token": token,
+		})
+		require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	}
+
+	_, err = request(logical.UpdateOperation, "auth/token/renew-self", token, thumbprint, nil)
+	require.NoError(t, err)
+	_, err = request(logical.UpdateOperation, "auth/token/renew", root, thumbprint, map[string]interface{}{
+		"token": token,
+	})
+	require.NoError(t, err)
+
+	// Child tokens inherit the binding of their parent.
+	resp, err = request(logical.UpdateOperation, "auth/token/create", token, thumbprint, map[string]interface{}{
+		"ttl": "30m",
+	})
+	require.NoError(t, err)
+	child := resp.Auth.ClientToken
+	_, err = request(logical.ReadOperation, "auth/token/lookup-self", child, otherThumbprint, nil)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	_, err = request(logical.ReadOperation, "auth/token/lookup-self", child, thumbprint, nil)
+	require.NoError(t, err)
+
+	// Roles can bind the tokens created against them.
+	resp, err = request(logical.UpdateOperation, "auth/token/roles/bound", root, "", map[string]interface{}{
+		"bind_client_cert": true,
+		"token_type":       "batch",
+		"orphan":           true,
+		"renewable":        false,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+	_, err = request(logical.UpdateOperation, "auth/token/roles/bound", root, "", map[string]interface{}{
+		"bind_client_cert": true,
+	})
+	require.NoError(t, err)
+	resp, err = request(logical.UpdateOperation, "auth/token/create/bound", root, otherThumbprint, nil)
+	require.NoError(t, err)
+	roleToken := resp.Auth.ClientToken
+	_, err = request(logical.ReadOperation, "auth/token/lookup-self", roleToken, thumbprint, nil)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+	_, err = request(logical.ReadOperation, "auth/token/lookup-self", roleToken, otherThumbprint, nil)
+	require.NoError(t, err)
+
+	// Unbound tokens are not affected.
+	unbound := create("", nil)
+	_, err = request(logical.ReadOperation, "auth/token/lookup-self", unbound, otherThumbprint, nil)
+	require.NoError(t, err)
+}
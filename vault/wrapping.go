@@ -125,6 +125,19 @@ DONELISTHANDLING:
 		}
 	}
 
+	// Wrapping TTLs asked for by the backend rather than the client are
+	// capped to the maximum of the namespace.
+	if resp.WrapInfo.Format != "jwt" {
+		conf, err := c.wrappingConfig(ctx, ns)
+		if err != nil {
+			c.logger.Error("failed to load wrapping config", "namespace", ns.Path, "error", err)
+			return nil, ErrInternalError
+		}
+		if conf.MaxTTL > 0 && resp.WrapInfo.TTL > conf.MaxTTL {
+			resp.WrapInfo.TTL = conf.MaxTTL
+		}
+	}
+
 	// If we are wrapping, the first part (performed in this functions) happens
 	// before auditing so that resp.WrapInfo.Token can contain the HMAC'd
 	// wrapping token ID in the audit logs, so that it can be determined from
@@ -449,6 +462,14 @@ func (c *Core) validateWrappingToken(ctx context.Context, req *logical.Request)
 		return false, nil
 	}
 
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	if te.NamespaceID != ns.ID {
+		return false, nil
+	}
+
 	if !thirdParty {
 		req.ClientTokenAccessor = te.Accessor
 		req.ClientTokenRemainingUses = te.NumUses
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+)
+
+// wrappingConfigSubPath is the path under the system view at which the
+// response wrapping configuration of each namespace is stored, by namespace
+// ID.
+const wrappingConfigSubPath = "config/wrapping/"
+
+// WrappingConfig holds the response wrapping settings of a namespace.
+type WrappingConfig struct {
+	// DefaultTTL is the TTL of the wrapping tokens of requests asking for
+	// response wrapping with a TTL of zero.
+	DefaultTTL time.Duration `json:"default_ttl"`
+
+	// MaxTTL is the longest TTL of the wrapping tokens created in the
+	// namespace. Requests asking for a longer TTL are denied.
+	MaxTTL time.Duration `json:"max_ttl"`
+
+	// AllowedPaths are the path prefixes, relative to the namespace, whose
+	// responses may be wrapped. Prefixes match whole path segments. Any path
+	// may be wrapped when empty.
+	AllowedPaths []string `json:"allowed_paths"`
+}
+
+// pathAllowed returns true if the responses of the given path may be wrapped.
+// A prefix only matches on a segment boundary, so that "secret" allows
+// "secret" and "secret/foo" but not "secretfoo".
+func (wc *WrappingConfig) pathAllowed(path string) bool {
+	if len(wc.AllowedPaths) == 0 {
+		return true
+	}
+	for _, prefix := range wc.AllowedPaths {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// wrappingConfig returns the response wrapping configuration of the
+// namespace. An empty configuration is returned if none is set.
+func (c *Core) wrappingConfig(ctx context.Context, ns *namespace.Namespace) (*WrappingConfig, error) {
+	c.wrappingConfigLock.RLock()
+	conf, ok := c.wrappingConfigs[ns.ID]
+	c.wrappingConfigLock.RUnlock()
+	if ok {
+		return conf, nil
+	}
+
+	c.wrappingConfigLock.Lock()
+	defer c.wrappingConfigLock.Unlock()
+
+	if conf, ok := c.wrappingConfigs[ns.ID]; ok {
+		return conf, nil
+	}
+
+	entry, err := c.systemBarrierView.Get(ctx, wrappingConfigSubPath+ns.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapping config: %w", err)
+	}
+
+	conf = new(WrappingConfig)
+	if entry != nil {
+		if err := entry.DecodeJSON(conf); err != nil {
+			return nil, fmt.Errorf("failed to decode wrapping config: %w", err)
+		}
+	}
+
+	if c.wrappingConfigs == nil {
+		c.wrappingConfigs = make(map[string]*WrappingConfig)
+	}
+	c.wrappingConfigs[ns.ID] = conf
+
+	return conf, nil
+}
+
+// setWrappingConfig persists the response wrapping configuration of the
+// namespace. A nil configuration removes it.
+func (c *Core) setWrappingConfig(ctx context.Context, ns *namespace.Namespace, conf *WrappingConfig) error {
+	c.wrappingConfigLock.Lock()
+	defer c.wrappingConfigLock.Unlock()
+
+	path := wrappingConfigSubPath + ns.ID
+	if conf == nil {
+		if err := c.systemBarrierView.Delete(ctx, path); err != nil {
+			return fmt.Errorf("failed to delete wrapping config: %w", err)
+		}
+		conf = new(WrappingConfig)
+	} else {
+		entry, err := logical.StorageEntryJSON(path, conf)
+		if err != nil {
+			return fmt.Errorf("failed to create wrapping config entry: %w", err)
+		}
+		if err := c.systemBarrierView.Put(ctx, entry); err != nil {
+			return fmt.Errorf("failed to save wrapping config: %w", err)
+		}
+	}
+
+	if c.wrappingConfigs == nil {
+		c.wrappingConfigs = make(map[string]*WrappingConfig)
+	}
+	c.wrappingConfigs[ns.ID] = conf
+
+	return nil
+}
+
+// resetWrappingConfigs clears the cached wrapping configurations, so that
+// they get loaded again from storage.
+func (c *Core) resetWrappingConfigs() {
+	c.wrappingConfigLock.Lock()
+	defer c.wrappingConfigLock.Unlock()
+
+	c.wrappingConfigs = make(map[string]*WrappingConfig)
+}
+
+// checkWrappingConfig applies the response wrapping configuration of the
+// namespace of the request to the wrapping it asks for. The default TTL is
+// filled in first, so that the policies check the TTL which is used.
+func (c *Core) checkWrappingConfig(ctx context.Context, req *logical.Request) error {
+	if req.WrapInfo == nil {
+		return nil
+	}
+
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	conf, err := c.wrappingConfig(ctx, ns)
+	if err != nil {
+		c.logger.Error("failed to load wrapping config", "namespace", ns.Path, "error", err)
+		return ErrInternalError
+	}
+
+	if req.WrapInfo.TTL == 0 {
+		req.WrapInfo.TTL = conf.DefaultTTL
+	}
+	if req.WrapInfo.TTL == 0 {
+		return nil
+	}
+
+	if conf.MaxTTL > 0 && req.WrapInfo.TTL > conf.MaxTTL {
+		return multierror.Append(fmt.Errorf("requested wrapping TTL exceeds the maximum of %s in this namespace", conf.MaxTTL), logical.ErrPermissionDenied)
+	}
+	if !conf.pathAllowed(req.Path) {
+		return multierror.Append(fmt.Errorf("response wrapping is not allowed for path %q in this namespace", req.Path), logical.ErrPermissionDenied)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2024 OpenBao a Series of LF Projects, LLC
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+	credUserpass "github.com/openbao/openbao/builtin/credential/userpass"
+	"github.com/openbao/openbao/helper/namespace"
+	"github.com/openbao/openbao/sdk/v2/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrappingConfig(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	core.logicalBackends["kv"] = PassthroughBackendFactory
+	meUUID, _ := uuid.GenerateUUID()
+	require.NoError(t, core.mount(ctx, &MountEntry{
+		Table: mountTableType,
+		UUID:  meUUID,
+		Path:  "wraptest",
+		Type:  "kv",
+	}))
+	_, err := core.HandleRequest(ctx, &logical.Request{
+		Path:        "wraptest/foo",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"zip": "zap",
+		},
+	})
+	require.NoError(t, err)
+
+	configure := func(data map[string]interface{}) (*logical.Response, error) {
+		return core.HandleRequest(ctx, &logical.Request{
+			Path:        "sys/wrapping/config",
+			ClientToken: root,
+			Operation:   logical.UpdateOperation,
+			Data:        data,
+		})
+	}
+	wrap := func(path string, ttl time.Duration) (*logical.Response, error) {
+		return core.HandleRequest(ctx, &logical.Request{
+			Path:        path,
+			ClientToken: root,
+			Operation:   logical.ReadOperation,
+			WrapInfo: &logical.RequestWrapInfo{
+				TTL: ttl,
+			},
+		})
+	}
+
+	_, err = configure(map[string]interface{}{
+		"default_ttl": "2h",
+		"max_ttl":     "1h",
+	})
+	require.Error(t, err)
+
+	_, err = configure(map[string]interface{}{
+		"default_ttl":   "10m",
+		"max_ttl":       "1h",
+		"allowed_paths": "/wraptest/",
+	})
+	require.NoError(t, err)
+
+	resp, err := core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/wrapping/config",
+		ClientToken: root,
+		Operation:   logical.ReadOperation,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"default_ttl":   int64(600),
+		"max_ttl":       int64(3600),
+		"allowed_paths": []string{"wraptest/"},
+	}, resp.Data)
+
+	// A TTL of zero takes the default of the namespace.
+	resp, err = wrap("wraptest/foo", 0)
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrapInfo)
+	require.Equal(t, 10*time.Minute, resp.WrapInfo.TTL)
+
+	resp, err = wrap("wraptest/foo", 30*time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, resp.WrapInfo.TTL)
+
+	_, err = wrap("wraptest/foo", 2*time.Hour)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	_, err = wrap("sys/mounts", 30*time.Minute)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// Requests which do not ask for wrapping are not restricted.
+	resp, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/mounts",
+		ClientToken: root,
+		Operation:   logical.ReadOperation,
+	})
+	require.NoError(t, err)
+	require.Nil(t, resp.WrapInfo)
+
+	// The configuration survives a seal and unseal.
+	core.resetWrappingConfigs()
+	_, err = wrap("sys/mounts", 30*time.Minute)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	_, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/wrapping/config",
+		ClientToken: root,
+		Operation:   logical.DeleteOperation,
+	})
+	require.NoError(t, err)
+	resp, err = wrap("sys/mounts", 2*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, resp.WrapInfo.TTL)
+}
+
+func TestWrappingConfig_PathAllowed(t *testing.T) {
+	conf := &WrappingConfig{AllowedPaths: []string{"secret/", "auth/approle/role/app/secret-id"}}
+	for path, allowed := range map[string]bool{
+		"secret":                             true,
+		"secret/foo":                         true,
+		"secretfoo":                          false,
+		"secretfoo/bar":                      false,
+		"auth/approle/role/app/secret-id":    true,
+		"auth/approle/role/app/secret-id/x":  true,
+		"auth/approle/role/app/secret-id-x":  false,
+		"auth/approle/role/app/secret-id-ac": false,
+		"sys/mounts":                         false,
+	} {
+		require.Equal(t, allowed, conf.pathAllowed(path), path)
+	}
+
+	require.True(t, (&WrappingConfig{}).pathAllowed("sys/mounts"))
+}
+
+func TestWrappingConfig_Login(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	core.credentialBackends["userpass"] = credUserpass.Factory
+	_, err := core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/auth/userpass",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"type": "userpass",
+		},
+	})
+	require.NoError(t, err)
+	_, err = core.HandleRequest(ctx, &logical.Request{
+		Path:        "auth/userpass/users/test",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"password": "foo",
+		},
+	})
+	require.NoError(t, err)
+
+	configure := func(data map[string]interface{}) {
+		_, err := core.HandleRequest(ctx, &logical.Request{
+			Path:        "sys/wrapping/config",
+			ClientToken: root,
+			Operation:   logical.UpdateOperation,
+			Data:        data,
+		})
+		require.NoError(t, err)
+	}
+	login := func(ttl time.Duration) (*logical.Response, error) {
+		return core.HandleRequest(ctx, &logical.Request{
+			Path:      "auth/userpass/login/test",
+			Operation: logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"password": "foo",
+			},
+			Connection: &logical.Connection{},
+			WrapInfo: &logical.RequestWrapInfo{
+				TTL: ttl,
+			},
+		})
+	}
+
+	configure(map[string]interface{}{
+		"max_ttl":       "1h",
+		"allowed_paths": "secret/",
+	})
+	_, err = login(30 * time.Minute)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	configure(map[string]interface{}{
+		"max_ttl":       "1h",
+		"allowed_paths": "auth/userpass/login",
+	})
+	_, err = login(2 * time.Hour)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	resp, err := login(30 * time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrapInfo)
+	require.Equal(t, 30*time.Minute, resp.WrapInfo.TTL)
+}
+
+func TestWrapping_ConcurrentUnwrap(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	resp, err := core.HandleRequest(ctx, &logical.Request{
+		Path:        "sys/wrapping/wrap",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"foo": "bar",
+		},
+		WrapInfo: &logical.RequestWrapInfo{
+			TTL: time.Minute,
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.WrapInfo)
+	wrappingToken := resp.WrapInfo.Token
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan *logical.Response, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Mix first-party and third-party unwraps.
+			req := &logical.Request{
+				Path:        "sys/wrapping/unwrap",
+				ClientToken: wrappingToken,
+				Operation:   logical.UpdateOperation,
+			}
+			if i%2 == 0 {
+				req.ClientToken = root
+				req.Data = map[string]interface{}{
+					"token": wrappingToken,
+				}
+			}
+			resp, err := core.HandleRequest(ctx, req)
+			if err == nil && !resp.IsError() {
+				results <- resp
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	require.Len(t, results, 1)
+	resp = <-results
+	require.Contains(t, string(resp.Data["http_raw_body"].([]byte)), `"foo":"bar"`)
+}
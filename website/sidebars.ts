@@ -385,6 +385,7 @@ const sidebars: SidebarsConfig = {
                 "auth/cert",
                 "auth/token",
                 "auth/userpass",
+                "auth/webauthn",
             ],
             "Audit Devices": [
                 "audit/index",
@@ -72,11 +72,13 @@ const sidebars: SidebarsConfig = {
         "auth/cert",
         "auth/token",
         "auth/userpass",
+        "auth/webauthn",
       ],
       "System Backend": [
         "system/index",
         "system/audit",
         "system/audit-hash",
+        "system/audit-salt",
         "system/auth",
         "system/capabilities",
         "system/capabilities-accessor",
@@ -92,6 +94,7 @@ const sidebars: SidebarsConfig = {
         "system/host-info",
         "system/in-flight-req",
         "system/init",
+        "system/internal-cache-physical",
         "system/internal-counters",
         {
           "sys/internal/inspect": [
@@ -121,11 +124,14 @@ const sidebars: SidebarsConfig = {
         "system/policies-password",
         "system/pprof",
         "system/quotas-config",
+        "system/lease-count-quotas",
         "system/rate-limit-quotas",
+        "system/secret-count-quotas",
         "system/raw",
         "system/rekey",
         "system/rekey-recovery-key",
         "system/remount",
+        "system/revoke-batch",
         "system/rotate",
         "system/rotate-config",
         "system/seal",
@@ -142,6 +148,7 @@ const sidebars: SidebarsConfig = {
         "system/unseal",
         "system/user-lockout",
         "system/version-history",
+        "system/wrapping-config",
         "system/wrapping-lookup",
         "system/wrapping-rewrap",
         "system/wrapping-unwrap",